@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/bougou/go-ipmi/pkg/client"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// collector gathers one group of metrics over an open session.
+type collector struct {
+	name    string
+	collect func(ctx context.Context, c *client.Client, m *metrics) error
+}
+
+// collectors are the collectors a module can run, in the order they run.
+var collectors = []collector{
+	{"bmc", collectBMC},
+	{"chassis", collectChassis},
+	{"sensor", collectSensors},
+	{"dcmi", collectDCMI},
+	{"sel", collectSEL},
+}
+
+// collectBMC reports the BMC's identity from Get Device ID and Get Device
+// GUID (v2.0§20.1, §20.8).
+func collectBMC(ctx context.Context, c *client.Client, m *metrics) error {
+	id, err := c.GetDeviceID(ctx)
+	if err != nil {
+		return fmt.Errorf("GetDeviceID: %w", err)
+	}
+	labels := []label{
+		{"firmware_revision", fmt.Sprintf("%d.%02d", id.MajorFirmwareRevision, id.MinorFirmwareRevision)},
+		{"ipmi_version", fmt.Sprintf("%d.%d", id.MajorIPMIVersion, id.MinorIPMIVersion)},
+		{"manufacturer_id", strconv.FormatUint(uint64(id.ManufacturerID), 10)},
+		{"product_id", strconv.FormatUint(uint64(id.ProductID), 10)},
+	}
+	// The GUID is optional; a BMC without one still has an identity.
+	if res, err := c.GetDeviceGUID(ctx); err == nil {
+		if guid, err := types.ParseGUID(res.GUID[:], types.GUIDModeSMBIOS); err == nil {
+			labels = append(labels, label{"guid", guid.String()})
+		}
+	}
+	m.gauge("ipmi_bmc_info", "Constant metric with the BMC's identity as labels.", 1, labels...)
+	return nil
+}
+
+// collectChassis reports the power and intrusion state of Get Chassis Status
+// (v2.0§28.2).
+func collectChassis(ctx context.Context, c *client.Client, m *metrics) error {
+	status, err := c.GetChassisStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("GetChassisStatus: %w", err)
+	}
+	m.gauge("ipmi_chassis_power_state", "'1' if the system power is on, '0' otherwise.", boolValue(status.PowerIsOn))
+	m.gauge("ipmi_chassis_intrusion_state", "'1' if the chassis intrusion is active, '0' otherwise.", boolValue(status.ChassisIntrusionActive))
+	return nil
+}
+
+// Sensor states: nominal, non-critical and critical or non-recoverable
+// thresholds crossed.
+const (
+	sensorStateNominal  = 0
+	sensorStateWarning  = 1
+	sensorStateCritical = 2
+)
+
+func sensorState(status types.SensorThresholdStatus) float64 {
+	switch status {
+	case types.SensorThresholdStatus_LNC, types.SensorThresholdStatus_UNC:
+		return sensorStateWarning
+	case types.SensorThresholdStatus_LCR, types.SensorThresholdStatus_UCR,
+		types.SensorThresholdStatus_LNR, types.SensorThresholdStatus_UNR:
+		return sensorStateCritical
+	default:
+		return sensorStateNominal
+	}
+}
+
+// collectSensors reports every sensor with a reading: the converted value
+// and threshold state of a threshold sensor, the active states of a
+// discrete one.
+func collectSensors(ctx context.Context, c *client.Client, m *metrics) error {
+	for result := range c.GetSensorsStream(ctx) {
+		if result.Err != nil {
+			return fmt.Errorf("GetSensorsStream: %w", result.Err)
+		}
+		s := result.Ok
+		if s.NotPresent || s.ScanningDisabled || !s.ReadingAvailable {
+			continue
+		}
+		// Sensor numbers are unique per owner and LUN only (v2.0§33.1).
+		labels := []label{
+			{"id", strconv.Itoa(int(s.Number))},
+			{"owner", fmt.Sprintf("0x%02x", s.GeneratorID.OwnerID())},
+			{"lun", strconv.Itoa(int(s.GeneratorID.LUN()))},
+			{"name", s.Name},
+			{"type", s.SensorType.String()},
+		}
+		if s.IsThreshold() && s.SensorUnit.IsAnalog() {
+			m.gauge("ipmi_sensor_value", "Reading of a threshold sensor, in its unit.", s.Value,
+				append(labels, label{"unit", s.SensorUnit.String()})...)
+			m.gauge("ipmi_sensor_state", "Threshold state of a sensor: 0=nominal, 1=warning (non-critical), 2=critical.",
+				sensorState(s.Threshold.ThresholdStatus), labels...)
+			continue
+		}
+		var states uint16
+		for _, offset := range s.Discrete.ActiveStates.TrueEvents() {
+			states |= 1 << offset
+		}
+		m.gauge("ipmi_sensor_active_states", "Bitmask of the asserted states of a discrete sensor, bit n for offset n.",
+			float64(states), labels...)
+	}
+	return nil
+}
+
+// collectDCMI reports the DCMI power reading (DCMI v1.5§6.6.1). A BMC whose
+// power measurement is inactive reports no reading.
+func collectDCMI(ctx context.Context, c *client.Client, m *metrics) error {
+	res, err := c.GetDCMIPowerReading(ctx)
+	if err != nil {
+		return fmt.Errorf("GetDCMIPowerReading: %w", err)
+	}
+	if !res.PowerMeasurementActive {
+		return nil
+	}
+	m.gauge("ipmi_dcmi_power_consumption_watts", "Current power consumption in watts.", float64(res.CurrentPower))
+	m.gauge("ipmi_dcmi_power_minimum_watts", "Minimum power consumption over the DCMI sampling period in watts.", float64(res.MinimumPower))
+	m.gauge("ipmi_dcmi_power_maximum_watts", "Maximum power consumption over the DCMI sampling period in watts.", float64(res.MaximumPower))
+	m.gauge("ipmi_dcmi_power_average_watts", "Average power consumption over the DCMI sampling period in watts.", float64(res.AveragePower))
+	return nil
+}
+
+// collectSEL reports the SEL's entry count and free space from Get SEL Info
+// (v2.0§31.2).
+func collectSEL(ctx context.Context, c *client.Client, m *metrics) error {
+	info, err := c.GetSELInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("GetSELInfo: %w", err)
+	}
+	m.gauge("ipmi_sel_logs_count", "Number of entries in the SEL.", float64(info.Entries))
+	m.gauge("ipmi_sel_free_space_bytes", "Free space in the SEL in bytes.", float64(info.FreeBytes))
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/client"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// defaultModule is the module a scrape without a module parameter uses.
+const defaultModule = "default"
+
+// config is the exporter's configuration file.
+type config struct {
+	Modules map[string]*module `json:"modules"`
+}
+
+// module is how to reach a class of BMCs: the credentials and session
+// settings, and which collectors to run.
+type module struct {
+	User       string   `json:"user"`
+	Password   string   `json:"password"`
+	Interface  string   `json:"interface"` // lan or lanplus (default)
+	Privilege  string   `json:"privilege"` // user (default), operator or administrator
+	Timeout    string   `json:"timeout"`   // per-request timeout, a Go duration
+	Retries    *int     `json:"retries"`
+	Collectors []string `json:"collectors"` // default: all
+
+	intf      client.Interface
+	privilege types.PrivilegeLevel
+	timeout   time.Duration
+}
+
+func defaultConfig() *config {
+	cfg := &config{Modules: map[string]*module{defaultModule: {}}}
+	if err := cfg.validate(); err != nil {
+		panic(err) // the zero module is valid
+	}
+	return cfg
+}
+
+func loadConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	cfg := &config{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// validate checks every module and resolves its settings.
+func (cfg *config) validate() error {
+	if len(cfg.Modules) == 0 {
+		return fmt.Errorf("no modules")
+	}
+	for name, m := range cfg.Modules {
+		if m == nil {
+			return fmt.Errorf("module %q: empty", name)
+		}
+		if err := m.validate(); err != nil {
+			return fmt.Errorf("module %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (m *module) validate() error {
+	switch strings.ToLower(m.Interface) {
+	case "", "lanplus":
+		m.intf = client.InterfaceLanplus
+	case "lan":
+		m.intf = client.InterfaceLan
+	default:
+		return fmt.Errorf("interface %q: expected lan or lanplus", m.Interface)
+	}
+
+	switch strings.ToLower(m.Privilege) {
+	case "", "user":
+		m.privilege = types.PrivilegeLevelUser
+	case "operator":
+		m.privilege = types.PrivilegeLevelOperator
+	case "administrator":
+		m.privilege = types.PrivilegeLevelAdministrator
+	default:
+		return fmt.Errorf("privilege %q: expected user, operator or administrator", m.Privilege)
+	}
+
+	m.timeout = 2 * time.Second
+	if m.Timeout != "" {
+		d, err := time.ParseDuration(m.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("timeout %q: expected a positive duration such as 2s", m.Timeout)
+		}
+		m.timeout = d
+	}
+	if m.Retries != nil && *m.Retries < 0 {
+		return fmt.Errorf("retries %d: must not be negative", *m.Retries)
+	}
+
+	for _, name := range m.Collectors {
+		if !slices.ContainsFunc(collectors, func(c collector) bool { return c.name == name }) {
+			return fmt.Errorf("unknown collector %q", name)
+		}
+	}
+	return nil
+}
+
+// enabled reports whether the module runs collector name.
+func (m *module) enabled(name string) bool {
+	return len(m.Collectors) == 0 || slices.Contains(m.Collectors, name)
+}
+
+func (cfg *config) moduleNames() string {
+	names := make([]string, 0, len(cfg.Modules))
+	for name := range cfg.Modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
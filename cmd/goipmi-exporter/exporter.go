@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bougou/go-ipmi/internal/promtext"
+	"github.com/bougou/go-ipmi/pkg/client"
+)
+
+// defaultPort is the RMCP port of a target given without one.
+const defaultPort = 623
+
+// The sensor records of a target are kept for sdrCacheTTL after its last
+// scrape, and of at most maxSDRCaches targets, the least recently scraped
+// going first: targets come from the scrape URL, so their number is the
+// callers' to choose.
+const (
+	sdrCacheTTL  = time.Hour
+	maxSDRCaches = 1024
+)
+
+// exporter serves /metrics, scraping the target each request names.
+type exporter struct {
+	cfg *config
+	now func() time.Time
+
+	mu sync.Mutex
+	// sdrCaches holds the sensor records of each target, keyed by its
+	// host:port, across scrapes.
+	sdrCaches map[string]*sdrCacheEntry
+}
+
+// sdrCacheEntry is the sensor records of one target and when it was last
+// scraped.
+type sdrCacheEntry struct {
+	cache *client.SDRCache
+	used  time.Time
+}
+
+func newExporter(cfg *config) *exporter {
+	return &exporter{cfg: cfg, now: time.Now, sdrCaches: map[string]*sdrCacheEntry{}}
+}
+
+// sdrCache returns the sensor records of target, dropping those of the
+// targets not scraped within sdrCacheTTL and, past maxSDRCaches targets,
+// those of the least recently scraped.
+func (e *exporter) sdrCache(target string) *client.SDRCache {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	for t, entry := range e.sdrCaches {
+		if now.Sub(entry.used) > sdrCacheTTL {
+			delete(e.sdrCaches, t)
+		}
+	}
+	entry, ok := e.sdrCaches[target]
+	if !ok {
+		for len(e.sdrCaches) >= maxSDRCaches {
+			e.evictOldestLocked()
+		}
+		entry = &sdrCacheEntry{cache: client.NewSDRCache()}
+		e.sdrCaches[target] = entry
+	}
+	entry.used = now
+	return entry.cache
+}
+
+// evictOldestLocked drops the sensor records of the least recently scraped
+// target. The caller holds e.mu.
+func (e *exporter) evictOldestLocked() {
+	var oldest string
+	var oldestUsed time.Time
+	for t, entry := range e.sdrCaches {
+		if oldest == "" || entry.used.Before(oldestUsed) {
+			oldest, oldestUsed = t, entry.used
+		}
+	}
+	delete(e.sdrCaches, oldest)
+}
+
+func (e *exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	moduleName := r.URL.Query().Get("module")
+	if moduleName == "" {
+		moduleName = defaultModule
+	}
+	mod, ok := e.cfg.Modules[moduleName]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown module %q", moduleName), http.StatusBadRequest)
+		return
+	}
+
+	target := r.URL.Query().Get("target")
+	var host string
+	var port int
+	if target != "" {
+		var err error
+		if host, port, err = splitTarget(target); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx := r.Context()
+	// Finish within the scrape timeout Prometheus announces, leaving it
+	// time to receive the response.
+	if v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil && secs > 1 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration((secs-0.5)*float64(time.Second)))
+			defer cancel()
+		}
+	}
+
+	m := newMetrics()
+	e.scrape(ctx, m, mod, host, port)
+
+	var buf bytes.Buffer
+	if err := m.writeTo(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", promtext.ContentType)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// splitTarget splits a target into its host and port, the port defaulting
+// to the RMCP port.
+func splitTarget(target string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(target)
+	if err != nil {
+		// No port: a bare host name or IPv4 address, or an IPv6 address
+		// with or without brackets.
+		host = target
+		if len(host) > 1 && host[0] == '[' && host[len(host)-1] == ']' {
+			host = host[1 : len(host)-1]
+		}
+		return host, defaultPort, nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("target %q: invalid port %q", target, portStr)
+	}
+	return host, port, nil
+}
+
+// scrape opens a session to the BMC at host:port, or the local BMC when host
+// is empty, and runs the module's collectors.
+func (e *exporter) scrape(ctx context.Context, m *metrics, mod *module, host string, port int) {
+	start := time.Now()
+	defer func() {
+		m.gauge("ipmi_scrape_duration_seconds", "Time the scrape of the BMC took.", time.Since(start).Seconds())
+	}()
+
+	c, err := e.newClient(mod, host, port)
+	if err == nil {
+		err = c.Connect(ctx)
+	}
+	if err != nil {
+		logf(host, "connect: %v", err)
+		for _, col := range collectors {
+			if mod.enabled(col.name) {
+				m.gauge("ipmi_up", upHelp, 0, label{"collector", col.name})
+			}
+		}
+		return
+	}
+	defer c.Close(context.Background()) //nolint:errcheck
+
+	for _, col := range collectors {
+		if !mod.enabled(col.name) {
+			continue
+		}
+		err := col.collect(ctx, c, m)
+		if err != nil {
+			logf(host, "collector %s: %v", col.name, err)
+		}
+		m.gauge("ipmi_up", upHelp, boolValue(err == nil), label{"collector", col.name})
+	}
+}
+
+const upHelp = "'1' if the collector's commands succeeded against the BMC, '0' otherwise."
+
+func (e *exporter) newClient(mod *module, host string, port int) (*client.Client, error) {
+	if host == "" {
+		c, err := client.NewOpenClient()
+		if err != nil {
+			return nil, err
+		}
+		return c.WithSDRCache(e.sdrCache("local")), nil
+	}
+	c, err := client.NewClient(host, port, mod.User, mod.Password)
+	if err != nil {
+		return nil, err
+	}
+	c.WithInterface(mod.intf).
+		WithTimeout(mod.timeout).
+		WithMaxPrivilegeLevel(mod.privilege).
+		WithSDRCache(e.sdrCache(net.JoinHostPort(host, strconv.Itoa(port))))
+	if mod.Retries != nil {
+		c.WithRetry(*mod.Retries)
+	}
+	return c, nil
+}
+
+func logf(host, format string, args ...any) {
+	if host == "" {
+		host = "local"
+	}
+	fmt.Fprintf(os.Stderr, "goipmi-exporter: %s: %s\n", host, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/server"
+	"github.com/bougou/go-ipmi/pkg/transport/udp"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+const (
+	testUser = "monitor"
+	testPass = "monitorpass"
+)
+
+// startTestBMC serves a BMC with an inlet temperature sensor, a power meter
+// and a user-privileged monitoring user over RMCP+ on a loopback port, and
+// returns its address.
+func startTestBMC(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+
+	h := mock.New()
+	h.Sensors().(*mock.Sensors).Values = map[uint8]uint8{1: 47}
+	h.Power().(*mock.Power).SetWatts(150)
+	inlet := &types.SDRFull{
+		GeneratorID:            0x20,
+		SensorNumber:           1,
+		SensorType:             types.SensorTypeTemperature,
+		SensorEventReadingType: types.EventReadingTypeThreshold,
+		SensorInitialization:   types.SensorInitialization{InitScanning: true},
+		SensorCapabilities:     types.SensorCapabilities{ThresholdAccess: types.SensorThresholdAccess_Readable},
+		SensorUnit:             types.SensorUnit{BaseUnit: types.SensorUnitType_DegreesC},
+		ReadingFactors:         types.ReadingFactors{M: 1},
+		UNC_Raw:                40,
+		UCR_Raw:                45,
+		IDStringBytes:          []byte("Inlet Temp"),
+	}
+	inlet.Mask.Threshold.UNC = types.Mask_Threshold{Readable: true, StatusReturned: true}
+	inlet.Mask.Threshold.UCR = types.Mask_Threshold{Readable: true, StatusReturned: true}
+	if err := h.Storage().SDR().Write(ctx, 1, inlet.Pack(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	b := bmc.New(bmc.DeviceInfo{IPMIVersion: 0x02, FirmwareMajor: 1, FirmwareMinor: 0x23, ManufacturerID: 0x000157},
+		[16]byte{1, 2, 3}, h, bmc.WithClock(clock.Real))
+	user, err := b.Users.Add(2, testUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.SetPassword([]byte(testPass))
+	user.Enabled = true
+	user.ChannelAccess[1] = bmc.UserChannelAccess{MaxPrivilege: bmc.PrivilegeLevelUser, Enabled: true}
+
+	pc, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	if err != nil {
+		t.Fatalf("udp listen: %v", err)
+	}
+	t.Cleanup(func() { _ = pc.Close() })
+	srv := server.NewServer(b, udp.Wrap(pc))
+	srvCtx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+	go func() {
+		_ = srv.Serve(srvCtx)
+	}()
+	return pc.LocalAddr().String()
+}
+
+func writeTestConfig(t *testing.T, body string) *config {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "ipmi.json")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func scrape(t *testing.T, e *exporter, query string) (int, string) {
+	t.Helper()
+
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/metrics?" + query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestScrape(t *testing.T) {
+	addr := startTestBMC(t)
+	cfg := writeTestConfig(t, `{"modules": {
+		"monitor": {"user": "`+testUser+`", "password": "`+testPass+`", "retries": 0},
+		"wrong": {"user": "`+testUser+`", "password": "nope", "retries": 0, "collectors": ["chassis"]}
+	}}`)
+	e := newExporter(cfg)
+
+	code, body := scrape(t, e, "module=monitor&target="+addr)
+	if code != http.StatusOK {
+		t.Fatalf("status %d: %s", code, body)
+	}
+	for _, want := range []string{
+		`ipmi_up{collector="bmc"} 1`,
+		`ipmi_up{collector="chassis"} 1`,
+		`ipmi_up{collector="sensor"} 1`,
+		`ipmi_up{collector="dcmi"} 1`,
+		`ipmi_up{collector="sel"} 1`,
+		`firmware_revision="1.23"`,
+		`ipmi_version="2.0"`,
+		`manufacturer_id="343"`,
+		`ipmi_chassis_power_state 0`,
+		`ipmi_chassis_intrusion_state 0`,
+		`ipmi_sensor_value{id="1",owner="0x20",lun="0",name="Inlet Temp",type="Temperature",unit="degrees C"} 47`,
+		`ipmi_sensor_state{id="1",owner="0x20",lun="0",name="Inlet Temp",type="Temperature"} 2`,
+		`ipmi_dcmi_power_consumption_watts 150`,
+		`ipmi_sel_logs_count 0`,
+		"# TYPE ipmi_sensor_value gauge",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape lacks %q:\n%s", want, body)
+		}
+	}
+	if entry := e.sdrCaches[addr]; entry == nil || entry.cache.Len() != 1 {
+		t.Errorf("SDR cache for %s not filled with the sensor record", addr)
+	}
+
+	// A failed session marks the module's collectors down; the scrape
+	// itself still succeeds.
+	code, body = scrape(t, e, "module=wrong&target="+addr)
+	if code != http.StatusOK || !strings.Contains(body, `ipmi_up{collector="chassis"} 0`) || strings.Contains(body, `collector="sensor"`) {
+		t.Errorf("bad credentials: status %d:\n%s", code, body)
+	}
+
+	if code, _ := scrape(t, e, "module=missing&target="+addr); code != http.StatusBadRequest {
+		t.Errorf("unknown module: status %d, want 400", code)
+	}
+}
+
+// TestSDRCacheEviction verifies the sensor records of targets not scraped
+// for sdrCacheTTL are dropped, and past maxSDRCaches targets those of the
+// least recently scraped.
+func TestSDRCacheEviction(t *testing.T) {
+	now := time.Unix(0, 0)
+	e := newExporter(&config{})
+	e.now = func() time.Time { return now }
+
+	first := e.sdrCache("a:623")
+	if e.sdrCache("a:623") != first {
+		t.Fatal("records of a target not kept across scrapes")
+	}
+	now = now.Add(sdrCacheTTL / 2)
+	e.sdrCache("b:623")
+	now = now.Add(sdrCacheTTL/2 + time.Second)
+	e.sdrCache("c:623")
+	if _, ok := e.sdrCaches["a:623"]; ok {
+		t.Error("records of a stale target kept")
+	}
+	if _, ok := e.sdrCaches["b:623"]; !ok {
+		t.Error("records of a recent target dropped")
+	}
+
+	for i := len(e.sdrCaches); i < maxSDRCaches; i++ {
+		now = now.Add(time.Millisecond)
+		e.sdrCache(fmt.Sprintf("10.0.%d.%d:623", i/256, i%256))
+	}
+	e.sdrCache("d:623")
+	if len(e.sdrCaches) != maxSDRCaches {
+		t.Fatalf("%d targets cached, want %d", len(e.sdrCaches), maxSDRCaches)
+	}
+	if _, ok := e.sdrCaches["b:623"]; ok {
+		t.Error("records of the least recently scraped target kept")
+	}
+}
+
+func TestSplitTarget(t *testing.T) {
+	for _, tc := range []struct {
+		target string
+		host   string
+		port   int
+	}{
+		{"10.0.0.5", "10.0.0.5", 623},
+		{"10.0.0.5:6230", "10.0.0.5", 6230},
+		{"bmc.example.com", "bmc.example.com", 623},
+		{"::1", "::1", 623},
+		{"[::1]", "::1", 623},
+		{"[::1]:6230", "::1", 6230},
+	} {
+		host, port, err := splitTarget(tc.target)
+		if err != nil || host != tc.host || port != tc.port {
+			t.Errorf("splitTarget(%q) = %q, %d, %v; want %q, %d", tc.target, host, port, err, tc.host, tc.port)
+		}
+	}
+	if _, _, err := splitTarget("10.0.0.5:http"); err == nil {
+		t.Error("splitTarget accepted a non-numeric port")
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	for _, body := range []string{
+		`{"modules": {}}`,
+		`{"modules": {"m": {"interface": "serial"}}}`,
+		`{"modules": {"m": {"privilege": "oem"}}}`,
+		`{"modules": {"m": {"timeout": "soon"}}}`,
+		`{"modules": {"m": {"collectors": ["fru"]}}}`,
+		`{"modules": {"m": {"passwd": "x"}}}`,
+	} {
+		path := filepath.Join(t.TempDir(), "ipmi.json")
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadConfig(path); err == nil {
+			t.Errorf("loadConfig accepted %s", body)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// goipmi-exporter is a Prometheus exporter for BMCs, built on pkg/client. It
+// serves the IPMI view of a machine as metrics: sensor readings and threshold
+// status, chassis power and intrusion state, the DCMI power reading, the SEL
+// entry count and free space, and the BMC's identity.
+//
+// Like the snmp and blackbox exporters, one exporter serves many BMCs: each
+// scrape names its target and a module, the credentials and session settings
+// to reach it with.
+//
+//	/metrics?target=10.0.0.5&module=default      the BMC at 10.0.0.5:623
+//	/metrics?target=10.0.0.5:6230                 another port, module "default"
+//	/metrics                                      this host's BMC, in-band (open interface)
+//
+// A Prometheus job scrapes it through relabeling:
+//
+//	scrape_configs:
+//	  - job_name: ipmi
+//	    metrics_path: /metrics
+//	    params: {module: [default]}
+//	    static_configs:
+//	      - targets: [10.0.0.5, 10.0.0.6]
+//	    relabel_configs:
+//	      - source_labels: [__address__]
+//	        target_label: __param_target
+//	      - source_labels: [__param_target]
+//	        target_label: instance
+//	      - target_label: __address__
+//	        replacement: exporter-host:9290
+//
+// Modules come from a JSON file (-config.file):
+//
+//	{
+//	  "modules": {
+//	    "default": {
+//	      "user": "ADMIN",
+//	      "password": "ADMIN",
+//	      "interface": "lanplus",
+//	      "privilege": "user",
+//	      "timeout": "2s",
+//	      "retries": 2,
+//	      "collectors": ["bmc", "chassis", "sensor", "dcmi", "sel"]
+//	    }
+//	  }
+//	}
+//
+// Every field is optional; without a file, the only module is "default" with
+// the anonymous user. The SDR repository of each target is cached between
+// scrapes and read again only when Get SDR Repository Info reports a change,
+// so a scrape costs one session and the sensor reads. A target's records are
+// dropped an hour after its last scrape, and the least recently scraped go
+// first past 1024 targets. Sensor series are labeled with the sensor's
+// owner and LUN as well as its number, which is unique only per owner LUN.
+//
+// Usage:
+//
+//	goipmi-exporter -config.file ipmi.json -web.listen-address :9290
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+func main() {
+	listen := flag.String("web.listen-address", ":9290", "address to serve /metrics on")
+	configFile := flag.String("config.file", "", "JSON file of modules; unset = an anonymous \"default\" module")
+	flag.Parse()
+
+	cfg := defaultConfig()
+	if *configFile != "" {
+		var err error
+		if cfg, err = loadConfig(*configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "goipmi-exporter: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", newExporter(cfg))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, "goipmi-exporter: scrape /metrics?target=<host[:port]>&module=<name>")
+	})
+
+	fmt.Printf("goipmi-exporter: listening on %s (modules: %s)\n", *listen, cfg.moduleNames())
+	if err := http.ListenAndServe(*listen, mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "goipmi-exporter: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io"
+
+	"github.com/bougou/go-ipmi/internal/promtext"
+)
+
+// metrics collects the samples of one scrape and writes them in the
+// Prometheus text exposition format (version 0.0.4). Samples are grouped by
+// metric family as the format requires, whatever order they are added in.
+type metrics struct {
+	families []*family
+	byName   map[string]*family
+}
+
+type family struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+type sample struct {
+	value  float64
+	labels []label
+}
+
+// label is one label name and value of a sample.
+type label struct {
+	name, value string
+}
+
+func newMetrics() *metrics {
+	return &metrics{byName: map[string]*family{}}
+}
+
+// gauge adds a sample of the gauge name.
+func (m *metrics) gauge(name, help string, value float64, labels ...label) {
+	f, ok := m.byName[name]
+	if !ok {
+		f = &family{name: name, help: help}
+		m.byName[name] = f
+		m.families = append(m.families, f)
+	}
+	f.samples = append(f.samples, sample{value: value, labels: labels})
+}
+
+// writeTo writes every family with its HELP and TYPE lines.
+func (m *metrics) writeTo(w io.Writer) error {
+	var pw promtext.Writer
+	for _, f := range m.families {
+		pw.Family(f.name, "gauge", f.help)
+		for _, s := range f.samples {
+			labels := make([]promtext.Label, len(s.labels))
+			for i, l := range s.labels {
+				labels[i] = promtext.Label{Name: l.name, Value: l.value}
+			}
+			pw.Sample(f.name, s.value, labels...)
+		}
+	}
+	_, err := pw.WriteTo(w)
+	return err
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// openAuditLog opens the audit log dest names: a file, appended to, or "-"
+// for stderr. Records are JSON lines, one per event, so they can be shipped
+// and queried as they are. The returned func closes the file.
+func openAuditLog(dest string) (*slog.Logger, func() error, error) {
+	if dest == "-" {
+		return newAuditLogger(os.Stderr), func() error { return nil }, nil
+	}
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return newAuditLogger(f), f.Close, nil
+}
+
+func newAuditLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, nil))
+}
+
+// auditDest describes dest for the startup banner.
+func auditDest(dest string) string {
+	if dest == "-" {
+		return "stderr"
+	}
+	return dest
+}
@@ -20,12 +20,32 @@ type runtimeConfig struct {
 	V15AuthTypes []bmc.V15AuthType // nil = default (md5)
 	V15Disabled  bool
 	Trace        bool
+	// FRUValidate checks Write FRU Data against the FRU format (header and
+	// area checksums) instead of accepting raw writes.
+	FRUValidate bool
+	// Satellite serves the reference sensor as a Device SDR instead of from
+	// the SDR repository, emulating a satellite management controller.
+	Satellite bool
 
 	// VMSocket is a unix socket path on which to also serve the OpenIPMI VM
 	// protocol (QEMU's ipmi-bmc-extern), sharing one BMC with the network
 	// server. Empty = VM protocol not served.
 	VMSocket string
 
+	// Redfish is a TCP address (e.g. ":8000") on which to also serve Redfish
+	// over HTTP, sharing one BMC with the network server. Empty = Redfish
+	// not served.
+	Redfish string
+
+	// Metrics is a TCP address (e.g. ":9291") on which to serve the server's
+	// command, session and packet metrics at /metrics in the Prometheus text
+	// format. Empty = not served.
+	Metrics string
+
+	// Audit is the file audit records are appended to as JSON lines, or
+	// "-" for stderr. Empty = no audit log.
+	Audit string
+
 	// Console selects the SOL console backend: ""/none = no console (SOL
 	// unadvertised), "pty" = allocate a PTY pair, otherwise a device path.
 	Console string
@@ -33,6 +53,14 @@ type runtimeConfig struct {
 	// console failure the server retries the HAL Open on an exponential
 	// backoff, keeping the SOL payload alive across the outage.
 	Reconnect bool
+
+	// Hwmon is the sysfs hwmon root whose sensors are served instead of the
+	// simulated one. Empty = the simulated inlet temperature.
+	Hwmon string
+
+	// StateDir is the directory keeping the storage and the non-volatile BMC
+	// state across restarts. Empty = everything in memory.
+	StateDir string
 }
 
 func loadRuntimeConfig() (runtimeConfig, error) {
@@ -41,7 +69,12 @@ func loadRuntimeConfig() (runtimeConfig, error) {
 		User:     envOr("GOIPMI_SERVER_USER", "ADMIN"),
 		Password: envOr("GOIPMI_SERVER_PASS", "ADMIN"),
 		VMSocket: envOr("GOIPMI_SERVER_VM_SOCKET", ""),
+		Redfish:  envOr("GOIPMI_SERVER_REDFISH", ""),
+		Metrics:  envOr("GOIPMI_SERVER_METRICS", ""),
+		Audit:    envOr("GOIPMI_SERVER_AUDIT", ""),
 		Console:  envOr("GOIPMI_SERVER_CONSOLE", ""),
+		Hwmon:    envOr("GOIPMI_SERVER_HWMON", ""),
+		StateDir: envOr("GOIPMI_SERVER_STATE_DIR", ""),
 	}
 	// "none" is the documented spelling of "no console" (see Console); the
 	// HAL layer only understands the empty string, and a raw "none" would
@@ -82,6 +115,22 @@ func loadRuntimeConfig() (runtimeConfig, error) {
 		cfg.Trace = trace
 	}
 
+	if v := strings.TrimSpace(os.Getenv("GOIPMI_SERVER_FRU_VALIDATE")); v != "" {
+		validate, err := parseBoolEnv(v)
+		if err != nil {
+			return cfg, fmt.Errorf("GOIPMI_SERVER_FRU_VALIDATE: %w", err)
+		}
+		cfg.FRUValidate = validate
+	}
+
+	if v := strings.TrimSpace(os.Getenv("GOIPMI_SERVER_SATELLITE")); v != "" {
+		satellite, err := parseBoolEnv(v)
+		if err != nil {
+			return cfg, fmt.Errorf("GOIPMI_SERVER_SATELLITE: %w", err)
+		}
+		cfg.Satellite = satellite
+	}
+
 	if raw := strings.TrimSpace(os.Getenv("GOIPMI_SERVER_V15_AUTH_TYPES")); raw != "" {
 		types, err := bmc.ParseV15AuthTypes(raw)
 		if err != nil {
@@ -106,6 +155,9 @@ func applyRuntimeConfig(b *bmc.BMC, cfg runtimeConfig) {
 	if cfg.Reconnect {
 		b.SOL.SetReconnectPolicy(&bmc.DefaultReconnectPolicy)
 	}
+	if fru := b.FRUInventory(); fru != nil {
+		fru.SetValidateWrites(cfg.FRUValidate)
+	}
 }
 
 func printRuntimeBanner(cfg runtimeConfig, b *bmc.BMC, consoleDesc string) {
@@ -119,12 +171,30 @@ func printRuntimeBanner(cfg runtimeConfig, b *bmc.BMC, consoleDesc string) {
 	if cfg.VMSocket != "" {
 		fmt.Printf("goipmi-server: OpenIPMI VM protocol on unix socket %s\n", cfg.VMSocket)
 	}
+	if cfg.Redfish != "" {
+		fmt.Printf("goipmi-server: Redfish on http://%s/redfish/v1\n", cfg.Redfish)
+	}
+	if cfg.Metrics != "" {
+		fmt.Printf("goipmi-server: metrics on http://%s/metrics\n", cfg.Metrics)
+	}
 	if consoleDesc != "" {
 		fmt.Printf("goipmi-server: console %s\n", consoleDesc)
 	}
+	if cfg.Hwmon != "" {
+		fmt.Printf("goipmi-server: hwmon sensors from %s\n", cfg.Hwmon)
+	}
+	if cfg.StateDir != "" {
+		fmt.Printf("goipmi-server: state kept in %s\n", cfg.StateDir)
+	}
+	if cfg.Audit != "" {
+		fmt.Printf("goipmi-server: audit log to %s\n", auditDest(cfg.Audit))
+	}
 	if cfg.Trace {
 		fmt.Println("goipmi-server: per-command trace enabled (stderr)")
 	}
+	if cfg.Satellite {
+		fmt.Println("goipmi-server: satellite controller, sensors served as Device SDRs")
+	}
 }
 
 func envOr(key, fallback string) string {
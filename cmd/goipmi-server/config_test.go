@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"testing"
 
 	"github.com/bougou/go-ipmi/pkg/bmc"
@@ -119,6 +120,92 @@ func TestLoadRuntimeConfigDisableV15(t *testing.T) {
 	}
 }
 
+func TestLoadRuntimeConfigFRUValidate(t *testing.T) {
+	t.Setenv("GOIPMI_SERVER_FRU_VALIDATE", "1")
+
+	cfg, err := loadRuntimeConfig()
+	if err != nil {
+		t.Fatalf("loadRuntimeConfig: %v", err)
+	}
+	if !cfg.FRUValidate {
+		t.Fatal("expected FRU write validation enabled")
+	}
+
+	t.Setenv("GOIPMI_SERVER_FRU_VALIDATE", "nonsense")
+	if _, err := loadRuntimeConfig(); err == nil {
+		t.Fatal("expected an error for an unparseable GOIPMI_SERVER_FRU_VALIDATE")
+	}
+}
+
+func TestReferenceSatellite(t *testing.T) {
+	t.Setenv("GOIPMI_SERVER_SATELLITE", "1")
+	cfg, err := loadRuntimeConfig()
+	if err != nil {
+		t.Fatalf("loadRuntimeConfig: %v", err)
+	}
+	if !cfg.Satellite {
+		t.Fatal("expected satellite emulation enabled")
+	}
+
+	ctx := context.Background()
+	h := mock.New()
+	sensors := []*types.SDRFull{referenceTemperatureSDR()}
+	seedReferenceStorage(ctx, h, cfg.Satellite, sensors, false)
+	if ids, _ := h.Storage().SDR().RecordIDs(ctx); len(ids) != 0 {
+		t.Fatalf("satellite SDR repository records: %v", ids)
+	}
+	b := bmc.New(bmc.DeviceInfo{}, [16]byte{}, h, bmc.WithDeviceSDRs(referenceDeviceSDRs(ctx, sensors)))
+	info, err := b.DeviceSDRs.Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.SDRCount != 1 || info.SensorCount[0] != 1 {
+		t.Fatalf("device SDR info: %+v", info)
+	}
+	if _, err := b.Sensors.Get(ctx, 0, referenceSensorNumber); err != nil {
+		t.Fatalf("reference sensor: %v", err)
+	}
+}
+
+// TestSeedReferenceStorageReplacesSensors verifies storage kept from an
+// earlier run keeps its sensor records, unless they are to be replaced, as
+// they are when served from hwmon.
+func TestSeedReferenceStorageReplacesSensors(t *testing.T) {
+	ctx := context.Background()
+	h := mock.New()
+	first := referenceTemperatureSDR()
+	seedReferenceStorage(ctx, h, false, []*types.SDRFull{first}, false)
+
+	second := referenceTemperatureSDR()
+	second.SensorNumber = 0x21
+	third := referenceTemperatureSDR()
+	third.SensorNumber = 0x22
+	seedReferenceStorage(ctx, h, false, []*types.SDRFull{second, third}, false)
+	sdr := h.Storage().SDR()
+	if ids, _ := sdr.RecordIDs(ctx); len(ids) != 2 {
+		t.Fatalf("records kept: %v", ids)
+	}
+
+	seedReferenceStorage(ctx, h, false, []*types.SDRFull{second, third}, true)
+	ids, err := sdr.RecordIDs(ctx)
+	if err != nil || len(ids) != 3 {
+		t.Fatalf("records after replacing the sensors: %v, %v", ids, err)
+	}
+	var numbers []uint8
+	for _, id := range ids {
+		data, err := sdr.Read(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if types.SDRRecordType(data[3]) == types.SDRRecordTypeFullSensor {
+			numbers = append(numbers, data[7])
+		}
+	}
+	if len(numbers) != 2 || numbers[0] != 0x21 || numbers[1] != 0x22 {
+		t.Fatalf("sensor numbers %#x", numbers)
+	}
+}
+
 // TestLoadRuntimeConfigConsoleNone verifies the documented "none" spelling
 // of "no console" normalizes to the empty string instead of being opened as
 // a device path (which would fail startup with ENOENT).
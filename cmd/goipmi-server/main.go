@@ -14,12 +14,27 @@
 //	GOIPMI_SERVER_V15             – set to 0/false to disable v1.5 while keeping lanplus (default: 1)
 //	GOIPMI_SERVER_VM_SOCKET       – unix socket to also serve the OpenIPMI VM protocol
 //	                                (QEMU ipmi-bmc-extern), sharing one BMC; unset = off
+//	GOIPMI_SERVER_REDFISH         – TCP address (e.g. :8000) to also serve Redfish over HTTP,
+//	                                sharing one BMC; unset = off
+//	GOIPMI_SERVER_METRICS         – TCP address (e.g. :9291) to serve command, session and packet
+//	                                metrics at /metrics in the Prometheus text format; unset = off
 //	GOIPMI_SERVER_TRACE           – set to 1/true to log every dispatched command to stderr (default: 0)
+//	GOIPMI_SERVER_AUDIT           – file to append JSON audit records to (state-changing commands
+//	                                and Redfish operations, session establishment, failed
+//	                                authentication, session close); "-" = stderr; unset = off
+//	GOIPMI_SERVER_SATELLITE       – set to 1/true to serve the sensor as a Device SDR with no
+//	                                SDR repository, as a satellite controller (default: 0)
 //	GOIPMI_SERVER_CONSOLE         – SOL console backend: "pty" allocates a PTY pair (linux),
 //	                                a path opens that device (e.g. /dev/ttyS0); unset = no SOL
 //	GOIPMI_SERVER_SOL_RECONNECT   – set to 1/true to reconnect a failed SOL console
 //	                                automatically (default policy; default: 0/off)
 //	                                SIGUSR1/SIGUSR2 inject/clear a console fault (e2e, linux)
+//	GOIPMI_SERVER_HWMON           – sysfs hwmon root (e.g. /sys/class/hwmon) whose sensors are
+//	                                served instead of the simulated inlet temperature; unset = off
+//	GOIPMI_SERVER_STATE_DIR       – directory keeping the FRU/SDR/SEL data and the non-volatile
+//	                                BMC state (users, channel access, SOL, LAN, boot options,
+//	                                command enables, PEF, alerting, DCMI) across restarts;
+//	                                unset = all in memory
 package main
 
 import (
@@ -27,6 +42,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -35,8 +51,11 @@ import (
 	"github.com/bougou/go-ipmi/pkg/bmc"
 	"github.com/bougou/go-ipmi/pkg/clock"
 	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/hal/filestore"
+	"github.com/bougou/go-ipmi/pkg/hal/hwmon"
 	"github.com/bougou/go-ipmi/pkg/hal/mock"
 	"github.com/bougou/go-ipmi/pkg/handlers"
+	"github.com/bougou/go-ipmi/pkg/redfish"
 	"github.com/bougou/go-ipmi/pkg/server"
 	"github.com/bougou/go-ipmi/pkg/transport/udp"
 	"github.com/bougou/go-ipmi/pkg/types"
@@ -64,13 +83,33 @@ func run() error {
 		IPMIVersion:             0x20,
 		ManufacturerID:          0x000157,
 		ProductID:               0x0001,
-		AdditionalDeviceSupport: 0x39, // Sensor+FRU+IPMB Rx/Tx; no SEL (unimplemented); SDR OR'd when present
+		AdditionalDeviceSupport: 0x39, // Sensor+FRU+IPMB Rx/Tx; SDR and SEL OR'd when present
 	}
 	var guid [16]byte
 	copy(guid[:], "go-ipmi-e2e\x00\x00\x00\x00")
 
 	halImpl := mock.New()
-	seedReferenceStorage(context.Background(), halImpl)
+	if cfg.StateDir != "" {
+		storage, err := filestore.Open(cfg.StateDir)
+		if err != nil {
+			return fmt.Errorf("state dir: %w", err)
+		}
+		halImpl.SetStorage(storage)
+	}
+	// The host's hwmon sensors, when asked for, replace the simulated one.
+	sensorSDRs := []*types.SDRFull{referenceTemperatureSDR()}
+	if cfg.Hwmon != "" {
+		sensors, err := hwmon.Open(cfg.Hwmon)
+		if err != nil {
+			return err
+		}
+		halImpl.SetSensors(sensors)
+		sensorSDRs = sensors.SDRs()
+	} else {
+		halImpl.Sensors().(*mock.Sensors).Values = map[uint8]uint8{referenceSensorNumber: 25}
+	}
+	seedReferenceStorage(context.Background(), halImpl, cfg.Satellite, sensorSDRs, cfg.Hwmon != "")
+	halImpl.Power().(*mock.Power).SetWatts(referencePowerWatts)
 
 	consoleDesc := ""
 	if cfg.Console != "" {
@@ -87,18 +126,43 @@ func run() error {
 		startConsoleFaultInjection()
 	}
 
-	b := bmc.New(info, guid, halImpl, bmc.WithClock(clock.Real))
+	bmcOpts := []bmc.Option{
+		bmc.WithClock(clock.Real),
+		bmc.WithStateErrorHandler(func(key string, err error) {
+			fmt.Fprintf(os.Stderr, "goipmi-server: state %s: %v\n", key, err)
+		}),
+	}
+	if cfg.Satellite {
+		bmcOpts = append(bmcOpts, bmc.WithDeviceSDRs(referenceDeviceSDRs(context.Background(), sensorSDRs)))
+	}
+	// With a VM attached, Chassis Control goes to it as the VM protocol's
+	// control commands; the mock keeps the power state either way.
+	var platform hal.HAL = halImpl
+	var machine *vmproto.Machine
+	if cfg.VMSocket != "" {
+		machine = vmproto.NewMachine(halImpl)
+		platform = machine
+	}
+	b := bmc.New(info, guid, platform, bmcOpts...)
 	applyRuntimeConfig(b, cfg)
 
-	user, err := b.Users.Add(2, cfg.User)
-	if err != nil {
-		return fmt.Errorf("add user: %w", err)
-	}
-	user.SetPassword([]byte(cfg.Password))
-	user.Enabled = true
-	user.ChannelAccess[1] = bmc.UserChannelAccess{
-		MaxPrivilege: bmc.PrivilegeLevelAdministrator,
-		Enabled:      true,
+	// The configured user is only the initial one: with a state dir, the
+	// users saved there, changed or not, win over the environment. Upsert
+	// saves the seeded user like any other change.
+	if _, err := b.Users.Get(2); errors.Is(err, bmc.ErrUserNotFound) {
+		err := b.Users.Upsert(2, func(u *bmc.User) error {
+			u.Name = cfg.User
+			u.SetPassword([]byte(cfg.Password))
+			u.Enabled = true
+			u.ChannelAccess[1] = bmc.UserChannelAccess{
+				MaxPrivilege: bmc.PrivilegeLevelAdministrator,
+				Enabled:      true,
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("add user: %w", err)
+		}
 	}
 
 	addr := ":" + cfg.Port
@@ -108,14 +172,31 @@ func run() error {
 	}
 	defer conn.Close()
 
-	// One registry shared by both frontends when tracing, so VM-protocol
-	// commands are traced too (the trace contract is "every dispatched
-	// command"). It is read-only during dispatch, so sharing it is safe.
-	var traceReg *handlers.Registry
+	// One registry shared by both frontends when tracing or auditing, so
+	// VM-protocol commands are traced and audited too (the trace contract is
+	// "every dispatched command"). It is read-only during dispatch, so sharing
+	// it is safe.
+	var sharedReg *handlers.Registry
+	var middleware []handlers.Middleware
 	var opts []server.ServerOption
+	var rfOpts []redfish.Option
+	if cfg.Audit != "" {
+		logger, closeAudit, err := openAuditLog(cfg.Audit)
+		if err != nil {
+			return err
+		}
+		defer closeAudit() //nolint:errcheck
+		middleware = append(middleware, handlers.AuditLog(logger))
+		opts = append(opts, server.WithAuditLogger(logger))
+		rfOpts = append(rfOpts, redfish.WithAuditLogger(logger))
+	}
 	if cfg.Trace {
-		traceReg = tracingRegistry()
-		opts = append(opts, server.WithHandlerRegistry(traceReg), server.WithSOLDebug())
+		middleware = append(middleware, traceCommands)
+		opts = append(opts, server.WithSOLDebug())
+	}
+	if len(middleware) > 0 {
+		sharedReg = commandRegistry(middleware...)
+		opts = append(opts, server.WithHandlerRegistry(sharedReg))
 	}
 	srv := server.NewServer(b, conn, opts...)
 
@@ -141,9 +222,9 @@ func run() error {
 		defer ln.Close()
 		defer os.Remove(cfg.VMSocket) //nolint:errcheck
 
-		var vmOpts []vmproto.VMServerOption
-		if traceReg != nil {
-			vmOpts = append(vmOpts, vmproto.WithVMHandlerRegistry(traceReg))
+		vmOpts := []vmproto.VMServerOption{vmproto.WithMachine(machine)}
+		if sharedReg != nil {
+			vmOpts = append(vmOpts, vmproto.WithVMHandlerRegistry(sharedReg))
 		}
 		vmSrv := vmproto.NewVMServer(b, vmOpts...)
 		go func() {
@@ -153,6 +234,41 @@ func run() error {
 		}()
 	}
 
+	// Optionally serve Redfish alongside, on the same BMC, so fleet tooling
+	// sees one machine through either protocol.
+	if cfg.Redfish != "" {
+		ln, err := net.Listen("tcp", cfg.Redfish)
+		if err != nil {
+			return fmt.Errorf("listen redfish %s: %w", cfg.Redfish, err)
+		}
+		defer ln.Close()
+
+		rfSrv := redfish.NewServer(b, rfOpts...)
+		go func() {
+			if err := rfSrv.Serve(ctx, ln); err != nil {
+				fmt.Fprintf(os.Stderr, "goipmi-server: redfish serve: %v\n", err)
+			}
+		}()
+	}
+
+	// Optionally expose what the clients are doing: command counts and
+	// latency, handshakes, sessions, SOL traffic and dropped packets.
+	if cfg.Metrics != "" {
+		ln, err := net.Listen("tcp", cfg.Metrics)
+		if err != nil {
+			return fmt.Errorf("listen metrics %s: %w", cfg.Metrics, err)
+		}
+		defer ln.Close()
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", srv.MetricsHandler())
+		go func() {
+			if err := http.Serve(ln, mux); err != nil && !errors.Is(err, net.ErrClosed) {
+				fmt.Fprintf(os.Stderr, "goipmi-server: metrics serve: %v\n", err)
+			}
+		}()
+	}
+
 	printRuntimeBanner(cfg, b, consoleDesc)
 
 	if err := srv.Serve(ctx); err != nil && !errors.Is(err, context.Canceled) {
@@ -187,12 +303,71 @@ func prepareVMSocket(path string) error {
 	return nil
 }
 
-func seedReferenceStorage(ctx context.Context, h hal.HAL) {
+// referenceSensorNumber is the inlet temperature sensor described by the
+// seeded Full Sensor Record; the mock HAL reports 25 degrees C for it.
+const referenceSensorNumber = 0x01
+
+// referencePowerWatts is the platform power the mock power meter reports,
+// so that DCMI power readings have data.
+const referencePowerWatts = 120
+
+// referenceTemperatureSDR is a threshold temperature sensor (1 degree C per
+// count) with settable upper thresholds, so sensor commands can be exercised
+// end to end.
+func referenceTemperatureSDR() *types.SDRFull {
+	full := &types.SDRFull{
+		GeneratorID:            0x20,
+		SensorNumber:           referenceSensorNumber,
+		SensorEntityID:         0x37, // air inlet
+		SensorType:             types.SensorTypeTemperature,
+		SensorEventReadingType: types.EventReadingTypeThreshold,
+		SensorInitialization:   types.SensorInitialization{InitScanning: true, InitEvents: true},
+		SensorCapabilities: types.SensorCapabilities{
+			AutoRearm:           true,
+			HysteresisAccess:    types.SensorHysteresisAccess_ReadableSettable,
+			ThresholdAccess:     types.SensorThresholdAccess_ReadableSettable,
+			EventMessageControl: types.SensorEventMessageControl_PerThresholdState,
+		},
+		SensorUnit:            types.SensorUnit{BaseUnit: types.SensorUnitType_DegreesC},
+		ReadingFactors:        types.ReadingFactors{M: 1},
+		UNC_Raw:               40,
+		UCR_Raw:               45,
+		UNR_Raw:               50,
+		PositiveHysteresisRaw: 1,
+		NegativeHysteresisRaw: 1,
+		IDStringBytes:         []byte("Inlet Temp"),
+	}
+	for _, m := range []*types.Mask_Threshold{&full.Mask.Threshold.UNC, &full.Mask.Threshold.UCR, &full.Mask.Threshold.UNR} {
+		*m = types.Mask_Threshold{Readable: true, Settable: true, StatusReturned: true, High_Assert: true, High_Deassert: true}
+	}
+	return full
+}
+
+// referenceDeviceSDRs returns the static Device SDRs of a satellite
+// controller: its sensors.
+func referenceDeviceSDRs(ctx context.Context, sensors []*types.SDRFull) *bmc.DeviceSDRStore {
+	store := (&mock.Storage{}).SDR()
+	for i, sdr := range sensors {
+		id := uint16(i + 1)
+		if err := store.Write(ctx, id, sdr.Pack(id)); err != nil {
+			fmt.Fprintf(os.Stderr, "goipmi-server: seed reference device SDR: %v\n", err)
+		}
+	}
+	return bmc.NewDeviceSDRStore(store, false)
+}
+
+// seedReferenceStorage seeds the reference FRU and SDR repository, with the
+// records of the sensors after the MC locator. A satellite controller has no
+// SDR repository records; its sensors are Device SDRs instead. Storage kept
+// from an earlier run is left as it is, except that with replaceSensors the
+// Full Sensor Records kept are replaced by those of sensors: the host's
+// hwmon sensors may have changed since.
+func seedReferenceStorage(ctx context.Context, h hal.HAL, satellite bool, sensors []*types.SDRFull, replaceSensors bool) {
 	store := h.Storage()
 	if store == nil {
 		return
 	}
-	if fru := store.FRU(); fru != nil {
+	if fru := store.FRU(); fru != nil && !hasFRU(ctx, fru) {
 		fruData, err := types.PackFRU(types.FRUPackConfig{
 			Product: &types.FRUPackProduct{
 				Manufacturer: "go-ipmi",
@@ -207,11 +382,65 @@ func seedReferenceStorage(ctx context.Context, h hal.HAL) {
 			fmt.Fprintf(os.Stderr, "goipmi-server: seed reference FRU: %v\n", err)
 		}
 	}
-	if sdr := store.SDR(); sdr != nil {
+	sdr := store.SDR()
+	if sdr == nil || satellite {
+		return
+	}
+	if !hasRecords(ctx, sdr) {
 		if err := sdr.Write(ctx, 1, types.PackMCLocator(types.MCLocatorPackOpts{
 			RecordID: 1,
 		})); err != nil {
 			fmt.Fprintf(os.Stderr, "goipmi-server: seed reference SDR: %v\n", err)
 		}
+	} else if !replaceSensors {
+		return
+	}
+	if err := replaceSensorSDRs(ctx, sdr, sensors); err != nil {
+		fmt.Fprintf(os.Stderr, "goipmi-server: seed reference sensor SDR: %v\n", err)
 	}
 }
+
+// replaceSensorSDRs deletes the Full Sensor Records of sdr and writes those
+// of sensors under the free record IDs from 2 up, leaving the other records
+// where they are.
+func replaceSensorSDRs(ctx context.Context, sdr hal.SDRStore, sensors []*types.SDRFull) error {
+	ids, err := sdr.RecordIDs(ctx)
+	if err != nil {
+		return err
+	}
+	used := make(map[uint16]bool, len(ids))
+	for _, id := range ids {
+		data, err := sdr.Read(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(data) > 3 && types.SDRRecordType(data[3]) == types.SDRRecordTypeFullSensor {
+			if err := sdr.Delete(ctx, id); err != nil {
+				return err
+			}
+			continue
+		}
+		used[id] = true
+	}
+	id := uint16(2)
+	for _, full := range sensors {
+		for used[id] {
+			id++
+		}
+		if err := sdr.Write(ctx, id, full.Pack(id)); err != nil {
+			return err
+		}
+		id++
+	}
+	return nil
+}
+
+func hasFRU(ctx context.Context, fru hal.FRUStore) bool {
+	ids, err := fru.DeviceIDs(ctx)
+	return err == nil && len(ids) > 0
+}
+
+func hasRecords(ctx context.Context, sdr hal.SDRStore) bool {
+	ids, err := sdr.RecordIDs(ctx)
+	return err == nil && len(ids) > 0
+}
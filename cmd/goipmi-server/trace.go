@@ -10,13 +10,16 @@ import (
 	"github.com/bougou/go-ipmi/pkg/types"
 )
 
-// tracingRegistry builds the standard command set with [traceCommands] wrapped
-// around it. Middleware is not applied retroactively, so Use must come before
-// the handlers are registered — which is also why this cannot just decorate the
-// registry [server.NewServer] would have built by default.
-func tracingRegistry() *handlers.Registry {
+// commandRegistry builds the standard command set with middleware, such as
+// [traceCommands], wrapped around it. Middleware is not applied retroactively,
+// so Use must come before the handlers are registered — which is also why this
+// cannot just decorate the registry [server.NewServer] would have built by
+// default.
+func commandRegistry(middleware ...handlers.Middleware) *handlers.Registry {
 	reg := handlers.NewRegistry()
-	reg.Use(traceCommands)
+	for _, m := range middleware {
+		reg.Use(m)
+	}
 	handlers.RegisterAllHandlers(reg)
 	return reg
 }
@@ -0,0 +1,82 @@
+// Package promtext writes the Prometheus text exposition format (version
+// 0.0.4), for the server's and the exporter's /metrics endpoints.
+package promtext
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the Content-Type of a response in the format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Label is one label name and value of a sample.
+type Label struct {
+	Name, Value string
+}
+
+// Labels builds labels from name, value pairs.
+func Labels(pairs ...string) []Label {
+	labels := make([]Label, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		labels = append(labels, Label{Name: pairs[i], Value: pairs[i+1]})
+	}
+	return labels
+}
+
+// Writer buffers metric families in the order they are written. The format
+// wants each family's samples right after its HELP and TYPE lines; callers
+// write them in that order.
+type Writer struct {
+	b strings.Builder
+}
+
+// Family starts the metric family name of type typ ("counter", "gauge",
+// "summary", ...).
+func (w *Writer) Family(name, typ, help string) {
+	w.b.WriteString("# HELP " + name + " " + helpEscaper.Replace(help) + "\n")
+	w.b.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+// Sample writes one sample of the current family.
+func (w *Writer) Sample(name string, value float64, labels ...Label) {
+	w.b.WriteString(name)
+	if len(labels) > 0 {
+		w.b.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.b.WriteByte(',')
+			}
+			w.b.WriteString(l.Name + `="` + labelValueEscaper.Replace(l.Value) + `"`)
+		}
+		w.b.WriteByte('}')
+	}
+	w.b.WriteByte(' ')
+	w.b.WriteString(formatValue(value))
+	w.b.WriteByte('\n')
+}
+
+// WriteTo writes what was buffered to dst.
+func (w *Writer) WriteTo(dst io.Writer) (int64, error) {
+	n, err := io.WriteString(dst, w.b.String())
+	return int64(n), err
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
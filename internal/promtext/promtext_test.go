@@ -0,0 +1,29 @@
+package promtext
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var w Writer
+	w.Family("test_value", "gauge", "A value.\nWith a \\ in its help.")
+	w.Sample("test_value", 1.5, Labels("name", `say "hi"`+"\n", "path", `C:\`)...)
+	w.Sample("test_value", math.Inf(-1))
+	w.Sample("test_value", math.NaN(), Label{Name: "unit", Value: "°C"})
+
+	var b strings.Builder
+	if _, err := w.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP test_value A value.\nWith a \\ in its help.
+# TYPE test_value gauge
+test_value{name="say \"hi\"\n",path="C:\\"} 1.5
+test_value -Inf
+test_value{unit="°C"} NaN
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,607 @@
+package bmc
+
+// LAN alerting (v2.0§17.11, §30.7, §30.8): the LAN alert destinations and
+// community string (LAN Configuration Parameters #16-#19), and the alert
+// engine that sends Platform Event Traps to them for PEF alert policies and
+// Alert Immediate, retrying until the alert is acknowledged or its retries
+// run out. Traps leave the BMC through the [AlertSender] the server
+// installs.
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+const (
+	// DefaultLANAlertDestinations is the number of non-volatile LAN alert
+	// destinations, kept in addition to the volatile destination 0 used by
+	// Alert Immediate (v2.0 Table 23-4 #17).
+	DefaultLANAlertDestinations = 4
+
+	// DefaultCommunityString is the SNMP community traps are sent with until
+	// it is configured (v2.0 Table 23-4 #16).
+	DefaultCommunityString = "public"
+
+	// LANAlertPollInterval is how often [BMC.Run] polls the alert engine
+	// for acknowledge timeouts and retries.
+	LANAlertPollInterval = 100 * time.Millisecond
+)
+
+// LANAlertDestinationPET is the PET trap destination type (v2.0 Table 23-4
+// #18); 110b and 111b are OEM types the reference BMC cannot send.
+const LANAlertDestinationPET = 0x00
+
+// AlertStatus is the Alert Immediate status of a channel (v2.0§30.7).
+type AlertStatus uint8
+
+const (
+	AlertStatusNone        AlertStatus = 0x00
+	AlertStatusNormalEnd   AlertStatus = 0x01
+	AlertStatusFailedRetry AlertStatus = 0x02
+	AlertStatusFailedAck   AlertStatus = 0x03
+	AlertStatusInProgress  AlertStatus = 0xff
+)
+
+// lanAlertMaxJobs bounds the alerts in progress; PEF alerts past it are
+// dropped, as the events they report stay in the SEL.
+const lanAlertMaxJobs = 16
+
+// LAN alerting failures, mapped by the handlers to completion codes.
+var (
+	// ErrAlertInProgress → CodeAlertImmediateAlreadyInProgress (81h).
+	ErrAlertInProgress = errors.New("alert immediate already in progress")
+	// ErrAlertDestinationOutOfRange → CodeParameterOutOfRange (C9h): a
+	// destination selector past the configured destinations.
+	ErrAlertDestinationOutOfRange = errors.New("alert destination out of range")
+	// ErrAlertQueueFull → CodeNodeBusy.
+	ErrAlertQueueFull = errors.New("too many alerts in progress")
+)
+
+// LANAlertDestination is one LAN alert destination: its Destination Type
+// and Destination Addresses parameters (v2.0 Table 23-4 #18, #19).
+type LANAlertDestination struct {
+	// Acknowledged alerts succeed only once a PET Acknowledge arrives;
+	// unacknowledged ones succeed once sent.
+	Acknowledged bool
+	Type         uint8
+	// Timeout is the acknowledge timeout and retry interval in seconds,
+	// 0-based.
+	Timeout uint8
+	Retries uint8
+
+	UseBackupGateway bool
+	IP               [4]byte
+	MAC              [6]byte
+}
+
+// retryInterval returns how long to wait between attempts.
+func (d *LANAlertDestination) retryInterval() time.Duration {
+	return time.Duration(d.Timeout+1) * time.Second
+}
+
+// AlertSender transmits one SNMP trap message to the given IPv4 address.
+// It is installed by the server, which owns the LAN socket.
+type AlertSender func(ctx context.Context, ip [4]byte, trap []byte) error
+
+// AlertEvent is the event an alert reports.
+type AlertEvent struct {
+	Event    PlatformEvent
+	Severity types.PEFEventSeverity
+	// GUID, when non-nil, replaces the BMC GUID in the trap (PEF
+	// configuration parameter #10).
+	GUID *[16]byte
+}
+
+// alertStep is one destination of an alert: an alert policy table entry,
+// or the destination of an Alert Immediate.
+type alertStep struct {
+	channel uint8
+	dest    uint8
+	policy  types.PEFAlertPolicyAction
+}
+
+// alertJob is an alert in progress, working through its steps in order.
+type alertJob struct {
+	ev        AlertEvent
+	steps     []alertStep
+	next      int
+	notBefore time.Time
+
+	immediate bool // an Alert Immediate; its channel's status tracks it
+
+	// The destination being alerted, while waiting.
+	waiting  bool
+	channel  uint8
+	dest     LANAlertDestination
+	trap     types.PlatformEventTrap
+	tries    uint8
+	deadline time.Time
+	sendErr  bool // the last attempt could not be sent
+
+	// The outcome of the last destination alerted, for the alert policies
+	// of the steps after it.
+	attempted bool
+	succeeded bool
+	status    AlertStatus
+	lastChan  uint8
+	lastType  uint8
+
+	// skip, while set, passes over the steps it matches (policies 3h, 4h).
+	skip func(alertStep) bool
+}
+
+// alertSend is a trap to transmit for a job.
+type alertSend struct {
+	job  *alertJob
+	ip   [4]byte
+	trap []byte
+}
+
+// LANAlertStore holds the LAN alert configuration of the BMC's LAN channel
+// and runs the alerts in progress.
+type LANAlertStore struct {
+	mu       sync.Mutex
+	h        hal.HAL
+	clock    clock.Clock
+	channels *ChannelStore
+	info     DeviceInfo
+	guid     [16]byte
+	start    time.Time // sysUpTime counts from here
+	send     AlertSender
+
+	community [18]byte
+	dests     []LANAlertDestination
+
+	seq       uint16
+	jobs      []*alertJob
+	immediate map[uint8]AlertStatus
+
+	// onChange, when set, is told of each change to the community string
+	// or a non-volatile destination.
+	onChange func()
+}
+
+// NewLANAlertStore returns an alert store with DefaultLANAlertDestinations
+// non-volatile destinations, all unset, and the default community string.
+// Traps identify the system by info and guid.
+func NewLANAlertStore(h hal.HAL, clk clock.Clock, channels *ChannelStore, info DeviceInfo, guid [16]byte) *LANAlertStore {
+	if clk == nil {
+		clk = clock.Real
+	}
+	a := &LANAlertStore{
+		h:         h,
+		clock:     clk,
+		channels:  channels,
+		info:      info,
+		guid:      guid,
+		start:     clk.Now(),
+		dests:     make([]LANAlertDestination, DefaultLANAlertDestinations+1),
+		immediate: make(map[uint8]AlertStatus),
+	}
+	copy(a.community[:], DefaultCommunityString)
+	return a
+}
+
+// SetSender installs the function traps are sent with. Until one is
+// installed every attempt fails.
+func (a *LANAlertStore) SetSender(send AlertSender) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.send = send
+}
+
+// Community returns the community string, NUL padded (v2.0 Table 23-4 #16).
+func (a *LANAlertStore) Community() [18]byte {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.community
+}
+
+// SetCommunity sets the community string.
+func (a *LANAlertStore) SetCommunity(community [18]byte) {
+	a.mu.Lock()
+	a.community = community
+	fn := a.onChange
+	a.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// SetOnChange installs fn to be told, after the store lock is released, of
+// each change to the community string or a non-volatile destination.
+func (a *LANAlertStore) SetOnChange(fn func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onChange = fn
+}
+
+// DestinationCount returns the number of non-volatile destinations (v2.0
+// Table 23-4 #17).
+func (a *LANAlertStore) DestinationCount() uint8 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return uint8(len(a.dests) - 1)
+}
+
+// Destination returns destination n; 0 is the volatile destination.
+func (a *LANAlertStore) Destination(n uint8) (LANAlertDestination, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if int(n) >= len(a.dests) {
+		return LANAlertDestination{}, ErrAlertDestinationOutOfRange
+	}
+	return a.dests[n], nil
+}
+
+// SetDestination sets destination n.
+func (a *LANAlertStore) SetDestination(n uint8, d LANAlertDestination) error {
+	a.mu.Lock()
+	if int(n) >= len(a.dests) {
+		a.mu.Unlock()
+		return ErrAlertDestinationOutOfRange
+	}
+	a.dests[n] = d
+	fn := a.onChange
+	a.mu.Unlock()
+	if n != 0 && fn != nil {
+		fn()
+	}
+	return nil
+}
+
+// Immediate starts an Alert Immediate to destination dest of channel
+// (v2.0§30.7). The first trap is sent before it returns; the outcome,
+// including failures to send, is read with [LANAlertStore.ImmediateStatus].
+func (a *LANAlertStore) Immediate(ctx context.Context, channel, dest uint8, ev AlertEvent) error {
+	a.mu.Lock()
+	if int(dest) >= len(a.dests) {
+		a.mu.Unlock()
+		return ErrAlertDestinationOutOfRange
+	}
+	if a.immediate[channel] == AlertStatusInProgress {
+		a.mu.Unlock()
+		return ErrAlertInProgress
+	}
+	if len(a.jobs) >= lanAlertMaxJobs {
+		a.mu.Unlock()
+		return ErrAlertQueueFull
+	}
+	a.immediate[channel] = AlertStatusInProgress
+	a.jobs = append(a.jobs, &alertJob{
+		ev:        ev,
+		steps:     []alertStep{{channel: channel, dest: dest, policy: types.PEFAlertPolicyAction_Always}},
+		immediate: true,
+	})
+	a.mu.Unlock()
+	_ = a.run(ctx)
+	return nil
+}
+
+// ImmediateStatus returns the Alert Immediate status of channel.
+func (a *LANAlertStore) ImmediateStatus(channel uint8) AlertStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.immediate[channel]
+}
+
+// ClearImmediateStatus clears the Alert Immediate status of channel. An
+// alert in progress runs on and sets it again when it ends.
+func (a *LANAlertStore) ClearImmediateStatus(channel uint8) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.immediate[channel] = AlertStatusNone
+}
+
+// queue starts an alert through the given alert policy entries (v2.0
+// §17.11). It is held back until notBefore, the end of the PEF alert
+// startup delay.
+func (a *LANAlertStore) queue(ctx context.Context, ev AlertEvent, steps []alertStep, notBefore time.Time) error {
+	if len(steps) == 0 {
+		return nil
+	}
+	a.mu.Lock()
+	if len(a.jobs) >= lanAlertMaxJobs {
+		a.mu.Unlock()
+		return ErrAlertQueueFull
+	}
+	a.jobs = append(a.jobs, &alertJob{ev: ev, steps: steps, notBefore: notBefore})
+	a.mu.Unlock()
+	return a.run(ctx)
+}
+
+// Acknowledge completes the acknowledged alert waiting with the given PET
+// sequence number and local timestamp (v2.0§30.8). It reports false when
+// no alert matches, such as for a repeated acknowledge.
+func (a *LANAlertStore) Acknowledge(ctx context.Context, seq uint16, timestamp uint32) bool {
+	a.mu.Lock()
+	var matched bool
+	for _, j := range a.jobs {
+		if j.waiting && j.dest.Acknowledged && j.trap.Sequence == seq && j.trap.LocalTimestamp == timestamp {
+			a.finishLocked(j, true, AlertStatusNormalEnd)
+			matched = true
+			break
+		}
+	}
+	a.mu.Unlock()
+	if matched {
+		_ = a.run(ctx)
+	}
+	return matched
+}
+
+// Poll retries the alerts whose acknowledge timeout or retry interval has
+// passed and moves on from those that ran out of retries.
+func (a *LANAlertStore) Poll(ctx context.Context) error {
+	return a.run(ctx)
+}
+
+// run sends every trap that is due, until none is.
+func (a *LANAlertStore) run(ctx context.Context) error {
+	a.mu.Lock()
+	idle := len(a.jobs) == 0
+	a.mu.Unlock()
+	if idle {
+		return nil
+	}
+	var errs []error
+	for {
+		// The HAL is asked for the agent address before the lock is
+		// taken: a slow NIC must not hold up the alert configuration.
+		agent := a.agentAddr(ctx)
+		a.mu.Lock()
+		sends := a.stepLocked(agent, a.clock.Now())
+		send := a.send
+		a.mu.Unlock()
+		if len(sends) == 0 {
+			return errors.Join(errs...)
+		}
+		for _, s := range sends {
+			var err error
+			if send == nil {
+				err = errors.New("no alert sender installed")
+			} else {
+				err = send(ctx, s.ip, s.trap)
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+			a.mu.Lock()
+			a.sentLocked(s.job, err)
+			a.mu.Unlock()
+		}
+	}
+}
+
+// stepLocked advances every job as far as it can go without waiting and
+// returns the traps to send. Finished jobs are removed.
+func (a *LANAlertStore) stepLocked(agent [4]byte, now time.Time) []alertSend {
+	var sends []alertSend
+	jobs := a.jobs[:0]
+	for _, j := range a.jobs {
+		if now.Before(j.notBefore) {
+			jobs = append(jobs, j)
+			continue
+		}
+		if j.waiting && !now.Before(j.deadline) {
+			if j.tries <= j.dest.Retries {
+				sends = append(sends, a.attemptLocked(j, now))
+				jobs = append(jobs, j)
+				continue
+			}
+			status := AlertStatusFailedRetry
+			if j.dest.Acknowledged && !j.sendErr {
+				status = AlertStatusFailedAck
+			}
+			a.finishLocked(j, false, status)
+		}
+		if j.waiting {
+			jobs = append(jobs, j)
+			continue
+		}
+		if s, ok := a.startNextLocked(agent, j, now); ok {
+			sends = append(sends, s)
+			jobs = append(jobs, j)
+			continue
+		}
+		if j.immediate {
+			for _, st := range j.steps {
+				a.immediate[st.channel] = j.status
+			}
+		}
+	}
+	clear(a.jobs[len(jobs):])
+	a.jobs = jobs
+	return sends
+}
+
+// startNextLocked starts alerting the next destination of j its alert
+// policies select and returns the first trap, sent from agent, or false when
+// j is done. Destinations that cannot be alerted fail at once.
+func (a *LANAlertStore) startNextLocked(agent [4]byte, j *alertJob, now time.Time) (alertSend, bool) {
+	for {
+		st, ok := j.nextStep(a.destType)
+		if !ok {
+			return alertSend{}, false
+		}
+		dest, ok := a.resolveLocked(st)
+		if ok && !j.immediate && !a.alertingEnabled(st.channel) {
+			ok = false
+		}
+		if !ok {
+			j.attempted, j.succeeded, j.status = true, false, AlertStatusFailedRetry
+			j.lastChan, j.lastType = st.channel, a.destType(st)
+			continue
+		}
+		j.channel = st.channel
+		j.dest = dest
+		j.tries = 0
+		j.sendErr = false
+		a.seq++
+		j.trap = a.trapLocked(j.ev, agent, a.seq, now)
+		j.waiting = true
+		return a.attemptLocked(j, now), true
+	}
+}
+
+// nextStep returns the next step of j to alert, skipping the ones its
+// alert policies rule out after a successful alert (v2.0 Table 30-6 #9).
+func (j *alertJob) nextStep(destType func(alertStep) uint8) (alertStep, bool) {
+	for j.next < len(j.steps) {
+		st := j.steps[j.next]
+		j.next++
+		if j.skip != nil {
+			if j.skip(st) {
+				continue
+			}
+			j.skip = nil
+		}
+		if !j.attempted || !j.succeeded || st.policy == types.PEFAlertPolicyAction_Always {
+			return st, true
+		}
+		switch st.policy {
+		case types.PEFAlertPolicyAction_NoProceed:
+			j.next = len(j.steps)
+		case types.PEFAlertPolicyAction_ProceedNextDifferentChannel:
+			last := j.lastChan
+			j.skip = func(s alertStep) bool { return s.channel == last }
+		case types.PEFAlertPolicyAction_ProceedNextDifferentDestination:
+			last := j.lastType
+			j.skip = func(s alertStep) bool { return destType(s) == last }
+		}
+	}
+	return alertStep{}, false
+}
+
+// resolveLocked returns the destination of st when it can be alerted: a
+// PET destination with an address, on a LAN channel.
+func (a *LANAlertStore) resolveLocked(st alertStep) (LANAlertDestination, bool) {
+	if !a.isLANChannel(st.channel) || int(st.dest) >= len(a.dests) {
+		return LANAlertDestination{}, false
+	}
+	d := a.dests[st.dest]
+	if d.Type != LANAlertDestinationPET || d.IP == [4]byte{} {
+		return LANAlertDestination{}, false
+	}
+	return d, true
+}
+
+// destType returns the destination type of st, or FFh when it is not a
+// LAN destination.
+func (a *LANAlertStore) destType(st alertStep) uint8 {
+	if !a.isLANChannel(st.channel) || int(st.dest) >= len(a.dests) {
+		return 0xff
+	}
+	return a.dests[st.dest].Type
+}
+
+func (a *LANAlertStore) isLANChannel(n uint8) bool {
+	if a.channels == nil {
+		return false
+	}
+	ch, err := a.channels.Get(n)
+	return err == nil && ch.Medium == ChannelMediumLAN
+}
+
+// alertingEnabled reports whether PEF alerting is enabled on channel n
+// (v2.0§22.22). Alert Immediate is sent regardless.
+func (a *LANAlertStore) alertingEnabled(n uint8) bool {
+	if a.channels == nil {
+		return false
+	}
+	ch, err := a.channels.Get(n)
+	return err == nil && ch.PEFAlerts
+}
+
+// attemptLocked counts one more attempt at j's destination and returns
+// its trap.
+func (a *LANAlertStore) attemptLocked(j *alertJob, now time.Time) alertSend {
+	j.tries++
+	j.deadline = now.Add(j.dest.retryInterval())
+	return alertSend{job: j, ip: j.dest.IP, trap: j.trap.Pack()}
+}
+
+// sentLocked records the outcome of sending a trap. An unacknowledged
+// alert succeeds once sent; a failed send is retried at the deadline.
+func (a *LANAlertStore) sentLocked(j *alertJob, err error) {
+	if !j.waiting {
+		return // acknowledged before the send returned
+	}
+	j.sendErr = err != nil
+	if err == nil && !j.dest.Acknowledged {
+		a.finishLocked(j, true, AlertStatusNormalEnd)
+	}
+}
+
+// finishLocked ends the alert to j's present destination.
+func (a *LANAlertStore) finishLocked(j *alertJob, ok bool, status AlertStatus) {
+	j.waiting = false
+	j.attempted, j.succeeded = true, ok
+	j.lastChan, j.lastType = j.channel, j.dest.Type
+	j.status = status
+}
+
+// trapLocked builds the trap for ev, sent from agent.
+func (a *LANAlertStore) trapLocked(ev AlertEvent, agent [4]byte, seq uint16, now time.Time) types.PlatformEventTrap {
+	guid := a.guid
+	if ev.GUID != nil {
+		guid = *ev.GUID
+	}
+	e := ev.Event
+	trap := types.PlatformEventTrap{
+		Community:        communityString(a.community),
+		Uptime:           uint32(now.Sub(a.start) / (10 * time.Millisecond)),
+		SensorType:       e.SensorType,
+		EventReadingType: e.EventReadingType,
+		EventDir:         e.EventDir,
+		GUID:             guid,
+		Sequence:         seq,
+		LocalTimestamp:   types.PETTimestamp(now),
+		UTCOffset:        types.PETUTCOffsetUnspecified,
+		TrapSourceType:   types.PETTrapSourceBMC,
+		EventSourceType:  types.PETEventSourceIPMI,
+		Severity:         ev.Severity,
+		SensorDevice:     uint8(e.GeneratorID),
+		SensorNumber:     e.SensorNumber,
+		EventData:        [8]byte{e.EventData.EventData1, e.EventData.EventData2, e.EventData.EventData3, 0xff, 0xff, 0xff, 0xff, 0xff},
+		LanguageCode:     types.PETLanguageEnglish,
+		ManufacturerID:   a.info.ManufacturerID,
+		SystemID:         a.info.ProductID,
+		AgentAddr:        agent,
+	}
+	return trap
+}
+
+// agentAddr returns the BMC's IP address for the traps' agent address, or
+// zero when the HAL has no network configuration. The caller must not hold
+// a.mu.
+func (a *LANAlertStore) agentAddr(ctx context.Context) [4]byte {
+	if a.h == nil {
+		return [4]byte{}
+	}
+	network := a.h.Network()
+	if network == nil {
+		return [4]byte{}
+	}
+	cfg, err := network.GetConfig(ctx)
+	if err != nil {
+		return [4]byte{}
+	}
+	return cfg.IP
+}
+
+// communityString returns the community string up to its terminating NUL.
+func communityString(c [18]byte) string {
+	for i, b := range c {
+		if b == 0 {
+			return string(c[:i])
+		}
+	}
+	return string(c[:])
+}
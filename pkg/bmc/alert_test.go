@@ -0,0 +1,214 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// alertRecorder is an AlertSender that keeps the traps it is given.
+type alertRecorder struct {
+	ips   [][4]byte
+	traps []types.PlatformEventTrap
+	err   error
+}
+
+func (r *alertRecorder) send(_ context.Context, ip [4]byte, msg []byte) error {
+	if r.err != nil {
+		return r.err
+	}
+	var trap types.PlatformEventTrap
+	if err := trap.Unpack(msg); err != nil {
+		return err
+	}
+	r.ips = append(r.ips, ip)
+	r.traps = append(r.traps, trap)
+	return nil
+}
+
+func newTestAlertBMC(t *testing.T) (*BMC, *mockClock, *alertRecorder) {
+	t.Helper()
+	b, clk, _ := newTestPEFBMC(t)
+	rec := &alertRecorder{}
+	b.Alerts.SetSender(rec.send)
+	return b, clk, rec
+}
+
+func setTestDestination(t *testing.T, a *LANAlertStore, n uint8, d LANAlertDestination) {
+	t.Helper()
+	if err := a.SetDestination(n, d); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLANAlertStore_ImmediateUnacknowledged(t *testing.T) {
+	b, clk, rec := newTestAlertBMC(t)
+	ctx := context.Background()
+	setTestDestination(t, b.Alerts, 1, LANAlertDestination{IP: [4]byte{192, 0, 2, 7}})
+	b.HAL().Network().(*mock.Network).Cfg.IP = [4]byte{192, 0, 2, 1}
+	var community [18]byte
+	copy(community[:], "ops")
+	b.Alerts.SetCommunity(community)
+
+	if err := b.Alerts.Immediate(ctx, 1, 1, AlertEvent{Event: pefTestEvent, Severity: types.PEFEventSeverityCritical}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.traps) != 1 || rec.ips[0] != [4]byte{192, 0, 2, 7} {
+		t.Fatalf("want one trap to 192.0.2.7, got %v", rec.ips)
+	}
+	trap := rec.traps[0]
+	if trap.Community != "ops" || trap.Severity != types.PEFEventSeverityCritical || trap.SensorNumber != 0x10 ||
+		trap.LocalTimestamp != types.PETTimestamp(clk.now) || trap.EventData[0] != pefTestEvent.EventData.EventData1 {
+		t.Fatalf("trap: %+v", trap)
+	}
+	if got := trap.SpecificTrap(); got != 0x010109 {
+		t.Fatalf("specific trap: want 0x010109, got %#06x", got)
+	}
+	if st := b.Alerts.ImmediateStatus(1); st != AlertStatusNormalEnd {
+		t.Fatalf("status: want normal end, got %#02x", st)
+	}
+	// The agent address is the BMC's own, read from the network HAL.
+	if trap.AgentAddr != [4]byte{192, 0, 2, 1} {
+		t.Fatalf("agent address: got %v", trap.AgentAddr)
+	}
+
+	// A destination without an address fails at once.
+	if err := b.Alerts.Immediate(ctx, 1, 2, AlertEvent{Event: pefTestEvent}); err != nil {
+		t.Fatal(err)
+	}
+	if st := b.Alerts.ImmediateStatus(1); st != AlertStatusFailedRetry {
+		t.Fatalf("unset destination: want failed retry, got %#02x", st)
+	}
+	if err := b.Alerts.Immediate(ctx, 1, DefaultLANAlertDestinations+1, AlertEvent{}); !errors.Is(err, ErrAlertDestinationOutOfRange) {
+		t.Fatalf("out of range destination: %v", err)
+	}
+}
+
+func TestLANAlertStore_AcknowledgedRetries(t *testing.T) {
+	b, clk, rec := newTestAlertBMC(t)
+	ctx := context.Background()
+	setTestDestination(t, b.Alerts, 1, LANAlertDestination{Acknowledged: true, Timeout: 1, Retries: 2, IP: [4]byte{192, 0, 2, 7}})
+
+	if err := b.Alerts.Immediate(ctx, 1, 1, AlertEvent{Event: pefTestEvent}); err != nil {
+		t.Fatal(err)
+	}
+	if st := b.Alerts.ImmediateStatus(1); st != AlertStatusInProgress {
+		t.Fatalf("status: want in progress, got %#02x", st)
+	}
+	if err := b.Alerts.Immediate(ctx, 1, 1, AlertEvent{Event: pefTestEvent}); !errors.Is(err, ErrAlertInProgress) {
+		t.Fatalf("second alert: want in progress error, got %v", err)
+	}
+
+	// Retries go out every (timeout+1) seconds with the same trap.
+	_ = b.Alerts.Poll(ctx)
+	if len(rec.traps) != 1 {
+		t.Fatalf("retried before the timeout: %d traps", len(rec.traps))
+	}
+	for i := 0; i < 2; i++ {
+		clk.now = clk.now.Add(2 * time.Second)
+		_ = b.Alerts.Poll(ctx)
+	}
+	if len(rec.traps) != 3 || rec.traps[2].Sequence != rec.traps[0].Sequence {
+		t.Fatalf("want 3 attempts of one trap, got %d", len(rec.traps))
+	}
+	clk.now = clk.now.Add(2 * time.Second)
+	_ = b.Alerts.Poll(ctx)
+	if len(rec.traps) != 3 {
+		t.Fatalf("retried past the retry count: %d traps", len(rec.traps))
+	}
+	if st := b.Alerts.ImmediateStatus(1); st != AlertStatusFailedAck {
+		t.Fatalf("status: want failed ack, got %#02x", st)
+	}
+
+	// An acknowledge ends the next alert.
+	b.Alerts.ClearImmediateStatus(1)
+	if err := b.Alerts.Immediate(ctx, 1, 1, AlertEvent{Event: pefTestEvent}); err != nil {
+		t.Fatal(err)
+	}
+	trap := rec.traps[len(rec.traps)-1]
+	if b.Alerts.Acknowledge(ctx, trap.Sequence, trap.LocalTimestamp+1) {
+		t.Fatal("acknowledge with the wrong timestamp matched")
+	}
+	if !b.Alerts.Acknowledge(ctx, trap.Sequence, trap.LocalTimestamp) {
+		t.Fatal("acknowledge did not match")
+	}
+	if st := b.Alerts.ImmediateStatus(1); st != AlertStatusNormalEnd {
+		t.Fatalf("status: want normal end, got %#02x", st)
+	}
+	if b.Alerts.Acknowledge(ctx, trap.Sequence, trap.LocalTimestamp) {
+		t.Fatal("repeated acknowledge matched")
+	}
+}
+
+func TestLANAlertStore_SendFailure(t *testing.T) {
+	b, clk, rec := newTestAlertBMC(t)
+	ctx := context.Background()
+	rec.err = errors.New("network unreachable")
+	setTestDestination(t, b.Alerts, 1, LANAlertDestination{Acknowledged: true, Retries: 1, IP: [4]byte{192, 0, 2, 7}})
+
+	if err := b.Alerts.Immediate(ctx, 1, 1, AlertEvent{Event: pefTestEvent}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		clk.now = clk.now.Add(time.Second)
+		_ = b.Alerts.Poll(ctx)
+	}
+	if st := b.Alerts.ImmediateStatus(1); st != AlertStatusFailedRetry {
+		t.Fatalf("status: want failed retry, got %#02x", st)
+	}
+}
+
+func TestPEF_AlertPolicies(t *testing.T) {
+	for name, tc := range map[string]struct {
+		policy     types.PEFAlertPolicyAction
+		noAlerting bool
+		want       [][4]byte
+	}{
+		"always":     {types.PEFAlertPolicyAction_Always, false, [][4]byte{{192, 0, 2, 1}, {192, 0, 2, 2}}},
+		"no proceed": {types.PEFAlertPolicyAction_NoProceed, false, [][4]byte{{192, 0, 2, 1}}},
+		// Set Channel Access disables PEF alerting on the channel.
+		"alerting disabled": {types.PEFAlertPolicyAction_Always, true, nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			b, _, rec := newTestAlertBMC(t)
+			ctx := context.Background()
+			setTestDestination(t, b.Alerts, 1, LANAlertDestination{IP: [4]byte{192, 0, 2, 1}})
+			setTestDestination(t, b.Alerts, 2, LANAlertDestination{IP: [4]byte{192, 0, 2, 2}})
+			for i, dest := range []uint8{1, 2} {
+				pol := types.PEFAlertPolicy{PolicyNumber: 3, PolicyState: true, PolicyAction: tc.policy, ChannelNumber: 1, Destination: dest}
+				if err := b.PEF.SetParam(uint8(types.PEFConfigParamSelector_AlertPolicy), append([]byte{uint8(i + 1)}, pol.Pack()...)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			f := pefTestFilter()
+			f.ActionAlert, f.AlertPolicyNumber, f.EventSeverity = true, 3, types.PEFEventSeverityNonCritical
+			setTestFilter(t, b.PEF, 1, f)
+			if tc.noAlerting {
+				access, _ := b.Channels.Access(1, true)
+				access.PEFAlerts = false
+				if err := b.Channels.SetAccess(1, true, access); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := b.LogEvent(ctx, pefTestEvent); err != nil {
+				t.Fatal(err)
+			}
+			if len(rec.ips) != len(tc.want) {
+				t.Fatalf("destinations: want %v, got %v", tc.want, rec.ips)
+			}
+			for i, ip := range tc.want {
+				if rec.ips[i] != ip {
+					t.Fatalf("destinations: want %v, got %v", tc.want, rec.ips)
+				}
+			}
+			if len(rec.traps) > 0 && rec.traps[0].Severity != types.PEFEventSeverityNonCritical {
+				t.Fatalf("severity: %#02x", rec.traps[0].Severity)
+			}
+		})
+	}
+}
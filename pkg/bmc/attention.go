@@ -0,0 +1,109 @@
+package bmc
+
+// SMS attention (the SMS_ATN bit of the KCS interface, v2.0§9, and Get
+// Message Flags, §22.4): the BMC asks system software to run Get
+// Message Flags whenever one of the flags that command reports is set, and
+// interrupts it when the matching BMC Global Enables interrupt, or the
+// watchdog's pre-timeout messaging interrupt, is on. The system interface
+// frontend that can signal the host installs an [AttentionNotifier].
+
+// MessageFlags are the flags Get Message Flags reports (v2.0 Table 22-4).
+type MessageFlags struct {
+	ReceiveMessageAvailable bool
+	EventMessageBufferFull  bool
+	WatchdogPreTimeout      bool
+}
+
+// Attention reports whether the flags raise SMS attention.
+func (f MessageFlags) Attention() bool {
+	return f.ReceiveMessageAvailable || f.EventMessageBufferFull || f.WatchdogPreTimeout
+}
+
+// AttentionState is what the system interface signals to system software.
+type AttentionState struct {
+	// Attention is asserted while a message flag is set.
+	Attention bool
+	// Interrupt is asserted while an enabled interrupt goes with it.
+	Interrupt bool
+	// InterruptsEnabled is set while system software has either messaging
+	// interrupt of the BMC Global Enables on.
+	InterruptsEnabled bool
+}
+
+// AttentionNotifier is told each time the [AttentionState] changes. It is
+// called with one change at a time, in order, and never with a BMC lock
+// held, so it may block, for example on a write to the guest.
+type AttentionNotifier func(AttentionState)
+
+// MessageFlags returns the message flags of the system interface.
+func (b *BMC) MessageFlags() MessageFlags {
+	return MessageFlags{
+		ReceiveMessageAvailable: b.Messages.Available(),
+		EventMessageBufferFull:  b.Messages.EventBufferFull(),
+		WatchdogPreTimeout:      b.Watchdog.PreTimeoutFlag(),
+	}
+}
+
+// Attention returns the SMS attention the system interface signals.
+func (b *BMC) Attention() AttentionState {
+	f := b.MessageFlags()
+	e := b.Messages.GlobalEnables()
+	return AttentionState{
+		Attention: f.Attention(),
+		Interrupt: f.ReceiveMessageAvailable && e.ReceiveMessageQueueInterrupt ||
+			f.EventMessageBufferFull && e.EventMessageBufferFullInterrupt ||
+			f.WatchdogPreTimeout && b.Watchdog.Status().PreTimeoutInterrupt == WatchdogPreTimeoutMessaging,
+		InterruptsEnabled: e.ReceiveMessageQueueInterrupt || e.EventMessageBufferFullInterrupt,
+	}
+}
+
+// SetAttentionNotifier installs fn to be told of the SMS attention state now
+// and of each change from then on; nil removes it. A change being told to
+// the notifier it replaces may still be in progress when it returns.
+func (b *BMC) SetAttentionNotifier(fn AttentionNotifier) {
+	b.attnMu.Lock()
+	b.attnNotify = fn
+	b.attn = b.Attention()
+	// Changes still queued were meant for the notifier replaced.
+	b.attnQueue = nil
+	if fn != nil {
+		b.attnQueue = append(b.attnQueue, b.attn)
+	}
+	b.flushAttentionLocked()
+}
+
+// updateAttention tells the notifier of an SMS attention change. It is called
+// each time a message flag or the interrupt enables change.
+func (b *BMC) updateAttention() {
+	b.attnMu.Lock()
+	state := b.Attention()
+	if state == b.attn || b.attnNotify == nil {
+		b.attn = state
+		b.attnMu.Unlock()
+		return
+	}
+	b.attn = state
+	b.attnQueue = append(b.attnQueue, state)
+	b.flushAttentionLocked()
+}
+
+// flushAttentionLocked tells the notifier of the queued changes, in order,
+// with attnMu released around each call. A change queued while another
+// goroutine is flushing is left to it, so the notifier is told of one change
+// at a time. The caller holds attnMu; it is released on return.
+func (b *BMC) flushAttentionLocked() {
+	if b.attnFlushing {
+		b.attnMu.Unlock()
+		return
+	}
+	b.attnFlushing = true
+	for len(b.attnQueue) > 0 {
+		fn, state := b.attnNotify, b.attnQueue[0]
+		b.attnQueue = b.attnQueue[1:]
+		b.attnMu.Unlock()
+		fn(state)
+		b.attnMu.Lock()
+	}
+	b.attnFlushing = false
+	b.attnMu.Unlock()
+}
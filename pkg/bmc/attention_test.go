@@ -0,0 +1,103 @@
+package bmc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestBMC_Attention(t *testing.T) {
+	b := New(DeviceInfo{}, [16]byte{}, mock.New())
+	ctx := context.Background()
+
+	type change = AttentionState
+	var changes []change
+	b.SetAttentionNotifier(func(state AttentionState) {
+		changes = append(changes, state)
+	})
+	expect := func(want ...change) {
+		t.Helper()
+		if len(changes) != len(want) {
+			t.Fatalf("changes %+v, want %+v", changes, want)
+		}
+		for i := range want {
+			if changes[i] != want[i] {
+				t.Fatalf("changes %+v, want %+v", changes, want)
+			}
+		}
+		changes = nil
+	}
+	expect(change{})
+
+	if err := b.Messages.Enqueue(ReceivedMessage{Channel: 1, Data: []byte{0x05}}); err != nil {
+		t.Fatal(err)
+	}
+	expect(change{true, false, false})
+	// A second message changes nothing.
+	if err := b.Messages.Enqueue(ReceivedMessage{Channel: 1, Data: []byte{0x05}}); err != nil {
+		t.Fatal(err)
+	}
+	expect()
+
+	e := b.Messages.GlobalEnables()
+	e.ReceiveMessageQueueInterrupt = true
+	e.EventMessageBuffer = true
+	b.Messages.SetGlobalEnables(e)
+	expect(change{true, true, true})
+
+	b.Messages.Flush()
+	expect(change{false, false, true})
+
+	b.Messages.PutEvent(make([]byte, 16))
+	expect(change{true, false, true})
+	if f := b.MessageFlags(); !f.EventMessageBufferFull || f.ReceiveMessageAvailable {
+		t.Fatalf("flags %+v", f)
+	}
+	if _, err := b.Messages.ReadEvent(); err != nil {
+		t.Fatal(err)
+	}
+	expect(change{false, false, true})
+
+	// With System Event Logging off, events reach the buffer but not the SEL.
+	e.SystemEventLogging = false
+	b.Messages.SetGlobalEnables(e)
+	event := PlatformEvent{SensorType: types.SensorTypeTemperature, EventReadingType: types.EventReadingTypeThreshold}
+	if err := b.LogEvent(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+	expect(change{true, false, true})
+	if info, err := b.SEL.Info(ctx); err != nil || info.Entries != 0 {
+		t.Fatalf("SEL info %+v, %v: logging is disabled", info, err)
+	}
+
+	b.Messages.Reset()
+	expect(change{false, false, false})
+	if e := b.Messages.GlobalEnables(); e != DefaultGlobalEnables {
+		t.Fatalf("enables after reset %+v", e)
+	}
+}
+
+// TestBMC_AttentionNotifierReenters verifies the notifier runs without the
+// BMC's locks held: system software answering the attention from within
+// the notifier changes it again, and that change is told next, in order.
+func TestBMC_AttentionNotifierReenters(t *testing.T) {
+	b := New(DeviceInfo{}, [16]byte{}, mock.New())
+
+	var changes []bool
+	b.SetAttentionNotifier(func(state AttentionState) {
+		changes = append(changes, state.Attention)
+		if state.Attention {
+			if _, err := b.Messages.Dequeue(); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+	if err := b.Messages.Enqueue(ReceivedMessage{Channel: 1, Data: []byte{0x05}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 3 || changes[0] || !changes[1] || changes[2] {
+		t.Fatalf("attention changes %v, want [false true false]", changes)
+	}
+}
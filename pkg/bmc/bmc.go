@@ -6,6 +6,7 @@
 package bmc
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -31,8 +32,11 @@ type DeviceInfo struct {
 // BMC is the central state object for an IPMI server.
 //
 // Callers create a BMC via [New] and pass it to the server together with a
-// transport and HAL.  The BMC does not own any goroutines; lifecycle management
-// belongs to the server.
+// transport and HAL.  The BMC's timed engines (watchdog, POH sampling,
+// threshold scanning, PEF and alert timers, bridged request expiry) run
+// under [BMC.Run], which every frontend starts for as long as it serves;
+// sessions, transports and the rest of the lifecycle belong to the
+// frontends.
 type BMC struct {
 	Info DeviceInfo
 	GUID [16]byte
@@ -73,6 +77,62 @@ type BMC struct {
 	// SOL holds the SOL payload configuration (v2.0 Table 26-5) and the
 	// active SOL instance state machine (v2.0 §15).
 	SOL *SOLStore
+	// SEL is the System Event Log device (v2.0§31).
+	SEL *SELStore
+	// Sensors holds the sensor device state (v2.0§35), initialised from the
+	// SDR repository and the Device SDRs.
+	Sensors *SensorStore
+	// DeviceSDRs holds the Device SDRs the BMC serves as a Sensor Device
+	// (v2.0§35.2-§35.4), or nil when it serves none. Set via
+	// [WithDeviceSDRs].
+	DeviceSDRs *DeviceSDRStore
+	// Chassis holds the chassis device state: power restore policy, restart
+	// cause, POH counter and front panel enables (v2.0§28).
+	Chassis *ChassisStore
+	// Watchdog is the BMC watchdog timer (v2.0§27).
+	Watchdog *Watchdog
+	// PEF holds the Platform Event Filtering configuration and runs each
+	// new event through the event filters (v2.0§17).
+	PEF *PEFStore
+	// Alerts holds the LAN alert destinations and sends the Platform Event
+	// Traps of PEF and Alert Immediate (v2.0§17.11).
+	Alerts *LANAlertStore
+	// LANConfig holds the writable LAN configuration of the network
+	// interface (v2.0§23.1).
+	LANConfig *LANConfigStore
+	// CommandEnables is the firmware firewall: the commands disabled per
+	// channel and LUN (v2.0§21).
+	CommandEnables *CommandEnableStore
+	// SystemInfo holds the System Info Parameters of the managed system
+	// (v2.0§22.14).
+	SystemInfo *SystemInfoStore
+	// DCMI holds the DCMI power management, identification and thermal
+	// state (DCMI v1.5).
+	DCMI *DCMIStore
+	// Messages holds the Receive Message Queue of the system interface and
+	// routes messages bridged between it and LAN sessions (v2.0§6.13). It
+	// also holds the Event Message Buffer and the BMC Global Enables
+	// (v2.0§22.1).
+	Messages *MessageStore
+
+	// attnMu guards the SMS attention last reported to attnNotify and the
+	// changes queued for it, which are delivered without attnMu held (see
+	// flushAttentionLocked).
+	attnMu       sync.Mutex
+	attnNotify   AttentionNotifier
+	attn         AttentionState
+	attnQueue    []AttentionState
+	attnFlushing bool
+
+	// run tracks the frontends running the timed engines (see run.go).
+	run runState
+
+	// stateMu serializes the saves of the non-volatile state to state, the
+	// state store of the HAL, or nil when it has none (see state.go).
+	stateMu  sync.Mutex
+	state    hal.StateStore
+	stateErr StateErrorHandler
+
 	// sdrRepo is the lazily-initialised SDR record repository (v2.0§33).
 	sdrRepo     *SDRRepository
 	sdrRepoOnce sync.Once
@@ -192,6 +252,13 @@ func (b *BMC) V15AuthTypeEnabled(authType V15AuthType) bool {
 	return false
 }
 
+// WithDeviceSDRs makes the BMC serve d as its Device SDRs, as a satellite
+// management controller does. The sensors they describe are served like
+// those of the SDR repository.
+func WithDeviceSDRs(d *DeviceSDRStore) Option {
+	return func(b *BMC) { b.DeviceSDRs = d }
+}
+
 // WithCipherSuites sets the RMCP+ cipher suites the server advertises and
 // accepts. Each ID must be a suite the reference server implements
 // ([SupportedCipherSuite]); otherwise an error is returned by New and the
@@ -218,6 +285,7 @@ func (b *BMC) ResolvedCipherSuites() []types.CipherSuiteID {
 // ID panics, failing at configuration time rather than at handshake time.
 func (b *BMC) SetCipherSuites(ids []types.CipherSuiteID) {
 	b.setCipherSuites(ids)
+	b.saveState(stateKeyLAN)
 }
 
 func (b *BMC) setCipherSuites(ids []types.CipherSuiteID) {
@@ -238,6 +306,9 @@ func (b *BMC) setCipherSuites(ids []types.CipherSuiteID) {
 // New creates a BMC with sane defaults.
 //
 // h is required; it provides hardware access.  opts are applied in order.
+// When the storage of h implements [hal.StateStorageHAL], the non-volatile
+// state saved there is restored over the defaults and the options, and each
+// change to it is saved from then on.
 func New(info DeviceInfo, guid [16]byte, h hal.HAL, opts ...Option) *BMC {
 	b := &BMC{
 		Info:  info,
@@ -248,15 +319,42 @@ func New(info DeviceInfo, guid [16]byte, h hal.HAL, opts ...Option) *BMC {
 		Users:    NewUserStore(),
 		Channels: NewChannelStore(),
 		SDRRepo:  NewSDRRepoStore(),
+
+		CommandEnables: NewCommandEnableStore(),
 	}
+	b.run.scanInterval = DefaultSensorScanInterval
 	for _, o := range opts {
 		o(b)
 	}
 	b.Sessions = NewSessionStore(b.clock)
 	b.V15Sessions = NewV15SessionStore(b.clock)
 	b.SOL = NewSOLStore(h, b.clock)
+	b.SEL = NewSELStore(h, b.clock)
+	b.Sensors = NewSensorStore(h, b.SDRRepository)
+	if b.DeviceSDRs != nil {
+		b.Sensors.SetDeviceSDRs(b.DeviceSDRs)
+		// A dynamic population change brings the sensors up to date.
+		b.DeviceSDRs.SetOnChange(func(ctx context.Context) { _ = b.Sensors.Reload(ctx) })
+	}
+	b.Chassis = NewChassisStore(h, b.clock)
+	b.Watchdog = NewWatchdog(h, b.clock, b.LogEvent)
+	b.Alerts = NewLANAlertStore(h, b.clock, b.Channels, info, guid)
+	b.PEF = NewPEFStore(h, b.clock, b.Alerts, b.logSEL)
+	b.LANConfig = NewLANConfigStore(h)
+	b.SystemInfo = NewSystemInfoStore(h)
+	b.DCMI = NewDCMIStore(h, b.clock, b.SEL)
+	b.Messages = NewMessageStore(b.clock)
 	// Session termination automatically deactivates its payloads (v2.0§24.2).
 	b.Sessions.SetOnRemove(b.SOL.DeactivateBySession)
+	// Watchdog and PEF resets and power cycles are system restarts
+	// (v2.0 Table 28-11).
+	recordRestart := func(cause uint8) { b.Chassis.RecordRestart(cause, 0) }
+	b.Watchdog.SetOnRestart(recordRestart)
+	b.PEF.SetOnRestart(recordRestart)
+	// Every message flag change may raise or drop SMS attention.
+	b.Messages.SetOnFlagChange(b.updateAttention)
+	b.Watchdog.SetOnPreTimeoutFlag(b.updateAttention)
+	b.initState()
 	return b
 }
 
@@ -289,6 +387,7 @@ func (b *BMC) SDRRepository() *SDRRepository {
 			return
 		}
 		b.sdrRepo = NewSDRRepository(store.SDR(), b.clock)
+		b.sdrRepo.SetReservations(b.SDRRepo)
 	})
 	return b.sdrRepo
 }
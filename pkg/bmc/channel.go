@@ -1,8 +1,10 @@
 package bmc
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"sync"
 )
 
@@ -39,10 +41,13 @@ type Channel struct {
 	Number uint8
 	// Medium is the channel's physical medium. It is security-relevant: the
 	// handler privilege check treats a session-less request on a
-	// [ChannelMediumSystemIF] channel as locally authorized (the system
-	// interface is inherently local), so labeling a network-reachable channel
-	// as the system interface, or attaching a session to the system-interface
-	// channel, would grant unauthenticated callers full privilege.
+	// [ChannelMediumSystemIF] channel as locally authorized (physical access
+	// is the authorization), so labeling a network-reachable channel as one
+	// would grant unauthenticated callers full privilege. A session-less
+	// request on an IPMB channel has no privilege of its own: one bridged
+	// with Send Message runs at the privilege its requester held where it was
+	// sent, capped like a session's by the channel's MaxPrivilege, and one no
+	// BMC bridged runs none but the privilege-exempt commands.
 	Medium     ChannelMedium
 	AccessMode ChannelAccessMode
 	// MaxPrivilege is the maximum privilege level allowed on this channel.
@@ -54,6 +59,36 @@ type Channel struct {
 	PEFAlerts bool
 }
 
+// ChannelAccess is the access configuration of a channel that Set Channel
+// Access writes and Get Channel Access reads (v2.0§22.22, §22.23). The
+// fields of a [Channel] hold its volatile (active) copy.
+type ChannelAccess struct {
+	AccessMode     ChannelAccessMode
+	MaxPrivilege   PrivilegeLevel
+	PerMessageAuth bool
+	UserLevelAuth  bool
+	PEFAlerts      bool
+}
+
+// Access returns the volatile access configuration of ch.
+func (ch *Channel) Access() ChannelAccess {
+	return ChannelAccess{
+		AccessMode:     ch.AccessMode,
+		MaxPrivilege:   ch.MaxPrivilege,
+		PerMessageAuth: ch.PerMessageAuth,
+		UserLevelAuth:  ch.UserLevelAuth,
+		PEFAlerts:      ch.PEFAlerts,
+	}
+}
+
+func (ch *Channel) setAccess(a ChannelAccess) {
+	ch.AccessMode = a.AccessMode
+	ch.MaxPrivilege = a.MaxPrivilege
+	ch.PerMessageAuth = a.PerMessageAuth
+	ch.UserLevelAuth = a.UserLevelAuth
+	ch.PEFAlerts = a.PEFAlerts
+}
+
 // ChannelStore holds the configuration for all BMC channels.
 //
 // Channel numbers follow the IPMI spec:
@@ -61,15 +96,26 @@ type Channel struct {
 //   - 0x01-0x0B – implementation-specific
 //   - 0x0E – current channel (self-reference, resolved by caller)
 //   - 0x0F – system interface
+//
+// Each channel has a volatile access configuration, the one in effect, and
+// a non-volatile one it returns to when the BMC restarts
+// ([ChannelStore.RestoreAccess]).
 type ChannelStore struct {
 	mu       sync.RWMutex
 	channels map[uint8]*Channel
+	// nonVolatile holds the non-volatile access configuration by channel.
+	nonVolatile map[uint8]ChannelAccess
+	// onChange, when set, is told of each non-volatile change.
+	onChange func()
 }
 
 // NewChannelStore returns a ChannelStore pre-populated with a default LAN channel (1)
 // and the system interface (15 / 0x0F).
 func NewChannelStore() *ChannelStore {
-	s := &ChannelStore{channels: make(map[uint8]*Channel, 4)}
+	s := &ChannelStore{
+		channels:    make(map[uint8]*Channel, 4),
+		nonVolatile: make(map[uint8]ChannelAccess, 4),
+	}
 	// Channel 1: LAN
 	s.channels[1] = &Channel{
 		Number:         1,
@@ -78,6 +124,7 @@ func NewChannelStore() *ChannelStore {
 		MaxPrivilege:   PrivilegeLevelAdministrator,
 		PerMessageAuth: true,
 		UserLevelAuth:  true,
+		PEFAlerts:      true,
 	}
 	// Channel 15: System Interface
 	s.channels[0x0F] = &Channel{
@@ -86,6 +133,9 @@ func NewChannelStore() *ChannelStore {
 		AccessMode:   ChannelAccessAlways,
 		MaxPrivilege: PrivilegeLevelAdministrator,
 	}
+	for n, ch := range s.channels {
+		s.nonVolatile[n] = ch.Access()
+	}
 	return s
 }
 
@@ -104,12 +154,119 @@ func (s *ChannelStore) Get(n uint8) (*Channel, error) {
 }
 
 // Set adds or replaces the channel at number n, storing a private copy so the
-// caller cannot mutate stored state afterwards.
+// caller cannot mutate stored state afterwards. The access configuration of
+// ch becomes both the volatile and the non-volatile one.
 func (s *ChannelStore) Set(ch *Channel) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	cp := *ch
 	s.channels[cp.Number] = &cp
+	s.nonVolatile[cp.Number] = cp.Access()
+	s.mu.Unlock()
+	s.changed()
+}
+
+// Access returns the volatile or the non-volatile access configuration of
+// channel n, or [ErrChannelNotFound].
+func (s *ChannelStore) Access(n uint8, volatile bool) (ChannelAccess, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ch, ok := s.channels[n]
+	if !ok {
+		return ChannelAccess{}, fmt.Errorf("channel %d: %w", n, ErrChannelNotFound)
+	}
+	if volatile {
+		return ch.Access(), nil
+	}
+	return s.nonVolatile[n], nil
+}
+
+// SetAccess sets the volatile or the non-volatile access configuration of
+// channel n (v2.0§22.22). A volatile change takes effect at once and lasts
+// until the BMC restarts; a non-volatile one only takes effect then.
+func (s *ChannelStore) SetAccess(n uint8, volatile bool, a ChannelAccess) error {
+	s.mu.Lock()
+	ch, ok := s.channels[n]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("channel %d: %w", n, ErrChannelNotFound)
+	}
+	if volatile {
+		ch.setAccess(a)
+		s.mu.Unlock()
+		return nil
+	}
+	s.nonVolatile[n] = a
+	s.mu.Unlock()
+	s.changed()
+	return nil
+}
+
+// RestoreAccess makes the non-volatile access configuration of every
+// channel the volatile one, as a BMC restart does.
+func (s *ChannelStore) RestoreAccess() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for n, ch := range s.channels {
+		ch.setAccess(s.nonVolatile[n])
+	}
+}
+
+// SetOnChange installs fn to be told, after the store lock is released, of
+// each change to the non-volatile access configuration.
+func (s *ChannelStore) SetOnChange(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+func (s *ChannelStore) changed() {
+	s.mu.RLock()
+	fn := s.onChange
+	s.mu.RUnlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// snapshotAccess returns the non-volatile access configuration by channel.
+func (s *ChannelStore) snapshotAccess() map[uint8]ChannelAccess {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.nonVolatile)
+}
+
+// restoreAccess makes access the non-volatile, and so the volatile, access
+// configuration of each channel it names that is configured.
+func (s *ChannelStore) restoreAccess(access map[uint8]ChannelAccess) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for n, a := range access {
+		if ch, ok := s.channels[n]; ok {
+			s.nonVolatile[n] = a
+			ch.setAccess(a)
+		}
+	}
+}
+
+// ChannelAvailable reports whether the access mode of ch lets it be used
+// now (v2.0§6.6): never when disabled, and when pre-boot only, while the
+// managed system is powered off. A BMC whose HAL has no chassis control
+// has no system to boot, so pre-boot only channels are always available.
+// The power state is the chassis store's sample (see
+// [ChassisStore.PowerState]), since the LAN frontend asks on every packet.
+func (b *BMC) ChannelAvailable(ctx context.Context, ch *Channel) bool {
+	switch ch.AccessMode {
+	case ChannelAccessDisabled:
+		return false
+	case ChannelAccessPreBootOnly:
+		if b.hal == nil || b.hal.Chassis() == nil {
+			return true
+		}
+		on, err := b.Chassis.PowerState(ctx)
+		return err == nil && !on
+	default:
+		return true
+	}
 }
 
 // All returns snapshot copies of all configured channels.
@@ -0,0 +1,47 @@
+package bmc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChannelStore_Access(t *testing.T) {
+	s := NewChannelStore()
+
+	nv, err := s.Access(1, false)
+	if err != nil || nv.AccessMode != ChannelAccessAlways || !nv.PEFAlerts || nv.MaxPrivilege != PrivilegeLevelAdministrator {
+		t.Fatalf("default non-volatile access: %+v %v", nv, err)
+	}
+
+	// A non-volatile change waits for a restart; a volatile one does not
+	// survive it.
+	nv.AccessMode = ChannelAccessDisabled
+	if err := s.SetAccess(1, false, nv); err != nil {
+		t.Fatal(err)
+	}
+	v := nv
+	v.AccessMode, v.MaxPrivilege = ChannelAccessAlways, PrivilegeLevelUser
+	if err := s.SetAccess(1, true, v); err != nil {
+		t.Fatal(err)
+	}
+	if ch, _ := s.Get(1); ch.AccessMode != ChannelAccessAlways || ch.MaxPrivilege != PrivilegeLevelUser {
+		t.Fatalf("volatile: %+v", ch)
+	}
+	s.RestoreAccess()
+	if ch, _ := s.Get(1); ch.AccessMode != ChannelAccessDisabled || ch.MaxPrivilege != PrivilegeLevelAdministrator {
+		t.Fatalf("restored: %+v", ch)
+	}
+
+	// Set makes the channel's settings both copies.
+	s.Set(&Channel{Number: 2, Medium: ChannelMediumLAN, AccessMode: ChannelAccessPreBootOnly})
+	if nv, _ := s.Access(2, false); nv.AccessMode != ChannelAccessPreBootOnly {
+		t.Fatalf("non-volatile after Set: %+v", nv)
+	}
+
+	if _, err := s.Access(9, true); !errors.Is(err, ErrChannelNotFound) {
+		t.Fatalf("unknown channel: %v", err)
+	}
+	if err := s.SetAccess(9, false, ChannelAccess{}); !errors.Is(err, ErrChannelNotFound) {
+		t.Fatalf("unknown channel: %v", err)
+	}
+}
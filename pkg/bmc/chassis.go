@@ -0,0 +1,406 @@
+package bmc
+
+// Chassis device state (v2.0§28): power restore policy, system restart
+// cause, power-on hours, front panel button enables, power cycle interval
+// and the chassis capabilities. Power and reset themselves are carried out
+// through [hal.ChassisHAL]; [BMC.Run] calls [ChassisStore.Poll] so the POH
+// counter follows the power state.
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// ChassisPollInterval is how often [BMC.Run] samples the power state for
+// the POH counter.
+const ChassisPollInterval = 10 * time.Second
+
+// POHMinutesPerCount is the resolution of the POH counter (v2.0§28.14).
+const POHMinutesPerCount = 1
+
+// Power restore policies (v2.0 Table 28-8).
+const (
+	PowerRestoreAlwaysOff = 0x00
+	PowerRestorePrevious  = 0x01
+	PowerRestoreAlwaysOn  = 0x02
+	// PowerRestoreNoChange leaves the policy as is; Set Power Restore
+	// Policy uses it to query the supported policies.
+	PowerRestoreNoChange = 0x03
+)
+
+// System restart causes (v2.0 Table 28-11).
+const (
+	RestartCauseUnknown           = 0x00
+	RestartCauseChassisControl    = 0x01
+	RestartCausePushbuttonReset   = 0x02
+	RestartCausePushbuttonPowerUp = 0x03
+	RestartCauseWatchdog          = 0x04
+	RestartCauseOEM               = 0x05
+	RestartCauseAlwaysRestore     = 0x06
+	RestartCausePreviousRestore   = 0x07
+	RestartCausePEFReset          = 0x08
+	RestartCausePEFPowerCycle     = 0x09
+	RestartCauseSoftReset         = 0x0a
+	RestartCauseRTCWakeup         = 0x0b
+)
+
+// Front panel button disables (v2.0 Table 28-9), as carried by Set Front
+// Panel Enables and Get Chassis Status byte 4.
+const (
+	FrontPanelDisablePowerOff   = 1 << 0
+	FrontPanelDisableReset      = 1 << 1
+	FrontPanelDisableDiagnostic = 1 << 2
+	FrontPanelDisableStandby    = 1 << 3
+)
+
+// Chassis command failures, mapped by the Chassis NetFn handlers to
+// completion codes (v2.0§28).
+var (
+	// ErrChassisNotPresent → CodeNotSupported: the HAL has no chassis.
+	ErrChassisNotPresent = errors.New("no chassis")
+	// ErrPowerRestorePolicyInvalid → CodeRequestDataFieldInvalid.
+	ErrPowerRestorePolicyInvalid = errors.New("invalid power restore policy")
+)
+
+// ChassisCapabilities is the chassis configuration set by Set Chassis
+// Capabilities and reported by Get Chassis Capabilities (v2.0§28.1, §28.7).
+type ChassisCapabilities struct {
+	FrontPanelLockout bool
+	IntrusionSensor   bool
+
+	FRUDeviceAddress              uint8
+	SDRDeviceAddress              uint8
+	SELDeviceAddress              uint8
+	SystemManagementDeviceAddress uint8
+	// BridgeDeviceAddress is optional; zero means none.
+	BridgeDeviceAddress uint8
+}
+
+// ChassisStore holds the chassis device state.
+type ChassisStore struct {
+	mu    sync.Mutex
+	h     hal.HAL
+	clock clock.Clock
+
+	restorePolicy uint8
+	caps          ChassisCapabilities
+	frontPanel    uint8 // FrontPanelDisable* bits
+	cycleInterval uint8 // seconds
+
+	restartCause   uint8
+	restartChannel uint8
+	// powerOnByCommand is set when the last power-on was a Chassis Control
+	// command (Get Chassis Status byte 2 bit 4).
+	powerOnByCommand bool
+
+	// The POH counter accumulates the time between samples that found the
+	// system powered on.
+	poh        time.Duration
+	powerOn    bool // power state at lastSample
+	lastSample time.Time
+
+	// onChange, when set, is told of each non-volatile change.
+	onChange func()
+}
+
+// NewChassisStore returns the chassis state of a BMC whose chassis is
+// controlled through h. The policy starts as always-off and every device
+// address as the BMC's own.
+func NewChassisStore(h hal.HAL, clk clock.Clock) *ChassisStore {
+	if clk == nil {
+		clk = clock.Real
+	}
+	return &ChassisStore{
+		h:     h,
+		clock: clk,
+		caps: ChassisCapabilities{
+			FRUDeviceAddress:              types.BMC_SA,
+			SDRDeviceAddress:              types.BMC_SA,
+			SELDeviceAddress:              types.BMC_SA,
+			SystemManagementDeviceAddress: types.BMC_SA,
+		},
+	}
+}
+
+func (c *ChassisStore) chassisHAL() hal.ChassisHAL {
+	if c.h == nil {
+		return nil
+	}
+	return c.h.Chassis()
+}
+
+// RestorePolicy returns the power restore policy.
+func (c *ChassisStore) RestorePolicy() uint8 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.restorePolicy
+}
+
+// SetRestorePolicy sets the power restore policy (v2.0§28.8).
+// [PowerRestoreNoChange] leaves it unchanged.
+func (c *ChassisStore) SetRestorePolicy(policy uint8) error {
+	if policy > PowerRestoreNoChange {
+		return ErrPowerRestorePolicyInvalid
+	}
+	if c.chassisHAL() == nil {
+		return ErrChassisNotPresent
+	}
+	if policy == PowerRestoreNoChange {
+		return nil
+	}
+	c.mu.Lock()
+	c.restorePolicy = policy
+	c.mu.Unlock()
+	c.changed()
+	return nil
+}
+
+// ApplyRestorePolicy powers the system up as the restore policy asks for
+// when AC power returns, which for an emulated BMC is when the server
+// starts. "Previous" powers up when the last power state this store
+// observed was on.
+func (c *ChassisStore) ApplyRestorePolicy(ctx context.Context) error {
+	ch := c.chassisHAL()
+	if ch == nil {
+		return nil
+	}
+	on, err := ch.PowerState(ctx)
+	if err != nil || on {
+		return err
+	}
+
+	c.mu.Lock()
+	cause := uint8(RestartCauseUnknown)
+	switch {
+	case c.restorePolicy == PowerRestoreAlwaysOn:
+		cause = RestartCauseAlwaysRestore
+	case c.restorePolicy == PowerRestorePrevious && c.powerOn:
+		cause = RestartCausePreviousRestore
+	}
+	c.mu.Unlock()
+	if cause == RestartCauseUnknown {
+		return nil
+	}
+
+	if err := ch.SetPower(ctx, true); err != nil {
+		return err
+	}
+	c.RecordRestart(cause, 0)
+	return nil
+}
+
+// RecordRestart records the cause of a system start or restart and the
+// channel of the command behind it, 0 when no command was involved
+// (v2.0§28.11).
+func (c *ChassisStore) RecordRestart(cause, channel uint8) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.restartCause = cause
+	c.restartChannel = channel
+	c.powerOnByCommand = cause == RestartCauseChassisControl
+}
+
+// RestartCause returns the last recorded restart cause and channel.
+func (c *ChassisStore) RestartCause() (cause, channel uint8) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.restartCause, c.restartChannel
+}
+
+// PowerOnByCommand reports whether the last power-on was a Chassis Control
+// command.
+func (c *ChassisStore) PowerOnByCommand() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.powerOnByCommand
+}
+
+// Poll samples the power state into the POH counter.
+func (c *ChassisStore) Poll(ctx context.Context) error {
+	ch := c.chassisHAL()
+	if ch == nil {
+		return nil
+	}
+	on, err := ch.PowerState(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	changed := c.sampleLocked(on)
+	c.mu.Unlock()
+	if changed {
+		c.changed()
+	}
+	return nil
+}
+
+// sampleLocked credits the time since the previous sample to the POH
+// counter if the system was on then, and reports whether the power state
+// changed or the counter passed a count. The caller holds c.mu.
+func (c *ChassisStore) sampleLocked(on bool) bool {
+	now := c.clock.Now()
+	count := c.poh / (POHMinutesPerCount * time.Minute)
+	if c.powerOn && !c.lastSample.IsZero() {
+		c.poh += now.Sub(c.lastSample)
+	}
+	changed := c.powerOn != on || c.poh/(POHMinutesPerCount*time.Minute) != count
+	c.powerOn = on
+	c.lastSample = now
+	return changed
+}
+
+// PowerState returns the power state of the last sample, sampling again
+// when that is older than [ChassisPollInterval]. Under [BMC.Run] the
+// sample is kept fresh by Poll, so callers on a per-packet path do not
+// reach the HAL.
+func (c *ChassisStore) PowerState(ctx context.Context) (bool, error) {
+	if c.chassisHAL() == nil {
+		return false, ErrChassisNotPresent
+	}
+	c.mu.Lock()
+	if !c.lastSample.IsZero() && c.clock.Now().Sub(c.lastSample) < ChassisPollInterval {
+		on := c.powerOn
+		c.mu.Unlock()
+		return on, nil
+	}
+	c.mu.Unlock()
+	if err := c.Poll(ctx); err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.powerOn, nil
+}
+
+// POHCounter samples the power state and returns the POH counter in
+// [POHMinutesPerCount] units (v2.0§28.14).
+func (c *ChassisStore) POHCounter(ctx context.Context) (uint32, error) {
+	ch := c.chassisHAL()
+	if ch == nil {
+		return 0, ErrChassisNotPresent
+	}
+	on, err := ch.PowerState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	changed := c.sampleLocked(on)
+	count := uint32(c.poh / (POHMinutesPerCount * time.Minute))
+	c.mu.Unlock()
+	if changed {
+		c.changed()
+	}
+	return count, nil
+}
+
+// FrontPanelDisables returns the disabled front panel buttons as
+// FrontPanelDisable* bits.
+func (c *ChassisStore) FrontPanelDisables() uint8 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.frontPanel
+}
+
+// SetFrontPanelDisables sets the disabled front panel buttons (v2.0§28.6).
+func (c *ChassisStore) SetFrontPanelDisables(disables uint8) {
+	c.mu.Lock()
+	c.frontPanel = disables & 0x0f
+	c.mu.Unlock()
+	c.changed()
+}
+
+// PowerCycleInterval returns the power cycle interval in seconds.
+func (c *ChassisStore) PowerCycleInterval() uint8 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cycleInterval
+}
+
+// SetPowerCycleInterval sets how long a power cycle holds power off
+// (v2.0§28.9), passing it on to a chassis that implements
+// [hal.PowerCycleIntervalHAL].
+func (c *ChassisStore) SetPowerCycleInterval(ctx context.Context, seconds uint8) error {
+	ch := c.chassisHAL()
+	if ch == nil {
+		return ErrChassisNotPresent
+	}
+	if ci, ok := ch.(hal.PowerCycleIntervalHAL); ok {
+		if err := ci.SetPowerCycleInterval(ctx, seconds); err != nil {
+			return err
+		}
+	}
+	c.mu.Lock()
+	c.cycleInterval = seconds
+	c.mu.Unlock()
+	c.changed()
+	return nil
+}
+
+// Capabilities returns the chassis capabilities.
+func (c *ChassisStore) Capabilities() ChassisCapabilities {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.caps
+}
+
+// SetCapabilities sets the chassis capabilities (v2.0§28.7).
+func (c *ChassisStore) SetCapabilities(caps ChassisCapabilities) {
+	c.mu.Lock()
+	c.caps = caps
+	c.mu.Unlock()
+	c.changed()
+}
+
+// SetBootFlags commits the boot flags (v2.0 Table 28-14 parameter #5) to
+// the chassis.
+func (c *ChassisStore) SetBootFlags(ctx context.Context, flags *types.BootOptionParam_BootFlags) error {
+	ch := c.chassisHAL()
+	if ch == nil {
+		return ErrChassisNotPresent
+	}
+	if err := ch.SetBootFlags(ctx, flags); err != nil {
+		return err
+	}
+	c.changed()
+	return nil
+}
+
+// SetBootInfoAcknowledge commits the boot info acknowledge data (v2.0 Table
+// 28-14 parameter #4) to the chassis.
+func (c *ChassisStore) SetBootInfoAcknowledge(ctx context.Context, ack *types.BootOptionParam_BootInfoAcknowledge) error {
+	ch := c.chassisHAL()
+	if ch == nil {
+		return ErrChassisNotPresent
+	}
+	if err := ch.SetBootInfoAcknowledge(ctx, ack); err != nil {
+		return err
+	}
+	c.changed()
+	return nil
+}
+
+// SetOnChange installs fn to be told, after the store lock is released, of
+// each change to the non-volatile chassis state: the restore policy and the
+// power state it may restore, the POH counter as it passes each count, the
+// front panel enables, the power cycle interval, the capabilities and the
+// boot options.
+func (c *ChassisStore) SetOnChange(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = fn
+}
+
+func (c *ChassisStore) changed() {
+	c.mu.Lock()
+	fn := c.onChange
+	c.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
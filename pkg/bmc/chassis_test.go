@@ -0,0 +1,120 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+)
+
+func TestChassisStore_POHCounter(t *testing.T) {
+	clk := &mockClock{now: time.Unix(1_700_000_000, 0)}
+	m := mock.New()
+	c := NewChassisStore(m, clk)
+	ch := m.Chassis().(*mock.Chassis)
+	ctx := context.Background()
+
+	// Time while the system is off does not count.
+	_ = c.Poll(ctx)
+	clk.now = clk.now.Add(time.Hour)
+	ch.On = true
+	_ = c.Poll(ctx)
+	clk.now = clk.now.Add(90 * time.Minute)
+	_ = c.Poll(ctx)
+	ch.On = false
+	clk.now = clk.now.Add(30 * time.Minute)
+	_ = c.Poll(ctx)
+	clk.now = clk.now.Add(time.Hour)
+
+	n, err := c.POHCounter(ctx)
+	if err != nil || n != 120 {
+		t.Fatalf("POH counter: %d %v", n, err)
+	}
+}
+
+// TestChassisStore_PowerState verifies the power state is served from the
+// last sample until it is older than the poll interval.
+func TestChassisStore_PowerState(t *testing.T) {
+	clk := &mockClock{now: time.Unix(1_700_000_000, 0)}
+	m := mock.New()
+	c := NewChassisStore(m, clk)
+	ch := m.Chassis().(*mock.Chassis)
+	ctx := context.Background()
+
+	if on, err := c.PowerState(ctx); err != nil || on {
+		t.Fatalf("first sample: %v %v", on, err)
+	}
+	ch.On = true
+	clk.now = clk.now.Add(ChassisPollInterval - time.Second)
+	if on, _ := c.PowerState(ctx); on {
+		t.Fatal("fresh sample not served from the store")
+	}
+	clk.now = clk.now.Add(time.Second)
+	if on, _ := c.PowerState(ctx); !on {
+		t.Fatal("stale sample not refreshed")
+	}
+}
+
+func TestChassisStore_RestorePolicy(t *testing.T) {
+	m := mock.New()
+	c := NewChassisStore(m, &mockClock{now: time.Unix(1_700_000_000, 0)})
+	ch := m.Chassis().(*mock.Chassis)
+	ctx := context.Background()
+
+	if err := c.SetRestorePolicy(0x04); !errors.Is(err, ErrPowerRestorePolicyInvalid) {
+		t.Fatalf("reserved policy: %v", err)
+	}
+	if err := c.ApplyRestorePolicy(ctx); err != nil || ch.On {
+		t.Fatalf("always-off: on=%v %v", ch.On, err)
+	}
+
+	// "Previous" restores the last power state the store observed.
+	_ = c.SetRestorePolicy(PowerRestorePrevious)
+	ch.On = true
+	_ = c.Poll(ctx)
+	ch.On = false
+	if err := c.ApplyRestorePolicy(ctx); err != nil || !ch.On {
+		t.Fatalf("previous: on=%v %v", ch.On, err)
+	}
+	if cause, channel := c.RestartCause(); cause != RestartCausePreviousRestore || channel != 0 {
+		t.Fatalf("restart cause %#02x channel %d", cause, channel)
+	}
+
+	ch.On = false
+	_ = c.SetRestorePolicy(PowerRestoreAlwaysOn)
+	_ = c.SetRestorePolicy(PowerRestoreNoChange)
+	if err := c.ApplyRestorePolicy(ctx); err != nil || !ch.On {
+		t.Fatalf("always-on: on=%v %v", ch.On, err)
+	}
+	if cause, _ := c.RestartCause(); cause != RestartCauseAlwaysRestore {
+		t.Fatalf("restart cause %#02x", cause)
+	}
+}
+
+func TestBMC_WatchdogRecordsRestartCause(t *testing.T) {
+	clk := &mockClock{now: time.Unix(1_700_000_000, 0)}
+	b := New(DeviceInfo{}, [16]byte{}, mock.New(), WithClock(clk))
+	ctx := context.Background()
+
+	b.Chassis.RecordRestart(RestartCauseChassisControl, 1)
+	if !b.Chassis.PowerOnByCommand() {
+		t.Fatal("chassis control power-on not recorded")
+	}
+	cfg := WatchdogConfig{TimerUse: WatchdogTimerUseSMSOS, TimeoutAction: WatchdogActionPowerCycle, InitialCountdown: 10}
+	if err := b.Watchdog.Set(cfg, false, 0); err != nil {
+		t.Fatal(err)
+	}
+	_ = b.Watchdog.Reset()
+	clk.now = clk.now.Add(2 * time.Second)
+	if err := b.Watchdog.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if cause, channel := b.Chassis.RestartCause(); cause != RestartCauseWatchdog || channel != 0 {
+		t.Fatalf("restart cause %#02x channel %d", cause, channel)
+	}
+	if b.Chassis.PowerOnByCommand() {
+		t.Fatal("watchdog restart reported as a command power-on")
+	}
+}
@@ -0,0 +1,122 @@
+package bmc
+
+// Firmware firewall (v2.0§21): the commands an administrator has disabled,
+// per channel and LUN. Which commands exist and which of them may be
+// disabled is up to the handler registry; this store only remembers the
+// disabled ones and the completion code they are rejected with.
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// Firmware firewall failures, mapped by the App NetFn handlers to
+// completion codes (v2.0§21).
+var (
+	// ErrCommandNotConfigurable → CodeRequestDataFieldInvalid: the command
+	// cannot be disabled.
+	ErrCommandNotConfigurable = errors.New("command is not configurable")
+	// ErrDisabledCommandCode: a disabled command can only be rejected with
+	// C1h or D5h.
+	ErrDisabledCommandCode = errors.New("disabled commands answer C1h or D5h")
+)
+
+type commandEnableKey struct {
+	channel, lun, netFn, cmd uint8
+}
+
+// CommandEnableStore holds the firmware firewall state.
+type CommandEnableStore struct {
+	mu           sync.RWMutex
+	disabled     map[commandEnableKey]struct{}
+	disabledCode types.CompletionCode
+
+	// onChange, when set, is told of each change to the disabled commands.
+	onChange func()
+}
+
+// NewCommandEnableStore returns a firewall with every command enabled.
+// Disabled commands answer [types.CodeInvalidCommand], as if they were not
+// implemented.
+func NewCommandEnableStore() *CommandEnableStore {
+	return &CommandEnableStore{
+		disabled:     make(map[commandEnableKey]struct{}),
+		disabledCode: types.CodeInvalidCommand,
+	}
+}
+
+// Enabled reports whether cmd of the request NetFn netFn is enabled on lun
+// of channel.
+func (s *CommandEnableStore) Enabled(channel, lun, netFn, cmd uint8) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, off := s.disabled[commandEnableKey{channel, lun & 0x03, netFn, cmd}]
+	return !off
+}
+
+// SetEnables enables or disables the commands in enables, keyed by command
+// code, of netFn on lun of channel, all at once (v2.0§21.7).
+func (s *CommandEnableStore) SetEnables(channel, lun, netFn uint8, enables map[uint8]bool) {
+	s.mu.Lock()
+	for cmd, enabled := range enables {
+		key := commandEnableKey{channel, lun & 0x03, netFn, cmd}
+		if enabled {
+			delete(s.disabled, key)
+		} else {
+			s.disabled[key] = struct{}{}
+		}
+	}
+	fn := s.onChange
+	s.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// Restricted reports whether any command is disabled on lun of channel,
+// which Get NetFn Support reports as a restricted LUN (v2.0§21.2).
+func (s *CommandEnableStore) Restricted(channel, lun uint8) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for key := range s.disabled {
+		if key.channel == channel && key.lun == lun&0x03 {
+			return true
+		}
+	}
+	return false
+}
+
+// DisabledCode returns the completion code a disabled command answers.
+func (s *CommandEnableStore) DisabledCode() types.CompletionCode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.disabledCode
+}
+
+// SetDisabledCode sets the completion code a disabled command answers:
+// [types.CodeInvalidCommand] (C1h), the default, hides the command as if it
+// were not implemented; [types.CodeNotSupported] (D5h) tells the caller it
+// exists but cannot run in the present state.
+func (s *CommandEnableStore) SetDisabledCode(cc types.CompletionCode) error {
+	if cc != types.CodeInvalidCommand && cc != types.CodeNotSupported {
+		return ErrDisabledCommandCode
+	}
+	s.mu.Lock()
+	s.disabledCode = cc
+	fn := s.onChange
+	s.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+	return nil
+}
+
+// SetOnChange installs fn to be told, after the store lock is released, of
+// each change to the disabled commands or their completion code.
+func (s *CommandEnableStore) SetOnChange(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
@@ -0,0 +1,40 @@
+package bmc
+
+import (
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestCommandEnableStore(t *testing.T) {
+	s := NewCommandEnableStore()
+	const lan, system, chassis = 1, 0x0f, 0x00
+
+	if !s.Enabled(system, 0, chassis, 0x01) || s.Restricted(system, 0) {
+		t.Fatal("commands should start enabled")
+	}
+	s.SetEnables(system, 0, chassis, map[uint8]bool{0x01: false, 0x02: true})
+	if s.Enabled(system, 0, chassis, 0x01) || !s.Enabled(system, 0, chassis, 0x02) {
+		t.Fatal("Get Chassis Status should be disabled on the system interface")
+	}
+	if !s.Enabled(lan, 0, chassis, 0x01) || !s.Enabled(system, 1, chassis, 0x01) {
+		t.Fatal("enables are per channel and LUN")
+	}
+	if !s.Restricted(system, 0) || s.Restricted(system, 1) || s.Restricted(lan, 0) {
+		t.Fatal("only LUN 0 of the system interface is restricted")
+	}
+	s.SetEnables(system, 0, chassis, map[uint8]bool{0x01: true})
+	if !s.Enabled(system, 0, chassis, 0x01) || s.Restricted(system, 0) {
+		t.Fatal("re-enabling should lift the restriction")
+	}
+
+	if s.DisabledCode() != types.CodeInvalidCommand {
+		t.Fatalf("default disabled code %#02x", uint8(s.DisabledCode()))
+	}
+	if err := s.SetDisabledCode(types.CodeNotSupported); err != nil || s.DisabledCode() != types.CodeNotSupported {
+		t.Fatalf("set D5h: %v", err)
+	}
+	if err := s.SetDisabledCode(types.CodeInsufficientPrivilege); err != ErrDisabledCommandCode {
+		t.Fatalf("set D4h: %v", err)
+	}
+}
@@ -0,0 +1,506 @@
+package bmc
+
+// DCMI management state (DCMI v1.5): the system power statistics and power
+// limit backed by [hal.PowerHAL], the asset tag and Management Controller
+// Identifier String, the inlet temperature limits and the DCMI
+// configuration parameters.
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+const (
+	// DCMIPowerSampleInterval is how often [BMC.Run] samples the system
+	// power for the Get Power Reading statistics.
+	DCMIPowerSampleInterval = time.Second
+	// DCMIPowerStatsPeriod is the rolling window the minimum, maximum and
+	// average power of Get Power Reading cover (DCMI v1.5 §6.6.1).
+	DCMIPowerStatsPeriod = time.Minute
+
+	// DCMIAssetTagMaxLen is the maximum asset tag length (DCMI v1.5 §6.4.3).
+	DCMIAssetTagMaxLen = 63
+	// DCMIMCIDMaxLen is the maximum Management Controller Identifier
+	// String length, including the terminating null (DCMI v1.5 §6.4.6).
+	DCMIMCIDMaxLen = 64
+	// DCMIStringChunk is the most bytes a single Get or Set of the asset
+	// tag or identifier string transfers.
+	DCMIStringChunk = 16
+)
+
+// DCMI entity IDs (DCMI v1.5 §6.7): 40h, 41h and 42h are the DCMI 1.0
+// codes for air inlet, processor and system board, equivalent to 37h, 03h
+// and 07h.
+const (
+	DCMIEntityInlet   types.EntityID = 0x37
+	DCMIEntityInletV1 types.EntityID = 0x40
+	DCMIEntityCPU     types.EntityID = 0x03
+	DCMIEntityCPUV1   types.EntityID = 0x41
+	DCMIEntityBoard   types.EntityID = 0x07
+	DCMIEntityBoardV1 types.EntityID = 0x42
+)
+
+// Recommended DHCP timing defaults in seconds (DCMI v1.5 Table 6-5 #3-5).
+const (
+	dcmiDHCPInitialTimeout = 4
+	dcmiDHCPContactTimeout = 120
+	dcmiDHCPRetryInterval  = 64
+)
+
+// DCMI failures, mapped by the DCMI handlers to completion codes (DCMI
+// v1.5 §6).
+var (
+	// ErrDCMIPowerNotSupported → CodeInvalidCommand: the HAL has no
+	// power meter ([hal.PowerMeterHAL]), so the power management commands are not offered.
+	ErrDCMIPowerNotSupported = errors.New("DCMI power management not supported")
+	// ErrDCMIPowerLimitOutOfRange → CodeSetDCMIPowerLimitOutOfRange (84h).
+	ErrDCMIPowerLimitOutOfRange = errors.New("DCMI power limit out of range")
+	// ErrDCMIExceptionActionInvalid → CodeRequestDataFieldInvalid.
+	ErrDCMIExceptionActionInvalid = errors.New("invalid DCMI exception action")
+	// ErrDCMIStringOutOfRange → CodeParameterOutOfRange: an asset tag or
+	// identifier string offset or length past its maximum.
+	ErrDCMIStringOutOfRange = errors.New("DCMI string offset or length out of range")
+	// ErrDCMIEntityInvalid → CodeRequestDataFieldInvalid: a thermal limit
+	// entity other than inlet temperature.
+	ErrDCMIEntityInvalid = errors.New("invalid DCMI thermal limit entity")
+	// ErrDCMIParamNotSupported → CodeParameterNotSupported (80h).
+	ErrDCMIParamNotSupported = errors.New("DCMI parameter not supported")
+	// ErrDCMIParamOutOfRange → CodeParameterOutOfRange: a set selector
+	// other than 00h.
+	ErrDCMIParamOutOfRange = errors.New("DCMI parameter set selector out of range")
+	// ErrDCMIParamLength → CodeRequestDataLengthInvalid.
+	ErrDCMIParamLength = errors.New("invalid DCMI parameter data length")
+)
+
+// DCMIEntity returns the DCMI 1.5 entity ID for the DCMI 1.0 codes 40h,
+// 41h and 42h, and id unchanged otherwise.
+func DCMIEntity(id types.EntityID) types.EntityID {
+	switch id {
+	case DCMIEntityInletV1:
+		return DCMIEntityInlet
+	case DCMIEntityCPUV1:
+		return DCMIEntityCPU
+	case DCMIEntityBoardV1:
+		return DCMIEntityBoard
+	}
+	return id
+}
+
+// PowerStatistics is BMC-side system power statistics data (not a wire
+// response). Handlers map this to dcmi.GetDCMIPowerReadingResponse (DCMI
+// v1.5 §6.6.1).
+type PowerStatistics struct {
+	Current uint16
+	Minimum uint16
+	Maximum uint16
+	Average uint16
+	// Timestamp is the SEL Time of the current reading.
+	Timestamp time.Time
+	// Period is the time the minimum, maximum and average cover.
+	Period time.Duration
+}
+
+// ThermalLimit is an inlet temperature limit (DCMI v1.5 §6.7.2). Limits
+// are kept for management software; enforcing them is left to the platform.
+type ThermalLimit struct {
+	// PowerOff selects the hard power off and log exception action; LogSEL
+	// the log only action, ignored when PowerOff is set.
+	PowerOff bool
+	LogSEL   bool
+	// Limit is the temperature limit in the units of the sensor record.
+	Limit            uint8
+	ExceptionTimeSec uint16
+}
+
+// thermalKey identifies the inlet temperature sensor a limit applies to.
+type thermalKey struct {
+	entity   types.EntityID
+	instance types.EntityInstance
+}
+
+// powerSample is one system power reading.
+type powerSample struct {
+	at    time.Time
+	watts uint16
+}
+
+// DCMIStore holds the DCMI management state. Its settings, the power limit
+// and its activation, the asset tag, the Management Controller Identifier
+// String, the inlet temperature limits and the configuration parameters, are
+// saved with the BMC's non-volatile state; the power statistics are not.
+type DCMIStore struct {
+	mu    sync.Mutex
+	h     hal.HAL
+	clock clock.Clock
+	sel   *SELStore
+
+	samples     []powerSample
+	limit       hal.PowerLimit
+	limitActive bool
+
+	assetTag []byte
+	mcID     []byte
+	thermal  map[thermalKey]ThermalLimit
+
+	discovery   uint8
+	dhcpTimings [3]uint8
+
+	// onChange, when set, is told of each change to the settings.
+	onChange func()
+}
+
+// NewDCMIStore returns the DCMI state of a BMC whose power is measured and
+// limited through h. Power reading timestamps are taken from sel's clock.
+func NewDCMIStore(h hal.HAL, clk clock.Clock, sel *SELStore) *DCMIStore {
+	if clk == nil {
+		clk = clock.Real
+	}
+	return &DCMIStore{
+		h:           h,
+		clock:       clk,
+		sel:         sel,
+		thermal:     map[thermalKey]ThermalLimit{},
+		dhcpTimings: [3]uint8{dcmiDHCPInitialTimeout, dcmiDHCPContactTimeout, dcmiDHCPRetryInterval},
+	}
+}
+
+// powerHAL returns the HAL's power meter, or nil when the HAL does not
+// implement [hal.PowerMeterHAL] or has no meter.
+func (d *DCMIStore) powerHAL() hal.PowerHAL {
+	meter, ok := d.h.(hal.PowerMeterHAL)
+	if !ok {
+		return nil
+	}
+	return meter.Power()
+}
+
+// PowerSupported reports whether the platform offers DCMI power management.
+func (d *DCMIStore) PowerSupported() bool {
+	return d.powerHAL() != nil
+}
+
+// SamplePower reads the system power and adds it to the statistics.
+func (d *DCMIStore) SamplePower(ctx context.Context) error {
+	ph := d.powerHAL()
+	if ph == nil {
+		return ErrDCMIPowerNotSupported
+	}
+	watts, err := ph.Reading(ctx)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.clock.Now()
+	d.samples = append(d.samples, powerSample{at: now, watts: watts})
+	i := 0
+	for i < len(d.samples) && now.Sub(d.samples[i].at) > DCMIPowerStatsPeriod {
+		i++
+	}
+	d.samples = d.samples[i:]
+	return nil
+}
+
+// PowerReading takes a fresh power sample and returns the statistics over
+// the samples of the last [DCMIPowerStatsPeriod].
+func (d *DCMIStore) PowerReading(ctx context.Context) (PowerStatistics, error) {
+	if err := d.SamplePower(ctx); err != nil {
+		return PowerStatistics{}, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	last := d.samples[len(d.samples)-1]
+	stats := PowerStatistics{
+		Current: last.watts,
+		Minimum: last.watts,
+		Maximum: last.watts,
+		Period:  last.at.Sub(d.samples[0].at),
+	}
+	var sum int
+	for _, s := range d.samples {
+		stats.Minimum = min(stats.Minimum, s.watts)
+		stats.Maximum = max(stats.Maximum, s.watts)
+		sum += int(s.watts)
+	}
+	stats.Average = uint16(sum / len(d.samples))
+	if d.sel != nil {
+		stats.Timestamp = d.sel.Time()
+	} else {
+		stats.Timestamp = last.at
+	}
+	return stats, nil
+}
+
+// PowerLimit returns the power limit last set and whether it is active.
+func (d *DCMIStore) PowerLimit() (hal.PowerLimit, bool, error) {
+	if d.powerHAL() == nil {
+		return hal.PowerLimit{}, false, ErrDCMIPowerNotSupported
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.limit, d.limitActive, nil
+}
+
+// SetPowerLimit sets the power limit (DCMI v1.5 §6.6.3). A limit that is
+// already active takes effect at once.
+func (d *DCMIStore) SetPowerLimit(ctx context.Context, limit hal.PowerLimit) error {
+	ph := d.powerHAL()
+	if ph == nil {
+		return ErrDCMIPowerNotSupported
+	}
+	switch limit.ExceptionAction {
+	case types.DCMIExceptionAction_NoAction, types.DCMIExceptionAction_PowerOffAndLogSEL, types.DCMIExceptionAction_LogSEL:
+	default:
+		return ErrDCMIExceptionActionInvalid
+	}
+	if limit.Watts == 0 {
+		return ErrDCMIPowerLimitOutOfRange
+	}
+	d.mu.Lock()
+	if d.limitActive {
+		if err := ph.SetLimit(ctx, &limit); err != nil {
+			d.mu.Unlock()
+			return err
+		}
+	}
+	d.limit = limit
+	d.mu.Unlock()
+	d.changed()
+	return nil
+}
+
+// ActivatePowerLimit activates or deactivates the power limit (DCMI v1.5
+// §6.6.4). Activating before a limit has been set fails with
+// [ErrDCMIPowerLimitOutOfRange].
+func (d *DCMIStore) ActivatePowerLimit(ctx context.Context, activate bool) error {
+	ph := d.powerHAL()
+	if ph == nil {
+		return ErrDCMIPowerNotSupported
+	}
+	if err := d.activatePowerLimit(ctx, ph, activate); err != nil {
+		return err
+	}
+	d.changed()
+	return nil
+}
+
+func (d *DCMIStore) activatePowerLimit(ctx context.Context, ph hal.PowerHAL, activate bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !activate {
+		if err := ph.SetLimit(ctx, nil); err != nil {
+			return err
+		}
+		d.limitActive = false
+		return nil
+	}
+	if d.limit.Watts == 0 {
+		return ErrDCMIPowerLimitOutOfRange
+	}
+	limit := d.limit
+	if err := ph.SetLimit(ctx, &limit); err != nil {
+		return err
+	}
+	d.limitActive = true
+	return nil
+}
+
+// dcmiStringRead returns up to count bytes of s from offset.
+func dcmiStringRead(s []byte, offset, count uint8) ([]byte, error) {
+	if count > DCMIStringChunk {
+		return nil, ErrDCMIStringOutOfRange
+	}
+	if int(offset) >= len(s) {
+		return []byte{}, nil
+	}
+	end := min(len(s), int(offset)+int(count))
+	return append([]byte(nil), s[offset:end]...), nil
+}
+
+// dcmiStringWrite writes data into s at offset and truncates s after it
+// (DCMI v1.5 §6.4.3): the new length is offset plus len(data). A gap
+// before offset is zero-filled.
+func dcmiStringWrite(s []byte, offset uint8, data []byte, maxLen int) ([]byte, error) {
+	if len(data) > DCMIStringChunk || int(offset)+len(data) > maxLen || int(offset) >= maxLen {
+		return nil, ErrDCMIStringOutOfRange
+	}
+	out := make([]byte, int(offset)+len(data))
+	copy(out, s)
+	copy(out[offset:], data)
+	return out, nil
+}
+
+// AssetTag returns up to count bytes of the asset tag from offset, and the
+// total asset tag length (DCMI v1.5 §6.4.2).
+func (d *DCMIStore) AssetTag(offset, count uint8) ([]byte, uint8, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	data, err := dcmiStringRead(d.assetTag, offset, count)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, uint8(len(d.assetTag)), nil
+}
+
+// SetAssetTag writes data to the asset tag at offset and returns the new
+// total length (DCMI v1.5 §6.4.3).
+func (d *DCMIStore) SetAssetTag(offset uint8, data []byte) (uint8, error) {
+	d.mu.Lock()
+	tag, err := dcmiStringWrite(d.assetTag, offset, data, DCMIAssetTagMaxLen)
+	if err != nil {
+		d.mu.Unlock()
+		return 0, err
+	}
+	d.assetTag = tag
+	fn := d.onChange
+	d.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+	return uint8(len(tag)), nil
+}
+
+// MCIdentifier returns up to count bytes of the Management Controller
+// Identifier String from offset, and the string length up to its first
+// null (DCMI v1.5 §6.4.6.1).
+func (d *DCMIStore) MCIdentifier(offset, count uint8) ([]byte, uint8, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := len(d.mcID)
+	for i, c := range d.mcID {
+		if c == 0 {
+			n = i
+			break
+		}
+	}
+	data, err := dcmiStringRead(d.mcID[:n], offset, count)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, uint8(n), nil
+}
+
+// SetMCIdentifier writes data to the Management Controller Identifier
+// String at offset and returns the new total length (DCMI v1.5 §6.4.6.2).
+func (d *DCMIStore) SetMCIdentifier(offset uint8, data []byte) (uint8, error) {
+	d.mu.Lock()
+	id, err := dcmiStringWrite(d.mcID, offset, data, DCMIMCIDMaxLen)
+	if err != nil {
+		d.mu.Unlock()
+		return 0, err
+	}
+	d.mcID = id
+	fn := d.onChange
+	d.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+	return uint8(len(id)), nil
+}
+
+// SetOnChange installs fn to be told, after the store lock is released, of
+// each change to the power limit or its activation, the asset tag, the
+// Management Controller Identifier String, an inlet temperature limit or a
+// configuration parameter.
+func (d *DCMIStore) SetOnChange(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onChange = fn
+}
+
+func (d *DCMIStore) changed() {
+	d.mu.Lock()
+	fn := d.onChange
+	d.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// ThermalLimit returns the limit of the inlet temperature sensor of the
+// given entity instance; a limit never set reads as zero.
+func (d *DCMIStore) ThermalLimit(entity types.EntityID, instance types.EntityInstance) (ThermalLimit, error) {
+	if DCMIEntity(entity) != DCMIEntityInlet {
+		return ThermalLimit{}, ErrDCMIEntityInvalid
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.thermal[thermalKey{DCMIEntityInlet, instance}], nil
+}
+
+// SetThermalLimit sets the limit of the inlet temperature sensor of the
+// given entity instance (DCMI v1.5 §6.7.2).
+func (d *DCMIStore) SetThermalLimit(entity types.EntityID, instance types.EntityInstance, limit ThermalLimit) error {
+	if DCMIEntity(entity) != DCMIEntityInlet {
+		return ErrDCMIEntityInvalid
+	}
+	d.mu.Lock()
+	d.thermal[thermalKey{DCMIEntityInlet, instance}] = limit
+	d.mu.Unlock()
+	d.changed()
+	return nil
+}
+
+// ConfigParam returns the data of a DCMI configuration parameter (DCMI
+// v1.5 Table 6-5).
+func (d *DCMIStore) ConfigParam(selector types.DCMIConfigParamSelector, set uint8) ([]byte, error) {
+	if set != 0 {
+		return nil, ErrDCMIParamOutOfRange
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch selector {
+	case types.DCMIConfigParamSelector_ActivateDHCP:
+		// A trigger, not a setting: it always reads back 00h.
+		return []byte{0x00}, nil
+	case types.DCMIConfigParamSelector_DiscoveryConfiguration:
+		return []byte{d.discovery}, nil
+	case types.DCMIConfigParamSelector_DHCPTiming1,
+		types.DCMIConfigParamSelector_DHCPTiming2,
+		types.DCMIConfigParamSelector_DHCPTiming3:
+		return []byte{d.dhcpTimings[selector-types.DCMIConfigParamSelector_DHCPTiming1]}, nil
+	}
+	return nil, ErrDCMIParamNotSupported
+}
+
+// SetConfigParam sets a DCMI configuration parameter (DCMI v1.5 Table
+// 6-5). Activate DHCP is accepted and otherwise ignored: DHCP runs outside
+// the BMC, behind [hal.NetworkHAL].
+func (d *DCMIStore) SetConfigParam(selector types.DCMIConfigParamSelector, set uint8, data []byte) error {
+	switch selector {
+	case types.DCMIConfigParamSelector_ActivateDHCP,
+		types.DCMIConfigParamSelector_DiscoveryConfiguration,
+		types.DCMIConfigParamSelector_DHCPTiming1,
+		types.DCMIConfigParamSelector_DHCPTiming2,
+		types.DCMIConfigParamSelector_DHCPTiming3:
+	default:
+		return ErrDCMIParamNotSupported
+	}
+	if set != 0 {
+		return ErrDCMIParamOutOfRange
+	}
+	if len(data) != 1 {
+		return ErrDCMIParamLength
+	}
+	d.mu.Lock()
+	switch selector {
+	case types.DCMIConfigParamSelector_ActivateDHCP:
+		d.mu.Unlock()
+		return nil
+	case types.DCMIConfigParamSelector_DiscoveryConfiguration:
+		d.discovery = data[0]
+	case types.DCMIConfigParamSelector_DHCPTiming1,
+		types.DCMIConfigParamSelector_DHCPTiming2,
+		types.DCMIConfigParamSelector_DHCPTiming3:
+		d.dhcpTimings[selector-types.DCMIConfigParamSelector_DHCPTiming1] = data[0]
+	}
+	d.mu.Unlock()
+	d.changed()
+	return nil
+}
@@ -0,0 +1,179 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func newTestDCMIBMC(t *testing.T) (*BMC, *mockClock, *mock.Power) {
+	t.Helper()
+	clk := &mockClock{now: time.Unix(1_700_000_000, 0)}
+	m := mock.New()
+	b := New(DeviceInfo{}, [16]byte{}, m, WithClock(clk))
+	return b, clk, m.Power().(*mock.Power)
+}
+
+func TestDCMIStore_PowerStatistics(t *testing.T) {
+	b, clk, power := newTestDCMIBMC(t)
+	ctx := context.Background()
+
+	for _, w := range []uint16{100, 300, 200} {
+		power.SetWatts(w)
+		if err := b.DCMI.SamplePower(ctx); err != nil {
+			t.Fatal(err)
+		}
+		clk.now = clk.now.Add(10 * time.Second)
+	}
+	power.SetWatts(150)
+	stats, err := b.DCMI.PowerReading(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Current != 150 || stats.Minimum != 100 || stats.Maximum != 300 || stats.Average != 187 {
+		t.Fatalf("stats: %+v", stats)
+	}
+	if stats.Period != 30*time.Second || !stats.Timestamp.Equal(clk.now) {
+		t.Fatalf("period %v timestamp %v", stats.Period, stats.Timestamp)
+	}
+
+	// Samples older than the statistics period drop out.
+	clk.now = clk.now.Add(DCMIPowerStatsPeriod)
+	power.SetWatts(90)
+	stats, err = b.DCMI.PowerReading(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Minimum != 90 || stats.Maximum != 150 || stats.Period != DCMIPowerStatsPeriod {
+		t.Fatalf("after window: %+v", stats)
+	}
+
+	power.ReadErr = hal.ErrNotSupported
+	if _, err := b.DCMI.PowerReading(ctx); !errors.Is(err, hal.ErrNotSupported) {
+		t.Fatalf("read error: %v", err)
+	}
+}
+
+// TestDCMIStore_NoPowerMeter verifies a HAL without [hal.PowerMeterHAL]
+// offers no power management rather than failing to build.
+func TestDCMIStore_NoPowerMeter(t *testing.T) {
+	// Embedding the interface hides the mock's Power method.
+	h := struct{ hal.HAL }{mock.New()}
+	b := New(DeviceInfo{}, [16]byte{}, h)
+	if b.DCMI.PowerSupported() {
+		t.Fatal("power management offered without a power meter")
+	}
+	if _, err := b.DCMI.PowerReading(context.Background()); !errors.Is(err, ErrDCMIPowerNotSupported) {
+		t.Fatalf("want ErrDCMIPowerNotSupported, got %v", err)
+	}
+}
+
+func TestDCMIStore_PowerLimit(t *testing.T) {
+	b, _, power := newTestDCMIBMC(t)
+	ctx := context.Background()
+
+	if err := b.DCMI.ActivatePowerLimit(ctx, true); !errors.Is(err, ErrDCMIPowerLimitOutOfRange) {
+		t.Fatalf("activate unset limit: %v", err)
+	}
+	if err := b.DCMI.SetPowerLimit(ctx, hal.PowerLimit{Watts: 200, ExceptionAction: 0x05}); !errors.Is(err, ErrDCMIExceptionActionInvalid) {
+		t.Fatalf("bad exception action: %v", err)
+	}
+	limit := hal.PowerLimit{Watts: 200, CorrectionTimeMs: 1000, ExceptionAction: types.DCMIExceptionAction_LogSEL, SamplingPeriodSec: 5}
+	if err := b.DCMI.SetPowerLimit(ctx, limit); err != nil {
+		t.Fatal(err)
+	}
+	if got, active, _ := b.DCMI.PowerLimit(); active || got != limit {
+		t.Fatalf("set limit: %+v active=%v", got, active)
+	}
+	if power.ActiveLimit() != nil {
+		t.Fatal("an inactive limit reached the HAL")
+	}
+
+	if err := b.DCMI.ActivatePowerLimit(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := power.ActiveLimit(); got == nil || *got != limit {
+		t.Fatalf("activated limit: %+v", got)
+	}
+	// Changing an active limit applies it at once.
+	limit.Watts = 250
+	if err := b.DCMI.SetPowerLimit(ctx, limit); err != nil {
+		t.Fatal(err)
+	}
+	if got := power.ActiveLimit(); got == nil || got.Watts != 250 {
+		t.Fatalf("updated limit: %+v", got)
+	}
+	if err := b.DCMI.ActivatePowerLimit(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if power.ActiveLimit() != nil {
+		t.Fatal("deactivated limit still set on the HAL")
+	}
+}
+
+func TestDCMIStore_Strings(t *testing.T) {
+	b, _, _ := newTestDCMIBMC(t)
+
+	if n, err := b.DCMI.SetAssetTag(0, []byte("rack-7")); err != nil || n != 6 {
+		t.Fatalf("set asset tag: %d %v", n, err)
+	}
+	// A write truncates the tag after the written bytes.
+	if n, err := b.DCMI.SetAssetTag(5, []byte("12")); err != nil || n != 7 {
+		t.Fatalf("overwrite asset tag: %d %v", n, err)
+	}
+	data, total, err := b.DCMI.AssetTag(0, 16)
+	if err != nil || string(data) != "rack-12" || total != 7 {
+		t.Fatalf("asset tag: %q %d %v", data, total, err)
+	}
+	if _, _, err := b.DCMI.AssetTag(0, 17); !errors.Is(err, ErrDCMIStringOutOfRange) {
+		t.Fatalf("oversized read: %v", err)
+	}
+	if _, err := b.DCMI.SetAssetTag(60, []byte("abcd")); !errors.Is(err, ErrDCMIStringOutOfRange) {
+		t.Fatalf("write past the maximum: %v", err)
+	}
+
+	// The identifier length stops at the first null.
+	if _, err := b.DCMI.SetMCIdentifier(0, []byte("bmc-a\x00xx")); err != nil {
+		t.Fatal(err)
+	}
+	data, total, err = b.DCMI.MCIdentifier(0, 16)
+	if err != nil || string(data) != "bmc-a" || total != 5 {
+		t.Fatalf("identifier: %q %d %v", data, total, err)
+	}
+}
+
+func TestDCMIStore_ThermalAndConfig(t *testing.T) {
+	b, _, _ := newTestDCMIBMC(t)
+
+	limit := ThermalLimit{LogSEL: true, Limit: 40, ExceptionTimeSec: 30}
+	if err := b.DCMI.SetThermalLimit(DCMIEntityInletV1, 1, limit); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := b.DCMI.ThermalLimit(DCMIEntityInlet, 1); err != nil || got != limit {
+		t.Fatalf("thermal limit: %+v %v", got, err)
+	}
+	if err := b.DCMI.SetThermalLimit(DCMIEntityCPU, 1, limit); !errors.Is(err, ErrDCMIEntityInvalid) {
+		t.Fatalf("CPU entity: %v", err)
+	}
+
+	if err := b.DCMI.SetConfigParam(types.DCMIConfigParamSelector_DHCPTiming2, 0, []byte{90}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := b.DCMI.ConfigParam(types.DCMIConfigParamSelector_DHCPTiming2, 0); err != nil || data[0] != 90 {
+		t.Fatalf("DHCP timing: %v %v", data, err)
+	}
+	if err := b.DCMI.SetConfigParam(types.DCMIConfigParamSelector_DHCPTiming2, 0, []byte{1, 2}); !errors.Is(err, ErrDCMIParamLength) {
+		t.Fatalf("long data: %v", err)
+	}
+	if err := b.DCMI.SetConfigParam(types.DCMIConfigParamSelector_DHCPTiming2, 1, []byte{1}); !errors.Is(err, ErrDCMIParamOutOfRange) {
+		t.Fatalf("set selector: %v", err)
+	}
+	if _, err := b.DCMI.ConfigParam(0x7f, 0); !errors.Is(err, ErrDCMIParamNotSupported) {
+		t.Fatalf("unknown parameter: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package bmc
+
+// Device SDRs (v2.0§35.2-§35.4): the SDRs a Sensor Device returns about its
+// own sensors through the Sensor/Event NetFn, as a satellite management
+// controller does in place of an SDR repository.
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// DeviceSDRInfo is the Get Device SDR Info data of a Sensor Device
+// (v2.0§35.2).
+type DeviceSDRInfo struct {
+	// SDRCount is the number of SDRs in the device.
+	SDRCount int
+	// SensorCount is the number of sensors on each LUN.
+	SensorCount [4]int
+	// Dynamic is set for a dynamic sensor population.
+	Dynamic bool
+	// ChangeIndicator is incremented each time a dynamic sensor population
+	// changes.
+	ChangeIndicator uint32
+}
+
+// DeviceSDRStore holds the Device SDRs of the BMC as a Sensor Device. The
+// records live in a [hal.SDRStore] of their own, separate from the SDR
+// repository, and are read with the repository's Record ID semantics
+// (0000h first, FFFFh last). Reservations are separate from the SDR
+// repository's too.
+//
+// A static population is fixed once the BMC is serving: seed the store
+// before the BMC is created. A dynamic population may change at run time
+// through [DeviceSDRStore.Add] and [DeviceSDRStore.Remove], each of which
+// cancels the reservation and advances the change indicator.
+type DeviceSDRStore struct {
+	records *SDRRepository
+	dynamic bool
+
+	mu       sync.Mutex
+	changes  uint32
+	onChange func(ctx context.Context)
+}
+
+// NewDeviceSDRStore returns Device SDRs backed by store, with a dynamic
+// sensor population when dynamic is set.
+func NewDeviceSDRStore(store hal.SDRStore, dynamic bool) *DeviceSDRStore {
+	return &DeviceSDRStore{records: NewSDRRepository(store, nil), dynamic: dynamic}
+}
+
+// SetOnChange installs the callback run after the population changes. The
+// BMC uses it to bring the sensor state up to date.
+func (d *DeviceSDRStore) SetOnChange(fn func(ctx context.Context)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onChange = fn
+}
+
+// Dynamic reports whether the sensor population is dynamic.
+func (d *DeviceSDRStore) Dynamic() bool { return d.dynamic }
+
+// Reserve returns a new Device SDR reservation ID (v2.0§35.4).
+func (d *DeviceSDRStore) Reserve() uint16 { return d.records.reservations.Reserve() }
+
+// ValidateReservation reports whether id is the current reservation.
+func (d *DeviceSDRStore) ValidateReservation(id uint16) bool {
+	return d.records.reservations.Validate(id)
+}
+
+// RecordIDs returns the sorted list of stored record IDs.
+func (d *DeviceSDRStore) RecordIDs(ctx context.Context) ([]uint16, error) {
+	return d.records.RecordIDs(ctx)
+}
+
+// GetRecord returns one record and the ID of the next (v2.0§35.3), as
+// [SDRRepository.GetRecord] does.
+func (d *DeviceSDRStore) GetRecord(ctx context.Context, recordID uint16) ([]byte, uint16, error) {
+	return d.records.GetRecord(ctx, recordID)
+}
+
+// Info returns the record count, the per-LUN sensor counts and the
+// population change indicator (v2.0§35.2). Sensors are counted from the
+// Full, Compact and Event-Only sensor records by their Sensor Owner LUN,
+// shared records counting once per sensor.
+func (d *DeviceSDRStore) Info(ctx context.Context) (*DeviceSDRInfo, error) {
+	ids, err := d.records.RecordIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info := &DeviceSDRInfo{SDRCount: len(ids), Dynamic: d.dynamic}
+	for _, id := range ids {
+		record, _, err := d.records.GetRecord(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		lun, n := sdrSensorCount(record)
+		info.SensorCount[lun] += n
+	}
+	d.mu.Lock()
+	info.ChangeIndicator = d.changes
+	d.mu.Unlock()
+	return info, nil
+}
+
+// sdrSensorCount returns the Sensor Owner LUN of a sensor record and the
+// number of sensors it describes, 0 for a record that describes none.
+func sdrSensorCount(record []byte) (lun uint8, n int) {
+	if len(record) < types.SDRRecordHeaderSize+3 {
+		return 0, 0
+	}
+	lun = record[6] & 0x03
+	// Byte offsets of the Sensor Record Sharing field (v2.0 Table 43-2,
+	// Table 43-3); its share count [3:0] of 0 or 1 means one sensor.
+	var sharing int
+	switch types.SDRRecordType(record[3]) {
+	case types.SDRRecordTypeFullSensor:
+		return lun, 1
+	case types.SDRRecordTypeCompactSensor:
+		sharing = 23
+	case types.SDRRecordTypeEventOnly:
+		sharing = 12
+	default:
+		return 0, 0
+	}
+	if len(record) <= sharing || record[sharing]&0x0f == 0 {
+		return lun, 1
+	}
+	return lun, int(record[sharing] & 0x0f)
+}
+
+// Add adds a record to a dynamic population and returns its Record ID.
+func (d *DeviceSDRStore) Add(ctx context.Context, record []byte) (uint16, error) {
+	if !d.dynamic {
+		return 0, hal.ErrNotSupported
+	}
+	id, err := d.records.Add(ctx, record)
+	if err != nil {
+		return 0, err
+	}
+	d.changed(ctx)
+	return id, nil
+}
+
+// Remove removes a record from a dynamic population.
+func (d *DeviceSDRStore) Remove(ctx context.Context, recordID uint16) error {
+	if !d.dynamic {
+		return hal.ErrNotSupported
+	}
+	d.records.mu.Lock()
+	err := d.records.deleteLocked(ctx, recordID)
+	d.records.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	d.changed(ctx)
+	return nil
+}
+
+// changed advances the change indicator and runs the change callback.
+func (d *DeviceSDRStore) changed(ctx context.Context) {
+	d.mu.Lock()
+	d.changes++
+	fn := d.onChange
+	d.mu.Unlock()
+	if fn != nil {
+		fn(ctx)
+	}
+}
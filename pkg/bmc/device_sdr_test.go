@@ -0,0 +1,62 @@
+package bmc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestDeviceSDRStore_InfoCountsSensorsPerLUN(t *testing.T) {
+	store := (&mock.Storage{}).SDR()
+	ctx := context.Background()
+	full := &types.SDRFull{SensorNumber: 0x10, SensorType: types.SensorTypeTemperature}
+	// Four sensors sharing one Compact record on LUN 1.
+	compact := &types.SDRCompact{GeneratorID: 0x0120, SensorNumber: 0x20, ShareCount: 4}
+	_ = store.Write(ctx, 1, full.Pack(1))
+	_ = store.Write(ctx, 2, compact.Pack(2))
+	_ = store.Write(ctx, 3, types.PackMCLocator(types.MCLocatorPackOpts{RecordID: 3}))
+
+	d := NewDeviceSDRStore(store, false)
+	info, err := d.Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.SDRCount != 3 || info.SensorCount != [4]int{1, 4, 0, 0} || info.Dynamic {
+		t.Fatalf("info: %+v", info)
+	}
+	if _, err := d.Add(ctx, full.Pack(0)); err == nil {
+		t.Fatal("static population accepted a new record")
+	}
+}
+
+func TestDeviceSDRStore_DynamicPopulation(t *testing.T) {
+	d := NewDeviceSDRStore((&mock.Storage{}).SDR(), true)
+	b := New(DeviceInfo{}, [16]byte{}, mock.New(), WithDeviceSDRs(d))
+	ctx := context.Background()
+
+	resID := d.Reserve()
+	full := &types.SDRFull{SensorNumber: 0x30, SensorType: types.SensorTypeTemperature}
+	id, err := d.Add(ctx, full.Pack(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.ValidateReservation(resID) {
+		t.Fatal("population change left the reservation valid")
+	}
+	if _, err := b.Sensors.Get(ctx, 0, 0x30); err != nil {
+		t.Fatalf("added sensor: %v", err)
+	}
+
+	if err := d.Remove(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Sensors.Get(ctx, 0, 0x30); err == nil {
+		t.Fatal("removed sensor still served")
+	}
+	info, _ := d.Info(ctx)
+	if info.ChangeIndicator != 2 || info.SDRCount != 0 {
+		t.Fatalf("info after two changes: %+v", info)
+	}
+}
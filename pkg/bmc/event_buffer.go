@@ -0,0 +1,124 @@
+package bmc
+
+// BMC Global Enables (v2.0§22.1, §22.2) and the Event Message Buffer
+// (v2.0§22.8): whether events are logged to the SEL and kept for system
+// software to read, and which of the message flags interrupt it. The flags
+// themselves, and the SMS attention they raise, are in attention.go.
+
+import (
+	"errors"
+)
+
+// ErrEventBufferEmpty → CodeDataNotAvailable (80h).
+var ErrEventBufferEmpty = errors.New("event message buffer empty")
+
+// GlobalEnables is the BMC Global Enables byte (v2.0 Table 22-1).
+type GlobalEnables struct {
+	OEM2 bool
+	OEM1 bool
+	OEM0 bool
+	// SystemEventLogging has the events the BMC receives logged to the SEL.
+	SystemEventLogging bool
+	// EventMessageBuffer has them kept in the Event Message Buffer.
+	EventMessageBuffer bool
+	// EventMessageBufferFullInterrupt and ReceiveMessageQueueInterrupt
+	// interrupt system software when the buffer fills and when a message
+	// is queued.
+	EventMessageBufferFullInterrupt bool
+	ReceiveMessageQueueInterrupt    bool
+}
+
+// DefaultGlobalEnables are the enables at power-up: events are logged, and
+// system software turns on the rest as its driver needs them.
+var DefaultGlobalEnables = GlobalEnables{SystemEventLogging: true}
+
+// GlobalEnables returns the BMC Global Enables.
+func (s *MessageStore) GlobalEnables() GlobalEnables {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enables
+}
+
+// SetGlobalEnables sets the BMC Global Enables. Turning the Event Message
+// Buffer off empties it.
+func (s *MessageStore) SetGlobalEnables(e GlobalEnables) {
+	s.mu.Lock()
+	s.enables = e
+	if !e.EventMessageBuffer {
+		s.eventFull, s.eventOverflow = false, false
+	}
+	s.mu.Unlock()
+	s.flagsChanged()
+}
+
+// PutEvent keeps record, an event in SEL record format, in the Event
+// Message Buffer when the buffer is enabled. The buffer holds one event: one
+// arriving while it is full is discarded, counted, and sets the overflow
+// flag.
+func (s *MessageStore) PutEvent(record []byte) {
+	s.mu.Lock()
+	if !s.enables.EventMessageBuffer {
+		s.mu.Unlock()
+		return
+	}
+	if s.eventFull {
+		s.eventOverflow = true
+		s.eventDiscards++
+		s.mu.Unlock()
+		return
+	}
+	copy(s.event[:], record)
+	s.eventFull = true
+	s.mu.Unlock()
+	s.flagsChanged()
+}
+
+// ReadEvent empties the Event Message Buffer, clearing its overflow flag,
+// and returns the event it held, or [ErrEventBufferEmpty].
+func (s *MessageStore) ReadEvent() ([16]byte, error) {
+	s.mu.Lock()
+	if !s.eventFull {
+		s.mu.Unlock()
+		return [16]byte{}, ErrEventBufferEmpty
+	}
+	record := s.event
+	s.event = [16]byte{}
+	s.eventFull, s.eventOverflow = false, false
+	s.mu.Unlock()
+	s.flagsChanged()
+	return record, nil
+}
+
+// EventBufferFull reports whether the Event Message Buffer holds an event,
+// the Event Message Buffer Full flag of Get Message Flags.
+func (s *MessageStore) EventBufferFull() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.eventFull
+}
+
+// EventBufferOverflow reports whether an event was discarded because the
+// Event Message Buffer was full since it was last read or cleared.
+func (s *MessageStore) EventBufferOverflow() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.eventOverflow
+}
+
+// EventBufferDiscards returns the number of events discarded because the
+// Event Message Buffer was full, over the store's lifetime.
+func (s *MessageStore) EventBufferDiscards() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.eventDiscards
+}
+
+// ClearEventBuffer empties the Event Message Buffer and clears its overflow
+// flag.
+func (s *MessageStore) ClearEventBuffer() {
+	s.mu.Lock()
+	s.event = [16]byte{}
+	s.eventFull, s.eventOverflow = false, false
+	s.mu.Unlock()
+	s.flagsChanged()
+}
@@ -0,0 +1,39 @@
+package bmc
+
+import "net"
+
+// EvictReason says why a session store dropped a session other than on a
+// Close Session request.
+type EvictReason string
+
+const (
+	// EvictTimeout: the session was inactive beyond the store's inactivity
+	// timeout (v2.0§6.12.15).
+	EvictTimeout EvictReason = "timeout"
+	// EvictCapacity: the store was full, and this was its oldest pending
+	// session, dropped to make room for a new one.
+	EvictCapacity EvictReason = "capacity"
+	// EvictRefused: the BMC refused the session's RMCP+ handshake and
+	// discarded the pending session; see [SessionStore.Evict].
+	EvictRefused EvictReason = "refused"
+)
+
+// Eviction describes a session a store evicted, for the hook set with
+// [SessionStore.SetOnEvict] or [V15SessionStore.SetOnEvict].
+type Eviction struct {
+	Reason EvictReason
+	// SessionID is the BMC session ID (RMCP+), or the session ID the console
+	// last used (v1.5: the temporary ID until activation).
+	SessionID uint32
+	Handle    uint8
+	Channel   uint8
+	Active    bool
+	// User is the name of the session's user, empty for the anonymous user
+	// and for an RMCP+ session evicted for timeout or capacity before its
+	// handshake completed.
+	User         string
+	MaxPrivilege PrivilegeLevel
+	// Addr is the remote console's address; nil for v1.5 sessions, which do
+	// not keep it.
+	Addr net.Addr
+}
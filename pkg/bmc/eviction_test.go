@@ -76,3 +76,74 @@ func TestRMCPPlusEvictExpiredNoDeadlockUnderHeldLock(t *testing.T) {
 		t.Fatal("DEADLOCK: EvictExpired blocked on a session lock held by the caller")
 	}
 }
+
+// TestSessionStoreOnEvict checks the eviction hook reports the sessions
+// Allocate evicts, expired and for capacity, not only the periodic scan's.
+func TestSessionStoreOnEvict(t *testing.T) {
+	clk := &mockClock{now: time.Now()}
+	store := NewSessionStoreWithOptions(clk, WithMaxSessions(2))
+	var got []Eviction
+	store.SetOnEvict(func(ev Eviction) { got = append(got, ev) })
+
+	stale, err := store.Allocate(1, 0, 0, 0, PrivilegeLevelAdministrator, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk.now = clk.now.Add(DefaultInactivityTimeout + DefaultInactivityTimeoutTolerance + time.Second)
+	oldest, err := store.Allocate(2, 0, 0, 0, PrivilegeLevelUser, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk.now = clk.now.Add(time.Second)
+	if _, err := store.Allocate(3, 0, 0, 0, PrivilegeLevelUser, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Allocate(4, 0, 0, 0, PrivilegeLevelUser, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Eviction{
+		{Reason: EvictTimeout, SessionID: stale.BMCID, Handle: stale.Handle, Channel: 1, MaxPrivilege: PrivilegeLevelAdministrator},
+		{Reason: EvictCapacity, SessionID: oldest.BMCID, Handle: oldest.Handle, Channel: 1, MaxPrivilege: PrivilegeLevelUser},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("evictions = %+v, want %+v", got, want)
+	}
+}
+
+// TestV15SessionStoreOnEvict is the v1.5 counterpart, through CreatePending.
+func TestV15SessionStoreOnEvict(t *testing.T) {
+	clk := &mockClock{now: time.Now()}
+	store := NewV15SessionStore(clk)
+	store.max = 2
+	u, err := NewUserStore().Add(2, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []Eviction
+	store.SetOnEvict(func(ev Eviction) { got = append(got, ev) })
+
+	stale, err := store.CreatePending(V15AuthTypeMD5, u, [16]byte{}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk.now = clk.now.Add(DefaultInactivityTimeout + DefaultInactivityTimeoutTolerance + time.Second)
+	oldest, err := store.CreatePending(V15AuthTypeMD5, u, [16]byte{}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk.now = clk.now.Add(time.Second)
+	for range 2 {
+		if _, err := store.CreatePending(V15AuthTypeMD5, u, [16]byte{}, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []Eviction{
+		{Reason: EvictTimeout, SessionID: stale.TempSessionID, Handle: stale.Handle, Channel: 1, User: "admin"},
+		{Reason: EvictCapacity, SessionID: oldest.TempSessionID, Handle: oldest.Handle, Channel: 1, User: "admin"},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("evictions = %+v, want %+v", got, want)
+	}
+}
@@ -2,19 +2,41 @@ package bmc
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// FRU write failures, mapped by the Storage NetFn handlers to completion
+// codes (v2.0§34.3).
+var (
+	// ErrFRUWriteProtected → CodeWriteFRUDataWriteProtected (80h).
+	ErrFRUWriteProtected = errors.New("FRU device is write-protected")
+	// ErrFRUWriteOutOfRange → CodeParameterOutOfRange: the write runs past
+	// the end of the FRU inventory area.
+	ErrFRUWriteOutOfRange = errors.New("FRU write past the end of the inventory area")
+	// ErrFRUInvalid → CodeRequestDataFieldInvalid: with write validation on,
+	// the write completes a FRU structure whose checksum is wrong.
+	ErrFRUInvalid = errors.New("invalid FRU data")
 )
 
 // FRUInventory reads FRU inventory blobs from [hal.FRUStore] and implements
 // FRU Device semantics for Storage NetFn handlers (v2.0§34).
 type FRUInventory struct {
 	store hal.FRUStore
+
+	// mu serialises the read-modify-write of Write.
+	mu             sync.Mutex
+	validateWrites bool
+	writeProtected map[uint8]bool
 }
 
 // NewFRUInventory returns an inventory backed by store.
 func NewFRUInventory(store hal.FRUStore) *FRUInventory {
-	return &FRUInventory{store: store}
+	return &FRUInventory{store: store, writeProtected: make(map[uint8]bool)}
 }
 
 // Read returns the full FRU inventory blob for deviceID (v2.0§34.2).
@@ -30,3 +52,156 @@ func (f *FRUInventory) AreaSize(ctx context.Context, deviceID uint8) (uint16, er
 	}
 	return uint16(len(data)), nil
 }
+
+// SetWriteProtected sets whether Write FRU Data may change deviceID. A
+// store whose device is write-protected in hardware can instead return
+// [types.CodeWriteFRUDataWriteProtected] from [hal.FRUStore.Write].
+func (f *FRUInventory) SetWriteProtected(deviceID uint8, protected bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writeProtected[deviceID] = protected
+}
+
+// SetValidateWrites turns FRU format checking of writes on or off (default
+// off, as v2.0§34.3 specifies a raw write). When on, a write that completes
+// the common header, an info area or a multi-record, that is, a write that
+// covers its checksum byte, fails with [ErrFRUInvalid] unless the structure
+// checks out (Platform Management FRU v1.0 §8-§16). Tools write FRU data
+// front to back in chunks, so each structure is checked once all of it has
+// been written.
+func (f *FRUInventory) SetValidateWrites(validate bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.validateWrites = validate
+}
+
+// Write writes data at offset into the FRU inventory of deviceID and stores
+// the result through [hal.FRUStore.Write] (v2.0§34.3). The inventory area
+// does not grow: a write past its end fails with [ErrFRUWriteOutOfRange].
+// It returns the number of bytes written.
+func (f *FRUInventory) Write(ctx context.Context, deviceID uint8, offset uint16, data []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.writeProtected[deviceID] {
+		return 0, ErrFRUWriteProtected
+	}
+	current, err := f.store.Read(ctx, deviceID)
+	if err != nil {
+		return 0, err
+	}
+	end := int(offset) + len(data)
+	if end > len(current) {
+		return 0, ErrFRUWriteOutOfRange
+	}
+	updated := append([]byte(nil), current...)
+	copy(updated[offset:], data)
+	if f.validateWrites {
+		if err := validateFRUWrite(updated, int(offset), end); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrFRUInvalid, err)
+		}
+	}
+	if err := f.store.Write(ctx, deviceID, updated); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// validateFRUWrite checks each FRU structure in data whose checksum byte
+// lies in the written range [start, end).
+func validateFRUWrite(data []byte, start, end int) error {
+	covers := func(last int) bool { return last >= start && last < end }
+	if len(data) < int(types.FRUCommonHeaderSize) {
+		return nil
+	}
+
+	hdr := &types.FRUCommonHeader{}
+	_ = hdr.Unpack(data)
+	if covers(int(types.FRUCommonHeaderSize) - 1) {
+		if hdr.FormatVersion&0x0f != types.FRUFormatVersion {
+			return fmt.Errorf("common header format version %#02x", hdr.FormatVersion)
+		}
+		if fruChecksum(data[:types.FRUCommonHeaderSize]) != 0 {
+			return errors.New("common header checksum mismatch")
+		}
+	}
+	if !hdr.Valid() {
+		// The areas cannot be located until the header is complete.
+		return nil
+	}
+
+	// The chassis, board and product info areas carry their length in 8
+	// byte multiples in byte 1 and end with a zero checksum byte (fru§10-§12).
+	areas := []struct {
+		name     string
+		offset8B uint8
+	}{
+		{"chassis info area", hdr.ChassisOffset8B},
+		{"board info area", hdr.BoardOffset8B},
+		{"product info area", hdr.ProductOffset8B},
+	}
+	for _, area := range areas {
+		if area.offset8B == 0 {
+			continue
+		}
+		off := int(area.offset8B) * 8
+		if off+1 >= len(data) {
+			return fmt.Errorf("%s at %#x: beyond the inventory area", area.name, off)
+		}
+		length := int(data[off+1]) * 8
+		if length == 0 || off+length > len(data) {
+			// A bad length is only wrong once this write set it; otherwise
+			// the area has not been written yet.
+			if covers(off + 1) {
+				return fmt.Errorf("%s at %#x: invalid length %d", area.name, off, length)
+			}
+			continue
+		}
+		if covers(off + length - 1) {
+			if data[off]&0x0f != types.FRUFormatVersion {
+				return fmt.Errorf("%s at %#x: format version %#02x", area.name, off, data[off])
+			}
+			if fruChecksum(data[off:off+length]) != 0 {
+				return fmt.Errorf("%s at %#x: checksum mismatch", area.name, off)
+			}
+		}
+	}
+
+	// Multi-records each carry a header checksum and a record checksum
+	// (fru§16.2).
+	if hdr.MultiRecordsOffset8B == 0 {
+		return nil
+	}
+	for off := int(hdr.MultiRecordsOffset8B) * 8; off+5 <= len(data); {
+		if covers(off+4) && fruChecksum(data[off:off+5]) != 0 {
+			return fmt.Errorf("multi-record at %#x: header checksum mismatch", off)
+		}
+		if fruChecksum(data[off:off+5]) != 0 {
+			// Not written yet; the records after it cannot be located.
+			return nil
+		}
+		length := int(data[off+2])
+		if off+5+length > len(data) {
+			return fmt.Errorf("multi-record at %#x: record runs past the inventory area", off)
+		}
+		if covers(off+5+length-1) || length == 0 && covers(off+4) {
+			if fruChecksum(data[off+5:off+5+length])+data[off+3] != 0 {
+				return fmt.Errorf("multi-record at %#x: record checksum mismatch", off)
+			}
+		}
+		if data[off+1]&0x80 != 0 {
+			break
+		}
+		off += 5 + length
+	}
+	return nil
+}
+
+// fruChecksum returns the 8-bit sum of data, zero for a FRU structure whose
+// zero checksum byte is included.
+func fruChecksum(data []byte) uint8 {
+	var sum uint8
+	for _, b := range data {
+		sum += b
+	}
+	return sum
+}
@@ -0,0 +1,96 @@
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func testFRUImage(t *testing.T, serial string) []byte {
+	t.Helper()
+	data, err := types.PackFRU(types.FRUPackConfig{
+		Board:   &types.FRUPackBoard{Mfg: "Acme", Product: "Board", Serial: "B-1"},
+		Product: &types.FRUPackProduct{Manufacturer: "Acme", Name: "Server", Serial: serial},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func newTestFRUInventory(t *testing.T, image []byte) (*FRUInventory, hal.FRUStore) {
+	t.Helper()
+	store := mock.New().Storage().FRU()
+	if err := store.Write(context.Background(), 0, image); err != nil {
+		t.Fatal(err)
+	}
+	return NewFRUInventory(store), store
+}
+
+func TestFRUInventory_Write(t *testing.T) {
+	image := testFRUImage(t, "SN-0001")
+	fru, store := newTestFRUInventory(t, image)
+	ctx := context.Background()
+
+	// Without validation a write is raw, even one that breaks the header.
+	if n, err := fru.Write(ctx, 0, 7, []byte{0x00}); err != nil || n != 1 {
+		t.Fatalf("raw write: %d %v", n, err)
+	}
+	if data, _ := store.Read(ctx, 0); data[7] != 0x00 || len(data) != len(image) {
+		t.Fatalf("stored image: % x", data)
+	}
+
+	if _, err := fru.Write(ctx, 0, uint16(len(image)-1), []byte{1, 2}); !errors.Is(err, ErrFRUWriteOutOfRange) {
+		t.Fatalf("write past the end: %v", err)
+	}
+	if _, err := fru.Write(ctx, 1, 0, []byte{1}); !errors.Is(err, hal.ErrNotFound) {
+		t.Fatalf("missing device: %v", err)
+	}
+	fru.SetWriteProtected(0, true)
+	if _, err := fru.Write(ctx, 0, 0, []byte{1}); !errors.Is(err, ErrFRUWriteProtected) {
+		t.Fatalf("write-protected device: %v", err)
+	}
+}
+
+func TestFRUInventory_ValidateWrites(t *testing.T) {
+	fru, store := newTestFRUInventory(t, testFRUImage(t, "SN-0001"))
+	fru.SetValidateWrites(true)
+	ctx := context.Background()
+
+	// A new image written front to back in chunks passes as each header
+	// and area completes.
+	next := testFRUImage(t, "SN-0002")
+	for off := 0; off < len(next); off += 16 {
+		end := min(off+16, len(next))
+		if _, err := fru.Write(ctx, 0, uint16(off), next[off:end]); err != nil {
+			t.Fatalf("chunk at %d: %v", off, err)
+		}
+	}
+	if data, _ := store.Read(ctx, 0); !bytes.Equal(data, next) {
+		t.Fatal("chunked write did not reach the store")
+	}
+
+	// A write completing the header or an area with a bad checksum is
+	// rejected and leaves the store untouched.
+	if _, err := fru.Write(ctx, 0, 7, []byte{next[7] + 1}); !errors.Is(err, ErrFRUInvalid) {
+		t.Fatalf("bad header checksum: %v", err)
+	}
+	hdr := &types.FRUCommonHeader{}
+	_ = hdr.Unpack(next)
+	off := int(hdr.ProductOffset8B) * 8
+	last := off + int(next[off+1])*8 - 1
+	if _, err := fru.Write(ctx, 0, uint16(off+3), next[off+3:last]); err != nil {
+		t.Fatalf("area body without its checksum: %v", err)
+	}
+	if _, err := fru.Write(ctx, 0, uint16(off+3), append([]byte{'X'}, next[off+4:last+1]...)); !errors.Is(err, ErrFRUInvalid) {
+		t.Fatalf("bad product area checksum: %v", err)
+	}
+	if data, _ := store.Read(ctx, 0); !bytes.Equal(data, next) {
+		t.Fatal("rejected write reached the store")
+	}
+}
@@ -0,0 +1,305 @@
+package bmc
+
+// LAN configuration (v2.0§23.1): the writable LAN configuration parameters
+// that are not alerting state. Network interface settings are written to
+// [hal.NetworkHAL], staged while the Set In Progress parameter is set.
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+)
+
+// DefaultIPv6StaticAddresses is the number of static IPv6 addresses the LAN
+// channel can be configured with (v2.0 Table 23-4 param #55).
+const DefaultIPv6StaticAddresses = 2
+
+// MaxCipherSuiteEntries is the most cipher suites the LAN configuration
+// parameters describe (v2.0 Table 23-4 params #22-#24).
+const MaxCipherSuiteEntries = 16
+
+// LAN configuration failures, mapped by the Transport NetFn handlers to
+// completion codes (v2.0§23.1).
+var (
+	// ErrLANNoNetwork → CodeNotSupported: the BMC has no network interface
+	// to configure.
+	ErrLANNoNetwork = errors.New("no network interface")
+	// ErrLANParamNotSupported → CodeParameterNotSupported (80h).
+	ErrLANParamNotSupported = errors.New("LAN parameter not supported")
+	// ErrLANSetInProgress → CodeParamConfigSetInProgressConflict (81h).
+	ErrLANSetInProgress = errors.New("LAN parameters already set in progress")
+	// ErrLANParamReadOnly → CodeParamConfigSetReadOnly (82h): the
+	// parameter is read-only, or the interface cannot change the setting.
+	ErrLANParamReadOnly = errors.New("LAN parameter is read-only")
+	// ErrLANParamInvalid → CodeRequestDataFieldInvalid: a reserved or
+	// unsupported value.
+	ErrLANParamInvalid = errors.New("invalid LAN parameter data")
+	// ErrLANParamOutOfRange → CodeParameterOutOfRange: a set selector past
+	// the end of its table.
+	ErrLANParamOutOfRange = errors.New("LAN parameter selector out of range")
+)
+
+// LANConfigStore holds the LAN configuration state of the BMC's single
+// network interface. Every LAN channel shares it.
+//
+// While Set In Progress is set, network interface writes are kept in a
+// staged copy that Get LAN Configuration Parameters reads back; commit
+// write or set complete hands it to [hal.NetworkHAL.SetConfig]. Rollback is
+// not implemented, so set complete commits like commit write does. Writes
+// outside a set in progress go to the interface at once.
+type LANConfigStore struct {
+	mu sync.Mutex
+	h  hal.HAL
+
+	setInProgress bool
+	staged        *hal.IPConfig
+
+	// cipherPrivileges holds the maximum privilege of each cipher suite
+	// entry, in the order of [BMC.ResolvedCipherSuites].
+	cipherPrivileges [MaxCipherSuiteEntries]PrivilegeLevel
+
+	// onChange, when set, is told of each cipher suite privilege change
+	// and of each network interface write that reaches the HAL.
+	onChange func()
+}
+
+// NewLANConfigStore returns the LAN configuration of a BMC whose network
+// interface is configured through h. Every cipher suite starts out allowing
+// Administrator sessions.
+func NewLANConfigStore(h hal.HAL) *LANConfigStore {
+	c := &LANConfigStore{h: h}
+	for i := range c.cipherPrivileges {
+		c.cipherPrivileges[i] = PrivilegeLevelAdministrator
+	}
+	return c
+}
+
+func (c *LANConfigStore) networkHAL() hal.NetworkHAL {
+	if c.h == nil {
+		return nil
+	}
+	return c.h.Network()
+}
+
+// SetInProgress reports whether a set is in progress (v2.0 Table 23-4
+// param #0).
+func (c *LANConfigStore) SetInProgress() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.setInProgress
+}
+
+// BeginSet marks a set in progress. Claiming the parameters while another
+// set is in progress fails with [ErrLANSetInProgress].
+func (c *LANConfigStore) BeginSet() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.setInProgress {
+		return ErrLANSetInProgress
+	}
+	c.setInProgress = true
+	return nil
+}
+
+// Commit writes the staged network interface settings to the HAL. With
+// complete set, the set in progress also ends; a failed write leaves the
+// staged settings in place so the caller can retry.
+func (c *LANConfigStore) Commit(ctx context.Context, complete bool) error {
+	c.mu.Lock()
+	var fn func()
+	if c.staged != nil {
+		network := c.networkHAL()
+		if network == nil {
+			c.mu.Unlock()
+			return ErrLANNoNetwork
+		}
+		if err := network.SetConfig(ctx, c.staged); err != nil {
+			c.mu.Unlock()
+			return err
+		}
+		c.staged = nil
+		fn = c.onChange
+	}
+	if complete {
+		c.setInProgress = false
+	}
+	c.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+	return nil
+}
+
+// Abort ends any set in progress and discards staged settings, as a BMC
+// reset does (v2.0 Table 23-4 param #0).
+func (c *LANConfigStore) Abort() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setInProgress = false
+	c.staged = nil
+}
+
+// Network returns the network interface settings, including any staged by
+// a set in progress.
+func (c *LANConfigStore) Network(ctx context.Context) (hal.IPConfig, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cfg, err := c.networkLocked(ctx)
+	if err != nil {
+		return hal.IPConfig{}, err
+	}
+	return *cfg, nil
+}
+
+// networkLocked returns a copy of the settings writes apply to. The caller
+// holds c.mu.
+func (c *LANConfigStore) networkLocked(ctx context.Context) (*hal.IPConfig, error) {
+	if c.staged != nil {
+		cp := *c.staged
+		cp.IPv6Static = append([]hal.IPv6Address(nil), c.staged.IPv6Static...)
+		return &cp, nil
+	}
+	network := c.networkHAL()
+	if network == nil {
+		return nil, ErrLANNoNetwork
+	}
+	return network.GetConfig(ctx)
+}
+
+// UpdateNetwork changes the network interface settings in field through
+// update: staged while a set is in progress, written to the HAL otherwise.
+// Settings the interface lists as fixed fail with [ErrLANParamReadOnly].
+func (c *LANConfigStore) UpdateNetwork(ctx context.Context, field hal.NetworkField, update func(*hal.IPConfig) error) error {
+	c.mu.Lock()
+	cfg, err := c.updateNetworkLocked(ctx, field, update)
+	fn := c.onChange
+	c.mu.Unlock()
+	if cfg != nil && fn != nil {
+		fn()
+	}
+	return err
+}
+
+// updateNetworkLocked applies update and returns the settings written to
+// the HAL, or nil when they were only staged. The caller holds c.mu.
+func (c *LANConfigStore) updateNetworkLocked(ctx context.Context, field hal.NetworkField, update func(*hal.IPConfig) error) (*hal.IPConfig, error) {
+	cfg, err := c.networkLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Fixed&field != 0 {
+		return nil, ErrLANParamReadOnly
+	}
+	if err := update(cfg); err != nil {
+		return nil, err
+	}
+	if c.setInProgress {
+		c.staged = cfg
+		return nil, nil
+	}
+	if err := c.networkHAL().SetConfig(ctx, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// CipherSuitePrivileges returns the maximum privilege of each cipher suite
+// entry (v2.0 Table 23-4 param #24).
+func (c *LANConfigStore) CipherSuitePrivileges() [MaxCipherSuiteEntries]PrivilegeLevel {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cipherPrivileges
+}
+
+// CipherSuitePrivilege returns the maximum privilege of cipher suite entry
+// n, or [PrivilegeLevelNoAccess] past the end of the table.
+func (c *LANConfigStore) CipherSuitePrivilege(n int) PrivilegeLevel {
+	if n < 0 || n >= MaxCipherSuiteEntries {
+		return PrivilegeLevelNoAccess
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cipherPrivileges[n]
+}
+
+// SetCipherSuitePrivileges sets the maximum privilege of the first count
+// cipher suite entries; the rest read back as zero. Each level must be
+// Callback through OEM.
+func (c *LANConfigStore) SetCipherSuitePrivileges(levels [MaxCipherSuiteEntries]PrivilegeLevel, count int) error {
+	for i := 0; i < count && i < MaxCipherSuiteEntries; i++ {
+		if levels[i] < PrivilegeLevelCallback || levels[i] > PrivilegeLevelOEM {
+			return ErrLANParamInvalid
+		}
+	}
+	c.mu.Lock()
+	for i := range c.cipherPrivileges {
+		if i >= count {
+			levels[i] = 0
+		}
+	}
+	c.cipherPrivileges = levels
+	fn := c.onChange
+	c.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+	return nil
+}
+
+// SetOnChange installs fn to be told, after the store lock is released, of
+// each change to the cipher suite privileges and of each network interface
+// write handed to the HAL; staged writes are told of when committed.
+func (c *LANConfigStore) SetOnChange(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = fn
+}
+
+// restoreCipherSuitePrivileges sets the cipher suite privileges as saved,
+// without the validation of a Set LAN Configuration Parameters write.
+func (c *LANConfigStore) restoreCipherSuitePrivileges(levels [MaxCipherSuiteEntries]PrivilegeLevel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cipherPrivileges = levels
+}
+
+// committedNetwork returns the network interface settings the HAL holds,
+// leaving out any staged ones, or nil when the BMC has no network interface.
+func (c *LANConfigStore) committedNetwork(ctx context.Context) (*hal.IPConfig, error) {
+	network := c.networkHAL()
+	if network == nil {
+		return nil, nil
+	}
+	return network.GetConfig(ctx)
+}
+
+// restoreNetwork hands the saved network interface settings to the HAL.
+// Settings the interface lists as fixed keep the interface's values.
+func (c *LANConfigStore) restoreNetwork(ctx context.Context, saved *hal.IPConfig) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	network := c.networkHAL()
+	if network == nil {
+		return ErrLANNoNetwork
+	}
+	cfg, err := network.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if cfg.Fixed&hal.NetworkFieldAddress == 0 {
+		cfg.IP, cfg.Mask, cfg.Gateway, cfg.DHCP = saved.IP, saved.Mask, saved.Gateway, saved.DHCP
+	}
+	if cfg.Fixed&hal.NetworkFieldMAC == 0 {
+		cfg.MAC = saved.MAC
+	}
+	if cfg.Fixed&hal.NetworkFieldVLAN == 0 {
+		cfg.VLANEnabled, cfg.VLANID, cfg.VLANPriority = saved.VLANEnabled, saved.VLANID, saved.VLANPriority
+	}
+	if cfg.Fixed&hal.NetworkFieldIPv6 == 0 {
+		cfg.IPv6Mode = saved.IPv6Mode
+		cfg.IPv6Static = append([]hal.IPv6Address(nil), saved.IPv6Static...)
+	}
+	return network.SetConfig(ctx, cfg)
+}
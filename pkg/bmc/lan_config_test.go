@@ -0,0 +1,99 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+)
+
+func TestLANConfigStore_SetInProgress(t *testing.T) {
+	m := mock.New()
+	network := m.Network().(*mock.Network)
+	network.Cfg = hal.IPConfig{IP: [4]byte{192, 168, 1, 50}}
+	c := NewLANConfigStore(m)
+	ctx := context.Background()
+
+	if err := c.BeginSet(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.BeginSet(); !errors.Is(err, ErrLANSetInProgress) {
+		t.Fatalf("second set in progress: %v", err)
+	}
+	setIP := func(cfg *hal.IPConfig) error {
+		cfg.IP = [4]byte{10, 0, 0, 7}
+		return nil
+	}
+	if err := c.UpdateNetwork(ctx, hal.NetworkFieldAddress, setIP); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := c.Network(ctx); err != nil || cfg.IP != [4]byte{10, 0, 0, 7} {
+		t.Fatalf("staged config: %+v %v", cfg, err)
+	}
+	if network.Cfg.IP != [4]byte{192, 168, 1, 50} {
+		t.Fatal("a staged write reached the HAL")
+	}
+
+	// Commit write applies the staged settings and keeps the set open.
+	if err := c.Commit(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if network.Cfg.IP != [4]byte{10, 0, 0, 7} || !c.SetInProgress() {
+		t.Fatalf("commit write: %+v in progress=%v", network.Cfg, c.SetInProgress())
+	}
+
+	// Abort discards what was staged since.
+	_ = c.UpdateNetwork(ctx, hal.NetworkFieldAddress, func(cfg *hal.IPConfig) error {
+		cfg.IP = [4]byte{10, 0, 0, 8}
+		return nil
+	})
+	c.Abort()
+	if cfg, _ := c.Network(ctx); cfg.IP != [4]byte{10, 0, 0, 7} || c.SetInProgress() {
+		t.Fatalf("after abort: %+v in progress=%v", cfg, c.SetInProgress())
+	}
+}
+
+func TestLANConfigStore_Fixed(t *testing.T) {
+	m := mock.New()
+	m.Network().(*mock.Network).Cfg = hal.IPConfig{Fixed: hal.NetworkFieldMAC | hal.NetworkFieldVLAN}
+	c := NewLANConfigStore(m)
+
+	err := c.UpdateNetwork(context.Background(), hal.NetworkFieldMAC, func(cfg *hal.IPConfig) error {
+		cfg.MAC = [6]byte{0x52, 0x54, 0, 0, 0, 1}
+		return nil
+	})
+	if !errors.Is(err, ErrLANParamReadOnly) {
+		t.Fatalf("fixed MAC: %v", err)
+	}
+	if err := c.UpdateNetwork(context.Background(), hal.NetworkFieldAddress, func(*hal.IPConfig) error { return nil }); err != nil {
+		t.Fatalf("address: %v", err)
+	}
+}
+
+func TestLANConfigStore_CipherSuitePrivileges(t *testing.T) {
+	c := NewLANConfigStore(mock.New())
+	if got := c.CipherSuitePrivilege(0); got != PrivilegeLevelAdministrator {
+		t.Fatalf("default privilege = %v", got)
+	}
+
+	var levels [MaxCipherSuiteEntries]PrivilegeLevel
+	levels[0] = PrivilegeLevelOperator
+	levels[1] = PrivilegeLevelUser
+	levels[5] = PrivilegeLevelUser // past count: stored as zero
+	if err := c.SetCipherSuitePrivileges(levels, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.CipherSuitePrivileges(); got[0] != PrivilegeLevelOperator || got[1] != PrivilegeLevelUser || got[5] != 0 {
+		t.Fatalf("privileges: %v", got)
+	}
+	if got := c.CipherSuitePrivilege(MaxCipherSuiteEntries); got != PrivilegeLevelNoAccess {
+		t.Fatalf("past the table: %v", got)
+	}
+
+	levels[1] = 0
+	if err := c.SetCipherSuitePrivileges(levels, 2); !errors.Is(err, ErrLANParamInvalid) {
+		t.Fatalf("reserved level: %v", err)
+	}
+}
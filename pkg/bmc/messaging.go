@@ -0,0 +1,227 @@
+package bmc
+
+// System interface messaging (v2.0§6.13, §22.3-§22.7): the Receive Message
+// Queue, where messages sent to the system interface from other channels
+// wait until system software collects them with Get Message, the
+// per-channel receive enables of Enable Message Channel Receive, and the
+// route a message system software sends back to a LAN session takes.
+// Messages leave the BMC through the [MessageDeliverer] the server installs.
+// Bridging onto an IPMB segment is in messaging_ipmb.go, the Event Message
+// Buffer and the BMC Global Enables in event_buffer.go.
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/clock"
+)
+
+// ReceiveMessageQueueSize is the number of messages the Receive Message
+// Queue holds; a message sent to a full queue is refused (v2.0§6.13.1
+// requires at least two).
+const ReceiveMessageQueueSize = 16
+
+// System interface messaging failures, mapped by the handlers to
+// completion codes.
+var (
+	// ErrReceiveQueueFull → CodeNodeBusy (C0h).
+	ErrReceiveQueueFull = errors.New("receive message queue full")
+	// ErrReceiveQueueEmpty → CodeDataNotAvailable (80h).
+	ErrReceiveQueueEmpty = errors.New("receive message queue empty")
+	// ErrMessageReceiveDisabled → CodeSendMessageNAKOnWrite (83h): system
+	// software turned off the messages of the sending channel.
+	ErrMessageReceiveDisabled = errors.New("message channel receive disabled")
+	// ErrNoMessageRoute → CodeSendMessageInvalidSessionHandle (80h): no
+	// session the message can be delivered to.
+	ErrNoMessageRoute = errors.New("no session to deliver the message to")
+)
+
+// ReceivedMessage is one Receive Message Queue entry, as Get Message returns
+// it (v2.0 Table 22-8).
+type ReceivedMessage struct {
+	Channel uint8
+	// Privilege is the privilege of the session the message arrived in, 0
+	// for a session-less channel.
+	Privilege PrivilegeLevel
+	// Data is the message, preceded by the session handle for a LAN
+	// channel.
+	Data []byte
+}
+
+// MessageDeliverer sends msg, an IPMI message in IPMB format, to the session
+// with handle on channel. It is installed by the server, which owns the LAN
+// socket, and returns an error wrapping [ErrNoSession] when no active session
+// has that handle.
+type MessageDeliverer func(ctx context.Context, channel, handle uint8, msg []byte) error
+
+// MessageStore holds the Receive Message Queue and the channels allowed to
+// put messages in it, the Event Message Buffer and the BMC Global Enables,
+// and the requests bridged onto IPMB segments awaiting their responses.
+type MessageStore struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	queue    []ReceivedMessage
+	disabled map[uint8]bool
+	deliver  MessageDeliverer
+	// onFlags, when set, is told of each change to the message flags or
+	// the interrupt enables.
+	onFlags func()
+
+	enables       GlobalEnables
+	event         [16]byte
+	eventFull     bool
+	eventOverflow bool
+	// eventDiscards counts the events discarded on overflow since the
+	// store was created; unlike the flag, nothing clears it.
+	eventDiscards uint64
+
+	buses         map[uint8]ipmbPort
+	tracked       []trackedRequest
+	nextSeq       uint8
+	bridgeTimeout time.Duration
+}
+
+// NewMessageStore returns an empty queue accepting messages from every
+// channel, with [DefaultGlobalEnables], connected to no IPMB segment.
+func NewMessageStore(clk clock.Clock) *MessageStore {
+	return &MessageStore{
+		clock:         clk,
+		disabled:      make(map[uint8]bool),
+		enables:       DefaultGlobalEnables,
+		buses:         make(map[uint8]ipmbPort),
+		bridgeTimeout: DefaultBridgeTimeout,
+	}
+}
+
+// SetDeliverer installs the function messages to other channels are sent
+// with. Until one is installed every delivery fails with
+// [ErrNoMessageRoute].
+func (s *MessageStore) SetDeliverer(deliver MessageDeliverer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliver = deliver
+}
+
+// SetOnFlagChange sets the function told of each change to the Receive
+// Message Available or Event Message Buffer Full flag, or to the BMC Global
+// Enables. It is called without the store locked.
+func (s *MessageStore) SetOnFlagChange(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onFlags = fn
+}
+
+// flagsChanged calls the onFlags function, if any. s.mu must not be held.
+func (s *MessageStore) flagsChanged() {
+	s.mu.Lock()
+	fn := s.onFlags
+	s.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// Deliver sends msg to the session with handle on channel.
+func (s *MessageStore) Deliver(ctx context.Context, channel, handle uint8, msg []byte) error {
+	s.mu.Lock()
+	deliver := s.deliver
+	s.mu.Unlock()
+	if deliver == nil {
+		return ErrNoMessageRoute
+	}
+	if err := deliver(ctx, channel, handle, msg); err != nil {
+		if errors.Is(err, ErrNoSession) {
+			return ErrNoMessageRoute
+		}
+		return err
+	}
+	return nil
+}
+
+// Enqueue adds msg to the Receive Message Queue. It fails with
+// [ErrMessageReceiveDisabled] when messages from msg.Channel are turned off
+// and with [ErrReceiveQueueFull] when the queue is full. The queue keeps its
+// own copy of msg.Data.
+func (s *MessageStore) Enqueue(msg ReceivedMessage) error {
+	s.mu.Lock()
+	if s.disabled[msg.Channel] {
+		s.mu.Unlock()
+		return ErrMessageReceiveDisabled
+	}
+	if len(s.queue) >= ReceiveMessageQueueSize {
+		s.mu.Unlock()
+		return ErrReceiveQueueFull
+	}
+	msg.Data = append([]byte(nil), msg.Data...)
+	s.queue = append(s.queue, msg)
+	s.mu.Unlock()
+	s.flagsChanged()
+	return nil
+}
+
+// Dequeue removes and returns the oldest message, or [ErrReceiveQueueEmpty].
+func (s *MessageStore) Dequeue() (ReceivedMessage, error) {
+	s.mu.Lock()
+	if len(s.queue) == 0 {
+		s.mu.Unlock()
+		return ReceivedMessage{}, ErrReceiveQueueEmpty
+	}
+	msg := s.queue[0]
+	s.queue[0] = ReceivedMessage{}
+	s.queue = s.queue[1:]
+	s.mu.Unlock()
+	s.flagsChanged()
+	return msg, nil
+}
+
+// Available reports whether a message is waiting, the Receive Message
+// Available flag of Get Message Flags.
+func (s *MessageStore) Available() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue) > 0
+}
+
+// Flush empties the Receive Message Queue.
+func (s *MessageStore) Flush() {
+	s.mu.Lock()
+	s.queue = nil
+	s.mu.Unlock()
+	s.flagsChanged()
+}
+
+// ReceiveEnabled reports whether messages from channel are accepted.
+func (s *MessageStore) ReceiveEnabled(channel uint8) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.disabled[channel]
+}
+
+// SetReceiveEnabled turns the messages from channel on or off
+// (v2.0§22.5). Messages already queued stay.
+func (s *MessageStore) SetReceiveEnabled(channel uint8, enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if enabled {
+		delete(s.disabled, channel)
+	} else {
+		s.disabled[channel] = true
+	}
+}
+
+// Reset returns the store to its power-up state: the queue and the Event
+// Message Buffer are emptied, every channel accepted again, the BMC Global
+// Enables restored to their defaults, and the bridged requests forgotten.
+func (s *MessageStore) Reset() {
+	s.mu.Lock()
+	s.queue = nil
+	s.disabled = make(map[uint8]bool)
+	s.enables = DefaultGlobalEnables
+	s.event = [16]byte{}
+	s.eventFull, s.eventOverflow = false, false
+	s.tracked = nil
+	s.mu.Unlock()
+	s.flagsChanged()
+}
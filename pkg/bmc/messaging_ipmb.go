@@ -0,0 +1,292 @@
+package bmc
+
+// Message bridging onto IPMB (v2.0§6.13, §22.7): channels connected to an
+// IPMB segment, the requests LAN sessions send onto one with Track Request,
+// and the responses routed back into those sessions. The segment itself is
+// outside the BMC: whoever owns it (package ipmb simulates one) connects a
+// channel with [MessageStore.ConnectIPMB] and hands the messages addressed to
+// the BMC to [MessageStore.ReceiveIPMB].
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/protocol"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+const (
+	// DefaultBridgeTimeout is how long a tracked request waits for its
+	// response before the BMC answers the requester with C3h (timeout)
+	// itself.
+	DefaultBridgeTimeout = 5 * time.Second
+
+	// MessagePollInterval is how often [BMC.Run] expires tracked requests.
+	MessagePollInterval = 100 * time.Millisecond
+
+	// maxTrackedRequests is the number of tracked requests awaiting their
+	// responses at once.
+	maxTrackedRequests = 16
+
+	// smsLUN is the LUN of the system interface on the BMC (v2.0§7.2):
+	// messages to it go in the Receive Message Queue.
+	smsLUN uint8 = 0x02
+)
+
+// IPMB bridging failures, mapped by the handlers to completion codes.
+var (
+	// ErrIPMBNotConnected → CodeSendMessageBusError (82h): the channel is
+	// not connected to an IPMB segment.
+	ErrIPMBNotConnected = errors.New("channel not connected to an IPMB segment")
+	// ErrIPMBNAK → CodeSendMessageNAKOnWrite (83h): no controller on the
+	// segment acknowledged the message.
+	ErrIPMBNAK = errors.New("IPMB message not acknowledged")
+	// ErrBridgeTableFull → CodeNodeBusy (C0h): too many tracked requests are
+	// awaiting their responses.
+	ErrBridgeTableFull = errors.New("too many bridged requests outstanding")
+	// ErrIPMBSelfAddressed → CodeRequestDataFieldInvalid (CCh): the message
+	// is addressed to the BMC's own slave address on the segment, which
+	// would loop it back into the BMC from a channel it did not arrive on.
+	ErrIPMBSelfAddressed = errors.New("IPMB message addressed to the BMC itself")
+)
+
+// IPMBWriter puts msg, an IPMB message, on a segment. It returns an error
+// wrapping [ErrIPMBNAK] when no controller acknowledges it; the response to a
+// request arrives later, through [MessageStore.ReceiveIPMB]. ctx carries the
+// message's [IPMBOrigin] when the BMC bridged it (see
+// [IPMBOriginFromContext]).
+type IPMBWriter func(ctx context.Context, msg []byte) error
+
+// IPMBOrigin is where a message the BMC puts on a segment comes from: the
+// channel of its requester and the privilege the requester holds there. An
+// IPMB request carries no session, so the controller serving it runs it at
+// no more than that privilege.
+type IPMBOrigin struct {
+	Channel   uint8
+	Privilege PrivilegeLevel
+}
+
+// ipmbOriginKey is the context key of an [IPMBOrigin].
+type ipmbOriginKey struct{}
+
+// WithIPMBOrigin returns a copy of ctx carrying from, for the controller
+// that receives a message written with it.
+func WithIPMBOrigin(ctx context.Context, from IPMBOrigin) context.Context {
+	return context.WithValue(ctx, ipmbOriginKey{}, from)
+}
+
+// IPMBOriginFromContext returns the origin ctx carries, and false when the
+// message was not bridged by a BMC.
+func IPMBOriginFromContext(ctx context.Context) (IPMBOrigin, bool) {
+	from, ok := ctx.Value(ipmbOriginKey{}).(IPMBOrigin)
+	return from, ok
+}
+
+// ipmbPort is a channel's connection to its segment.
+type ipmbPort struct {
+	addr  uint8
+	write IPMBWriter
+}
+
+// trackedRequest is a request bridged with Track Request, as written onto
+// the segment and as the requester sent it.
+type trackedRequest struct {
+	channel uint8
+	rsSA    uint8
+	netFn   uint8
+	cmd     uint8
+	seq     uint8
+
+	// req is the requester's original message, which the response is
+	// addressed back to.
+	req    []byte
+	origin uint8
+	handle uint8
+
+	deadline time.Time
+}
+
+// ConnectIPMB connects channel to an IPMB segment on which the BMC answers at
+// slave address addr and puts messages with write. A nil write disconnects
+// the channel.
+func (s *MessageStore) ConnectIPMB(channel, addr uint8, write IPMBWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if write == nil {
+		delete(s.buses, channel)
+		return
+	}
+	s.buses[channel] = ipmbPort{addr: addr, write: write}
+}
+
+// IPMBAddress returns the BMC's slave address on the segment of channel, and
+// false when the channel is not connected.
+func (s *MessageStore) IPMBAddress(channel uint8) (uint8, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	port, ok := s.buses[channel]
+	return port.addr, ok
+}
+
+// SetBridgeTimeout sets how long a tracked request waits for its response;
+// 0 restores [DefaultBridgeTimeout].
+func (s *MessageStore) SetBridgeTimeout(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d <= 0 {
+		d = DefaultBridgeTimeout
+	}
+	s.bridgeTimeout = d
+}
+
+// WriteIPMB puts msg, sent by a requester at from, on the segment of channel
+// unchanged. Any response is the sender's to collect: one addressed to the
+// BMC's SMS LUN lands in the Receive Message Queue.
+func (s *MessageStore) WriteIPMB(ctx context.Context, channel uint8, from IPMBOrigin, msg []byte) error {
+	s.mu.Lock()
+	port, ok := s.buses[channel]
+	s.mu.Unlock()
+	if !ok {
+		return ErrIPMBNotConnected
+	}
+	if len(msg) > 0 && msg[0] == port.addr {
+		return ErrIPMBSelfAddressed
+	}
+	return port.write(WithIPMBOrigin(ctx, from), msg)
+}
+
+// Bridge puts req, a request from the session with handle at from, on the
+// segment of channel with Track Request: the BMC's slave address and a
+// sequence number of its own replace the requester's, and the response is
+// routed back into the session addressed as req was. A request left
+// unanswered for the bridge timeout is answered with C3h by [Poll].
+//
+// [Poll]: MessageStore.Poll
+func (s *MessageStore) Bridge(ctx context.Context, channel uint8, from IPMBOrigin, handle uint8, req []byte) error {
+	s.mu.Lock()
+	port, ok := s.buses[channel]
+	if !ok {
+		s.mu.Unlock()
+		return ErrIPMBNotConnected
+	}
+	if req[0] == port.addr {
+		s.mu.Unlock()
+		return ErrIPMBSelfAddressed
+	}
+	if len(s.tracked) >= maxTrackedRequests {
+		s.mu.Unlock()
+		return ErrBridgeTableFull
+	}
+	entry := trackedRequest{
+		channel:  channel,
+		rsSA:     req[0],
+		netFn:    req[1] >> 2,
+		cmd:      req[5],
+		seq:      s.nextSeqLocked(channel),
+		req:      append([]byte(nil), req...),
+		origin:   from.Channel,
+		handle:   handle,
+		deadline: s.clock.Now().Add(s.bridgeTimeout),
+	}
+	s.tracked = append(s.tracked, entry)
+	s.mu.Unlock()
+
+	msg := append([]byte(nil), req...)
+	msg[3] = port.addr
+	msg[4] = entry.seq << 2
+	msg[len(msg)-1] = protocol.Checksum(msg[3 : len(msg)-1])
+	if err := port.write(WithIPMBOrigin(ctx, from), msg); err != nil {
+		s.mu.Lock()
+		for i, t := range s.tracked {
+			if t.channel == entry.channel && t.seq == entry.seq {
+				s.tracked = append(s.tracked[:i], s.tracked[i+1:]...)
+				break
+			}
+		}
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// nextSeqLocked returns the next sequence number not in use by a tracked
+// request on channel.
+func (s *MessageStore) nextSeqLocked(channel uint8) uint8 {
+	for {
+		seq := s.nextSeq
+		s.nextSeq = (s.nextSeq + 1) & 0x3F
+		inUse := false
+		for _, t := range s.tracked {
+			if t.channel == channel && t.seq == seq {
+				inUse = true
+				break
+			}
+		}
+		if !inUse {
+			return seq
+		}
+	}
+}
+
+// untrack forgets the tracked request on channel that resp, a response on its
+// segment, answers, and returns it.
+func (s *MessageStore) untrack(channel uint8, resp []byte) (trackedRequest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.tracked {
+		if t.channel == channel && t.rsSA == resp[3] && t.seq == resp[4]>>2 &&
+			t.cmd == resp[5] && t.netFn|0x01 == resp[1]>>2 {
+			s.tracked = append(s.tracked[:i], s.tracked[i+1:]...)
+			return t, true
+		}
+	}
+	return trackedRequest{}, false
+}
+
+// ReceiveIPMB takes msg, a message on the segment of channel addressed to the
+// BMC. The response to a tracked request is delivered into the session that
+// sent it; any other message to the SMS LUN goes in the Receive Message Queue
+// for system software. The rest is dropped with [ErrNoMessageRoute].
+func (s *MessageStore) ReceiveIPMB(ctx context.Context, channel uint8, msg []byte) error {
+	if !protocol.ValidMessage(msg) {
+		return ErrNoMessageRoute
+	}
+	if msg[1]&0x04 != 0 && len(msg) > 7 { // a response, with its completion code
+		if entry, ok := s.untrack(channel, msg); ok {
+			return s.Deliver(ctx, entry.origin, entry.handle, protocol.BuildIPMBResponse(entry.req, msg[6], msg[7:len(msg)-1]))
+		}
+	}
+	if msg[1]&0x03 != smsLUN {
+		return ErrNoMessageRoute
+	}
+	return s.Enqueue(ReceivedMessage{Channel: channel, Data: msg})
+}
+
+// Poll answers every tracked request whose response is overdue with C3h
+// (timeout) and forgets it. [BMC.Run] runs it every
+// [MessagePollInterval].
+func (s *MessageStore) Poll(ctx context.Context) error {
+	now := s.clock.Now()
+	s.mu.Lock()
+	var expired []trackedRequest
+	kept := s.tracked[:0]
+	for _, t := range s.tracked {
+		if now.Before(t.deadline) {
+			kept = append(kept, t)
+		} else {
+			expired = append(expired, t)
+		}
+	}
+	s.tracked = kept
+	s.mu.Unlock()
+
+	var errs []error
+	for _, t := range expired {
+		resp := protocol.BuildIPMBResponse(t.req, uint8(types.CodeProcessTimeout), nil)
+		if err := s.Deliver(ctx, t.origin, t.handle, resp); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,132 @@
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/protocol"
+)
+
+// ipmbRequest builds an IPMB-format message with both checksums.
+func ipmbRequest(rsSA, netFnLUN, rqSA, seqLUN, cmd uint8, data ...byte) []byte {
+	msg := []byte{rsSA, netFnLUN, 0, rqSA, seqLUN, cmd}
+	msg[2] = protocol.Checksum(msg[0:2])
+	msg = append(msg, data...)
+	return append(msg, protocol.Checksum(msg[3:]))
+}
+
+func TestMessageStoreBridge(t *testing.T) {
+	clk := &mockClock{now: time.Now()}
+	s := NewMessageStore(clk)
+	ctx := context.Background()
+	const (
+		ipmb      = 0
+		lan       = 1
+		handle    = 0x05
+		satellite = 0x2C
+	)
+
+	from := IPMBOrigin{Channel: lan, Privilege: PrivilegeLevelOperator}
+
+	var delivered [][]byte
+	s.SetDeliverer(func(_ context.Context, channel, h uint8, msg []byte) error {
+		if channel != lan || h != handle {
+			t.Errorf("delivered to channel %d handle 0x%02x", channel, h)
+		}
+		delivered = append(delivered, msg)
+		return nil
+	})
+
+	// Get Device ID from the remote console (0x81, seq 7) to the satellite.
+	req := ipmbRequest(satellite, 0x06<<2, 0x81, 7<<2|0x01, 0x01)
+	if err := s.Bridge(ctx, ipmb, from, handle, req); !errors.Is(err, ErrIPMBNotConnected) {
+		t.Fatalf("bridge to an unconnected channel: %v", err)
+	}
+
+	var written [][]byte
+	s.ConnectIPMB(ipmb, 0x20, func(ctx context.Context, msg []byte) error {
+		if msg[0] != satellite {
+			return ErrIPMBNAK
+		}
+		if got, ok := IPMBOriginFromContext(ctx); !ok || got != from {
+			t.Errorf("written with origin %+v, %v", got, ok)
+		}
+		written = append(written, msg)
+		return nil
+	})
+	if addr, ok := s.IPMBAddress(ipmb); !ok || addr != 0x20 {
+		t.Fatalf("IPMBAddress = 0x%02x, %v", addr, ok)
+	}
+
+	if err := s.Bridge(ctx, ipmb, from, handle, req); err != nil {
+		t.Fatal(err)
+	}
+	out := written[0]
+	if !protocol.ValidMessage(out) || out[3] != 0x20 || out[0] != satellite || out[5] != 0x01 {
+		t.Fatalf("bridged request % x: the BMC should stand in as the requester", out)
+	}
+
+	// The satellite's answer comes back with the console's addressing.
+	resp := ipmbRequest(0x20, 0x07<<2, satellite, out[4]&0xFC, 0x01, 0x00, 0x42)
+	if err := s.ReceiveIPMB(ctx, ipmb, resp); err != nil {
+		t.Fatal(err)
+	}
+	want := ipmbRequest(0x81, 0x07<<2|0x01, satellite, 7<<2, 0x01, 0x00, 0x42)
+	if len(delivered) != 1 || !bytes.Equal(delivered[0], want) {
+		t.Fatalf("delivered % x, want % x", delivered, want)
+	}
+	// A duplicate response has nothing left to answer.
+	if err := s.ReceiveIPMB(ctx, ipmb, resp); !errors.Is(err, ErrNoMessageRoute) {
+		t.Fatalf("duplicate response: %v", err)
+	}
+
+	if err := s.Bridge(ctx, ipmb, from, handle, ipmbRequest(0x30, 0x06<<2, 0x81, 0, 0x01)); !errors.Is(err, ErrIPMBNAK) {
+		t.Fatalf("absent controller: %v", err)
+	}
+	// The BMC's own address would loop the request back into it.
+	self := ipmbRequest(0x20, 0x06<<2, 0x81, 0, 0x01)
+	if err := s.Bridge(ctx, ipmb, from, handle, self); !errors.Is(err, ErrIPMBSelfAddressed) {
+		t.Fatalf("bridge to the BMC itself: %v", err)
+	}
+	if err := s.WriteIPMB(ctx, ipmb, from, self); !errors.Is(err, ErrIPMBSelfAddressed) {
+		t.Fatalf("write to the BMC itself: %v", err)
+	}
+
+	// An unanswered request times out with C3h.
+	if err := s.Bridge(ctx, ipmb, from, handle, req); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Poll(ctx); err != nil || len(delivered) != 1 {
+		t.Fatalf("poll before the deadline: %v, %d delivered", err, len(delivered))
+	}
+	clk.now = clk.now.Add(DefaultBridgeTimeout)
+	if err := s.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want = ipmbRequest(0x81, 0x07<<2|0x01, satellite, 7<<2, 0x01, 0xC3)
+	if len(delivered) != 2 || !bytes.Equal(delivered[1], want) {
+		t.Fatalf("timeout delivered % x, want % x", delivered, want)
+	}
+
+	// Messages to the SMS LUN go to system software.
+	sms := ipmbRequest(0x20, 0x06<<2|smsLUN, satellite, 0x04, 0x01)
+	if err := s.ReceiveIPMB(ctx, ipmb, sms); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := s.Dequeue()
+	if err != nil || msg.Channel != ipmb || !bytes.Equal(msg.Data, sms) {
+		t.Fatalf("dequeued %+v, %v", msg, err)
+	}
+
+	for i := 0; i < maxTrackedRequests; i++ {
+		if err := s.Bridge(ctx, ipmb, from, handle, req); err != nil {
+			t.Fatalf("bridge %d: %v", i, err)
+		}
+	}
+	if err := s.Bridge(ctx, ipmb, from, handle, req); !errors.Is(err, ErrBridgeTableFull) {
+		t.Fatalf("full table: %v", err)
+	}
+}
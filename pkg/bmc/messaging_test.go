@@ -0,0 +1,85 @@
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/clock"
+)
+
+func TestMessageStoreQueue(t *testing.T) {
+	s := NewMessageStore(clock.Real)
+	const lan = 1
+
+	if s.Available() {
+		t.Fatal("the queue should start empty")
+	}
+	if _, err := s.Dequeue(); !errors.Is(err, ErrReceiveQueueEmpty) {
+		t.Fatalf("empty dequeue: %v", err)
+	}
+
+	data := []byte{0x05, 0x20, 0x18}
+	if err := s.Enqueue(ReceivedMessage{Channel: lan, Privilege: PrivilegeLevelUser, Data: data}); err != nil {
+		t.Fatal(err)
+	}
+	data[0] = 0xff
+	msg, err := s.Dequeue()
+	if err != nil || msg.Channel != lan || msg.Privilege != PrivilegeLevelUser || !bytes.Equal(msg.Data, []byte{0x05, 0x20, 0x18}) {
+		t.Fatalf("dequeued %+v, %v", msg, err)
+	}
+
+	for i := 0; i < ReceiveMessageQueueSize; i++ {
+		if err := s.Enqueue(ReceivedMessage{Channel: lan, Data: []byte{uint8(i)}}); err != nil {
+			t.Fatalf("enqueue %d: %v", i, err)
+		}
+	}
+	if err := s.Enqueue(ReceivedMessage{Channel: lan}); !errors.Is(err, ErrReceiveQueueFull) {
+		t.Fatalf("full queue: %v", err)
+	}
+	if msg, _ := s.Dequeue(); msg.Data[0] != 0 {
+		t.Fatalf("the oldest message comes first, got %+v", msg)
+	}
+	s.Flush()
+	if s.Available() {
+		t.Fatal("Flush should empty the queue")
+	}
+
+	s.SetReceiveEnabled(lan, false)
+	if s.ReceiveEnabled(lan) {
+		t.Fatal("receive should be disabled")
+	}
+	if err := s.Enqueue(ReceivedMessage{Channel: lan}); !errors.Is(err, ErrMessageReceiveDisabled) {
+		t.Fatalf("disabled channel: %v", err)
+	}
+	s.Reset()
+	if !s.ReceiveEnabled(lan) {
+		t.Fatal("Reset should accept every channel again")
+	}
+}
+
+func TestMessageStoreDeliver(t *testing.T) {
+	s := NewMessageStore(clock.Real)
+	ctx := context.Background()
+
+	if err := s.Deliver(ctx, 1, 0x05, nil); !errors.Is(err, ErrNoMessageRoute) {
+		t.Fatalf("no deliverer: %v", err)
+	}
+
+	var got []byte
+	s.SetDeliverer(func(_ context.Context, channel, handle uint8, msg []byte) error {
+		if handle != 0x05 {
+			return fmt.Errorf("handle 0x%02x: %w", handle, ErrNoSession)
+		}
+		got = msg
+		return nil
+	})
+	if err := s.Deliver(ctx, 1, 0x05, []byte{0x81}); err != nil || !bytes.Equal(got, []byte{0x81}) {
+		t.Fatalf("deliver: %v, got % x", err, got)
+	}
+	if err := s.Deliver(ctx, 1, 0x06, nil); !errors.Is(err, ErrNoMessageRoute) {
+		t.Fatalf("unknown handle: %v", err)
+	}
+}
@@ -0,0 +1,849 @@
+package bmc
+
+// Platform Event Filtering (v2.0§17, §30): the event filter table, the
+// alert policy table, the alert strings and the other PEF configuration
+// parameters, the PEF postpone timer, and the engine that runs each new
+// event through the filters, carries out the selected chassis action
+// through [hal.ChassisHAL] and hands alerts to the [LANAlertStore].
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+const (
+	// PEFVersion is the PEF version reported by Get PEF Capabilities
+	// (v2.0§30.1: 51h = v1.5/v2.0).
+	PEFVersion = 0x51
+
+	// DefaultPEFEventFilters is the number of event filter table entries.
+	DefaultPEFEventFilters = 16
+	// DefaultPEFAlertPolicies is the number of alert policy table entries.
+	DefaultPEFAlertPolicies = 16
+	// DefaultPEFAlertStrings is the number of non-volatile alert strings
+	// kept in addition to the volatile alert string 0.
+	DefaultPEFAlertStrings = 4
+	// PEFAlertStringBlocks is the number of 16-byte blocks of each alert
+	// string (v2.0 Table 30-6 #13).
+	PEFAlertStringBlocks = 4
+
+	// PEFPollInterval is how often [BMC.Run] polls the postpone timer and
+	// the startup delay; both count in seconds.
+	PEFPollInterval = time.Second
+)
+
+// PEF action bits, in the layout of the event filter action byte and of
+// Get PEF Capabilities byte 2 (v2.0 Table 17-7, §30.1).
+const (
+	PEFActionAlert               = 1 << 0
+	PEFActionPowerDown           = 1 << 1
+	PEFActionReset               = 1 << 2
+	PEFActionPowerCycle          = 1 << 3
+	PEFActionOEM                 = 1 << 4
+	PEFActionDiagnosticInterrupt = 1 << 5
+	PEFActionGroupControl        = 1 << 6
+)
+
+// PEF Control bits (v2.0 Table 30-6 #1).
+const (
+	PEFControlEnable            = 1 << 0
+	PEFControlEventMessages     = 1 << 1
+	PEFControlStartupDelay      = 1 << 2
+	PEFControlAlertStartupDelay = 1 << 3
+)
+
+// Arm PEF Postpone Timer values with a meaning of their own (v2.0§30.2);
+// 01h-FDh arm the timer for that many seconds.
+const (
+	PEFPostponeDisable          = 0x00
+	PEFPostponeTemporaryDisable = 0xfe
+	PEFPostponeGetCountdown     = 0xff
+)
+
+// DefaultPEFSensorNumber is the sensor number PEF Action events are logged
+// under until [PEFStore.SetSensorNumber] sets the one the platform's sensor
+// records use.
+const DefaultPEFSensorNumber = 0x00
+
+// pefEventAction is the System Event sensor offset logged before a PEF
+// action is taken (v2.0 Table 42-3).
+const pefEventAction = 0x04
+
+// pefAlertStringBlockSize is the size of one alert string block.
+const pefAlertStringBlockSize = 16
+
+// pefMaxPending bounds the events held back while PEF is postponed; later
+// events are left for software, which sees them in the SEL.
+const pefMaxPending = 64
+
+// PEF configuration failures, mapped by the Sensor/Event NetFn handlers to
+// completion codes (v2.0§30.3, §30.4).
+var (
+	// ErrPEFParamNotSupported → CodeParameterNotSupported (80h).
+	ErrPEFParamNotSupported = errors.New("PEF parameter not supported")
+	// ErrPEFSetInProgress → CodeParamConfigSetInProgressConflict (81h).
+	ErrPEFSetInProgress = errors.New("PEF parameters already set in progress")
+	// ErrPEFParamReadOnly → CodeParamConfigSetReadOnly (82h).
+	ErrPEFParamReadOnly = errors.New("PEF parameter is read-only")
+	// ErrPEFParamLength → CodeRequestDataLengthInvalid.
+	ErrPEFParamLength = errors.New("invalid PEF parameter data length")
+	// ErrPEFParamInvalid → CodeRequestDataFieldInvalid: reserved bits set,
+	// or a change to a manufacturer pre-configured filter beyond enabling
+	// or disabling it.
+	ErrPEFParamInvalid = errors.New("invalid PEF parameter data")
+	// ErrPEFParamOutOfRange → CodeParameterOutOfRange: a set or block
+	// selector past the end of its table.
+	ErrPEFParamOutOfRange = errors.New("PEF parameter selector out of range")
+)
+
+// PEFCapabilities is BMC-side PEF capability data (not a wire response).
+// Handlers map this to sensor.GetPEFCapabilitiesResponse (v2.0§30.1).
+type PEFCapabilities struct {
+	Version      uint8
+	Actions      uint8
+	EventFilters uint8
+}
+
+// pefMatch is the outcome of running an event through the event filters.
+type pefMatch struct {
+	ev      PlatformEvent
+	actions uint8
+	// alerts holds the alert policy entries of each policy set selected
+	// by a matching filter with the alert action.
+	alerts    [][]alertStep
+	alert     AlertEvent
+	notBefore time.Time // end of the alert startup delay
+}
+
+// pefEvent is an event held back while PEF is postponed, with the SEL
+// Record ID it was logged under (0000h when it could not be logged).
+type pefEvent struct {
+	ev       PlatformEvent
+	recordID uint16
+}
+
+// PEFStore holds the PEF configuration parameters and runs the PEF engine.
+// The non-volatile parameters are saved with the BMC's non-volatile state;
+// the postpone timer and the last processed event IDs last for the life of
+// the BMC.
+type PEFStore struct {
+	mu       sync.Mutex
+	h        hal.HAL
+	clock    clock.Clock
+	alerts   *LANAlertStore
+	logEvent func(context.Context, PlatformEvent) error
+	// onRestart, when set, records a reset or power cycle action as the
+	// system restart cause.
+	onRestart func(cause uint8)
+
+	sensorNumber uint8
+	start        time.Time // startup delays count from here
+
+	setInProgress     uint8 // #0: 0 = set complete, 1 = set in progress
+	control           uint8 // #1
+	actionControl     uint8 // #2
+	startupDelay      uint8 // #3, seconds
+	alertStartupDelay uint8 // #4, seconds
+	filters           []types.PEFEventFilter
+	policies          []types.PEFAlertPolicy
+	useGUID           bool     // #10 [0]
+	guid              [16]byte // #10
+	stringKeys        [][2]uint8
+	strings           [][]byte // PEFAlertStringBlocks blocks each
+
+	postpone      uint8 // armed timeout, or a PEFPostpone value
+	postponeUntil time.Time
+	pending       []pefEvent
+
+	lastBMC      uint16
+	lastSoftware uint16
+
+	// onChange, when set, is told of each change to the non-volatile
+	// parameters.
+	onChange func()
+}
+
+// NewPEFStore returns a PEF with empty (disabled) filter and policy tables,
+// PEF enabled and every supported action globally enabled. Actions go to
+// h's chassis and alerts, when non-nil, to alerts; logEvent, when non-nil,
+// receives the PEF Action events and must not feed them back into
+// [PEFStore.Process].
+func NewPEFStore(h hal.HAL, clk clock.Clock, alerts *LANAlertStore, logEvent func(context.Context, PlatformEvent) error) *PEFStore {
+	if clk == nil {
+		clk = clock.Real
+	}
+	p := &PEFStore{
+		h:            h,
+		clock:        clk,
+		alerts:       alerts,
+		logEvent:     logEvent,
+		sensorNumber: DefaultPEFSensorNumber,
+		start:        clk.Now(),
+		control:      PEFControlEnable,
+		filters:      make([]types.PEFEventFilter, DefaultPEFEventFilters),
+		policies:     make([]types.PEFAlertPolicy, DefaultPEFAlertPolicies),
+		stringKeys:   make([][2]uint8, DefaultPEFAlertStrings+1),
+		strings:      make([][]byte, DefaultPEFAlertStrings+1),
+	}
+	for i := range p.strings {
+		p.strings[i] = make([]byte, PEFAlertStringBlocks*pefAlertStringBlockSize)
+	}
+	p.actionControl = p.supportedActions()
+	return p
+}
+
+// SetSensorNumber sets the System Event sensor number PEF Action events are
+// logged under.
+func (p *PEFStore) SetSensorNumber(n uint8) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sensorNumber = n
+}
+
+// SetOnRestart sets the function told of each system restart a PEF action
+// causes.
+func (p *PEFStore) SetOnRestart(fn func(cause uint8)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onRestart = fn
+}
+
+// supportedActions returns the PEF actions the chassis and the alert store
+// can carry out.
+func (p *PEFStore) supportedActions() uint8 {
+	var actions uint8
+	if p.alerts != nil {
+		actions |= PEFActionAlert
+	}
+	if p.h == nil {
+		return actions
+	}
+	ch := p.h.Chassis()
+	if ch == nil {
+		return actions
+	}
+	actions |= PEFActionPowerDown | PEFActionReset | PEFActionPowerCycle
+	if _, ok := ch.(hal.DiagnosticInterruptHAL); ok {
+		actions |= PEFActionDiagnosticInterrupt
+	}
+	return actions
+}
+
+// Capabilities returns the PEF capabilities (v2.0§30.1).
+func (p *PEFStore) Capabilities() PEFCapabilities {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PEFCapabilities{
+		Version:      PEFVersion,
+		Actions:      p.supportedActions(),
+		EventFilters: uint8(len(p.filters)),
+	}
+}
+
+// SetEventFilter installs filter n (1-based) as is, bypassing the rules
+// Set PEF Configuration Parameters applies. Platforms use it to provide
+// manufacturer pre-configured filters.
+func (p *PEFStore) SetEventFilter(n uint8, f types.PEFEventFilter) error {
+	p.mu.Lock()
+	dst := p.filterAt(n)
+	if dst == nil {
+		p.mu.Unlock()
+		return ErrPEFParamOutOfRange
+	}
+	*dst = f
+	fn := p.onChange
+	p.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+	return nil
+}
+
+// SetOnChange installs fn to be told, after the store lock is released, of
+// each change to the non-volatile PEF configuration parameters.
+func (p *PEFStore) SetOnChange(fn func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onChange = fn
+}
+
+// filterAt returns event filter n (1-based), or nil when out of range.
+func (p *PEFStore) filterAt(n uint8) *types.PEFEventFilter {
+	if n == 0 || int(n) > len(p.filters) {
+		return nil
+	}
+	return &p.filters[n-1]
+}
+
+// AlertString returns alert string n up to its terminating NUL, or false
+// when n is out of range.
+func (p *PEFStore) AlertString(n uint8) ([]byte, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if int(n) >= len(p.strings) {
+		return nil, false
+	}
+	s := p.strings[n]
+	for i, c := range s {
+		if c == 0 {
+			s = s[:i]
+			break
+		}
+	}
+	return append([]byte(nil), s...), true
+}
+
+// GetParam returns the parameter data for selector (v2.0 Table 30-6). The
+// set and block selectors pick the entry of table parameters and are
+// echoed at the start of their data, as the spec lays them out.
+func (p *PEFStore) GetParam(selector, set, block uint8) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch types.PEFConfigParamSelector(selector) {
+	case types.PEFConfigParamSelector_SetInProgress:
+		return []byte{p.setInProgress}, nil
+	case types.PEFConfigParamSelector_Control:
+		return []byte{p.control}, nil
+	case types.PEFConfigParamSelector_ActionGlobalControl:
+		return []byte{p.actionControl}, nil
+	case types.PEFConfigParamSelector_StartupDelay:
+		return []byte{p.startupDelay}, nil
+	case types.PEFConfigParamSelector_AlertStartDelay:
+		return []byte{p.alertStartupDelay}, nil
+	case types.PEFConfigParamSelector_EventFiltersCount:
+		return []byte{uint8(len(p.filters))}, nil
+	case types.PEFConfigParamSelector_EventFilter:
+		f := p.filterAt(set)
+		if f == nil {
+			return nil, ErrPEFParamOutOfRange
+		}
+		return append([]byte{set}, f.Pack()...), nil
+	case types.PEFConfigParamSelector_EventFilterData1:
+		f := p.filterAt(set)
+		if f == nil {
+			return nil, ErrPEFParamOutOfRange
+		}
+		return []byte{set, f.Pack()[0]}, nil
+	case types.PEFConfigParamSelector_AlertPoliciesCount:
+		return []byte{uint8(len(p.policies))}, nil
+	case types.PEFConfigParamSelector_AlertPolicy:
+		n := set & 0x7f
+		if n == 0 || int(n) > len(p.policies) {
+			return nil, ErrPEFParamOutOfRange
+		}
+		return append([]byte{n}, p.policies[n-1].Pack()...), nil
+	case types.PEFConfigParamSelector_SystemGUID:
+		out := make([]byte, 17)
+		if p.useGUID {
+			out[0] = 0x01
+		}
+		copy(out[1:], p.guid[:])
+		return out, nil
+	case types.PEFConfigParamSelector_AlertStringsCount:
+		return []byte{uint8(len(p.strings) - 1)}, nil
+	case types.PEFConfigParamSelector_AlertStringKey:
+		n := set & 0x7f
+		if int(n) >= len(p.stringKeys) {
+			return nil, ErrPEFParamOutOfRange
+		}
+		return []byte{n, p.stringKeys[n][0], p.stringKeys[n][1]}, nil
+	case types.PEFConfigParamSelector_AlertString:
+		n := set & 0x7f
+		if int(n) >= len(p.strings) || block == 0 || block > PEFAlertStringBlocks {
+			return nil, ErrPEFParamOutOfRange
+		}
+		off := int(block-1) * pefAlertStringBlockSize
+		return append([]byte{n, block}, p.strings[n][off:off+pefAlertStringBlockSize]...), nil
+	default:
+		return nil, ErrPEFParamNotSupported
+	}
+}
+
+// SetParam validates and applies one parameter write (v2.0 Table 30-6).
+// Event filter actions the chassis cannot carry out are cleared, so they
+// read back as 0b (v2.0§17.6).
+func (p *PEFStore) SetParam(selector uint8, data []byte) error {
+	p.mu.Lock()
+	err := p.setParamLocked(selector, data)
+	fn := p.onChange
+	p.mu.Unlock()
+	if err == nil && fn != nil && types.PEFConfigParamSelector(selector) != types.PEFConfigParamSelector_SetInProgress {
+		fn()
+	}
+	return err
+}
+
+// setParamLocked applies one parameter write. The caller holds p.mu.
+func (p *PEFStore) setParamLocked(selector uint8, data []byte) error {
+	sel := types.PEFConfigParamSelector(selector)
+	switch sel {
+	case types.PEFConfigParamSelector_EventFiltersCount,
+		types.PEFConfigParamSelector_AlertPoliciesCount,
+		types.PEFConfigParamSelector_AlertStringsCount:
+		return ErrPEFParamReadOnly
+	}
+	if want, ok := pefParamLength[sel]; !ok {
+		return ErrPEFParamNotSupported
+	} else if len(data) < want {
+		return ErrPEFParamLength
+	}
+
+	switch sel {
+	case types.PEFConfigParamSelector_SetInProgress:
+		// Commit write (10b) requires rollback support, which is not
+		// implemented; 11b is reserved.
+		if data[0] > 1 {
+			return ErrPEFParamInvalid
+		}
+		if data[0] == 1 && p.setInProgress != 0 {
+			return ErrPEFSetInProgress
+		}
+		p.setInProgress = data[0]
+	case types.PEFConfigParamSelector_Control:
+		if data[0]&^0x0f != 0 {
+			return ErrPEFParamInvalid
+		}
+		p.control = data[0]
+	case types.PEFConfigParamSelector_ActionGlobalControl:
+		if data[0]&^0x3f != 0 {
+			return ErrPEFParamInvalid
+		}
+		p.actionControl = data[0] & p.supportedActions()
+	case types.PEFConfigParamSelector_StartupDelay:
+		p.startupDelay = data[0]
+	case types.PEFConfigParamSelector_AlertStartDelay:
+		p.alertStartupDelay = data[0]
+	case types.PEFConfigParamSelector_EventFilter:
+		return p.setFilterLocked(data[0], data[1:21])
+	case types.PEFConfigParamSelector_EventFilterData1:
+		f := p.filterAt(data[0])
+		if f == nil {
+			return ErrPEFParamOutOfRange
+		}
+		if data[1]&0x1f != 0 {
+			return ErrPEFParamInvalid
+		}
+		typ := types.PEFEventFilterType((data[1] >> 5) & 0x03)
+		if f.FilterType == types.PEFEventFilterType_PreConfigured && typ != f.FilterType {
+			return ErrPEFParamInvalid
+		}
+		f.FilterState = data[1]&0x80 != 0
+		f.FilterType = typ
+	case types.PEFConfigParamSelector_AlertPolicy:
+		n := data[0] & 0x7f
+		if n == 0 || int(n) > len(p.policies) {
+			return ErrPEFParamOutOfRange
+		}
+		var pol types.PEFAlertPolicy
+		if err := pol.Unpack(data[1:4]); err != nil {
+			return ErrPEFParamLength
+		}
+		p.policies[n-1] = pol
+	case types.PEFConfigParamSelector_SystemGUID:
+		if data[0]&^0x01 != 0 {
+			return ErrPEFParamInvalid
+		}
+		p.useGUID = data[0]&0x01 != 0
+		copy(p.guid[:], data[1:17])
+	case types.PEFConfigParamSelector_AlertStringKey:
+		n := data[0] & 0x7f
+		if int(n) >= len(p.stringKeys) {
+			return ErrPEFParamOutOfRange
+		}
+		p.stringKeys[n] = [2]uint8{data[1] & 0x7f, data[2] & 0x7f}
+	case types.PEFConfigParamSelector_AlertString:
+		n, block := data[0]&0x7f, data[1]
+		if int(n) >= len(p.strings) || block == 0 || block > PEFAlertStringBlocks {
+			return ErrPEFParamOutOfRange
+		}
+		if len(data)-2 > pefAlertStringBlockSize {
+			return ErrPEFParamLength
+		}
+		dst := p.strings[n][int(block-1)*pefAlertStringBlockSize:][:pefAlertStringBlockSize]
+		clear(dst)
+		copy(dst, data[2:])
+	}
+	return nil
+}
+
+// pefParamLength is the minimum data length of each settable parameter,
+// including its set and block selectors.
+var pefParamLength = map[types.PEFConfigParamSelector]int{
+	types.PEFConfigParamSelector_SetInProgress:       1,
+	types.PEFConfigParamSelector_Control:             1,
+	types.PEFConfigParamSelector_ActionGlobalControl: 1,
+	types.PEFConfigParamSelector_StartupDelay:        1,
+	types.PEFConfigParamSelector_AlertStartDelay:     1,
+	types.PEFConfigParamSelector_EventFilter:         21,
+	types.PEFConfigParamSelector_EventFilterData1:    2,
+	types.PEFConfigParamSelector_AlertPolicy:         4,
+	types.PEFConfigParamSelector_SystemGUID:          17,
+	types.PEFConfigParamSelector_AlertStringKey:      3,
+	types.PEFConfigParamSelector_AlertString:         2,
+}
+
+// setFilterLocked writes event filter n from its 20-byte table entry
+// (v2.0 Table 17-7). Software may only enable or disable a manufacturer
+// pre-configured filter.
+func (p *PEFStore) setFilterLocked(n uint8, entry []byte) error {
+	f := p.filterAt(n)
+	if f == nil {
+		return ErrPEFParamOutOfRange
+	}
+	if entry[0]&0x1f != 0 || entry[1]&0x80 != 0 || entry[2]&0x80 != 0 {
+		return ErrPEFParamInvalid
+	}
+	if f.FilterType == types.PEFEventFilterType_PreConfigured {
+		cur := f.Pack()
+		for i := range cur {
+			mask := byte(0xff)
+			if i == 0 {
+				mask = 0x7f // filter enable
+			}
+			if cur[i]&mask != entry[i]&mask {
+				return ErrPEFParamInvalid
+			}
+		}
+		f.FilterState = entry[0]&0x80 != 0
+		return nil
+	}
+	var nf types.PEFEventFilter
+	if err := nf.Unpack(entry); err != nil {
+		return ErrPEFParamLength
+	}
+	setFilterActions(&nf, filterActions(&nf)&p.supportedActions())
+	*f = nf
+	return nil
+}
+
+// filterActions returns the actions of f as PEF action bits.
+func filterActions(f *types.PEFEventFilter) uint8 {
+	var a uint8
+	a = types.SetOrClearBit0(a, f.ActionAlert)
+	a = types.SetOrClearBit1(a, f.ActionPowerOff)
+	a = types.SetOrClearBit2(a, f.ActionReset)
+	a = types.SetOrClearBit3(a, f.ActionPowerCycle)
+	a = types.SetOrClearBit4(a, f.ActionOEM)
+	a = types.SetOrClearBit5(a, f.ActionDiagnosticInterrupt)
+	a = types.SetOrClearBit6(a, f.ActionGroupControlOperation)
+	return a
+}
+
+// setFilterActions sets the actions of f from PEF action bits.
+func setFilterActions(f *types.PEFEventFilter, a uint8) {
+	f.ActionAlert = a&PEFActionAlert != 0
+	f.ActionPowerOff = a&PEFActionPowerDown != 0
+	f.ActionReset = a&PEFActionReset != 0
+	f.ActionPowerCycle = a&PEFActionPowerCycle != 0
+	f.ActionOEM = a&PEFActionOEM != 0
+	f.ActionDiagnosticInterrupt = a&PEFActionDiagnosticInterrupt != 0
+	f.ActionGroupControlOperation = a&PEFActionGroupControl != 0
+}
+
+// ArmPostponeTimer arms or disables the PEF postpone timer and returns the
+// present countdown (v2.0§30.2). [PEFPostponeGetCountdown] only reads it.
+func (p *PEFStore) ArmPostponeTimer(timeout uint8) uint8 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.clock.Now()
+	switch timeout {
+	case PEFPostponeGetCountdown:
+	case PEFPostponeDisable, PEFPostponeTemporaryDisable:
+		p.postpone = timeout
+	default:
+		p.postpone = timeout
+		p.postponeUntil = now.Add(time.Duration(timeout) * time.Second)
+	}
+	return p.countdownLocked(now)
+}
+
+// countdownLocked returns the present postpone timer value in seconds,
+// rounded up, or the disable value the timer was set to.
+func (p *PEFStore) countdownLocked(now time.Time) uint8 {
+	if p.postpone == PEFPostponeDisable || p.postpone == PEFPostponeTemporaryDisable {
+		return p.postpone
+	}
+	left := p.postponeUntil.Sub(now)
+	if left <= 0 {
+		return 0
+	}
+	return uint8((left + time.Second - 1) / time.Second)
+}
+
+// LastProcessed returns the Record IDs of the last events processed by the
+// BMC and by system software (v2.0§30.6).
+func (p *PEFStore) LastProcessed() (bmcID, softwareID uint16) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastBMC, p.lastSoftware
+}
+
+// SetLastProcessed sets the Record ID of the last event processed by the
+// BMC or, when byBMC is false, by system software (v2.0§30.5).
+func (p *PEFStore) SetLastProcessed(byBMC bool, id uint16) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if byBMC {
+		p.lastBMC = id
+	} else {
+		p.lastSoftware = id
+	}
+}
+
+// heldLocked reports whether event processing is held back by the PEF
+// startup delay or a running postpone timer (v2.0§17.3, §17.4).
+func (p *PEFStore) heldLocked(now time.Time) bool {
+	if p.control&PEFControlStartupDelay != 0 && now.Before(p.start.Add(time.Duration(p.startupDelay)*time.Second)) {
+		return true
+	}
+	return p.postpone != PEFPostponeDisable && p.postpone != PEFPostponeTemporaryDisable &&
+		now.Before(p.postponeUntil)
+}
+
+// Process runs a new event through the event filters (v2.0§17.7), carries
+// out the highest-priority chassis action of the filters it matches and
+// starts their alerts. recordID is the event's SEL Record ID, 0000h when it could not
+// be logged. While the startup delay or the postpone timer holds PEF back
+// the event is queued for [PEFStore.Poll]; a disabled PEF ignores it.
+func (p *PEFStore) Process(ctx context.Context, ev PlatformEvent, recordID uint16) error {
+	p.mu.Lock()
+	if p.control&PEFControlEnable == 0 || p.postpone == PEFPostponeTemporaryDisable {
+		p.mu.Unlock()
+		return nil
+	}
+	if len(p.pending) > 0 || p.heldLocked(p.clock.Now()) {
+		if len(p.pending) < pefMaxPending {
+			p.pending = append(p.pending, pefEvent{ev: ev, recordID: recordID})
+		}
+		p.mu.Unlock()
+		return nil
+	}
+	m := p.matchLocked(ev)
+	p.lastBMC = recordID
+	eventMessages := p.control&PEFControlEventMessages != 0
+	p.mu.Unlock()
+	return p.act(ctx, m, eventMessages)
+}
+
+// Poll processes the queued events once the startup delay has passed and
+// the postpone timer has expired or been disabled. Events whose Record ID
+// is at or before the last one software reports processed are skipped:
+// software has already handled them (v2.0§17.3).
+func (p *PEFStore) Poll(ctx context.Context) error {
+	p.mu.Lock()
+	now := p.clock.Now()
+	if p.postpone != PEFPostponeDisable && p.postpone != PEFPostponeTemporaryDisable &&
+		!now.Before(p.postponeUntil) {
+		p.postpone = PEFPostponeDisable
+	}
+	if len(p.pending) == 0 || p.control&PEFControlEnable == 0 ||
+		p.postpone == PEFPostponeTemporaryDisable || p.heldLocked(now) {
+		p.mu.Unlock()
+		return nil
+	}
+	var matched []pefMatch
+	for _, e := range p.pending {
+		if e.recordID != 0 && e.recordID <= p.lastSoftware {
+			continue
+		}
+		matched = append(matched, p.matchLocked(e.ev))
+		p.lastBMC = e.recordID
+	}
+	p.pending = nil
+	eventMessages := p.control&PEFControlEventMessages != 0
+	p.mu.Unlock()
+
+	var errs []error
+	for _, m := range matched {
+		if err := p.act(ctx, m, eventMessages); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// matchLocked returns the actions of the enabled filters ev matches,
+// limited to the globally enabled actions, and the alerts they select. An
+// alert reports the highest severity of the matching alert filters, and
+// each policy set is alerted once however many filters select it.
+func (p *PEFStore) matchLocked(ev PlatformEvent) pefMatch {
+	m := pefMatch{ev: ev, alert: AlertEvent{Event: ev}}
+	var policies uint16 // bit n: policy set n
+	for i := range p.filters {
+		f := &p.filters[i]
+		if !f.FilterState || !filterMatches(f, ev) {
+			continue
+		}
+		m.actions |= filterActions(f)
+		if f.ActionAlert {
+			policies |= 1 << (f.AlertPolicyNumber & 0x0f)
+			m.alert.Severity = max(m.alert.Severity, f.EventSeverity)
+		}
+	}
+	m.actions &= p.actionControl & p.supportedActions()
+	if m.actions&PEFActionAlert == 0 {
+		return m
+	}
+	for n := uint8(1); n < 16; n++ {
+		if policies&(1<<n) == 0 {
+			continue
+		}
+		var steps []alertStep
+		for _, pol := range p.policies {
+			if pol.PolicyState && pol.PolicyNumber == n {
+				steps = append(steps, alertStep{channel: pol.ChannelNumber, dest: pol.Destination, policy: pol.PolicyAction})
+			}
+		}
+		if len(steps) > 0 {
+			m.alerts = append(m.alerts, steps)
+		}
+	}
+	if p.useGUID {
+		guid := p.guid
+		m.alert.GUID = &guid
+	}
+	if p.control&PEFControlAlertStartupDelay != 0 {
+		m.notBefore = p.start.Add(time.Duration(p.alertStartupDelay) * time.Second)
+	}
+	return m
+}
+
+// filterMatches compares ev against an event filter (v2.0 Table 17-7).
+// FFh in the generator ID bytes, sensor type, sensor number and event
+// trigger fields matches any value; the event offset must be selected in
+// the offset mask.
+func filterMatches(f *types.PEFEventFilter, ev PlatformEvent) bool {
+	gen, evGen := uint16(f.GeneratorID), uint16(ev.GeneratorID)
+	if lo := uint8(gen); lo != 0xff && lo != uint8(evGen) {
+		return false
+	}
+	if hi := uint8(gen >> 8); hi != 0xff && hi != uint8(evGen>>8) {
+		return false
+	}
+	if f.SensorType != 0xff && f.SensorType != ev.SensorType {
+		return false
+	}
+	if f.SensorNumber != 0xff && uint8(f.SensorNumber) != ev.SensorNumber {
+		return false
+	}
+	if f.EventReadingType != 0xff && f.EventReadingType != ev.EventReadingType {
+		return false
+	}
+	if f.EventData1EventOffsetMask&(1<<ev.Offset()) == 0 {
+		return false
+	}
+	ed := ev.EventData
+	return eventDataMatches(ed.EventData1, f.EventData1ANDMask, f.EventData1Compare1, f.EventData1Compare2) &&
+		eventDataMatches(ed.EventData2, f.EventData2ANDMask, f.EventData2Compare1, f.EventData2Compare2) &&
+		eventDataMatches(ed.EventData3, f.EventData3ANDMask, f.EventData3Compare1, f.EventData3Compare2)
+}
+
+// eventDataMatches applies an event data AND mask and compare pair (v2.0
+// Table 17-7): bits set in compare 1 must equal compare 2 exactly, and if
+// any of the other bits are set in compare 2, at least one of them must be
+// set in the data. Bits outside the AND mask are ignored.
+func eventDataMatches(data, and, cmp1, cmp2 uint8) bool {
+	v := data & and
+	if (v^cmp2)&cmp1&and != 0 {
+		return false
+	}
+	anyOf := ^cmp1 & cmp2 & and
+	return anyOf == 0 || v&anyOf != 0
+}
+
+// act carries out the highest-priority chassis action of m (v2.0 Table
+// 17-2: power down, power cycle, reset, diagnostic interrupt), preceded by
+// a PEF Action event when event messages are enabled, then starts its
+// alerts, which come next in priority.
+func (p *PEFStore) act(ctx context.Context, m pefMatch, eventMessages bool) error {
+	actions := m.actions
+	var action uint8
+	switch {
+	case actions&PEFActionPowerDown != 0:
+		action = PEFActionPowerDown
+	case actions&PEFActionPowerCycle != 0:
+		action = PEFActionPowerCycle
+	case actions&PEFActionReset != 0:
+		action = PEFActionReset
+	case actions&PEFActionDiagnosticInterrupt != 0:
+		action = PEFActionDiagnosticInterrupt
+	case actions&PEFActionAlert == 0:
+		return nil
+	}
+	var errs []error
+	if eventMessages && p.logEvent != nil {
+		if err := p.logEvent(ctx, p.actionEvent(actions)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if action != 0 {
+		if err := p.chassisAction(ctx, action); err != nil {
+			errs = append(errs, err)
+		} else {
+			p.recordRestart(action)
+		}
+	}
+	for _, steps := range m.alerts {
+		if err := p.alerts.queue(ctx, m.alert, steps, m.notBefore); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// actionEvent builds the System Event "PEF Action" event (v2.0 Table 42-3):
+// event data 2 carries the actions taken.
+func (p *PEFStore) actionEvent(actions uint8) PlatformEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PlatformEvent{
+		GeneratorID:      types.GeneratorID(types.BMC_SA),
+		SensorType:       types.SensorTypeSystemEvent,
+		SensorNumber:     p.sensorNumber,
+		EventReadingType: types.EventReadingTypeSensorSpecific,
+		EventDir:         types.EventDirAssertion,
+		EventData: types.EventData{
+			EventData1: 0xc0 | pefEventAction,
+			EventData2: actions,
+			EventData3: 0xff,
+		},
+	}
+}
+
+// recordRestart reports the restart cause of a completed chassis action.
+func (p *PEFStore) recordRestart(action uint8) {
+	p.mu.Lock()
+	onRestart := p.onRestart
+	p.mu.Unlock()
+	if onRestart == nil {
+		return
+	}
+	switch action {
+	case PEFActionReset:
+		onRestart(RestartCausePEFReset)
+	case PEFActionPowerCycle:
+		onRestart(RestartCausePEFPowerCycle)
+	}
+}
+
+// chassisAction carries out one PEF chassis action on the managed system.
+func (p *PEFStore) chassisAction(ctx context.Context, action uint8) error {
+	ch := p.h.Chassis()
+	switch action {
+	case PEFActionPowerDown:
+		return ch.SetPower(ctx, false)
+	case PEFActionPowerCycle:
+		return ch.PowerCycle(ctx)
+	case PEFActionReset:
+		return ch.ColdReset(ctx)
+	case PEFActionDiagnosticInterrupt:
+		return ch.(hal.DiagnosticInterruptHAL).DiagnosticInterrupt(ctx)
+	}
+	return nil
+}
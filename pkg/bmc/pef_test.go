@@ -0,0 +1,244 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// pefTestEvent is a UCR going high assertion from temperature sensor 0x10.
+var pefTestEvent = PlatformEvent{
+	GeneratorID:      types.GeneratorID(types.BMC_SA),
+	SensorType:       types.SensorTypeTemperature,
+	SensorNumber:     0x10,
+	EventReadingType: types.EventReadingTypeThreshold,
+	EventDir:         types.EventDirAssertion,
+	EventData:        types.EventData{EventData1: thresholdEventData1 | 0x09, EventData2: 95, EventData3: 90},
+}
+
+// pefTestFilter matches pefTestEvent.
+func pefTestFilter() types.PEFEventFilter {
+	return types.PEFEventFilter{
+		FilterState:               true,
+		GeneratorID:               0xffff,
+		SensorType:                types.SensorTypeTemperature,
+		SensorNumber:              0xff,
+		EventReadingType:          types.EventReadingTypeThreshold,
+		EventData1EventOffsetMask: 1 << 0x09,
+	}
+}
+
+func newTestPEFBMC(t *testing.T) (*BMC, *mockClock, *mock.Chassis) {
+	t.Helper()
+	clk := &mockClock{now: time.Unix(1_700_000_000, 0)}
+	m := mock.New()
+	b := New(DeviceInfo{}, [16]byte{}, m, WithClock(clk))
+	return b, clk, m.Chassis().(*mock.Chassis)
+}
+
+func setTestFilter(t *testing.T, p *PEFStore, n uint8, f types.PEFEventFilter) {
+	t.Helper()
+	if err := p.SetParam(uint8(types.PEFConfigParamSelector_EventFilter), append([]byte{n}, f.Pack()...)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPEFStore_Params(t *testing.T) {
+	b, _, _ := newTestPEFBMC(t)
+	p := b.PEF
+	caps := p.Capabilities()
+	want := uint8(PEFActionAlert | PEFActionPowerDown | PEFActionReset | PEFActionPowerCycle | PEFActionDiagnosticInterrupt)
+	if caps.Version != PEFVersion || caps.Actions != want || caps.EventFilters != DefaultPEFEventFilters {
+		t.Fatalf("capabilities: %+v", caps)
+	}
+
+	// Unsupported actions read back as 0b.
+	f := pefTestFilter()
+	f.ActionOEM, f.ActionPowerCycle = true, true
+	setTestFilter(t, p, 3, f)
+	data, err := p.GetParam(uint8(types.PEFConfigParamSelector_EventFilter), 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got types.PEFConfigParam_EventFilter
+	if err := got.Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if got.SetSelector != 3 || got.Filter.ActionOEM || !got.Filter.ActionPowerCycle {
+		t.Fatalf("filter 3: %+v", got.Filter)
+	}
+
+	for name, tc := range map[string]struct {
+		selector uint8
+		data     []byte
+		want     error
+	}{
+		"read-only count":     {uint8(types.PEFConfigParamSelector_EventFiltersCount), []byte{4}, ErrPEFParamReadOnly},
+		"filter out of range": {uint8(types.PEFConfigParamSelector_EventFilter), append([]byte{DefaultPEFEventFilters + 1}, f.Pack()...), ErrPEFParamOutOfRange},
+		"short filter":        {uint8(types.PEFConfigParamSelector_EventFilter), []byte{1, 0x80}, ErrPEFParamLength},
+		"reserved control":    {uint8(types.PEFConfigParamSelector_Control), []byte{0x10}, ErrPEFParamInvalid},
+		"group control":       {uint8(types.PEFConfigParamSelector_GroupControl), []byte{1, 0, 0, 0, 0, 0}, ErrPEFParamNotSupported},
+		"long string block":   {uint8(types.PEFConfigParamSelector_AlertString), append([]byte{1, 1}, make([]byte, 17)...), ErrPEFParamLength},
+	} {
+		if err := p.SetParam(tc.selector, tc.data); !errors.Is(err, tc.want) {
+			t.Errorf("%s: want %v, got %v", name, tc.want, err)
+		}
+	}
+
+	// Set in progress is a flag a second claimant sees as a conflict.
+	if err := p.SetParam(0, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetParam(0, []byte{1}); !errors.Is(err, ErrPEFSetInProgress) {
+		t.Fatalf("second set in progress: %v", err)
+	}
+
+	// Alert strings span 16-byte blocks.
+	_ = p.SetParam(uint8(types.PEFConfigParamSelector_AlertString), append([]byte{2, 1}, "0123456789abcdef"...))
+	_ = p.SetParam(uint8(types.PEFConfigParamSelector_AlertString), append([]byte{2, 2}, "Fan\x00"...))
+	if s, ok := p.AlertString(2); !ok || string(s) != "0123456789abcdefFan" {
+		t.Fatalf("alert string 2: %q", s)
+	}
+}
+
+func TestPEFStore_PreConfiguredFilter(t *testing.T) {
+	b, _, _ := newTestPEFBMC(t)
+	p := b.PEF
+	f := pefTestFilter()
+	f.FilterType = types.PEFEventFilterType_PreConfigured
+	f.ActionPowerOff = true
+	if err := p.SetEventFilter(1, f); err != nil {
+		t.Fatal(err)
+	}
+
+	// Software may disable the filter...
+	disabled := f
+	disabled.FilterState = false
+	setTestFilter(t, p, 1, disabled)
+	if err := p.SetParam(uint8(types.PEFConfigParamSelector_EventFilterData1), []byte{1, 0xa0}); err != nil {
+		t.Fatal(err)
+	}
+	// ...but not alter it.
+	changed := f
+	changed.SensorNumber = 0x20
+	err := p.SetParam(uint8(types.PEFConfigParamSelector_EventFilter), append([]byte{1}, changed.Pack()...))
+	if !errors.Is(err, ErrPEFParamInvalid) {
+		t.Fatalf("altering a pre-configured filter: %v", err)
+	}
+	if err := p.SetParam(uint8(types.PEFConfigParamSelector_EventFilterData1), []byte{1, 0x80}); !errors.Is(err, ErrPEFParamInvalid) {
+		t.Fatalf("changing the filter type: %v", err)
+	}
+}
+
+func TestPEF_LogEventTakesHighestPriorityAction(t *testing.T) {
+	b, _, ch := newTestPEFBMC(t)
+	ctx := context.Background()
+	reset := pefTestFilter()
+	reset.ActionReset = true
+	setTestFilter(t, b.PEF, 1, reset)
+	off := pefTestFilter()
+	off.ActionPowerOff = true
+	off.SensorNumber = 0x10
+	setTestFilter(t, b.PEF, 2, off)
+	other := pefTestFilter()
+	other.ActionPowerCycle = true
+	other.SensorNumber = 0x11
+	setTestFilter(t, b.PEF, 3, other)
+	_ = b.PEF.SetParam(uint8(types.PEFConfigParamSelector_Control), []byte{PEFControlEnable | PEFControlEventMessages})
+
+	ch.On = true
+	if err := b.LogEvent(ctx, pefTestEvent); err != nil {
+		t.Fatal(err)
+	}
+	if ch.On || ch.ColdResets != 0 || ch.PowerCycles != 0 {
+		t.Fatalf("want power down only: on=%v resets=%d cycles=%d", ch.On, ch.ColdResets, ch.PowerCycles)
+	}
+	rec, _, err := b.SEL.GetEntry(ctx, 0xffff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel, err := types.ParseSEL(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed := sel.Standard.EventData
+	if sel.Standard.SensorType != types.SensorTypeSystemEvent || ed.EventReadingOffset() != pefEventAction ||
+		ed.EventData2 != PEFActionPowerDown|PEFActionReset {
+		t.Fatalf("PEF action event: %+v", sel.Standard)
+	}
+	if bmcID, _ := b.PEF.LastProcessed(); bmcID != 1 {
+		t.Fatalf("last BMC processed: want the event's record 1, got %d", bmcID)
+	}
+
+	// Globally disabled actions are not taken.
+	_ = b.PEF.SetParam(uint8(types.PEFConfigParamSelector_ActionGlobalControl), []byte{PEFActionReset})
+	ch.On = true
+	_ = b.LogEvent(ctx, pefTestEvent)
+	if !ch.On || ch.ColdResets != 1 {
+		t.Fatalf("global action control: on=%v resets=%d", ch.On, ch.ColdResets)
+	}
+}
+
+func TestPEF_PostponeTimer(t *testing.T) {
+	b, clk, ch := newTestPEFBMC(t)
+	ctx := context.Background()
+	f := pefTestFilter()
+	f.ActionPowerCycle = true
+	setTestFilter(t, b.PEF, 1, f)
+
+	if got := b.PEF.ArmPostponeTimer(30); got != 30 {
+		t.Fatalf("armed countdown: %d", got)
+	}
+	_ = b.LogEvent(ctx, pefTestEvent) // record 1, handled by software
+	_ = b.LogEvent(ctx, pefTestEvent) // record 2
+	b.PEF.SetLastProcessed(false, 1)
+
+	clk.now = clk.now.Add(10 * time.Second)
+	_ = b.PEF.Poll(ctx)
+	if ch.PowerCycles != 0 || b.PEF.ArmPostponeTimer(PEFPostponeGetCountdown) != 20 {
+		t.Fatalf("postponed: cycles=%d countdown=%d", ch.PowerCycles, b.PEF.ArmPostponeTimer(PEFPostponeGetCountdown))
+	}
+
+	clk.now = clk.now.Add(20 * time.Second)
+	if err := b.PEF.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ch.PowerCycles != 1 {
+		t.Fatalf("after expiry: want only record 2 acted on, got %d power cycles", ch.PowerCycles)
+	}
+	if bmcID, _ := b.PEF.LastProcessed(); bmcID != 2 {
+		t.Fatalf("last BMC processed: %d", bmcID)
+	}
+
+	// A temporary disable drops events until it is cancelled.
+	b.PEF.ArmPostponeTimer(PEFPostponeTemporaryDisable)
+	_ = b.LogEvent(ctx, pefTestEvent)
+	b.PEF.ArmPostponeTimer(PEFPostponeDisable)
+	_ = b.PEF.Poll(ctx)
+	if ch.PowerCycles != 1 {
+		t.Fatalf("temporary disable: %d power cycles", ch.PowerCycles)
+	}
+}
+
+func TestEventDataMatches(t *testing.T) {
+	for _, tc := range []struct {
+		name                  string
+		data, and, cmp1, cmp2 uint8
+		want                  bool
+	}{
+		{"no mask", 0x5a, 0x00, 0x00, 0x00, true},
+		{"exact match", 0x5a, 0xff, 0xff, 0x5a, true},
+		{"exact mismatch", 0x5b, 0xff, 0xff, 0x5a, false},
+		{"masked out", 0x5b, 0xfe, 0xff, 0x5a, true},
+		{"any bit set", 0x04, 0x0f, 0x00, 0x06, true},
+		{"no bit set", 0x08, 0x0f, 0x00, 0x06, false},
+	} {
+		if got := eventDataMatches(tc.data, tc.and, tc.cmp1, tc.cmp2); got != tc.want {
+			t.Errorf("%s: want %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package bmc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// runState tracks the frontends running the BMC's timed engines (see
+// [BMC.Run]).
+type runState struct {
+	mu      sync.Mutex
+	runners int
+	cancel  context.CancelFunc
+	// engines tracks the engines the current runners started; a new
+	// generation gets a fresh one, so stopping never waits on its
+	// successor.
+	engines *sync.WaitGroup
+	// restoreOnce applies the power restore policy on the first Run only:
+	// that is the emulated BMC's AC power-on.
+	restoreOnce sync.Once
+	// scanInterval is the threshold engine's sampling period; zero or
+	// negative disables it. Set via [WithSensorScanInterval].
+	scanInterval time.Duration
+}
+
+// WithSensorScanInterval sets how often [BMC.Run] samples sensors for
+// threshold events (default [DefaultSensorScanInterval]). Zero or a negative
+// value disables the threshold engine.
+func WithSensorScanInterval(d time.Duration) Option {
+	return func(b *BMC) { b.SetSensorScanInterval(d) }
+}
+
+// SetSensorScanInterval changes the threshold engine's sampling period. It
+// takes effect the next time the engines start.
+func (b *BMC) SetSensorScanInterval(d time.Duration) {
+	b.run.mu.Lock()
+	b.run.scanInterval = d
+	b.run.mu.Unlock()
+}
+
+// Run drives the BMC's timed engines until ctx is canceled: the watchdog
+// countdown, the chassis power sampling behind the POH counter, threshold
+// sensor scanning, the PEF startup delay and postpone timer, LAN alert
+// retries, the expiry of requests bridged onto IPMB and power meter
+// sampling. Before the engines first start, Run applies the power restore
+// policy (v2.0§28.8), since starting the emulated BMC is its AC power-on.
+//
+// Every frontend serving the BMC calls Run for as long as it serves, so a
+// deployment with any one of them (LAN, VM protocol, Redfish) keeps time.
+// The engines run once however many frontends share the BMC, and stop when
+// the last Run returns.
+func (b *BMC) Run(ctx context.Context) {
+	// A chassis that fails to power up is not fatal; the restart cause
+	// simply stays unrecorded.
+	b.run.restoreOnce.Do(func() { _ = b.Chassis.ApplyRestorePolicy(ctx) })
+
+	b.run.mu.Lock()
+	b.run.runners++
+	if b.run.runners == 1 {
+		// The engines outlive the Run that started them while others
+		// still run, so they get a context of their own.
+		engineCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		b.run.cancel = cancel
+		b.run.engines = &sync.WaitGroup{}
+		b.startEnginesLocked(engineCtx, b.run.engines)
+	}
+	b.run.mu.Unlock()
+
+	<-ctx.Done()
+
+	b.run.mu.Lock()
+	b.run.runners--
+	var stopped *sync.WaitGroup
+	if b.run.runners == 0 {
+		b.run.cancel()
+		stopped = b.run.engines
+		b.run.cancel, b.run.engines = nil, nil
+	}
+	b.run.mu.Unlock()
+	if stopped != nil {
+		stopped.Wait()
+	}
+}
+
+// startEnginesLocked starts one goroutine per engine the BMC has, tracked
+// by wg. The caller holds b.run.mu.
+func (b *BMC) startEnginesLocked(ctx context.Context, wg *sync.WaitGroup) {
+	start := func(interval time.Duration, poll func(context.Context) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.runEngine(ctx, interval, poll)
+		}()
+	}
+	if b.run.scanInterval > 0 {
+		// A full SEL or an unreadable repository must not stop sampling;
+		// the next tick tries again.
+		start(b.run.scanInterval, b.ScanSensors)
+	}
+	// A failed watchdog timeout action is not retried: the timer has
+	// already expired and stopped, as on real hardware.
+	start(WatchdogPollInterval, b.Watchdog.Poll)
+	start(ChassisPollInterval, b.Chassis.Poll)
+	// A failed PEF action is not retried; the events stay in the SEL for
+	// software to handle.
+	start(PEFPollInterval, b.PEF.Poll)
+	start(LANAlertPollInterval, b.Alerts.Poll)
+	start(MessagePollInterval, b.Messages.Poll)
+	if b.DCMI.PowerSupported() {
+		start(DCMIPowerSampleInterval, b.DCMI.SamplePower)
+	}
+}
+
+// runEngine calls poll on every tick of the BMC clock until ctx is
+// canceled. A failed poll is retried on the next tick.
+func (b *BMC) runEngine(ctx context.Context, interval time.Duration, poll func(context.Context) error) {
+	ticker := b.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			_ = poll(ctx)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package bmc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// tickClock is a clock whose tickers fire only when the test sends on tick.
+type tickClock struct{ tick chan time.Time }
+
+func (c *tickClock) Now() time.Time                       { return time.Now() }
+func (c *tickClock) NewTimer(d time.Duration) clock.Timer { return clock.Real.NewTimer(d) }
+func (c *tickClock) NewTicker(time.Duration) clock.Ticker { return c }
+func (c *tickClock) C() <-chan time.Time                  { return c.tick }
+func (c *tickClock) Stop()                                {}
+
+// runTicks runs one engine, feeds it two ticks and stops it. The send
+// returns once the engine has taken the tick; the second one waits for the
+// first pass to finish.
+func runTicks(b *BMC, clk *tickClock, poll func(context.Context) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		b.runEngine(ctx, time.Second, poll)
+		close(done)
+	}()
+	clk.tick <- time.Now()
+	clk.tick <- time.Now()
+	cancel()
+	<-done
+}
+
+// TestSensorScanLogsThresholdEvents verifies the threshold engine samples
+// sensors on each clock tick and logs the events raised to the SEL.
+func TestSensorScanLogsThresholdEvents(t *testing.T) {
+	m := mock.New()
+	sdr := &types.SDRFull{
+		SensorNumber:           1,
+		SensorType:             types.SensorTypeTemperature,
+		SensorEventReadingType: types.EventReadingTypeThreshold,
+		SensorInitialization:   types.SensorInitialization{InitScanning: true, InitEvents: true},
+		SensorCapabilities:     types.SensorCapabilities{AutoRearm: true},
+		ReadingFactors:         types.ReadingFactors{M: 1},
+		UCR_Raw:                45,
+	}
+	sdr.Mask.Threshold.UCR = types.Mask_Threshold{Readable: true, High_Assert: true}
+	if err := m.Storage().SDR().Write(context.Background(), 1, sdr.Pack(1)); err != nil {
+		t.Fatal(err)
+	}
+	m.Sensors().(*mock.Sensors).Values = map[uint8]uint8{1: 50}
+
+	clk := &tickClock{tick: make(chan time.Time)}
+	b := New(DeviceInfo{}, [16]byte{}, m, WithClock(clk))
+	runTicks(b, clk, b.ScanSensors)
+
+	info, err := b.SEL.Info(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Entries != 1 {
+		t.Fatalf("want one UCR assertion logged, got %d entries", info.Entries)
+	}
+}
+
+// TestPowerSamplingFeedsStatistics verifies the power meter is sampled on
+// each clock tick, so that power readings cover more than the request's own
+// sample.
+func TestPowerSamplingFeedsStatistics(t *testing.T) {
+	m := mock.New()
+	power := m.Power().(*mock.Power)
+	power.SetWatts(200)
+
+	clk := &tickClock{tick: make(chan time.Time)}
+	b := New(DeviceInfo{}, [16]byte{}, m, WithClock(clk))
+	runTicks(b, clk, b.DCMI.SamplePower)
+
+	power.SetWatts(100)
+	stats, err := b.DCMI.PowerReading(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Current != 100 || stats.Minimum != 100 || stats.Maximum != 200 {
+		t.Fatalf("stats: %+v", stats)
+	}
+}
+
+// TestRunDrivesWatchdogWithoutLAN verifies the watchdog times out under Run
+// alone, as in a deployment serving only the VM protocol, and that the
+// engines keep running until the last of several Runs returns.
+func TestRunDrivesWatchdogWithoutLAN(t *testing.T) {
+	b := New(DeviceInfo{}, [16]byte{}, mock.New())
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	second, cancelSecond := context.WithCancel(context.Background())
+	done := make(chan struct{}, 2)
+	for _, ctx := range []context.Context{first, second} {
+		go func() {
+			b.Run(ctx)
+			done <- struct{}{}
+		}()
+	}
+	for running := 0; running != 2; {
+		time.Sleep(time.Millisecond)
+		b.run.mu.Lock()
+		running = b.run.runners
+		b.run.mu.Unlock()
+	}
+	// The frontend that stops first must not take the engines with it.
+	cancelFirst()
+	<-done
+
+	cfg := WatchdogConfig{TimerUse: WatchdogTimerUseSMSOS, InitialCountdown: 1}
+	if err := b.Watchdog.Set(cfg, false, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Watchdog.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for b.Watchdog.Status().ExpirationFlags == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("watchdog did not time out under Run")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancelSecond()
+	<-done
+}
@@ -2,6 +2,8 @@ package bmc
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/bougou/go-ipmi/pkg/clock"
@@ -14,6 +16,22 @@ import (
 // handlers clamp values larger than that when packing the wire response.
 const defaultSDRRepoSize = 64 * 1024
 
+// SDR repository update failures, mapped by the Storage NetFn handlers to
+// completion codes (v2.0§33).
+var (
+	// ErrSDRReservationCanceled → CodeReservationCanceled.
+	ErrSDRReservationCanceled = errors.New("SDR repository reservation canceled")
+	// ErrSDRRepoFull → CodeOutOfSpace: the record does not fit in the
+	// repository.
+	ErrSDRRepoFull = errors.New("SDR repository full")
+	// ErrSDRRecordMismatch → CodePartialAddRecordMismatch (80h) for Partial
+	// Add SDR: the record length does not match its header.
+	ErrSDRRecordMismatch = errors.New("SDR record length mismatch")
+	// ErrSDRPartialOffset → CodeParameterOutOfRange: a partial add fragment
+	// does not continue where the previous one ended.
+	ErrSDRPartialOffset = errors.New("SDR partial add offset out of sequence")
+)
+
 // SDRCapabilities describes which SDR repository operations this BMC supports.
 // Handlers map these flags onto storage.SDROperationSupport (v2.0§33.9).
 type SDRCapabilities struct {
@@ -47,19 +65,56 @@ type SDRRepoAllocInfo struct {
 	MaximumRecordSize  uint8
 }
 
-// SDRRepository reads SDR records from [hal.SDRStore] and implements
-// repository semantics for Storage NetFn handlers (v2.0§33).
+// sdrPartialAdd is a record being assembled by Partial Add SDR.
+type sdrPartialAdd struct {
+	recordID uint16
+	data     []byte
+}
+
+// SDRRepository reads and writes SDR records in [hal.SDRStore] and
+// implements repository semantics for Storage NetFn handlers (v2.0§33).
+// Records are kept in wire format; the BMC owns the Record ID (bytes 0-1).
+//
+// Both update modes are supported: records may be changed at any time, and
+// Enter SDR Repository Update Mode additionally makes Get SDR unavailable
+// until the update is over. Sensors follow the new records once the
+// Initialization Agent runs ([SensorStore.Reload]).
 type SDRRepository struct {
 	store hal.SDRStore
 	clk   clock.Clock
+
+	mu           sync.Mutex
+	reservations *SDRRepoStore
+	nextID       uint16
+	partial      *sdrPartialAdd
+	updateMode   bool
+	overflow     bool
+	lastAdd      time.Time
+	lastErase    time.Time
 }
 
-// NewSDRRepository returns a repository backed by store.
+// NewSDRRepository returns a repository backed by store, with reservations
+// of its own until [SDRRepository.SetReservations] shares the BMC's.
 func NewSDRRepository(store hal.SDRStore, clk clock.Clock) *SDRRepository {
 	if clk == nil {
 		clk = clock.Real
 	}
-	return &SDRRepository{store: store, clk: clk}
+	return &SDRRepository{
+		store:        store,
+		clk:          clk,
+		reservations: NewSDRRepoStore(),
+		nextID:       1,
+		lastAdd:      selTimestampUnspecified,
+		lastErase:    selTimestampUnspecified,
+	}
+}
+
+// SetReservations makes the repository check and cancel reservations in
+// r, the tracker Reserve SDR Repository hands them out from.
+func (r *SDRRepository) SetReservations(reservations *SDRRepoStore) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reservations = reservations
 }
 
 // RecordIDs returns the sorted list of stored record IDs.
@@ -144,16 +199,22 @@ func (r *SDRRepository) Info(ctx context.Context) (*SDRRepoInfo, error) {
 		return nil, err
 	}
 	free := defaultSDRRepoSize - used
-	now := r.clk.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return &SDRRepoInfo{
 		SDRVersion:      types.SDRCommandSetVersion,
 		RecordCount:     uint16(len(ids)),
 		FreeBytes:       free,
-		MostRecentAdd:   now,
-		MostRecentErase: now,
+		MostRecentAdd:   r.lastAdd,
+		MostRecentErase: r.lastErase,
+		Overflow:        r.overflow,
 		Capabilities: SDRCapabilities{
-			ReserveRepo:  true,
-			GetAllocInfo: true,
+			ModalUpdate:    true,
+			NonModalUpdate: true,
+			DeleteSDR:      true,
+			PartialAddSDR:  true,
+			ReserveRepo:    true,
+			GetAllocInfo:   true,
 		},
 	}, nil
 }
@@ -187,6 +248,229 @@ func (r *SDRRepository) AllocInfo(ctx context.Context) (*SDRRepoAllocInfo, error
 	}, nil
 }
 
+// UpdateMode reports whether the repository is in update mode (v2.0§33.19).
+func (r *SDRRepository) UpdateMode() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.updateMode
+}
+
+// EnterUpdateMode puts the repository in update mode (v2.0§33.19).
+func (r *SDRRepository) EnterUpdateMode() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updateMode = true
+}
+
+// ExitUpdateMode ends update mode (v2.0§33.20), as a BMC reset also does.
+func (r *SDRRepository) ExitUpdateMode() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updateMode = false
+}
+
+// Add stores a whole record and returns its assigned Record ID (v2.0§33.13).
+// The Record ID bytes of record are ignored. The change cancels any
+// reservation.
+func (r *SDRRepository) Add(ctx context.Context, record []byte) (uint16, error) {
+	if !sdrRecordComplete(record) {
+		return 0, ErrSDRRecordMismatch
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, err := r.allocIDLocked(ctx, len(record))
+	if err != nil {
+		return 0, err
+	}
+	return id, r.commitLocked(ctx, id, record)
+}
+
+// sdrRecordComplete reports whether record is exactly as long as its
+// header's record length says.
+func sdrRecordComplete(record []byte) bool {
+	return len(record) >= types.SDRRecordHeaderSize &&
+		len(record) == types.SDRRecordHeaderSize+int(record[4])
+}
+
+// allocIDLocked picks the next free Record ID, skipping the reserved values
+// 0000h and FFFFh, and reports ErrSDRRepoFull when size more bytes do not
+// fit. The caller holds r.mu.
+func (r *SDRRepository) allocIDLocked(ctx context.Context, size int) (uint16, error) {
+	if err := r.fitsLocked(ctx, size); err != nil {
+		return 0, err
+	}
+	ids, err := r.store.RecordIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	inUse := make(map[uint16]bool, len(ids))
+	for _, id := range ids {
+		inUse[id] = true
+	}
+	if r.partial != nil {
+		inUse[r.partial.recordID] = true
+	}
+	for {
+		id := r.nextID
+		r.nextID++
+		if id == 0 || id == 0xffff || inUse[id] {
+			continue
+		}
+		return id, nil
+	}
+}
+
+// fitsLocked reports ErrSDRRepoFull, and records the overflow, when size
+// more bytes do not fit in the repository. The caller holds r.mu.
+func (r *SDRRepository) fitsLocked(ctx context.Context, size int) error {
+	used, _, err := r.scanRecords(ctx)
+	if err != nil {
+		return err
+	}
+	if used+size > defaultSDRRepoSize {
+		r.overflow = true
+		return ErrSDRRepoFull
+	}
+	return nil
+}
+
+// commitLocked writes record under id, cancels any reservation and
+// records the addition time. The caller holds r.mu.
+func (r *SDRRepository) commitLocked(ctx context.Context, id uint16, record []byte) error {
+	rec := append([]byte(nil), record...)
+	types.PackUint16L(id, rec, 0)
+	if err := r.store.Write(ctx, id, rec); err != nil {
+		return err
+	}
+	r.reservations.Cancel()
+	r.lastAdd = r.nowLocked()
+	return nil
+}
+
+func (r *SDRRepository) nowLocked() time.Time {
+	return r.clk.Now().Truncate(time.Second)
+}
+
+// PartialAdd accumulates one fragment of a record (v2.0§33.14). The first
+// fragment passes recordID 0 and offset 0; the returned Record ID must be
+// passed with every following fragment. The record is committed when last
+// is set, and must then be as long as its header says.
+//
+// The first fragment must find room for the whole record its header
+// announces, and the record must still fit when it is committed: other
+// records may have been added in between.
+func (r *SDRRepository) PartialAdd(ctx context.Context, reservationID, recordID uint16, offset uint8, data []byte, last bool) (uint16, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.reservations.Validate(reservationID) {
+		return 0, ErrSDRReservationCanceled
+	}
+
+	if recordID == 0 {
+		if offset != 0 {
+			return 0, ErrSDRPartialOffset
+		}
+		r.partial = nil
+		size := len(data)
+		if len(data) >= types.SDRRecordHeaderSize {
+			size = types.SDRRecordHeaderSize + int(data[4])
+		}
+		id, err := r.allocIDLocked(ctx, size)
+		if err != nil {
+			return 0, err
+		}
+		r.partial = &sdrPartialAdd{recordID: id}
+	}
+	p := r.partial
+	if p == nil || (recordID != 0 && p.recordID != recordID) {
+		return 0, hal.ErrNotFound
+	}
+	if int(offset) != len(p.data) {
+		return 0, ErrSDRPartialOffset
+	}
+	if len(p.data)+len(data) > types.SDRRecordHeaderSize+0xff {
+		r.partial = nil
+		return 0, ErrSDRRecordMismatch
+	}
+	p.data = append(p.data, data...)
+	if !last {
+		return p.recordID, nil
+	}
+
+	r.partial = nil
+	if !sdrRecordComplete(p.data) {
+		return 0, ErrSDRRecordMismatch
+	}
+	if err := r.fitsLocked(ctx, len(p.data)); err != nil {
+		return 0, err
+	}
+	return p.recordID, r.commitLocked(ctx, p.recordID, p.data)
+}
+
+// Delete removes one record and returns its Record ID (v2.0§33.15). It
+// needs the current reservation and cancels it.
+func (r *SDRRepository) Delete(ctx context.Context, reservationID, recordID uint16) (uint16, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.reservations.Validate(reservationID) {
+		return 0, ErrSDRReservationCanceled
+	}
+	if err := r.deleteLocked(ctx, recordID); err != nil {
+		return 0, err
+	}
+	return recordID, nil
+}
+
+// deleteLocked removes one record, cancels any reservation and records the
+// erase time. The caller holds r.mu.
+func (r *SDRRepository) deleteLocked(ctx context.Context, recordID uint16) error {
+	ids, err := r.store.RecordIDs(ctx)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, id := range ids {
+		if id == recordID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return hal.ErrNotFound
+	}
+	if err := r.store.Delete(ctx, recordID); err != nil {
+		return err
+	}
+	r.reservations.Cancel()
+	r.lastErase = r.nowLocked()
+	return nil
+}
+
+// Clear erases every record (v2.0§33.16). It needs the current
+// reservation and cancels it. Erasure completes at once.
+func (r *SDRRepository) Clear(ctx context.Context, reservationID uint16) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.reservations.Validate(reservationID) {
+		return ErrSDRReservationCanceled
+	}
+	ids, err := r.store.RecordIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := r.store.Delete(ctx, id); err != nil {
+			return err
+		}
+	}
+	r.reservations.Cancel()
+	r.partial = nil
+	r.overflow = false
+	r.nextID = 1
+	r.lastErase = r.nowLocked()
+	return nil
+}
+
 // StorageMissing reports whether err indicates a missing FRU device or SDR record
 // (mapped to completion code CBh by storage handlers).
 func StorageMissing(err error) bool {
@@ -2,7 +2,9 @@ package bmc
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/bougou/go-ipmi/pkg/clock"
 	"github.com/bougou/go-ipmi/pkg/hal/mock"
@@ -29,3 +31,173 @@ func TestSDRRepositoryInfo_EmptyRepoFreeBytes(t *testing.T) {
 		t.Fatalf("unexpected capabilities: %+v", info.Capabilities)
 	}
 }
+
+// testSDRRecord returns an SDR of n body bytes with the Record ID left 0.
+func testSDRRecord(n int) []byte {
+	rec := make([]byte, types.SDRRecordHeaderSize+n)
+	rec[2] = types.SDRCommandSetVersion
+	rec[3] = 0x12
+	rec[4] = uint8(n)
+	for i := types.SDRRecordHeaderSize; i < len(rec); i++ {
+		rec[i] = 0xee
+	}
+	return rec
+}
+
+func TestSDRRepository_AddAndDeleteTimestamps(t *testing.T) {
+	clk := &mockClock{now: time.Unix(1700000000, 0)}
+	repo := NewSDRRepository(mock.New().Storage().SDR(), clk)
+	ctx := context.Background()
+
+	info, _ := repo.Info(ctx)
+	if info.MostRecentAdd != selTimestampUnspecified || info.MostRecentErase != selTimestampUnspecified {
+		t.Fatalf("fresh repository timestamps: %v %v", info.MostRecentAdd, info.MostRecentErase)
+	}
+	if c := info.Capabilities; !c.ModalUpdate || !c.NonModalUpdate || !c.DeleteSDR || !c.PartialAddSDR {
+		t.Fatalf("update capabilities: %+v", c)
+	}
+
+	if _, err := repo.Add(ctx, testSDRRecord(4)[:7]); !errors.Is(err, ErrSDRRecordMismatch) {
+		t.Fatalf("short record: want ErrSDRRecordMismatch, got %v", err)
+	}
+	id, err := repo.Add(ctx, testSDRRecord(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, _, err := repo.GetRecord(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := types.UnpackUint16L(rec, 0); got != id {
+		t.Fatalf("stored Record ID: want %#04x got %#04x", id, got)
+	}
+	info, _ = repo.Info(ctx)
+	if !info.MostRecentAdd.Equal(clk.now) || info.RecordCount != 1 {
+		t.Fatalf("after add: %+v", info)
+	}
+
+	resID := repo.reservations.Reserve()
+	clk.now = clk.now.Add(time.Minute)
+	if _, err := repo.Delete(ctx, resID, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Delete(ctx, resID, id); !errors.Is(err, ErrSDRReservationCanceled) {
+		t.Fatalf("delete cancels the reservation: got %v", err)
+	}
+	if _, err := repo.Delete(ctx, repo.reservations.Reserve(), id); !StorageMissing(err) {
+		t.Fatalf("deleted record: want not found, got %v", err)
+	}
+	info, _ = repo.Info(ctx)
+	if !info.MostRecentErase.Equal(clk.now) || info.RecordCount != 0 {
+		t.Fatalf("after delete: %+v", info)
+	}
+}
+
+func TestSDRRepository_AddCancelsReservation(t *testing.T) {
+	repo := NewSDRRepository(mock.New().Storage().SDR(), nil)
+	reservations := NewSDRRepoStore()
+	repo.SetReservations(reservations)
+	resID := reservations.Reserve()
+	if _, err := repo.Add(context.Background(), testSDRRecord(2)); err != nil {
+		t.Fatal(err)
+	}
+	if reservations.Validate(resID) {
+		t.Fatal("Add SDR left the reservation valid")
+	}
+}
+
+func TestSDRRepository_PartialAdd(t *testing.T) {
+	repo := NewSDRRepository(mock.New().Storage().SDR(), nil)
+	ctx := context.Background()
+	rec := testSDRRecord(10)
+
+	if _, err := repo.PartialAdd(ctx, 0, 0, 0, rec[:8], false); !errors.Is(err, ErrSDRReservationCanceled) {
+		t.Fatalf("want ErrSDRReservationCanceled, got %v", err)
+	}
+	resID := repo.reservations.Reserve()
+	id, err := repo.PartialAdd(ctx, resID, 0, 0, rec[:8], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.PartialAdd(ctx, resID, id, 4, rec[8:], true); !errors.Is(err, ErrSDRPartialOffset) {
+		t.Fatalf("out-of-sequence offset: want ErrSDRPartialOffset, got %v", err)
+	}
+	if got, err := repo.PartialAdd(ctx, resID, id, 8, rec[8:], true); err != nil || got != id {
+		t.Fatalf("last part: id=%#04x err=%v", got, err)
+	}
+	if _, _, err := repo.GetRecord(ctx, id); err != nil {
+		t.Fatalf("committed record not readable: %v", err)
+	}
+
+	resID = repo.reservations.Reserve()
+	id, err = repo.PartialAdd(ctx, resID, 0, 0, rec[:8], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.PartialAdd(ctx, resID, id, 8, rec[8:12], true); !errors.Is(err, ErrSDRRecordMismatch) {
+		t.Fatalf("short record: want ErrSDRRecordMismatch, got %v", err)
+	}
+}
+
+// TestSDRRepository_PartialAddReservesRecord verifies the first fragment
+// must find room for the whole record its header announces, not just for
+// itself.
+func TestSDRRepository_PartialAddReservesRecord(t *testing.T) {
+	repo := NewSDRRepository(mock.New().Storage().SDR(), nil)
+	ctx := context.Background()
+	big := testSDRRecord(0xff)
+	for {
+		if _, err := repo.Add(ctx, big); errors.Is(err, ErrSDRRepoFull) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	info, err := repo.Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.FreeBytes < 8 || info.FreeBytes >= len(big) {
+		t.Fatalf("free bytes %d: the test needs room for a fragment but not the record", info.FreeBytes)
+	}
+
+	resID := repo.reservations.Reserve()
+	if _, err := repo.PartialAdd(ctx, resID, 0, 0, big[:8], false); !errors.Is(err, ErrSDRRepoFull) {
+		t.Fatalf("want ErrSDRRepoFull for a record that cannot fit, got %v", err)
+	}
+	small := testSDRRecord(2)
+	if _, err := repo.PartialAdd(ctx, resID, 0, 0, small, true); err != nil {
+		t.Fatalf("a record that fits: %v", err)
+	}
+}
+
+func TestSDRRepository_ClearAndUpdateMode(t *testing.T) {
+	repo := NewSDRRepository(mock.New().Storage().SDR(), &mockClock{now: time.Unix(1700000000, 0)})
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := repo.Add(ctx, testSDRRecord(4)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Clear(ctx, 0); !errors.Is(err, ErrSDRReservationCanceled) {
+		t.Fatalf("clear without reservation: got %v", err)
+	}
+	if err := repo.Clear(ctx, repo.reservations.Reserve()); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := repo.RecordIDs(ctx); len(ids) != 0 {
+		t.Fatalf("records left after clear: %v", ids)
+	}
+	if id, _ := repo.Add(ctx, testSDRRecord(4)); id != 1 {
+		t.Fatalf("record IDs restart after clear: got %#04x", id)
+	}
+
+	repo.EnterUpdateMode()
+	if !repo.UpdateMode() {
+		t.Fatal("not in update mode")
+	}
+	repo.ExitUpdateMode()
+	if repo.UpdateMode() {
+		t.Fatal("still in update mode")
+	}
+}
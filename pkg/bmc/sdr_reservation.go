@@ -26,6 +26,14 @@ func (s *SDRRepoStore) Reserve() uint16 {
 	return s.reservationID
 }
 
+// Cancel invalidates the active reservation, as a change to the repository
+// does (v2.0§33.11).
+func (s *SDRRepoStore) Cancel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reservationID = 0
+}
+
 // Validate reports whether id matches the active reservation.
 func (s *SDRRepoStore) Validate(id uint16) bool {
 	s.mu.Lock()
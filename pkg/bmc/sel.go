@@ -0,0 +1,513 @@
+package bmc
+
+// System Event Log device (v2.0§31): record ID assignment, timestamping,
+// reservations, the asynchronous erase of Clear SEL, and SEL Time.
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+const (
+	// SELVersion is the SEL command set version reported by Get SEL Info
+	// (v2.0§31.2: 51h = v1.5/v2.0 compliant).
+	SELVersion = 0x51
+
+	// SELRecordSize is the fixed size of every SEL record (v2.0§32).
+	SELRecordSize = 16
+
+	// DefaultSELCapacity is the number of records the SEL holds before Add
+	// SEL Entry reports out of space and the overflow flag is set.
+	DefaultSELCapacity = 1024
+
+	// SELTimeUTCOffsetUnspecified is the SEL Time UTC Offset value meaning
+	// "unspecified" (v2.0§31.11a).
+	SELTimeUTCOffsetUnspecified int16 = 0x07ff
+
+	// selTimeOffsetRecord is the offset of the 4-byte timestamp in standard
+	// and timestamped OEM records (v2.0 Table 32-1, Table 32-2).
+	selTimeOffsetRecord = 3
+)
+
+// selTimestampUnspecified packs as FFFFFFFFh, the "no entry ever made"
+// timestamp of Get SEL Info (v2.0§31.2).
+var selTimestampUnspecified = types.ParseTimestamp(0xffffffff)
+
+// SEL operation failures, mapped by the Storage NetFn handlers to completion
+// codes (v2.0§31).
+var (
+	// ErrSELReservationCanceled → CodeReservationCanceled.
+	ErrSELReservationCanceled = errors.New("SEL reservation canceled")
+	// ErrSELEraseInProgress → CodeSELEraseInProgress (81h).
+	ErrSELEraseInProgress = errors.New("SEL erase in progress")
+	// ErrSELFull → CodeOutOfSpace: the SEL holds its capacity of records.
+	ErrSELFull = errors.New("SEL full")
+	// ErrSELRecordType → CodeAddSELEntryRecordTypeNotSupported (80h): a
+	// standard-range record type other than 02h (v2.0§31.6.1).
+	ErrSELRecordType = errors.New("SEL record type not supported")
+	// ErrSELRecordMismatch → CodePartialAddRecordMismatch (80h): a partial
+	// add did not produce exactly one 16-byte record.
+	ErrSELRecordMismatch = errors.New("SEL partial add record length mismatch")
+	// ErrSELPartialOffset → CodeParameterOutOfRange: a partial add fragment
+	// does not continue where the previous one ended.
+	ErrSELPartialOffset = errors.New("SEL partial add offset out of sequence")
+)
+
+// SELInfo is BMC-side SEL status (not a wire response).
+// Handlers map this to storage.GetSELInfoResponse (v2.0§31.2).
+type SELInfo struct {
+	Version         uint8
+	Entries         uint16
+	FreeBytes       int // raw free capacity; §31.2 wire encoding is the handler's job
+	MostRecentAdd   time.Time
+	MostRecentErase time.Time
+	Overflow        bool
+}
+
+// SELAllocInfo is BMC-side SEL allocation accounting (not a wire response).
+// Handlers map this to storage.GetSELAllocInfoResponse (v2.0§31.3).
+type SELAllocInfo struct {
+	PossibleAllocUnits uint16
+	AllocUnitSize      uint16
+	FreeAllocUnits     uint16
+	LargestFreeBlock   uint16
+	MaximumRecordSize  uint8
+}
+
+// selPartialAdd is a record being assembled by Partial Add SEL Entry.
+type selPartialAdd struct {
+	recordID uint16
+	data     []byte
+}
+
+// SELStore implements SEL device semantics over the [hal.SELStore] blob
+// store. Records are kept in wire format; the BMC owns the Record ID (bytes
+// 0-1) and, for timestamped record types, the timestamp (bytes 3-6).
+type SELStore struct {
+	mu    sync.Mutex
+	store hal.SELStore
+	clock clock.Clock
+
+	capacity      int
+	eraseDuration time.Duration
+
+	reservationID uint16
+	generation    uint16
+	nextID        uint16
+	overflow      bool
+	partial       *selPartialAdd
+
+	lastAdd    time.Time
+	lastErase  time.Time
+	eraseUntil time.Time // erase reported in progress until this clock time
+
+	timeOffset time.Duration // SEL Time minus clock time
+	utcOffset  int16         // minutes; SELTimeUTCOffsetUnspecified when unset
+}
+
+// NewSELStore returns the SEL device backed by h's storage. A nil HAL or a
+// HAL without SEL storage yields a store whose [SELStore.Supported] is false.
+func NewSELStore(h hal.HAL, clk clock.Clock) *SELStore {
+	if clk == nil {
+		clk = clock.Real
+	}
+	s := &SELStore{
+		clock:     clk,
+		capacity:  DefaultSELCapacity,
+		nextID:    1,
+		lastAdd:   selTimestampUnspecified,
+		lastErase: selTimestampUnspecified,
+		utcOffset: SELTimeUTCOffsetUnspecified,
+	}
+	if h != nil {
+		if st := h.Storage(); st != nil {
+			s.store = st.SEL()
+		}
+	}
+	return s
+}
+
+// Supported reports whether a SEL blob store backs this device.
+func (s *SELStore) Supported() bool { return s.store != nil }
+
+// SetCapacity sets the number of records the SEL can hold. Values below 1
+// are ignored.
+func (s *SELStore) SetCapacity(n int) {
+	if n < 1 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capacity = n
+}
+
+// SetEraseDuration sets how long Clear SEL reports the erase as in progress
+// (v2.0§31.9). The default of zero completes erasure immediately.
+func (s *SELStore) SetEraseDuration(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.eraseDuration = d
+}
+
+// Reserve invalidates any prior reservation and returns a new non-zero ID
+// (v2.0§31.4).
+func (s *SELStore) Reserve() uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	if s.generation == 0 {
+		s.generation = 1
+	}
+	s.reservationID = s.generation
+	return s.reservationID
+}
+
+// Validate reports whether id matches the active reservation.
+func (s *SELStore) Validate(id uint16) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.validateLocked(id)
+}
+
+func (s *SELStore) validateLocked(id uint16) bool {
+	return id != 0 && id == s.reservationID
+}
+
+func (s *SELStore) erasingLocked() bool {
+	return s.clock.Now().Before(s.eraseUntil)
+}
+
+// Info returns BMC-side SEL status per v2.0§31.2 semantics.
+func (s *SELStore) Info(ctx context.Context) (*SELInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, err := s.store.RecordIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	free := (s.capacity - len(ids)) * SELRecordSize
+	if free < 0 {
+		free = 0
+	}
+	return &SELInfo{
+		Version:         SELVersion,
+		Entries:         uint16(len(ids)),
+		FreeBytes:       free,
+		MostRecentAdd:   s.lastAdd,
+		MostRecentErase: s.lastErase,
+		Overflow:        s.overflow,
+	}, nil
+}
+
+// AllocInfo returns BMC-side allocation accounting per v2.0§31.3 semantics.
+// Every record occupies exactly one 16-byte allocation unit.
+func (s *SELStore) AllocInfo(ctx context.Context) (*SELAllocInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, err := s.store.RecordIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	freeUnits := s.capacity - len(ids)
+	if freeUnits < 0 {
+		freeUnits = 0
+	}
+	return &SELAllocInfo{
+		PossibleAllocUnits: uint16(s.capacity),
+		AllocUnitSize:      SELRecordSize,
+		FreeAllocUnits:     uint16(freeUnits),
+		LargestFreeBlock:   uint16(freeUnits),
+		MaximumRecordSize:  1,
+	}, nil
+}
+
+// GetEntry returns the wire record and the next Record ID for traversal.
+// Per v2.0§31.5: recordID 0000h maps to the first entry; FFFFh maps to the
+// last. The next Record ID of the last entry is FFFFh.
+func (s *SELStore) GetEntry(ctx context.Context, recordID uint16) (record []byte, nextID uint16, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.erasingLocked() {
+		return nil, 0, ErrSELEraseInProgress
+	}
+	ids, idx, err := s.lookupLocked(ctx, recordID)
+	if err != nil {
+		return nil, 0, err
+	}
+	record, err = s.store.Read(ctx, ids[idx])
+	if err != nil {
+		return nil, 0, err
+	}
+	if idx+1 < len(ids) {
+		nextID = ids[idx+1]
+	} else {
+		nextID = 0xffff
+	}
+	return record, nextID, nil
+}
+
+// lookupLocked resolves recordID (including the 0000h/FFFFh aliases) to an
+// index into the current record ID list.
+func (s *SELStore) lookupLocked(ctx context.Context, recordID uint16) ([]uint16, int, error) {
+	ids, err := s.store.RecordIDs(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(ids) == 0 {
+		return nil, 0, hal.ErrNotFound
+	}
+	switch recordID {
+	case 0:
+		return ids, 0, nil
+	case 0xffff:
+		return ids, len(ids) - 1, nil
+	}
+	for i, id := range ids {
+		if id == recordID {
+			return ids, i, nil
+		}
+	}
+	return nil, 0, hal.ErrNotFound
+}
+
+// Add stores a 16-byte SEL record and returns its assigned Record ID
+// (v2.0§31.6). Record ID bytes in record are ignored; standard (02h) and
+// timestamped OEM (C0h-DFh) records are stamped with the current SEL Time.
+func (s *SELStore) Add(ctx context.Context, record []byte) (uint16, error) {
+	if len(record) != SELRecordSize {
+		return 0, ErrSELRecordMismatch
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.erasingLocked() {
+		return 0, ErrSELEraseInProgress
+	}
+	id, err := s.allocIDLocked(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return id, s.commitLocked(ctx, id, record)
+}
+
+// allocIDLocked picks the next free Record ID, skipping the reserved values
+// 0000h and FFFFh, and reports ErrSELFull when the SEL is at capacity.
+func (s *SELStore) allocIDLocked(ctx context.Context) (uint16, error) {
+	ids, err := s.store.RecordIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) >= s.capacity {
+		s.overflow = true
+		return 0, ErrSELFull
+	}
+	used := make(map[uint16]bool, len(ids))
+	for _, id := range ids {
+		used[id] = true
+	}
+	if s.partial != nil {
+		used[s.partial.recordID] = true
+	}
+	for {
+		id := s.nextID
+		s.nextID++
+		if id == 0 || id == 0xffff || used[id] {
+			continue
+		}
+		return id, nil
+	}
+}
+
+// commitLocked validates the record type, fills in the BMC-owned fields, and
+// writes the record under id.
+func (s *SELStore) commitLocked(ctx context.Context, id uint16, record []byte) error {
+	typ := types.SELRecordType(record[2])
+	if typ.Range() == types.SELRecordTypeRangeStandard && typ != 0x02 {
+		return ErrSELRecordType
+	}
+	rec := make([]byte, SELRecordSize)
+	copy(rec, record)
+	types.PackUint16L(id, rec, 0)
+	now := s.timeLocked()
+	if typ.Range() != types.SELRecordTypeRangeNonTimestampedOEM {
+		types.PackUint32L(uint32(now.Unix()), rec, selTimeOffsetRecord)
+	}
+	if err := s.store.Write(ctx, id, rec); err != nil {
+		return err
+	}
+	s.lastAdd = now
+	return nil
+}
+
+// PartialAdd accumulates one fragment of a record (v2.0§31.7). The first
+// fragment passes recordID 0 and offset 0; the returned Record ID must be
+// passed with every following fragment. The record is committed when last is
+// set, and must then total exactly 16 bytes.
+func (s *SELStore) PartialAdd(ctx context.Context, reservationID, recordID uint16, offset uint8, data []byte, last bool) (uint16, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.erasingLocked() {
+		return 0, ErrSELEraseInProgress
+	}
+	if !s.validateLocked(reservationID) {
+		return 0, ErrSELReservationCanceled
+	}
+
+	if recordID == 0 {
+		if offset != 0 {
+			return 0, ErrSELPartialOffset
+		}
+		s.partial = nil
+		id, err := s.allocIDLocked(ctx)
+		if err != nil {
+			return 0, err
+		}
+		s.partial = &selPartialAdd{recordID: id}
+	}
+	p := s.partial
+	if p == nil || (recordID != 0 && p.recordID != recordID) {
+		return 0, hal.ErrNotFound
+	}
+	if int(offset) != len(p.data) {
+		return 0, ErrSELPartialOffset
+	}
+	if len(p.data)+len(data) > SELRecordSize {
+		s.partial = nil
+		return 0, ErrSELRecordMismatch
+	}
+	p.data = append(p.data, data...)
+	if !last {
+		return p.recordID, nil
+	}
+
+	s.partial = nil
+	if len(p.data) != SELRecordSize {
+		return 0, ErrSELRecordMismatch
+	}
+	return p.recordID, s.commitLocked(ctx, p.recordID, p.data)
+}
+
+// Delete removes one record and returns its Record ID (v2.0§31.8). A
+// successful delete cancels the reservation.
+func (s *SELStore) Delete(ctx context.Context, reservationID, recordID uint16) (uint16, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.erasingLocked() {
+		return 0, ErrSELEraseInProgress
+	}
+	if !s.validateLocked(reservationID) {
+		return 0, ErrSELReservationCanceled
+	}
+	ids, idx, err := s.lookupLocked(ctx, recordID)
+	if err != nil {
+		return 0, err
+	}
+	id := ids[idx]
+	if err := s.store.Delete(ctx, id); err != nil {
+		return 0, err
+	}
+	s.reservationID = 0
+	s.lastErase = s.timeLocked()
+	return id, nil
+}
+
+// Clear initiates erasure of all records (v2.0§31.9) and reports whether the
+// erase has completed. Initiating requires a valid reservation and cancels
+// it; while an erase is in progress a second initiate only reports status.
+func (s *SELStore) Clear(ctx context.Context, reservationID uint16) (complete bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.erasingLocked() {
+		return false, nil
+	}
+	if !s.validateLocked(reservationID) {
+		return false, ErrSELReservationCanceled
+	}
+	ids, err := s.store.RecordIDs(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range ids {
+		if err := s.store.Delete(ctx, id); err != nil {
+			return false, err
+		}
+	}
+	now := s.clock.Now()
+	s.reservationID = 0
+	s.partial = nil
+	s.overflow = false
+	s.nextID = 1
+	s.lastErase = s.timeLocked()
+	s.eraseUntil = now.Add(s.eraseDuration)
+	return !s.erasingLocked(), nil
+}
+
+// EraseComplete reports whether the last Clear has finished (the Clear SEL
+// "get erasure status" action, which needs no reservation).
+func (s *SELStore) EraseComplete() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.erasingLocked()
+}
+
+// LastRecord returns the Record ID of the last record in the SEL (FFFFh when
+// the SEL is empty) and the time of the most recent addition, for Get Last
+// Processed Event ID (v2.0§30.6).
+func (s *SELStore) LastRecord(ctx context.Context) (uint16, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.erasingLocked() {
+		return 0, time.Time{}, ErrSELEraseInProgress
+	}
+	ids, err := s.store.RecordIDs(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if len(ids) == 0 {
+		return 0xffff, s.lastAdd, nil
+	}
+	return ids[len(ids)-1], s.lastAdd, nil
+}
+
+// Time returns the current SEL Time (v2.0§31.10), truncated to seconds.
+func (s *SELStore) Time() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.timeLocked()
+}
+
+func (s *SELStore) timeLocked() time.Time {
+	return s.clock.Now().Add(s.timeOffset).Truncate(time.Second)
+}
+
+// SetTime sets the SEL Time (v2.0§31.11). The clock keeps running from t.
+func (s *SELStore) SetTime(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timeOffset = t.Sub(s.clock.Now())
+}
+
+// UTCOffset returns the SEL Time UTC offset in minutes (v2.0§31.11a), or
+// [SELTimeUTCOffsetUnspecified].
+func (s *SELStore) UTCOffset() int16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.utcOffset
+}
+
+// SetUTCOffset sets the SEL Time UTC offset. Valid values are -1440..1440
+// minutes and [SELTimeUTCOffsetUnspecified]; anything else returns false.
+func (s *SELStore) SetUTCOffset(minutes int16) bool {
+	if (minutes < -1440 || minutes > 1440) && minutes != SELTimeUTCOffsetUnspecified {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.utcOffset = minutes
+	return true
+}
@@ -0,0 +1,196 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func testSELRecord(typ uint8) []byte {
+	rec := make([]byte, SELRecordSize)
+	rec[2] = typ
+	for i := 3; i < SELRecordSize; i++ {
+		rec[i] = 0xee
+	}
+	return rec
+}
+
+func TestSELStore_AddAssignsIDAndTimestamp(t *testing.T) {
+	clk := &mockClock{now: time.Unix(1700000000, 0)}
+	s := NewSELStore(mock.New(), clk)
+	ctx := context.Background()
+
+	id1, err := s.Add(ctx, testSELRecord(0x02))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id2, err := s.Add(ctx, testSELRecord(0xe0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id1 == 0 || id2 == id1 {
+		t.Fatalf("record IDs: %#04x, %#04x", id1, id2)
+	}
+
+	rec, next, err := s.GetEntry(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != id2 {
+		t.Fatalf("next: want %#04x got %#04x", id2, next)
+	}
+	if got, _, _ := types.UnpackUint16L(rec, 0); got != id1 {
+		t.Fatalf("record ID bytes: want %#04x got %#04x", id1, got)
+	}
+	if ts, _, _ := types.UnpackUint32L(rec, 3); ts != 1700000000 {
+		t.Fatalf("standard record timestamp: got %d", ts)
+	}
+
+	rec, next, err = s.GetEntry(ctx, 0xffff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != 0xffff {
+		t.Fatalf("last entry next: want ffff got %#04x", next)
+	}
+	if ts, _, _ := types.UnpackUint32L(rec, 3); ts != 0xeeeeeeee {
+		t.Fatalf("non-timestamped OEM record was stamped: %#08x", ts)
+	}
+
+	if _, err := s.Add(ctx, testSELRecord(0x01)); !errors.Is(err, ErrSELRecordType) {
+		t.Fatalf("record type 01h: want ErrSELRecordType, got %v", err)
+	}
+}
+
+func TestSELStore_FullSetsOverflow(t *testing.T) {
+	s := NewSELStore(mock.New(), &mockClock{now: time.Unix(1700000000, 0)})
+	s.SetCapacity(2)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := s.Add(ctx, testSELRecord(0x02)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Add(ctx, testSELRecord(0x02)); !errors.Is(err, ErrSELFull) {
+		t.Fatalf("want ErrSELFull, got %v", err)
+	}
+	info, err := s.Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Overflow || info.FreeBytes != 0 {
+		t.Fatalf("info after overflow: %+v", info)
+	}
+
+	if _, err := s.Clear(ctx, s.Reserve()); err != nil {
+		t.Fatal(err)
+	}
+	info, _ = s.Info(ctx)
+	if info.Overflow || info.Entries != 0 {
+		t.Fatalf("info after clear: %+v", info)
+	}
+}
+
+func TestSELStore_ClearEraseInProgress(t *testing.T) {
+	clk := &mockClock{now: time.Unix(1700000000, 0)}
+	s := NewSELStore(mock.New(), clk)
+	s.SetEraseDuration(2 * time.Second)
+	ctx := context.Background()
+	if _, err := s.Add(ctx, testSELRecord(0x02)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Clear(ctx, 0x1234); !errors.Is(err, ErrSELReservationCanceled) {
+		t.Fatalf("clear without reservation: want ErrSELReservationCanceled, got %v", err)
+	}
+	resID := s.Reserve()
+	complete, err := s.Clear(ctx, resID)
+	if err != nil || complete {
+		t.Fatalf("clear: complete=%v err=%v", complete, err)
+	}
+	if s.Validate(resID) {
+		t.Fatal("clear must cancel the reservation")
+	}
+	if _, err := s.Add(ctx, testSELRecord(0x02)); !errors.Is(err, ErrSELEraseInProgress) {
+		t.Fatalf("add during erase: want ErrSELEraseInProgress, got %v", err)
+	}
+
+	clk.now = clk.now.Add(2 * time.Second)
+	if !s.EraseComplete() {
+		t.Fatal("erase should complete after the erase duration")
+	}
+	id, err := s.Add(ctx, testSELRecord(0x02))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 {
+		t.Fatalf("record IDs restart after clear: got %#04x", id)
+	}
+}
+
+func TestSELStore_PartialAdd(t *testing.T) {
+	s := NewSELStore(mock.New(), &mockClock{now: time.Unix(1700000000, 0)})
+	ctx := context.Background()
+	rec := testSELRecord(0x02)
+
+	if _, err := s.PartialAdd(ctx, 0, 0, 0, rec[:8], false); !errors.Is(err, ErrSELReservationCanceled) {
+		t.Fatalf("want ErrSELReservationCanceled, got %v", err)
+	}
+	resID := s.Reserve()
+	id, err := s.PartialAdd(ctx, resID, 0, 0, rec[:8], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PartialAdd(ctx, resID, id, 4, rec[8:], true); !errors.Is(err, ErrSELPartialOffset) {
+		t.Fatalf("out-of-sequence offset: want ErrSELPartialOffset, got %v", err)
+	}
+	got, err := s.PartialAdd(ctx, resID, id, 8, rec[8:], true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != id {
+		t.Fatalf("record ID: want %#04x got %#04x", id, got)
+	}
+	if _, _, err := s.GetEntry(ctx, id); err != nil {
+		t.Fatalf("committed record not readable: %v", err)
+	}
+
+	id, err = s.PartialAdd(ctx, resID, 0, 0, rec[:8], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PartialAdd(ctx, resID, id, 8, rec[8:12], true); !errors.Is(err, ErrSELRecordMismatch) {
+		t.Fatalf("short record: want ErrSELRecordMismatch, got %v", err)
+	}
+}
+
+func TestSELStore_TimeAndUTCOffset(t *testing.T) {
+	clk := &mockClock{now: time.Unix(1700000000, 0)}
+	s := NewSELStore(mock.New(), clk)
+
+	s.SetTime(time.Unix(1800000000, 0))
+	clk.now = clk.now.Add(10 * time.Second)
+	if got := s.Time().Unix(); got != 1800000010 {
+		t.Fatalf("SEL time: want 1800000010 got %d", got)
+	}
+
+	if s.UTCOffset() != SELTimeUTCOffsetUnspecified {
+		t.Fatalf("default UTC offset: got %d", s.UTCOffset())
+	}
+	if !s.SetUTCOffset(-480) || s.UTCOffset() != -480 {
+		t.Fatal("SetUTCOffset(-480) not applied")
+	}
+	if s.SetUTCOffset(1441) {
+		t.Fatal("SetUTCOffset(1441) must be rejected")
+	}
+}
+
+func TestSELStore_NilHALUnsupported(t *testing.T) {
+	if NewSELStore(nil, nil).Supported() {
+		t.Fatal("SEL without storage must not be supported")
+	}
+}
@@ -0,0 +1,636 @@
+package bmc
+
+// Sensor device (v2.0§35): per-sensor thresholds, hysteresis, event enables
+// and event status. State starts from the Full and Compact sensor records in
+// the SDR repository and is changed at run time by the Sensor/Event NetFn
+// commands; readings come from [hal.SensorHAL].
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// Threshold indexes, in the bit order of the threshold masks of Get/Set
+// Sensor Thresholds and Get Sensor Reading (v2.0§35.8, §35.9, §35.14).
+const (
+	ThresholdLNC = iota
+	ThresholdLCR
+	ThresholdLNR
+	ThresholdUNC
+	ThresholdUCR
+	ThresholdUNR
+
+	NumThresholds
+)
+
+// Sensor command failures, mapped by the Sensor/Event NetFn handlers to
+// completion codes (v2.0§35).
+var (
+	// ErrSensorNotPresent → CodeRequestedDataNotPresent: no such sensor.
+	ErrSensorNotPresent = errors.New("sensor not present")
+	// ErrSensorCommandIllegal → CodeIllegalCommand (CDh): the command does
+	// not apply to the sensor, e.g. thresholds on a discrete sensor or a set
+	// on fixed hysteresis.
+	ErrSensorCommandIllegal = errors.New("command illegal for sensor")
+	// ErrSensorThresholdNotSettable → CodeRequestDataFieldInvalid: a Set
+	// Sensor Thresholds mask selects a threshold that is not settable.
+	ErrSensorThresholdNotSettable = errors.New("sensor threshold not settable")
+)
+
+// SensorEventMask holds one bit per event for the assertion and deassertion
+// directions, laid out as the event mask bytes of v2.0§35.10-35.13: threshold
+// events LNC going low (bit 0) through UNR going high (bit 11), or discrete
+// states 0-14.
+type SensorEventMask struct {
+	Assert   uint16
+	Deassert uint16
+}
+
+// SensorKey identifies a sensor by the LUN it is owned on and its number:
+// the sensor number is unique only per LUN (v2.0§35).
+type SensorKey struct {
+	LUN    uint8
+	Number uint8
+}
+
+// Sensor is the run-time state of one sensor.
+type Sensor struct {
+	// LUN is the Sensor Owner LUN of the sensor record, 0 for sensors only
+	// the HAL lists.
+	LUN              uint8
+	Number           uint8
+	Type             types.SensorType
+	EventReadingType types.EventReadingType
+	// Name is the ID string of the sensor record, or the name the HAL
+	// lists for a sensor without one.
+	Name string
+
+	// RecordID is the ID of the sensor record describing the sensor, 0 for
+	// sensors only the HAL lists. Entity and EntityInstance are the entity
+	// it monitors (v2.0§43.1).
+	RecordID       uint16
+	Entity         types.EntityID
+	EntityInstance types.EntityInstance
+
+	// Full is set for sensors described by a Full Sensor Record, the only
+	// kind that carries reading factors.
+	Full           bool
+	Format         types.SensorAnalogUnitFormat
+	ReadingFactors types.ReadingFactors
+	Linearization  types.LinearizationFunc
+
+	ThresholdAccess  types.SensorThresholdAccess
+	HysteresisAccess types.SensorHysteresisAccess
+	EventControl     types.SensorEventMessageControl
+	AutoRearm        bool
+
+	// Thresholds holds the raw values indexed by ThresholdLNC..ThresholdUNR.
+	// The masks below use the same bit order.
+	Thresholds         [NumThresholds]uint8
+	ReadableThresholds uint8
+	SettableThresholds uint8
+	// StatusThresholds selects the comparisons Get Sensor Reading reports.
+	StatusThresholds uint8
+
+	PositiveHysteresis uint8
+	NegativeHysteresis uint8
+
+	EventMessagesEnabled bool
+	ScanningEnabled      bool
+	// SupportedEvents are the events the sensor record allows; EventEnables
+	// is always a subset of it.
+	SupportedEvents SensorEventMask
+	EventEnables    SensorEventMask
+	EventStatus     SensorEventMask
+
+	// DiscreteState holds the asserted states (bit n = state n) of a
+	// discrete sensor, set through [SensorStore.SetDiscreteState].
+	DiscreteState uint16
+
+	// crossed and conditions are the threshold engine's memory of the last
+	// sample: the thresholds crossed after hysteresis, and the event
+	// offsets whose condition was present.
+	crossed    uint8
+	conditions uint16
+}
+
+// Key returns the key the sensor is stored under.
+func (s *Sensor) Key() SensorKey {
+	return SensorKey{LUN: s.LUN, Number: s.Number}
+}
+
+// IsThreshold reports whether the sensor is threshold based.
+func (s *Sensor) IsThreshold() bool {
+	return s.EventReadingType.IsThreshold()
+}
+
+// HasAnalogReading reports whether the sensor returns a numeric reading that
+// thresholds can be compared against.
+func (s *Sensor) HasAnalogReading() bool {
+	return s.Format != types.SensorAnalogUnitFormat_NotAnalog
+}
+
+// value converts raw into an integer that orders readings in the sensor's
+// data format.
+func (s *Sensor) value(raw uint8) int {
+	switch s.Format {
+	case types.SensorAnalogUnitFormat_1sComplement:
+		if raw&0x80 != 0 {
+			return -int(^raw & 0x7f)
+		}
+		return int(raw)
+	case types.SensorAnalogUnitFormat_2sComplement:
+		return int(int8(raw))
+	default:
+		return int(raw)
+	}
+}
+
+// Reading converts raw into the sensor's units. Sensors without reading
+// factors read as the raw value in their data format.
+func (s *Sensor) Reading(raw uint8) float64 {
+	if s.Full {
+		return types.ConvertReading(raw, s.Format, s.ReadingFactors, s.Linearization)
+	}
+	return float64(s.value(raw))
+}
+
+// ThresholdStatus returns the comparison status of raw against the current
+// thresholds, in threshold mask bit order: a lower threshold bit is set at or
+// below the threshold, an upper one at or above it. Only thresholds in
+// StatusThresholds are compared.
+func (s *Sensor) ThresholdStatus(raw uint8) uint8 {
+	if !s.IsThreshold() || !s.HasAnalogReading() {
+		return 0
+	}
+	v := s.value(raw)
+	var status uint8
+	for i := 0; i < NumThresholds; i++ {
+		if s.StatusThresholds&(1<<i) == 0 {
+			continue
+		}
+		t := s.value(s.Thresholds[i])
+		if (i < ThresholdUNC && v <= t) || (i >= ThresholdUNC && v >= t) {
+			status |= 1 << i
+		}
+	}
+	return status
+}
+
+// SensorReading is one sample of a sensor for Get Sensor Reading
+// (v2.0§35.14).
+type SensorReading struct {
+	Raw         uint8
+	Unavailable bool
+	// ThresholdStatus is [Sensor.ThresholdStatus] for Raw.
+	ThresholdStatus uint8
+	// States is the discrete state of a non-threshold sensor.
+	States uint16
+}
+
+// SensorStore tracks the run-time state of every sensor the BMC owns. It is
+// populated on first use from the SDR repository and the Device SDRs, then
+// from any sensor the HAL lists that has no record.
+type SensorStore struct {
+	mu         sync.Mutex
+	h          hal.HAL
+	repo       func() *SDRRepository
+	deviceSDRs *DeviceSDRStore
+	loaded     bool
+	sensors    map[SensorKey]*Sensor
+}
+
+// sdrSource is a set of SDRs sensors are described by.
+type sdrSource interface {
+	RecordIDs(ctx context.Context) ([]uint16, error)
+	GetRecord(ctx context.Context, recordID uint16) ([]byte, uint16, error)
+}
+
+// NewSensorStore returns a store reading from h. repo supplies the SDR
+// repository (typically [BMC.SDRRepository]); it may be nil or return nil.
+func NewSensorStore(h hal.HAL, repo func() *SDRRepository) *SensorStore {
+	return &SensorStore{h: h, repo: repo}
+}
+
+// SetDeviceSDRs makes the store also serve the sensors described by the
+// Device SDRs d. It takes effect on the next load.
+func (s *SensorStore) SetDeviceSDRs(d *DeviceSDRStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deviceSDRs = d
+	s.loaded = false
+}
+
+func (s *SensorStore) sensorHAL() hal.SensorHAL {
+	if s.h == nil {
+		return nil
+	}
+	return s.h.Sensors()
+}
+
+// readRaw reads sensor k from sh. The HAL numbers the sensors of LUN 0;
+// those of the other LUNs are read through [hal.LUNSensorHAL], and cannot
+// be read from a HAL that does not implement it.
+func readRaw(ctx context.Context, sh hal.SensorHAL, k SensorKey) (uint8, error) {
+	if k.LUN == 0 {
+		return sh.ReadRaw(ctx, k.Number)
+	}
+	if lsh, ok := sh.(hal.LUNSensorHAL); ok {
+		return lsh.ReadRawLUN(ctx, k.LUN, k.Number)
+	}
+	return 0, hal.ErrNotSupported
+}
+
+// Reload discards run-time changes and rebuilds sensor state from the SDR
+// repository, the Device SDRs and the HAL.
+func (s *SensorStore) Reload(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loaded = false
+	return s.loadLocked(ctx)
+}
+
+// loadLocked populates the store on first use. The caller holds s.mu.
+func (s *SensorStore) loadLocked(ctx context.Context) error {
+	if s.loaded {
+		return nil
+	}
+	sensors := make(map[SensorKey]*Sensor)
+
+	var sources []sdrSource
+	if s.repo != nil {
+		if repo := s.repo(); repo != nil {
+			sources = append(sources, repo)
+		}
+	}
+	if s.deviceSDRs != nil {
+		sources = append(sources, s.deviceSDRs)
+	}
+	for _, src := range sources {
+		ids, err := src.RecordIDs(ctx)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			record, _, err := src.GetRecord(ctx, id)
+			if err != nil {
+				return err
+			}
+			sdr, err := types.ParseSDR(record, 0)
+			if err != nil {
+				// Records the parser cannot decode describe no sensor
+				// this store can serve.
+				continue
+			}
+			switch {
+			case sdr.Full != nil:
+				sensor := sensorFromFull(sdr.Full)
+				sensor.RecordID = id
+				sensors[sensor.Key()] = sensor
+			case sdr.Compact != nil:
+				for _, sensor := range sensorsFromCompact(sdr.Compact) {
+					sensor.RecordID = id
+					sensors[sensor.Key()] = sensor
+				}
+			}
+		}
+	}
+
+	if sh := s.sensorHAL(); sh != nil {
+		descs, err := sh.List(ctx)
+		if err != nil && err != hal.ErrNotSupported {
+			return err
+		}
+		for _, d := range descs {
+			k := SensorKey{Number: d.ID}
+			if _, ok := sensors[k]; ok {
+				continue
+			}
+			sensors[k] = &Sensor{
+				Number:               d.ID,
+				Type:                 types.SensorType(d.Type),
+				Name:                 d.Name,
+				EventReadingType:     types.EventReadingTypeThreshold,
+				Format:               types.SensorAnalogUnitFormat_Unsigned,
+				EventControl:         types.SensorEventMessageControl_NoEvents,
+				EventMessagesEnabled: true,
+				ScanningEnabled:      true,
+			}
+		}
+	}
+
+	s.sensors = sensors
+	s.loaded = true
+	return nil
+}
+
+// sensorLocked returns the live state of sensor n on lun. The caller holds
+// s.mu.
+func (s *SensorStore) sensorLocked(ctx context.Context, lun, n uint8) (*Sensor, error) {
+	if err := s.loadLocked(ctx); err != nil {
+		return nil, err
+	}
+	sensor, ok := s.sensors[SensorKey{LUN: lun, Number: n}]
+	if !ok {
+		return nil, ErrSensorNotPresent
+	}
+	return sensor, nil
+}
+
+// Keys returns the keys of every sensor, sorted by LUN, then number.
+func (s *SensorStore) Keys(ctx context.Context) ([]SensorKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(ctx); err != nil {
+		return nil, err
+	}
+	out := make([]SensorKey, 0, len(s.sensors))
+	for k := range s.sensors {
+		out = append(out, k)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].LUN != out[j].LUN {
+			return out[i].LUN < out[j].LUN
+		}
+		return out[i].Number < out[j].Number
+	})
+	return out, nil
+}
+
+// Get returns a copy of the state of sensor n on lun.
+func (s *SensorStore) Get(ctx context.Context, lun, n uint8) (Sensor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sensor, err := s.sensorLocked(ctx, lun, n)
+	if err != nil {
+		return Sensor{}, err
+	}
+	return *sensor, nil
+}
+
+// Read samples sensor n on lun and returns its state with the reading. A
+// sensor whose scanning is disabled, or that the HAL cannot read, reports
+// the reading as unavailable rather than failing.
+func (s *SensorStore) Read(ctx context.Context, lun, n uint8) (Sensor, SensorReading, error) {
+	sensor, err := s.Get(ctx, lun, n)
+	if err != nil {
+		return Sensor{}, SensorReading{}, err
+	}
+
+	reading := SensorReading{States: sensor.DiscreteState}
+	if !sensor.IsThreshold() || !sensor.HasAnalogReading() {
+		reading.Unavailable = !sensor.ScanningEnabled
+		return sensor, reading, nil
+	}
+
+	sh := s.sensorHAL()
+	if sh == nil || !sensor.ScanningEnabled {
+		reading.Unavailable = true
+		return sensor, reading, nil
+	}
+	raw, err := readRaw(ctx, sh, sensor.Key())
+	if err != nil {
+		reading.Unavailable = true
+		return sensor, reading, nil
+	}
+	reading.Raw = raw
+	reading.ThresholdStatus = sensor.ThresholdStatus(raw)
+	return sensor, reading, nil
+}
+
+// SetThresholds sets the thresholds selected by mask (bit n = threshold n)
+// to the corresponding values (v2.0§35.8). Ordering between thresholds is
+// the requester's responsibility and is not checked.
+func (s *SensorStore) SetThresholds(ctx context.Context, lun, n uint8, mask uint8, values [NumThresholds]uint8) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sensor, err := s.sensorLocked(ctx, lun, n)
+	if err != nil {
+		return err
+	}
+	if !sensor.IsThreshold() || sensor.ThresholdAccess != types.SensorThresholdAccess_ReadableSettable {
+		return ErrSensorCommandIllegal
+	}
+	mask &= 1<<NumThresholds - 1
+	if mask&^sensor.SettableThresholds != 0 {
+		return ErrSensorThresholdNotSettable
+	}
+	for i := 0; i < NumThresholds; i++ {
+		if mask&(1<<i) != 0 {
+			sensor.Thresholds[i] = values[i]
+		}
+	}
+	return nil
+}
+
+// SetHysteresis sets the positive- and negative-going hysteresis
+// (v2.0§35.6).
+func (s *SensorStore) SetHysteresis(ctx context.Context, lun, n uint8, positive, negative uint8) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sensor, err := s.sensorLocked(ctx, lun, n)
+	if err != nil {
+		return err
+	}
+	if sensor.HysteresisAccess != types.SensorHysteresisAccess_ReadableSettable {
+		return ErrSensorCommandIllegal
+	}
+	sensor.PositiveHysteresis = positive
+	sensor.NegativeHysteresis = negative
+	return nil
+}
+
+// SetEventEnable sets the sensor-wide event message and scanning enables,
+// then enables the events in enable and disables those in disable
+// (v2.0§35.10). Per-event changes are limited to the events the sensor
+// supports and are ignored unless the sensor has per-event control.
+func (s *SensorStore) SetEventEnable(ctx context.Context, lun, n uint8, events, scanning bool, enable, disable SensorEventMask) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sensor, err := s.sensorLocked(ctx, lun, n)
+	if err != nil {
+		return err
+	}
+	if sensor.EventControl == types.SensorEventMessageControl_NoEvents {
+		return ErrSensorCommandIllegal
+	}
+	sensor.EventMessagesEnabled = events
+	sensor.ScanningEnabled = scanning
+	if sensor.EventControl != types.SensorEventMessageControl_PerThresholdState {
+		return nil
+	}
+	sensor.EventEnables.Assert |= enable.Assert & sensor.SupportedEvents.Assert
+	sensor.EventEnables.Deassert |= enable.Deassert & sensor.SupportedEvents.Deassert
+	sensor.EventEnables.Assert &^= disable.Assert
+	sensor.EventEnables.Deassert &^= disable.Deassert
+	return nil
+}
+
+// Rearm clears event status so that events present on the sensor are
+// generated again (v2.0§35.12): all of it when all is set, otherwise only
+// the events in m. Re-armed assertion conditions still present are raised
+// again by the next [SensorStore.Scan].
+func (s *SensorStore) Rearm(ctx context.Context, lun, n uint8, all bool, m SensorEventMask) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sensor, err := s.sensorLocked(ctx, lun, n)
+	if err != nil {
+		return err
+	}
+	if all {
+		sensor.EventStatus = SensorEventMask{}
+		sensor.conditions = 0
+		return nil
+	}
+	sensor.EventStatus.Assert &^= m.Assert
+	sensor.EventStatus.Deassert &^= m.Deassert
+	sensor.conditions &^= m.Assert
+	return nil
+}
+
+// SetDiscreteState sets the asserted states (bit n = state n) reported by
+// Get Sensor Reading for a discrete sensor.
+func (s *SensorStore) SetDiscreteState(ctx context.Context, lun, n uint8, states uint16) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sensor, err := s.sensorLocked(ctx, lun, n)
+	if err != nil {
+		return err
+	}
+	if sensor.IsThreshold() {
+		return ErrSensorCommandIllegal
+	}
+	sensor.DiscreteState = states & 0x7fff
+	return nil
+}
+
+// sdrThresholdMasks lists the SDR threshold masks in threshold index order.
+func sdrThresholdMasks(m *types.Mask_Thresholds) [NumThresholds]*types.Mask_Threshold {
+	return [NumThresholds]*types.Mask_Threshold{&m.LNC, &m.LCR, &m.LNR, &m.UNC, &m.UCR, &m.UNR}
+}
+
+// sdrDiscreteBits returns the states set in m as a 15-bit mask.
+func sdrDiscreteBits(m types.Mask_DiscreteEvent) uint16 {
+	var out uint16
+	for _, state := range m.TrueEvents() {
+		out |= 1 << state
+	}
+	return out
+}
+
+// applySDRMask fills the threshold and event masks of sensor from a sensor
+// record's mask fields.
+func (s *Sensor) applySDRMask(m types.Mask) {
+	if !s.IsThreshold() {
+		s.SupportedEvents = SensorEventMask{
+			Assert:   sdrDiscreteBits(m.Discrete.Assert),
+			Deassert: sdrDiscreteBits(m.Discrete.Deassert),
+		}
+		return
+	}
+	for i, t := range sdrThresholdMasks(&m.Threshold) {
+		bit := uint8(1) << i
+		if t.Readable {
+			s.ReadableThresholds |= bit
+		}
+		if t.Settable {
+			s.SettableThresholds |= bit
+		}
+		if t.StatusReturned {
+			s.StatusThresholds |= bit
+		}
+		low, high := uint16(1)<<(2*i), uint16(1)<<(2*i+1)
+		if t.Low_Assert {
+			s.SupportedEvents.Assert |= low
+		}
+		if t.High_Assert {
+			s.SupportedEvents.Assert |= high
+		}
+		if t.Low_Deassert {
+			s.SupportedEvents.Deassert |= low
+		}
+		if t.High_Deassert {
+			s.SupportedEvents.Deassert |= high
+		}
+	}
+}
+
+// applySDRInit sets the enables the Initialization Agent would program from
+// the record, falling back to the sensor's power-up defaults.
+func (s *Sensor) applySDRInit(init types.SensorInitialization) {
+	s.EventMessagesEnabled = init.InitEvents || init.EventGenerationEnabled
+	s.ScanningEnabled = init.InitScanning || init.SensorScanningEnabled
+	if s.EventControl != types.SensorEventMessageControl_NoEvents {
+		s.EventEnables = s.SupportedEvents
+	}
+}
+
+func sensorFromFull(r *types.SDRFull) *Sensor {
+	sensor := &Sensor{
+		LUN:                r.GeneratorID.LUN(),
+		Number:             uint8(r.SensorNumber),
+		Type:               r.SensorType,
+		EventReadingType:   r.SensorEventReadingType,
+		Name:               string(r.IDStringBytes),
+		Entity:             r.SensorEntityID,
+		EntityInstance:     r.SensorEntityInstance,
+		Full:               true,
+		Format:             r.SensorUnit.AnalogDataFormat,
+		ReadingFactors:     r.ReadingFactors,
+		Linearization:      r.LinearizationFunc,
+		ThresholdAccess:    r.SensorCapabilities.ThresholdAccess,
+		HysteresisAccess:   r.SensorCapabilities.HysteresisAccess,
+		EventControl:       r.SensorCapabilities.EventMessageControl,
+		AutoRearm:          r.SensorCapabilities.AutoRearm,
+		Thresholds:         [NumThresholds]uint8{r.LNC_Raw, r.LCR_Raw, r.LNR_Raw, r.UNC_Raw, r.UCR_Raw, r.UNR_Raw},
+		PositiveHysteresis: r.PositiveHysteresisRaw,
+		NegativeHysteresis: r.NegativeHysteresisRaw,
+	}
+	sensor.applySDRMask(r.Mask)
+	sensor.applySDRInit(r.SensorInitialization)
+	return sensor
+}
+
+// sensorsFromCompact returns one sensor per sensor number a Compact Sensor
+// Record covers; a non-zero share count describes that many consecutive
+// sensors, whose entity instance also increments when the record says so
+// (v2.0§43.2). Compact records have no threshold values, so their
+// thresholds start at zero.
+func sensorsFromCompact(r *types.SDRCompact) []*Sensor {
+	count := int(r.ShareCount)
+	if count == 0 {
+		count = 1
+	}
+	out := make([]*Sensor, 0, count)
+	for i := 0; i < count && int(r.SensorNumber)+i <= 0xff; i++ {
+		instance := r.SensorEntityInstance
+		if r.EntityInstanceSharing {
+			instance += types.EntityInstance(i)
+		}
+		sensor := &Sensor{
+			LUN:                r.GeneratorID.LUN(),
+			Number:             uint8(r.SensorNumber) + uint8(i),
+			Type:               r.SensorType,
+			EventReadingType:   r.SensorEventReadingType,
+			Name:               string(r.IDStringBytes),
+			Entity:             r.SensorEntityID,
+			EntityInstance:     instance,
+			Format:             r.SensorUnit.AnalogDataFormat,
+			ThresholdAccess:    r.SensorCapabilities.ThresholdAccess,
+			HysteresisAccess:   r.SensorCapabilities.HysteresisAccess,
+			EventControl:       r.SensorCapabilities.EventMessageControl,
+			AutoRearm:          r.SensorCapabilities.AutoRearm,
+			PositiveHysteresis: r.PositiveHysteresisRaw,
+			NegativeHysteresis: r.NegativeHysteresisRaw,
+		}
+		sensor.applySDRMask(r.Mask)
+		sensor.applySDRInit(r.SensorInitialization)
+		out = append(out, sensor)
+	}
+	return out
+}
@@ -0,0 +1,267 @@
+package bmc
+
+// Threshold evaluation (v2.0§36.3): the BMC samples its threshold sensors,
+// compares each reading against the thresholds and hysteresis programmed
+// from the sensor records, and raises assertion and deassertion events as
+// the reading crosses them.
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// DefaultSensorScanInterval is how often [BMC.Run] samples sensors for
+// threshold events.
+const DefaultSensorScanInterval = time.Second
+
+const (
+	// eventMessageRevision is the EvMRev of IPMI v2.0 event messages
+	// (v2.0§29.3).
+	eventMessageRevision = 0x04
+
+	// selRecordTypeSystemEvent is the standard SEL record type (v2.0§32.1).
+	selRecordTypeSystemEvent = 0x02
+
+	// thresholdEventData1 marks event data 2 as the trigger reading and
+	// event data 3 as the trigger threshold (v2.0 Table 29-6).
+	thresholdEventData1 = 0x50
+
+	// numThresholdEvents is the number of threshold event offsets, LNC
+	// going low (00h) through UNR going high (0Bh).
+	numThresholdEvents = 2 * NumThresholds
+)
+
+// PlatformEvent is an event raised by a sensor the BMC owns, in the fields
+// of a Platform Event Message (v2.0§29.3).
+type PlatformEvent struct {
+	GeneratorID      types.GeneratorID
+	SensorType       types.SensorType
+	SensorNumber     uint8
+	EventReadingType types.EventReadingType
+	EventDir         types.EventDir
+	EventData        types.EventData
+}
+
+// Offset returns the event offset. For threshold events it follows the
+// event bit order of [types.SensorEventFlag]: LNC going low (00h) through
+// UNR going high (0Bh).
+func (e PlatformEvent) Offset() uint8 {
+	return e.EventData.EventReadingOffset()
+}
+
+// SELRecord returns the event as a system event record (v2.0§32.1). The
+// Record ID and timestamp are left for [SELStore.Add] to assign.
+func (e PlatformEvent) SELRecord() []byte {
+	sel := &types.SEL{
+		RecordType: selRecordTypeSystemEvent,
+		Standard: &types.SELStandard{
+			GeneratorID:      e.GeneratorID,
+			EvMRev:           eventMessageRevision,
+			SensorType:       e.SensorType,
+			SensorNumber:     types.SensorNumber(e.SensorNumber),
+			EventDir:         e.EventDir,
+			EventReadingType: e.EventReadingType,
+			EventData:        e.EventData,
+		},
+	}
+	return sel.Pack()
+}
+
+// crossings returns the thresholds raw is past, in threshold mask bit order.
+// A lower threshold is crossed at or below its value and an upper one at or
+// above it; once crossed, a threshold stays crossed until the reading
+// returns past it by the hysteresis (positive-going for lower thresholds,
+// negative-going for upper ones).
+func (s *Sensor) crossings(raw uint8) uint8 {
+	v := s.value(raw)
+	var out uint8
+	for i := 0; i < NumThresholds; i++ {
+		bit := uint8(1) << i
+		t := s.value(s.Thresholds[i])
+		crossed := s.crossed&bit != 0
+		if i < ThresholdUNC {
+			if crossed {
+				t += int(s.PositiveHysteresis)
+			}
+			if v <= t {
+				out |= bit
+			}
+			continue
+		}
+		if crossed {
+			t -= int(s.NegativeHysteresis)
+		}
+		if v >= t {
+			out |= bit
+		}
+	}
+	return out
+}
+
+// thresholdConditions returns the threshold event offsets whose condition is
+// present given the crossed thresholds: the going-low offset of a crossed
+// lower threshold, the going-high offset of a crossed upper threshold, and
+// the opposite offset of every threshold not crossed.
+func thresholdConditions(crossed uint8) uint16 {
+	var out uint16
+	for i := 0; i < NumThresholds; i++ {
+		low, high := uint16(1)<<(2*i), uint16(1)<<(2*i+1)
+		if (i < ThresholdUNC) == (crossed&(1<<i) != 0) {
+			out |= low
+		} else {
+			out |= high
+		}
+	}
+	return out
+}
+
+// scannable reports whether the threshold engine samples the sensor.
+func (s *Sensor) scannable() bool {
+	return s.ScanningEnabled && s.IsThreshold() && s.HasAnalogReading()
+}
+
+// eventEnabled reports whether an event with bit in enables may be sent as
+// an event message.
+func (s *Sensor) eventEnabled(enables, bit uint16) bool {
+	return s.EventMessagesEnabled &&
+		s.EventControl != types.SensorEventMessageControl_NoEvents &&
+		enables&bit != 0
+}
+
+// thresholdEvent builds the event for threshold event offset raised by raw.
+func (s *Sensor) thresholdEvent(offset int, raw uint8, dir types.EventDir) PlatformEvent {
+	return PlatformEvent{
+		GeneratorID:      types.GeneratorID(types.BMC_SA) | types.GeneratorID(s.LUN&0x03)<<8,
+		SensorType:       s.Type,
+		SensorNumber:     s.Number,
+		EventReadingType: types.EventReadingTypeThreshold,
+		EventDir:         dir,
+		EventData: types.EventData{
+			EventData1: thresholdEventData1 | uint8(offset),
+			EventData2: raw,
+			EventData3: s.Thresholds[offset/2],
+		},
+	}
+}
+
+// evaluate updates the event status of a threshold sensor for the reading
+// raw and returns the events to send. Only offsets the sensor record
+// supports are tracked. A sensor without auto re-arm latches its event
+// status and raises no further event in a direction already latched until
+// it is re-armed.
+func (s *Sensor) evaluate(raw uint8) []PlatformEvent {
+	s.crossed = s.crossings(raw)
+	supported := s.SupportedEvents.Assert | s.SupportedEvents.Deassert
+	cur := thresholdConditions(s.crossed) & supported
+	rose, fell := cur&^s.conditions, s.conditions&^cur
+	s.conditions = cur
+
+	var out []PlatformEvent
+	for offset := 0; offset < numThresholdEvents; offset++ {
+		bit := uint16(1) << offset
+		switch {
+		case rose&bit != 0:
+			if !s.AutoRearm && s.EventStatus.Assert&bit != 0 {
+				continue
+			}
+			s.EventStatus.Assert |= bit & s.SupportedEvents.Assert
+			if s.AutoRearm {
+				s.EventStatus.Deassert &^= bit
+			}
+			if s.eventEnabled(s.EventEnables.Assert, bit) {
+				out = append(out, s.thresholdEvent(offset, raw, types.EventDirAssertion))
+			}
+		case fell&bit != 0:
+			if !s.AutoRearm && s.EventStatus.Deassert&bit != 0 {
+				continue
+			}
+			s.EventStatus.Deassert |= bit & s.SupportedEvents.Deassert
+			if s.AutoRearm {
+				s.EventStatus.Assert &^= bit
+			}
+			if s.eventEnabled(s.EventEnables.Deassert, bit) {
+				out = append(out, s.thresholdEvent(offset, raw, types.EventDirDeassertion))
+			}
+		}
+	}
+	return out
+}
+
+// Scan samples every threshold sensor whose scanning is enabled and returns
+// the threshold events the readings raise, in LUN and sensor number order.
+// Sensors the HAL cannot read keep their previous state.
+func (s *SensorStore) Scan(ctx context.Context) ([]PlatformEvent, error) {
+	sh := s.sensorHAL()
+	if sh == nil {
+		return nil, nil
+	}
+	keys, err := s.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []PlatformEvent
+	for _, k := range keys {
+		sensor, err := s.Get(ctx, k.LUN, k.Number)
+		if err != nil || !sensor.scannable() {
+			continue
+		}
+		// The HAL is read without s.mu held; thresholds and enables are
+		// taken from the live state when the reading is evaluated.
+		raw, err := readRaw(ctx, sh, k)
+		if err != nil {
+			continue
+		}
+		s.mu.Lock()
+		if live, ok := s.sensors[k]; ok && live.scannable() {
+			events = append(events, live.evaluate(raw)...)
+		}
+		s.mu.Unlock()
+	}
+	return events, nil
+}
+
+// ScanSensors runs one pass of the threshold engine and logs the events it
+// raises.
+func (b *BMC) ScanSensors(ctx context.Context) error {
+	events, err := b.Sensors.Scan(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, ev := range events {
+		if err := b.LogEvent(ctx, ev); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LogEvent delivers an event generated by the BMC to its Event Receiver,
+// which records it in the SEL (v2.0§29.1), keeps it in the Event Message
+// Buffer for system software (v2.0§22.8) and hands it to PEF (v2.0§17).
+// Events are not logged when no SEL backs the BMC or System Event Logging is
+// disabled; PEF still sees them.
+func (b *BMC) LogEvent(ctx context.Context, ev PlatformEvent) error {
+	id, err := b.addSEL(ctx, ev)
+	b.Messages.PutEvent(ev.SELRecord())
+	return errors.Join(err, b.PEF.Process(ctx, ev, id))
+}
+
+// logSEL records ev in the SEL without passing it to PEF.
+func (b *BMC) logSEL(ctx context.Context, ev PlatformEvent) error {
+	_, err := b.addSEL(ctx, ev)
+	return err
+}
+
+// addSEL records ev in the SEL and returns its Record ID, or 0000h when it
+// could not be logged.
+func (b *BMC) addSEL(ctx context.Context, ev PlatformEvent) (uint16, error) {
+	if !b.SEL.Supported() || !b.Messages.GlobalEnables().SystemEventLogging {
+		return 0, nil
+	}
+	return b.SEL.Add(ctx, ev.SELRecord())
+}
@@ -0,0 +1,162 @@
+package bmc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// scanOnce sets sensor 0x10 to raw and runs one threshold engine pass.
+func scanOnce(t *testing.T, s *SensorStore, m *mock.HAL, raw uint8) []PlatformEvent {
+	t.Helper()
+	m.Sensors().(*mock.Sensors).Values = map[uint8]uint8{0x10: raw}
+	events, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestSensorScan_ThresholdCrossingsWithHysteresis(t *testing.T) {
+	s, m := newTestSensorStore(t)
+
+	if events := scanOnce(t, s, m, 70); len(events) != 0 {
+		t.Fatalf("below thresholds: %+v", events)
+	}
+
+	events := scanOnce(t, s, m, 85)
+	if len(events) != 1 {
+		t.Fatalf("crossing UNC: want 1 event, got %+v", events)
+	}
+	ev := events[0]
+	// UNC going high is threshold event offset 07h; event data 2/3 carry
+	// the trigger reading and threshold.
+	if ev.Offset() != 0x07 || ev.EventDir != types.EventDirAssertion || ev.EventReadingType != types.EventReadingTypeThreshold {
+		t.Fatalf("event: %+v", ev)
+	}
+	if ev.EventData != (types.EventData{EventData1: 0x57, EventData2: 85, EventData3: 80}) {
+		t.Fatalf("event data: %+v", ev.EventData)
+	}
+
+	if events = scanOnce(t, s, m, 90); len(events) != 1 || events[0].Offset() != 0x09 {
+		t.Fatalf("crossing UCR: %+v", events)
+	}
+
+	// UNC stays asserted within the 2-count negative-going hysteresis; UCR
+	// has no deassertion event enabled.
+	if events = scanOnce(t, s, m, 79); len(events) != 0 {
+		t.Fatalf("inside hysteresis: %+v", events)
+	}
+	sensor, _ := s.Get(context.Background(), 0, 0x10)
+	if sensor.EventStatus.Assert != 1<<7 {
+		t.Fatalf("assertion status: %#04x", sensor.EventStatus.Assert)
+	}
+
+	events = scanOnce(t, s, m, 77)
+	if len(events) != 1 || events[0].Offset() != 0x07 || events[0].EventDir != types.EventDirDeassertion {
+		t.Fatalf("leaving hysteresis: %+v", events)
+	}
+	if sensor, _ = s.Get(context.Background(), 0, 0x10); sensor.EventStatus != (SensorEventMask{Deassert: 1 << 7}) {
+		t.Fatalf("status after deassertion: %+v", sensor.EventStatus)
+	}
+}
+
+func TestSensorScan_EventEnablesAndScanning(t *testing.T) {
+	s, m := newTestSensorStore(t)
+	ctx := context.Background()
+
+	// With event messages off the status still tracks the reading.
+	if err := s.SetEventEnable(ctx, 0, 0x10, false, true, SensorEventMask{}, SensorEventMask{}); err != nil {
+		t.Fatal(err)
+	}
+	if events := scanOnce(t, s, m, 85); len(events) != 0 {
+		t.Fatalf("event messages disabled: %+v", events)
+	}
+	if sensor, _ := s.Get(ctx, 0, 0x10); sensor.EventStatus.Assert != 1<<7 {
+		t.Fatalf("status with events disabled: %+v", sensor.EventStatus)
+	}
+
+	// A per-event disable suppresses only that event.
+	if err := s.SetEventEnable(ctx, 0, 0x10, true, true, SensorEventMask{}, SensorEventMask{Assert: 1 << 9}); err != nil {
+		t.Fatal(err)
+	}
+	if events := scanOnce(t, s, m, 95); len(events) != 0 {
+		t.Fatalf("UCR assertion disabled: %+v", events)
+	}
+
+	// A sensor with scanning disabled is not sampled at all.
+	if err := s.SetEventEnable(ctx, 0, 0x10, true, false, SensorEventMask{}, SensorEventMask{}); err != nil {
+		t.Fatal(err)
+	}
+	if events := scanOnce(t, s, m, 50); len(events) != 0 {
+		t.Fatalf("scanning disabled: %+v", events)
+	}
+	if sensor, _ := s.Get(ctx, 0, 0x10); sensor.EventStatus.Deassert != 0 {
+		t.Fatalf("status changed while scanning disabled: %+v", sensor.EventStatus)
+	}
+}
+
+func TestSensorScan_ManualRearm(t *testing.T) {
+	m := mock.New()
+	sdr := testThresholdSDR(0x10)
+	sdr.SensorCapabilities.AutoRearm = false
+	if err := m.Storage().SDR().Write(context.Background(), 1, sdr.Pack(1)); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewSDRRepository(m.Storage().SDR(), nil)
+	s := NewSensorStore(m, func() *SDRRepository { return repo })
+
+	if events := scanOnce(t, s, m, 85); len(events) != 1 {
+		t.Fatalf("first crossing: %+v", events)
+	}
+	scanOnce(t, s, m, 70)
+	if events := scanOnce(t, s, m, 85); len(events) != 0 {
+		t.Fatalf("latched status must suppress re-assertion: %+v", events)
+	}
+
+	// Re-arming a condition that is still present raises it again.
+	if err := s.Rearm(context.Background(), 0, 0x10, true, SensorEventMask{}); err != nil {
+		t.Fatal(err)
+	}
+	if events := scanOnce(t, s, m, 85); len(events) != 1 || events[0].Offset() != 0x07 {
+		t.Fatalf("after re-arm: %+v", events)
+	}
+}
+
+func TestBMC_ScanSensorsLogsToSEL(t *testing.T) {
+	m := mock.New()
+	if err := m.Storage().SDR().Write(context.Background(), 1, testThresholdSDR(0x10).Pack(1)); err != nil {
+		t.Fatal(err)
+	}
+	m.Sensors().(*mock.Sensors).Values = map[uint8]uint8{0x10: 92}
+	b := New(DeviceInfo{}, [16]byte{}, m)
+
+	if err := b.ScanSensors(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	info, err := b.SEL.Info(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Entries != 2 {
+		t.Fatalf("want UNC and UCR assertions logged, got %d entries", info.Entries)
+	}
+
+	record, _, err := b.SEL.GetEntry(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel, err := types.ParseSEL(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := sel.Standard
+	if std.GeneratorID != types.GeneratorID(types.BMC_SA) || std.SensorNumber != 0x10 || std.SensorType != types.SensorTypeTemperature {
+		t.Fatalf("record: %+v", std)
+	}
+	if std.EventDir != types.EventDirAssertion || std.EventData.EventReadingOffset() != 0x07 || std.EventData.EventData2 != 92 {
+		t.Fatalf("event: %+v", std)
+	}
+}
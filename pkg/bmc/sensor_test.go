@@ -0,0 +1,159 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// testThresholdSDR returns a temperature sensor record with settable upper
+// thresholds, a read-only LNC, and events on UNC/UCR going high.
+func testThresholdSDR(number uint8) *types.SDRFull {
+	full := &types.SDRFull{
+		SensorNumber:           types.SensorNumber(number),
+		SensorType:             types.SensorTypeTemperature,
+		SensorEventReadingType: types.EventReadingTypeThreshold,
+		SensorInitialization:   types.SensorInitialization{InitScanning: true, InitEvents: true},
+		SensorCapabilities: types.SensorCapabilities{
+			AutoRearm:           true,
+			HysteresisAccess:    types.SensorHysteresisAccess_ReadableSettable,
+			ThresholdAccess:     types.SensorThresholdAccess_ReadableSettable,
+			EventMessageControl: types.SensorEventMessageControl_PerThresholdState,
+		},
+		ReadingFactors:        types.ReadingFactors{M: 1},
+		LNC_Raw:               10,
+		UNC_Raw:               80,
+		UCR_Raw:               90,
+		PositiveHysteresisRaw: 2,
+		NegativeHysteresisRaw: 2,
+	}
+	full.Mask.Threshold.LNC = types.Mask_Threshold{Readable: true, StatusReturned: true}
+	full.Mask.Threshold.UNC = types.Mask_Threshold{Readable: true, Settable: true, StatusReturned: true, High_Assert: true, High_Deassert: true}
+	full.Mask.Threshold.UCR = types.Mask_Threshold{Readable: true, Settable: true, StatusReturned: true, High_Assert: true}
+	return full
+}
+
+func newTestSensorStore(t *testing.T) (*SensorStore, *mock.HAL) {
+	t.Helper()
+	m := mock.New()
+	if err := m.Storage().SDR().Write(context.Background(), 1, testThresholdSDR(0x10).Pack(1)); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewSDRRepository(m.Storage().SDR(), nil)
+	return NewSensorStore(m, func() *SDRRepository { return repo }), m
+}
+
+func TestSensorStore_LoadsFromSDR(t *testing.T) {
+	s, _ := newTestSensorStore(t)
+	sensor, err := s.Get(context.Background(), 0, 0x10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sensor.Thresholds[ThresholdUNC] != 80 || sensor.Thresholds[ThresholdUCR] != 90 {
+		t.Fatalf("thresholds: %v", sensor.Thresholds)
+	}
+	if sensor.ReadableThresholds != 0x19 || sensor.SettableThresholds != 0x18 {
+		t.Fatalf("masks: readable=%#02x settable=%#02x", sensor.ReadableThresholds, sensor.SettableThresholds)
+	}
+	// UNC going high is bit 7, UCR going high bit 9.
+	want := SensorEventMask{Assert: 1<<7 | 1<<9, Deassert: 1 << 7}
+	if sensor.SupportedEvents != want || sensor.EventEnables != want {
+		t.Fatalf("events: supported=%+v enables=%+v", sensor.SupportedEvents, sensor.EventEnables)
+	}
+	if !sensor.EventMessagesEnabled || !sensor.ScanningEnabled {
+		t.Fatal("init bits not applied")
+	}
+
+	if _, err := s.Get(context.Background(), 0, 0x11); !errors.Is(err, ErrSensorNotPresent) {
+		t.Fatalf("unknown sensor: want ErrSensorNotPresent, got %v", err)
+	}
+}
+
+func TestSensorStore_ReadThresholdStatus(t *testing.T) {
+	s, m := newTestSensorStore(t)
+	ctx := context.Background()
+	m.Sensors().(*mock.Sensors).Values = map[uint8]uint8{0x10: 85}
+
+	_, reading, err := s.Read(ctx, 0, 0x10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reading.Unavailable || reading.Raw != 85 {
+		t.Fatalf("reading: %+v", reading)
+	}
+	if reading.ThresholdStatus != 1<<ThresholdUNC {
+		t.Fatalf("status: want UNC only, got %#02x", reading.ThresholdStatus)
+	}
+
+	if err := s.SetThresholds(ctx, 0, 0x10, 1<<ThresholdUNC, [NumThresholds]uint8{ThresholdUNC: 86}); err != nil {
+		t.Fatal(err)
+	}
+	if _, reading, _ = s.Read(ctx, 0, 0x10); reading.ThresholdStatus != 0 {
+		t.Fatalf("status after raising UNC: %#02x", reading.ThresholdStatus)
+	}
+
+	delete(m.Sensors().(*mock.Sensors).Values, 0x10)
+	if _, reading, err = s.Read(ctx, 0, 0x10); err != nil || !reading.Unavailable {
+		t.Fatalf("HAL error: want unavailable reading, got %+v err=%v", reading, err)
+	}
+}
+
+func TestSensorStore_SetThresholdsRejectsReadOnly(t *testing.T) {
+	s, _ := newTestSensorStore(t)
+	err := s.SetThresholds(context.Background(), 0, 0x10, 1<<ThresholdLNC, [NumThresholds]uint8{})
+	if !errors.Is(err, ErrSensorThresholdNotSettable) {
+		t.Fatalf("want ErrSensorThresholdNotSettable, got %v", err)
+	}
+}
+
+func TestSensorStore_EventEnableAndRearm(t *testing.T) {
+	s, _ := newTestSensorStore(t)
+	ctx := context.Background()
+
+	// Disabling UCR going high leaves UNC; enabling an unsupported event is a no-op.
+	err := s.SetEventEnable(ctx, 0, 0x10, true, true, SensorEventMask{Assert: 1 << 11}, SensorEventMask{Assert: 1 << 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sensor, _ := s.Get(ctx, 0, 0x10)
+	if sensor.EventEnables.Assert != 1<<7 {
+		t.Fatalf("assert enables: %#04x", sensor.EventEnables.Assert)
+	}
+
+	s.sensors[SensorKey{Number: 0x10}].EventStatus = SensorEventMask{Assert: 1<<7 | 1<<9}
+	if err := s.Rearm(ctx, 0, 0x10, false, SensorEventMask{Assert: 1 << 9}); err != nil {
+		t.Fatal(err)
+	}
+	if sensor, _ = s.Get(ctx, 0, 0x10); sensor.EventStatus.Assert != 1<<7 {
+		t.Fatalf("status after selective re-arm: %#04x", sensor.EventStatus.Assert)
+	}
+	if err := s.Rearm(ctx, 0, 0x10, true, SensorEventMask{}); err != nil {
+		t.Fatal(err)
+	}
+	if sensor, _ = s.Get(ctx, 0, 0x10); sensor.EventStatus != (SensorEventMask{}) {
+		t.Fatalf("status after re-arm all: %+v", sensor.EventStatus)
+	}
+}
+
+func TestSensorStore_HALOnlySensors(t *testing.T) {
+	m := mock.New()
+	sensors := m.Sensors().(*mock.Sensors)
+	sensors.Descs = []hal.SensorDescriptor{{ID: 0x30, Type: uint8(types.SensorTypeVoltage), Name: "P12V"}}
+	sensors.Values = map[uint8]uint8{0x30: 0xc0}
+	s := NewSensorStore(m, nil)
+
+	sensor, reading, err := s.Read(context.Background(), 0, 0x30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sensor.Type != types.SensorTypeVoltage || reading.Raw != 0xc0 || reading.Unavailable {
+		t.Fatalf("sensor=%+v reading=%+v", sensor, reading)
+	}
+	if err := s.SetHysteresis(context.Background(), 0, 0x30, 1, 1); !errors.Is(err, ErrSensorCommandIllegal) {
+		t.Fatalf("hysteresis without SDR: want ErrSensorCommandIllegal, got %v", err)
+	}
+}
@@ -139,6 +139,9 @@ type SessionStore struct {
 	// goroutine, which may be mid reconnect dial), and holding the lock
 	// across that would freeze every session lookup and allocation.
 	onRemove func(bmcID uint32)
+	// onEvict fires, after onRemove and likewise without the store lock,
+	// for each session the store evicts on its own.
+	onEvict func(Eviction)
 }
 
 // NewSessionStore creates a SessionStore limited to [MaxSessions] concurrent sessions
@@ -185,19 +188,20 @@ func NewSessionStoreWithOptions(clk clock.Clock, opts ...SessionStoreOption) *Se
 func (s *SessionStore) Allocate(consoleID uint32, authAlg types.AuthAlg, integrityAlg types.IntegrityAlg, cryptAlg types.CryptAlg, maxPriv PrivilegeLevel, channel uint8) (*Session, error) {
 	s.mu.Lock()
 
-	// Collect the evicted IDs so their removal hooks (payload deactivation,
-	// which can block) run after the lock is released — even on the error
-	// paths below, the evicted sessions are gone and must be cleaned up.
-	removed := s.evictExpiredLocked()
+	// Collect the evictions so their hooks (payload deactivation, which can
+	// block) run after the lock is released — even on the error paths below,
+	// the evicted sessions are gone and must be cleaned up.
+	evicted := s.evictExpiredLocked()
 	defer func() {
+		onEvict := s.onEvict
 		s.mu.Unlock()
-		s.fireRemoveAll(removed)
+		s.fireEvicted(onEvict, evicted)
 	}()
 
 	if len(s.sessions) >= s.max {
 		// Evict oldest pending session if any exist.
-		if id, ok := s.evictOldestPendingLocked(); ok {
-			removed = append(removed, id)
+		if ev, ok := s.evictOldestPendingLocked(); ok {
+			evicted = append(evicted, ev)
 		} else {
 			return nil, ErrSessionFull
 		}
@@ -272,6 +276,19 @@ func (s *SessionStore) Get(bmcID uint32) (*Session, error) {
 	return sess, nil
 }
 
+// GetByHandle returns the active session with the one-byte session handle,
+// or [ErrNoSession]. Messages bridged to a LAN session name it by handle.
+func (s *SessionStore) GetByHandle(handle uint8) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sess := range s.sessions {
+		if sess.Handle == handle && sess.State == SessionStateActive {
+			return sess, nil
+		}
+	}
+	return nil, fmt.Errorf("session handle 0x%02x: %w", handle, ErrNoSession)
+}
+
 // Touch refreshes the session's inactivity clock. The server calls it for every
 // packet that passed integrity and sequence validation. Handshake packets never
 // touch: RAKP messages carry no authenticator, so the inactivity budget stamped
@@ -316,6 +333,26 @@ func (s *SessionStore) Close(bmcID uint32) error {
 	return nil
 }
 
+// Evict removes a session like [SessionStore.Close], but reports it to the
+// eviction hook for reason. The RAKP handlers call it with [EvictRefused] for
+// a handshake they refuse, holding the session's ProcMu, so the eviction also
+// names the user the handshake asked for.
+func (s *SessionStore) Evict(bmcID uint32, reason EvictReason) error {
+	s.mu.Lock()
+	sess, ok := s.sessions[bmcID]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("session 0x%08x: %w", bmcID, ErrNoSession)
+	}
+	delete(s.sessions, bmcID)
+	ev := evictionOf(sess, reason)
+	ev.User = userName(sess.User)
+	onEvict := s.onEvict
+	s.mu.Unlock()
+	s.fireEvicted(onEvict, []Eviction{ev})
+	return nil
+}
+
 // SetOnRemove registers the hook fired when a session leaves the store.
 func (s *SessionStore) SetOnRemove(fn func(bmcID uint32)) {
 	s.mu.Lock()
@@ -332,11 +369,24 @@ func (s *SessionStore) fireRemove(bmcID uint32) {
 	}
 }
 
-// fireRemoveAll invokes the removal hook for every ID collected by a locked
-// eviction pass.
-func (s *SessionStore) fireRemoveAll(ids []uint32) {
-	for _, id := range ids {
-		s.fireRemove(id)
+// SetOnEvict registers the hook fired for each session the store evicts:
+// expired ones, whether found by [SessionStore.EvictExpired] or by
+// [SessionStore.Allocate], the oldest pending one Allocate drops when the
+// store is full, and those removed with [SessionStore.Evict].
+func (s *SessionStore) SetOnEvict(fn func(Eviction)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onEvict = fn
+}
+
+// fireEvicted invokes the removal hook, then onEvict, for every session a
+// locked eviction pass collected. onEvict is read by the caller under s.mu.
+func (s *SessionStore) fireEvicted(onEvict func(Eviction), evicted []Eviction) {
+	for _, ev := range evicted {
+		s.fireRemove(ev.SessionID)
+		if onEvict != nil {
+			onEvict(ev)
+		}
 	}
 }
 
@@ -344,29 +394,49 @@ func (s *SessionStore) fireRemoveAll(ids []uint32) {
 // Called periodically by the server.
 func (s *SessionStore) EvictExpired() int {
 	s.mu.Lock()
-	removed := s.evictExpiredLocked()
+	evicted := s.evictExpiredLocked()
+	onEvict := s.onEvict
 	s.mu.Unlock()
-	s.fireRemoveAll(removed)
-	return len(removed)
+	s.fireEvicted(onEvict, evicted)
+	return len(evicted)
 }
 
-// evictExpiredLocked removes sessions inactive beyond the timeout and returns
-// their IDs. s.mu must be held.
-func (s *SessionStore) evictExpiredLocked() []uint32 {
+// evictExpiredLocked removes sessions inactive beyond the timeout and
+// describes them. s.mu must be held.
+func (s *SessionStore) evictExpiredLocked() []Eviction {
 	now := s.clock.Now()
-	var removed []uint32
+	var evicted []Eviction
 	for id, sess := range s.sessions {
 		if now.Sub(sess.LastActivity) > s.timeout+DefaultInactivityTimeoutTolerance {
 			delete(s.sessions, id)
-			removed = append(removed, id)
+			evicted = append(evicted, evictionOf(sess, EvictTimeout))
 		}
 	}
-	return removed
+	return evicted
+}
+
+// evictionOf describes sess, evicted for reason. The store lock must be
+// held: the handshake writes User under ProcMu alone, so it is only read
+// once Activate has published the session as active under that lock.
+func evictionOf(sess *Session, reason EvictReason) Eviction {
+	ev := Eviction{
+		Reason:       reason,
+		SessionID:    sess.BMCID,
+		Handle:       sess.Handle,
+		Channel:      sess.Channel,
+		Active:       sess.State == SessionStateActive,
+		MaxPrivilege: sess.MaxPrivilege,
+		Addr:         sess.GetAddr(),
+	}
+	if ev.Active {
+		ev.User = userName(sess.User)
+	}
+	return ev
 }
 
-// evictOldestPendingLocked removes the oldest pending session and returns its
-// ID. ok=false when no pending sessions exist. s.mu must be held.
-func (s *SessionStore) evictOldestPendingLocked() (uint32, bool) {
+// evictOldestPendingLocked removes the oldest pending session and describes
+// it. ok=false when no pending sessions exist. s.mu must be held.
+func (s *SessionStore) evictOldestPendingLocked() (Eviction, bool) {
 	var oldest *Session
 	for _, sess := range s.sessions {
 		if sess.State == SessionStatePending {
@@ -376,10 +446,10 @@ func (s *SessionStore) evictOldestPendingLocked() (uint32, bool) {
 		}
 	}
 	if oldest == nil {
-		return 0, false
+		return Eviction{}, false
 	}
 	delete(s.sessions, oldest.BMCID)
-	return oldest.BMCID, true
+	return evictionOf(oldest, EvictCapacity), true
 }
 
 // Count returns the number of sessions currently in the store.
@@ -389,6 +459,37 @@ func (s *SessionStore) Count() int {
 	return len(s.sessions)
 }
 
+// ActiveSession identifies an active session for reporting: the channel it
+// runs on and the name of the user it authenticated as.
+type ActiveSession struct {
+	Channel uint8
+	User    string
+}
+
+// ActiveSessions lists the store's active sessions. Pending handshakes are
+// left out: their user is not authenticated yet. A session's User is written
+// before [SessionStore.Activate] publishes the active state under the store
+// lock, so reading it under the same lock is safe.
+func (s *SessionStore) ActiveSessions() []ActiveSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []ActiveSession
+	for _, sess := range s.sessions {
+		if sess.State == SessionStateActive {
+			out = append(out, ActiveSession{Channel: sess.Channel, User: userName(sess.User)})
+		}
+	}
+	return out
+}
+
+// userName is the name of u, empty for the anonymous (nil) user.
+func userName(u *User) string {
+	if u == nil {
+		return ""
+	}
+	return u.Name
+}
+
 // Cap returns the maximum number of concurrent sessions the store can hold,
 // i.e. the number of slots in the session table.
 func (s *SessionStore) Cap() int {
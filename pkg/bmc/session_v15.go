@@ -110,6 +110,10 @@ type V15SessionStore struct {
 	clock    clock.Clock
 	// nextHandle seeds session handle assignment; see allocHandleLocked.
 	nextHandle uint8
+
+	// onEvict fires, without the store lock, for each session the store
+	// evicts on its own.
+	onEvict func(Eviction)
 }
 
 // NewV15SessionStore creates a V15SessionStore with the default limits.
@@ -127,14 +131,22 @@ func NewV15SessionStore(clk clock.Clock) *V15SessionStore {
 // unguarded field write happens after it becomes reachable to other goroutines.
 func (s *V15SessionStore) CreatePending(authType V15AuthType, user *User, challenge [16]byte, channel uint8) (*V15Session, error) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 
-	s.evictExpiredLocked()
+	// As in [SessionStore.Allocate], the eviction hook runs once the lock is
+	// released, on the error paths too.
+	evicted := s.evictExpiredLocked()
+	defer func() {
+		onEvict := s.onEvict
+		s.mu.Unlock()
+		fireV15Evicted(onEvict, evicted)
+	}()
 
 	if len(s.sessions) >= s.max {
-		if !s.evictOldestPendingLocked() {
+		ev, ok := s.evictOldestPendingLocked()
+		if !ok {
 			return nil, ErrSessionFull
 		}
+		evicted = append(evicted, ev)
 	}
 
 	tempID, err := randomUint32()
@@ -241,6 +253,20 @@ func (s *V15SessionStore) CountActiveSessions() int {
 	return n
 }
 
+// ActiveSessions lists the store's active v1.5 sessions, mirroring
+// [SessionStore.ActiveSessions].
+func (s *V15SessionStore) ActiveSessions() []ActiveSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []ActiveSession
+	for _, sess := range s.sessions {
+		if sess.State == V15SessionStateActive {
+			out = append(out, ActiveSession{Channel: sess.Channel, User: userName(sess.User)})
+		}
+	}
+	return out
+}
+
 // CountActiveSessionsForUser returns active sessions owned by userID.
 func (s *V15SessionStore) CountActiveSessionsForUser(userID uint8) int {
 	s.mu.Lock()
@@ -343,33 +369,71 @@ func (s *V15SessionStore) Close(id uint32) error {
 	return nil
 }
 
+// SetOnEvict registers the hook fired for each session the store evicts:
+// expired ones, whether found by [V15SessionStore.EvictExpired] or by
+// [V15SessionStore.CreatePending], and the oldest pending one CreatePending
+// drops when the store is full.
+func (s *V15SessionStore) SetOnEvict(fn func(Eviction)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onEvict = fn
+}
+
+// fireV15Evicted invokes onEvict, read by the caller under the store lock,
+// for every session a locked eviction pass collected.
+func fireV15Evicted(onEvict func(Eviction), evicted []Eviction) {
+	if onEvict == nil {
+		return
+	}
+	for _, ev := range evicted {
+		onEvict(ev)
+	}
+}
+
 // EvictExpired removes inactive v1.5 sessions past the timeout. Eviction reads
 // LastActivity and deletes under the store lock only; it never takes a
 // session's ProcMu, so a handler holding one can trigger eviction safely.
 func (s *V15SessionStore) EvictExpired() int {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.evictExpiredLocked()
+	evicted := s.evictExpiredLocked()
+	onEvict := s.onEvict
+	s.mu.Unlock()
+	fireV15Evicted(onEvict, evicted)
+	return len(evicted)
 }
 
-// evictExpiredLocked removes sessions inactive beyond the timeout. s.mu must be
-// held.
-func (s *V15SessionStore) evictExpiredLocked() int {
+// evictExpiredLocked removes sessions inactive beyond the timeout and
+// describes them. s.mu must be held.
+func (s *V15SessionStore) evictExpiredLocked() []Eviction {
 	now := s.clock.Now()
 	limit := s.timeout + DefaultInactivityTimeoutTolerance
-	n := 0
+	var evicted []Eviction
 	for id, sess := range s.sessions {
 		if now.Sub(sess.LastActivity) > limit {
 			delete(s.sessions, id)
-			n++
+			evicted = append(evicted, v15EvictionOf(id, sess, EvictTimeout))
 		}
 	}
-	return n
+	return evicted
+}
+
+// v15EvictionOf describes sess, stored under id and evicted for reason. The
+// store lock must be held.
+func v15EvictionOf(id uint32, sess *V15Session, reason EvictReason) Eviction {
+	return Eviction{
+		Reason:       reason,
+		SessionID:    id,
+		Handle:       sess.Handle,
+		Channel:      sess.Channel,
+		Active:       sess.State == V15SessionStateActive,
+		User:         userName(sess.User),
+		MaxPrivilege: sess.MaxPrivilege,
+	}
 }
 
-// evictOldestPendingLocked removes the oldest pending session, returning true
-// if one was removed. s.mu must be held.
-func (s *V15SessionStore) evictOldestPendingLocked() bool {
+// evictOldestPendingLocked removes the oldest pending session and describes
+// it. ok=false when no pending sessions exist. s.mu must be held.
+func (s *V15SessionStore) evictOldestPendingLocked() (Eviction, bool) {
 	var oldest *V15Session
 	var oldestID uint32
 	for id, sess := range s.sessions {
@@ -381,10 +445,10 @@ func (s *V15SessionStore) evictOldestPendingLocked() bool {
 		}
 	}
 	if oldest == nil {
-		return false
+		return Eviction{}, false
 	}
 	delete(s.sessions, oldestID)
-	return true
+	return v15EvictionOf(oldestID, oldest, EvictCapacity), true
 }
 
 // v15SeqDiff returns seq-high as a signed delta with uint32 wrap-around.
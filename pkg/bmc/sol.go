@@ -9,6 +9,7 @@ import (
 	"errors"
 	"math"
 	"math/rand/v2"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -116,8 +117,14 @@ type SOLConfig struct {
 	// Server-internal, written once at construction and never settable via
 	// IPMI (#7/#8 are read-only per SetParam), so a plain field suffices.
 	PayloadPort uint16
+
+	// onChange, when set, is told of each non-volatile parameter write.
+	onChange func()
 }
 
+// solNonVolatileParams are the non-volatile parameters of Table 26-5.
+var solNonVolatileParams = []uint8{1, 2, 3, 4, 5}
+
 // NewSOLConfig returns a SOLConfig with manufacturer defaults.
 func NewSOLConfig() *SOLConfig {
 	return &SOLConfig{
@@ -184,6 +191,39 @@ func (c *SOLConfig) GetParam(selector uint8) ([]byte, bool) {
 // SetParam validates and applies one parameter write (Table 26-3/26-5),
 // returning the command-specific completion code on failure.
 func (c *SOLConfig) SetParam(selector uint8, data []byte) types.CompletionCode {
+	cc := c.setParam(selector, data)
+	if cc == types.CodeOK && slices.Contains(solNonVolatileParams, selector) {
+		c.mu.Lock()
+		fn := c.onChange
+		c.mu.Unlock()
+		if fn != nil {
+			fn()
+		}
+	}
+	return cc
+}
+
+// SetOnChange installs fn to be told, after the configuration lock is
+// released, of each write of a non-volatile parameter.
+func (c *SOLConfig) SetOnChange(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = fn
+}
+
+// nonVolatileParams returns the data of the non-volatile parameters by
+// selector.
+func (c *SOLConfig) nonVolatileParams() map[uint8][]byte {
+	out := make(map[uint8][]byte, len(solNonVolatileParams))
+	for _, sel := range solNonVolatileParams {
+		if data, ok := c.GetParam(sel); ok {
+			out[sel] = data
+		}
+	}
+	return out
+}
+
+func (c *SOLConfig) setParam(selector uint8, data []byte) types.CompletionCode {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -0,0 +1,581 @@
+package bmc
+
+// Non-volatile state: the settings the spec keeps across a BMC restart are
+// kept in the [hal.StateStore] of a storage HAL that implements
+// [hal.StateStorageHAL]. [New] restores them, and each change committed
+// afterwards is saved at once, one key per store, so a crash loses nothing
+// that was acknowledged. The FRU, SDR and SEL data and the system info
+// parameters are the storage HAL's own. The volatile settings, sessions and
+// the SEL reservation are not saved, nor are the PEF last processed event
+// IDs and the DCMI power statistics.
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// The keys of the non-volatile state.
+const (
+	stateKeyUsers    = "users"
+	stateKeyChannels = "channels"
+	stateKeySOL      = "sol"
+	stateKeyLAN      = "lan"
+	stateKeyChassis  = "chassis"
+	stateKeyCommands = "command_enables"
+	stateKeyPEF      = "pef"
+	stateKeyAlerts   = "lan_alerts"
+	stateKeyDCMI     = "dcmi"
+)
+
+var stateKeys = []string{
+	stateKeyUsers, stateKeyChannels, stateKeySOL, stateKeyLAN, stateKeyChassis,
+	stateKeyCommands, stateKeyPEF, stateKeyAlerts, stateKeyDCMI,
+}
+
+// StateErrorHandler is told of a key of the non-volatile state that could not
+// be restored or saved.
+type StateErrorHandler func(key string, err error)
+
+// WithStateErrorHandler has fn told of the failures to restore or save the
+// non-volatile state, which are otherwise dropped: a change is committed
+// whether or not it could be saved.
+func WithStateErrorHandler(fn StateErrorHandler) Option {
+	return func(b *BMC) { b.stateErr = fn }
+}
+
+// lanState is the non-volatile LAN configuration the BMC keeps.
+type lanState struct {
+	// CipherSuites is nil when the default suites are in use.
+	CipherSuites          []types.CipherSuiteID `json:",omitempty"`
+	CipherSuitePrivileges [MaxCipherSuiteEntries]PrivilegeLevel
+	// Network is absent when the BMC has no network interface.
+	Network *hal.IPConfig `json:",omitempty"`
+}
+
+// chassisState is the non-volatile chassis state. POH is the time the
+// system was powered on, as the POH counter counts it. The boot options are
+// in their Get System Boot Options format, and absent when the chassis keeps
+// none.
+type chassisState struct {
+	RestorePolicy       uint8
+	PowerOn             bool
+	POH                 time.Duration
+	FrontPanelDisables  uint8
+	PowerCycleInterval  uint8
+	Capabilities        ChassisCapabilities
+	BootFlags           []byte `json:",omitempty"`
+	BootInfoAcknowledge []byte `json:",omitempty"`
+}
+
+// commandEnableState is the firmware firewall state: the disabled commands
+// and the completion code they answer.
+type commandEnableState struct {
+	Disabled     []commandEnableEntry
+	DisabledCode types.CompletionCode
+}
+
+// commandEnableEntry is one disabled command.
+type commandEnableEntry struct {
+	Channel, LUN, NetFn, Command uint8
+}
+
+// pefState is the non-volatile PEF configuration. The event filters and
+// alert policies are in their table entry format; alert string 0 and its
+// key are volatile and left out.
+type pefState struct {
+	Control           uint8
+	ActionControl     uint8
+	StartupDelay      uint8
+	AlertStartupDelay uint8
+	EventFilters      [][]byte
+	AlertPolicies     [][]byte
+	UseGUID           bool
+	GUID              [16]byte
+	AlertStringKeys   [][2]uint8
+	AlertStrings      [][]byte
+}
+
+// lanAlertState is the non-volatile LAN alert configuration. Destination 0
+// is volatile and left out.
+type lanAlertState struct {
+	Community    [18]byte
+	Destinations []LANAlertDestination
+}
+
+// dcmiState is the non-volatile DCMI state. PowerLimit is absent when no
+// limit was set.
+type dcmiState struct {
+	PowerLimit       *hal.PowerLimit `json:",omitempty"`
+	PowerLimitActive bool
+	AssetTag         []byte `json:",omitempty"`
+	MCID             []byte `json:",omitempty"`
+	ThermalLimits    []dcmiThermalLimit
+	Discovery        uint8
+	DHCPTimings      [3]uint8
+}
+
+// dcmiThermalLimit is the limit of one inlet temperature sensor.
+type dcmiThermalLimit struct {
+	Instance types.EntityInstance
+	Limit    ThermalLimit
+}
+
+// initState restores the non-volatile state, and from then on saves each
+// change to it. It does nothing when the HAL has no state store.
+func (b *BMC) initState() {
+	if b.hal == nil {
+		return
+	}
+	storage, ok := b.hal.Storage().(hal.StateStorageHAL)
+	if !ok {
+		return
+	}
+	store := storage.State()
+	if store == nil {
+		return
+	}
+
+	ctx := context.Background()
+	for _, key := range stateKeys {
+		data, err := store.Read(ctx, key)
+		if errors.Is(err, hal.ErrNotFound) {
+			continue
+		}
+		if err == nil {
+			err = b.restoreState(ctx, key, data)
+		}
+		if err != nil {
+			b.reportStateError(key, fmt.Errorf("restore: %w", err))
+		}
+	}
+
+	b.stateMu.Lock()
+	b.state = store
+	b.stateMu.Unlock()
+	b.Users.SetOnChange(func() { b.saveState(stateKeyUsers) })
+	b.Channels.SetOnChange(func() { b.saveState(stateKeyChannels) })
+	b.SOL.Config().SetOnChange(func() { b.saveState(stateKeySOL) })
+	b.LANConfig.SetOnChange(func() { b.saveState(stateKeyLAN) })
+	b.Chassis.SetOnChange(func() { b.saveState(stateKeyChassis) })
+	b.CommandEnables.SetOnChange(func() { b.saveState(stateKeyCommands) })
+	b.PEF.SetOnChange(func() { b.saveState(stateKeyPEF) })
+	b.Alerts.SetOnChange(func() { b.saveState(stateKeyAlerts) })
+	b.DCMI.SetOnChange(func() { b.saveState(stateKeyDCMI) })
+}
+
+// saveState writes the state under key to the state store, if any. Saves
+// are serialized so an older snapshot never overwrites a newer one.
+func (b *BMC) saveState(key string) {
+	b.stateMu.Lock()
+	defer b.stateMu.Unlock()
+	if b.state == nil {
+		return
+	}
+	ctx := context.Background()
+	data, err := b.marshalState(ctx, key)
+	if err == nil {
+		err = b.state.Write(ctx, key, data)
+	}
+	if err != nil {
+		b.reportStateError(key, fmt.Errorf("save: %w", err))
+	}
+}
+
+func (b *BMC) reportStateError(key string, err error) {
+	if b.stateErr != nil {
+		b.stateErr(key, err)
+	}
+}
+
+// marshalState returns the state under key.
+func (b *BMC) marshalState(ctx context.Context, key string) ([]byte, error) {
+	var v any
+	switch key {
+	case stateKeyUsers:
+		v = b.Users.snapshot()
+	case stateKeyChannels:
+		v = b.Channels.snapshotAccess()
+	case stateKeySOL:
+		v = b.SOL.Config().nonVolatileParams()
+	case stateKeyLAN:
+		b.cfgMu.RLock()
+		suites := append([]types.CipherSuiteID(nil), b.cipherSuites...)
+		b.cfgMu.RUnlock()
+		network, err := b.LANConfig.committedNetwork(ctx)
+		if err != nil {
+			return nil, err
+		}
+		v = lanState{CipherSuites: suites, CipherSuitePrivileges: b.LANConfig.CipherSuitePrivileges(), Network: network}
+	case stateKeyChassis:
+		st, err := b.Chassis.snapshot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		v = st
+	case stateKeyCommands:
+		v = b.CommandEnables.snapshot()
+	case stateKeyPEF:
+		v = b.PEF.snapshot()
+	case stateKeyAlerts:
+		v = b.Alerts.snapshot()
+	case stateKeyDCMI:
+		v = b.DCMI.snapshot()
+	default:
+		return nil, fmt.Errorf("unknown state key %q", key)
+	}
+	return json.Marshal(v)
+}
+
+// restoreState restores the state under key from data.
+func (b *BMC) restoreState(ctx context.Context, key string, data []byte) error {
+	switch key {
+	case stateKeyUsers:
+		var users []*User
+		if err := json.Unmarshal(data, &users); err != nil {
+			return err
+		}
+		b.Users.restore(users)
+	case stateKeyChannels:
+		var access map[uint8]ChannelAccess
+		if err := json.Unmarshal(data, &access); err != nil {
+			return err
+		}
+		b.Channels.restoreAccess(access)
+	case stateKeySOL:
+		var params map[uint8][]byte
+		if err := json.Unmarshal(data, &params); err != nil {
+			return err
+		}
+		return b.SOL.Config().restore(params)
+	case stateKeyLAN:
+		var st lanState
+		if err := json.Unmarshal(data, &st); err != nil {
+			return err
+		}
+		for _, id := range st.CipherSuites {
+			if !SupportedCipherSuite(id) {
+				return fmt.Errorf("cipher suite %d is not implemented", id)
+			}
+		}
+		b.setCipherSuites(st.CipherSuites)
+		b.LANConfig.restoreCipherSuitePrivileges(st.CipherSuitePrivileges)
+		if st.Network != nil {
+			if err := b.LANConfig.restoreNetwork(ctx, st.Network); err != nil {
+				return fmt.Errorf("network: %w", err)
+			}
+		}
+	case stateKeyChassis:
+		var st chassisState
+		if err := json.Unmarshal(data, &st); err != nil {
+			return err
+		}
+		return b.Chassis.restore(ctx, st)
+	case stateKeyCommands:
+		var st commandEnableState
+		if err := json.Unmarshal(data, &st); err != nil {
+			return err
+		}
+		return b.CommandEnables.restore(st)
+	case stateKeyPEF:
+		var st pefState
+		if err := json.Unmarshal(data, &st); err != nil {
+			return err
+		}
+		return b.PEF.restore(st)
+	case stateKeyAlerts:
+		var st lanAlertState
+		if err := json.Unmarshal(data, &st); err != nil {
+			return err
+		}
+		b.Alerts.restore(st)
+	case stateKeyDCMI:
+		var st dcmiState
+		if err := json.Unmarshal(data, &st); err != nil {
+			return err
+		}
+		return b.DCMI.restore(ctx, st)
+	}
+	return nil
+}
+
+// restore applies the non-volatile parameters params, by selector, as saved.
+// The volatile bit rate follows the non-volatile one, as after a restart.
+func (c *SOLConfig) restore(params map[uint8][]byte) error {
+	var errs []error
+	for _, sel := range solNonVolatileParams {
+		data, ok := params[sel]
+		if !ok {
+			continue
+		}
+		if cc := c.setParam(sel, data); cc != types.CodeOK {
+			errs = append(errs, fmt.Errorf("SOL parameter #%d: %w", sel, cc))
+		}
+	}
+	c.ResetVolatile()
+	return errors.Join(errs...)
+}
+
+// snapshot returns the non-volatile chassis state.
+func (c *ChassisStore) snapshot(ctx context.Context) (chassisState, error) {
+	c.mu.Lock()
+	st := chassisState{
+		RestorePolicy:      c.restorePolicy,
+		PowerOn:            c.powerOn,
+		POH:                c.poh,
+		FrontPanelDisables: c.frontPanel,
+		PowerCycleInterval: c.cycleInterval,
+		Capabilities:       c.caps,
+	}
+	c.mu.Unlock()
+
+	ch := c.chassisHAL()
+	if ch == nil {
+		return st, nil
+	}
+	flags, err := ch.GetBootFlags(ctx)
+	switch {
+	case err == nil:
+		st.BootFlags = flags.Pack()
+	case !errors.Is(err, hal.ErrNotSupported):
+		return st, err
+	}
+	ack, err := ch.GetBootInfoAcknowledge(ctx)
+	switch {
+	case err == nil:
+		st.BootInfoAcknowledge = ack.Pack()
+	case !errors.Is(err, hal.ErrNotSupported):
+		return st, err
+	}
+	return st, nil
+}
+
+// restore applies st as saved, handing the boot options to the chassis.
+func (c *ChassisStore) restore(ctx context.Context, st chassisState) error {
+	c.mu.Lock()
+	if st.RestorePolicy < PowerRestoreNoChange {
+		c.restorePolicy = st.RestorePolicy
+	}
+	c.powerOn = st.PowerOn
+	c.poh = max(st.POH, 0)
+	c.frontPanel = st.FrontPanelDisables & 0x0f
+	c.cycleInterval = st.PowerCycleInterval
+	c.caps = st.Capabilities
+	c.mu.Unlock()
+
+	ch := c.chassisHAL()
+	if ch == nil {
+		return nil
+	}
+	var errs []error
+	if len(st.BootFlags) > 0 {
+		var flags types.BootOptionParam_BootFlags
+		err := flags.Unpack(st.BootFlags)
+		if err == nil {
+			err = ch.SetBootFlags(ctx, &flags)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("boot flags: %w", err))
+		}
+	}
+	if len(st.BootInfoAcknowledge) > 0 {
+		var ack types.BootOptionParam_BootInfoAcknowledge
+		err := ack.Unpack(st.BootInfoAcknowledge)
+		if err == nil {
+			err = ch.SetBootInfoAcknowledge(ctx, &ack)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("boot info acknowledge: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// snapshot returns the firmware firewall state.
+func (s *CommandEnableStore) snapshot() commandEnableState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st := commandEnableState{DisabledCode: s.disabledCode}
+	for key := range s.disabled {
+		st.Disabled = append(st.Disabled, commandEnableEntry{key.channel, key.lun, key.netFn, key.cmd})
+	}
+	slices.SortFunc(st.Disabled, func(a, b commandEnableEntry) int {
+		return cmp.Or(cmp.Compare(a.Channel, b.Channel), cmp.Compare(a.LUN, b.LUN),
+			cmp.Compare(a.NetFn, b.NetFn), cmp.Compare(a.Command, b.Command))
+	})
+	return st
+}
+
+// restore applies st as saved.
+func (s *CommandEnableStore) restore(st commandEnableState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.disabled)
+	for _, e := range st.Disabled {
+		s.disabled[commandEnableKey{e.Channel, e.LUN & 0x03, e.NetFn, e.Command}] = struct{}{}
+	}
+	if st.DisabledCode != types.CodeInvalidCommand && st.DisabledCode != types.CodeNotSupported {
+		return ErrDisabledCommandCode
+	}
+	s.disabledCode = st.DisabledCode
+	return nil
+}
+
+// snapshot returns the non-volatile PEF configuration.
+func (p *PEFStore) snapshot() pefState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := pefState{
+		Control:           p.control,
+		ActionControl:     p.actionControl,
+		StartupDelay:      p.startupDelay,
+		AlertStartupDelay: p.alertStartupDelay,
+		UseGUID:           p.useGUID,
+		GUID:              p.guid,
+		AlertStringKeys:   append([][2]uint8(nil), p.stringKeys[1:]...),
+	}
+	for i := range p.filters {
+		st.EventFilters = append(st.EventFilters, p.filters[i].Pack())
+	}
+	for i := range p.policies {
+		st.AlertPolicies = append(st.AlertPolicies, p.policies[i].Pack())
+	}
+	for _, s := range p.strings[1:] {
+		st.AlertStrings = append(st.AlertStrings, append([]byte(nil), s...))
+	}
+	return st
+}
+
+// restore applies st as saved, without the validation of a Set PEF
+// Configuration Parameters write. Entries past the end of a table are
+// dropped, and actions the chassis cannot carry out are cleared.
+func (p *PEFStore) restore(st pefState) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	supported := p.supportedActions()
+	p.control = st.Control & 0x0f
+	p.actionControl = st.ActionControl & supported
+	p.startupDelay = st.StartupDelay
+	p.alertStartupDelay = st.AlertStartupDelay
+	p.useGUID = st.UseGUID
+	p.guid = st.GUID
+	var errs []error
+	for i, data := range st.EventFilters {
+		if i >= len(p.filters) {
+			break
+		}
+		var f types.PEFEventFilter
+		if err := f.Unpack(data); err != nil {
+			errs = append(errs, fmt.Errorf("event filter %d: %w", i+1, err))
+			continue
+		}
+		setFilterActions(&f, filterActions(&f)&supported)
+		p.filters[i] = f
+	}
+	for i, data := range st.AlertPolicies {
+		if i >= len(p.policies) {
+			break
+		}
+		if err := p.policies[i].Unpack(data); err != nil {
+			errs = append(errs, fmt.Errorf("alert policy %d: %w", i+1, err))
+		}
+	}
+	for i, key := range st.AlertStringKeys {
+		if i+1 < len(p.stringKeys) {
+			p.stringKeys[i+1] = [2]uint8{key[0] & 0x7f, key[1] & 0x7f}
+		}
+	}
+	for i, s := range st.AlertStrings {
+		if i+1 < len(p.strings) {
+			dst := p.strings[i+1]
+			clear(dst)
+			copy(dst, s)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// snapshot returns the non-volatile LAN alert configuration.
+func (a *LANAlertStore) snapshot() lanAlertState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return lanAlertState{
+		Community:    a.community,
+		Destinations: append([]LANAlertDestination(nil), a.dests[1:]...),
+	}
+}
+
+// restore applies st as saved. Destinations past the configured ones are
+// dropped.
+func (a *LANAlertStore) restore(st lanAlertState) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.community = st.Community
+	for i, d := range st.Destinations {
+		if i+1 < len(a.dests) {
+			a.dests[i+1] = d
+		}
+	}
+}
+
+// snapshot returns the non-volatile DCMI state.
+func (d *DCMIStore) snapshot() dcmiState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	st := dcmiState{
+		PowerLimitActive: d.limitActive,
+		AssetTag:         append([]byte(nil), d.assetTag...),
+		MCID:             append([]byte(nil), d.mcID...),
+		Discovery:        d.discovery,
+		DHCPTimings:      d.dhcpTimings,
+	}
+	if d.limit.Watts != 0 {
+		limit := d.limit
+		st.PowerLimit = &limit
+	}
+	for k, limit := range d.thermal {
+		st.ThermalLimits = append(st.ThermalLimits, dcmiThermalLimit{Instance: k.instance, Limit: limit})
+	}
+	slices.SortFunc(st.ThermalLimits, func(a, b dcmiThermalLimit) int { return cmp.Compare(a.Instance, b.Instance) })
+	return st
+}
+
+// restore applies st as saved, handing an active power limit to the power
+// limiter. A limit is dropped when the platform has no power meter.
+func (d *DCMIStore) restore(ctx context.Context, st dcmiState) error {
+	if len(st.AssetTag) > DCMIAssetTagMaxLen || len(st.MCID) > DCMIMCIDMaxLen {
+		return errors.New("DCMI string too long")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.assetTag = append([]byte(nil), st.AssetTag...)
+	d.mcID = append([]byte(nil), st.MCID...)
+	d.thermal = make(map[thermalKey]ThermalLimit, len(st.ThermalLimits))
+	for _, t := range st.ThermalLimits {
+		d.thermal[thermalKey{DCMIEntityInlet, t.Instance}] = t.Limit
+	}
+	d.discovery = st.Discovery
+	d.dhcpTimings = st.DHCPTimings
+
+	ph := d.powerHAL()
+	if ph == nil || st.PowerLimit == nil || st.PowerLimit.Watts == 0 {
+		return nil
+	}
+	d.limit = *st.PowerLimit
+	if !st.PowerLimitActive {
+		return nil
+	}
+	limit := d.limit
+	if err := ph.SetLimit(ctx, &limit); err != nil {
+		return fmt.Errorf("power limit: %w", err)
+	}
+	d.limitActive = true
+	return nil
+}
@@ -0,0 +1,259 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// restart returns a BMC over a fresh HAL sharing the storage of h, as the
+// same BMC after a restart.
+func restart(t *testing.T, h *mock.HAL) (*BMC, *mock.HAL) {
+	t.Helper()
+	next := mock.New()
+	next.SetStorage(h.Storage())
+	b := New(DeviceInfo{}, [16]byte{}, next, WithStateErrorHandler(func(key string, err error) {
+		t.Errorf("state %s: %v", key, err)
+	}))
+	return b, next
+}
+
+func TestBMC_StateSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	h := mock.New()
+	b := New(DeviceInfo{}, [16]byte{}, h)
+
+	err := b.Users.Upsert(3, func(u *User) error {
+		u.Name = "operator"
+		u.SetPassword([]byte("secret"))
+		u.Enabled = true
+		u.ChannelAccess[1] = UserChannelAccess{MaxPrivilege: PrivilegeLevelOperator, Enabled: true}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nv := ChannelAccess{AccessMode: ChannelAccessPreBootOnly, MaxPrivilege: PrivilegeLevelUser}
+	if err := b.Channels.SetAccess(1, false, nv); err != nil {
+		t.Fatal(err)
+	}
+	// A volatile change is lost.
+	if err := b.Channels.SetAccess(1, true, ChannelAccess{AccessMode: ChannelAccessDisabled}); err != nil {
+		t.Fatal(err)
+	}
+	if cc := b.SOL.Config().SetParam(5, []byte{0x07}); cc != types.CodeOK {
+		t.Fatalf("SOL bit rate: %v", cc)
+	}
+	var levels [MaxCipherSuiteEntries]PrivilegeLevel
+	levels[0], levels[1] = PrivilegeLevelUser, PrivilegeLevelOperator
+	if err := b.LANConfig.SetCipherSuitePrivileges(levels, 2); err != nil {
+		t.Fatal(err)
+	}
+	b.SetCipherSuites([]types.CipherSuiteID{types.CipherSuiteID17})
+	if err := b.Chassis.SetRestorePolicy(PowerRestoreAlwaysOn); err != nil {
+		t.Fatal(err)
+	}
+	flags := &types.BootOptionParam_BootFlags{BootFlagsValid: true, Persist: true, BootDeviceSelector: types.BootDeviceSelectorForcePXE}
+	if err := b.Chassis.SetBootFlags(ctx, flags); err != nil {
+		t.Fatal(err)
+	}
+
+	b, next := restart(t, h)
+
+	u, err := b.Users.GetByName("operator")
+	if err != nil || u.ID != 3 || !u.Enabled || !u.VerifyPassword([]byte("secret")) ||
+		u.ChannelAccess[1].MaxPrivilege != PrivilegeLevelOperator {
+		t.Fatalf("user after restart %+v, %v", u, err)
+	}
+	if _, err := b.Users.Get(1); err != nil {
+		t.Fatalf("anonymous user: %v", err)
+	}
+	for _, volatile := range []bool{true, false} {
+		if a, err := b.Channels.Access(1, volatile); err != nil || a != nv {
+			t.Fatalf("channel access (volatile %v) %+v, %v", volatile, a, err)
+		}
+	}
+	for sel, want := range map[uint8]uint8{5: 0x07, 6: 0x07} {
+		if data, _ := b.SOL.Config().GetParam(sel); data[0] != want {
+			t.Fatalf("SOL parameter #%d = %#x, want %#x", sel, data[0], want)
+		}
+	}
+	if got := b.LANConfig.CipherSuitePrivileges(); got != levels {
+		t.Fatalf("cipher suite privileges %v", got)
+	}
+	if got := b.ResolvedCipherSuites(); len(got) != 1 || got[0] != types.CipherSuiteID17 {
+		t.Fatalf("cipher suites %v", got)
+	}
+	if p := b.Chassis.RestorePolicy(); p != PowerRestoreAlwaysOn {
+		t.Fatalf("restore policy %d", p)
+	}
+	got, err := next.Chassis().GetBootFlags(ctx)
+	if err != nil || got.BootDeviceSelector != types.BootDeviceSelectorForcePXE || !got.Persist {
+		t.Fatalf("boot flags handed to the chassis %+v, %v", got, err)
+	}
+
+	// Deleting the user is saved too.
+	if err := b.Users.Delete(3); err != nil {
+		t.Fatal(err)
+	}
+	b, _ = restart(t, next)
+	if _, err := b.Users.Get(3); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("deleted user after restart: %v", err)
+	}
+}
+
+// TestBMC_POHSurvivesRestart verifies the POH counter is saved as it counts,
+// while the power state stays on, and is restored as a lifetime count.
+func TestBMC_POHSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	clk := &mockClock{now: time.Unix(1_700_000_000, 0)}
+	h := mock.New()
+	h.Chassis().(*mock.Chassis).On = true
+	b := New(DeviceInfo{}, [16]byte{}, h, WithClock(clk))
+	_ = b.Chassis.Poll(ctx)
+	for range 5 {
+		clk.now = clk.now.Add(POHMinutesPerCount * time.Minute)
+		_ = b.Chassis.Poll(ctx)
+	}
+
+	next := mock.New()
+	next.SetStorage(h.Storage())
+	b = New(DeviceInfo{}, [16]byte{}, next, WithClock(clk))
+	if n, err := b.Chassis.POHCounter(ctx); err != nil || n != 5 {
+		t.Fatalf("POH counter after restart: %d %v", n, err)
+	}
+}
+
+// TestBMC_ConfigStateSurvivesRestart covers the firmware firewall, PEF,
+// LAN alerting, DCMI and network interface settings.
+func TestBMC_ConfigStateSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	h := mock.New()
+	b := New(DeviceInfo{}, [16]byte{}, h)
+
+	b.CommandEnables.SetEnables(1, 0, 0x00, map[uint8]bool{0x02: false})
+	if err := b.CommandEnables.SetDisabledCode(types.CodeNotSupported); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.PEF.SetParam(uint8(types.PEFConfigParamSelector_StartupDelay), []byte{30}); err != nil {
+		t.Fatal(err)
+	}
+	filter := types.PEFEventFilter{FilterState: true, ActionPowerOff: true, SensorType: types.SensorTypeTemperature}
+	entry := append([]byte{2}, filter.Pack()...)
+	if err := b.PEF.SetParam(uint8(types.PEFConfigParamSelector_EventFilter), entry); err != nil {
+		t.Fatal(err)
+	}
+	alert := append([]byte{1, 1}, "fan failed"...)
+	if err := b.PEF.SetParam(uint8(types.PEFConfigParamSelector_AlertString), alert); err != nil {
+		t.Fatal(err)
+	}
+	var community [18]byte
+	copy(community[:], "private")
+	b.Alerts.SetCommunity(community)
+	dest := LANAlertDestination{Acknowledged: true, Retries: 3, IP: [4]byte{10, 0, 0, 9}}
+	if err := b.Alerts.SetDestination(1, dest); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.DCMI.SetAssetTag(0, []byte("rack-7")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.DCMI.SetMCIdentifier(0, []byte("bmc-7")); err != nil {
+		t.Fatal(err)
+	}
+	limit := hal.PowerLimit{Watts: 450, CorrectionTimeMs: 1000, ExceptionAction: types.DCMIExceptionAction_LogSEL, SamplingPeriodSec: 5}
+	if err := b.DCMI.SetPowerLimit(ctx, limit); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.DCMI.ActivatePowerLimit(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	thermal := ThermalLimit{LogSEL: true, Limit: 35, ExceptionTimeSec: 60}
+	if err := b.DCMI.SetThermalLimit(DCMIEntityInlet, 1, thermal); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.DCMI.SetConfigParam(types.DCMIConfigParamSelector_DHCPTiming2, 0, []byte{90}); err != nil {
+		t.Fatal(err)
+	}
+	err := b.LANConfig.UpdateNetwork(ctx, hal.NetworkFieldAddress, func(cfg *hal.IPConfig) error {
+		cfg.IP = [4]byte{192, 168, 0, 20}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, next := restart(t, h)
+
+	if b.CommandEnables.Enabled(1, 0, 0x00, 0x02) || b.CommandEnables.DisabledCode() != types.CodeNotSupported {
+		t.Fatal("command enables lost")
+	}
+	if data, err := b.PEF.GetParam(uint8(types.PEFConfigParamSelector_StartupDelay), 0, 0); err != nil || data[0] != 30 {
+		t.Fatalf("PEF startup delay %v, %v", data, err)
+	}
+	if data, err := b.PEF.GetParam(uint8(types.PEFConfigParamSelector_EventFilter), 2, 0); err != nil || string(data) != string(entry) {
+		t.Fatalf("event filter 2 % x, %v", data, err)
+	}
+	if s, ok := b.PEF.AlertString(1); !ok || string(s) != "fan failed" {
+		t.Fatalf("alert string 1 %q", s)
+	}
+	if got := b.Alerts.Community(); got != community {
+		t.Fatalf("community %q", got)
+	}
+	if got, err := b.Alerts.Destination(1); err != nil || got != dest {
+		t.Fatalf("destination 1 %+v, %v", got, err)
+	}
+	if tag, _, err := b.DCMI.AssetTag(0, 16); err != nil || string(tag) != "rack-7" {
+		t.Fatalf("asset tag %q, %v", tag, err)
+	}
+	if id, _, err := b.DCMI.MCIdentifier(0, 16); err != nil || string(id) != "bmc-7" {
+		t.Fatalf("MC ID %q, %v", id, err)
+	}
+	if got, active, err := b.DCMI.PowerLimit(); err != nil || got != limit || !active {
+		t.Fatalf("power limit %+v active %v, %v", got, active, err)
+	}
+	if got := next.Power().(*mock.Power).ActiveLimit(); got == nil || *got != limit {
+		t.Fatalf("power limit handed to the HAL %+v", got)
+	}
+	if got, err := b.DCMI.ThermalLimit(DCMIEntityInletV1, 1); err != nil || got != thermal {
+		t.Fatalf("inlet limit %+v, %v", got, err)
+	}
+	if data, err := b.DCMI.ConfigParam(types.DCMIConfigParamSelector_DHCPTiming2, 0); err != nil || data[0] != 90 {
+		t.Fatalf("DHCP timing 2 %v, %v", data, err)
+	}
+	if data, err := b.DCMI.ConfigParam(types.DCMIConfigParamSelector_DHCPTiming1, 0); err != nil || data[0] != dcmiDHCPInitialTimeout {
+		t.Fatalf("DHCP timing 1 %v, %v", data, err)
+	}
+	cfg, err := next.Network().GetConfig(ctx)
+	if err != nil || cfg.IP != [4]byte{192, 168, 0, 20} {
+		t.Fatalf("network handed to the HAL %+v, %v", cfg, err)
+	}
+}
+
+// failingState is a state store whose writes fail.
+type failingState struct{ *mock.Storage }
+
+func (s failingState) State() hal.StateStore { return s }
+
+func (s failingState) Read(context.Context, string) ([]byte, error) { return nil, hal.ErrNotFound }
+
+func (s failingState) Write(context.Context, string, []byte) error { return errors.New("disk full") }
+
+func TestBMC_StateSaveError(t *testing.T) {
+	h := mock.New()
+	h.SetStorage(failingState{&mock.Storage{}})
+	var keys []string
+	b := New(DeviceInfo{}, [16]byte{}, h, WithStateErrorHandler(func(key string, err error) {
+		keys = append(keys, key)
+	}))
+
+	// The change is committed whether or not it could be saved.
+	b.Chassis.SetFrontPanelDisables(FrontPanelDisablePowerOff)
+	if b.Chassis.FrontPanelDisables() != FrontPanelDisablePowerOff || len(keys) != 1 || keys[0] != stateKeyChassis {
+		t.Fatalf("front panel %#x, errors for %v", b.Chassis.FrontPanelDisables(), keys)
+	}
+}
@@ -0,0 +1,316 @@
+package bmc
+
+// System Info Parameters (v2.0§22.14): strings the BIOS, the OS and the
+// BMC publish about the managed system. Each string parameter is split over
+// set selectors of 16-byte blocks; the block of set selector 0 starts with
+// the string encoding and length (v2.0 Table 22-16a). The values persist
+// through [hal.SystemInfoStore] when the storage HAL implements
+// [hal.SystemInfoStorageHAL], and otherwise live in memory.
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// SystemInfoBlockSize is the size of the block one set selector of a string
+// parameter carries.
+const SystemInfoBlockSize = 16
+
+// SystemInfoMaxSets is the number of set selectors a string parameter
+// spans: the encoding and length bytes plus a string of up to 255 bytes.
+const SystemInfoMaxSets = (2 + 255 + SystemInfoBlockSize - 1) / SystemInfoBlockSize
+
+// System Info string encodings (v2.0 Table 22-16a, set selector 0 byte 2).
+const (
+	SystemInfoEncodingASCII   = 0x00 // ASCII+Latin1
+	SystemInfoEncodingUTF8    = 0x01
+	SystemInfoEncodingUnicode = 0x02 // UTF-16, MS-byte first
+)
+
+// System Info Parameter failures, mapped by the App NetFn handlers to
+// completion codes (v2.0§22.14).
+var (
+	// ErrSystemInfoParamNotSupported → CodeParameterNotSupported (80h).
+	ErrSystemInfoParamNotSupported = errors.New("system info parameter not supported")
+	// ErrSystemInfoSetInProgress → CodeParamConfigSetInProgressConflict (81h).
+	ErrSystemInfoSetInProgress = errors.New("system info parameters already set in progress")
+	// ErrSystemInfoParamReadOnly → CodeParamConfigSetReadOnly (82h): the
+	// parameter is read-only, or read-only on the requesting channel.
+	ErrSystemInfoParamReadOnly = errors.New("system info parameter is read-only")
+	// ErrSystemInfoParamInvalid → CodeRequestDataFieldInvalid: a reserved
+	// encoding or an over-long block.
+	ErrSystemInfoParamInvalid = errors.New("invalid system info parameter data")
+	// ErrSystemInfoParamOutOfRange → CodeParameterOutOfRange: a set selector
+	// past the longest string.
+	ErrSystemInfoParamOutOfRange = errors.New("system info set selector out of range")
+)
+
+// SystemInfoStore holds the System Info Parameters of the managed system.
+//
+// The system firmware version is written by the BIOS over the system
+// interface and is read-only on other channels; the BMC URL is the BMC's
+// own and read-only everywhere, set with [SystemInfoStore.SetString]. The
+// system name, OS names, OS version and base OS / hypervisor URL can be
+// written from any channel, so a guest agent's OS name is visible to LAN
+// clients.
+//
+// While Set In Progress is set, writes are kept in a staged copy that Get
+// System Info Parameters reads back; commit write or set complete stores
+// them. As with the LAN configuration, set complete commits rather than
+// rolling back.
+type SystemInfoStore struct {
+	mu sync.Mutex
+	h  hal.HAL
+
+	// values holds the parameters when the HAL has no persistent store.
+	values map[types.SystemInfoParamSelector][]byte
+
+	setInProgress bool
+	staged        map[types.SystemInfoParamSelector][]byte
+}
+
+// NewSystemInfoStore returns the System Info Parameters of a BMC whose
+// storage HAL is h.Storage().
+func NewSystemInfoStore(h hal.HAL) *SystemInfoStore {
+	return &SystemInfoStore{
+		h:      h,
+		values: make(map[types.SystemInfoParamSelector][]byte),
+		staged: make(map[types.SystemInfoParamSelector][]byte),
+	}
+}
+
+// persistent returns the HAL store the parameters persist in, or nil.
+func (s *SystemInfoStore) persistent() hal.SystemInfoStore {
+	if s.h == nil {
+		return nil
+	}
+	storage := s.h.Storage()
+	if storage == nil {
+		return nil
+	}
+	if p, ok := storage.(hal.SystemInfoStorageHAL); ok {
+		return p.SystemInfo()
+	}
+	return nil
+}
+
+// systemInfoStringParam reports whether param is one of the string
+// parameters.
+func systemInfoStringParam(param types.SystemInfoParamSelector) bool {
+	return param >= types.SystemInfoParamSelector_SystemFirmwareVersion &&
+		param <= types.SystemInfoParamSelector_ManagementURL
+}
+
+// Writable reports whether Set System Info Parameters may write param on a
+// channel, systemInterface telling whether it is the system interface.
+func (s *SystemInfoStore) Writable(param types.SystemInfoParamSelector, systemInterface bool) error {
+	switch param {
+	case types.SystemInfoParamSelector_SystemFirmwareVersion:
+		if !systemInterface {
+			return ErrSystemInfoParamReadOnly
+		}
+		return nil
+	case types.SystemInfoParamSelector_BMCURL:
+		return ErrSystemInfoParamReadOnly
+	default:
+		if !systemInfoStringParam(param) {
+			return ErrSystemInfoParamNotSupported
+		}
+		return nil
+	}
+}
+
+// SetInProgress reports whether a set is in progress (v2.0 Table 22-16a
+// param #0).
+func (s *SystemInfoStore) SetInProgress() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.setInProgress
+}
+
+// BeginSet marks a set in progress. Claiming the parameters while another
+// set is in progress fails with [ErrSystemInfoSetInProgress].
+func (s *SystemInfoStore) BeginSet() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.setInProgress {
+		return ErrSystemInfoSetInProgress
+	}
+	s.setInProgress = true
+	return nil
+}
+
+// Commit stores the staged parameters. With complete set, the set in
+// progress also ends; a failed write leaves the unwritten parameters staged
+// so the caller can retry.
+func (s *SystemInfoStore) Commit(ctx context.Context, complete bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for param, data := range s.staged {
+		if err := s.storeLocked(ctx, param, data); err != nil {
+			return err
+		}
+		delete(s.staged, param)
+	}
+	if complete {
+		s.setInProgress = false
+	}
+	return nil
+}
+
+// Abort ends any set in progress and discards staged parameters, as a BMC
+// reset does.
+func (s *SystemInfoStore) Abort() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setInProgress = false
+	clear(s.staged)
+}
+
+// Block returns the 16-byte block of set selector set of a string
+// parameter, including any staged by a set in progress. Blocks past the end
+// of the string read as zeros.
+func (s *SystemInfoStore) Block(ctx context.Context, param types.SystemInfoParamSelector, set uint8) ([]byte, error) {
+	if !systemInfoStringParam(param) {
+		return nil, ErrSystemInfoParamNotSupported
+	}
+	if set >= SystemInfoMaxSets {
+		return nil, ErrSystemInfoParamOutOfRange
+	}
+	s.mu.Lock()
+	data, err := s.valueLocked(ctx, param)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	block := make([]byte, SystemInfoBlockSize)
+	if off := int(set) * SystemInfoBlockSize; off < len(data) {
+		copy(block, data[off:])
+	}
+	return block, nil
+}
+
+// SetBlock writes the block of set selector set of a string parameter:
+// staged while a set is in progress, stored otherwise. The block of set
+// selector 0 carries the encoding and string length; a short block leaves
+// the rest of the 16 bytes as they were.
+func (s *SystemInfoStore) SetBlock(ctx context.Context, param types.SystemInfoParamSelector, set uint8, block []byte, systemInterface bool) error {
+	if err := s.Writable(param, systemInterface); err != nil {
+		return err
+	}
+	if set >= SystemInfoMaxSets {
+		return ErrSystemInfoParamOutOfRange
+	}
+	if len(block) == 0 || len(block) > SystemInfoBlockSize {
+		return ErrSystemInfoParamInvalid
+	}
+	if set == 0 && block[0]&0x0f > SystemInfoEncodingUnicode {
+		return ErrSystemInfoParamInvalid
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := s.valueLocked(ctx, param)
+	if err != nil {
+		return err
+	}
+	off := int(set) * SystemInfoBlockSize
+	if end := off + len(block); end > len(data) {
+		data = append(data, make([]byte, end-len(data))...)
+	}
+	copy(data[off:], block)
+	if s.setInProgress {
+		s.staged[param] = data
+		return nil
+	}
+	return s.storeLocked(ctx, param, data)
+}
+
+// String returns the decoded value of a string parameter, "" when it was
+// never set.
+func (s *SystemInfoStore) String(ctx context.Context, param types.SystemInfoParamSelector) (string, error) {
+	if !systemInfoStringParam(param) {
+		return "", ErrSystemInfoParamNotSupported
+	}
+	s.mu.Lock()
+	data, err := s.valueLocked(ctx, param)
+	s.mu.Unlock()
+	if err != nil || len(data) < 2 {
+		return "", err
+	}
+	raw := data[2:]
+	if n := int(data[1]); n < len(raw) {
+		raw = raw[:n]
+	}
+	if data[0]&0x0f == SystemInfoEncodingUnicode {
+		u16 := make([]uint16, len(raw)/2)
+		for i := range u16 {
+			u16[i] = binary.BigEndian.Uint16(raw[2*i:])
+		}
+		return string(utf16.Decode(u16)), nil
+	}
+	return string(raw), nil
+}
+
+// SetString stores the value of a string parameter, ASCII-encoded when it
+// is plain ASCII and UTF-8-encoded otherwise, and truncated to the whole
+// characters that fit in 255 bytes.
+// It is how the BMC sets its own parameters, so it skips the read-only
+// rules of [SystemInfoStore.Writable].
+func (s *SystemInfoStore) SetString(ctx context.Context, param types.SystemInfoParamSelector, v string) error {
+	if !systemInfoStringParam(param) {
+		return ErrSystemInfoParamNotSupported
+	}
+	encoding := uint8(SystemInfoEncodingASCII)
+	for i := 0; i < len(v); i++ {
+		if v[i] >= utf8.RuneSelf {
+			encoding = SystemInfoEncodingUTF8
+			break
+		}
+	}
+	if len(v) > 255 {
+		n := 255
+		for n > 0 && !utf8.RuneStart(v[n]) {
+			n--
+		}
+		v = v[:n]
+	}
+	data := append([]byte{encoding, uint8(len(v))}, v...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.staged, param)
+	return s.storeLocked(ctx, param, data)
+}
+
+// valueLocked returns a copy of the data of param, staged or stored. The
+// caller holds s.mu.
+func (s *SystemInfoStore) valueLocked(ctx context.Context, param types.SystemInfoParamSelector) ([]byte, error) {
+	if data, ok := s.staged[param]; ok {
+		return append([]byte(nil), data...), nil
+	}
+	if p := s.persistent(); p != nil {
+		data, err := p.Read(ctx, uint8(param))
+		if errors.Is(err, hal.ErrNotFound) {
+			return nil, nil
+		}
+		return data, err
+	}
+	return append([]byte(nil), s.values[param]...), nil
+}
+
+// storeLocked stores the data of param. The caller holds s.mu.
+func (s *SystemInfoStore) storeLocked(ctx context.Context, param types.SystemInfoParamSelector, data []byte) error {
+	if p := s.persistent(); p != nil {
+		return p.Write(ctx, uint8(param), data)
+	}
+	s.values[param] = data
+	return nil
+}
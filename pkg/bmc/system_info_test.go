@@ -0,0 +1,122 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestSystemInfoStore_Blocks(t *testing.T) {
+	m := mock.New()
+	s := NewSystemInfoStore(m)
+	ctx := context.Background()
+	name := types.SystemInfoParamSelector_SystemName
+
+	// "compute-node-0042" is 17 bytes: 14 in set 0, 3 in set 1.
+	set0 := append([]byte{SystemInfoEncodingASCII, 17}, "compute-node-0"...)
+	if err := s.SetBlock(ctx, name, 0, set0, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetBlock(ctx, name, 1, []byte("042"), false); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := s.String(ctx, name); err != nil || v != "compute-node-0042" {
+		t.Fatalf("string: %q %v", v, err)
+	}
+	block, err := s.Block(ctx, name, 1)
+	if err != nil || string(block[:3]) != "042" || len(block) != SystemInfoBlockSize || block[3] != 0 {
+		t.Fatalf("set 1: % x %v", block, err)
+	}
+	if block, _ := s.Block(ctx, name, 5); block[0] != 0 {
+		t.Fatalf("set past the string: % x", block)
+	}
+	if _, err := s.Block(ctx, name, SystemInfoMaxSets); !errors.Is(err, ErrSystemInfoParamOutOfRange) {
+		t.Fatalf("set %d: %v", SystemInfoMaxSets, err)
+	}
+
+	// The value persists through the HAL store.
+	if v, _ := NewSystemInfoStore(m).String(ctx, name); v != "compute-node-0042" {
+		t.Fatalf("after restart: %q", v)
+	}
+
+	if err := s.SetBlock(ctx, name, 0, []byte{0x03, 1, 'x'}, false); !errors.Is(err, ErrSystemInfoParamInvalid) {
+		t.Fatalf("reserved encoding: %v", err)
+	}
+}
+
+func TestSystemInfoStore_ReadOnly(t *testing.T) {
+	s := NewSystemInfoStore(nil)
+	ctx := context.Background()
+	fw := types.SystemInfoParamSelector_SystemFirmwareVersion
+
+	if err := s.SetBlock(ctx, fw, 0, []byte{0, 3, '1', '.', '0'}, false); !errors.Is(err, ErrSystemInfoParamReadOnly) {
+		t.Fatalf("firmware version off the system interface: %v", err)
+	}
+	if err := s.SetBlock(ctx, fw, 0, []byte{0, 3, '1', '.', '0'}, true); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.String(ctx, fw); v != "1.0" {
+		t.Fatalf("firmware version: %q", v)
+	}
+	if err := s.Writable(types.SystemInfoParamSelector_BMCURL, true); !errors.Is(err, ErrSystemInfoParamReadOnly) {
+		t.Fatalf("BMC URL: %v", err)
+	}
+	if err := s.SetString(ctx, types.SystemInfoParamSelector_BMCURL, "https://bmc.example/ü"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.String(ctx, types.SystemInfoParamSelector_BMCURL); v != "https://bmc.example/ü" {
+		t.Fatalf("BMC URL: %q", v)
+	}
+	// A value past 255 bytes is cut before the character that would not
+	// fit whole.
+	long := strings.Repeat("a", 254) + "ü"
+	if err := s.SetString(ctx, types.SystemInfoParamSelector_SystemName, long); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.String(ctx, types.SystemInfoParamSelector_SystemName); v != long[:254] {
+		t.Fatalf("truncated system name: %d bytes, valid UTF-8 %v", len(v), utf8.ValidString(v))
+	}
+	if err := s.Writable(0x20, true); !errors.Is(err, ErrSystemInfoParamNotSupported) {
+		t.Fatalf("unknown parameter: %v", err)
+	}
+}
+
+func TestSystemInfoStore_SetInProgress(t *testing.T) {
+	s := NewSystemInfoStore(mock.New())
+	ctx := context.Background()
+	osName := types.SystemInfoParamSelector_OSName
+
+	if err := s.BeginSet(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.BeginSet(); !errors.Is(err, ErrSystemInfoSetInProgress) {
+		t.Fatalf("second set in progress: %v", err)
+	}
+	if err := s.SetBlock(ctx, osName, 0, append([]byte{0, 5}, "Linux"...), false); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.String(ctx, osName); v != "Linux" {
+		t.Fatalf("staged value: %q", v)
+	}
+	if v, _ := NewSystemInfoStore(s.h).String(ctx, osName); v != "" {
+		t.Fatalf("a staged write was stored: %q", v)
+	}
+	if err := s.Commit(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := NewSystemInfoStore(s.h).String(ctx, osName); v != "Linux" || s.SetInProgress() {
+		t.Fatalf("after set complete: %q in progress=%v", v, s.SetInProgress())
+	}
+
+	_ = s.BeginSet()
+	_ = s.SetBlock(ctx, osName, 0, append([]byte{0, 7}, "Windows"...), false)
+	s.Abort()
+	if v, _ := s.String(ctx, osName); v != "Linux" || s.SetInProgress() {
+		t.Fatalf("after abort: %q in progress=%v", v, s.SetInProgress())
+	}
+}
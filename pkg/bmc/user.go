@@ -141,6 +141,8 @@ type UserStore struct {
 	// is immutable after construction, so it needs no locking.
 	maxUsers uint8
 	users    map[uint8]*User
+	// onChange, when set, is told of each committed change.
+	onChange func()
 }
 
 // UserStoreOption configures a [UserStore] at construction time.
@@ -295,6 +297,14 @@ func (s *UserStore) FindEnabledByNameOnChannel(name string, channel uint8) (*Use
 // past its return, since using the live pointer outside the lock recreates the
 // race the snapshot lookups exist to prevent.
 func (s *UserStore) Update(id uint8, fn func(*User) error) error {
+	if err := s.update(id, fn); err != nil {
+		return err
+	}
+	s.changed()
+	return nil
+}
+
+func (s *UserStore) update(id uint8, fn func(*User) error) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	u, ok := s.users[id]
@@ -316,6 +326,14 @@ func (s *UserStore) Update(id uint8, fn func(*User) error) error {
 // rejected with [ErrUsernameTaken] to keep name-based session lookup
 // deterministic. Returns [ErrInvalidUserID] for an id outside 1..max.
 func (s *UserStore) Upsert(id uint8, fn func(*User) error) error {
+	if err := s.upsert(id, fn); err != nil {
+		return err
+	}
+	s.changed()
+	return nil
+}
+
+func (s *UserStore) upsert(id uint8, fn func(*User) error) error {
 	if id < 1 || id > s.maxUsers {
 		return ErrInvalidUserID
 	}
@@ -347,14 +365,71 @@ func (s *UserStore) Delete(id uint8) error {
 		return fmt.Errorf("cannot delete anonymous user (ID 1)")
 	}
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	if _, ok := s.users[id]; !ok {
+		s.mu.Unlock()
 		return fmt.Errorf("user %d: %w", id, ErrUserNotFound)
 	}
 	delete(s.users, id)
+	s.mu.Unlock()
+	s.changed()
 	return nil
 }
 
+// SetOnChange installs fn to be told, after the store lock is released, of
+// each change committed through Update, Upsert and Delete. Construction-time
+// seeding through Add is not reported.
+func (s *UserStore) SetOnChange(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+func (s *UserStore) changed() {
+	s.mu.RLock()
+	fn := s.onChange
+	s.mu.RUnlock()
+	if fn != nil {
+		fn()
+	}
+}
+
+// snapshot returns copies of every user in ID order.
+func (s *UserStore) snapshot() []*User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]*User, 0, len(s.users))
+	for id := uint8(1); id <= s.maxUsers; id++ {
+		if u, ok := s.users[id]; ok {
+			out = append(out, copyUser(u))
+		}
+	}
+	return out
+}
+
+// restore replaces the users with copies of users. Slots past the store
+// maximum are dropped, and the anonymous user is kept when users lacks it.
+func (s *UserStore) restore(users []*User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	table := map[uint8]*User{1: s.users[1]}
+	for _, u := range users {
+		if u == nil || u.ID < 1 || u.ID > s.maxUsers {
+			continue
+		}
+		cp := copyUser(u)
+		if cp.ChannelAccess == nil {
+			cp.ChannelAccess = make(map[uint8]UserChannelAccess)
+		}
+		table[u.ID] = cp
+	}
+	s.users = table
+}
+
+// List returns snapshot copies of every user in ID order.
+func (s *UserStore) List() []*User {
+	return s.snapshot()
+}
+
 // Count returns the number of configured users.
 func (s *UserStore) Count() int {
 	s.mu.RLock()
@@ -0,0 +1,352 @@
+package bmc
+
+// BMC Watchdog Timer (v2.0§27): countdown, pre-timeout interrupt, timer use
+// expiration flags and the timeout actions carried out through
+// [hal.ChassisHAL]. The countdown is kept as a deadline on the BMC clock;
+// [BMC.Run] calls [Watchdog.Poll] to fire pre-timeouts and expirations.
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// WatchdogPollInterval is how often [BMC.Run] polls the watchdog; it
+// matches the 100 ms resolution of the countdown.
+const WatchdogPollInterval = 100 * time.Millisecond
+
+// watchdogCountdownUnit is the unit of the initial and present countdown
+// values (v2.0§27.6).
+const watchdogCountdownUnit = 100 * time.Millisecond
+
+// Timer use values (v2.0 Table 27-6, byte 1 [2:0]). Bit n of the timer use
+// expiration flags corresponds to timer use n.
+const (
+	WatchdogTimerUseBIOSFRB2 = 0x01
+	WatchdogTimerUseBIOSPOST = 0x02
+	WatchdogTimerUseOSLoad   = 0x03
+	WatchdogTimerUseSMSOS    = 0x04
+	WatchdogTimerUseOEM      = 0x05
+)
+
+// Timeout actions (v2.0 Table 27-6, byte 2 [2:0]).
+const (
+	WatchdogActionNone       = 0x00
+	WatchdogActionHardReset  = 0x01
+	WatchdogActionPowerDown  = 0x02
+	WatchdogActionPowerCycle = 0x03
+)
+
+// Pre-timeout interrupts (v2.0 Table 27-6, byte 2 [6:4]).
+const (
+	WatchdogPreTimeoutNone      = 0x00
+	WatchdogPreTimeoutSMI       = 0x01
+	WatchdogPreTimeoutNMI       = 0x02
+	WatchdogPreTimeoutMessaging = 0x03
+)
+
+// watchdogExpirationFlagsMask covers the timer use expiration flags, bits
+// 1-5 (v2.0 Table 27-6, byte 4).
+const watchdogExpirationFlagsMask = 0x3e
+
+// watchdogEventInterrupt is the Watchdog 2 sensor offset logged for a
+// pre-timeout interrupt (v2.0 Table 42-3); offsets 00h-03h are the timeout
+// actions themselves.
+const watchdogEventInterrupt = 0x08
+
+// DefaultWatchdogSensorNumber is the sensor number Watchdog 2 events are
+// logged under until [Watchdog.SetSensorNumber] sets the one the platform's
+// sensor records use.
+const DefaultWatchdogSensorNumber = 0x00
+
+// Watchdog command failures, mapped by the App NetFn handlers to completion
+// codes (v2.0§27).
+var (
+	// ErrWatchdogUninitialized → CodeResetWatchdogTimerUninitialized (80h):
+	// Reset Watchdog Timer before any Set Watchdog Timer.
+	ErrWatchdogUninitialized = errors.New("watchdog timer not initialized")
+	// ErrWatchdogInvalidConfig → CodeRequestDataFieldInvalid: a reserved
+	// timer use, timeout action or pre-timeout interrupt.
+	ErrWatchdogInvalidConfig = errors.New("invalid watchdog timer configuration")
+)
+
+// WatchdogConfig is the configuration programmed by Set Watchdog Timer
+// (v2.0§27.6).
+type WatchdogConfig struct {
+	DontLog             bool
+	TimerUse            uint8
+	PreTimeoutInterrupt uint8
+	TimeoutAction       uint8
+	// PreTimeoutInterval is in seconds.
+	PreTimeoutInterval uint8
+	// InitialCountdown is in 100 ms units.
+	InitialCountdown uint16
+}
+
+// WatchdogStatus is BMC-side watchdog state (not a wire response).
+// Handlers map this to app.GetWatchdogTimerResponse (v2.0§27.7).
+type WatchdogStatus struct {
+	WatchdogConfig
+	Running         bool
+	ExpirationFlags uint8
+	// PresentCountdown is in 100 ms units.
+	PresentCountdown uint16
+}
+
+// Watchdog is the BMC watchdog timer.
+type Watchdog struct {
+	mu       sync.Mutex
+	h        hal.HAL
+	clock    clock.Clock
+	logEvent func(context.Context, PlatformEvent) error
+	// onRestart, when set, records a reset or power cycle timeout action
+	// as the system restart cause.
+	onRestart func(cause uint8)
+	// onPreTimeoutFlag, when set, is told each time the pre-timeout
+	// interrupt flag is raised or cleared.
+	onPreTimeoutFlag func()
+
+	sensorNumber uint8
+
+	cfg         WatchdogConfig
+	initialized bool
+	running     bool
+	deadline    time.Time // expiry while running
+	present     uint16    // countdown while stopped
+
+	preTimeoutFired bool // the pre-timeout of this countdown was raised
+	preTimeoutFlag  bool // pre-timeout interrupt not yet cleared
+	expiration      uint8
+}
+
+// NewWatchdog returns a stopped, uninitialized watchdog. Timeout actions
+// go to h's chassis; logEvent, when non-nil, receives the Watchdog 2
+// events.
+func NewWatchdog(h hal.HAL, clk clock.Clock, logEvent func(context.Context, PlatformEvent) error) *Watchdog {
+	if clk == nil {
+		clk = clock.Real
+	}
+	return &Watchdog{h: h, clock: clk, logEvent: logEvent, sensorNumber: DefaultWatchdogSensorNumber}
+}
+
+// SetSensorNumber sets the sensor number Watchdog 2 events are logged
+// under.
+func (w *Watchdog) SetSensorNumber(n uint8) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.sensorNumber = n
+}
+
+// SetOnRestart sets the function told of each system restart a timeout
+// action causes.
+func (w *Watchdog) SetOnRestart(fn func(cause uint8)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onRestart = fn
+}
+
+// SetOnPreTimeoutFlag sets the function told each time the pre-timeout
+// interrupt flag is raised or cleared. It is called without the watchdog
+// locked.
+func (w *Watchdog) SetOnPreTimeoutFlag(fn func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onPreTimeoutFlag = fn
+}
+
+// Set programs the watchdog (v2.0§27.6). A running timer is stopped unless
+// dontStop is set, in which case it keeps running from the new initial
+// countdown; a stopped timer stays stopped. Expiration flags set in
+// clearFlags are cleared.
+func (w *Watchdog) Set(cfg WatchdogConfig, dontStop bool, clearFlags uint8) error {
+	if cfg.TimerUse < WatchdogTimerUseBIOSFRB2 || cfg.TimerUse > WatchdogTimerUseOEM ||
+		cfg.TimeoutAction > WatchdogActionPowerCycle ||
+		cfg.PreTimeoutInterrupt > WatchdogPreTimeoutMessaging {
+		return ErrWatchdogInvalidConfig
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cfg = cfg
+	w.initialized = true
+	w.expiration &^= clearFlags & watchdogExpirationFlagsMask
+	w.preTimeoutFired = false
+	if w.running && dontStop {
+		w.deadline = w.clock.Now().Add(time.Duration(cfg.InitialCountdown) * watchdogCountdownUnit)
+		return nil
+	}
+	w.running = false
+	w.present = cfg.InitialCountdown
+	return nil
+}
+
+// Reset starts the timer from its initial countdown, restarting it if it
+// is running (v2.0§27.5).
+func (w *Watchdog) Reset() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.initialized {
+		return ErrWatchdogUninitialized
+	}
+	w.running = true
+	w.preTimeoutFired = false
+	w.deadline = w.clock.Now().Add(time.Duration(w.cfg.InitialCountdown) * watchdogCountdownUnit)
+	return nil
+}
+
+// Status returns the watchdog state for Get Watchdog Timer (v2.0§27.7).
+func (w *Watchdog) Status() WatchdogStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return WatchdogStatus{
+		WatchdogConfig:   w.cfg,
+		Running:          w.running,
+		ExpirationFlags:  w.expiration,
+		PresentCountdown: w.presentLocked(),
+	}
+}
+
+// presentLocked returns the present countdown, rounded up to whole 100 ms
+// units so a running timer never reads zero before it expires.
+func (w *Watchdog) presentLocked() uint16 {
+	if !w.running {
+		return w.present
+	}
+	left := w.deadline.Sub(w.clock.Now())
+	if left <= 0 {
+		return 0
+	}
+	return uint16((left + watchdogCountdownUnit - 1) / watchdogCountdownUnit)
+}
+
+// PreTimeoutFlag reports whether a pre-timeout interrupt has occurred and
+// not yet been cleared.
+func (w *Watchdog) PreTimeoutFlag() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.preTimeoutFlag
+}
+
+// ClearPreTimeoutFlag clears the pre-timeout interrupt flag.
+func (w *Watchdog) ClearPreTimeoutFlag() {
+	w.mu.Lock()
+	was := w.preTimeoutFlag
+	w.preTimeoutFlag = false
+	fn := w.onPreTimeoutFlag
+	w.mu.Unlock()
+	if was && fn != nil {
+		fn()
+	}
+}
+
+// Poll fires the pre-timeout interrupt once the countdown reaches the
+// pre-timeout interval, and on expiry stops the timer, sets the timer use
+// expiration flag and carries out the timeout action. Both are logged as
+// Watchdog 2 events unless the configuration says not to.
+func (w *Watchdog) Poll(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return nil
+	}
+	cfg := w.cfg
+	left := w.deadline.Sub(w.clock.Now())
+	var events []PlatformEvent
+	var onPreTimeoutFlag func()
+	preTimeout := time.Duration(cfg.PreTimeoutInterval) * time.Second
+	fired := !w.preTimeoutFired && cfg.PreTimeoutInterrupt != WatchdogPreTimeoutNone && left <= preTimeout
+	if fired {
+		w.preTimeoutFired = true
+		w.preTimeoutFlag = true
+		onPreTimeoutFlag = w.onPreTimeoutFlag
+		events = append(events, w.eventLocked(watchdogEventInterrupt))
+	}
+	expired := left <= 0
+	if expired {
+		w.running = false
+		w.present = 0
+		w.expiration |= 1 << cfg.TimerUse
+		events = append(events, w.eventLocked(cfg.TimeoutAction))
+	}
+	onRestart := w.onRestart
+	w.mu.Unlock()
+
+	if onPreTimeoutFlag != nil {
+		onPreTimeoutFlag()
+	}
+	var errs []error
+	if fired && cfg.PreTimeoutInterrupt == WatchdogPreTimeoutNMI {
+		if err := w.diagnosticInterrupt(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if !cfg.DontLog && w.logEvent != nil {
+		for _, ev := range events {
+			if err := w.logEvent(ctx, ev); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if expired {
+		err := w.timeoutAction(ctx, cfg.TimeoutAction)
+		if err != nil {
+			errs = append(errs, err)
+		} else if onRestart != nil && (cfg.TimeoutAction == WatchdogActionHardReset || cfg.TimeoutAction == WatchdogActionPowerCycle) {
+			onRestart(RestartCauseWatchdog)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// eventLocked builds the Watchdog 2 event for offset (v2.0 Table 42-3):
+// event data 2 carries the interrupt type and the timer use.
+func (w *Watchdog) eventLocked(offset uint8) PlatformEvent {
+	return PlatformEvent{
+		GeneratorID:      types.GeneratorID(types.BMC_SA),
+		SensorType:       types.SensorTypeWatchdog2,
+		SensorNumber:     w.sensorNumber,
+		EventReadingType: types.EventReadingTypeSensorSpecific,
+		EventDir:         types.EventDirAssertion,
+		EventData: types.EventData{
+			EventData1: 0xc0 | offset,
+			EventData2: w.cfg.PreTimeoutInterrupt<<4 | w.cfg.TimerUse,
+			EventData3: 0xff,
+		},
+	}
+}
+
+// diagnosticInterrupt pulses the NMI pre-timeout interrupt to the managed
+// system, when its chassis implements [hal.DiagnosticInterruptHAL].
+func (w *Watchdog) diagnosticInterrupt(ctx context.Context) error {
+	if w.h == nil {
+		return nil
+	}
+	if di, ok := w.h.Chassis().(hal.DiagnosticInterruptHAL); ok {
+		return di.DiagnosticInterrupt(ctx)
+	}
+	return nil
+}
+
+// timeoutAction carries out a timeout action on the managed system.
+func (w *Watchdog) timeoutAction(ctx context.Context, action uint8) error {
+	if action == WatchdogActionNone || w.h == nil {
+		return nil
+	}
+	ch := w.h.Chassis()
+	if ch == nil {
+		return nil
+	}
+	switch action {
+	case WatchdogActionHardReset:
+		return ch.ColdReset(ctx)
+	case WatchdogActionPowerDown:
+		return ch.SetPower(ctx, false)
+	case WatchdogActionPowerCycle:
+		return ch.PowerCycle(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func newTestWatchdog() (*Watchdog, *mockClock, *mock.HAL, *[]PlatformEvent) {
+	clk := &mockClock{now: time.Unix(1_700_000_000, 0)}
+	m := mock.New()
+	var events []PlatformEvent
+	w := NewWatchdog(m, clk, func(_ context.Context, ev PlatformEvent) error {
+		events = append(events, ev)
+		return nil
+	})
+	return w, clk, m, &events
+}
+
+func TestWatchdog_ResetUninitialized(t *testing.T) {
+	w, _, _, _ := newTestWatchdog()
+	if err := w.Reset(); !errors.Is(err, ErrWatchdogUninitialized) {
+		t.Fatalf("want ErrWatchdogUninitialized, got %v", err)
+	}
+	if err := w.Set(WatchdogConfig{TimerUse: 0}, false, 0); !errors.Is(err, ErrWatchdogInvalidConfig) {
+		t.Fatalf("reserved timer use: want ErrWatchdogInvalidConfig, got %v", err)
+	}
+}
+
+func TestWatchdog_CountdownAndHardReset(t *testing.T) {
+	w, clk, m, events := newTestWatchdog()
+	ctx := context.Background()
+	cfg := WatchdogConfig{
+		TimerUse:            WatchdogTimerUseSMSOS,
+		TimeoutAction:       WatchdogActionHardReset,
+		PreTimeoutInterrupt: WatchdogPreTimeoutNMI,
+		PreTimeoutInterval:  2,
+		InitialCountdown:    50, // 5 s
+	}
+	if err := w.Set(cfg, false, 0); err != nil {
+		t.Fatal(err)
+	}
+	if st := w.Status(); st.Running || st.PresentCountdown != 50 {
+		t.Fatalf("after set: %+v", st)
+	}
+	if err := w.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	clk.now = clk.now.Add(2500 * time.Millisecond)
+	if err := w.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if st := w.Status(); !st.Running || st.PresentCountdown != 25 || w.PreTimeoutFlag() {
+		t.Fatalf("mid countdown: %+v pretimeout=%v", st, w.PreTimeoutFlag())
+	}
+
+	// Entering the 2 s pre-timeout interval raises the interrupt once.
+	clk.now = clk.now.Add(time.Second)
+	_ = w.Poll(ctx)
+	_ = w.Poll(ctx)
+	if !w.PreTimeoutFlag() || len(*events) != 1 || (*events)[0].Offset() != watchdogEventInterrupt {
+		t.Fatalf("pre-timeout: flag=%v events=%+v", w.PreTimeoutFlag(), *events)
+	}
+	if ed2 := (*events)[0].EventData.EventData2; ed2 != 0x24 {
+		t.Fatalf("pre-timeout event data 2: want NMI/SMS-OS 0x24, got %#02x", ed2)
+	}
+	if n := m.Chassis().(*mock.Chassis).DiagInterrupts; n != 1 {
+		t.Fatalf("NMI pre-timeout pulsed %d diagnostic interrupts, want 1", n)
+	}
+
+	clk.now = clk.now.Add(2 * time.Second)
+	if err := w.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	st := w.Status()
+	if st.Running || st.PresentCountdown != 0 || st.ExpirationFlags != 1<<WatchdogTimerUseSMSOS {
+		t.Fatalf("after expiry: %+v", st)
+	}
+	if m.Chassis().(*mock.Chassis).ColdResets != 1 {
+		t.Fatal("hard reset action not carried out")
+	}
+	last := (*events)[len(*events)-1]
+	if last.SensorType != types.SensorTypeWatchdog2 || last.Offset() != WatchdogActionHardReset {
+		t.Fatalf("timeout event: %+v", last)
+	}
+
+	// Set clears the expiration flags it selects.
+	if err := w.Set(cfg, false, 1<<WatchdogTimerUseSMSOS); err != nil {
+		t.Fatal(err)
+	}
+	if st := w.Status(); st.ExpirationFlags != 0 {
+		t.Fatalf("flags not cleared: %#02x", st.ExpirationFlags)
+	}
+}
+
+func TestWatchdog_DontStopAndDontLog(t *testing.T) {
+	w, clk, m, events := newTestWatchdog()
+	ctx := context.Background()
+	cfg := WatchdogConfig{DontLog: true, TimerUse: WatchdogTimerUseOSLoad, TimeoutAction: WatchdogActionPowerDown, InitialCountdown: 100}
+	if err := w.Set(cfg, false, 0); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Reset()
+	clk.now = clk.now.Add(4 * time.Second)
+
+	// "Don't stop" keeps the timer running from the new countdown.
+	cfg.InitialCountdown = 30
+	if err := w.Set(cfg, true, 0); err != nil {
+		t.Fatal(err)
+	}
+	if st := w.Status(); !st.Running || st.PresentCountdown != 30 {
+		t.Fatalf("don't stop: %+v", st)
+	}
+	// Without it, Set stops the timer.
+	if err := w.Set(cfg, false, 0); err != nil {
+		t.Fatal(err)
+	}
+	clk.now = clk.now.Add(time.Minute)
+	_ = w.Poll(ctx)
+	if w.Status().Running || m.Chassis().(*mock.Chassis).On {
+		t.Fatal("stopped timer expired")
+	}
+	if err := w.Set(cfg, true, 0); err != nil || w.Status().Running {
+		t.Fatalf("don't stop on a stopped timer must leave it stopped: err=%v", err)
+	}
+
+	m.Chassis().(*mock.Chassis).On = true
+	_ = w.Reset()
+	clk.now = clk.now.Add(3 * time.Second)
+	_ = w.Poll(ctx)
+	if m.Chassis().(*mock.Chassis).On {
+		t.Fatal("power down action not carried out")
+	}
+	if len(*events) != 0 {
+		t.Fatalf("don't log: %+v", *events)
+	}
+}
@@ -49,7 +49,7 @@ func SensorFilterOptionIsSensorType(sensorTypes ...types.SensorType) func(sensor
 func (c *Client) GetSensors(ctx context.Context, filterOptions ...SensorFilterOption) ([]*types.Sensor, error) {
 	var out = make([]*types.Sensor, 0)
 
-	sdrs, err := c.GetSDRs(ctx, types.SDRRecordTypeFullSensor, types.SDRRecordTypeCompactSensor)
+	sdrs, err := c.sensorSDRs(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("GetSDRs failed, err: %w", err)
 	}
@@ -92,7 +92,7 @@ func (c *Client) GetSensors(ctx context.Context, filterOptions ...SensorFilterOp
 func (c *Client) GetSensorsAny(ctx context.Context, filterOptions ...SensorFilterOption) ([]*types.Sensor, error) {
 	var out = make([]*types.Sensor, 0)
 
-	sdrs, err := c.GetSDRs(ctx, types.SDRRecordTypeFullSensor, types.SDRRecordTypeCompactSensor)
+	sdrs, err := c.sensorSDRs(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("GetSDRs failed, err: %w", err)
 	}
@@ -122,7 +122,7 @@ func (c *Client) GetSensorsAny(ctx context.Context, filterOptions ...SensorFilte
 // GetSensorsStream behaves like GetSensors, but returns an iterator of sensors instead of a slice.
 func (c *Client) GetSensorsStream(ctx context.Context, filterOptions ...SensorFilterOption) iter.Seq[*types.Result[types.Sensor]] {
 	return func(yield func(*types.Result[types.Sensor]) bool) {
-		sdrs := c.GetSDRsStream(ctx, types.SDRRecordTypeFullSensor, types.SDRRecordTypeCompactSensor)
+		sdrs := c.sensorSDRsStream(ctx)
 
 		for result := range sdrs {
 			select {
@@ -170,7 +170,7 @@ func (c *Client) GetSensorsStream(ctx context.Context, filterOptions ...SensorFi
 // GetSensorsAnyStream behaves like GetSensorsAny, but returns an iterator of sensors instead of a slice.
 func (c *Client) GetSensorsAnyStream(ctx context.Context, filterOptions ...SensorFilterOption) iter.Seq[*types.Result[types.Sensor]] {
 	return func(yield func(*types.Result[types.Sensor]) bool) {
-		sdrs := c.GetSDRsStream(ctx, types.SDRRecordTypeFullSensor, types.SDRRecordTypeCompactSensor)
+		sdrs := c.sensorSDRsStream(ctx)
 		for result := range sdrs {
 			select {
 			case <-ctx.Done():
@@ -214,6 +214,34 @@ func (c *Client) GetSensorsAnyStream(ctx context.Context, filterOptions ...Senso
 	}
 }
 
+// sensorSDRs returns the Full and Compact sensor records, from the SDR cache
+// when the client has one.
+func (c *Client) sensorSDRs(ctx context.Context) ([]*types.SDR, error) {
+	if c.sdrCache != nil {
+		return c.sdrCache.sensorSDRs(ctx, c)
+	}
+	return c.GetSDRs(ctx, types.SDRRecordTypeFullSensor, types.SDRRecordTypeCompactSensor)
+}
+
+// sensorSDRsStream is sensorSDRs as an iterator.
+func (c *Client) sensorSDRsStream(ctx context.Context) iter.Seq[*types.Result[types.SDR]] {
+	if c.sdrCache == nil {
+		return c.GetSDRsStream(ctx, types.SDRRecordTypeFullSensor, types.SDRRecordTypeCompactSensor)
+	}
+	return func(yield func(*types.Result[types.SDR]) bool) {
+		sdrs, err := c.sdrCache.sensorSDRs(ctx, c)
+		if err != nil {
+			yield(&types.Result[types.SDR]{Err: err})
+			return
+		}
+		for _, sdr := range sdrs {
+			if !yield(&types.Result[types.SDR]{Ok: sdr}) {
+				return
+			}
+		}
+	}
+}
+
 // GetSensorByID returns the sensor with current reading and status by specified sensor number.
 func (c *Client) GetSensorByID(ctx context.Context, sensorNumber uint8) (*types.Sensor, error) {
 	sdr, err := c.GetSDRBySensorID(ctx, sensorNumber)
@@ -392,6 +392,22 @@ func (c *Client) AddSELEntry(ctx context.Context, sel *types.SEL) (response *sto
 	return
 }
 
+// PartialAddSELEntry transfers one fragment of a SEL record. recordID is 0
+// for the first fragment and the ID returned by the previous fragment
+// thereafter; lastPart marks the fragment that completes the record.
+func (c *Client) PartialAddSELEntry(ctx context.Context, reservationID uint16, recordID uint16, offset uint8, lastPart bool, data []byte) (response *storage.PartialAddSELEntryResponse, err error) {
+	request := &storage.PartialAddSELEntryRequest{
+		ReservationID: reservationID,
+		RecordID:      recordID,
+		Offset:        offset,
+		LastPart:      lastPart,
+		RecordData:    data,
+	}
+	response = &storage.PartialAddSELEntryResponse{}
+	err = c.Exchange(ctx, request, response)
+	return
+}
+
 func (c *Client) GetSDRRepoAllocInfo(ctx context.Context) (response *storage.GetSDRRepoAllocInfoResponse, err error) {
 	request := &storage.GetSDRRepoAllocInfoRequest{}
 	response = &storage.GetSDRRepoAllocInfoResponse{}
@@ -863,3 +879,76 @@ func (c *Client) SetSELTime(ctx context.Context, t time.Time) (response *storage
 	err = c.Exchange(ctx, request, response)
 	return
 }
+
+// AddSDR adds a whole SDR to the SDR repository and returns the Record ID
+// the BMC assigned to it.
+func (c *Client) AddSDR(ctx context.Context, record []byte) (response *storage.AddSDRResponse, err error) {
+	request := &storage.AddSDRRequest{
+		RecordData: record,
+	}
+	response = &storage.AddSDRResponse{}
+	err = c.Exchange(ctx, request, response)
+	return
+}
+
+// PartialAddSDR transfers one fragment of an SDR. recordID is 0 for the
+// first fragment and the ID returned by the previous fragment thereafter;
+// lastPart marks the fragment that completes the record.
+func (c *Client) PartialAddSDR(ctx context.Context, reservationID uint16, recordID uint16, offset uint8, lastPart bool, data []byte) (response *storage.PartialAddSDRResponse, err error) {
+	request := &storage.PartialAddSDRRequest{
+		ReservationID: reservationID,
+		RecordID:      recordID,
+		Offset:        offset,
+		LastPart:      lastPart,
+		RecordData:    data,
+	}
+	response = &storage.PartialAddSDRResponse{}
+	err = c.Exchange(ctx, request, response)
+	return
+}
+
+func (c *Client) DeleteSDR(ctx context.Context, reservationID uint16, recordID uint16) (response *storage.DeleteSDRResponse, err error) {
+	request := &storage.DeleteSDRRequest{
+		ReservationID: reservationID,
+		RecordID:      recordID,
+	}
+	response = &storage.DeleteSDRResponse{}
+	err = c.Exchange(ctx, request, response)
+	return
+}
+
+func (c *Client) ClearSDRRepo(ctx context.Context, reservationID uint16) (response *storage.ClearSDRRepoResponse, err error) {
+	request := &storage.ClearSDRRepoRequest{
+		ReservationID:        reservationID,
+		GetErasureStatusFlag: false,
+	}
+	response = &storage.ClearSDRRepoResponse{}
+	err = c.Exchange(ctx, request, response)
+	return
+}
+
+func (c *Client) EnterSDRRepoUpdateMode(ctx context.Context) (response *storage.EnterSDRRepoUpdateModeResponse, err error) {
+	request := &storage.EnterSDRRepoUpdateModeRequest{}
+	response = &storage.EnterSDRRepoUpdateModeResponse{}
+	err = c.Exchange(ctx, request, response)
+	return
+}
+
+func (c *Client) ExitSDRRepoUpdateMode(ctx context.Context) (response *storage.ExitSDRRepoUpdateModeResponse, err error) {
+	request := &storage.ExitSDRRepoUpdateModeRequest{}
+	response = &storage.ExitSDRRepoUpdateModeResponse{}
+	err = c.Exchange(ctx, request, response)
+	return
+}
+
+// RunInitializationAgent runs the initialization agent, which initializes
+// the sensors from the SDR repository; with getStatus set it only reports
+// whether the last run completed.
+func (c *Client) RunInitializationAgent(ctx context.Context, getStatus bool) (response *storage.RunInitializationAgentResponse, err error) {
+	request := &storage.RunInitializationAgentRequest{
+		GetStatus: getStatus,
+	}
+	response = &storage.RunInitializationAgentResponse{}
+	err = c.Exchange(ctx, request, response)
+	return
+}
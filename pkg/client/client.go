@@ -84,6 +84,10 @@ type Client struct {
 	// starts from defaultFRUReadSize and remembers reductions across chunks.
 	fruMaxReadSize uint8
 
+	// sdrCache, when set, supplies the sensor records of the sensor
+	// discovery functions instead of a walk of the SDR repository.
+	sdrCache *SDRCache
+
 	// activeSOLStream is protected by l.
 	activeSOLStream *solStream
 
@@ -187,6 +191,14 @@ func (c *Client) WithOpenBackend(pref string) *Client {
 	return c
 }
 
+// WithSDRCache makes GetSensors, GetSensorsAny and their Stream variants
+// take the sensor records from cache, which may be shared by the Clients of
+// one BMC, instead of walking the SDR repository on every call.
+func (c *Client) WithSDRCache(cache *SDRCache) *Client {
+	c.sdrCache = cache
+	return c
+}
+
 func (c *Client) WithDebug(debug bool) *Client {
 	c.debug = debug
 	return c
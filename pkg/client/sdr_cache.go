@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// SDRCache keeps the sensor records of one BMC's SDR repository, so that a
+// sensor scan costs one Get SDR Repository Info plus the sensor reads instead
+// of a walk of the whole repository. The records are read again when the
+// repository's record count or most recent addition or erase timestamp
+// differs from when they were read (v2.0§33.9).
+//
+// An SDRCache is safe for concurrent use; install it on each Client talking
+// to the BMC with [Client.WithSDRCache].
+type SDRCache struct {
+	mu sync.Mutex
+
+	valid    bool
+	count    uint16
+	addition time.Time
+	erase    time.Time
+	sdrs     []*types.SDR
+}
+
+// NewSDRCache returns an empty SDRCache.
+func NewSDRCache() *SDRCache {
+	return &SDRCache{}
+}
+
+// Len returns the number of sensor records cached.
+func (s *SDRCache) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sdrs)
+}
+
+// Invalidate drops the cached records, so the next use reads them again.
+func (s *SDRCache) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.valid = false
+	s.sdrs = nil
+}
+
+// sensorSDRs returns the cached Full and Compact sensor records, reading them
+// through c when the repository changed. The records are shared: callers
+// must not modify them.
+func (s *SDRCache) sensorSDRs(ctx context.Context, c *Client) ([]*types.SDR, error) {
+	info, err := c.GetSDRRepoInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetSDRRepoInfo failed, err: %w", err)
+	}
+
+	// Held across the walk, so concurrent scans of one BMC read the
+	// repository once.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.valid && s.count == info.RecordCount &&
+		s.addition.Equal(info.MostRecentAdditionTime) && s.erase.Equal(info.MostRecentEraseTime) {
+		return s.sdrs, nil
+	}
+
+	sdrs, err := c.GetSDRsRaw(ctx, types.SDRRecordTypeFullSensor, types.SDRRecordTypeCompactSensor)
+	if err != nil {
+		s.valid, s.sdrs = false, nil
+		return nil, fmt.Errorf("GetSDRsRaw failed, err: %w", err)
+	}
+	s.valid = true
+	s.count = info.RecordCount
+	s.addition = info.MostRecentAdditionTime
+	s.erase = info.MostRecentEraseTime
+	s.sdrs = sdrs
+	return sdrs, nil
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/server"
+	"github.com/bougou/go-ipmi/pkg/transport/udp"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func testTemperatureSDR(number uint8, name string) *types.SDRFull {
+	return &types.SDRFull{
+		GeneratorID:            0x20,
+		SensorNumber:           types.SensorNumber(number),
+		SensorType:             types.SensorTypeTemperature,
+		SensorEventReadingType: types.EventReadingTypeThreshold,
+		SensorInitialization:   types.SensorInitialization{InitScanning: true},
+		SensorUnit:             types.SensorUnit{BaseUnit: types.SensorUnitType_DegreesC},
+		ReadingFactors:         types.ReadingFactors{M: 1},
+		IDStringBytes:          []byte(name),
+	}
+}
+
+// TestSDRCache verifies that a cached sensor scan reads the same sensors as
+// an uncached one, and that adding a record to the SDR repository makes the
+// cache read it again.
+func TestSDRCache(t *testing.T) {
+	const (
+		username = "ADMIN"
+		password = "ADMIN"
+	)
+	ctx := context.Background()
+
+	b := newTestBMC(t, clock.Real, username, password)
+	h := b.HAL().(*mock.HAL)
+	h.Sensors().(*mock.Sensors).Values = map[uint8]uint8{1: 25, 2: 30}
+	if err := h.Storage().SDR().Write(ctx, 1, testTemperatureSDR(1, "Inlet Temp").Pack(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	pc, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0})
+	if err != nil {
+		t.Fatalf("udp listen: %v", err)
+	}
+	t.Cleanup(func() { _ = pc.Close() })
+	addr := pc.LocalAddr().(*net.UDPAddr)
+	srv := server.NewServer(b, udp.Wrap(pc))
+	srvCtx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+	go func() {
+		_ = srv.Serve(srvCtx)
+	}()
+
+	c, err := NewClient(addr.IP.String(), addr.Port, username, password)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	cache := NewSDRCache()
+	c.WithInterface(InterfaceLanplus).WithTimeout(2 * time.Second).WithRetry(0).WithSDRCache(cache)
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close(context.Background()) })
+
+	sensors, err := c.GetSensors(ctx)
+	if err != nil {
+		t.Fatalf("GetSensors: %v", err)
+	}
+	if len(sensors) != 1 || sensors[0].Name != "Inlet Temp" || sensors[0].Value != 25 {
+		t.Fatalf("GetSensors = %v, want Inlet Temp at 25", sensors)
+	}
+	if cache.Len() != 1 {
+		t.Fatalf("cache.Len() = %d, want 1", cache.Len())
+	}
+
+	if _, err := c.AddSDR(ctx, testTemperatureSDR(2, "Exhaust Temp").Pack(0)); err != nil {
+		t.Fatalf("AddSDR: %v", err)
+	}
+	var names []string
+	for result := range c.GetSensorsStream(ctx) {
+		if result.Err != nil {
+			t.Fatalf("GetSensorsStream: %v", result.Err)
+		}
+		names = append(names, result.Ok.Name)
+	}
+	if len(names) != 2 || cache.Len() != 2 {
+		t.Fatalf("after AddSDR: sensors %v, cache.Len() = %d, want both sensors", names, cache.Len())
+	}
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestChannelAccessCodecRoundTrip(t *testing.T) {
+	setOrig := &SetChannelAccessRequest{
+		ChannelNumber:        0x01,
+		AccessOption:         uint8(types.ChannelAccessOption_Volatile),
+		DisablePEFAlerting:   true,
+		DisableUserLevelAuth: true,
+		AccessMode:           types.ChannelAccessMode_PrebootOnly,
+		PrivilegeOption:      uint8(types.ChannelAccessOption_NonVolatile),
+		MaxPrivilegeLevel:    0x03,
+	}
+	var set SetChannelAccessRequest
+	if err := set.Unpack(setOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if set != *setOrig {
+		t.Fatalf("set request mismatch: %+v vs %+v", setOrig, set)
+	}
+
+	getOrig := &GetChannelAccessRequest{ChannelNumber: 0x0e, AccessOption: types.ChannelAccessOption_NonVolatile}
+	var get GetChannelAccessRequest
+	if err := get.Unpack(getOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if get != *getOrig {
+		t.Fatalf("get request mismatch: %+v vs %+v", getOrig, get)
+	}
+
+	resOrig := &GetChannelAccessResponse{
+		PerMsgAuthDisabled: true,
+		AccessMode:         types.ChannelAccessMode_Disabled,
+		MaxPrivilegeLevel:  types.PrivilegeLevelOperator,
+	}
+	var res GetChannelAccessResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res != *resOrig {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestCommandEnablesCodecRoundTrip(t *testing.T) {
+	setOrig := &SetCommandEnablesRequest{
+		ChannelNumber:    0x0f,
+		CommandRangeMask: CommandRangeMask(CommandRangeMask80FF),
+		NetFn:            types.NetFnGroupExtensionRequest,
+		LUN:              2,
+		CodeForNetFn2C:   types.GroupExtensionDCMI,
+	}
+	setOrig.CommandsMaskBytes[3] = 0x5a
+	var set SetCommandEnablesRequest
+	if err := set.Unpack(setOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if set != *setOrig {
+		t.Fatalf("set request mismatch: %+v vs %+v", setOrig, set)
+	}
+
+	getOrig := &GetCommandEnablesRequest{
+		ChannelNumber:    0x01,
+		CommandRangeMask: CommandRangeMask(CommandRangeMask80FF),
+		NetFn:            types.NetFnOEMGroupRequest,
+		LUN:              1,
+		OEMIANA:          0x001b0f,
+	}
+	var get GetCommandEnablesRequest
+	if err := get.Unpack(getOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if get != *getOrig {
+		t.Fatalf("get request mismatch: %+v vs %+v", getOrig, get)
+	}
+
+	resOrig := &GetCommandEnablesResponse{CommandEnableMask: bytes.Repeat([]byte{0xa5}, 16)}
+	var res GetCommandEnablesResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(res.CommandEnableMask, resOrig.CommandEnableMask) {
+		t.Fatalf("response mismatch: % x", res.CommandEnableMask)
+	}
+
+	subOrig := &SetCommandSubfunctionEnablesRequest{
+		ChannelNumber:      0x0e,
+		NetFn:              types.NetFnChassisRequest,
+		Cmd:                0x01,
+		SubfunctionEnables: make([]bool, 64),
+	}
+	subOrig.SubfunctionEnables[0] = true
+	subOrig.SubfunctionEnables[40] = true
+	var sub SetCommandSubfunctionEnablesRequest
+	if err := sub.Unpack(subOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sub, *subOrig) {
+		t.Fatalf("sub-function request mismatch: %+v vs %+v", subOrig, sub)
+	}
+}
@@ -33,6 +33,25 @@ func (req *EnableMessageChannelReceiveRequest) Pack() []byte {
 	return out
 }
 
+func (req *EnableMessageChannelReceiveRequest) Unpack(msg []byte) error {
+	if len(msg) < 2 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
+	}
+
+	req.ChannelNumber = msg[0] & 0x0f
+	req.ChannelState = msg[1] & 0x03
+	return nil
+}
+
+func (res *EnableMessageChannelReceiveResponse) Pack() []byte {
+	out := make([]byte, 2)
+	types.PackUint8(res.ChannelNumber&0x0f, out, 0)
+	if res.ChannelEnabled {
+		types.PackUint8(0x01, out, 1)
+	}
+	return out
+}
+
 func (res *EnableMessageChannelReceiveResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
@@ -31,10 +31,40 @@ func (req *GetChannelAccessRequest) Pack() []byte {
 	return out
 }
 
+func (req *GetChannelAccessRequest) Unpack(msg []byte) error {
+	if len(msg) < 2 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
+	}
+
+	req.ChannelNumber = msg[0] & 0x0f
+	req.AccessOption = types.ChannelAccessOption(msg[1] >> 6)
+
+	return nil
+}
+
 func (req *GetChannelAccessRequest) Command() types.Command {
 	return types.CommandGetChannelAccess
 }
 
+func (res *GetChannelAccessResponse) Pack() []byte {
+	out := make([]byte, 2)
+
+	var b0 = uint8(res.AccessMode) & 0x07
+	if res.PEFAlertingDisabled {
+		b0 = types.SetBit5(b0)
+	}
+	if res.PerMsgAuthDisabled {
+		b0 = types.SetBit4(b0)
+	}
+	if res.UserLevelAuthDisabled {
+		b0 = types.SetBit3(b0)
+	}
+	types.PackUint8(b0, out, 0)
+	types.PackUint8(uint8(res.MaxPrivilegeLevel)&0x0f, out, 1)
+
+	return out
+}
+
 func (res *GetChannelAccessResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
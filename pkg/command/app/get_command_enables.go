@@ -29,7 +29,7 @@ func (req *GetCommandEnablesRequest) Pack() []byte {
 	out := make([]byte, 6)
 	types.PackUint8(req.ChannelNumber, out, 0)
 
-	netfn := uint8(req.NetFn) & (uint8(req.CommandRangeMask) << 6)
+	netfn := (uint8(req.NetFn) & 0x3f) | (uint8(req.CommandRangeMask) << 6)
 	types.PackUint8(netfn, out, 1)
 
 	types.PackUint8(req.LUN&0x03, out, 2)
@@ -47,6 +47,26 @@ func (req *GetCommandEnablesRequest) Pack() []byte {
 	return out[0:3]
 }
 
+func (req *GetCommandEnablesRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+	req.ChannelNumber = msg[0] & 0x0f
+	req.CommandRangeMask = CommandRangeMask(msg[1] >> 6)
+	req.NetFn = types.NetFn(msg[1] & 0x3f)
+	req.LUN = msg[2] & 0x03
+
+	var err error
+	req.CodeForNetFn2C, req.OEMIANA, err = unpackNetFnBodyCode(msg, 3, req.NetFn)
+	return err
+}
+
+func (res *GetCommandEnablesResponse) Pack() []byte {
+	out := make([]byte, 16)
+	copy(out, res.CommandEnableMask)
+	return out
+}
+
 func (res *GetCommandEnablesResponse) Unpack(msg []byte) error {
 	if len(msg) < 16 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 16)
@@ -44,6 +44,24 @@ func (req *GetCommandSubfunctionEnablesRequest) Pack() []byte {
 	return out[0:4]
 }
 
+func (req *GetCommandSubfunctionEnablesRequest) Unpack(msg []byte) error {
+	if len(msg) < 4 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
+	}
+	req.ChannelNumber = msg[0] & 0x0f
+	req.NetFn = types.NetFn(msg[1] & 0x3f)
+	req.LUN = msg[2] & 0x03
+	req.Cmd = msg[3]
+
+	var err error
+	req.CodeForNetFn2C, req.OEMIANA, err = unpackNetFnBodyCode(msg, 4, req.NetFn)
+	return err
+}
+
+func (res *GetCommandSubfunctionEnablesResponse) Pack() []byte {
+	return packSubfunctionBits(res.SubfunctionEnables)
+}
+
 func (res *GetCommandSubfunctionEnablesResponse) Unpack(msg []byte) error {
 	if len(msg) < 4 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
@@ -53,6 +53,29 @@ func (req *GetCommandSubfunctionSupportRequest) Pack() []byte {
 	return out[0:4]
 }
 
+func (req *GetCommandSubfunctionSupportRequest) Unpack(msg []byte) error {
+	if len(msg) < 4 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
+	}
+	req.ChannelNumber = msg[0] & 0x0f
+	req.NetFn = types.NetFn(msg[1] & 0x3f)
+	req.LUN = msg[2] & 0x03
+	req.Cmd = msg[3]
+
+	var err error
+	req.CodeForNetFn2C, req.OEMIANA, err = unpackNetFnBodyCode(msg, 4, req.NetFn)
+	return err
+}
+
+func (res *GetCommandSubfunctionSupportResponse) Pack() []byte {
+	out := make([]byte, 7)
+	out[0] = res.SpecificationType<<4 | res.ErrataVersion&0x0f
+	out[1] = res.SpecificationVersion
+	out[2] = res.SpecificationRevision
+	copy(out[3:], res.SupportMask)
+	return out
+}
+
 func (res *GetCommandSubfunctionSupportResponse) Unpack(msg []byte) error {
 	if len(msg) < 3 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
@@ -54,6 +54,45 @@ func (req *GetCommandSupportRequest) Pack() []byte {
 	return out[0:3]
 }
 
+func (req *GetCommandSupportRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+	req.ChannelNumber = msg[0] & 0x0f
+	req.CommandRangeMask = CommandRangeMask(msg[1] >> 6)
+	req.NetFn = types.NetFn(msg[1] & 0x3f)
+	req.LUN = msg[2] & 0x03
+
+	var err error
+	req.CodeForNetFn2C, req.OEMIANA, err = unpackNetFnBodyCode(msg, 3, req.NetFn)
+	return err
+}
+
+func (res *GetCommandSupportResponse) Pack() []byte {
+	out := make([]byte, 16)
+	copy(out, res.CommandSupportMask)
+	return out
+}
+
+// unpackNetFnBodyCode unpacks, at off, the defining body code of a Group
+// Extension (2Ch) request or the IANA of an OEM/Group (2Eh) request, which
+// the firmware firewall commands carry after the LUN or command byte.
+func unpackNetFnBodyCode(msg []byte, off int, netFn types.NetFn) (code uint8, iana uint32, err error) {
+	switch uint8(netFn) {
+	case 0x2c:
+		if len(msg) < off+1 {
+			return 0, 0, types.ErrUnpackedDataTooShortWith(len(msg), off+1)
+		}
+		code = msg[off]
+	case 0x2e:
+		if len(msg) < off+3 {
+			return 0, 0, types.ErrUnpackedDataTooShortWith(len(msg), off+3)
+		}
+		iana, _, _ = types.UnpackUint24L(msg, off)
+	}
+	return code, iana, nil
+}
+
 func (res *GetCommandSupportResponse) Unpack(msg []byte) error {
 	if len(msg) < 16 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 16)
@@ -47,6 +47,26 @@ func (req *GetConfigurableCommandsRequest) Pack() []byte {
 	return out[0:3]
 }
 
+func (req *GetConfigurableCommandsRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+	req.ChannelNumber = msg[0] & 0x0f
+	req.CommandRangeMask = CommandRangeMask(msg[1] >> 6)
+	req.NetFn = types.NetFn(msg[1] & 0x3f)
+	req.LUN = msg[2] & 0x03
+
+	var err error
+	req.CodeForNetFn2C, req.OEMIANA, err = unpackNetFnBodyCode(msg, 3, req.NetFn)
+	return err
+}
+
+func (res *GetConfigurableCommandsResponse) Pack() []byte {
+	out := make([]byte, 16)
+	copy(out, res.CommandSupportMask)
+	return out
+}
+
 func (res *GetConfigurableCommandsResponse) Unpack(msg []byte) error {
 	if len(msg) < 16 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 16)
@@ -51,6 +51,42 @@ func (req *GetConfigurableCommandSubfunctionsRequest) Pack() []byte {
 	return out[0:4]
 }
 
+func (req *GetConfigurableCommandSubfunctionsRequest) Unpack(msg []byte) error {
+	if len(msg) < 4 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
+	}
+	req.ChannelNumber = msg[0] & 0x0f
+	req.NetFn = types.NetFn(msg[1] & 0x3f)
+	req.LUN = msg[2] & 0x03
+	req.Cmd = msg[3]
+
+	var err error
+	req.CodeForNetFn2C, req.OEMIANA, err = unpackNetFnBodyCode(msg, 4, req.NetFn)
+	return err
+}
+
+func (res *GetConfigurableCommandSubfunctionsResponse) Pack() []byte {
+	return packSubfunctionBits(res.SubfunctionsSupport)
+}
+
+// packSubfunctionBits packs one bit per sub-function, LS bit first: 4 bytes
+// for sub-functions 0-31, 8 bytes when any of 32-63 is given.
+func packSubfunctionBits(bits []bool) []byte {
+	if len(bits) > 64 {
+		bits = bits[:64]
+	}
+	out := make([]byte, 4)
+	if len(bits) > 32 {
+		out = make([]byte, 8)
+	}
+	for i, set := range bits {
+		if set {
+			out[i/8] |= 1 << (i % 8)
+		}
+	}
+	return out
+}
+
 func (res *GetConfigurableCommandSubfunctionsResponse) Unpack(msg []byte) error {
 	if len(msg) < 4 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
@@ -43,6 +43,22 @@ func (req *GetNetFnSupportRequest) Pack() []byte {
 	return []byte{req.ChannelNumber}
 }
 
+func (req *GetNetFnSupportRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	req.ChannelNumber = msg[0] & 0x0f
+	return nil
+}
+
+func (res *GetNetFnSupportResponse) Pack() []byte {
+	out := make([]byte, 17)
+	out[0] = uint8(res.LUN3Support&0x03)<<6 | uint8(res.LUN2Support&0x03)<<4 |
+		uint8(res.LUN1Support&0x03)<<2 | uint8(res.LUN0Support&0x03)
+	copy(out[1:], res.NetFnPairsSupport)
+	return out
+}
+
 func (res *GetNetFnSupportResponse) Unpack(msg []byte) error {
 	if len(msg) < 17 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 17)
@@ -33,10 +33,30 @@ func (req *GetSystemInfoParamRequest) Pack() []byte {
 	return out
 }
 
+func (req *GetSystemInfoParamRequest) Unpack(msg []byte) error {
+	if len(msg) < 4 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
+	}
+
+	req.GetParamRevisionOnly = types.IsBit7Set(msg[0])
+	req.ParamSelector = types.SystemInfoParamSelector(msg[1])
+	req.SetSelector = msg[2]
+	req.BlockSelector = msg[3]
+
+	return nil
+}
+
 func (req *GetSystemInfoParamRequest) Command() types.Command {
 	return types.CommandGetSystemInfoParam
 }
 
+func (res *GetSystemInfoParamResponse) Pack() []byte {
+	out := make([]byte, 1+len(res.ParamData))
+	types.PackUint8(res.ParamRevision, out, 0)
+	types.PackBytes(res.ParamData, out, 1)
+	return out
+}
+
 func (res *GetSystemInfoParamResponse) Unpack(msg []byte) error {
 	if len(msg) < 1 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
@@ -54,6 +54,29 @@ func (res *GetWatchdogTimerResponse) PresentCountdownSec() float64 {
 	return float64(res.PresentCountdown) / WatchdogCountdownUnitsPerSecond
 }
 
+func (res *GetWatchdogTimerResponse) Pack() []byte {
+	out := make([]byte, 8)
+
+	b0 := uint8(res.TimerUse) & 0x07
+	if res.DontLog {
+		b0 = types.SetBit7(b0)
+	}
+	if res.TimerIsStarted {
+		b0 = types.SetBit6(b0)
+	}
+	types.PackUint8(b0, out, 0)
+
+	b1 := uint8(res.TimeoutAction) & 0x07
+	b1 |= (uint8(res.PreTimeoutInterrupt) & 0x07) << 4
+	types.PackUint8(b1, out, 1)
+
+	types.PackUint8(res.PreTimeoutIntervalSec, out, 2)
+	types.PackUint8(res.ExpirationFlags, out, 3)
+	types.PackUint16L(res.InitialCountdown, out, 4)
+	types.PackUint16L(res.PresentCountdown, out, 6)
+	return out
+}
+
 func (res *GetWatchdogTimerResponse) Unpack(msg []byte) error {
 	if len(msg) < 8 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 8)
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMessageBridgingCodecRoundTrip(t *testing.T) {
+	sendOrig := &SendMessageRequest{
+		TrackMask:     0x01,
+		Authenticated: true,
+		ChannelNumber: 0x0f,
+		MessageData:   []byte{0x81, 0x18, 0x67, 0x20, 0x04, 0x01, 0xdb},
+	}
+	var send SendMessageRequest
+	if err := send.Unpack(sendOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if send.TrackMask != sendOrig.TrackMask || send.Authenticated != sendOrig.Authenticated || send.Encrypted ||
+		send.ChannelNumber != sendOrig.ChannelNumber || !bytes.Equal(send.MessageData, sendOrig.MessageData) {
+		t.Fatalf("send message mismatch: %+v vs %+v", sendOrig, send)
+	}
+
+	enableOrig := &EnableMessageChannelReceiveRequest{ChannelNumber: 0x01, ChannelState: 0x02}
+	var enable EnableMessageChannelReceiveRequest
+	if err := enable.Unpack(enableOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if enable != *enableOrig {
+		t.Fatalf("enable request mismatch: %+v vs %+v", enableOrig, enable)
+	}
+	enabledOrig := &EnableMessageChannelReceiveResponse{ChannelNumber: 0x01, ChannelEnabled: true}
+	var enabled EnableMessageChannelReceiveResponse
+	if err := enabled.Unpack(enabledOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if enabled != *enabledOrig {
+		t.Fatalf("enable response mismatch: %+v vs %+v", enabledOrig, enabled)
+	}
+}
@@ -52,6 +52,24 @@ func (req *SendMessageRequest) Pack() []byte {
 	return out
 }
 
+func (req *SendMessageRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+
+	b, _, _ := types.UnpackUint8(msg, 0)
+	req.ChannelNumber = b & 0x0f
+	req.Authenticated = types.IsBit4Set(b)
+	req.Encrypted = types.IsBit5Set(b)
+	req.TrackMask = b >> 6
+	req.MessageData, _, _ = types.UnpackBytes(msg, 1, len(msg)-1)
+	return nil
+}
+
+func (res *SendMessageResponse) Pack() []byte {
+	return append([]byte{}, res.Data...)
+}
+
 func (res *SendMessageResponse) Unpack(msg []byte) error {
 	res.Data, _, _ = types.UnpackBytes(msg, 0, len(msg))
 	return nil
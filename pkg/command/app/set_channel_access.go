@@ -50,6 +50,26 @@ func (req *SetChannelAccessRequest) Pack() []byte {
 	return out
 }
 
+func (req *SetChannelAccessRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+
+	req.ChannelNumber = msg[0] & 0x0f
+
+	b := msg[1]
+	req.AccessOption = b >> 6
+	req.DisablePEFAlerting = types.IsBit5Set(b)
+	req.DisablePerMsgAuth = types.IsBit4Set(b)
+	req.DisableUserLevelAuth = types.IsBit3Set(b)
+	req.AccessMode = types.ChannelAccessMode(b & 0x07)
+
+	req.PrivilegeOption = msg[2] >> 6
+	req.MaxPrivilegeLevel = msg[2] & 0x0f
+
+	return nil
+}
+
 func (req *SetChannelAccessRequest) Command() types.Command {
 	return types.CommandSetChannelAccess
 }
@@ -47,6 +47,21 @@ func (req *SetCommandEnablesRequest) Pack() []byte {
 	return out[0:20]
 }
 
+func (req *SetCommandEnablesRequest) Unpack(msg []byte) error {
+	if len(msg) < 19 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 19)
+	}
+	req.ChannelNumber = msg[0] & 0x0f
+	req.CommandRangeMask = CommandRangeMask(msg[1] >> 6)
+	req.NetFn = types.NetFn(msg[1] & 0x3f)
+	req.LUN = msg[2] & 0x03
+	copy(req.CommandsMaskBytes[:], msg[3:19])
+
+	var err error
+	req.CodeForNetFn2C, req.OEMIANA, err = unpackNetFnBodyCode(msg, 19, req.NetFn)
+	return err
+}
+
 func (res *SetCommandEnablesResponse) Unpack(msg []byte) error {
 	return nil
 }
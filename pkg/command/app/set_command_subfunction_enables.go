@@ -27,8 +27,7 @@ func (req *SetCommandSubfunctionEnablesRequest) Command() types.Command {
 }
 
 func (req *SetCommandSubfunctionEnablesRequest) Pack() []byte {
-	var maxLength int = 15
-	out := make([]byte, maxLength)
+	out := make([]byte, 7)
 
 	out[0] = req.ChannelNumber
 	out[1] = (uint8(req.NetFn) & 0x3f) | (uint8(req.CommandRangeMask) << 6)
@@ -47,29 +46,47 @@ func (req *SetCommandSubfunctionEnablesRequest) Pack() []byte {
 		startIndexOfEnables = 7
 	}
 
-	enables := req.SubfunctionEnables
-	if len(req.SubfunctionEnables) > 64 {
-		enables = enables[:64]
+	// Sub-functions 0-31 pack into 4 bytes, 32-63 into 4 more.
+	return append(out[0:startIndexOfEnables], packSubfunctionBits(req.SubfunctionEnables)...)
+}
+
+func (req *SetCommandSubfunctionEnablesRequest) Unpack(msg []byte) error {
+	if len(msg) < 4 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
 	}
-	// Every 8 elements from the enables slice pack into 1 byte.
-	enableBytesLength := len(enables)/8 + 1
-
-	for i := 0; i < enableBytesLength; i++ {
-		var b uint8
-		b = types.SetOrClearBit0(b, req.SubfunctionEnables[i*8+0])
-		b = types.SetOrClearBit1(b, req.SubfunctionEnables[i*8+1])
-		b = types.SetOrClearBit2(b, req.SubfunctionEnables[i*8+2])
-		b = types.SetOrClearBit3(b, req.SubfunctionEnables[i*8+3])
-		b = types.SetOrClearBit4(b, req.SubfunctionEnables[i*8+4])
-		b = types.SetOrClearBit5(b, req.SubfunctionEnables[i*8+5])
-		b = types.SetOrClearBit6(b, req.SubfunctionEnables[i*8+6])
-		b = types.SetOrClearBit7(b, req.SubfunctionEnables[i*8+7])
-
-		out[startIndexOfEnables+i] = b
+	req.ChannelNumber = msg[0] & 0x0f
+	req.CommandRangeMask = CommandRangeMask(msg[1] >> 6)
+	req.NetFn = types.NetFn(msg[1] & 0x3f)
+	req.LUN = msg[2] & 0x03
+	req.Cmd = msg[3]
+
+	var err error
+	req.CodeForNetFn2C, req.OEMIANA, err = unpackNetFnBodyCode(msg, 4, req.NetFn)
+	if err != nil {
+		return err
 	}
 
-	return out[0 : startIndexOfEnables+enableBytesLength]
-
+	off := 4
+	switch uint8(req.NetFn) {
+	case 0x2c:
+		off = 5
+	case 0x2e:
+		off = 7
+	}
+	enables := msg[off:]
+	switch {
+	case len(enables) >= 8:
+		enables = enables[:8]
+	case len(enables) >= 4:
+		enables = enables[:4]
+	default:
+		return types.ErrUnpackedDataTooShortWith(len(msg), off+4)
+	}
+	req.SubfunctionEnables = make([]bool, 8*len(enables))
+	for i := range req.SubfunctionEnables {
+		req.SubfunctionEnables[i] = enables[i/8]&(1<<(i%8)) != 0
+	}
+	return nil
 }
 
 func (res *SetCommandSubfunctionEnablesResponse) Unpack(msg []byte) error {
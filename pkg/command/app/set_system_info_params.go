@@ -20,6 +20,16 @@ func (req *SetSystemInfoParamRequest) Pack() []byte {
 	return out
 }
 
+func (req *SetSystemInfoParamRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+
+	req.ParamSelector = types.SystemInfoParamSelector(msg[0])
+	req.ParamData = append([]byte(nil), msg[1:]...)
+	return nil
+}
+
 func (req *SetSystemInfoParamRequest) Command() types.Command {
 	return types.CommandSetSystemInfoParam
 }
@@ -47,6 +47,24 @@ func (req *SetWatchdogTimerRequest) Pack() []byte {
 	return out
 }
 
+func (req *SetWatchdogTimerRequest) Unpack(msg []byte) error {
+	if len(msg) < 6 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 6)
+	}
+
+	req.DontLog = types.IsBit7Set(msg[0])
+	req.DontStopTimer = types.IsBit6Set(msg[0])
+	req.TimerUse = TimerUse(0x07 & msg[0])
+
+	req.PreTimeoutInterrupt = PreTimeoutInterrupt((0x70 & msg[1]) >> 4)
+	req.TimeoutAction = TimeoutAction(0x07 & msg[1])
+
+	req.PreTimeoutIntervalSec = msg[2]
+	req.ExpirationFlags = msg[3]
+	req.InitialCountdown, _, _ = types.UnpackUint16L(msg, 4)
+	return nil
+}
+
 func (req *SetWatchdogTimerRequest) Command() types.Command {
 	return types.CommandSetWatchdogTimer
 }
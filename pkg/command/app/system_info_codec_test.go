@@ -0,0 +1,42 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestSystemInfoParamCodecRoundTrip(t *testing.T) {
+	getOrig := &GetSystemInfoParamRequest{
+		GetParamRevisionOnly: true,
+		ParamSelector:        types.SystemInfoParamSelector_OSName,
+		SetSelector:          2,
+	}
+	var get GetSystemInfoParamRequest
+	if err := get.Unpack(getOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if get != *getOrig {
+		t.Fatalf("get request mismatch: %+v vs %+v", getOrig, get)
+	}
+
+	resOrig := &GetSystemInfoParamResponse{ParamRevision: 0x11, ParamData: []byte{0x00, 0x00, 0x04, 'l', 'i', 'n', 'x'}}
+	var res GetSystemInfoParamResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res.ParamRevision != resOrig.ParamRevision || !bytes.Equal(res.ParamData, resOrig.ParamData) {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+
+	name := &types.SystemInfoParam_SystemName{SetSelector: 1, BlockData: []byte("-rack-7")}
+	setOrig := &SetSystemInfoParamRequest{ParamSelector: types.SystemInfoParamSelector_SystemName, ParamData: name.Pack()}
+	var set SetSystemInfoParamRequest
+	if err := set.Unpack(setOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if set.ParamSelector != setOrig.ParamSelector || !bytes.Equal(set.ParamData, setOrig.ParamData) {
+		t.Fatalf("set request mismatch: %+v vs %+v", setOrig, set)
+	}
+}
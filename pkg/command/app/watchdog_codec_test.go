@@ -0,0 +1,46 @@
+package app
+
+import "testing"
+
+func TestSetWatchdogTimerCodecRoundTrip(t *testing.T) {
+	reqOrig := &SetWatchdogTimerRequest{
+		DontLog:               true,
+		DontStopTimer:         true,
+		TimerUse:              TimerUseSMSOS,
+		PreTimeoutInterrupt:   PreTimeoutInterruptNMI,
+		TimeoutAction:         TimeoutActionPowerCycle,
+		PreTimeoutIntervalSec: 10,
+		ExpirationFlags:       0x10,
+		InitialCountdown:      600,
+	}
+	var req SetWatchdogTimerRequest
+	if err := req.Unpack(reqOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if req != *reqOrig {
+		t.Fatalf("request mismatch: %+v vs %+v", reqOrig, req)
+	}
+	if err := req.Unpack([]byte{0x44, 0x01, 0x00, 0x00, 0x58}); err == nil {
+		t.Fatal("Unpack of a 5 byte request succeeded, want error")
+	}
+}
+
+func TestGetWatchdogTimerCodecRoundTrip(t *testing.T) {
+	resOrig := &GetWatchdogTimerResponse{
+		TimerIsStarted:        true,
+		TimerUse:              TimerUseOSLoad,
+		PreTimeoutInterrupt:   PreTimeoutInterruptSMI,
+		TimeoutAction:         TimeoutActionHardReset,
+		PreTimeoutIntervalSec: 5,
+		ExpirationFlags:       0x08,
+		InitialCountdown:      1200,
+		PresentCountdown:      875,
+	}
+	var res GetWatchdogTimerResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res != *resOrig {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+}
@@ -112,3 +112,52 @@ func TestChassisControlRequest_Unpack(t *testing.T) {
 	// Touch ipmi to keep the import meaningful for future helper-based cases.
 	_ = types.CommandChassisControl
 }
+
+func TestChassisServerCodecsRoundTrip(t *testing.T) {
+	policy := &SetPowerRestorePolicyRequest{PowerRestorePolicy: PowerRestorePolicyPrevious}
+	var gotPolicy SetPowerRestorePolicyRequest
+	if err := gotPolicy.Unpack(policy.Pack()); err != nil || gotPolicy != *policy {
+		t.Fatalf("restore policy request: %+v %v", gotPolicy, err)
+	}
+	supported := &SetPowerRestorePolicyResponse{SupportPolicyAlwaysOff: true, SupportPolicyAlwaysOn: true}
+	var gotSupported SetPowerRestorePolicyResponse
+	if err := gotSupported.Unpack(supported.Pack()); err != nil || gotSupported != *supported {
+		t.Fatalf("restore policy response: %+v %v", gotSupported, err)
+	}
+
+	cause := &GetSystemRestartCauseResponse{SystemRestartCause: 0x04, ChannelNumber: 1}
+	var gotCause GetSystemRestartCauseResponse
+	if err := gotCause.Unpack(cause.Pack()); err != nil || gotCause != *cause {
+		t.Fatalf("restart cause: %+v %v", gotCause, err)
+	}
+
+	poh := &GetPOHCounterResponse{MinutesPerCount: 1, CounterReading: 0x01020304}
+	var gotPOH GetPOHCounterResponse
+	if err := gotPOH.Unpack(poh.Pack()); err != nil || gotPOH != *poh {
+		t.Fatalf("POH counter: %+v %v", gotPOH, err)
+	}
+
+	panel := &SetFrontPanelEnablesRequest{DisableResetButton: true, DisableSleepButton: true}
+	var gotPanel SetFrontPanelEnablesRequest
+	if err := gotPanel.Unpack(panel.Pack()); err != nil || gotPanel != *panel {
+		t.Fatalf("front panel enables: %+v %v", gotPanel, err)
+	}
+
+	interval := &SetPowerCycleIntervalRequest{IntervalInSec: 9}
+	var gotInterval SetPowerCycleIntervalRequest
+	if err := gotInterval.Unpack(interval.Pack()); err != nil || gotInterval != *interval {
+		t.Fatalf("power cycle interval: %+v %v", gotInterval, err)
+	}
+
+	set := &SetChassisCapabilitiesRequest{ProvideIntrusionSensor: true, FRUDeviceAddress: 0x20, SDRDeviceAddress: 0x20, SELDeviceAddress: 0x22, SystemManagementDeviceAddress: 0x20}
+	var gotSet SetChassisCapabilitiesRequest
+	if err := gotSet.Unpack(set.Pack()); err != nil || gotSet != *set {
+		t.Fatalf("set capabilities: %+v %v", gotSet, err)
+	}
+
+	caps := &GetChassisCapabilitiesResponse{ProvideDiagnosticInterrupt: true, FRUDeviceAddress: 0x20, SDRDeviceAddress: 0x20, SELDeviceAddress: 0x20, SystemManagementDeviceAddress: 0x20, BridgeDeviceAddress: 0x24}
+	var gotCaps GetChassisCapabilitiesResponse
+	if err := gotCaps.Unpack(caps.Pack()); err != nil || gotCaps != *caps {
+		t.Fatalf("capabilities: %+v %v", gotCaps, err)
+	}
+}
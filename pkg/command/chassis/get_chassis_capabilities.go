@@ -36,6 +36,22 @@ func (req *GetChassisCapabilitiesRequest) Command() types.Command {
 	return types.CommandGetChassisCapabilities
 }
 
+// Pack serialises the capabilities (§28.1), the inverse of
+// [GetChassisCapabilitiesResponse.Unpack]. The optional bridge device
+// address is emitted only when set.
+func (res *GetChassisCapabilitiesResponse) Pack() []byte {
+	var b uint8
+	b = types.SetOrClearBit3(b, res.ProvidePowerInterlock)
+	b = types.SetOrClearBit2(b, res.ProvideDiagnosticInterrupt)
+	b = types.SetOrClearBit1(b, res.ProvideFrontPanelLockout)
+	b = types.SetOrClearBit0(b, res.ProvideIntrusionSensor)
+	out := []byte{b, res.FRUDeviceAddress, res.SDRDeviceAddress, res.SELDeviceAddress, res.SystemManagementDeviceAddress}
+	if res.BridgeDeviceAddress != 0 {
+		out = append(out, res.BridgeDeviceAddress)
+	}
+	return out
+}
+
 func (res *GetChassisCapabilitiesResponse) Unpack(msg []byte) error {
 	if len(msg) < 5 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 5)
@@ -77,6 +77,7 @@ const (
 	PowerRestorePolicyAlwaysOff PowerRestorePolicy = 0 // 保持下电（关机）
 	PowerRestorePolicyPrevious  PowerRestorePolicy = 1 // 与之前保持一致（恢复断电前状态）
 	PowerRestorePolicyAlwaysOn  PowerRestorePolicy = 2 // 保持上电（开机）
+	PowerRestorePolicyNoChange  PowerRestorePolicy = 3 // Set Power Restore Policy only: report the supported policies
 )
 
 var SupportedPowerRestorePolicies = []string{
@@ -28,6 +28,15 @@ func (req *GetPOHCounterRequest) Pack() []byte {
 	return []byte{}
 }
 
+// Pack serialises the counter (§28.14), the inverse of
+// [GetPOHCounterResponse.Unpack].
+func (res *GetPOHCounterResponse) Pack() []byte {
+	out := make([]byte, 5)
+	types.PackUint8(res.MinutesPerCount, out, 0)
+	types.PackUint32L(res.CounterReading, out, 1)
+	return out
+}
+
 func (res *GetPOHCounterResponse) Unpack(msg []byte) error {
 	if len(msg) < 5 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 5)
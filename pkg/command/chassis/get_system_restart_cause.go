@@ -49,6 +49,12 @@ func (req *GetSystemRestartCauseRequest) Command() types.Command {
 	return types.CommandGetSystemRestartCause
 }
 
+// Pack serialises the restart cause (§28.11), the inverse of
+// [GetSystemRestartCauseResponse.Unpack].
+func (res *GetSystemRestartCauseResponse) Pack() []byte {
+	return []byte{uint8(res.SystemRestartCause) & 0x0f, res.ChannelNumber}
+}
+
 func (res *GetSystemRestartCauseResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
@@ -38,9 +38,30 @@ func (req *SetChassisCapabilitiesRequest) Pack() []byte {
 	types.PackUint8(req.SDRDeviceAddress, out, 2)
 	types.PackUint8(req.SELDeviceAddress, out, 3)
 	types.PackUint8(req.SystemManagementDeviceAddress, out, 4)
+	if req.BridgeDeviceAddress != 0 {
+		out = append(out, req.BridgeDeviceAddress)
+	}
 	return out
 }
 
+// Unpack parses a Set Chassis Capabilities request body (§28.7). The bridge
+// device address byte is optional.
+func (req *SetChassisCapabilitiesRequest) Unpack(msg []byte) error {
+	if len(msg) < 5 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 5)
+	}
+	req.ProvideFrontPanelLockout = types.IsBit1Set(msg[0])
+	req.ProvideIntrusionSensor = types.IsBit0Set(msg[0])
+	req.FRUDeviceAddress = msg[1]
+	req.SDRDeviceAddress = msg[2]
+	req.SELDeviceAddress = msg[3]
+	req.SystemManagementDeviceAddress = msg[4]
+	if len(msg) >= 6 {
+		req.BridgeDeviceAddress = msg[5]
+	}
+	return nil
+}
+
 func (req *SetChassisCapabilitiesRequest) Command() types.Command {
 	return types.CommandSetChassisCapabilities
 }
@@ -36,6 +36,19 @@ func (req *SetFrontPanelEnablesRequest) Pack() []byte {
 	return out
 }
 
+// Unpack parses a Set Front Panel Enables request body (§28.6), the inverse
+// of [SetFrontPanelEnablesRequest.Pack].
+func (req *SetFrontPanelEnablesRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	req.DisableSleepButton = types.IsBit3Set(msg[0])
+	req.DisableDiagnosticButton = types.IsBit2Set(msg[0])
+	req.DisableResetButton = types.IsBit1Set(msg[0])
+	req.DisablePoweroffButton = types.IsBit0Set(msg[0])
+	return nil
+}
+
 func (req *SetFrontPanelEnablesRequest) Command() types.Command {
 	return types.CommandSetFrontPanelEnables
 }
@@ -19,6 +19,15 @@ func (req *SetPowerCycleIntervalRequest) Pack() []byte {
 	return out
 }
 
+// Unpack parses a Set Power Cycle Interval request body (§28.9).
+func (req *SetPowerCycleIntervalRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	req.IntervalInSec = msg[0]
+	return nil
+}
+
 func (req *SetPowerCycleIntervalRequest) Command() types.Command {
 	return types.CommandSetPowerCycleInterval
 }
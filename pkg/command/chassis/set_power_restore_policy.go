@@ -23,10 +23,30 @@ func (req *SetPowerRestorePolicyRequest) Pack() []byte {
 	return out
 }
 
+// Unpack parses a Set Power Restore Policy request body (§28.8): the policy
+// in bits [2:0]; 011b only queries the supported policies.
+func (req *SetPowerRestorePolicyRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	req.PowerRestorePolicy = PowerRestorePolicy(msg[0] & 0x07)
+	return nil
+}
+
 func (req *SetPowerRestorePolicyRequest) Command() types.Command {
 	return types.CommandSetPowerRestorePolicy
 }
 
+// Pack serialises the supported policy bitmask, the inverse of
+// [SetPowerRestorePolicyResponse.Unpack].
+func (res *SetPowerRestorePolicyResponse) Pack() []byte {
+	var b uint8
+	b = types.SetOrClearBit0(b, res.SupportPolicyAlwaysOff)
+	b = types.SetOrClearBit1(b, res.SupportPolicyPrevious)
+	b = types.SetOrClearBit2(b, res.SupportPolicyAlwaysOn)
+	return []byte{b}
+}
+
 func (res *SetPowerRestorePolicyResponse) Unpack(msg []byte) error {
 	if len(msg) < 1 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
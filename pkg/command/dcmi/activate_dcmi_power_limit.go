@@ -21,10 +21,24 @@ func (req *ActivateDCMIPowerLimitRequest) Pack() []byte {
 	return []byte{types.GroupExtensionDCMI, activate, 0x00, 0x00}
 }
 
+func (req *ActivateDCMIPowerLimitRequest) Unpack(msg []byte) error {
+	if len(msg) < 2 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
+	}
+
+	req.Activate = msg[1] == 0x01
+
+	return nil
+}
+
 func (req *ActivateDCMIPowerLimitRequest) Command() types.Command {
 	return types.CommandActivateDCMIPowerLimit
 }
 
+func (res *ActivateDCMIPowerLimitResponse) Pack() []byte {
+	return []byte{types.GroupExtensionDCMI}
+}
+
 func (res *ActivateDCMIPowerLimitResponse) Unpack(msg []byte) error {
 	if len(msg) < 1 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
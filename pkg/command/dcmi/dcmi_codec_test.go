@@ -0,0 +1,127 @@
+package dcmi
+
+import (
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestDCMIPowerReadingCodecRoundTrip(t *testing.T) {
+	resOrig := &GetDCMIPowerReadingResponse{
+		CurrentPower:           120,
+		MinimumPower:           90,
+		MaximumPower:           310,
+		AveragePower:           140,
+		Timestamp:              1_700_000_000,
+		ReportingPeriod:        60_000,
+		PowerMeasurementActive: true,
+	}
+	var res GetDCMIPowerReadingResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res != *resOrig {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+}
+
+func TestDCMIPowerLimitCodecRoundTrip(t *testing.T) {
+	setOrig := &SetDCMIPowerLimitRequest{
+		ExceptionAction:             types.DCMIExceptionAction_PowerOffAndLogSEL,
+		PowerLimitRequested:         450,
+		CorrectionTimeLimitMilliSec: 6000,
+		StatisticsSamplingPeriodSec: 30,
+	}
+	var set SetDCMIPowerLimitRequest
+	if err := set.Unpack(setOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if set != *setOrig {
+		t.Fatalf("set request mismatch: %+v vs %+v", setOrig, set)
+	}
+
+	resOrig := &GetDCMIPowerLimitResponse{
+		ExceptionAction:             setOrig.ExceptionAction,
+		PowerLimitRequested:         setOrig.PowerLimitRequested,
+		CorrectionTimeLimitMilliSec: setOrig.CorrectionTimeLimitMilliSec,
+		StatisticsSamplingPeriodSec: setOrig.StatisticsSamplingPeriodSec,
+	}
+	var res GetDCMIPowerLimitResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res != *resOrig {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+}
+
+func TestDCMIThermalLimitCodecRoundTrip(t *testing.T) {
+	setOrig := &SetDCMIThermalLimitRequest{
+		EntityID:                   0x37,
+		EntityInstance:             1,
+		ExceptionAction_LogSELOnly: true,
+		TemperatureLimit:           45,
+		ExceptionTimeSec:           20,
+	}
+	var set SetDCMIThermalLimitRequest
+	if err := set.Unpack(setOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if set != *setOrig {
+		t.Fatalf("set request mismatch: %+v vs %+v", setOrig, set)
+	}
+
+	resOrig := &GetDCMIThermalLimitResponse{
+		ExceptionAction_LogSELOnly: true,
+		TemperatureLimit:           45,
+		ExceptionTimeSec:           20,
+	}
+	var res GetDCMIThermalLimitResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res != *resOrig {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+}
+
+func TestDCMITemperatureReadingsCodecSignMagnitude(t *testing.T) {
+	resOrig := &GetDCMITemperatureReadingsResponse{
+		TotalEntityInstances: 2,
+		TemperatureReadings: []DCMITemperatureReading{
+			{TemperatureReading: 24, EntityInstance: 1},
+			{TemperatureReading: -5, EntityInstance: 2},
+		},
+	}
+	msg := resOrig.Pack()
+	if msg[5] != 0x85 {
+		t.Fatalf("-5 degrees: want 0x85, got %#02x", msg[5])
+	}
+	var res GetDCMITemperatureReadingsResponse
+	if err := res.Unpack(msg); err != nil {
+		t.Fatal(err)
+	}
+	if res.TemperatureReadingsCount != 2 || res.TemperatureReadings[0].TemperatureReading != 24 || res.TemperatureReadings[1].TemperatureReading != -5 {
+		t.Fatalf("readings: %+v", res)
+	}
+}
+
+func TestDCMIStringCodecRoundTrip(t *testing.T) {
+	setOrig := &SetDCMIMgmtControllerIdentifierRequest{Offset: 4, WriteBytes: 5, IDStr: []byte("bmc-1")}
+	var set SetDCMIMgmtControllerIdentifierRequest
+	if err := set.Unpack(setOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if set.Offset != 4 || set.WriteBytes != 5 || string(set.IDStr) != "bmc-1" {
+		t.Fatalf("set request mismatch: %+v", set)
+	}
+
+	resOrig := &GetDCMIAssetTagResponse{TotalLength: 20, AssetTag: []byte("rack-7-slot-12")}
+	var res GetDCMIAssetTagResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalLength != 20 || string(res.AssetTag) != "rack-7-slot-12" {
+		t.Fatalf("response mismatch: %+v", res)
+	}
+}
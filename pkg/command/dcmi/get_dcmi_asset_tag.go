@@ -17,6 +17,8 @@ import (
 // [DCMI specification v1.5]: https://www.intel.com/content/dam/www/public/us/en/documents/technical-specifications/dcmi-v1-5-rev-spec.pdf
 type GetDCMIAssetTagRequest struct {
 	Offset uint8
+	// Number of bytes to read (16 bytes maximum); 0 reads the maximum.
+	ReadBytes uint8
 }
 
 type GetDCMIAssetTagResponse struct {
@@ -30,13 +32,35 @@ func (req *GetDCMIAssetTagRequest) Pack() []byte {
 	// Number of bytes to read (16 bytes maximum)
 	// using the fixed (maximum) value is OK here.
 	var readBytes = uint8(0x10)
+	if req.ReadBytes != 0 {
+		readBytes = req.ReadBytes
+	}
 	return []byte{types.GroupExtensionDCMI, req.Offset, readBytes}
 }
 
+func (req *GetDCMIAssetTagRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+
+	req.Offset = msg[1]
+	req.ReadBytes = msg[2]
+
+	return nil
+}
+
 func (req *GetDCMIAssetTagRequest) Command() types.Command {
 	return types.CommandGetDCMIAssetTag
 }
 
+func (res *GetDCMIAssetTagResponse) Pack() []byte {
+	out := make([]byte, 2+len(res.AssetTag))
+	out[0] = types.GroupExtensionDCMI
+	out[1] = res.TotalLength
+	copy(out[2:], res.AssetTag)
+	return out
+}
+
 func (res *GetDCMIAssetTagResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
@@ -27,10 +27,33 @@ func (req *GetDCMIConfigParamRequest) Pack() []byte {
 	return out
 }
 
+func (req *GetDCMIConfigParamRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+
+	req.ParamSelector = types.DCMIConfigParamSelector(msg[1])
+	req.SetSelector = msg[2]
+
+	return nil
+}
+
 func (req *GetDCMIConfigParamRequest) Command() types.Command {
 	return types.CommandGetDCMIConfigParam
 }
 
+func (res *GetDCMIConfigParamResponse) Pack() []byte {
+	out := make([]byte, 4+len(res.ParamData))
+
+	out[0] = types.GroupExtensionDCMI
+	out[1] = res.MajorVersion
+	out[2] = res.MinorVersion
+	out[3] = res.ParamRevision
+	copy(out[4:], res.ParamData)
+
+	return out
+}
+
 func (res *GetDCMIConfigParamResponse) Unpack(msg []byte) error {
 	if len(msg) < 5 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 5)
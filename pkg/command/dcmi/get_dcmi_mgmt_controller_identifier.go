@@ -9,6 +9,8 @@ import (
 // [DCMI specification v1.5]: 6.4.6.1 Get Management Controller Identifier String Command
 type GetDCMIMgmtControllerIdentifierRequest struct {
 	Offset uint8
+	// Number of bytes to read (16 bytes maximum); 0 reads the maximum.
+	ReadBytes uint8
 }
 
 type GetDCMIMgmtControllerIdentifierResponse struct {
@@ -24,13 +26,35 @@ func (req *GetDCMIMgmtControllerIdentifierRequest) Pack() []byte {
 	// Number of bytes to read (16 bytes maximum)
 	// using the fixed (maximum) value is OK here.
 	var readBytes = uint8(0x10)
+	if req.ReadBytes != 0 {
+		readBytes = req.ReadBytes
+	}
 	return []byte{types.GroupExtensionDCMI, req.Offset, readBytes}
 }
 
+func (req *GetDCMIMgmtControllerIdentifierRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+
+	req.Offset = msg[1]
+	req.ReadBytes = msg[2]
+
+	return nil
+}
+
 func (req *GetDCMIMgmtControllerIdentifierRequest) Command() types.Command {
 	return types.CommandGetDCMIMgmtControllerIdentifier
 }
 
+func (res *GetDCMIMgmtControllerIdentifierResponse) Pack() []byte {
+	out := make([]byte, 2+len(res.IDStr))
+	out[0] = types.GroupExtensionDCMI
+	out[1] = res.IDStrLength
+	copy(out[2:], res.IDStr)
+	return out
+}
+
 func (res *GetDCMIMgmtControllerIdentifierResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
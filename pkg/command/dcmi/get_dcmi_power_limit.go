@@ -25,10 +25,29 @@ func (req *GetDCMIPowerLimitRequest) Pack() []byte {
 	return []byte{types.GroupExtensionDCMI, 0x00, 0x00}
 }
 
+func (req *GetDCMIPowerLimitRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+	return nil
+}
+
 func (req *GetDCMIPowerLimitRequest) Command() types.Command {
 	return types.CommandGetDCMIPowerLimit
 }
 
+func (res *GetDCMIPowerLimitResponse) Pack() []byte {
+	out := make([]byte, 14)
+
+	out[0] = types.GroupExtensionDCMI
+	out[3] = uint8(res.ExceptionAction)
+	types.PackUint16L(res.PowerLimitRequested, out, 4)
+	types.PackUint32L(res.CorrectionTimeLimitMilliSec, out, 6)
+	types.PackUint16L(res.StatisticsSamplingPeriodSec, out, 12)
+
+	return out
+}
+
 func (res *GetDCMIPowerLimitResponse) Unpack(msg []byte) error {
 	if len(msg) < 14 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 14)
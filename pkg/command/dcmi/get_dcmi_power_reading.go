@@ -48,10 +48,34 @@ func (req *GetDCMIPowerReadingRequest) Pack() []byte {
 	return []byte{types.GroupExtensionDCMI, 0x01, 0x00, 0x00}
 }
 
+func (req *GetDCMIPowerReadingRequest) Unpack(msg []byte) error {
+	if len(msg) < 4 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
+	}
+	return nil
+}
+
 func (req *GetDCMIPowerReadingRequest) Command() types.Command {
 	return types.CommandGetDCMIPowerReading
 }
 
+func (res *GetDCMIPowerReadingResponse) Pack() []byte {
+	out := make([]byte, 18)
+
+	out[0] = types.GroupExtensionDCMI
+	types.PackUint16L(res.CurrentPower, out, 1)
+	types.PackUint16L(res.MinimumPower, out, 3)
+	types.PackUint16L(res.MaximumPower, out, 5)
+	types.PackUint16L(res.AveragePower, out, 7)
+	types.PackUint32L(res.Timestamp, out, 9)
+	types.PackUint32L(res.ReportingPeriod, out, 13)
+	if res.PowerMeasurementActive {
+		out[17] = types.SetBit6(out[17])
+	}
+
+	return out
+}
+
 func (res *GetDCMIPowerReadingResponse) Unpack(msg []byte) error {
 	if len(msg) < 18 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 19)
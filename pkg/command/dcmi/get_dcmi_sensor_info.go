@@ -34,10 +34,34 @@ func (req *GetDCMISensorInfoRequest) Pack() []byte {
 	return out
 }
 
+func (req *GetDCMISensorInfoRequest) Unpack(msg []byte) error {
+	if len(msg) < 5 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 5)
+	}
+
+	req.SensorType = types.SensorType(msg[1])
+	req.EntityID = types.EntityID(msg[2])
+	req.EntityInstance = types.EntityInstance(msg[3])
+	req.EntityInstanceStart = msg[4]
+
+	return nil
+}
+
 func (req *GetDCMISensorInfoRequest) Command() types.Command {
 	return types.CommandGetDCMISensorInfo
 }
 
+func (res *GetDCMISensorInfoResponse) Pack() []byte {
+	out := make([]byte, 3+2*len(res.SDRRecordID))
+	out[0] = types.GroupExtensionDCMI
+	out[1] = res.TotalEntityInstances
+	out[2] = uint8(len(res.SDRRecordID))
+	for i, id := range res.SDRRecordID {
+		types.PackUint16L(id, out, 3+i*2)
+	}
+	return out
+}
+
 func (res *GetDCMISensorInfoResponse) Unpack(msg []byte) error {
 	if len(msg) < 3 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
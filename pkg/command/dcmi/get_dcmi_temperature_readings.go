@@ -23,6 +23,8 @@ type GetDCMITemperatureReadingsResponse struct {
 }
 
 type DCMITemperatureReading struct {
+	// Temperature in degrees Celsius, sent in sign and magnitude form: [7]
+	// sign (1b = negative), [6:0] magnitude.
 	TemperatureReading int8
 	EntityInstance     types.EntityInstance
 	EntityID           types.EntityID
@@ -32,10 +34,39 @@ func (req *GetDCMITemperatureReadingsRequest) Pack() []byte {
 	return []byte{types.GroupExtensionDCMI, byte(req.SensorType), byte(req.EntityID), byte(req.EntityInstance), req.EntityInstanceStart}
 }
 
+func (req *GetDCMITemperatureReadingsRequest) Unpack(msg []byte) error {
+	if len(msg) < 5 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 5)
+	}
+
+	req.SensorType = types.SensorType(msg[1])
+	req.EntityID = types.EntityID(msg[2])
+	req.EntityInstance = types.EntityInstance(msg[3])
+	req.EntityInstanceStart = msg[4]
+
+	return nil
+}
+
 func (req *GetDCMITemperatureReadingsRequest) Command() types.Command {
 	return types.CommandGetDCMITemperatureReadings
 }
 
+func (res *GetDCMITemperatureReadingsResponse) Pack() []byte {
+	out := make([]byte, 3+2*len(res.TemperatureReadings))
+	out[0] = types.GroupExtensionDCMI
+	out[1] = res.TotalEntityInstances
+	out[2] = uint8(len(res.TemperatureReadings))
+	for i, r := range res.TemperatureReadings {
+		v := uint8(r.TemperatureReading)
+		if r.TemperatureReading < 0 {
+			v = 0x80 | uint8(-int(r.TemperatureReading))&0x7f
+		}
+		out[3+i*2] = v
+		out[3+i*2+1] = uint8(r.EntityInstance)
+	}
+	return out
+}
+
 func (res *GetDCMITemperatureReadingsResponse) Unpack(msg []byte) error {
 	if len(msg) < 3 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
@@ -57,7 +88,10 @@ func (res *GetDCMITemperatureReadingsResponse) Unpack(msg []byte) error {
 		r := DCMITemperatureReading{}
 
 		v := msg[3+i*2]
-		r.TemperatureReading = int8(v)
+		r.TemperatureReading = int8(v & 0x7f)
+		if v&0x80 != 0 {
+			r.TemperatureReading = -r.TemperatureReading
+		}
 
 		r.EntityInstance = types.EntityInstance(msg[3+i*2+1])
 		r.EntityID = res.EntityID
@@ -30,10 +30,31 @@ func (req *GetDCMIThermalLimitRequest) Pack() []byte {
 	return []byte{types.GroupExtensionDCMI, byte(req.EntityID), byte(req.EntityInstance)}
 }
 
+func (req *GetDCMIThermalLimitRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+
+	req.EntityID = types.EntityID(msg[1])
+	req.EntityInstance = types.EntityInstance(msg[2])
+
+	return nil
+}
+
 func (req *GetDCMIThermalLimitRequest) Command() types.Command {
 	return types.CommandGetDCMIThermalLimit
 }
 
+func (res *GetDCMIThermalLimitResponse) Pack() []byte {
+	out := make([]byte, 5)
+	out[0] = types.GroupExtensionDCMI
+	out[1] = types.SetOrClearBit6(out[1], res.ExceptionAction_PowerOffAndLogSEL)
+	out[1] = types.SetOrClearBit5(out[1], res.ExceptionAction_LogSELOnly)
+	out[2] = res.TemperatureLimit
+	types.PackUint16L(res.ExceptionTimeSec, out, 3)
+	return out
+}
+
 func (res *GetDCMIThermalLimitResponse) Unpack(msg []byte) error {
 	if len(msg) < 5 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 5)
@@ -40,10 +40,26 @@ func (req *SetDCMIAssetTagRequest) Pack() []byte {
 	return out
 }
 
+func (req *SetDCMIAssetTagRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+
+	req.Offset = msg[1]
+	req.WriteBytes = msg[2]
+	req.AssetTag, _, _ = types.UnpackBytes(msg, 3, len(msg)-3)
+
+	return nil
+}
+
 func (req *SetDCMIAssetTagRequest) Command() types.Command {
 	return types.CommandSetDCMIAssetTag
 }
 
+func (res *SetDCMIAssetTagResponse) Pack() []byte {
+	return []byte{types.GroupExtensionDCMI, res.TotalLength}
+}
+
 func (res *SetDCMIAssetTagResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
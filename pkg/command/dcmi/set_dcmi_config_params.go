@@ -26,10 +26,26 @@ func (req *SetDCMIConfigParamRequest) Pack() []byte {
 
 }
 
+func (req *SetDCMIConfigParamRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+
+	req.ParamSelector = types.DCMIConfigParamSelector(msg[1])
+	req.SetSelector = msg[2]
+	req.ParamData, _, _ = types.UnpackBytes(msg, 3, len(msg)-3)
+
+	return nil
+}
+
 func (req *SetDCMIConfigParamRequest) Command() types.Command {
 	return types.CommandSetDCMIConfigParam
 }
 
+func (res *SetDCMIConfigParamResponse) Pack() []byte {
+	return []byte{types.GroupExtensionDCMI}
+}
+
 func (res *SetDCMIConfigParamResponse) Unpack(msg []byte) error {
 	if len(msg) < 1 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
@@ -28,10 +28,26 @@ func (req *SetDCMIMgmtControllerIdentifierRequest) Pack() []byte {
 	return out
 }
 
+func (req *SetDCMIMgmtControllerIdentifierRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+
+	req.Offset = msg[1]
+	req.WriteBytes = msg[2]
+	req.IDStr, _, _ = types.UnpackBytes(msg, 3, len(msg)-3)
+
+	return nil
+}
+
 func (req *SetDCMIMgmtControllerIdentifierRequest) Command() types.Command {
 	return types.CommandSetDCMIMgmtControllerIdentifier
 }
 
+func (res *SetDCMIMgmtControllerIdentifierResponse) Pack() []byte {
+	return []byte{types.GroupExtensionDCMI, res.TotalLength}
+}
+
 func (res *SetDCMIMgmtControllerIdentifierResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
@@ -39,10 +39,27 @@ func (req *SetDCMIPowerLimitRequest) Pack() []byte {
 	return out
 }
 
+func (req *SetDCMIPowerLimitRequest) Unpack(msg []byte) error {
+	if len(msg) < 15 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 15)
+	}
+
+	req.ExceptionAction = types.DCMIExceptionAction(msg[4])
+	req.PowerLimitRequested, _, _ = types.UnpackUint16L(msg, 5)
+	req.CorrectionTimeLimitMilliSec, _, _ = types.UnpackUint32L(msg, 7)
+	req.StatisticsSamplingPeriodSec, _, _ = types.UnpackUint16L(msg, 13)
+
+	return nil
+}
+
 func (req *SetDCMIPowerLimitRequest) Command() types.Command {
 	return types.CommandSetDCMIPowerLimit
 }
 
+func (res *SetDCMIPowerLimitResponse) Pack() []byte {
+	return []byte{types.GroupExtensionDCMI}
+}
+
 func (res *SetDCMIPowerLimitResponse) Unpack(msg []byte) error {
 	if len(msg) < 1 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
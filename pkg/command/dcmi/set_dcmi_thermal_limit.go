@@ -39,10 +39,29 @@ func (req *SetDCMIThermalLimitRequest) Pack() []byte {
 	return out
 }
 
+func (req *SetDCMIThermalLimitRequest) Unpack(msg []byte) error {
+	if len(msg) < 7 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 7)
+	}
+
+	req.EntityID = types.EntityID(msg[1])
+	req.EntityInstance = types.EntityInstance(msg[2])
+	req.ExceptionAction_PowerOffAndLogSEL = types.IsBit6Set(msg[3])
+	req.ExceptionAction_LogSELOnly = types.IsBit5Set(msg[3])
+	req.TemperatureLimit = msg[4]
+	req.ExceptionTimeSec, _, _ = types.UnpackUint16L(msg, 5)
+
+	return nil
+}
+
 func (req *SetDCMIThermalLimitRequest) Command() types.Command {
 	return types.CommandSetDCMIThermalLimit
 }
 
+func (res *SetDCMIThermalLimitResponse) Pack() []byte {
+	return []byte{types.GroupExtensionDCMI}
+}
+
 func (res *SetDCMIThermalLimitResponse) Unpack(msg []byte) error {
 	if len(msg) < 1 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
@@ -1,6 +1,8 @@
 package sensor
 
 import (
+	"fmt"
+
 	"github.com/bougou/go-ipmi/pkg/types"
 )
 
@@ -33,6 +35,10 @@ type AlertImmediateRequest struct {
 	SendAlertString     bool
 	AlertStringSelector uint8
 
+	// WithPlatformEvent reports whether the optional Platform Event Message
+	// fields below (bytes 4:11) are present.
+	WithPlatformEvent bool
+
 	GeneratorID  uint8
 	EvMRev       uint8
 	SensorType   types.SensorType
@@ -48,18 +54,89 @@ type AlertImmediateResponse struct {
 }
 
 func (req *AlertImmediateRequest) Pack() []byte {
-	out := make([]byte, 1)
+	out := make([]byte, 3, 11)
+	out[0] = req.ChannelNumber & 0x0f
+	out[1] = req.Operation<<6 | req.DestinationSelector&0x0f
+	out[2] = types.SetOrClearBit7(req.AlertStringSelector&0x7f, req.SendAlertString)
+	if req.WithPlatformEvent {
+		b := uint8(req.EventReadingType) & 0x7f
+		b = types.SetOrClearBit7(b, bool(req.EventDir))
+		out = append(out,
+			req.GeneratorID,
+			req.EvMRev,
+			uint8(req.SensorType),
+			uint8(req.SensorNumber),
+			b,
+			req.EventData.EventData1,
+			req.EventData.EventData2,
+			req.EventData.EventData3,
+		)
+	}
 	return out
 }
 
+func (req *AlertImmediateRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+	req.ChannelNumber = msg[0] & 0x0f
+	req.Operation = msg[1] >> 6
+	req.DestinationSelector = msg[1] & 0x0f
+	req.SendAlertString = types.IsBit7Set(msg[2])
+	req.AlertStringSelector = msg[2] & 0x7f
+
+	req.WithPlatformEvent = len(msg) > 3
+	if !req.WithPlatformEvent {
+		return nil
+	}
+	if len(msg) < 11 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 11)
+	}
+	req.GeneratorID = msg[3]
+	req.EvMRev = msg[4]
+	req.SensorType = types.SensorType(msg[5])
+	req.SensorNumber = types.SensorNumber(msg[6])
+	req.EventDir = types.EventDir(types.IsBit7Set(msg[7]))
+	req.EventReadingType = types.EventReadingType(msg[7] & 0x7f)
+	req.EventData.EventData1 = msg[8]
+	req.EventData.EventData2 = msg[9]
+	req.EventData.EventData3 = msg[10]
+	return nil
+}
+
 func (req *AlertImmediateRequest) Command() types.Command {
 	return types.CommandAlertImmediate
 }
 
 func (res *AlertImmediateResponse) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	res.AlertImmediateStatus = msg[0]
 	return nil
 }
 
+func (res *AlertImmediateResponse) Pack() []byte {
+	return []byte{res.AlertImmediateStatus}
+}
+
 func (res *AlertImmediateResponse) Format() string {
-	return ""
+	return fmt.Sprintf("Alert Immediate Status : %s\n", AlertImmediateStatus(res.AlertImmediateStatus))
+}
+
+func (status AlertImmediateStatus) String() string {
+	switch status {
+	case AlertImmediateStatusNoStatus:
+		return "no status"
+	case AlertImmediateStatusNormalEnd:
+		return "normal end"
+	case AlertImmediateStatusFailedRetry:
+		return "failed, retries exhausted"
+	case AlertImmediateStatusFailedWaitACK:
+		return "failed, timed out waiting for acknowledge"
+	case AlertImmediateStatusInProgress:
+		return "in progress"
+	default:
+		return fmt.Sprintf("unknown (%#02x)", uint8(status))
+	}
 }
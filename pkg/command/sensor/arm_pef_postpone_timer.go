@@ -54,3 +54,15 @@ func (res *ArmPEFPostponeTimerResponse) Format() string {
 	return "" +
 		fmt.Sprintf("Present timer countdown value : %d (%#02x)\n", res.PresentValue, res.PresentValue)
 }
+
+func (req *ArmPEFPostponeTimerRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	req.Timeout, _, _ = types.UnpackUint8(msg, 0)
+	return nil
+}
+
+func (res *ArmPEFPostponeTimerResponse) Pack() []byte {
+	return []byte{res.PresentValue}
+}
@@ -45,6 +45,21 @@ func (req *ClearMessageFlagsRequest) Pack() []byte {
 	return []byte{b}
 }
 
+func (req *ClearMessageFlagsRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+
+	b, _, _ := types.UnpackUint8(msg, 0)
+	req.ClearOEM2 = types.IsBit7Set(b)
+	req.ClearOEM1 = types.IsBit6Set(b)
+	req.ClearOEM0 = types.IsBit5Set(b)
+	req.ClearWatchdogPreTimeoutInterruptFlag = types.IsBit3Set(b)
+	req.ClearEventMessageBuffer = types.IsBit1Set(b)
+	req.ClearReceiveMessageQueue = types.IsBit0Set(b)
+	return nil
+}
+
 func (res *ClearMessageFlagsResponse) Unpack(msg []byte) error {
 	return nil
 }
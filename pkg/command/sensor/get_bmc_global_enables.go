@@ -28,6 +28,32 @@ func (req *GetBMCGlobalEnablesRequest) Pack() []byte {
 	return []byte{}
 }
 
+func (res *GetBMCGlobalEnablesResponse) Pack() []byte {
+	var b uint8 = 0
+	if res.OEM2Enabled {
+		b = types.SetBit7(b)
+	}
+	if res.OEM1Enabled {
+		b = types.SetBit6(b)
+	}
+	if res.OEM0Enabled {
+		b = types.SetBit5(b)
+	}
+	if res.SystemEventLoggingEnabled {
+		b = types.SetBit3(b)
+	}
+	if res.EventMessageBufferEnabled {
+		b = types.SetBit2(b)
+	}
+	if res.EventMessageBufferFullInterruptEnabled {
+		b = types.SetBit1(b)
+	}
+	if res.ReceiveMessageQueueInterruptEnabled {
+		b = types.SetBit0(b)
+	}
+	return []byte{b}
+}
+
 func (res *GetBMCGlobalEnablesResponse) Unpack(msg []byte) error {
 	if len(msg) < 1 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
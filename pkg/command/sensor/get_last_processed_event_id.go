@@ -47,3 +47,12 @@ func (res *GetLastProcessedEventIdResponse) Format() string {
 		fmt.Sprintf("Last S/W processed ID : %#04x (%d)\n", res.LastSoftwareProcessedEventRecordID, res.LastSoftwareProcessedEventRecordID) +
 		fmt.Sprintf("Last BMC processed ID : %#04x (%d)\n", res.LastBMCProcessedEventRecordID, res.LastBMCProcessedEventRecordID)
 }
+
+func (res *GetLastProcessedEventIdResponse) Pack() []byte {
+	out := make([]byte, 10)
+	types.PackUint32L(uint32(res.MostRecentAdditionTime.Unix()), out, 0)
+	types.PackUint16L(res.LastRecordID, out, 4)
+	types.PackUint16L(res.LastSoftwareProcessedEventRecordID, out, 6)
+	types.PackUint16L(res.LastBMCProcessedEventRecordID, out, 8)
+	return out
+}
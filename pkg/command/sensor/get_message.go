@@ -10,8 +10,14 @@ type GetMessageRequest struct {
 }
 
 type GetMessageResponse struct {
-	ChannelNumber uint8
-	MessageData   []byte
+	// Inferred privilege level of the message: the privilege of the session
+	// a LAN message was received on, 0 when the channel has no sessions.
+	PrivilegeLevel types.PrivilegeLevel
+	ChannelNumber  uint8
+
+	// The message as it was received. For a LAN channel it is preceded by
+	// the handle of the session that sent it.
+	MessageData []byte
 }
 
 func (req *GetMessageRequest) Command() types.Command {
@@ -22,11 +28,20 @@ func (req *GetMessageRequest) Pack() []byte {
 	return []byte{}
 }
 
+func (res *GetMessageResponse) Pack() []byte {
+	out := make([]byte, 1+len(res.MessageData))
+	types.PackUint8(uint8(res.PrivilegeLevel)<<4|res.ChannelNumber&0x0f, out, 0)
+	types.PackBytes(res.MessageData, out, 1)
+	return out
+}
+
 func (res *GetMessageResponse) Unpack(msg []byte) error {
 	if len(msg) < 1 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
 	}
-	res.ChannelNumber, _, _ = types.UnpackUint8(msg, 0)
+	b, _, _ := types.UnpackUint8(msg, 0)
+	res.PrivilegeLevel = types.PrivilegeLevel(b >> 4)
+	res.ChannelNumber = b & 0x0f
 	res.MessageData, _, _ = types.UnpackBytes(msg, 1, len(msg)-1)
 	return nil
 }
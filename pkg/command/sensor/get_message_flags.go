@@ -26,6 +26,30 @@ func (req *GetMessageFlagsRequest) Pack() []byte {
 	return []byte{}
 }
 
+func (res *GetMessageFlagsResponse) Pack() []byte {
+	var b uint8 = 0
+	if res.OEM2Available {
+		b = types.SetBit7(b)
+	}
+	if res.OEM1Available {
+		b = types.SetBit6(b)
+	}
+	if res.OEM0Available {
+		b = types.SetBit5(b)
+	}
+	if res.WatchdogPreTimeoutInterruptOccurred {
+		b = types.SetBit3(b)
+	}
+	if res.EventMessageBufferFull {
+		b = types.SetBit1(b)
+	}
+	if res.ReceiveMessageQueueAvailable {
+		b = types.SetBit0(b)
+	}
+
+	return []byte{b}
+}
+
 func (res *GetMessageFlagsResponse) Unpack(msg []byte) error {
 	if len(msg) < 1 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
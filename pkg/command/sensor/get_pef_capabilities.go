@@ -68,3 +68,21 @@ func (res *GetPEFCapabilitiesResponse) Format() string {
 		fmt.Sprintf("Support Power Down           : %s\n", types.FormatBool(res.SupportPowerDown, "supported", "not-supported")) +
 		fmt.Sprintf("Support Alert                : %s\n", types.FormatBool(res.SupportAlert, "supported", "not-supported"))
 }
+
+func (res *GetPEFCapabilitiesResponse) Pack() []byte {
+	out := make([]byte, 3)
+	types.PackUint8(res.PEFVersion, out, 0)
+
+	var b1 uint8
+	b1 = types.SetOrClearBit7(b1, res.SupportOEMEventFilter)
+	b1 = types.SetOrClearBit5(b1, res.SupportDiagnosticInterrupt)
+	b1 = types.SetOrClearBit4(b1, res.SupportOEMAction)
+	b1 = types.SetOrClearBit3(b1, res.SupportPowerCycle)
+	b1 = types.SetOrClearBit2(b1, res.SupportReset)
+	b1 = types.SetOrClearBit1(b1, res.SupportPowerDown)
+	b1 = types.SetOrClearBit0(b1, res.SupportAlert)
+	types.PackUint8(b1, out, 1)
+
+	types.PackUint8(res.EventFilterTableEntries, out, 2)
+	return out
+}
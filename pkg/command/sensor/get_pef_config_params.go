@@ -70,5 +70,23 @@ func (res *GetPEFConfigParamResponse) Format() string {
 		fmt.Sprintf("Configuration Parameter Data : %# 02x\n", res.ParamData)
 }
 
+func (req *GetPEFConfigParamRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+	req.GetParamRevisionOnly = types.IsBit7Set(msg[0])
+	req.ParamSelector = types.PEFConfigParamSelector(msg[0] & 0x7f)
+	req.SetSelector, _, _ = types.UnpackUint8(msg, 1)
+	req.BlockSelector, _, _ = types.UnpackUint8(msg, 2)
+	return nil
+}
+
+func (res *GetPEFConfigParamResponse) Pack() []byte {
+	out := make([]byte, 1+len(res.ParamData))
+	types.PackUint8(res.ParamRevision, out, 0)
+	types.PackBytes(res.ParamData, out, 1)
+	return out
+}
+
 // GroupControlsCount:  &PEFConfigParam_GroupControlsCount{},
 // GroupControls:       []*PEFConfigParam_GroupControl{},
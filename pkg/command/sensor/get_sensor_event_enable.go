@@ -130,3 +130,25 @@ func (res *GetSensorEventEnableResponse) Format() string {
 		fmt.Sprintf("Enabled Assert Event      : %s\n", strings.Join(assertedStr, "\n - ")) +
 		fmt.Sprintf("Enabled Deassert Event    : %s\n", strings.Join(deassertedStr, "\n - "))
 }
+
+func (req *GetSensorEventEnableRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	req.SensorNumber, _, _ = types.UnpackUint8(msg, 0)
+	return nil
+}
+
+func (res *GetSensorEventEnableResponse) Pack() []byte {
+	out := make([]byte, 5)
+
+	var b1 uint8
+	b1 = types.SetOrClearBit7(b1, !res.EventMessagesDisabled)
+	b1 = types.SetOrClearBit6(b1, !res.SensorScanningDisabled)
+	types.PackUint8(b1, out, 0)
+
+	assert, deassert := res.SensorEventFlag.EventMasks()
+	types.PackUint16L(assert, out, 1)
+	types.PackUint16L(deassert, out, 3)
+	return out
+}
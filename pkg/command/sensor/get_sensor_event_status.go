@@ -134,3 +134,26 @@ func (res *GetSensorEventStatusResponse) Format() string {
 		fmt.Sprintf("Occurred Assert Event     : %s\n", strings.Join(assertedStr, "\n - ")) +
 		fmt.Sprintf("Occurred Deassert Event   : %s\n", strings.Join(deassertedStr, "\n -"))
 }
+
+func (req *GetSensorEventStatusRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	req.SensorNumber, _, _ = types.UnpackUint8(msg, 0)
+	return nil
+}
+
+func (res *GetSensorEventStatusResponse) Pack() []byte {
+	out := make([]byte, 5)
+
+	var b1 uint8
+	b1 = types.SetOrClearBit7(b1, !res.EventMessagesDisabled)
+	b1 = types.SetOrClearBit6(b1, !res.SensorScanningDisabled)
+	b1 = types.SetOrClearBit5(b1, res.ReadingUnavailable)
+	types.PackUint8(b1, out, 0)
+
+	assert, deassert := res.SensorEventFlag.EventMasks()
+	types.PackUint16L(assert, out, 1)
+	types.PackUint16L(deassert, out, 3)
+	return out
+}
@@ -42,3 +42,18 @@ func (res *GetSensorHysteresisResponse) Format() string {
 		fmt.Sprintf("Positive Hysteresis : %d\n", res.PositiveRaw) +
 		fmt.Sprintf("Negative Hysteresis : %d\n", res.NegativeRaw)
 }
+
+func (req *GetSensorHysteresisRequest) Unpack(msg []byte) error {
+	if len(msg) < 2 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
+	}
+	req.SensorNumber, _, _ = types.UnpackUint8(msg, 0)
+	return nil
+}
+
+func (res *GetSensorHysteresisResponse) Pack() []byte {
+	out := make([]byte, 2)
+	types.PackUint8(res.PositiveRaw, out, 0)
+	types.PackUint8(res.NegativeRaw, out, 1)
+	return out
+}
@@ -140,3 +140,48 @@ func (res *GetSensorReadingResponse) Format() string {
 		fmt.Sprintf("Threshold Status       : %s\n", res.ThresholdStatus()) +
 		fmt.Sprintf("Discrete Events        : %v\n", res.ActiveStates.TrueEvents())
 }
+
+func (req *GetSensorReadingRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	req.SensorNumber, _, _ = types.UnpackUint8(msg, 0)
+	return nil
+}
+
+// Pack encodes the response with both optional status bytes. A comparison
+// status bit is set when either the threshold field or the discrete state
+// sharing it is set; bit 7 of the last byte is reserved and returned as 1b.
+func (res *GetSensorReadingResponse) Pack() []byte {
+	out := make([]byte, 4)
+	types.PackUint8(res.Reading, out, 0)
+
+	var b1 uint8
+	b1 = types.SetOrClearBit7(b1, !res.EventMessagesDisabled)
+	b1 = types.SetOrClearBit6(b1, !res.SensorScanningDisabled)
+	b1 = types.SetOrClearBit5(b1, res.ReadingUnavailable)
+	types.PackUint8(b1, out, 1)
+
+	states := res.ActiveStates
+	var b2 uint8
+	b2 = types.SetOrClearBit7(b2, states.State_7)
+	b2 = types.SetOrClearBit6(b2, states.State_6)
+	b2 = types.SetOrClearBit5(b2, res.Above_UNR || states.State_5)
+	b2 = types.SetOrClearBit4(b2, res.Above_UCR || states.State_4)
+	b2 = types.SetOrClearBit3(b2, res.Above_UNC || states.State_3)
+	b2 = types.SetOrClearBit2(b2, res.Below_LNR || states.State_2)
+	b2 = types.SetOrClearBit1(b2, res.Below_LCR || states.State_1)
+	b2 = types.SetOrClearBit0(b2, res.Below_LNC || states.State_0)
+	types.PackUint8(b2, out, 2)
+
+	var b3 uint8 = 0x80
+	b3 = types.SetOrClearBit6(b3, states.State_14)
+	b3 = types.SetOrClearBit5(b3, states.State_13)
+	b3 = types.SetOrClearBit4(b3, states.State_12)
+	b3 = types.SetOrClearBit3(b3, states.State_11)
+	b3 = types.SetOrClearBit2(b3, states.State_10)
+	b3 = types.SetOrClearBit1(b3, states.State_9)
+	b3 = types.SetOrClearBit0(b3, states.State_8)
+	types.PackUint8(b3, out, 3)
+	return out
+}
@@ -75,3 +75,27 @@ func (res *GetSensorReadingFactorsResponse) Format() string {
 		fmt.Sprintf("Accuracy    : %d\n", res.Accuracy) +
 		fmt.Sprintf("AccuracyExp : %d\n", res.Accuracy_Exp)
 }
+
+func (req *GetSensorReadingFactorsRequest) Unpack(msg []byte) error {
+	if len(msg) < 2 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
+	}
+	req.SensorNumber, _, _ = types.UnpackUint8(msg, 0)
+	req.Reading, _, _ = types.UnpackUint8(msg, 1)
+	return nil
+}
+
+func (res *GetSensorReadingFactorsResponse) Pack() []byte {
+	out := make([]byte, 7)
+	types.PackUint8(res.NextReading, out, 0)
+
+	m := types.TwoSComplementEncode(int32(res.M), 10)
+	b := types.TwoSComplementEncode(int32(res.B), 10)
+	out[1] = uint8(m)
+	out[2] = uint8(m>>2)&0xc0 | res.Tolerance&0x3f
+	out[3] = uint8(b)
+	out[4] = uint8(b>>2)&0xc0 | uint8(res.Accuracy)&0x3f
+	out[5] = uint8(res.Accuracy>>2)&0xf0 | (res.Accuracy_Exp&0x03)<<2
+	out[6] = uint8(types.TwoSComplementEncode(int32(res.R_Exp), 4))<<4 | uint8(types.TwoSComplementEncode(int32(res.B_Exp), 4))&0x0f
+	return out
+}
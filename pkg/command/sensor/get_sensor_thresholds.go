@@ -70,3 +70,32 @@ func (res *GetSensorThresholdsResponse) Format() string {
 		fmt.Sprintf("LCR Readable : %v%s\n", res.LCR_Readable, types.FormatBool(res.LCR_Readable, fmt.Sprintf(", raw: %#02x", res.LCR_Raw), "")) +
 		fmt.Sprintf("LNC Readable : %v%s\n", res.LNC_Readable, types.FormatBool(res.LNC_Readable, fmt.Sprintf(", raw: %#02x", res.LNC_Raw), ""))
 }
+
+func (req *GetSensorThresholdsRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	req.SensorNumber, _, _ = types.UnpackUint8(msg, 0)
+	return nil
+}
+
+func (res *GetSensorThresholdsResponse) Pack() []byte {
+	out := make([]byte, 7)
+
+	var b uint8
+	b = types.SetOrClearBit5(b, res.UNR_Readable)
+	b = types.SetOrClearBit4(b, res.UCR_Readable)
+	b = types.SetOrClearBit3(b, res.UNC_Readable)
+	b = types.SetOrClearBit2(b, res.LNR_Readable)
+	b = types.SetOrClearBit1(b, res.LCR_Readable)
+	b = types.SetOrClearBit0(b, res.LNC_Readable)
+	types.PackUint8(b, out, 0)
+
+	types.PackUint8(res.LNC_Raw, out, 1)
+	types.PackUint8(res.LCR_Raw, out, 2)
+	types.PackUint8(res.LNR_Raw, out, 3)
+	types.PackUint8(res.UNC_Raw, out, 4)
+	types.PackUint8(res.UCR_Raw, out, 5)
+	types.PackUint8(res.UNR_Raw, out, 6)
+	return out
+}
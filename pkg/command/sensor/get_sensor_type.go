@@ -42,3 +42,18 @@ func (res *GetSensorTypeResponse) Format() string {
 		fmt.Sprintf("Sensor Type        : %s\n", res.SensorType) +
 		fmt.Sprintf("Event/Reading Type : %#02x (%s)\n", uint8(res.EventReadingType), res.EventReadingType.String())
 }
+
+func (req *GetSensorTypeRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	req.SensorNumber, _, _ = types.UnpackUint8(msg, 0)
+	return nil
+}
+
+func (res *GetSensorTypeResponse) Pack() []byte {
+	out := make([]byte, 2)
+	types.PackUint8(uint8(res.SensorType), out, 0)
+	types.PackUint8(uint8(res.EventReadingType)&0x7f, out, 1)
+	return out
+}
@@ -0,0 +1,83 @@
+package sensor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestMessageFlagsCodecRoundTrip(t *testing.T) {
+	flagsOrig := &GetMessageFlagsResponse{WatchdogPreTimeoutInterruptOccurred: true, ReceiveMessageQueueAvailable: true}
+	if got := flagsOrig.Pack(); !bytes.Equal(got, []byte{0x09}) {
+		t.Fatalf("message flags = % x, want 09", got)
+	}
+	var flags GetMessageFlagsResponse
+	if err := flags.Unpack(flagsOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if flags != *flagsOrig {
+		t.Fatalf("message flags mismatch: %+v vs %+v", flagsOrig, flags)
+	}
+
+	clearOrig := &ClearMessageFlagsRequest{ClearEventMessageBuffer: true, ClearReceiveMessageQueue: true}
+	var clear ClearMessageFlagsRequest
+	if err := clear.Unpack(clearOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if clear != *clearOrig {
+		t.Fatalf("clear message flags mismatch: %+v vs %+v", clearOrig, clear)
+	}
+}
+
+func TestGetMessageCodecRoundTrip(t *testing.T) {
+	resOrig := &GetMessageResponse{
+		PrivilegeLevel: types.PrivilegeLevelAdministrator,
+		ChannelNumber:  0x01,
+		MessageData:    []byte{0x05, 0x20, 0x18, 0xc8, 0x81, 0x04, 0x01, 0x7a},
+	}
+	packed := resOrig.Pack()
+	if packed[0] != 0x41 {
+		t.Fatalf("channel byte = %#02x, want 41h", packed[0])
+	}
+	var res GetMessageResponse
+	if err := res.Unpack(packed); err != nil {
+		t.Fatal(err)
+	}
+	if res.PrivilegeLevel != resOrig.PrivilegeLevel || res.ChannelNumber != resOrig.ChannelNumber ||
+		!bytes.Equal(res.MessageData, resOrig.MessageData) {
+		t.Fatalf("get message mismatch: %+v vs %+v", resOrig, res)
+	}
+}
+
+func TestGlobalEnablesCodecRoundTrip(t *testing.T) {
+	setOrig := &SetBMCGlobalEnablesRequest{EnableOEM0: true, EnableSystemEventLogging: true, EnableEventMessageBuffer: true, EnableReceiveMessageQueueInterrupt: true}
+	if got := setOrig.Pack(); !bytes.Equal(got, []byte{0x2d}) {
+		t.Fatalf("set global enables = % x, want 2d", got)
+	}
+	var set SetBMCGlobalEnablesRequest
+	if err := set.Unpack(setOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if set != *setOrig {
+		t.Fatalf("set global enables mismatch: %+v vs %+v", setOrig, set)
+	}
+
+	getOrig := &GetBMCGlobalEnablesResponse{SystemEventLoggingEnabled: true, EventMessageBufferFullInterruptEnabled: true}
+	var get GetBMCGlobalEnablesResponse
+	if err := get.Unpack(getOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if get != *getOrig {
+		t.Fatalf("get global enables mismatch: %+v vs %+v", getOrig, get)
+	}
+
+	eventOrig := &ReadEventMessageBufferResponse{MessageData: [16]byte{0x00, 0x00, 0x02, 15: 0xff}}
+	var event ReadEventMessageBufferResponse
+	if err := event.Unpack(eventOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if event != *eventOrig {
+		t.Fatalf("event message buffer mismatch: %+v vs %+v", eventOrig, event)
+	}
+}
@@ -0,0 +1,104 @@
+package sensor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestPEFCapabilitiesCodecRoundTrip(t *testing.T) {
+	resOrig := &GetPEFCapabilitiesResponse{
+		PEFVersion:                 0x51,
+		SupportDiagnosticInterrupt: true,
+		SupportPowerCycle:          true,
+		SupportPowerDown:           true,
+		EventFilterTableEntries:    16,
+	}
+	var res GetPEFCapabilitiesResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res != *resOrig {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+}
+
+func TestPEFConfigParamCodecRoundTrip(t *testing.T) {
+	getOrig := &GetPEFConfigParamRequest{
+		GetParamRevisionOnly: true,
+		ParamSelector:        types.PEFConfigParamSelector_AlertString,
+		SetSelector:          2,
+		BlockSelector:        3,
+	}
+	var get GetPEFConfigParamRequest
+	if err := get.Unpack(getOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if get != *getOrig {
+		t.Fatalf("get request mismatch: %+v vs %+v", getOrig, get)
+	}
+
+	filter := &types.PEFConfigParam_EventFilter{
+		SetSelector: 1,
+		Filter:      &types.PEFEventFilter{FilterState: true, ActionPowerOff: true, EventData3Compare2: 0x5a},
+	}
+	setOrig := &SetPEFConfigParamRequest{ParamSelector: types.PEFConfigParamSelector_EventFilter, ParamData: filter.Pack()}
+	var set SetPEFConfigParamRequest
+	if err := set.Unpack(setOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if set.ParamSelector != setOrig.ParamSelector || len(set.ParamData) != 21 {
+		t.Fatalf("set request: %+v", set)
+	}
+	var got types.PEFConfigParam_EventFilter
+	if err := got.Unpack(set.ParamData); err != nil {
+		t.Fatal(err)
+	}
+	if got.SetSelector != 1 || *got.Filter != *filter.Filter {
+		t.Fatalf("event filter mismatch: %+v vs %+v", filter.Filter, got.Filter)
+	}
+
+	var res GetPEFConfigParamResponse
+	if err := res.Unpack((&GetPEFConfigParamResponse{ParamRevision: 0x11, ParamData: []byte{0x01}}).Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res.ParamRevision != 0x11 || len(res.ParamData) != 1 || res.ParamData[0] != 0x01 {
+		t.Fatalf("get response: %+v", res)
+	}
+}
+
+func TestPEFPostponeAndLastProcessedCodecRoundTrip(t *testing.T) {
+	var arm ArmPEFPostponeTimerRequest
+	if err := arm.Unpack((&ArmPEFPostponeTimerRequest{Timeout: 0x3c}).Pack()); err != nil || arm.Timeout != 0x3c {
+		t.Fatalf("arm request: %+v err=%v", arm, err)
+	}
+	var armRes ArmPEFPostponeTimerResponse
+	if err := armRes.Unpack((&ArmPEFPostponeTimerResponse{PresentValue: 0x1e}).Pack()); err != nil || armRes.PresentValue != 0x1e {
+		t.Fatalf("arm response: %+v err=%v", armRes, err)
+	}
+
+	setOrig := &SetLastProcessedEventIdRequest{ByBMC: true, RecordID: 0x1234}
+	var set SetLastProcessedEventIdRequest
+	if err := set.Unpack(setOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if set != *setOrig {
+		t.Fatalf("set request mismatch: %+v vs %+v", setOrig, set)
+	}
+
+	resOrig := &GetLastProcessedEventIdResponse{
+		MostRecentAdditionTime:             time.Unix(1_700_000_000, 0),
+		LastRecordID:                       0x0010,
+		LastSoftwareProcessedEventRecordID: 0x000e,
+		LastBMCProcessedEventRecordID:      0x000f,
+	}
+	var res GetLastProcessedEventIdResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if !res.MostRecentAdditionTime.Equal(resOrig.MostRecentAdditionTime) || res.LastRecordID != 0x0010 ||
+		res.LastSoftwareProcessedEventRecordID != 0x000e || res.LastBMCProcessedEventRecordID != 0x000f {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+}
@@ -22,6 +22,12 @@ func (req *ReadEventMessageBufferRequest) Pack() []byte {
 	return []byte{}
 }
 
+func (res *ReadEventMessageBufferResponse) Pack() []byte {
+	out := make([]byte, 16)
+	copy(out, res.MessageData[:])
+	return out
+}
+
 func (res *ReadEventMessageBufferResponse) Unpack(msg []byte) error {
 	if len(msg) < 16 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 16)
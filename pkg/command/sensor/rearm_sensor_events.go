@@ -115,3 +115,22 @@ func (res *RearmSensorEventsResponse) Unpack(msg []byte) error {
 func (res *RearmSensorEventsResponse) Format() string {
 	return ""
 }
+
+// Unpack decodes a request. The event bytes are optional; missing bytes
+// select no events. Both the threshold and the discrete fields sharing a mask
+// bit are set, since the request itself does not say which applies.
+func (req *RearmSensorEventsRequest) Unpack(msg []byte) error {
+	if len(msg) < 2 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
+	}
+	req.SensorNumber, _, _ = types.UnpackUint8(msg, 0)
+	b1, _, _ := types.UnpackUint8(msg, 1)
+	req.RearmAllEventStatus = !types.IsBit7Set(b1)
+
+	masks := make([]byte, 4)
+	copy(masks, msg[2:])
+	assert, _, _ := types.UnpackUint16L(masks, 0)
+	deassert, _, _ := types.UnpackUint16L(masks, 2)
+	req.SensorEventFlag.SetEventMasks(assert, deassert)
+	return nil
+}
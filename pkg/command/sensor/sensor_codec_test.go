@@ -0,0 +1,209 @@
+package sensor
+
+import (
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestGetSensorReadingCodecRoundTrip(t *testing.T) {
+	var req GetSensorReadingRequest
+	if err := req.Unpack((&GetSensorReadingRequest{SensorNumber: 0x42}).Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if req.SensorNumber != 0x42 {
+		t.Fatalf("SensorNumber: got %#02x", req.SensorNumber)
+	}
+
+	resOrig := &GetSensorReadingResponse{Reading: 0x55, SensorScanningDisabled: true, Above_UCR: true, Above_UNC: true}
+	resOrig.ActiveStates.State_9 = true
+	var res GetSensorReadingResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res.Reading != 0x55 || res.EventMessagesDisabled || !res.SensorScanningDisabled || res.ReadingUnavailable {
+		t.Fatalf("flags: %+v", res)
+	}
+	if res.ThresholdStatus() != types.SensorThresholdStatus_UCR || !res.ActiveStates.State_9 {
+		t.Fatalf("status: %+v", res)
+	}
+}
+
+func TestSensorThresholdsCodecRoundTrip(t *testing.T) {
+	setOrig := &SetSensorThresholdsRequest{SensorNumber: 3, SetUNC: true, SetLCR: true, UNC_Raw: 0x70, LCR_Raw: 0x10}
+	var set SetSensorThresholdsRequest
+	if err := set.Unpack(setOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if set != *setOrig {
+		t.Fatalf("request mismatch: %+v vs %+v", setOrig, set)
+	}
+
+	resOrig := &GetSensorThresholdsResponse{UNC_Readable: true, LNR_Readable: true, UNC_Raw: 0x70, LNR_Raw: 0x02}
+	var res GetSensorThresholdsResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res != *resOrig {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+}
+
+func TestSensorHysteresisCodecRoundTrip(t *testing.T) {
+	setOrig := &SetSensorHysteresisRequest{SensorNumber: 7, PositiveHysteresis: 2, NegativeHysteresis: 3}
+	var set SetSensorHysteresisRequest
+	if err := set.Unpack(setOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if set != *setOrig {
+		t.Fatalf("request mismatch: %+v vs %+v", setOrig, set)
+	}
+
+	var res GetSensorHysteresisResponse
+	if err := res.Unpack((&GetSensorHysteresisResponse{PositiveRaw: 4, NegativeRaw: 5}).Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res.PositiveRaw != 4 || res.NegativeRaw != 5 {
+		t.Fatalf("response: %+v", res)
+	}
+}
+
+func TestSetSensorEventEnableCodec(t *testing.T) {
+	reqOrig := &SetSensorEventEnableRequest{SensorNumber: 9, DisableSensorScanning: true, Mode: SetSensorEventEnableModeEnable}
+	reqOrig.SensorEvent_UCR_High_Assert = true
+	reqOrig.SensorEvent_LNC_Low_Deassert = true
+	msg := reqOrig.Pack()
+	// v2.0§35.10: [7] 0b = disable all event messages, [6] 0b = disable scanning.
+	if msg[1] != 0x90 {
+		t.Fatalf("byte 2: want 0x90, got %#02x", msg[1])
+	}
+
+	var req SetSensorEventEnableRequest
+	if err := req.Unpack(msg); err != nil {
+		t.Fatal(err)
+	}
+	if req.DisableEventMessages || !req.DisableSensorScanning || req.Mode != SetSensorEventEnableModeEnable {
+		t.Fatalf("flags: %+v", req)
+	}
+	assert, deassert := req.SensorEventFlag.EventMasks()
+	if assert != 1<<9 || deassert != 1<<0 {
+		t.Fatalf("masks: assert=%#04x deassert=%#04x", assert, deassert)
+	}
+
+	// The event bytes are optional.
+	if err := req.Unpack([]byte{9, 0x80}); err != nil {
+		t.Fatal(err)
+	}
+	if assert, deassert = req.SensorEventFlag.EventMasks(); assert != 0 || deassert != 0 {
+		t.Fatalf("masks without event bytes: %#04x %#04x", assert, deassert)
+	}
+}
+
+func TestSensorEventResponsesCodecRoundTrip(t *testing.T) {
+	enableOrig := &GetSensorEventEnableResponse{SensorScanningDisabled: true}
+	enableOrig.SensorEventFlag.SetEventMasks(0x0a80, 0x0001)
+	var enable GetSensorEventEnableResponse
+	if err := enable.Unpack(enableOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if enable != *enableOrig {
+		t.Fatalf("event enable mismatch: %+v vs %+v", enableOrig, enable)
+	}
+
+	statusOrig := &GetSensorEventStatusResponse{ReadingUnavailable: true}
+	statusOrig.SensorEventFlag.SetEventMasks(0x4000, 0)
+	var status GetSensorEventStatusResponse
+	if err := status.Unpack(statusOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if status != *statusOrig {
+		t.Fatalf("event status mismatch: %+v vs %+v", statusOrig, status)
+	}
+
+	rearmOrig := &RearmSensorEventsRequest{SensorNumber: 1}
+	rearmOrig.SensorEvent_UNR_High_Assert = true
+	var rearm RearmSensorEventsRequest
+	if err := rearm.Unpack(rearmOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if rearm.RearmAllEventStatus || !rearm.SensorEvent_UNR_High_Assert {
+		t.Fatalf("re-arm: %+v", rearm)
+	}
+	if err := rearm.Unpack((&RearmSensorEventsRequest{SensorNumber: 1, RearmAllEventStatus: true}).Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if !rearm.RearmAllEventStatus {
+		t.Fatal("re-arm all not decoded")
+	}
+}
+
+func TestGetSensorTypeAndFactorsCodecRoundTrip(t *testing.T) {
+	typOrig := &GetSensorTypeResponse{SensorType: types.SensorTypeVoltage, EventReadingType: types.EventReadingTypeThreshold}
+	var typ GetSensorTypeResponse
+	if err := typ.Unpack(typOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if typ != *typOrig {
+		t.Fatalf("sensor type mismatch: %+v vs %+v", typOrig, typ)
+	}
+
+	factorsOrig := &GetSensorReadingFactorsResponse{
+		NextReading: 0xff,
+		ReadingFactors: types.ReadingFactors{
+			M: -300, B: 411, B_Exp: -2, R_Exp: 3,
+			Tolerance: 0x2a, Accuracy: 0x3c5, Accuracy_Exp: 2,
+		},
+	}
+	var factors GetSensorReadingFactorsResponse
+	if err := factors.Unpack(factorsOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if factors != *factorsOrig {
+		t.Fatalf("reading factors mismatch: %+v vs %+v", factorsOrig, factors)
+	}
+}
+
+func TestAlertImmediateCodecRoundTrip(t *testing.T) {
+	reqOrig := &AlertImmediateRequest{
+		ChannelNumber:       1,
+		DestinationSelector: 3,
+		Operation:           uint8(AlertImmediateOperationInitiateAlert),
+		SendAlertString:     true,
+		AlertStringSelector: 2,
+		WithPlatformEvent:   true,
+		GeneratorID:         0x41,
+		EvMRev:              0x04,
+		SensorType:          types.SensorTypeTemperature,
+		SensorNumber:        0x10,
+		EventDir:            types.EventDirDeassertion,
+		EventReadingType:    types.EventReadingTypeThreshold,
+		EventData:           types.EventData{EventData1: 0x59, EventData2: 95, EventData3: 90},
+	}
+	msg := reqOrig.Pack()
+	if len(msg) != 11 || msg[1] != 0x03 || msg[2] != 0x82 || msg[7] != 0x81 {
+		t.Fatalf("request bytes: % x", msg)
+	}
+	var req AlertImmediateRequest
+	if err := req.Unpack(msg); err != nil {
+		t.Fatal(err)
+	}
+	if req != *reqOrig {
+		t.Fatalf("request mismatch: %+v vs %+v", reqOrig, req)
+	}
+
+	// The Platform Event Message fields are optional.
+	if err := req.Unpack([]byte{0x01, 0x40, 0x00}); err != nil {
+		t.Fatal(err)
+	}
+	if req.WithPlatformEvent || req.Operation != uint8(AlertImmediateOperationGetStatus) {
+		t.Fatalf("status request: %+v", req)
+	}
+
+	var res AlertImmediateResponse
+	if err := res.Unpack((&AlertImmediateResponse{AlertImmediateStatus: uint8(AlertImmediateStatusFailedWaitACK)}).Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res.AlertImmediateStatus != uint8(AlertImmediateStatusFailedWaitACK) {
+		t.Fatalf("response: %+v", res)
+	}
+}
@@ -53,6 +53,22 @@ func (req *SetBMCGlobalEnablesRequest) Pack() []byte {
 	return []byte{b}
 }
 
+func (req *SetBMCGlobalEnablesRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+
+	b, _, _ := types.UnpackUint8(msg, 0)
+	req.EnableOEM2 = types.IsBit7Set(b)
+	req.EnableOEM1 = types.IsBit6Set(b)
+	req.EnableOEM0 = types.IsBit5Set(b)
+	req.EnableSystemEventLogging = types.IsBit3Set(b)
+	req.EnableEventMessageBuffer = types.IsBit2Set(b)
+	req.EnableEventMessageBufferFullInterrupt = types.IsBit1Set(b)
+	req.EnableReceiveMessageQueueInterrupt = types.IsBit0Set(b)
+	return nil
+}
+
 func (res *SetBMCGlobalEnablesResponse) Unpack(msg []byte) error {
 	return nil
 }
@@ -42,3 +42,12 @@ func (res *SetLastProcessedEventIdResponse) Unpack(msg []byte) error {
 func (res *SetLastProcessedEventIdResponse) Format() string {
 	return ""
 }
+
+func (req *SetLastProcessedEventIdRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+	req.ByBMC = types.IsBit0Set(msg[0])
+	req.RecordID, _, _ = types.UnpackUint16L(msg, 1)
+	return nil
+}
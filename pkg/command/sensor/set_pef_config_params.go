@@ -38,3 +38,12 @@ func (res *SetPEFConfigParamResponse) Unpack(msg []byte) error {
 func (res *SetPEFConfigParamResponse) Format() string {
 	return ""
 }
+
+func (req *SetPEFConfigParamRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	req.ParamSelector = types.PEFConfigParamSelector(msg[0] & 0x7f)
+	req.ParamData, _, _ = types.UnpackBytes(msg, 1, len(msg)-1)
+	return nil
+}
@@ -43,8 +43,10 @@ func (req *SetSensorEventEnableRequest) Pack() []byte {
 
 	var b1 uint8
 	b1 = (uint8(req.Mode) & 0x03) << 4
-	b1 = types.SetOrClearBit7(b1, req.DisableEventMessages)
-	b1 = types.SetOrClearBit6(b1, req.DisableSensorScanning)
+	// [7] - 0b = disable all Event Messages from this sensor
+	// [6] - 0b = disable scanning on this sensor
+	b1 = types.SetOrClearBit7(b1, !req.DisableEventMessages)
+	b1 = types.SetOrClearBit6(b1, !req.DisableSensorScanning)
 	out[1] = b1
 
 	if req.DisableEventMessages {
@@ -121,6 +123,27 @@ func (req *SetSensorEventEnableRequest) Pack() []byte {
 	return out
 }
 
+// Unpack decodes a request. The event bytes are optional; missing bytes
+// select no events. Both the threshold and the discrete fields sharing a mask
+// bit are set, since the request itself does not say which applies.
+func (req *SetSensorEventEnableRequest) Unpack(msg []byte) error {
+	if len(msg) < 2 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
+	}
+	req.SensorNumber, _, _ = types.UnpackUint8(msg, 0)
+	b1, _, _ := types.UnpackUint8(msg, 1)
+	req.DisableEventMessages = !types.IsBit7Set(b1)
+	req.DisableSensorScanning = !types.IsBit6Set(b1)
+	req.Mode = SetSensorEventEnableMode((b1 >> 4) & 0x03)
+
+	masks := make([]byte, 4)
+	copy(masks, msg[2:])
+	assert, _, _ := types.UnpackUint16L(masks, 0)
+	deassert, _, _ := types.UnpackUint16L(masks, 2)
+	req.SensorEventFlag.SetEventMasks(assert, deassert)
+	return nil
+}
+
 func (res *SetSensorEventEnableResponse) Unpack(msg []byte) error {
 	return nil
 }
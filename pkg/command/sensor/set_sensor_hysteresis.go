@@ -35,3 +35,13 @@ func (res *SetSensorHysteresisResponse) Unpack(msg []byte) error {
 func (res *SetSensorHysteresisResponse) Format() string {
 	return ""
 }
+
+func (req *SetSensorHysteresisRequest) Unpack(msg []byte) error {
+	if len(msg) < 4 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
+	}
+	req.SensorNumber, _, _ = types.UnpackUint8(msg, 0)
+	req.PositiveHysteresis, _, _ = types.UnpackUint8(msg, 2)
+	req.NegativeHysteresis, _, _ = types.UnpackUint8(msg, 3)
+	return nil
+}
@@ -90,3 +90,26 @@ func (res *SetSensorThresholdsResponse) Unpack(msg []byte) error {
 func (res *SetSensorThresholdsResponse) Format() string {
 	return ""
 }
+
+func (req *SetSensorThresholdsRequest) Unpack(msg []byte) error {
+	if len(msg) < 8 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 8)
+	}
+	req.SensorNumber, _, _ = types.UnpackUint8(msg, 0)
+
+	b, _, _ := types.UnpackUint8(msg, 1)
+	req.SetUNR = types.IsBit5Set(b)
+	req.SetUCR = types.IsBit4Set(b)
+	req.SetUNC = types.IsBit3Set(b)
+	req.SetLNR = types.IsBit2Set(b)
+	req.SetLCR = types.IsBit1Set(b)
+	req.SetLNC = types.IsBit0Set(b)
+
+	req.LNC_Raw, _, _ = types.UnpackUint8(msg, 2)
+	req.LCR_Raw, _, _ = types.UnpackUint8(msg, 3)
+	req.LNR_Raw, _, _ = types.UnpackUint8(msg, 4)
+	req.UNC_Raw, _, _ = types.UnpackUint8(msg, 5)
+	req.UCR_Raw, _, _ = types.UnpackUint8(msg, 6)
+	req.UNR_Raw, _, _ = types.UnpackUint8(msg, 7)
+	return nil
+}
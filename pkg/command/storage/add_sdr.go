@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// 33.13 Add SDR Command
+type AddSDRRequest struct {
+	// RecordData is the whole SDR, header included. The Record ID in the
+	// header is ignored; the BMC assigns one.
+	RecordData []byte
+}
+
+type AddSDRResponse struct {
+	RecordID uint16 // Record ID for added record, LS Byte first
+}
+
+func (req *AddSDRRequest) Command() types.Command {
+	return types.CommandAddSDR
+}
+
+func (req *AddSDRRequest) Pack() []byte {
+	return append([]byte(nil), req.RecordData...)
+}
+
+// Unpack parses an Add SDR request body (§33.13), the inverse of
+// [AddSDRRequest.Pack].
+func (req *AddSDRRequest) Unpack(msg []byte) error {
+	if len(msg) < types.SDRRecordHeaderSize {
+		return types.ErrUnpackedDataTooShortWith(len(msg), types.SDRRecordHeaderSize)
+	}
+	req.RecordData = append([]byte(nil), msg...)
+	return nil
+}
+
+func (res *AddSDRResponse) Pack() []byte {
+	out := make([]byte, 2)
+	types.PackUint16L(res.RecordID, out, 0)
+	return out
+}
+
+func (res *AddSDRResponse) Unpack(msg []byte) error {
+	if len(msg) < 2 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
+	}
+	res.RecordID, _, _ = types.UnpackUint16L(msg, 0)
+	return nil
+}
+
+func (res *AddSDRResponse) Format() string {
+	return fmt.Sprintf("Record ID : %d (%#02x)", res.RecordID, res.RecordID)
+}
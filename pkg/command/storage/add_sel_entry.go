@@ -23,6 +23,12 @@ func (req *AddSELEntryRequest) Pack() []byte {
 	return req.SEL.Pack()
 }
 
+func (res *AddSELEntryResponse) Pack() []byte {
+	out := make([]byte, 2)
+	types.PackUint16L(res.RecordID, out, 0)
+	return out
+}
+
 func (res *AddSELEntryResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// 33.16 Clear SDR Repository Command
+type ClearSDRRepoRequest struct {
+	ReservationID        uint16 // LS Byte first
+	GetErasureStatusFlag bool
+}
+
+type ClearSDRRepoResponse struct {
+	// ErasureProgressStatus [3:0]: 0h = erasure in progress, 1h = erase
+	// completed.
+	ErasureProgressStatus uint8
+}
+
+func (req *ClearSDRRepoRequest) Pack() []byte {
+	var out = make([]byte, 6)
+	types.PackUint16L(req.ReservationID, out, 0)
+	types.PackUint8('C', out, 2) // fixed 'C' char
+	types.PackUint8('L', out, 3) // fixed 'L' char
+	types.PackUint8('R', out, 4) // fixed 'R' char
+	if req.GetErasureStatusFlag {
+		types.PackUint8(0x00, out, 5) //  get erasure status
+	} else {
+		types.PackUint8(0xaa, out, 5) //  initiate erase
+	}
+	return out
+}
+
+// Unpack rejects requests without the 'CLR' confirmation or with an action
+// byte other than 00h (get erasure status) or AAh (initiate erase).
+func (req *ClearSDRRepoRequest) Unpack(msg []byte) error {
+	if len(msg) < 6 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 6)
+	}
+	req.ReservationID, _, _ = types.UnpackUint16L(msg, 0)
+	if msg[2] != 'C' || msg[3] != 'L' || msg[4] != 'R' {
+		return errors.New("clear SDR repository: missing 'CLR' confirmation")
+	}
+	switch msg[5] {
+	case 0x00:
+		req.GetErasureStatusFlag = true
+	case 0xaa:
+		req.GetErasureStatusFlag = false
+	default:
+		return fmt.Errorf("clear SDR repository: invalid action %#02x", msg[5])
+	}
+	return nil
+}
+
+func (req *ClearSDRRepoRequest) Command() types.Command {
+	return types.CommandClearSDRRepo
+}
+
+func (res *ClearSDRRepoResponse) Pack() []byte {
+	return []byte{res.ErasureProgressStatus}
+}
+
+func (res *ClearSDRRepoResponse) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+
+	res.ErasureProgressStatus, _, _ = types.UnpackUint8(msg, 0)
+	return nil
+}
+
+func (res *ClearSDRRepoResponse) Format() string {
+	return fmt.Sprintf("%v", res)
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/bougou/go-ipmi/pkg/types"
@@ -30,10 +31,35 @@ func (req *ClearSELRequest) Pack() []byte {
 	return out
 }
 
+// Unpack rejects requests without the 'CLR' confirmation or with an action
+// byte other than 00h (get erasure status) or AAh (initiate erase).
+func (req *ClearSELRequest) Unpack(msg []byte) error {
+	if len(msg) < 6 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 6)
+	}
+	req.ReservationID, _, _ = types.UnpackUint16L(msg, 0)
+	if msg[2] != 'C' || msg[3] != 'L' || msg[4] != 'R' {
+		return errors.New("clear SEL: missing 'CLR' confirmation")
+	}
+	switch msg[5] {
+	case 0x00:
+		req.GetErasureStatusFlag = true
+	case 0xaa:
+		req.GetErasureStatusFlag = false
+	default:
+		return fmt.Errorf("clear SEL: invalid action %#02x", msg[5])
+	}
+	return nil
+}
+
 func (req *ClearSELRequest) Command() types.Command {
 	return types.CommandClearSEL
 }
 
+func (res *ClearSELResponse) Pack() []byte {
+	return []byte{res.ErasureProgressStatus}
+}
+
 func (res *ClearSELResponse) Unpack(msg []byte) error {
 	if len(msg) < 1 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// 33.15 Delete SDR Command
+type DeleteSDRRequest struct {
+	ReservationID uint16
+	RecordID      uint16
+}
+
+type DeleteSDRResponse struct {
+	RecordID uint16
+}
+
+func (req *DeleteSDRRequest) Command() types.Command {
+	return types.CommandDeleteSDR
+}
+
+func (req *DeleteSDRRequest) Pack() []byte {
+	out := make([]byte, 4)
+	types.PackUint16L(req.ReservationID, out, 0)
+	types.PackUint16L(req.RecordID, out, 2)
+	return out
+}
+
+func (req *DeleteSDRRequest) Unpack(msg []byte) error {
+	if len(msg) < 4 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
+	}
+	req.ReservationID, _, _ = types.UnpackUint16L(msg, 0)
+	req.RecordID, _, _ = types.UnpackUint16L(msg, 2)
+	return nil
+}
+
+func (res *DeleteSDRResponse) Pack() []byte {
+	out := make([]byte, 2)
+	types.PackUint16L(res.RecordID, out, 0)
+	return out
+}
+
+func (res *DeleteSDRResponse) Unpack(msg []byte) error {
+	if len(msg) < 2 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
+	}
+	res.RecordID, _, _ = types.UnpackUint16L(msg, 0)
+	return nil
+}
+
+func (res *DeleteSDRResponse) Format() string {
+	return fmt.Sprintf("Record ID : %d (%#02x)", res.RecordID, res.RecordID)
+}
@@ -27,6 +27,21 @@ func (req *DeleteSELEntryRequest) Pack() []byte {
 	return out
 }
 
+func (req *DeleteSELEntryRequest) Unpack(msg []byte) error {
+	if len(msg) < 4 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
+	}
+	req.ReservationID, _, _ = types.UnpackUint16L(msg, 0)
+	req.RecordID, _, _ = types.UnpackUint16L(msg, 2)
+	return nil
+}
+
+func (res *DeleteSELEntryResponse) Pack() []byte {
+	out := make([]byte, 2)
+	types.PackUint16L(res.RecordID, out, 0)
+	return out
+}
+
 func (res *DeleteSELEntryResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// 33.19 Enter SDR Repository Update Mode Command
+type EnterSDRRepoUpdateModeRequest struct {
+	// empty
+}
+
+type EnterSDRRepoUpdateModeResponse struct {
+	// empty
+}
+
+func (req *EnterSDRRepoUpdateModeRequest) Command() types.Command {
+	return types.CommandEnterSDRRepoUpdateMode
+}
+
+func (req *EnterSDRRepoUpdateModeRequest) Pack() []byte {
+	return []byte{}
+}
+
+func (res *EnterSDRRepoUpdateModeResponse) Unpack(msg []byte) error {
+	return nil
+}
+
+func (res *EnterSDRRepoUpdateModeResponse) Format() string {
+	return ""
+}
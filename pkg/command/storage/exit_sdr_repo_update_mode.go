@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// 33.20 Exit SDR Repository Update Mode Command
+type ExitSDRRepoUpdateModeRequest struct {
+	// empty
+}
+
+type ExitSDRRepoUpdateModeResponse struct {
+	// empty
+}
+
+func (req *ExitSDRRepoUpdateModeRequest) Command() types.Command {
+	return types.CommandExitSDRRepoUpdateMode
+}
+
+func (req *ExitSDRRepoUpdateModeRequest) Pack() []byte {
+	return []byte{}
+}
+
+func (res *ExitSDRRepoUpdateModeResponse) Unpack(msg []byte) error {
+	return nil
+}
+
+func (res *ExitSDRRepoUpdateModeResponse) Format() string {
+	return ""
+}
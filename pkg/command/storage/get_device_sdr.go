@@ -33,6 +33,26 @@ func (req *GetDeviceSDRRequest) Pack() []byte {
 	return out
 }
 
+func (req *GetDeviceSDRRequest) Unpack(msg []byte) error {
+	if len(msg) < 6 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 6)
+	}
+	req.ReservationID, _, _ = types.UnpackUint16L(msg, 0)
+	req.RecordID, _, _ = types.UnpackUint16L(msg, 2)
+	req.ReadOffset, _, _ = types.UnpackUint8(msg, 4)
+	req.ReadBytes, _, _ = types.UnpackUint8(msg, 5)
+	return nil
+}
+
+func (res *GetDeviceSDRResponse) Pack() []byte {
+	out := make([]byte, 2+len(res.RecordData))
+	types.PackUint16L(res.NextRecordID, out, 0)
+	if len(res.RecordData) > 0 {
+		types.PackBytes(res.RecordData, out, 2)
+	}
+	return out
+}
+
 func (res *GetDeviceSDRResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
@@ -49,6 +49,40 @@ func (req *GetDeviceSDRInfoRequest) Pack() []byte {
 	return []byte{b}
 }
 
+// Unpack accepts the request without data that IPMI v1.0 devices send,
+// which asks for the sensor count.
+func (req *GetDeviceSDRInfoRequest) Unpack(msg []byte) error {
+	req.GetSDRCount = len(msg) > 0 && types.IsBit0Set(msg[0])
+	return nil
+}
+
+func (res *GetDeviceSDRInfoResponse) Pack() []byte {
+	var b uint8
+	if res.DynamicSensorPopulation {
+		b = types.SetBit7(b)
+	}
+	if res.LUN3HasSensors {
+		b = types.SetBit3(b)
+	}
+	if res.LUN2HasSensors {
+		b = types.SetBit2(b)
+	}
+	if res.LUN1HasSensors {
+		b = types.SetBit1(b)
+	}
+	if res.LUN0HasSensors {
+		b = types.SetBit0(b)
+	}
+	if !res.DynamicSensorPopulation {
+		return []byte{res.Count, b}
+	}
+	out := make([]byte, 6)
+	types.PackUint8(res.Count, out, 0)
+	types.PackUint8(b, out, 1)
+	types.PackUint32L(res.SensorPopulationChangeIndicator, out, 2)
+	return out
+}
+
 func (res *GetDeviceSDRInfoResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
@@ -41,6 +41,26 @@ func (req *GetSELEntryRequest) Pack() []byte {
 	return msg
 }
 
+func (req *GetSELEntryRequest) Unpack(msg []byte) error {
+	if len(msg) < 6 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 6)
+	}
+	req.ReservationID, _, _ = types.UnpackUint16L(msg, 0)
+	req.RecordID, _, _ = types.UnpackUint16L(msg, 2)
+	req.Offset, _, _ = types.UnpackUint8(msg, 4)
+	req.ReadBytes, _, _ = types.UnpackUint8(msg, 5)
+	return nil
+}
+
+func (res *GetSELEntryResponse) Pack() []byte {
+	out := make([]byte, 2+len(res.Data))
+	types.PackUint16L(res.NextRecordID, out, 0)
+	if len(res.Data) > 0 {
+		types.PackBytes(res.Data, out, 2)
+	}
+	return out
+}
+
 func (res *GetSELEntryResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
@@ -38,6 +38,34 @@ func (req *GetSELInfoRequest) Pack() []byte {
 	return []byte{}
 }
 
+func (res *GetSELInfoResponse) Pack() []byte {
+	out := make([]byte, 14)
+	types.PackUint8(res.SELVersion, out, 0)
+	types.PackUint16L(res.Entries, out, 1)
+	types.PackUint16L(res.FreeBytes, out, 3)
+	// LS-byte-first unsigned 32-bit Unix timestamp per §31.2; wraps at 2106-02-07.
+	types.PackUint32L(uint32(res.RecentAdditionTime.Unix()), out, 5)
+	types.PackUint32L(uint32(res.RecentEraseTime.Unix()), out, 9)
+	var b uint8
+	if res.OperationSupport.Overflow {
+		b = types.SetBit7(b)
+	}
+	if res.OperationSupport.DeleteSEL {
+		b = types.SetBit3(b)
+	}
+	if res.OperationSupport.PartialAddSEL {
+		b = types.SetBit2(b)
+	}
+	if res.OperationSupport.ReserveSEL {
+		b = types.SetBit1(b)
+	}
+	if res.OperationSupport.GetSELAllocInfo {
+		b = types.SetBit0(b)
+	}
+	types.PackUint8(b, out, 13)
+	return out
+}
+
 func (res *GetSELInfoResponse) Unpack(msg []byte) error {
 	if len(msg) < 14 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 14)
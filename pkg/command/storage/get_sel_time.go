@@ -25,6 +25,12 @@ func (req *GetSELTimeRequest) Command() types.Command {
 	return types.CommandGetSELTime
 }
 
+func (res *GetSELTimeResponse) Pack() []byte {
+	out := make([]byte, 4)
+	types.PackUint32L(uint32(res.Time.Unix()), out, 0)
+	return out
+}
+
 func (res *GetSELTimeResponse) Unpack(msg []byte) error {
 	if len(msg) < 4 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
@@ -24,6 +24,13 @@ func (req *GetSELTimeUTCOffsetRequest) Command() types.Command {
 	return types.CommandGetSELTimeUTCOffset
 }
 
+func (res *GetSELTimeUTCOffsetResponse) Pack() []byte {
+	out := make([]byte, 2)
+	a := types.TwoSComplementEncode(int32(res.MinutesOffset), 16)
+	types.PackUint16L(uint16(a), out, 0)
+	return out
+}
+
 func (res *GetSELTimeUTCOffsetResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
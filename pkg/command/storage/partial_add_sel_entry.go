@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// 31.7 Partial Add SEL Entry Command
+type PartialAddSELEntryRequest struct {
+	ReservationID uint16 // LS Byte first
+	RecordID      uint16 // 0000h for the first partial add of a record
+	Offset        uint8  // Offset into record
+	LastPart      bool   // In progress [3:0]: 1h = last record data being transferred
+	RecordData    []byte
+}
+
+type PartialAddSELEntryResponse struct {
+	RecordID uint16 // Record ID for added record, LS Byte first
+}
+
+func (req *PartialAddSELEntryRequest) Command() types.Command {
+	return types.CommandPartialAddSELEntry
+}
+
+func (req *PartialAddSELEntryRequest) Pack() []byte {
+	out := make([]byte, 6+len(req.RecordData))
+	types.PackUint16L(req.ReservationID, out, 0)
+	types.PackUint16L(req.RecordID, out, 2)
+	types.PackUint8(req.Offset, out, 4)
+	if req.LastPart {
+		types.PackUint8(0x01, out, 5)
+	}
+	if len(req.RecordData) > 0 {
+		types.PackBytes(req.RecordData, out, 6)
+	}
+	return out
+}
+
+func (req *PartialAddSELEntryRequest) Unpack(msg []byte) error {
+	if len(msg) < 6 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 6)
+	}
+	req.ReservationID, _, _ = types.UnpackUint16L(msg, 0)
+	req.RecordID, _, _ = types.UnpackUint16L(msg, 2)
+	req.Offset, _, _ = types.UnpackUint8(msg, 4)
+	req.LastPart = msg[5]&0x0f == 0x01
+	req.RecordData, _, _ = types.UnpackBytes(msg, 6, len(msg)-6)
+	return nil
+}
+
+func (res *PartialAddSELEntryResponse) Pack() []byte {
+	out := make([]byte, 2)
+	types.PackUint16L(res.RecordID, out, 0)
+	return out
+}
+
+func (res *PartialAddSELEntryResponse) Unpack(msg []byte) error {
+	if len(msg) < 2 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
+	}
+	res.RecordID, _, _ = types.UnpackUint16L(msg, 0)
+	return nil
+}
+
+func (res *PartialAddSELEntryResponse) Format() string {
+	return fmt.Sprintf("Record ID : %d (%#02x)", res.RecordID, res.RecordID)
+}
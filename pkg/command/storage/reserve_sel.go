@@ -21,6 +21,12 @@ func (req *ReserveSELRequest) Pack() []byte {
 	return nil
 }
 
+func (res *ReserveSELResponse) Pack() []byte {
+	out := make([]byte, 2)
+	types.PackUint16L(res.ReservationID, out, 0)
+	return out
+}
+
 func (res *ReserveSELResponse) Unpack(msg []byte) error {
 	if len(msg) < 2 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
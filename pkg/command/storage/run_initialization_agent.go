@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// 33.21 Run Initialization Agent Command
+type RunInitializationAgentRequest struct {
+	// GetStatus only reports the initialization status; otherwise the
+	// initialization agent is run.
+	GetStatus bool
+}
+
+type RunInitializationAgentResponse struct {
+	Completed bool
+}
+
+func (req *RunInitializationAgentRequest) Command() types.Command {
+	return types.CommandRunInitializationAgent
+}
+
+func (req *RunInitializationAgentRequest) Pack() []byte {
+	if req.GetStatus {
+		return []byte{0x00}
+	}
+	return []byte{0x01}
+}
+
+func (req *RunInitializationAgentRequest) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	req.GetStatus = !types.IsBit0Set(msg[0])
+	return nil
+}
+
+func (res *RunInitializationAgentResponse) Pack() []byte {
+	if res.Completed {
+		return []byte{0x01}
+	}
+	return []byte{0x00}
+}
+
+func (res *RunInitializationAgentResponse) Unpack(msg []byte) error {
+	if len(msg) < 1 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
+	}
+	res.Completed = types.IsBit0Set(msg[0])
+	return nil
+}
+
+func (res *RunInitializationAgentResponse) Format() string {
+	return fmt.Sprintf("Initialization completed : %v", res.Completed)
+}
@@ -21,6 +21,15 @@ func (req *SetSELTimeRequest) Pack() []byte {
 	return out
 }
 
+func (req *SetSELTimeRequest) Unpack(msg []byte) error {
+	if len(msg) < 4 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 4)
+	}
+	t, _, _ := types.UnpackUint32L(msg, 0)
+	req.Time = types.ParseTimestamp(t)
+	return nil
+}
+
 func (req *SetSELTimeRequest) Command() types.Command {
 	return types.CommandSetSELTime
 }
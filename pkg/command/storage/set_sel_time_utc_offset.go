@@ -23,6 +23,15 @@ func (req *SetSELTimeUTCOffsetRequest) Pack() []byte {
 	return out
 }
 
+func (req *SetSELTimeUTCOffsetRequest) Unpack(msg []byte) error {
+	if len(msg) < 2 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 2)
+	}
+	b, _, _ := types.UnpackUint16L(msg, 0)
+	req.MinutesOffset = int16(types.TwoSComplement(uint32(b), 16))
+	return nil
+}
+
 func (req *SetSELTimeUTCOffsetRequest) Command() types.Command {
 	return types.CommandSetSELTimeUTCOffset
 }
@@ -138,3 +138,192 @@ func TestGetSDRRequestUnpackTruncated(t *testing.T) {
 		t.Fatal("expected error for truncated request")
 	}
 }
+
+func TestPartialAddSDRCodecRoundTrip(t *testing.T) {
+	reqOrig := &PartialAddSDRRequest{
+		ReservationID: 0x0003,
+		RecordID:      0x0010,
+		Offset:        5,
+		LastPart:      true,
+		RecordData:    []byte{0x01, 0x02},
+	}
+	var req PartialAddSDRRequest
+	if err := req.Unpack(reqOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if req.ReservationID != reqOrig.ReservationID || req.RecordID != reqOrig.RecordID ||
+		req.Offset != reqOrig.Offset || req.LastPart != reqOrig.LastPart || len(req.RecordData) != 2 {
+		t.Fatalf("request mismatch: %+v vs %+v", reqOrig, req)
+	}
+
+	resOrig := &PartialAddSDRResponse{RecordID: 0x0010}
+	var res PartialAddSDRResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res != *resOrig {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+
+	var add AddSDRRequest
+	if err := add.Unpack([]byte{0x00, 0x00, 0x51}); err == nil {
+		t.Fatal("expected error for an Add SDR record shorter than its header")
+	}
+}
+
+func TestClearSDRRepoRequestUnpack(t *testing.T) {
+	for _, status := range []bool{false, true} {
+		reqOrig := &ClearSDRRepoRequest{ReservationID: 0x0042, GetErasureStatusFlag: status}
+		var req ClearSDRRepoRequest
+		if err := req.Unpack(reqOrig.Pack()); err != nil {
+			t.Fatal(err)
+		}
+		if req != *reqOrig {
+			t.Fatalf("request mismatch: %+v vs %+v", reqOrig, req)
+		}
+	}
+
+	msg := (&ClearSDRRepoRequest{}).Pack()
+	msg[3] = 'X'
+	var req ClearSDRRepoRequest
+	if err := req.Unpack(msg); err == nil {
+		t.Fatal("expected error without the 'CLR' confirmation")
+	}
+}
+
+func TestGetDeviceSDRInfoCodecRoundTrip(t *testing.T) {
+	for _, resOrig := range []*GetDeviceSDRInfoResponse{
+		{Count: 3, LUN0HasSensors: true, LUN2HasSensors: true},
+		{Count: 1, DynamicSensorPopulation: true, LUN1HasSensors: true, SensorPopulationChangeIndicator: 0x01020304},
+	} {
+		msg := resOrig.Pack()
+		var res GetDeviceSDRInfoResponse
+		if err := res.Unpack(msg); err != nil {
+			t.Fatal(err)
+		}
+		if res != *resOrig {
+			t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+		}
+		if !resOrig.DynamicSensorPopulation && len(msg) != 2 {
+			t.Fatalf("static population sends a change indicator: % x", msg)
+		}
+	}
+
+	var req GetDeviceSDRInfoRequest
+	if err := req.Unpack(nil); err != nil || req.GetSDRCount {
+		t.Fatalf("v1.0 request without data: %+v %v", req, err)
+	}
+}
+
+func TestGetSELInfoCodecRoundTrip(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	resOrig := &GetSELInfoResponse{
+		SELVersion:         0x51,
+		Entries:            3,
+		FreeBytes:          4096,
+		RecentAdditionTime: ts,
+		RecentEraseTime:    ts,
+		OperationSupport: SELOperationSupport{
+			Overflow:   true,
+			DeleteSEL:  true,
+			ReserveSEL: true,
+		},
+	}
+	var res GetSELInfoResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res.Entries != resOrig.Entries || res.FreeBytes != resOrig.FreeBytes || !res.RecentAdditionTime.Equal(ts) {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+	if res.OperationSupport != resOrig.OperationSupport {
+		t.Fatalf("OperationSupport: want %+v, got %+v", resOrig.OperationSupport, res.OperationSupport)
+	}
+}
+
+func TestGetSELEntryCodecRoundTrip(t *testing.T) {
+	reqOrig := &GetSELEntryRequest{ReservationID: 0x0102, RecordID: 0x0304, Offset: 2, ReadBytes: 0xff}
+	var req GetSELEntryRequest
+	if err := req.Unpack(reqOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if *reqOrig != req {
+		t.Fatalf("request mismatch: %+v vs %+v", reqOrig, req)
+	}
+
+	resOrig := &GetSELEntryResponse{NextRecordID: 0xffff, Data: make([]byte, 16)}
+	var res GetSELEntryResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res.NextRecordID != resOrig.NextRecordID || len(res.Data) != 16 {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+}
+
+func TestPartialAddSELEntryCodecRoundTrip(t *testing.T) {
+	reqOrig := &PartialAddSELEntryRequest{
+		ReservationID: 0x0001,
+		RecordID:      0x0002,
+		Offset:        8,
+		LastPart:      true,
+		RecordData:    []byte{0x01, 0x02, 0x03},
+	}
+	var req PartialAddSELEntryRequest
+	if err := req.Unpack(reqOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if req.ReservationID != reqOrig.ReservationID || req.RecordID != reqOrig.RecordID ||
+		req.Offset != reqOrig.Offset || req.LastPart != reqOrig.LastPart || len(req.RecordData) != 3 {
+		t.Fatalf("request mismatch: %+v vs %+v", reqOrig, req)
+	}
+
+	resOrig := &PartialAddSELEntryResponse{RecordID: 0x0002}
+	var res PartialAddSELEntryResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res != *resOrig {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+}
+
+func TestClearSELRequestUnpack(t *testing.T) {
+	for _, status := range []bool{false, true} {
+		reqOrig := &ClearSELRequest{ReservationID: 0x0042, GetErasureStatusFlag: status}
+		var req ClearSELRequest
+		if err := req.Unpack(reqOrig.Pack()); err != nil {
+			t.Fatal(err)
+		}
+		if req != *reqOrig {
+			t.Fatalf("request mismatch: %+v vs %+v", reqOrig, req)
+		}
+	}
+
+	msg := (&ClearSELRequest{}).Pack()
+	msg[5] = 0x55
+	var req ClearSELRequest
+	if err := req.Unpack(msg); err == nil {
+		t.Fatal("expected error for invalid action byte")
+	}
+}
+
+func TestSELTimeUTCOffsetCodecRoundTrip(t *testing.T) {
+	reqOrig := &SetSELTimeUTCOffsetRequest{MinutesOffset: -480}
+	var req SetSELTimeUTCOffsetRequest
+	if err := req.Unpack(reqOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if req != *reqOrig {
+		t.Fatalf("request mismatch: %+v vs %+v", reqOrig, req)
+	}
+
+	resOrig := &GetSELTimeUTCOffsetResponse{MinutesOffset: 0x07ff}
+	var res GetSELTimeUTCOffsetResponse
+	if err := res.Unpack(resOrig.Pack()); err != nil {
+		t.Fatal(err)
+	}
+	if res != *resOrig {
+		t.Fatalf("response mismatch: %+v vs %+v", resOrig, res)
+	}
+}
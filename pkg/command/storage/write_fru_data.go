@@ -32,6 +32,24 @@ func (req *WriteFRUDataRequest) Pack() []byte {
 	return out
 }
 
+// Unpack parses a Write FRU Data request body (§34.3), the inverse of
+// [WriteFRUDataRequest.Pack].
+func (req *WriteFRUDataRequest) Unpack(msg []byte) error {
+	if len(msg) < 3 {
+		return types.ErrUnpackedDataTooShortWith(len(msg), 3)
+	}
+	req.FRUDeviceID, _, _ = types.UnpackUint8(msg, 0)
+	req.WriteOffset, _, _ = types.UnpackUint16L(msg, 1)
+	req.WriteData, _, _ = types.UnpackBytes(msg, 3, len(msg)-3)
+	return nil
+}
+
+// Pack serialises the response (§34.3), the inverse of
+// [WriteFRUDataResponse.Unpack].
+func (res *WriteFRUDataResponse) Pack() []byte {
+	return []byte{res.CountWritten}
+}
+
 func (res *WriteFRUDataResponse) Unpack(msg []byte) error {
 	if len(msg) < 1 {
 		return types.ErrUnpackedDataTooShortWith(len(msg), 1)
@@ -0,0 +1,236 @@
+// Package filestore provides a [hal.StorageHAL] kept in a directory, so the
+// FRU, SDR and SEL data, the System Info Parameters and the BMC's
+// non-volatile state survive a restart of the process serving them, as they
+// survive a BMC restart on real hardware.
+//
+// Every blob is a file of its own, one subdirectory per store:
+//
+//	fru/<device ID>  sdr/<record ID>  sel/<record ID>
+//	sysinfo/<parameter selector>  state/<key>.json
+//
+// A write replaces its file atomically (written to a temporary file, synced
+// and renamed over it), so a crash leaves either the old blob or the new one.
+// Files are created readable by the owner only: the state holds the user
+// passwords.
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+)
+
+// ErrInvalidKey is returned by the state store for a key that is not a plain
+// file name.
+var ErrInvalidKey = errors.New("invalid state key")
+
+// Storage is a [hal.StorageHAL] in a directory. It also implements
+// [hal.SystemInfoStorageHAL] and [hal.StateStorageHAL].
+type Storage struct {
+	fru, sdr, sel, sys, state blobDir
+}
+
+// Open returns the storage in dir, creating the directory and its
+// subdirectories as needed.
+func Open(dir string) (*Storage, error) {
+	s := &Storage{
+		fru:   blobDir{path: filepath.Join(dir, "fru")},
+		sdr:   blobDir{path: filepath.Join(dir, "sdr")},
+		sel:   blobDir{path: filepath.Join(dir, "sel")},
+		sys:   blobDir{path: filepath.Join(dir, "sysinfo")},
+		state: blobDir{path: filepath.Join(dir, "state"), ext: ".json"},
+	}
+	for _, d := range []blobDir{s.fru, s.sdr, s.sel, s.sys, s.state} {
+		if err := os.MkdirAll(d.path, 0o700); err != nil {
+			return nil, fmt.Errorf("filestore: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func (s *Storage) FRU() hal.FRUStore { return fruStore{s.fru} }
+func (s *Storage) SDR() hal.SDRStore { return recordStore{s.sdr} }
+func (s *Storage) SEL() hal.SELStore { return recordStore{s.sel} }
+
+// SystemInfo implements [hal.SystemInfoStorageHAL].
+func (s *Storage) SystemInfo() hal.SystemInfoStore { return systemInfoStore{s.sys} }
+
+// State implements [hal.StateStorageHAL].
+func (s *Storage) State() hal.StateStore { return stateStore{s.state} }
+
+// blobDir is a directory of blobs, one file each.
+type blobDir struct {
+	path string
+	ext  string
+}
+
+func (d blobDir) file(name string) string {
+	return filepath.Join(d.path, name+d.ext)
+}
+
+func (d blobDir) read(name string) ([]byte, error) {
+	data, err := os.ReadFile(d.file(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, hal.ErrNotFound
+	}
+	return data, err
+}
+
+// write replaces the blob atomically.
+func (d blobDir) write(name string, data []byte) error {
+	f, err := os.CreateTemp(d.path, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, d.file(name))
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return d.sync()
+}
+
+func (d blobDir) remove(name string) error {
+	err := os.Remove(d.file(name))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return d.sync()
+}
+
+// sync makes the directory entries durable, so a rename or removal survives
+// a crash.
+func (d blobDir) sync() error {
+	f, err := os.Open(d.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
+
+// ids returns the numeric blob names up to limit, in ascending order. Other
+// files, such as the temporary files of interrupted writes, are skipped.
+func (d blobDir) ids(limit uint64) ([]uint64, error) {
+	entries, err := os.ReadDir(d.path)
+	if err != nil {
+		return nil, err
+	}
+	var out []uint64
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), d.ext)
+		if !ok || e.IsDir() {
+			continue
+		}
+		id, err := strconv.ParseUint(name, 10, 64)
+		if err != nil || id > limit {
+			continue
+		}
+		out = append(out, id)
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+type fruStore struct{ d blobDir }
+
+func (f fruStore) Read(_ context.Context, deviceID uint8) ([]byte, error) {
+	return f.d.read(strconv.Itoa(int(deviceID)))
+}
+
+func (f fruStore) Write(_ context.Context, deviceID uint8, data []byte) error {
+	return f.d.write(strconv.Itoa(int(deviceID)), data)
+}
+
+func (f fruStore) Delete(_ context.Context, deviceID uint8) error {
+	return f.d.remove(strconv.Itoa(int(deviceID)))
+}
+
+func (f fruStore) DeviceIDs(_ context.Context) ([]uint8, error) {
+	ids, err := f.d.ids(0xff)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]uint8, len(ids))
+	for i, id := range ids {
+		out[i] = uint8(id)
+	}
+	return out, nil
+}
+
+// recordStore is the SDR and the SEL store, both keyed by record ID.
+type recordStore struct{ d blobDir }
+
+func (r recordStore) Read(_ context.Context, recordID uint16) ([]byte, error) {
+	return r.d.read(strconv.Itoa(int(recordID)))
+}
+
+func (r recordStore) Write(_ context.Context, recordID uint16, data []byte) error {
+	return r.d.write(strconv.Itoa(int(recordID)), data)
+}
+
+func (r recordStore) Delete(_ context.Context, recordID uint16) error {
+	return r.d.remove(strconv.Itoa(int(recordID)))
+}
+
+func (r recordStore) RecordIDs(_ context.Context) ([]uint16, error) {
+	ids, err := r.d.ids(0xffff)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]uint16, len(ids))
+	for i, id := range ids {
+		out[i] = uint16(id)
+	}
+	return out, nil
+}
+
+type systemInfoStore struct{ d blobDir }
+
+func (y systemInfoStore) Read(_ context.Context, param uint8) ([]byte, error) {
+	return y.d.read(strconv.Itoa(int(param)))
+}
+
+func (y systemInfoStore) Write(_ context.Context, param uint8, data []byte) error {
+	return y.d.write(strconv.Itoa(int(param)), data)
+}
+
+type stateStore struct{ d blobDir }
+
+func (t stateStore) Read(_ context.Context, key string) ([]byte, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("%q: %w", key, ErrInvalidKey)
+	}
+	return t.d.read(key)
+}
+
+func (t stateStore) Write(_ context.Context, key string, data []byte) error {
+	if !validKey(key) {
+		return fmt.Errorf("%q: %w", key, ErrInvalidKey)
+	}
+	return t.d.write(key, data)
+}
+
+// validKey reports whether key names a file of the state directory, and not
+// a temporary file or one elsewhere.
+func validKey(key string) bool {
+	return key != "" && !strings.HasPrefix(key, ".") && !strings.ContainsAny(key, `/\`)
+}
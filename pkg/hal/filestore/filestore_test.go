@@ -0,0 +1,99 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+)
+
+func TestStorageRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.FRU().Write(ctx, 0, []byte{0x01, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uint16{10, 2, 0x0100} {
+		if err := s.SEL().Write(ctx, id, []byte{byte(id)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SEL().Delete(ctx, 0x0100); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.State().Write(ctx, "users", []byte(`[]`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.State().Write(ctx, "users", []byte(`[{"ID":1}]`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second process opening the same directory sees everything.
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := s.FRU().Read(ctx, 0); err != nil || !slices.Equal(data, []byte{0x01, 0x02}) {
+		t.Fatalf("FRU 0 = % x, %v", data, err)
+	}
+	// Log order is numeric, not the order of the file names.
+	if ids, err := s.SEL().RecordIDs(ctx); err != nil || !slices.Equal(ids, []uint16{2, 10}) {
+		t.Fatalf("SEL record IDs %v, %v", ids, err)
+	}
+	if data, err := s.State().Read(ctx, "users"); err != nil || string(data) != `[{"ID":1}]` {
+		t.Fatalf("state = %s, %v", data, err)
+	}
+
+	if _, err := s.SDR().Read(ctx, 1); !errors.Is(err, hal.ErrNotFound) {
+		t.Fatalf("missing SDR: %v", err)
+	}
+	if _, err := s.State().Read(ctx, "chassis"); !errors.Is(err, hal.ErrNotFound) {
+		t.Fatalf("missing state: %v", err)
+	}
+	if err := s.SEL().Delete(ctx, 0x0100); err != nil {
+		t.Fatalf("deleting a missing record: %v", err)
+	}
+}
+
+func TestStorageFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", "../users", ".tmp-1", `a\b`} {
+		if err := s.State().Write(ctx, key, nil); !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("key %q: %v", key, err)
+		}
+	}
+
+	if err := s.State().Write(ctx, "users", []byte(`[]`)); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "state", "users.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("state file mode %v, want owner-only", perm)
+	}
+
+	// The leftover of an interrupted write is not a record.
+	if err := os.WriteFile(filepath.Join(dir, "sdr", ".tmp-123"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := s.SDR().RecordIDs(ctx); err != nil || len(ids) != 0 {
+		t.Fatalf("SDR record IDs %v, %v", ids, err)
+	}
+}
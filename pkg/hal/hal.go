@@ -11,7 +11,8 @@
 // The only concrete Go packages used in the interface signatures are from the
 // standard library and only primitives (context, error, []byte, basic types).
 // This makes it possible to implement HAL for:
-//   - Linux via sysfs / hwmon / i2c-dev / libgpiod (not yet implemented in-tree)
+//   - Linux via sysfs / hwmon / i2c-dev / libgpiod (sensors via pkg/hal/hwmon;
+//     the rest not yet implemented in-tree)
 //   - Bare-metal Go / TinyGo with direct MMIO or SPI/I2C drivers
 //   - Simulation / test via pkg/hal/mock
 //   - Bridges to existing daemon APIs (e.g. OpenBMC D-Bus)
@@ -86,6 +87,20 @@ type ChassisHAL interface {
 	GetBootInfoAcknowledge(ctx context.Context) (*types.BootOptionParam_BootInfoAcknowledge, error)
 }
 
+// DiagnosticInterruptHAL is optionally implemented by a [ChassisHAL] that can
+// pulse a diagnostic interrupt (NMI) to the managed system (Chassis Control
+// action 0x04, spec Table 28-3; the PEF diagnostic interrupt action, §17.6).
+type DiagnosticInterruptHAL interface {
+	DiagnosticInterrupt(ctx context.Context) error
+}
+
+// PowerCycleIntervalHAL is optionally implemented by a [ChassisHAL] that can
+// hold power off for a set time during a power cycle (Set Power Cycle
+// Interval, spec §28.9).
+type PowerCycleIntervalHAL interface {
+	SetPowerCycleInterval(ctx context.Context, seconds uint8) error
+}
+
 // SensorDescriptor describes a sensor exposed by the hardware.
 type SensorDescriptor struct {
 	ID   uint8
@@ -101,6 +116,13 @@ type SensorHAL interface {
 	List(ctx context.Context) ([]SensorDescriptor, error)
 }
 
+// LUNSensorHAL is optionally implemented by a [SensorHAL] whose sensors are
+// spread over the BMC's LUNs, as sensor records can place them. ReadRaw
+// reads the sensors of LUN 0; ReadRawLUN reads those of any LUN.
+type LUNSensorHAL interface {
+	ReadRawLUN(ctx context.Context, lun, sensorID uint8) (uint8, error)
+}
+
 // IPConfig holds the BMC network interface configuration.
 type IPConfig struct {
 	IP      [4]byte
@@ -112,8 +134,42 @@ type IPConfig struct {
 	// Parameters param #8). Zero means the standard 623; a non-zero value lets a
 	// BMC that listens on a non-standard port advertise it to in-band software.
 	Port uint16
+
+	// VLANEnabled turns 802.1q tagging on with VLANID (1-4094) and
+	// VLANPriority (0-7).
+	VLANEnabled  bool
+	VLANID       uint16
+	VLANPriority uint8
+
+	// IPv6Mode selects whether the interface runs IPv4 only, IPv6 only or
+	// both; IPv6Static holds its static IPv6 addresses.
+	IPv6Mode   types.LanIPv6EnableMode
+	IPv6Static []IPv6Address
+
+	// Fixed lists the settings the interface cannot change. Set LAN
+	// Configuration Parameters rejects writes to them as read-only.
+	Fixed NetworkField
+}
+
+// IPv6Address is a static IPv6 address of the BMC network interface.
+type IPv6Address struct {
+	Enabled      bool
+	IP           [16]byte
+	PrefixLength uint8
 }
 
+// NetworkField is a set of [IPConfig] settings.
+type NetworkField uint8
+
+const (
+	// NetworkFieldAddress covers the IP address source, IPv4 address,
+	// subnet mask and default gateway.
+	NetworkFieldAddress NetworkField = 1 << iota
+	NetworkFieldMAC
+	NetworkFieldVLAN
+	NetworkFieldIPv6
+)
+
 // NetworkHAL configures the BMC's own network interface.
 // This is separate from [transport.PacketConn]: transport is how packets arrive;
 // NetworkHAL is how the LAN configuration commands read/write NIC parameters.
@@ -174,6 +230,43 @@ type ConsoleConn interface {
 	SendBreak(ctx context.Context) error
 }
 
+// PowerLimit is a DCMI power limit (DCMI v1.5 §6.6.3).
+type PowerLimit struct {
+	// Watts is the power limit requested.
+	Watts uint16
+	// CorrectionTimeMs is the time the platform has to bring its power
+	// below the limit before ExceptionAction is taken.
+	CorrectionTimeMs uint32
+	// ExceptionAction is taken when the limit cannot be kept within the
+	// correction time.
+	ExceptionAction types.DCMIExceptionAction
+	// SamplingPeriodSec is the management application's statistics
+	// sampling period.
+	SamplingPeriodSec uint16
+}
+
+// PowerMeterHAL is optionally implemented by a [HAL] whose platform can
+// measure its power draw. Power returns the power meter and power limiter
+// used by the DCMI power management commands (DCMI v1.5 §6.6), or nil when
+// the target has none after all.
+type PowerMeterHAL interface {
+	Power() PowerHAL
+}
+
+// PowerHAL measures and limits the power drawn by the managed system.
+//
+// Enforcing a limit, including taking its exception action, is left to the
+// implementation: the BMC only records the limit and tells the hardware
+// when it is activated or deactivated.
+type PowerHAL interface {
+	// Reading returns the current system input power in watts.
+	Reading(ctx context.Context) (uint16, error)
+	// SetLimit activates limit, replacing any limit in effect; nil
+	// deactivates power limiting. Implementations that cannot limit power
+	// return ErrNotSupported.
+	SetLimit(ctx context.Context, limit *PowerLimit) error
+}
+
 // ErrNotSupported is returned by HAL methods when the hardware does not
 // support the requested operation.  Handlers translate this to an appropriate
 // IPMI completion code: CodeParameterNotSupported 0x80 (v2.0§28.12/§28.13) for
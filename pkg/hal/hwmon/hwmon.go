@@ -0,0 +1,408 @@
+// Package hwmon provides a [hal.SensorHAL] over the Linux hwmon class
+// (Documentation/hwmon/sysfs-interface), so goipmi-server can serve the
+// host's own sensors as a software BMC on a machine without one.
+//
+// [Open] discovers the temperature, voltage, current, power and fan inputs of
+// every chip under the sysfs root and numbers them from 1, in chip order.
+// Each sensor gets reading factors chosen from its limits, a round step such
+// as 1 °C or 20 mV a raw count, so the raw byte
+// [Sensors.ReadRaw] returns converts back to the reading through the Full
+// Sensor Record [Sensors.SDRs] generates for it (v2.0§36.3):
+//
+//	sysfs          sensor type   unit  sysfs scale  raw format
+//	temp<n>_input  temperature   °C    milli        2's complement
+//	in<n>_input    voltage       V     milli        unsigned
+//	curr<n>_input  current       A     milli        unsigned
+//	power<n>_input power supply  W     micro        unsigned
+//	fan<n>_input   fan           RPM   1            unsigned
+//
+// The min, lcrit, max, crit and emergency limits of an input become its
+// LNC, LCR, UNC, UCR and UNR thresholds, readable but not settable: the BMC
+// does not write to the chip.
+package hwmon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// DefaultRoot is where Linux lists the hwmon chips.
+const DefaultRoot = "/sys/class/hwmon"
+
+// kind is a class of hwmon input.
+type kind struct {
+	prefix     string
+	sensorType types.SensorType
+	unit       types.SensorUnitType
+	// scale is the sysfs value of one unit.
+	scale  int64
+	signed bool
+	// fallback is the largest reading expected, in sysfs units, for an
+	// input without limits.
+	fallback int64
+}
+
+var kinds = []kind{
+	{"temp", types.SensorTypeTemperature, types.SensorUnitType_DegreesC, 1000, true, 100_000},
+	{"in", types.SensorTypeVoltage, types.SensorUnitType_Volts, 1000, false, 15_000},
+	{"curr", types.SensorTypeCurrent, types.SensorUnitType_Amps, 1000, false, 100_000},
+	{"power", types.SensorTypePowerSupply, types.SensorUnitType_Watts, 1_000_000, false, 1_000_000_000},
+	{"fan", types.SensorTypeFan, types.SensorUnitType_RPM, 1, false, 12_000},
+}
+
+// limits are the sysfs limit files, by threshold in the order of
+// [types.Mask_Thresholds]: lower ones first.
+var limits = [...]struct {
+	suffix string
+	upper  bool
+}{
+	{"lcrit", false}, // LCR
+	{"min", false},   // LNC
+	{"max", true},    // UNC
+	{"crit", true},   // UCR
+	{"emergency", true},
+}
+
+// Sensor is one discovered hwmon input.
+type Sensor struct {
+	Number uint8
+	// Name is the input's label, or the chip name and input, cut to the 16
+	// bytes of an SDR ID string.
+	Name   string
+	Type   types.SensorType
+	Unit   types.SensorUnitType
+	Format types.SensorAnalogUnitFormat
+	// Factors convert the raw reading to Unit.
+	Factors types.ReadingFactors
+	// Path is the sysfs file the reading is read from.
+	Path string
+
+	entity   types.EntityID
+	instance types.EntityInstance
+	// step is the sysfs value of one raw count.
+	step   int64
+	signed bool
+	limits [len(limits)]*int64
+}
+
+// Raw returns the raw reading of the sysfs value v, clamped to the range of
+// the format.
+func (s *Sensor) Raw(v int64) uint8 {
+	q := v / s.step
+	if r := v % s.step; 2*r >= s.step {
+		q++
+	} else if 2*r <= -s.step {
+		q--
+	}
+	if s.signed {
+		return uint8(int8(max(-128, min(127, q))))
+	}
+	return uint8(max(0, min(255, q)))
+}
+
+// Sensors is the [hal.SensorHAL] of the hwmon chips under a sysfs root.
+type Sensors struct {
+	sensors []*Sensor
+}
+
+var (
+	// chipRE matches the hwmon chip directories, inputRE the input files.
+	chipRE  = regexp.MustCompile(`^hwmon(\d+)$`)
+	inputRE = regexp.MustCompile(`^(temp|in|curr|power|fan)(\d+)_(input|average)$`)
+)
+
+// Open discovers the sensors of the hwmon chips under root, [DefaultRoot] on
+// a live system. A root without chips has no sensors.
+func Open(root string) (*Sensors, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("hwmon: %w", err)
+	}
+	type chip struct {
+		index int
+		path  string
+	}
+	var chips []chip
+	for _, e := range entries {
+		m := chipRE.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		i, _ := strconv.Atoi(m[1])
+		chips = append(chips, chip{i, filepath.Join(root, e.Name())})
+	}
+	slices.SortFunc(chips, func(a, b chip) int { return a.index - b.index })
+
+	s := &Sensors{}
+	for i, c := range chips {
+		if err := s.discover(c.path, types.EntityInstance(i)); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// discover adds the inputs of the chip in dir.
+func (s *Sensors) discover(dir string, instance types.EntityInstance) error {
+	chip := readString(filepath.Join(dir, "name"))
+	if chip == "" {
+		chip = filepath.Base(dir)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("hwmon: %w", err)
+	}
+	names := make(map[string]bool, len(files))
+	for _, f := range files {
+		names[f.Name()] = true
+	}
+
+	indexes := make(map[string][]int)
+	for name := range names {
+		m := inputRE.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		// power<n>_average stands in for a missing power<n>_input.
+		if m[3] == "average" && names[m[1]+m[2]+"_input"] {
+			continue
+		}
+		i, _ := strconv.Atoi(m[2])
+		indexes[m[1]] = append(indexes[m[1]], i)
+	}
+
+	for _, k := range kinds {
+		slices.Sort(indexes[k.prefix])
+
+		for _, i := range indexes[k.prefix] {
+			if len(s.sensors) == 0xfe {
+				return nil // 0FFh is reserved
+			}
+			base := filepath.Join(dir, fmt.Sprintf("%s%d", k.prefix, i))
+			input := base + "_input"
+			if !names[filepath.Base(input)] {
+				input = base + "_average"
+			}
+			name := readString(base + "_label")
+			if name == "" {
+				name = fmt.Sprintf("%s %s%d", chip, k.prefix, i)
+			}
+			if len(name) > 16 {
+				n := 16
+				for n > 0 && !utf8.RuneStart(name[n]) {
+					n--
+				}
+				name = name[:n]
+			}
+			sensor := &Sensor{
+				Number:   uint8(len(s.sensors) + 1),
+				Name:     name,
+				Type:     k.sensorType,
+				Unit:     k.unit,
+				Path:     input,
+				entity:   entityOf(chip, k),
+				instance: instance,
+				signed:   k.signed,
+			}
+			var expected int64
+			for j, l := range limits {
+				if v, err := readInt(base + "_" + l.suffix); err == nil {
+					sensor.limits[j] = &v
+					if l.upper {
+						expected = max(expected, v)
+					}
+				}
+			}
+			if expected <= 0 {
+				expected = k.fallback
+			}
+			sensor.setFactors(k.scale, expected)
+			s.sensors = append(s.sensors, sensor)
+		}
+	}
+	return nil
+}
+
+// setFactors chooses the finest round step, M of 1, 2 or 5 times 10^R
+// units, that still reaches a quarter above expected, a sysfs value: the
+// reading is M * raw * 10^R units, and one raw count is M * 10^R * scale in
+// sysfs.
+func (s *Sensor) setFactors(scale, expected int64) {
+	s.Format = types.SensorAnalogUnitFormat_Unsigned
+	span := int64(255)
+	if s.signed {
+		s.Format = types.SensorAnalogUnitFormat_2sComplement
+		span = 127
+	}
+	expected += expected / 4
+
+	// R_Exp is a 4-bit signed field; a step finer than one sysfs unit
+	// gains nothing.
+	for r := int64(-8); r <= 7; r++ {
+		unit, ok := pow10(scale, r)
+		if !ok {
+			continue
+		}
+		for _, m := range []int64{1, 2, 5} {
+			if step := m * unit; span*step >= expected {
+				s.step = step
+				s.Factors = types.ReadingFactors{M: int16(m), R_Exp: int8(r)}
+				return
+			}
+		}
+	}
+	// Beyond 5 * 10^7 units a count: take the coarsest step there is.
+	s.step, _ = pow10(scale, 7)
+	s.step *= 5
+	s.Factors = types.ReadingFactors{M: 5, R_Exp: 7}
+}
+
+// pow10 returns scale * 10^r, and whether it is a whole number.
+func pow10(scale, r int64) (int64, bool) {
+	for ; r > 0; r-- {
+		scale *= 10
+	}
+	for ; r < 0; r++ {
+		if scale%10 != 0 {
+			return 0, false
+		}
+		scale /= 10
+	}
+	return scale, true
+}
+
+// entityOf returns the entity an input of the chip monitors: the processor
+// for the CPU temperature drivers, a fan for a fan, and otherwise the system
+// board (v2.0 Table 43-13).
+func entityOf(chip string, k kind) types.EntityID {
+	switch {
+	case k.prefix == "fan":
+		return 0x1d // fan / cooling device
+	case k.prefix == "temp" && (chip == "coretemp" || chip == "k10temp" || chip == "zenpower"):
+		return 0x03 // processor
+	default:
+		return 0x07 // system board
+	}
+}
+
+// ReadRaw implements [hal.SensorHAL]. An input that reads back an error, as
+// an idle chip may, answers that error.
+func (s *Sensors) ReadRaw(_ context.Context, sensorID uint8) (uint8, error) {
+	sensor := s.Sensor(sensorID)
+	if sensor == nil {
+		return 0, hal.ErrNotFound
+	}
+	v, err := readInt(sensor.Path)
+	if err != nil {
+		return 0, err
+	}
+	return sensor.Raw(v), nil
+}
+
+// List implements [hal.SensorHAL].
+func (s *Sensors) List(_ context.Context) ([]hal.SensorDescriptor, error) {
+	out := make([]hal.SensorDescriptor, len(s.sensors))
+	for i, sensor := range s.sensors {
+		out[i] = hal.SensorDescriptor{ID: sensor.Number, Type: uint8(sensor.Type), Name: sensor.Name}
+	}
+	return out, nil
+}
+
+// Sensor returns the sensor numbered n, or nil.
+func (s *Sensors) Sensor(n uint8) *Sensor {
+	if n == 0 || int(n) > len(s.sensors) {
+		return nil
+	}
+	return s.sensors[n-1]
+}
+
+// Sensors returns the discovered sensors in number order.
+func (s *Sensors) Sensors() []*Sensor {
+	return slices.Clone(s.sensors)
+}
+
+// SDRs returns a Full Sensor Record for each sensor, in number order, owned
+// by the BMC at slave address 20h. Record IDs are assigned when they are
+// packed into the SDR repository.
+func (s *Sensors) SDRs() []*types.SDRFull {
+	out := make([]*types.SDRFull, len(s.sensors))
+	for i, sensor := range s.sensors {
+		out[i] = sensor.SDR()
+	}
+	return out
+}
+
+// SDR returns the Full Sensor Record of the sensor.
+func (s *Sensor) SDR() *types.SDRFull {
+	r := &types.SDRFull{
+		GeneratorID:            0x20,
+		SensorNumber:           types.SensorNumber(s.Number),
+		SensorEntityID:         s.entity,
+		SensorEntityInstance:   s.instance,
+		SensorType:             s.Type,
+		SensorEventReadingType: types.EventReadingTypeThreshold,
+		SensorInitialization:   types.SensorInitialization{InitScanning: true},
+		SensorCapabilities: types.SensorCapabilities{
+			AutoRearm:           true,
+			EventMessageControl: types.SensorEventMessageControl_NoEvents,
+		},
+		SensorUnit:          types.SensorUnit{AnalogDataFormat: s.Format, BaseUnit: s.Unit},
+		ReadingFactors:      s.Factors,
+		SensorMaxReadingRaw: 0xff,
+		IDStringBytes:       []byte(s.Name),
+	}
+	if s.signed {
+		r.SensorMaxReadingRaw, r.SensorMinReadingRaw = 0x7f, 0x80
+	}
+
+	th := &r.Mask.Threshold
+	masks := [len(limits)]*types.Mask_Threshold{&th.LCR, &th.LNC, &th.UNC, &th.UCR, &th.UNR}
+	raws := [len(limits)]*uint8{&r.LCR_Raw, &r.LNC_Raw, &r.UNC_Raw, &r.UCR_Raw, &r.UNR_Raw}
+	for j, v := range s.limits {
+		if v == nil {
+			continue
+		}
+		*raws[j] = s.Raw(*v)
+		m := masks[j]
+		m.Readable, m.StatusReturned = true, true
+		if limits[j].upper {
+			m.High_Assert, m.High_Deassert = true, true
+		} else {
+			m.Low_Assert, m.Low_Deassert = true, true
+		}
+		r.SensorCapabilities.ThresholdAccess = types.SensorThresholdAccess_Readable
+		r.SensorCapabilities.EventMessageControl = types.SensorEventMessageControl_PerThresholdState
+		r.SensorInitialization.InitEvents = true
+	}
+	return r
+}
+
+func readString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readInt(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("hwmon: %s: %w", filepath.Base(path), err)
+	}
+	return v, nil
+}
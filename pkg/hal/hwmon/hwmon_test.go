@@ -0,0 +1,151 @@
+package hwmon
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// fakeTree writes files, by path under a new sysfs root, and returns the root.
+func fakeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestOpen(t *testing.T) {
+	root := fakeTree(t, map[string]string{
+		"hwmon10/name":          "acpitz",
+		"hwmon10/temp1_input":   "-5000",
+		"hwmon0/name":           "coretemp",
+		"hwmon0/temp1_label":    "Package id 0",
+		"hwmon0/temp1_input":    "45500",
+		"hwmon0/temp1_max":      "80000",
+		"hwmon0/temp1_crit":     "100000",
+		"hwmon0/temp2_input":    "41000",
+		"hwmon2/name":           "nct6775",
+		"hwmon2/in0_input":      "1216",
+		"hwmon2/in0_min":        "1000",
+		"hwmon2/in0_max":        "1500",
+		"hwmon2/fan1_input":     "1350",
+		"hwmon2/fan1_min":       "300",
+		"hwmon2/curr1_input":    "7250",
+		"hwmon2/power1_average": "95000000",
+		"hwmon2/pwm1":           "128",
+		"notachip/temp1_input":  "1",
+	})
+	s, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name    string
+		typ     types.SensorType
+		factors types.ReadingFactors
+		value   float64
+	}{
+		{"Package id 0", types.SensorTypeTemperature, types.ReadingFactors{M: 1}, 46},
+		{"coretemp temp2", types.SensorTypeTemperature, types.ReadingFactors{M: 1}, 41},
+		{"nct6775 in0", types.SensorTypeVoltage, types.ReadingFactors{M: 1, R_Exp: -2}, 1.22},
+		{"nct6775 curr1", types.SensorTypeCurrent, types.ReadingFactors{M: 5, R_Exp: -1}, 7.5},
+		{"nct6775 power1", types.SensorTypePowerSupply, types.ReadingFactors{M: 5}, 95},
+		{"nct6775 fan1", types.SensorTypeFan, types.ReadingFactors{M: 1, R_Exp: 2}, 1400},
+		{"acpitz temp1", types.SensorTypeTemperature, types.ReadingFactors{M: 1}, -5},
+	}
+	list, _ := s.List(context.Background())
+	if len(list) != len(want) {
+		t.Fatalf("sensors %+v", list)
+	}
+	for i, w := range want {
+		n := uint8(i + 1)
+		sensor := s.Sensor(n)
+		if list[i].ID != n || list[i].Name != w.name || sensor.Type != w.typ || sensor.Factors != w.factors {
+			t.Fatalf("sensor %d: %+v, factors %v; want %+v", n, list[i], sensor.Factors, w)
+		}
+		raw, err := s.ReadRaw(context.Background(), n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The SDR converts the raw byte back to the reading.
+		sdr := s.SDRs()[i]
+		got := types.ConvertReading(raw, sdr.SensorUnit.AnalogDataFormat, sdr.ReadingFactors, sdr.LinearizationFunc)
+		if math.Abs(got-w.value) > 1e-9 || sdr.SensorType != w.typ || string(sdr.IDStringBytes) != w.name {
+			t.Fatalf("sensor %d reads %v, want %v (SDR %+v)", n, got, w.value, sdr)
+		}
+	}
+	if _, err := s.ReadRaw(context.Background(), 8); err == nil {
+		t.Fatal("read of an absent sensor")
+	}
+}
+
+func TestSDRThresholds(t *testing.T) {
+	root := fakeTree(t, map[string]string{
+		"hwmon0/name":        "coretemp",
+		"hwmon0/temp1_input": "45000",
+		"hwmon0/temp1_max":   "80000",
+		"hwmon0/temp1_crit":  "100000",
+		"hwmon0/fan1_input":  "1200",
+	})
+	s, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdr := s.Sensor(1).SDR()
+	convert := func(raw uint8) float64 {
+		return types.ConvertReading(raw, sdr.SensorUnit.AnalogDataFormat, sdr.ReadingFactors, sdr.LinearizationFunc)
+	}
+	th := sdr.Mask.Threshold
+	if convert(sdr.UNC_Raw) != 80 || convert(sdr.UCR_Raw) != 100 ||
+		!th.UNC.Readable || th.UNC.Settable || !th.UCR.High_Assert || th.LNC.Readable ||
+		sdr.SensorCapabilities.ThresholdAccess != types.SensorThresholdAccess_Readable ||
+		sdr.SensorEntityID != 0x03 {
+		t.Fatalf("temperature SDR %+v", sdr)
+	}
+
+	// An input without limits has no thresholds and raises no events.
+	sdr = s.Sensor(2).SDR()
+	if sdr.SensorCapabilities.ThresholdAccess != types.SensorThresholdAccess_No ||
+		sdr.SensorCapabilities.EventMessageControl != types.SensorEventMessageControl_NoEvents ||
+		sdr.SensorEntityID != 0x1d {
+		t.Fatalf("fan SDR %+v", sdr)
+	}
+
+	// Readings beyond the range saturate.
+	if raw := s.Sensor(1).Raw(500_000); raw != 0x7f {
+		t.Fatalf("raw of 500 °C = %#x", raw)
+	}
+	if raw := s.Sensor(2).Raw(-10); raw != 0 {
+		t.Fatalf("raw of -10 RPM = %#x", raw)
+	}
+}
+
+// TestOpenLongLabel verifies a label past the 16 bytes of the SDR ID string
+// is cut on a rune boundary.
+func TestOpenLongLabel(t *testing.T) {
+	root := fakeTree(t, map[string]string{
+		"hwmon0/name":        "coretemp",
+		"hwmon0/temp1_label": "Package temp 0 °C",
+		"hwmon0/temp1_input": "45000",
+	})
+	s, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := s.Sensor(1).Name; name != "Package temp 0 " {
+		t.Fatalf("name %q", name)
+	}
+}
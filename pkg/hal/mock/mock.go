@@ -18,12 +18,13 @@ import (
 // HAL is a fully in-memory [hal.HAL].
 type HAL struct {
 	chassis *Chassis
-	sensors *Sensors
-	storage *Storage
+	sensors hal.SensorHAL
+	storage hal.StorageHAL
 	network *Network
 	gpio    *GPIO
 	i2c     *I2C
 	console hal.ConsoleHAL
+	power   *Power
 }
 
 // New returns a [HAL] with all sub-interfaces initialised.
@@ -40,6 +41,7 @@ func New() *HAL {
 		network: &Network{},
 		gpio:    &GPIO{levels: map[string]bool{}, watchers: map[string][]func(bool){}},
 		i2c:     &I2C{},
+		power:   &Power{},
 	}
 }
 
@@ -49,11 +51,21 @@ func (h *HAL) Storage() hal.StorageHAL { return h.storage }
 func (h *HAL) Network() hal.NetworkHAL { return h.network }
 func (h *HAL) GPIO() hal.GPIOHAL       { return h.gpio }
 func (h *HAL) I2C() hal.I2CHAL         { return h.i2c }
+func (h *HAL) Power() hal.PowerHAL     { return h.power }
 
 // Console returns the console installed by [HAL.SetConsole], or nil when the
 // simulated target has no redirectable serial port.
 func (h *HAL) Console() hal.ConsoleHAL { return h.console }
 
+// SetStorage replaces the in-memory storage with s, for example a
+// file-backed one that keeps the FRU, SDR and SEL data and the BMC state
+// across restarts.
+func (h *HAL) SetStorage(s hal.StorageHAL) { h.storage = s }
+
+// SetSensors replaces the mock sensors with s, for example the host's own
+// hwmon sensors.
+func (h *HAL) SetSensors(s hal.SensorHAL) { h.sensors = s }
+
 // SetConsole installs the console sub-interface returned by [HAL.Console].
 // Tests typically pass [*Console]; production wiring may pass any
 // [hal.ConsoleHAL] implementation.
@@ -71,6 +83,8 @@ type Chassis struct {
 	ColdResets      int
 	WarmResets      int
 	PowerCycles     int
+	DiagInterrupts  int
+	CycleInterval   uint8
 	LastIdentifySec uint8
 	BootFlags       *types.BootOptionParam_BootFlags
 	BootInfoAck     *types.BootOptionParam_BootInfoAcknowledge
@@ -113,6 +127,22 @@ func (c *Chassis) PowerCycle(_ context.Context) error {
 	return nil
 }
 
+// DiagnosticInterrupt implements [hal.DiagnosticInterruptHAL].
+func (c *Chassis) DiagnosticInterrupt(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.DiagInterrupts++
+	return nil
+}
+
+// SetPowerCycleInterval implements [hal.PowerCycleIntervalHAL].
+func (c *Chassis) SetPowerCycleInterval(_ context.Context, seconds uint8) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.CycleInterval = seconds
+	return nil
+}
+
 func (c *Chassis) WarmReset(_ context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -222,6 +252,7 @@ func (n *Network) GetConfig(_ context.Context) (*hal.IPConfig, error) {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	cp := n.Cfg
+	cp.IPv6Static = append([]hal.IPv6Address(nil), n.Cfg.IPv6Static...)
 	return &cp, nil
 }
 
@@ -229,6 +260,62 @@ func (n *Network) SetConfig(_ context.Context, cfg *hal.IPConfig) error {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	n.Cfg = *cfg
+	n.Cfg.IPv6Static = append([]hal.IPv6Address(nil), cfg.IPv6Static...)
+	return nil
+}
+
+// --- Power ---
+
+// Power is the mock [hal.PowerHAL]. It reads back Watts and records the
+// active limit without enforcing it.
+type Power struct {
+	mu    sync.Mutex
+	Watts uint16
+	// Limit is the active power limit, nil when power limiting is off.
+	Limit *hal.PowerLimit
+
+	// ReadErr, when set, fails every Reading.
+	ReadErr error
+}
+
+// SetWatts sets the power draw Reading reports, safe for concurrent use
+// with a running server (unlike direct Watts writes).
+func (p *Power) SetWatts(w uint16) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Watts = w
+}
+
+// ActiveLimit returns a copy of the active power limit, or nil. Safe for
+// concurrent use with a running server, unlike direct Limit reads.
+func (p *Power) ActiveLimit() *hal.PowerLimit {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Limit == nil {
+		return nil
+	}
+	cp := *p.Limit
+	return &cp
+}
+
+func (p *Power) Reading(_ context.Context) (uint16, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ReadErr != nil {
+		return 0, p.ReadErr
+	}
+	return p.Watts, nil
+}
+
+func (p *Power) SetLimit(_ context.Context, limit *hal.PowerLimit) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if limit == nil {
+		p.Limit = nil
+		return nil
+	}
+	cp := *limit
+	p.Limit = &cp
 	return nil
 }
 
@@ -13,10 +13,20 @@ type Storage struct {
 	mu  sync.RWMutex
 	fru map[uint8][]byte
 	sdr map[uint16][]byte
+	sel map[uint16][]byte
+	sys map[uint8][]byte
+	st  map[string][]byte
 }
 
 func (s *Storage) FRU() hal.FRUStore { return (*fruStore)(s) }
 func (s *Storage) SDR() hal.SDRStore { return (*sdrStore)(s) }
+func (s *Storage) SEL() hal.SELStore { return (*selStore)(s) }
+
+// SystemInfo implements [hal.SystemInfoStorageHAL].
+func (s *Storage) SystemInfo() hal.SystemInfoStore { return (*systemInfoStore)(s) }
+
+// State implements [hal.StateStorageHAL].
+func (s *Storage) State() hal.StateStore { return (*stateStore)(s) }
 
 type fruStore Storage
 
@@ -105,3 +115,95 @@ func (d *sdrStore) RecordIDs(_ context.Context) ([]uint16, error) {
 	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
 	return out, nil
 }
+
+type selStore Storage
+
+func (l *selStore) Read(_ context.Context, recordID uint16) ([]byte, error) {
+	s := (*Storage)(l)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.sel[recordID]
+	if !ok {
+		return nil, hal.ErrNotFound
+	}
+	return append([]byte(nil), v...), nil
+}
+
+func (l *selStore) Write(_ context.Context, recordID uint16, data []byte) error {
+	s := (*Storage)(l)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sel == nil {
+		s.sel = map[uint16][]byte{}
+	}
+	s.sel[recordID] = append([]byte(nil), data...)
+	return nil
+}
+
+func (l *selStore) Delete(_ context.Context, recordID uint16) error {
+	s := (*Storage)(l)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sel, recordID)
+	return nil
+}
+
+func (l *selStore) RecordIDs(_ context.Context) ([]uint16, error) {
+	s := (*Storage)(l)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]uint16, 0, len(s.sel))
+	for id := range s.sel {
+		out = append(out, id)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out, nil
+}
+
+type systemInfoStore Storage
+
+func (y *systemInfoStore) Read(_ context.Context, param uint8) ([]byte, error) {
+	s := (*Storage)(y)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.sys[param]
+	if !ok {
+		return nil, hal.ErrNotFound
+	}
+	return append([]byte(nil), v...), nil
+}
+
+func (y *systemInfoStore) Write(_ context.Context, param uint8, data []byte) error {
+	s := (*Storage)(y)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sys == nil {
+		s.sys = map[uint8][]byte{}
+	}
+	s.sys[param] = append([]byte(nil), data...)
+	return nil
+}
+
+type stateStore Storage
+
+func (t *stateStore) Read(_ context.Context, key string) ([]byte, error) {
+	s := (*Storage)(t)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.st[key]
+	if !ok {
+		return nil, hal.ErrNotFound
+	}
+	return append([]byte(nil), v...), nil
+}
+
+func (t *stateStore) Write(_ context.Context, key string, data []byte) error {
+	s := (*Storage)(t)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.st == nil {
+		s.st = map[string][]byte{}
+	}
+	s.st[key] = append([]byte(nil), data...)
+	return nil
+}
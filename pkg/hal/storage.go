@@ -2,11 +2,12 @@ package hal
 
 import "context"
 
-// StorageHAL groups persistent blob stores for Storage NetFn data (v2.0§33–§34).
+// StorageHAL groups persistent blob stores for Storage NetFn data (v2.0§31–§34).
 // Each sub-store may be nil when the backing hardware is absent.
 type StorageHAL interface {
 	FRU() FRUStore
 	SDR() SDRStore
+	SEL() SELStore
 }
 
 // FRUStore holds wire-format FRU inventory blobs (v2.0§34).
@@ -27,3 +28,49 @@ type SDRStore interface {
 	Delete(ctx context.Context, recordID uint16) error
 	RecordIDs(ctx context.Context) ([]uint16, error)
 }
+
+// SELStore holds 16-byte wire-format System Event Log records (v2.0§31–§32).
+// Record IDs 0000h and FFFFh are reserved for functional use and are never
+// stored; [bmc.SELStore] assigns IDs and maps Get SEL Entry(0000h/FFFFh) to
+// the first/last entry (v2.0§31.5). RecordIDs returns entries in log order,
+// which for the IDs the BMC assigns is ascending numeric order.
+type SELStore interface {
+	Read(ctx context.Context, recordID uint16) ([]byte, error)
+	Write(ctx context.Context, recordID uint16, data []byte) error
+	Delete(ctx context.Context, recordID uint16) error
+	RecordIDs(ctx context.Context) ([]uint16, error)
+}
+
+// SystemInfoStorageHAL is optionally implemented by a [StorageHAL] that
+// persists the System Info Parameters (v2.0§22.14), so a value one client
+// sets survives a BMC restart.
+type SystemInfoStorageHAL interface {
+	SystemInfo() SystemInfoStore
+}
+
+// SystemInfoStore holds System Info Parameter data by parameter selector.
+// For the string parameters the data is the blocks of every set selector
+// back to back: the encoding byte, the string length and the string
+// (v2.0 Table 22-16a). Read returns [ErrNotFound] for a parameter that was
+// never written.
+type SystemInfoStore interface {
+	Read(ctx context.Context, param uint8) ([]byte, error)
+	Write(ctx context.Context, param uint8, data []byte) error
+}
+
+// StateStorageHAL is optionally implemented by a [StorageHAL] that persists
+// the non-volatile state of the BMC itself: users, channel access, the SOL
+// and LAN configuration, chassis settings and boot options. The BMC restores
+// it at construction and writes each committed change back, so it survives a
+// restart as the spec's non-volatile settings do.
+type StateStorageHAL interface {
+	State() StateStore
+}
+
+// StateStore holds the BMC's non-volatile state as one opaque blob per key,
+// each written whole. Read returns [ErrNotFound] for a key that was never
+// written.
+type StateStore interface {
+	Read(ctx context.Context, key string) ([]byte, error)
+	Write(ctx context.Context, key string, data []byte) error
+}
@@ -21,9 +21,13 @@ const (
 
 // IPMI App command IDs.
 const (
-	CmdColdReset              uint8 = 0x02
-	CmdWarmReset              uint8 = 0x03
-	CmdGetChannelCipherSuites uint8 = 0x54
+	CmdColdReset                    uint8 = 0x02
+	CmdWarmReset                    uint8 = 0x03
+	CmdGetChannelCipherSuites       uint8 = 0x54
+	CmdSetChannelAccess             uint8 = 0x40
+	CmdSetSystemInfoParam           uint8 = 0x58
+	CmdSetCommandEnables            uint8 = 0x60
+	CmdSetCommandSubfunctionEnables uint8 = 0x62
 )
 
 // RegisterAppHandlers adds all App/Global command handlers to r.
@@ -34,6 +38,12 @@ func RegisterAppHandlers(r *Registry) {
 	r.RegisterFunc(types.CommandGetSelfTestResults, handleGetSelfTestResults)
 	r.RegisterFunc(types.CommandGetDeviceGUID, handleGetDeviceGUID)
 	r.RegisterFunc(types.CommandGetChannelInfo, handleGetChannelInfo)
+	r.RegisterFunc(types.CommandSetChannelAccess, handleSetChannelAccess)
+	r.RegisterFunc(types.CommandGetChannelAccess, handleGetChannelAccess)
+	r.RegisterFunc(types.CommandSetSystemInfoParam, handleSetSystemInfoParam)
+	r.RegisterFunc(types.CommandGetSystemInfoParam, handleGetSystemInfoParam)
+	registerFirewallHandlers(r)
+	registerMessagingHandlers(r)
 }
 
 // handleGetDeviceID implements Get Device ID (App 0x01).
@@ -41,6 +51,9 @@ func RegisterAppHandlers(r *Registry) {
 func handleGetDeviceID(ctx context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
 	info := hctx.BMC.Info
 	deviceRev := info.DeviceRevision & 0x0F
+	if hctx.BMC.DeviceSDRs != nil {
+		deviceRev |= 0x80 // bit 7: device provides Device SDRs (Table 20-2)
+	}
 	additional := info.AdditionalDeviceSupport
 	if store := storageHAL(hctx); store != nil {
 		if hasSDRRecords(ctx, store) {
@@ -50,6 +63,9 @@ func handleGetDeviceID(ctx context.Context, hctx *HandlerContext, _ []byte) ([]b
 			additional |= 0x08 // bit 3: FRU Inventory Device (Table 20-2)
 		}
 	}
+	if hctx.BMC.SEL != nil && hctx.BMC.SEL.Supported() {
+		additional |= 0x04 // bit 2: SEL Device (Table 20-2)
+	}
 	resp := make([]byte, 11)
 	resp[0] = info.DeviceID
 	resp[1] = deviceRev
@@ -80,6 +96,22 @@ func handleColdReset(ctx context.Context, hctx *HandlerContext, _ []byte) ([]byt
 	// volatile SOL configuration (#0 set in progress, #6 bit rate) returns
 	// to its power-up state (Table 26-3, Table 26-5).
 	hctx.BMC.SOL.Config().ResetVolatile()
+	// It also aborts a LAN parameter set in progress, discarding the staged
+	// network settings (Table 23-4 param #0).
+	hctx.BMC.LANConfig.Abort()
+	// The same goes for a System Info Parameter set in progress (v2.0
+	// Table 22-16a param #0).
+	hctx.BMC.SystemInfo.Abort()
+	// The channels return to their non-volatile access configuration
+	// (v2.0§22.22).
+	hctx.BMC.Channels.RestoreAccess()
+	// The Receive Message Queue is emptied and every channel's messages
+	// are accepted again (v2.0§22.5).
+	hctx.BMC.Messages.Reset()
+	// And it ends SDR repository update mode (v2.0§33.19).
+	if repo := hctx.BMC.SDRRepository(); repo != nil {
+		repo.ExitUpdateMode()
+	}
 	return nil, types.CodeOK, nil
 }
 
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/app"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// Channel Access option encodings (request byte 2 bits [7:6] and byte 3 bits
+// [7:6] of Set Channel Access, byte 2 bits [7:6] of Get Channel Access).
+const (
+	channelAccessNoChange    uint8 = 0x00
+	channelAccessNonVolatile uint8 = 0x01
+	channelAccessVolatile    uint8 = 0x02
+)
+
+// channelAccessTarget resolves the channel of a Set/Get Channel Access
+// request. cc is CCh for an unknown channel and 82h for a session-less one,
+// which has no access configuration to set or get.
+func channelAccessTarget(hctx *HandlerContext, nibble uint8, notSupported types.CompletionCode) (*bmc.Channel, types.CompletionCode) {
+	ch, err := hctx.BMC.Channels.Get(resolveUserChannel(hctx, nibble))
+	if err != nil {
+		return nil, types.CodeRequestDataFieldInvalid
+	}
+	if channelSessionSupportForMedium(ch.Medium) == channelSessionLess {
+		return nil, notSupported
+	}
+	return ch, types.CodeOK
+}
+
+// handleSetChannelAccess implements Set Channel Access (App 0x40,
+// v2.0§22.22). The access settings and the privilege limit each go to the
+// volatile or the non-volatile configuration, as their options select. A
+// volatile change takes effect on the next request, so disabling the
+// channel a session runs on cuts that session off; a non-volatile one
+// waits for the BMC to restart. Shared access is not supported (83h).
+func handleSetChannelAccess(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.SetChannelAccessRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	ch, cc := channelAccessTarget(hctx, request.ChannelNumber, types.CodeSetChannelAccessSetNotSupported)
+	if cc != types.CodeOK {
+		return nil, cc, nil
+	}
+
+	if request.AccessOption > channelAccessVolatile || request.PrivilegeOption > channelAccessVolatile {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	if request.AccessOption != channelAccessNoChange {
+		switch request.AccessMode {
+		case types.ChannelAccessMode_Disabled, types.ChannelAccessMode_PrebootOnly, types.ChannelAccessMode_AlwaysAvailable:
+		case types.ChannelAccessMode_Shared:
+			return nil, types.CodeSetChannelAccessModeNotSupported, nil
+		default:
+			return nil, types.CodeRequestDataFieldInvalid, nil
+		}
+	}
+	priv := bmc.PrivilegeLevel(request.MaxPrivilegeLevel)
+	if request.PrivilegeOption != channelAccessNoChange && (priv < bmc.PrivilegeLevelCallback || priv > bmc.PrivilegeLevelOEM) {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+
+	for _, option := range []uint8{channelAccessNonVolatile, channelAccessVolatile} {
+		if request.AccessOption != option && request.PrivilegeOption != option {
+			continue
+		}
+		volatile := option == channelAccessVolatile
+		access, err := hctx.BMC.Channels.Access(ch.Number, volatile)
+		if err != nil {
+			return nil, types.CodeRequestDataFieldInvalid, nil
+		}
+		if request.AccessOption == option {
+			access.AccessMode = bmc.ChannelAccessMode(request.AccessMode)
+			access.PEFAlerts = !request.DisablePEFAlerting
+			access.PerMessageAuth = !request.DisablePerMsgAuth
+			access.UserLevelAuth = !request.DisableUserLevelAuth
+		}
+		if request.PrivilegeOption == option {
+			access.MaxPrivilege = priv
+		}
+		if err := hctx.BMC.Channels.SetAccess(ch.Number, volatile, access); err != nil {
+			return nil, types.CodeRequestDataFieldInvalid, nil
+		}
+	}
+	return nil, types.CodeOK, nil
+}
+
+// handleGetChannelAccess implements Get Channel Access (App 0x41,
+// v2.0§22.23), reading the volatile or the non-volatile configuration.
+func handleGetChannelAccess(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.GetChannelAccessRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	option := uint8(request.AccessOption)
+	if option != channelAccessNonVolatile && option != channelAccessVolatile {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	ch, cc := channelAccessTarget(hctx, request.ChannelNumber, types.CodeGetChannelAccessNotSupported)
+	if cc != types.CodeOK {
+		return nil, cc, nil
+	}
+	access, err := hctx.BMC.Channels.Access(ch.Number, option == channelAccessVolatile)
+	if err != nil {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+
+	response := &app.GetChannelAccessResponse{
+		PEFAlertingDisabled:   !access.PEFAlerts,
+		PerMsgAuthDisabled:    !access.PerMessageAuth,
+		UserLevelAuthDisabled: !access.UserLevelAuth,
+		AccessMode:            types.ChannelAccessMode(access.AccessMode),
+		MaxPrivilegeLevel:     types.PrivilegeLevel(access.MaxPrivilege),
+	}
+	return response.Pack(), types.CodeOK, nil
+}
@@ -0,0 +1,391 @@
+package handlers
+
+// Firmware firewall and command discovery (v2.0§21). What is supported
+// comes from the [Registry] dispatching the request: every registered
+// command is supported on every LUN of every channel, as the registry
+// serves them there alike. [bmc.CommandEnableStore] holds the commands
+// disabled per channel and LUN, which the registry's dispatch rejects.
+//
+// A command has no sub-functions of its own here, so sub-function 0 stands
+// for the command itself: it is supported, configurable and enabled exactly
+// when the command is, and the sub-function commands read and write the
+// command's enable.
+//
+// The support bits follow the spec's polarity: in Get Command Support and
+// Get Command Sub-function Support a 0b bit is a supported command, while
+// the configurable and enable masks set 1b.
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/app"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// firewallLUNs is the number of LUNs the firewall is configured for.
+const firewallLUNs = 4
+
+// registerFirewallHandlers adds the firmware firewall and command discovery
+// handlers to r. None of them can be disabled, so a client cannot lock
+// itself out of re-enabling a command.
+func registerFirewallHandlers(r *Registry) {
+	commands := []struct {
+		c  types.Command
+		fn func(context.Context, *HandlerContext, []byte) ([]byte, types.CompletionCode, error)
+	}{
+		{types.CommandGetNetFnSupport, handleGetNetFnSupport},
+		{types.CommandGetCommandSupport, handleGetCommandSupport},
+		{types.CommandGetCommandSubfunctionSupport, handleGetCommandSubfunctionSupport},
+		{types.CommandGetConfigurableCommands, handleGetConfigurableCommands},
+		{types.CommandGetConfigurableCommandSubfunctions, handleGetConfigurableCommandSubfunctions},
+		{types.CommandSetCommandEnables, handleSetCommandEnables},
+		{types.CommandGetCommandEnables, handleGetCommandEnables},
+		{types.CommandSetCommandSubfunctionEnables, handleSetCommandSubfunctionEnables},
+		{types.CommandGetCommandSubfunctionEnables, handleGetCommandSubfunctionEnables},
+	}
+	for _, cmd := range commands {
+		r.RegisterFunc(cmd.c, cmd.fn)
+		r.SetConfigurable(cmd.c, false)
+	}
+}
+
+// firewallChannel resolves the channel nibble of a firewall request, 0x0E
+// being the arrival channel. ok is false for a channel the BMC does not
+// have.
+func firewallChannel(hctx *HandlerContext, nibble uint8) (channel uint8, ok bool) {
+	channel = nibble & 0x0f
+	if channel == types.ChannelNumberSelf {
+		if hctx.Channel == nil {
+			return 0, false
+		}
+		channel = hctx.Channel.Number
+	}
+	if _, err := hctx.BMC.Channels.Get(channel); err != nil {
+		return 0, false
+	}
+	return channel, true
+}
+
+// firewallRegistry returns the registry the request was dispatched by, or
+// nil when the handler was called directly.
+func firewallRegistry(hctx *HandlerContext) *Registry {
+	if hctx == nil {
+		return nil
+	}
+	return hctx.Registry
+}
+
+// netFnBodySupported reports whether the defining body code of a Group
+// Extension (2Ch) request names a supported group. The only group the
+// standard handlers serve is DCMI; OEM (2Eh) commands are not told apart
+// by IANA.
+func netFnBodySupported(netFn types.NetFn, code uint8) bool {
+	return netFn != types.NetFnGroupExtensionRequest || code == types.GroupExtensionDCMI
+}
+
+// commandRangeBase returns the first command code of a 128-command range.
+// ok is false for the reserved range values.
+func commandRangeBase(rangeMask app.CommandRangeMask) (base int, ok bool) {
+	switch uint8(rangeMask) {
+	case app.CommandRangeMask007F:
+		return 0x00, true
+	case app.CommandRangeMask80FF:
+		return 0x80, true
+	default:
+		return 0, false
+	}
+}
+
+// commandMask builds a 128-command bitmap from base, setting the bit of
+// each command bit returns true for.
+func commandMask(base int, bit func(cmd uint8) bool) []byte {
+	mask := make([]byte, 16)
+	for i := 0; i < 128; i++ {
+		if bit(uint8(base + i)) {
+			mask[i/8] |= 1 << (i % 8)
+		}
+	}
+	return mask
+}
+
+// handleGetNetFnSupport implements Get NetFn Support (App 0x09, v2.0§21.2).
+// Each LUN reports commands without restriction, or restricted once the
+// firewall disables any command there. The NetFn pair bitmap takes 4 bytes
+// per LUN, LUN 0 first, one bit per even NetFn.
+func handleGetNetFnSupport(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.GetNetFnSupportRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	channel, ok := firewallChannel(hctx, request.ChannelNumber)
+	if !ok {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	r := firewallRegistry(hctx)
+	if r == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+
+	var pairs [4]byte
+	for netFn := 0; netFn < 0x40; netFn += 2 {
+		if r.SupportsNetFn(uint8(netFn)) {
+			pairs[netFn/16] |= 1 << (netFn / 2 % 8)
+		}
+	}
+	response := &app.GetNetFnSupportResponse{NetFnPairsSupport: make([]byte, 0, 16)}
+	lunSupport := []*app.LUNSupport{&response.LUN0Support, &response.LUN1Support, &response.LUN2Support, &response.LUN3Support}
+	for lun := uint8(0); lun < firewallLUNs; lun++ {
+		*lunSupport[lun] = 0x01
+		if hctx.BMC.CommandEnables.Restricted(channel, lun) {
+			*lunSupport[lun] = 0x02
+		}
+		response.NetFnPairsSupport = append(response.NetFnPairsSupport, pairs[:]...)
+	}
+	return response.Pack(), types.CodeOK, nil
+}
+
+// handleGetCommandSupport implements Get Command Support (App 0x0A,
+// v2.0§21.3): a 0b bit for each registered command of the NetFn, whether
+// or not the firewall has disabled it.
+func handleGetCommandSupport(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.GetCommandSupportRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	base, ok := commandRangeBase(request.CommandRangeMask)
+	if !ok {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	if _, ok := firewallChannel(hctx, request.ChannelNumber); !ok {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	r := firewallRegistry(hctx)
+	if r == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	netFn := uint8(request.NetFn)
+	body := netFnBodySupported(request.NetFn, request.CodeForNetFn2C)
+	response := &app.GetCommandSupportResponse{CommandSupportMask: commandMask(base, func(cmd uint8) bool {
+		return !body || !r.Supported(netFn, cmd)
+	})}
+	return response.Pack(), types.CodeOK, nil
+}
+
+// handleGetConfigurableCommands implements Get Configurable Commands (App
+// 0x0C, v2.0§21.5): a 1b bit for each command the firewall may disable.
+func handleGetConfigurableCommands(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.GetConfigurableCommandsRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	base, ok := commandRangeBase(request.CommandRangeMask)
+	if !ok {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	if _, ok := firewallChannel(hctx, request.ChannelNumber); !ok {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	r := firewallRegistry(hctx)
+	if r == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	netFn := uint8(request.NetFn)
+	body := netFnBodySupported(request.NetFn, request.CodeForNetFn2C)
+	response := &app.GetConfigurableCommandsResponse{CommandSupportMask: commandMask(base, func(cmd uint8) bool {
+		return body && r.Configurable(netFn, cmd)
+	})}
+	return response.Pack(), types.CodeOK, nil
+}
+
+// handleGetCommandEnables implements Get Command Enables (App 0x61,
+// v2.0§21.8): a 1b bit for each supported command enabled on the channel
+// and LUN.
+func handleGetCommandEnables(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.GetCommandEnablesRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	base, ok := commandRangeBase(request.CommandRangeMask)
+	if !ok {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	channel, ok := firewallChannel(hctx, request.ChannelNumber)
+	if !ok {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	r := firewallRegistry(hctx)
+	if r == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	netFn := uint8(request.NetFn)
+	body := netFnBodySupported(request.NetFn, request.CodeForNetFn2C)
+	response := &app.GetCommandEnablesResponse{CommandEnableMask: commandMask(base, func(cmd uint8) bool {
+		return body && commandEnabled(hctx, r, channel, request.LUN, netFn, cmd)
+	})}
+	return response.Pack(), types.CodeOK, nil
+}
+
+// commandEnabled reports whether a supported command is enabled; commands
+// the firewall cannot configure always are.
+func commandEnabled(hctx *HandlerContext, r *Registry, channel, lun, netFn, cmd uint8) bool {
+	if !r.Supported(netFn, cmd) {
+		return false
+	}
+	return !r.Configurable(netFn, cmd) || hctx.BMC.CommandEnables.Enabled(channel, lun, netFn, cmd)
+}
+
+// handleSetCommandEnables implements Set Command Enables (App 0x60,
+// v2.0§21.7). The mask sets the enable of every configurable command in
+// the range at once; bits of unsupported commands are ignored, and a 0b bit
+// for a command that cannot be disabled rejects the whole request with CCh.
+func handleSetCommandEnables(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.SetCommandEnablesRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	base, ok := commandRangeBase(request.CommandRangeMask)
+	if !ok {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	channel, ok := firewallChannel(hctx, request.ChannelNumber)
+	if !ok || !netFnBodySupported(request.NetFn, request.CodeForNetFn2C) {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	r := firewallRegistry(hctx)
+	if r == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+
+	netFn := uint8(request.NetFn)
+	enables := make(map[uint8]bool)
+	for i := 0; i < 128; i++ {
+		cmd := uint8(base + i)
+		enabled := request.CommandsMaskBytes[i/8]&(1<<(i%8)) != 0
+		switch {
+		case r.Configurable(netFn, cmd):
+			enables[cmd] = enabled
+		case r.Supported(netFn, cmd) && !enabled:
+			return firewallFailure(bmc.ErrCommandNotConfigurable)
+		}
+	}
+	hctx.BMC.CommandEnables.SetEnables(channel, request.LUN, netFn, enables)
+	return nil, types.CodeOK, nil
+}
+
+// firewallCommandCC maps a firmware firewall failure to its completion code
+// (v2.0§21).
+func firewallCommandCC(err error) types.CompletionCode {
+	switch {
+	case err == nil:
+		return types.CodeOK
+	case errors.Is(err, bmc.ErrCommandNotConfigurable):
+		return types.CodeRequestDataFieldInvalid
+	default:
+		return types.CodeUnspecifiedError
+	}
+}
+
+// firewallFailure is the handler return for a firmware firewall failure.
+// Only an unmapped error is passed on for logging.
+func firewallFailure(err error) ([]byte, types.CompletionCode, error) {
+	cc := firewallCommandCC(err)
+	if cc == types.CodeUnspecifiedError {
+		return nil, cc, err
+	}
+	return nil, cc, nil
+}
+
+// subfunctionCommand resolves the command of a sub-function request. cc is
+// CCh for an unknown channel or a command that is not supported.
+func subfunctionCommand(hctx *HandlerContext, nibble uint8, netFn types.NetFn, code, cmd uint8) (r *Registry, channel uint8, cc types.CompletionCode) {
+	channel, ok := firewallChannel(hctx, nibble)
+	if !ok {
+		return nil, 0, types.CodeRequestDataFieldInvalid
+	}
+	r = firewallRegistry(hctx)
+	if r == nil {
+		return nil, 0, types.CodeNotSupported
+	}
+	if !netFnBodySupported(netFn, code) || !r.Supported(uint8(netFn), cmd) {
+		return nil, 0, types.CodeRequestDataFieldInvalid
+	}
+	return r, channel, types.CodeOK
+}
+
+// handleGetCommandSubfunctionSupport implements Get Command Sub-function
+// Support (App 0x0B, v2.0§21.4): sub-function 0 of an IPMI v2.0 command.
+func handleGetCommandSubfunctionSupport(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.GetCommandSubfunctionSupportRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if _, _, cc := subfunctionCommand(hctx, request.ChannelNumber, request.NetFn, request.CodeForNetFn2C, request.Cmd); cc != types.CodeOK {
+		return nil, cc, nil
+	}
+	response := &app.GetCommandSubfunctionSupportResponse{
+		SpecificationVersion: 0x02,
+		SupportMask:          []byte{0xfe, 0xff, 0xff, 0xff},
+	}
+	return response.Pack(), types.CodeOK, nil
+}
+
+// handleGetConfigurableCommandSubfunctions implements Get Configurable
+// Command Sub-functions (App 0x0D, v2.0§21.6).
+func handleGetConfigurableCommandSubfunctions(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.GetConfigurableCommandSubfunctionsRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	r, _, cc := subfunctionCommand(hctx, request.ChannelNumber, request.NetFn, request.CodeForNetFn2C, request.Cmd)
+	if cc != types.CodeOK {
+		return nil, cc, nil
+	}
+	response := &app.GetConfigurableCommandSubfunctionsResponse{
+		SubfunctionsSupport: []bool{r.Configurable(uint8(request.NetFn), request.Cmd)},
+	}
+	return response.Pack(), types.CodeOK, nil
+}
+
+// handleGetCommandSubfunctionEnables implements Get Command Sub-function
+// Enables (App 0x63, v2.0§21.10).
+func handleGetCommandSubfunctionEnables(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.GetCommandSubfunctionEnablesRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	r, channel, cc := subfunctionCommand(hctx, request.ChannelNumber, request.NetFn, request.CodeForNetFn2C, request.Cmd)
+	if cc != types.CodeOK {
+		return nil, cc, nil
+	}
+	response := &app.GetCommandSubfunctionEnablesResponse{
+		SubfunctionEnables: []bool{commandEnabled(hctx, r, channel, request.LUN, uint8(request.NetFn), request.Cmd)},
+	}
+	return response.Pack(), types.CodeOK, nil
+}
+
+// handleSetCommandSubfunctionEnables implements Set Command Sub-function
+// Enables (App 0x62, v2.0§21.9). The bit of sub-function 0 enables or
+// disables the command; the bits of sub-functions that do not exist are
+// ignored.
+func handleSetCommandSubfunctionEnables(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.SetCommandSubfunctionEnablesRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	r, channel, cc := subfunctionCommand(hctx, request.ChannelNumber, request.NetFn, request.CodeForNetFn2C, request.Cmd)
+	if cc != types.CodeOK {
+		return nil, cc, nil
+	}
+	netFn := uint8(request.NetFn)
+	enabled := request.SubfunctionEnables[0]
+	if !r.Configurable(netFn, request.Cmd) {
+		if !enabled {
+			return firewallFailure(bmc.ErrCommandNotConfigurable)
+		}
+		return nil, types.CodeOK, nil
+	}
+	hctx.BMC.CommandEnables.SetEnables(channel, request.LUN, netFn, map[uint8]bool{request.Cmd: enabled})
+	return nil, types.CodeOK, nil
+}
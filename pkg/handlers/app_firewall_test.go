@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/app"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestFirmwareFirewall(t *testing.T) {
+	b := newTestBMC()
+	reg := NewRegistry()
+	RegisterAppHandlers(reg)
+	RegisterSessionHandlers(reg)
+	RegisterChassisHandlers(reg)
+	ctx := context.Background()
+
+	lan, _ := b.Channels.Get(lanChannelNumber)
+	system, _ := b.Channels.Get(0x0f)
+	admin := &HandlerContext{BMC: b, Channel: lan, Session: &bmc.Session{PrivilegeLevel: bmc.PrivilegeLevelAdministrator}}
+	inBand := &HandlerContext{BMC: b, Channel: system}
+
+	dispatch := func(hctx *HandlerContext, netFn types.NetFn, cmd uint8, req []byte) ([]byte, types.CompletionCode) {
+		t.Helper()
+		resp, cc, err := reg.Dispatch(ctx, hctx, uint8(netFn), cmd, req)
+		if err != nil {
+			t.Fatalf("dispatch %#02x/%#02x: %v", uint8(netFn), cmd, err)
+		}
+		return resp, cc
+	}
+	chassisStatus := types.CommandGetChassisStatus
+
+	// Get Command Support is derived from the registry: 0b for registered.
+	get := &app.GetCommandSupportRequest{ChannelNumber: types.ChannelNumberSelf, NetFn: types.NetFnChassisRequest}
+	resp, cc := dispatch(admin, types.NetFnAppRequest, types.CommandGetCommandSupport.ID, get.Pack())
+	support := &app.GetCommandSupportResponse{}
+	if err := support.Unpack(resp); err != nil || cc != types.CodeOK {
+		t.Fatalf("command support: cc=%#02x %v", uint8(cc), err)
+	}
+	if support.CommandSupportMask[0]&(1<<chassisStatus.ID) != 0 || support.CommandSupportMask[15]&0x80 == 0 {
+		t.Fatalf("command support mask: % x", support.CommandSupportMask)
+	}
+
+	// Security locks Get Chassis Status out of the system interface.
+	set := &app.SetCommandEnablesRequest{ChannelNumber: system.Number, NetFn: types.NetFnChassisRequest}
+	for i := range set.CommandsMaskBytes {
+		set.CommandsMaskBytes[i] = 0xff
+	}
+	set.CommandsMaskBytes[0] &^= 1 << chassisStatus.ID
+	if _, cc := dispatch(admin, types.NetFnAppRequest, CmdSetCommandEnables, set.Pack()); cc != types.CodeOK {
+		t.Fatalf("set command enables: cc=%#02x", uint8(cc))
+	}
+	if _, cc := dispatch(inBand, types.NetFnChassisRequest, chassisStatus.ID, nil); cc != types.CodeInvalidCommand {
+		t.Fatalf("disabled in-band: cc=%#02x, want C1h", uint8(cc))
+	}
+	if _, cc := dispatch(admin, types.NetFnChassisRequest, chassisStatus.ID, nil); cc != types.CodeOK {
+		t.Fatalf("LAN is unaffected: cc=%#02x", uint8(cc))
+	}
+	if err := b.CommandEnables.SetDisabledCode(types.CodeNotSupported); err != nil {
+		t.Fatal(err)
+	}
+	if _, cc := dispatch(inBand, types.NetFnChassisRequest, chassisStatus.ID, nil); cc != types.CodeNotSupported {
+		t.Fatalf("disabled in-band: cc=%#02x, want D5h", uint8(cc))
+	}
+
+	// The enables read back, and the LUN is reported restricted.
+	getEnables := &app.GetCommandEnablesRequest{ChannelNumber: system.Number, NetFn: types.NetFnChassisRequest}
+	resp, cc = dispatch(inBand, types.NetFnAppRequest, types.CommandGetCommandEnables.ID, getEnables.Pack())
+	enables := &app.GetCommandEnablesResponse{}
+	if err := enables.Unpack(resp); err != nil || cc != types.CodeOK {
+		t.Fatalf("get command enables: cc=%#02x %v", uint8(cc), err)
+	}
+	if enables.CommandEnableMask[0]&(1<<chassisStatus.ID) != 0 || enables.CommandEnableMask[0]&(1<<types.CommandChassisControl.ID) == 0 {
+		t.Fatalf("command enable mask: % x", enables.CommandEnableMask)
+	}
+	netFnReq := &app.GetNetFnSupportRequest{ChannelNumber: types.ChannelNumberSelf}
+	resp, cc = dispatch(inBand, types.NetFnAppRequest, types.CommandGetNetFnSupport.ID, netFnReq.Pack())
+	netFns := &app.GetNetFnSupportResponse{}
+	if err := netFns.Unpack(resp); err != nil || cc != types.CodeOK {
+		t.Fatalf("netfn support: cc=%#02x %v", uint8(cc), err)
+	}
+	if netFns.LUN0Support != 0x02 || netFns.LUN1Support != 0x01 || netFns.NetFnPairsSupport[0] != 0x09 {
+		t.Fatalf("netfn support: %+v", netFns)
+	}
+
+	// The firewall and session setup cannot be disabled.
+	configurable := &app.GetConfigurableCommandsRequest{ChannelNumber: types.ChannelNumberSelf, NetFn: types.NetFnAppRequest}
+	resp, cc = dispatch(admin, types.NetFnAppRequest, types.CommandGetConfigurableCommands.ID, configurable.Pack())
+	mask := &app.GetConfigurableCommandsResponse{}
+	if err := mask.Unpack(resp); err != nil || cc != types.CodeOK {
+		t.Fatalf("configurable commands: cc=%#02x %v", uint8(cc), err)
+	}
+	for _, c := range []types.Command{types.CommandGetDeviceID, types.CommandSetCommandEnables, types.CommandActivateSession} {
+		want := c == types.CommandGetDeviceID
+		if got := mask.CommandSupportMask[c.ID/8]&(1<<(c.ID%8)) != 0; got != want {
+			t.Fatalf("%s configurable: %v", c.Name, got)
+		}
+	}
+	lockout := &app.SetCommandEnablesRequest{ChannelNumber: types.ChannelNumberSelf, NetFn: types.NetFnAppRequest}
+	if _, cc := dispatch(admin, types.NetFnAppRequest, CmdSetCommandEnables, lockout.Pack()); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("disabling the firewall: cc=%#02x, want CCh", uint8(cc))
+	}
+	if !b.CommandEnables.Enabled(lan.Number, 0, uint8(types.NetFnAppRequest), types.CommandGetDeviceID.ID) {
+		t.Fatal("a rejected Set Command Enables changed the enables")
+	}
+
+	// Sub-function 0 stands for the command.
+	sub := &app.SetCommandSubfunctionEnablesRequest{
+		ChannelNumber:      system.Number,
+		NetFn:              types.NetFnChassisRequest,
+		Cmd:                chassisStatus.ID,
+		SubfunctionEnables: []bool{true},
+	}
+	if _, cc := dispatch(admin, types.NetFnAppRequest, CmdSetCommandSubfunctionEnables, sub.Pack()); cc != types.CodeOK {
+		t.Fatalf("set sub-function enables: cc=%#02x", uint8(cc))
+	}
+	if _, cc := dispatch(inBand, types.NetFnChassisRequest, chassisStatus.ID, nil); cc != types.CodeOK {
+		t.Fatalf("re-enabled in-band: cc=%#02x", uint8(cc))
+	}
+	unknown := &app.GetCommandSubfunctionSupportRequest{ChannelNumber: types.ChannelNumberSelf, NetFn: types.NetFnChassisRequest, Cmd: 0x7f}
+	if _, cc := dispatch(admin, types.NetFnAppRequest, types.CommandGetCommandSubfunctionSupport.ID, unknown.Pack()); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("unsupported command sub-functions: cc=%#02x", uint8(cc))
+	}
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/app"
+	"github.com/bougou/go-ipmi/pkg/command/sensor"
+	"github.com/bougou/go-ipmi/pkg/protocol"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// Send Message tracking (request byte 1 bits [7:6], v2.0 Table 22-10).
+const (
+	sendMessageNoTracking   uint8 = 0x00
+	sendMessageTrackRequest uint8 = 0x01
+)
+
+// Enable Message Channel Receive channel operations (request byte 2 bits
+// [1:0], v2.0 Table 22-6).
+const (
+	enableMessageChannelDisable uint8 = 0x00
+	enableMessageChannelEnable  uint8 = 0x01
+	enableMessageChannelGet     uint8 = 0x02
+)
+
+// registerMessagingHandlers adds the system interface messaging commands
+// (v2.0§22.1-§22.8). Get Message, Get Message Flags, Clear Message Flags,
+// Enable Message Channel Receive, Read Event Message Buffer and Set BMC
+// Global Enables serve the system interface only; Send Message bridges
+// messages between it and the LAN sessions.
+func registerMessagingHandlers(r *Registry) {
+	r.RegisterFunc(types.CommandSetBMCGlobalEnables, handleSetBMCGlobalEnables)
+	r.RegisterFunc(types.CommandGetBMCGlobalEnables, handleGetBMCGlobalEnables)
+	r.RegisterFunc(types.CommandClearMessageFlags, handleClearMessageFlags)
+	r.RegisterFunc(types.CommandGetMessageFlags, handleGetMessageFlags)
+	r.RegisterFunc(types.CommandEnableMessageChannelReceive, handleEnableMessageChannelReceive)
+	r.RegisterFunc(types.CommandGetMessage, handleGetMessage)
+	r.RegisterFunc(types.CommandSendMessage, handleSendMessage)
+	r.RegisterFunc(types.CommandReadEventMessageBuffer, handleReadEventMessageBuffer)
+}
+
+// messagingCommandCC maps a system interface messaging failure to its
+// completion code (v2.0§22.6, §22.7).
+func messagingCommandCC(err error) types.CompletionCode {
+	switch {
+	case err == nil:
+		return types.CodeOK
+	case errors.Is(err, bmc.ErrReceiveQueueFull):
+		return types.CodeNodeBusy
+	case errors.Is(err, bmc.ErrReceiveQueueEmpty), errors.Is(err, bmc.ErrEventBufferEmpty):
+		return types.CodeDataNotAvailable
+	case errors.Is(err, bmc.ErrMessageReceiveDisabled):
+		return types.CodeSendMessageNAKOnWrite
+	case errors.Is(err, bmc.ErrNoMessageRoute):
+		return types.CodeSendMessageInvalidSessionHandle
+	case errors.Is(err, bmc.ErrIPMBNotConnected):
+		return types.CodeSendMessageBusError
+	case errors.Is(err, bmc.ErrIPMBNAK):
+		return types.CodeSendMessageNAKOnWrite
+	case errors.Is(err, bmc.ErrBridgeTableFull):
+		return types.CodeNodeBusy
+	case errors.Is(err, bmc.ErrIPMBSelfAddressed):
+		return types.CodeRequestDataFieldInvalid
+	default:
+		return types.CodeUnspecifiedError
+	}
+}
+
+// messagingFailure is the handler return for a messaging failure. Only an
+// unmapped error is passed on for logging.
+func messagingFailure(err error) ([]byte, types.CompletionCode, error) {
+	cc := messagingCommandCC(err)
+	if cc == types.CodeUnspecifiedError {
+		return nil, cc, err
+	}
+	return nil, cc, nil
+}
+
+// handleSetBMCGlobalEnables implements Set BMC Global Enables (App 0x2E,
+// v2.0§22.1).
+func handleSetBMCGlobalEnables(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	if !fromSystemInterface(hctx) {
+		return nil, types.CodeInsufficientPrivilege, nil
+	}
+	request := &sensor.SetBMCGlobalEnablesRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	hctx.BMC.Messages.SetGlobalEnables(bmc.GlobalEnables{
+		OEM2:                            request.EnableOEM2,
+		OEM1:                            request.EnableOEM1,
+		OEM0:                            request.EnableOEM0,
+		SystemEventLogging:              request.EnableSystemEventLogging,
+		EventMessageBuffer:              request.EnableEventMessageBuffer,
+		EventMessageBufferFullInterrupt: request.EnableEventMessageBufferFullInterrupt,
+		ReceiveMessageQueueInterrupt:    request.EnableReceiveMessageQueueInterrupt,
+	})
+	return nil, types.CodeOK, nil
+}
+
+// handleGetBMCGlobalEnables implements Get BMC Global Enables (App 0x2F,
+// v2.0§22.2).
+func handleGetBMCGlobalEnables(_ context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	e := hctx.BMC.Messages.GlobalEnables()
+	response := &sensor.GetBMCGlobalEnablesResponse{
+		OEM2Enabled:                            e.OEM2,
+		OEM1Enabled:                            e.OEM1,
+		OEM0Enabled:                            e.OEM0,
+		SystemEventLoggingEnabled:              e.SystemEventLogging,
+		EventMessageBufferEnabled:              e.EventMessageBuffer,
+		EventMessageBufferFullInterruptEnabled: e.EventMessageBufferFullInterrupt,
+		ReceiveMessageQueueInterruptEnabled:    e.ReceiveMessageQueueInterrupt,
+	}
+	return response.Pack(), types.CodeOK, nil
+}
+
+// handleGetMessageFlags implements Get Message Flags (App 0x31, v2.0§22.4).
+func handleGetMessageFlags(_ context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	if !fromSystemInterface(hctx) {
+		return nil, types.CodeInsufficientPrivilege, nil
+	}
+	flags := hctx.BMC.MessageFlags()
+	response := &sensor.GetMessageFlagsResponse{
+		WatchdogPreTimeoutInterruptOccurred: flags.WatchdogPreTimeout,
+		EventMessageBufferFull:              flags.EventMessageBufferFull,
+		ReceiveMessageQueueAvailable:        flags.ReceiveMessageAvailable,
+	}
+	return response.Pack(), types.CodeOK, nil
+}
+
+// handleClearMessageFlags implements Clear Message Flags (App 0x30,
+// v2.0§22.3). Clearing the Receive Message Queue flag flushes the queue, and
+// clearing the Event Message Buffer flag empties the buffer.
+func handleClearMessageFlags(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	if !fromSystemInterface(hctx) {
+		return nil, types.CodeInsufficientPrivilege, nil
+	}
+	request := &sensor.ClearMessageFlagsRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if request.ClearReceiveMessageQueue {
+		hctx.BMC.Messages.Flush()
+	}
+	if request.ClearEventMessageBuffer {
+		hctx.BMC.Messages.ClearEventBuffer()
+	}
+	if request.ClearWatchdogPreTimeoutInterruptFlag {
+		hctx.BMC.Watchdog.ClearPreTimeoutFlag()
+	}
+	return nil, types.CodeOK, nil
+}
+
+// handleEnableMessageChannelReceive implements Enable Message Channel Receive
+// (App 0x32, v2.0§22.5): whether messages from a channel are put in the
+// Receive Message Queue.
+func handleEnableMessageChannelReceive(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	if !fromSystemInterface(hctx) {
+		return nil, types.CodeInsufficientPrivilege, nil
+	}
+	request := &app.EnableMessageChannelReceiveRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	ch, err := hctx.BMC.Channels.Get(request.ChannelNumber)
+	if err != nil || ch.Medium == bmc.ChannelMediumSystemIF {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	switch request.ChannelState {
+	case enableMessageChannelDisable, enableMessageChannelEnable:
+		hctx.BMC.Messages.SetReceiveEnabled(ch.Number, request.ChannelState == enableMessageChannelEnable)
+	case enableMessageChannelGet:
+	default:
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	response := &app.EnableMessageChannelReceiveResponse{
+		ChannelNumber:  ch.Number,
+		ChannelEnabled: hctx.BMC.Messages.ReceiveEnabled(ch.Number),
+	}
+	return response.Pack(), types.CodeOK, nil
+}
+
+// handleGetMessage implements Get Message (App 0x33, v2.0§22.6), taking the
+// oldest message from the Receive Message Queue.
+func handleGetMessage(_ context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	if !fromSystemInterface(hctx) {
+		return nil, types.CodeInsufficientPrivilege, nil
+	}
+	msg, err := hctx.BMC.Messages.Dequeue()
+	if err != nil {
+		return messagingFailure(err)
+	}
+	response := &sensor.GetMessageResponse{
+		PrivilegeLevel: types.PrivilegeLevel(msg.Privilege),
+		ChannelNumber:  msg.Channel,
+		MessageData:    msg.Data,
+	}
+	return response.Pack(), types.CodeOK, nil
+}
+
+// handleSendMessage implements Send Message (App 0x34, v2.0§22.7) between
+// the LAN sessions and the system interface. A LAN session's message to the
+// system interface goes in the Receive Message Queue behind its session
+// handle, and system software answers it with a Send Message to the LAN
+// channel naming that handle, which the BMC delivers into the session
+// (v2.0§6.13). The handle travels with the message, so the BMC keeps no
+// state for such a request, and No Tracking and Track Request are served
+// alike. A message to an IPMB channel is put on its segment: with Track
+// Request the BMC stands in as the requester and routes the response back
+// into the session, as it does for the timeout it answers itself if none
+// comes; otherwise the message goes out as sent. Either way it carries the
+// requester's privilege here, which caps what the controller serving it
+// runs, and a message to the BMC's own slave address is refused. Send Raw
+// is not supported.
+func handleSendMessage(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.SendMessageRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if request.TrackMask != sendMessageNoTracking && request.TrackMask != sendMessageTrackRequest {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	target, err := hctx.BMC.Channels.Get(request.ChannelNumber)
+	if err != nil {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+
+	switch {
+	case target.Medium == bmc.ChannelMediumSystemIF && hctx.Channel != nil && hctx.Channel.Medium == bmc.ChannelMediumLAN:
+		// The answer can only be routed back into an RMCP+ session: a v1.5
+		// session has no address to send an unsolicited message to.
+		if hctx.Session == nil {
+			return nil, types.CodeNotSupported, nil
+		}
+		if !protocol.ValidMessage(request.MessageData) {
+			return nil, types.CodeRequestDataFieldInvalid, nil
+		}
+		msg := bmc.ReceivedMessage{
+			Channel:   hctx.Channel.Number,
+			Privilege: hctx.Session.PrivilegeLevel,
+			Data:      append([]byte{hctx.Session.Handle}, request.MessageData...),
+		}
+		if err := hctx.BMC.Messages.Enqueue(msg); err != nil {
+			return messagingFailure(err)
+		}
+	case target.Medium == bmc.ChannelMediumLAN && fromSystemInterface(hctx):
+		if len(request.MessageData) < 1 || !protocol.ValidMessage(request.MessageData[1:]) {
+			return nil, types.CodeRequestDataFieldInvalid, nil
+		}
+		handle := request.MessageData[0]
+		if err := hctx.BMC.Messages.Deliver(ctx, target.Number, handle, request.MessageData[1:]); err != nil {
+			return messagingFailure(err)
+		}
+	case target.Medium == bmc.ChannelMediumIPMBv10 && hctx.Channel != nil &&
+		(hctx.Channel.Medium == bmc.ChannelMediumSystemIF || hctx.Channel.Medium == bmc.ChannelMediumLAN):
+		if !protocol.ValidMessage(request.MessageData) {
+			return nil, types.CodeRequestDataFieldInvalid, nil
+		}
+		// The controller serving the message holds it to the privilege
+		// the requester has here.
+		priv, _ := requestPrivilege(hctx)
+		from := bmc.IPMBOrigin{Channel: hctx.Channel.Number, Privilege: priv}
+		var err error
+		switch {
+		case request.TrackMask == sendMessageNoTracking || fromSystemInterface(hctx):
+			err = hctx.BMC.Messages.WriteIPMB(ctx, target.Number, from, request.MessageData)
+		case hctx.Session == nil:
+			// As above, only an RMCP+ session can take the response.
+			return nil, types.CodeNotSupported, nil
+		default:
+			err = hctx.BMC.Messages.Bridge(ctx, target.Number, from, hctx.Session.Handle, request.MessageData)
+		}
+		if err != nil {
+			return messagingFailure(err)
+		}
+	default:
+		// No other channel is bridged.
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	return nil, types.CodeOK, nil
+}
+
+// handleReadEventMessageBuffer implements Read Event Message Buffer (App
+// 0x35, v2.0§22.8), taking the event from the Event Message Buffer.
+func handleReadEventMessageBuffer(_ context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	if !fromSystemInterface(hctx) {
+		return nil, types.CodeInsufficientPrivilege, nil
+	}
+	record, err := hctx.BMC.Messages.ReadEvent()
+	if err != nil {
+		return messagingFailure(err)
+	}
+	response := &sensor.ReadEventMessageBufferResponse{MessageData: record}
+	return response.Pack(), types.CodeOK, nil
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/app"
+	"github.com/bougou/go-ipmi/pkg/command/sensor"
+	"github.com/bougou/go-ipmi/pkg/protocol"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// ipmbMessage frames an IPMB-format message with both checksums.
+func ipmbMessage(rsSA, netFnLUN, rqSA, seqLUN, cmd uint8, data ...byte) []byte {
+	msg := []byte{rsSA, netFnLUN, 0, rqSA, seqLUN, cmd}
+	msg[2] = protocol.Checksum(msg[0:2])
+	msg = append(msg, data...)
+	return append(msg, protocol.Checksum(msg[3:]))
+}
+
+func TestMessageBridging(t *testing.T) {
+	b := newTestBMC()
+	reg := NewRegistry()
+	RegisterAppHandlers(reg)
+	ctx := context.Background()
+
+	lan, _ := b.Channels.Get(lanChannelNumber)
+	system, _ := b.Channels.Get(0x0f)
+	sess := &bmc.Session{Handle: 0x05, Channel: lan.Number, PrivilegeLevel: bmc.PrivilegeLevelOperator}
+	remote := &HandlerContext{BMC: b, Channel: lan, Session: sess}
+	inBand := &HandlerContext{BMC: b, Channel: system}
+
+	dispatch := func(hctx *HandlerContext, cmd types.Command, req []byte) ([]byte, types.CompletionCode) {
+		t.Helper()
+		resp, cc, err := reg.Dispatch(ctx, hctx, uint8(cmd.NetFn), cmd.ID, req)
+		if err != nil {
+			t.Fatalf("dispatch %s: %v", cmd.Name, err)
+		}
+		return resp, cc
+	}
+
+	// Only the system interface reads the queue.
+	if _, cc := dispatch(remote, types.CommandGetMessage, nil); cc != types.CodeInsufficientPrivilege {
+		t.Fatalf("Get Message over LAN: cc=%#02x, want D4h", uint8(cc))
+	}
+	if _, cc := dispatch(inBand, types.CommandGetMessage, nil); cc != types.CodeDataNotAvailable {
+		t.Fatalf("empty queue: cc=%#02x, want 80h", uint8(cc))
+	}
+
+	// A LAN request to the system interface raises Receive Message Available.
+	request := ipmbMessage(0x81, 0x30<<2, 0x81, 0x07<<2, 0x01, 0xaa)
+	send := &app.SendMessageRequest{TrackMask: 0x01, ChannelNumber: system.Number, MessageData: request}
+	if _, cc := dispatch(remote, types.CommandSendMessage, send.Pack()); cc != types.CodeOK {
+		t.Fatalf("send to the system interface: cc=%#02x", uint8(cc))
+	}
+	resp, cc := dispatch(inBand, types.CommandGetMessageFlags, nil)
+	flags := &sensor.GetMessageFlagsResponse{}
+	if err := flags.Unpack(resp); err != nil || cc != types.CodeOK || !flags.ReceiveMessageQueueAvailable {
+		t.Fatalf("message flags: cc=%#02x %+v %v", uint8(cc), flags, err)
+	}
+	resp, cc = dispatch(inBand, types.CommandGetMessage, nil)
+	msg := &sensor.GetMessageResponse{}
+	if err := msg.Unpack(resp); err != nil || cc != types.CodeOK {
+		t.Fatalf("get message: cc=%#02x %v", uint8(cc), err)
+	}
+	if msg.ChannelNumber != lan.Number || msg.PrivilegeLevel != types.PrivilegeLevelOperator ||
+		!bytes.Equal(msg.MessageData, append([]byte{sess.Handle}, request...)) {
+		t.Fatalf("get message: %+v", msg)
+	}
+
+	// The answer goes back to the session the handle names.
+	var delivered []byte
+	b.Messages.SetDeliverer(func(_ context.Context, channel, handle uint8, msg []byte) error {
+		if channel != lan.Number || handle != sess.Handle {
+			return fmt.Errorf("handle 0x%02x: %w", handle, bmc.ErrNoSession)
+		}
+		delivered = msg
+		return nil
+	})
+	answer := ipmbMessage(0x81, 0x31<<2, 0x81, 0x07<<2, 0x01, 0x00, 0x55)
+	reply := &app.SendMessageRequest{ChannelNumber: lan.Number, MessageData: append([]byte{sess.Handle}, answer...)}
+	if _, cc := dispatch(inBand, types.CommandSendMessage, reply.Pack()); cc != types.CodeOK || !bytes.Equal(delivered, answer) {
+		t.Fatalf("answer: cc=%#02x delivered % x", uint8(cc), delivered)
+	}
+	reply.MessageData[0] = 0x06
+	if _, cc := dispatch(inBand, types.CommandSendMessage, reply.Pack()); cc != types.CodeSendMessageInvalidSessionHandle {
+		t.Fatalf("unknown handle: cc=%#02x, want 80h", uint8(cc))
+	}
+
+	// Malformed messages, Send Raw and unbridged routes are refused.
+	bad := &app.SendMessageRequest{ChannelNumber: system.Number, MessageData: request[:len(request)-1]}
+	if _, cc := dispatch(remote, types.CommandSendMessage, bad.Pack()); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("bad checksum: cc=%#02x, want CCh", uint8(cc))
+	}
+	raw := &app.SendMessageRequest{TrackMask: 0x02, ChannelNumber: system.Number, MessageData: request}
+	if _, cc := dispatch(remote, types.CommandSendMessage, raw.Pack()); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("send raw: cc=%#02x, want CCh", uint8(cc))
+	}
+	loop := &app.SendMessageRequest{ChannelNumber: lan.Number, MessageData: append([]byte{sess.Handle}, answer...)}
+	if _, cc := dispatch(remote, types.CommandSendMessage, loop.Pack()); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("LAN to LAN: cc=%#02x, want CCh", uint8(cc))
+	}
+
+	// System software can turn the LAN channel's messages off.
+	disable := &app.EnableMessageChannelReceiveRequest{ChannelNumber: lan.Number, ChannelState: 0x00}
+	resp, cc = dispatch(inBand, types.CommandEnableMessageChannelReceive, disable.Pack())
+	state := &app.EnableMessageChannelReceiveResponse{}
+	if err := state.Unpack(resp); err != nil || cc != types.CodeOK || state.ChannelNumber != lan.Number || state.ChannelEnabled {
+		t.Fatalf("disable receive: cc=%#02x %+v %v", uint8(cc), state, err)
+	}
+	if _, cc := dispatch(remote, types.CommandSendMessage, send.Pack()); cc != types.CodeSendMessageNAKOnWrite {
+		t.Fatalf("disabled receive: cc=%#02x, want 83h", uint8(cc))
+	}
+	self := &app.EnableMessageChannelReceiveRequest{ChannelNumber: system.Number, ChannelState: 0x02}
+	if _, cc := dispatch(inBand, types.CommandEnableMessageChannelReceive, self.Pack()); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("system interface receive: cc=%#02x, want CCh", uint8(cc))
+	}
+
+	// Clear Message Flags flushes the queue.
+	b.Messages.SetReceiveEnabled(lan.Number, true)
+	if _, cc := dispatch(remote, types.CommandSendMessage, send.Pack()); cc != types.CodeOK {
+		t.Fatalf("re-enabled receive: cc=%#02x", uint8(cc))
+	}
+	clear := &sensor.ClearMessageFlagsRequest{ClearReceiveMessageQueue: true}
+	if _, cc := dispatch(inBand, types.CommandClearMessageFlags, clear.Pack()); cc != types.CodeOK || b.Messages.Available() {
+		t.Fatalf("clear message flags: cc=%#02x", uint8(cc))
+	}
+}
+
+func TestSendMessageToIPMB(t *testing.T) {
+	b := newTestBMC()
+	reg := NewRegistry()
+	RegisterAppHandlers(reg)
+	ctx := context.Background()
+
+	b.Channels.Set(&bmc.Channel{Number: 0, Medium: bmc.ChannelMediumIPMBv10, AccessMode: bmc.ChannelAccessAlways, MaxPrivilege: bmc.PrivilegeLevelAdministrator})
+	lan, _ := b.Channels.Get(lanChannelNumber)
+	system, _ := b.Channels.Get(0x0f)
+	sess := &bmc.Session{Handle: 0x05, Channel: lan.Number, PrivilegeLevel: bmc.PrivilegeLevelAdministrator}
+	remote := &HandlerContext{BMC: b, Channel: lan, Session: sess}
+	v15 := &HandlerContext{BMC: b, Channel: lan, V15Session: &bmc.V15Session{State: bmc.V15SessionStateActive, PrivilegeLevel: bmc.PrivilegeLevelAdministrator}}
+	inBand := &HandlerContext{BMC: b, Channel: system}
+
+	dispatch := func(hctx *HandlerContext, req *app.SendMessageRequest) types.CompletionCode {
+		t.Helper()
+		cmd := types.CommandSendMessage
+		_, cc, err := reg.Dispatch(ctx, hctx, uint8(cmd.NetFn), cmd.ID, req.Pack())
+		if err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+		return cc
+	}
+
+	request := ipmbMessage(0x2c, 0x06<<2, 0x81, 0x09<<2, 0x01)
+	tracked := &app.SendMessageRequest{TrackMask: 0x01, ChannelNumber: 0, MessageData: request}
+	if cc := dispatch(remote, tracked); cc != types.CodeSendMessageBusError {
+		t.Fatalf("unconnected IPMB: cc=%#02x, want 82h", uint8(cc))
+	}
+
+	var written [][]byte
+	b.Messages.ConnectIPMB(0, 0x20, func(_ context.Context, msg []byte) error {
+		if msg[0] != 0x2c {
+			return bmc.ErrIPMBNAK
+		}
+		written = append(written, msg)
+		return nil
+	})
+	if cc := dispatch(remote, tracked); cc != types.CodeOK || len(written) != 1 || written[0][3] != 0x20 {
+		t.Fatalf("tracked request: cc=%#02x written % x", uint8(cc), written)
+	}
+	untracked := &app.SendMessageRequest{ChannelNumber: 0, MessageData: request}
+	if cc := dispatch(inBand, untracked); cc != types.CodeOK || !bytes.Equal(written[1], request) {
+		t.Fatalf("untracked request: cc=%#02x written % x", uint8(cc), written)
+	}
+	if cc := dispatch(v15, tracked); cc != types.CodeNotSupported {
+		t.Fatalf("tracked from a v1.5 session: cc=%#02x, want D5h", uint8(cc))
+	}
+	absent := &app.SendMessageRequest{ChannelNumber: 0, MessageData: ipmbMessage(0x30, 0x06<<2, 0x81, 0, 0x01)}
+	if cc := dispatch(remote, absent); cc != types.CodeSendMessageNAKOnWrite {
+		t.Fatalf("absent controller: cc=%#02x, want 83h", uint8(cc))
+	}
+	// Addressed to the BMC itself, the request would run outside the
+	// session's privilege checks.
+	self := ipmbMessage(0x20, 0x06<<2, 0x81, 0, 0x01)
+	for _, track := range []uint8{0x00, 0x01} {
+		loop := &app.SendMessageRequest{TrackMask: track, ChannelNumber: 0, MessageData: self}
+		if cc := dispatch(remote, loop); cc != types.CodeRequestDataFieldInvalid {
+			t.Fatalf("message to the BMC itself (tracking %d): cc=%#02x, want CCh", track, uint8(cc))
+		}
+	}
+}
+
+func TestEventMessageBuffer(t *testing.T) {
+	b := newTestBMC()
+	reg := NewRegistry()
+	RegisterAppHandlers(reg)
+	ctx := context.Background()
+
+	lan, _ := b.Channels.Get(lanChannelNumber)
+	system, _ := b.Channels.Get(0x0f)
+	remote := &HandlerContext{BMC: b, Channel: lan, Session: &bmc.Session{PrivilegeLevel: bmc.PrivilegeLevelAdministrator}}
+	inBand := &HandlerContext{BMC: b, Channel: system}
+
+	dispatch := func(hctx *HandlerContext, cmd types.Command, req []byte) ([]byte, types.CompletionCode) {
+		t.Helper()
+		resp, cc, err := reg.Dispatch(ctx, hctx, uint8(cmd.NetFn), cmd.ID, req)
+		if err != nil {
+			t.Fatalf("dispatch %s: %v", cmd.Name, err)
+		}
+		return resp, cc
+	}
+	event := bmc.PlatformEvent{
+		GeneratorID:      0x20,
+		SensorType:       types.SensorTypeTemperature,
+		SensorNumber:     0x01,
+		EventReadingType: types.EventReadingTypeThreshold,
+		EventData:        types.EventData{EventData1: 0x57, EventData2: 0x60, EventData3: 0x50},
+	}
+
+	// Only system software enables the buffer, but anyone may look.
+	enable := &sensor.SetBMCGlobalEnablesRequest{EnableSystemEventLogging: true, EnableEventMessageBuffer: true}
+	if _, cc := dispatch(remote, types.CommandSetBMCGlobalEnables, enable.Pack()); cc != types.CodeInsufficientPrivilege {
+		t.Fatalf("Set BMC Global Enables over LAN: cc=%#02x, want D4h", uint8(cc))
+	}
+	_ = b.LogEvent(ctx, event)
+	if _, cc := dispatch(inBand, types.CommandReadEventMessageBuffer, nil); cc != types.CodeDataNotAvailable {
+		t.Fatalf("disabled buffer: cc=%#02x, want 80h", uint8(cc))
+	}
+	if _, cc := dispatch(inBand, types.CommandSetBMCGlobalEnables, enable.Pack()); cc != types.CodeOK {
+		t.Fatalf("Set BMC Global Enables: cc=%#02x", uint8(cc))
+	}
+	resp, cc := dispatch(remote, types.CommandGetBMCGlobalEnables, nil)
+	enables := &sensor.GetBMCGlobalEnablesResponse{}
+	if err := enables.Unpack(resp); err != nil || cc != types.CodeOK || !enables.EventMessageBufferEnabled || !enables.SystemEventLoggingEnabled {
+		t.Fatalf("Get BMC Global Enables: cc=%#02x %+v %v", uint8(cc), enables, err)
+	}
+
+	// An event fills the buffer; the next one overflows it.
+	_ = b.LogEvent(ctx, event)
+	_ = b.LogEvent(ctx, event)
+	if !b.Messages.EventBufferOverflow() || b.Messages.EventBufferDiscards() != 1 {
+		t.Fatal("the second event should overflow the buffer")
+	}
+	resp, cc = dispatch(inBand, types.CommandGetMessageFlags, nil)
+	flags := &sensor.GetMessageFlagsResponse{}
+	if err := flags.Unpack(resp); err != nil || cc != types.CodeOK || !flags.EventMessageBufferFull {
+		t.Fatalf("message flags: cc=%#02x %+v %v", uint8(cc), flags, err)
+	}
+	if _, cc := dispatch(remote, types.CommandReadEventMessageBuffer, nil); cc != types.CodeInsufficientPrivilege {
+		t.Fatalf("Read Event Message Buffer over LAN: cc=%#02x, want D4h", uint8(cc))
+	}
+	resp, cc = dispatch(inBand, types.CommandReadEventMessageBuffer, nil)
+	read := &sensor.ReadEventMessageBufferResponse{}
+	if err := read.Unpack(resp); err != nil || cc != types.CodeOK || !bytes.Equal(read.MessageData[:], event.SELRecord()) {
+		t.Fatalf("Read Event Message Buffer: cc=%#02x % x %v", uint8(cc), read.MessageData, err)
+	}
+	if b.Messages.EventBufferOverflow() {
+		t.Fatal("reading the buffer should clear its overflow")
+	}
+	if _, cc := dispatch(inBand, types.CommandReadEventMessageBuffer, nil); cc != types.CodeDataNotAvailable {
+		t.Fatalf("empty buffer: cc=%#02x, want 80h", uint8(cc))
+	}
+
+	// Clear Message Flags empties the buffer and its overflow.
+	_ = b.LogEvent(ctx, event)
+	_ = b.LogEvent(ctx, event)
+	clear := &sensor.ClearMessageFlagsRequest{ClearEventMessageBuffer: true}
+	if _, cc := dispatch(inBand, types.CommandClearMessageFlags, clear.Pack()); cc != types.CodeOK ||
+		b.Messages.EventBufferFull() || b.Messages.EventBufferOverflow() {
+		t.Fatalf("clear message flags: cc=%#02x", uint8(cc))
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/app"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// systemInfoParamRevision is the parameter revision reported for every
+// System Info Parameter (v2.0§22.14b).
+const systemInfoParamRevision uint8 = 0x11
+
+// systemInfoCommandCC maps a System Info Parameter failure to its
+// completion code (v2.0§22.14).
+func systemInfoCommandCC(err error) types.CompletionCode {
+	switch {
+	case err == nil:
+		return types.CodeOK
+	case errors.Is(err, bmc.ErrSystemInfoParamNotSupported):
+		return types.CodeParameterNotSupported
+	case errors.Is(err, bmc.ErrSystemInfoSetInProgress):
+		return types.CodeParamConfigSetInProgressConflict
+	case errors.Is(err, bmc.ErrSystemInfoParamReadOnly):
+		return types.CodeParamConfigSetReadOnly
+	case errors.Is(err, bmc.ErrSystemInfoParamInvalid):
+		return types.CodeRequestDataFieldInvalid
+	case errors.Is(err, bmc.ErrSystemInfoParamOutOfRange):
+		return types.CodeParameterOutOfRange
+	default:
+		return codeFromHalErr(err)
+	}
+}
+
+// systemInfoFailure is the handler return for a System Info Parameter
+// failure. Only an unmapped error is passed on for logging.
+func systemInfoFailure(err error) ([]byte, types.CompletionCode, error) {
+	cc := systemInfoCommandCC(err)
+	if cc == types.CodeUnspecifiedError {
+		return nil, cc, err
+	}
+	return nil, cc, nil
+}
+
+// fromSystemInterface reports whether the request arrived on the system
+// interface, the channel the BIOS writes the firmware version over.
+func fromSystemInterface(hctx *HandlerContext) bool {
+	return hctx.Channel != nil && hctx.Channel.Medium == bmc.ChannelMediumSystemIF
+}
+
+// handleGetSystemInfoParam implements Get System Info Parameters (App
+// 0x59, v2.0§22.14b). Set In Progress (#0) answers with its state byte;
+// the string parameters (#1-#7) answer with the set selector followed by
+// its 16-byte block from [bmc.SystemInfoStore]. The block selector is not
+// used. A revision-only query answers with the revision alone, but still
+// 80h for an unsupported parameter.
+func handleGetSystemInfoParam(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.GetSystemInfoParamRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	info := hctx.BMC.SystemInfo
+	if info == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+
+	var data []byte
+	if request.ParamSelector == types.SystemInfoParamSelector_SetInProgress {
+		state := types.SetInProgress_SetComplete
+		if info.SetInProgress() {
+			state = types.SetInProgress_SetInProgress
+		}
+		data = []byte{byte(state)}
+	} else {
+		block, err := info.Block(ctx, request.ParamSelector, request.SetSelector)
+		if err != nil {
+			return systemInfoFailure(err)
+		}
+		data = append([]byte{request.SetSelector}, block...)
+	}
+
+	response := &app.GetSystemInfoParamResponse{ParamRevision: systemInfoParamRevision}
+	if !request.GetParamRevisionOnly {
+		response.ParamData = data
+	}
+	return response.Pack(), types.CodeOK, nil
+}
+
+// handleSetSystemInfoParam implements Set System Info Parameters (App
+// 0x58, v2.0§22.14a). The string parameters take the set selector followed
+// by up to 16 bytes of its block, staged while Set In Progress (#0) is "set
+// in progress" and stored by "commit write" or "set complete". The system
+// firmware version can only be written over the system interface and the
+// BMC URL not at all; both return 82h.
+func handleSetSystemInfoParam(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	request := &app.SetSystemInfoParamRequest{}
+	if err := request.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	info := hctx.BMC.SystemInfo
+	if info == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	data := request.ParamData
+
+	if request.ParamSelector == types.SystemInfoParamSelector_SetInProgress {
+		if len(data) != 1 {
+			return nil, types.CodeRequestDataLengthInvalid, nil
+		}
+		var err error
+		switch types.SetInProgressState(data[0] & 0x03) {
+		case types.SetInProgress_SetComplete:
+			err = info.Commit(ctx, true)
+		case types.SetInProgress_SetInProgress:
+			err = info.BeginSet()
+		case types.SetInProgress_CommitWrite:
+			err = info.Commit(ctx, false)
+		default:
+			err = bmc.ErrSystemInfoParamInvalid
+		}
+		if err != nil {
+			return systemInfoFailure(err)
+		}
+		return nil, types.CodeOK, nil
+	}
+
+	if err := info.Writable(request.ParamSelector, fromSystemInterface(hctx)); err != nil {
+		return systemInfoFailure(err)
+	}
+	if len(data) < 2 || len(data) > 1+bmc.SystemInfoBlockSize {
+		return nil, types.CodeRequestDataLengthInvalid, nil
+	}
+	if err := info.SetBlock(ctx, request.ParamSelector, data[0], data[1:], fromSystemInterface(hctx)); err != nil {
+		return systemInfoFailure(err)
+	}
+	return nil, types.CodeOK, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/bougou/go-ipmi/pkg/bmc"
 	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/command/app"
 	"github.com/bougou/go-ipmi/pkg/hal/mock"
 	"github.com/bougou/go-ipmi/pkg/types"
 )
@@ -100,3 +101,87 @@ func TestHandleColdReset(t *testing.T) {
 		t.Errorf("ColdResets: want 1, got %d", chassis.ColdResets)
 	}
 }
+
+func TestHandleSystemInfoParams(t *testing.T) {
+	b := newTestBMC()
+	lan := &HandlerContext{BMC: b}
+	system := &HandlerContext{BMC: b, Channel: &bmc.Channel{Medium: bmc.ChannelMediumSystemIF}}
+	ctx := context.Background()
+
+	set := func(hctx *HandlerContext, param types.SystemInfoParamSelector, data ...byte) types.CompletionCode {
+		req := &app.SetSystemInfoParamRequest{ParamSelector: param, ParamData: data}
+		_, cc, _ := handleSetSystemInfoParam(ctx, hctx, req.Pack())
+		return cc
+	}
+
+	// A guest agent writes the OS name over the system interface, two
+	// blocks long; a LAN client reads it back.
+	osName := "Debian GNU/Linux 13"
+	if cc := set(system, types.SystemInfoParamSelector_OSName, append([]byte{0, 0, byte(len(osName))}, osName[:14]...)...); cc != types.CodeOK {
+		t.Fatalf("set 0: cc=%#02x", uint8(cc))
+	}
+	if cc := set(system, types.SystemInfoParamSelector_OSName, append([]byte{1}, osName[14:]...)...); cc != types.CodeOK {
+		t.Fatalf("set 1: cc=%#02x", uint8(cc))
+	}
+	params := &types.SystemInfoParams{SetInProgress: &types.SystemInfoParam_SetInProgress{}}
+	for i := uint8(0); i < 2; i++ {
+		get := &app.GetSystemInfoParamRequest{ParamSelector: types.SystemInfoParamSelector_OSName, SetSelector: i}
+		resp, cc, _ := handleGetSystemInfoParam(ctx, lan, get.Pack())
+		var res app.GetSystemInfoParamResponse
+		if err := res.Unpack(resp); err != nil || cc != types.CodeOK || res.ParamRevision != systemInfoParamRevision {
+			t.Fatalf("get set %d: cc=%#02x %+v %v", i, uint8(cc), res, err)
+		}
+		p := &types.SystemInfoParam_OSName{}
+		if err := p.Unpack(res.ParamData); err != nil || p.SetSelector != i || len(p.BlockData) != 16 {
+			t.Fatalf("set %d data: %+v %v", i, p, err)
+		}
+		params.OSNames = append(params.OSNames, p)
+	}
+	if got := params.ToSystemInfo().OSName; got != osName {
+		t.Fatalf("OS name: %q", got)
+	}
+
+	// Read-only parameters and rules.
+	fw := []byte{0, 0, 3, '2', '.', '1'}
+	if cc := set(lan, types.SystemInfoParamSelector_SystemFirmwareVersion, fw...); cc != types.CodeParamConfigSetReadOnly {
+		t.Fatalf("firmware version over LAN: cc=%#02x", uint8(cc))
+	}
+	if cc := set(system, types.SystemInfoParamSelector_SystemFirmwareVersion, fw...); cc != types.CodeOK {
+		t.Fatalf("firmware version over the system interface: cc=%#02x", uint8(cc))
+	}
+	if cc := set(system, types.SystemInfoParamSelector_BMCURL, 0, 0, 1, 'x'); cc != types.CodeParamConfigSetReadOnly {
+		t.Fatalf("BMC URL: cc=%#02x", uint8(cc))
+	}
+	if cc := set(lan, 0x40, 0, 0, 1, 'x'); cc != types.CodeParameterNotSupported {
+		t.Fatalf("unknown parameter: cc=%#02x", uint8(cc))
+	}
+	get := &app.GetSystemInfoParamRequest{GetParamRevisionOnly: true, ParamSelector: 0x40}
+	if _, cc, _ := handleGetSystemInfoParam(ctx, lan, get.Pack()); cc != types.CodeParameterNotSupported {
+		t.Fatalf("revision of an unknown parameter: cc=%#02x", uint8(cc))
+	}
+
+	// Set in progress stages writes until commit; a second claim conflicts.
+	if cc := set(lan, types.SystemInfoParamSelector_SetInProgress, 0x01); cc != types.CodeOK {
+		t.Fatalf("set in progress: cc=%#02x", uint8(cc))
+	}
+	if cc := set(lan, types.SystemInfoParamSelector_SetInProgress, 0x01); cc != types.CodeParamConfigSetInProgressConflict {
+		t.Fatalf("second set in progress: cc=%#02x", uint8(cc))
+	}
+	get = &app.GetSystemInfoParamRequest{ParamSelector: types.SystemInfoParamSelector_SetInProgress}
+	if resp, _, _ := handleGetSystemInfoParam(ctx, lan, get.Pack()); len(resp) != 2 || resp[1] != byte(types.SetInProgress_SetInProgress) {
+		t.Fatalf("set in progress state: % x", resp)
+	}
+	_, _, _ = handleColdReset(ctx, lan, nil)
+	if b.SystemInfo.SetInProgress() {
+		t.Fatal("cold reset left the set in progress")
+	}
+
+	// Without a store the commands are not supported.
+	b.SystemInfo = nil
+	if _, cc, _ := handleGetSystemInfoParam(ctx, lan, get.Pack()); cc != types.CodeNotSupported {
+		t.Fatalf("get without a store: cc=%#02x", uint8(cc))
+	}
+	if cc := set(lan, types.SystemInfoParamSelector_SetInProgress, 0x01); cc != types.CodeNotSupported {
+		t.Fatalf("set without a store: cc=%#02x", uint8(cc))
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// Audit record messages. Every record carries the requester's identity
+// (user, channel, session, session_id, remote, privilege) and its outcome.
+const (
+	AuditMsgCommand            = "ipmi command"
+	AuditMsgSessionEstablished = "ipmi session established"
+	AuditMsgSessionRejected    = "ipmi session authentication failed"
+	AuditMsgSessionClosed      = "ipmi session closed"
+)
+
+// auditedCommands are the state-changing commands [AuditLog] records: power
+// and boot control, user and password management, channel, LAN, SOL, PEF and
+// system info configuration, the firmware firewall's command enables, sensor
+// thresholds, FRU writes, messages sent to another channel, and erasing the
+// SEL.
+var auditedCommands = map[types.CommandKey]bool{
+	types.CommandChassisControl.Key():       true,
+	types.CommandSetSystemBootOptions.Key(): true,
+	types.CommandSetUsername.Key():          true,
+	types.CommandSetUserAccess.Key():        true,
+	types.CommandSetUserPassword.Key():      true,
+	types.CommandSetUserPayloadAccess.Key(): true,
+	types.CommandSetChannelAccess.Key():     true,
+	types.CommandSetLanConfigParam.Key():    true,
+	types.CommandSetSOLConfigParam.Key():    true,
+	types.CommandSetPEFConfigParam.Key():    true,
+	types.CommandSetSystemInfoParam.Key():   true,
+	types.CommandSetCommandEnables.Key():    true,
+	types.CommandSetSensorThresholds.Key():  true,
+	types.CommandWriteFRUData.Key():         true,
+	types.CommandSendMessage.Key():          true,
+	types.CommandClearSEL.Key():             true,
+}
+
+// changesState reports whether req asks its command to change state. Two
+// audited commands also have a read-only form: Set User Password's test
+// password operation (v2.0§22.30) and Clear SEL's get erasure status
+// (v2.0§31.9).
+func changesState(c types.Command, req []byte) bool {
+	switch c.Key() {
+	case types.CommandSetUserPassword.Key():
+		return len(req) < 2 || req[1]&0x03 != passwordOpTestPassword
+	case types.CommandClearSEL.Key():
+		return len(req) < 6 || req[5] != 0x00
+	}
+	return auditedCommands[c.Key()]
+}
+
+// AuditLog returns middleware that writes a [log/slog] audit record to
+// logger for every state-changing command, and for the session commands
+// that establish (v1.5 Activate Session) and close a session. Records of
+// rejected requests, privilege rejections included, are logged at Warn.
+//
+// The RMCP+ handshake, and a v1.5 activation refused before dispatch, do not
+// pass through the registry; the server logs those with [AuditSession].
+// server.WithAuditLogger does both.
+func AuditLog(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+			resp, cc, err := next.Handle(ctx, hctx, req)
+			if hctx == nil {
+				return resp, cc, err
+			}
+
+			var msg string
+			switch key := hctx.Command.Key(); {
+			case key == types.CommandActivateSession.Key():
+				msg = AuditMsgSessionEstablished
+				if cc != types.CodeOK {
+					msg = AuditMsgSessionRejected
+				}
+			case key == types.CommandCloseSession.Key() && cc == types.CodeOK:
+				msg = AuditMsgSessionClosed
+			case changesState(hctx.Command, req):
+				msg = AuditMsgCommand
+			default:
+				return resp, cc, err
+			}
+
+			attrs := append(requestAuditAttrs(hctx),
+				slog.String("command", hctx.Command.Name),
+				slog.String("netfn", fmt.Sprintf("0x%02x", uint8(hctx.Command.NetFn))),
+				slog.String("cmd", fmt.Sprintf("0x%02x", hctx.Command.ID)),
+				slog.String("outcome", auditOutcome(cc == types.CodeOK)),
+				slog.String("cc", fmt.Sprintf("0x%02x", uint8(cc))),
+			)
+			level := slog.LevelInfo
+			if cc != types.CodeOK {
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.String("cc_desc", types.StrCC(hctx.Command, uint8(cc))))
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+			logger.LogAttrs(ctx, level, msg, attrs...)
+			return resp, cc, err
+		})
+	}
+}
+
+// requestAuditAttrs identifies who sent the request. A v1.5 session's ID is
+// read from the session rather than the request, so a successful Activate
+// Session reports the permanent ID that later records carry.
+func requestAuditAttrs(hctx *HandlerContext) []slog.Attr {
+	user := ""
+	if hctx.User != nil {
+		user = hctx.User.Name
+	}
+	var channel, handle uint8
+	if hctx.Channel != nil {
+		channel = hctx.Channel.Number
+	}
+	sessionID := hctx.SessionID
+	privilege := "-"
+	switch {
+	case hctx.Session != nil:
+		handle = hctx.Session.Handle
+		privilege = types.PrivilegeLevel(hctx.Session.PrivilegeLevel).String()
+	case hctx.V15Session != nil:
+		handle = hctx.V15Session.Handle
+		privilege = types.PrivilegeLevel(hctx.V15Session.PrivilegeLevel).String()
+		if hctx.V15Session.SessionID != 0 {
+			sessionID = hctx.V15Session.SessionID
+		}
+	}
+	return []slog.Attr{
+		slog.String("user", user),
+		slog.Int("channel", int(channel)),
+		slog.Int("session", int(handle)),
+		slog.String("session_id", fmt.Sprintf("0x%08x", sessionID)),
+		slog.String("remote", addrString(hctx.RemoteAddr)),
+		slog.String("privilege", privilege),
+	}
+}
+
+// SessionAudit describes one session establishment attempt for
+// [AuditSession], or one session closed without a Close Session request for
+// [AuditSessionClosed].
+type SessionAudit struct {
+	// User is the user name the console asked for.
+	User       string
+	Channel    uint8
+	Handle     uint8
+	SessionID  uint32
+	RemoteAddr net.Addr
+	// Privilege is the privilege the session was granted or, for a
+	// rejected one, requested; for a closed one, its maximum privilege.
+	Privilege types.PrivilegeLevel
+	// Reason is why the session was rejected, such as the RMCP+ status
+	// code the BMC answered, or why it was closed; empty for an established
+	// session.
+	Reason string
+}
+
+// AuditSession writes the audit record of a session establishment attempt in
+// the form [AuditLog] uses: established at Info, rejected at Warn with its
+// reason.
+func AuditSession(ctx context.Context, logger *slog.Logger, a SessionAudit) {
+	ok := a.Reason == ""
+	attrs := []slog.Attr{
+		slog.String("user", a.User),
+		slog.Int("channel", int(a.Channel)),
+		slog.Int("session", int(a.Handle)),
+		slog.String("session_id", fmt.Sprintf("0x%08x", a.SessionID)),
+		slog.String("remote", addrString(a.RemoteAddr)),
+		slog.String("privilege", a.Privilege.String()),
+		slog.String("outcome", auditOutcome(ok)),
+	}
+	if ok {
+		logger.LogAttrs(ctx, slog.LevelInfo, AuditMsgSessionEstablished, attrs...)
+		return
+	}
+	attrs = append(attrs, slog.String("reason", a.Reason))
+	logger.LogAttrs(ctx, slog.LevelWarn, AuditMsgSessionRejected, attrs...)
+}
+
+// AuditSessionClosed writes the audit record of a session the BMC closed on
+// its own, such as one evicted for inactivity, at Info with its reason. A
+// Close Session request is recorded by [AuditLog] instead.
+func AuditSessionClosed(ctx context.Context, logger *slog.Logger, a SessionAudit) {
+	logger.LogAttrs(ctx, slog.LevelInfo, AuditMsgSessionClosed,
+		slog.String("user", a.User),
+		slog.Int("channel", int(a.Channel)),
+		slog.Int("session", int(a.Handle)),
+		slog.String("session_id", fmt.Sprintf("0x%08x", a.SessionID)),
+		slog.String("remote", addrString(a.RemoteAddr)),
+		slog.String("privilege", a.Privilege.String()),
+		slog.String("outcome", auditOutcome(true)),
+		slog.String("reason", a.Reason),
+	)
+}
+
+func auditOutcome(ok bool) string {
+	if ok {
+		return "success"
+	}
+	return "failure"
+}
+
+func addrString(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	return addr.String()
+}
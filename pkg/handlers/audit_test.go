@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// auditRecords decodes the JSON audit records written to buf.
+func auditRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var r map[string]any
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("audit record %q: %v", line, err)
+		}
+		records = append(records, r)
+	}
+	buf.Reset()
+	return records
+}
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	reg := NewRegistry()
+	reg.Use(AuditLog(slog.New(slog.NewJSONHandler(&buf, nil))))
+	RegisterAllHandlers(reg)
+
+	b := newTestBMCWithMock(mock.New())
+	user, err := b.Users.Add(2, "operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, _ := b.Channels.Get(1)
+	sess, err := b.Sessions.Allocate(1, types.AuthAlg_HMAC_SHA1, types.IntegrityAlg_None, types.CryptAlg_None, bmc.PrivilegeLevelOperator, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Sessions.Activate(sess.BMCID); err != nil {
+		t.Fatal(err)
+	}
+	sess.User = user
+	sess.PrivilegeLevel = bmc.PrivilegeLevelOperator
+	hctx := func() *HandlerContext {
+		return &HandlerContext{
+			BMC:        b,
+			Session:    sess,
+			SessionID:  sess.BMCID,
+			Channel:    ch,
+			User:       user,
+			RemoteAddr: &net.UDPAddr{IP: net.IPv4(192, 0, 2, 7), Port: 40000},
+		}
+	}
+	dispatch := func(c types.Command, req []byte) {
+		t.Helper()
+		if _, _, err := reg.Dispatch(context.Background(), hctx(), uint8(c.NetFn), c.ID, req); err != nil {
+			t.Fatalf("%s: %v", c.Name, err)
+		}
+	}
+
+	// Chassis Control (power off) changes state: one record with the
+	// requester's identity and the outcome.
+	dispatch(types.CommandChassisControl, []byte{0x00})
+	records := auditRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("Chassis Control: %d records, want 1", len(records))
+	}
+	for k, want := range map[string]any{
+		"level":      "INFO",
+		"msg":        AuditMsgCommand,
+		"command":    "Chassis Control",
+		"user":       "operator",
+		"channel":    float64(1),
+		"session":    float64(sess.Handle),
+		"session_id": fmt.Sprintf("0x%08x", sess.BMCID),
+		"remote":     "192.0.2.7:40000",
+		"privilege":  "OPERATOR",
+		"outcome":    "success",
+		"cc":         "0x00",
+	} {
+		if got := records[0][k]; got != want {
+			t.Errorf("record[%q] = %v, want %v", k, got, want)
+		}
+	}
+
+	// Reads, and the read-only forms of audited commands, are not recorded.
+	dispatch(types.CommandGetDeviceID, nil)
+	dispatch(types.CommandClearSEL, []byte{0, 0, 'C', 'L', 'R', 0x00})
+	dispatch(types.CommandSetUserPassword, append([]byte{2, 0x03}, make([]byte, 16)...))
+	if records := auditRecords(t, &buf); len(records) != 0 {
+		t.Errorf("read-only commands recorded: %v", records)
+	}
+
+	// A privilege rejection is a failed attempt worth recording.
+	dispatch(types.CommandSetUsername, append([]byte{3}, []byte("intruder\x00\x00\x00\x00\x00\x00\x00\x00")...))
+	records = auditRecords(t, &buf)
+	if len(records) != 1 || records[0]["level"] != "WARN" || records[0]["outcome"] != "failure" ||
+		records[0]["cc"] != "0xd4" {
+		t.Errorf("rejected Set User Name: %v", records)
+	}
+
+	// So are the other configuration writes, whatever their outcome.
+	for _, c := range []types.Command{
+		types.CommandSetCommandEnables,
+		types.CommandSetPEFConfigParam,
+		types.CommandSetSystemInfoParam,
+		types.CommandWriteFRUData,
+		types.CommandSetSensorThresholds,
+		types.CommandSendMessage,
+	} {
+		dispatch(c, []byte{0x01})
+		if records := auditRecords(t, &buf); len(records) != 1 || records[0]["command"] != c.Name {
+			t.Errorf("%s: %v", c.Name, records)
+		}
+	}
+
+	// Closing the session is recorded as such.
+	dispatch(types.CommandCloseSession, binary.LittleEndian.AppendUint32(nil, sess.BMCID))
+	records = auditRecords(t, &buf)
+	if len(records) != 1 || records[0]["msg"] != AuditMsgSessionClosed {
+		t.Errorf("Close Session: %v", records)
+	}
+}
+
+func TestAuditSession(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	AuditSession(context.Background(), logger, SessionAudit{
+		User:      "admin",
+		Channel:   1,
+		Handle:    1,
+		SessionID: 0x42,
+		Privilege: types.PrivilegeLevelAdministrator,
+	})
+	AuditSession(context.Background(), logger, SessionAudit{
+		User:      "guest",
+		Channel:   1,
+		Privilege: types.PrivilegeLevelAdministrator,
+		Reason:    types.RmcpStatusCodeUnauthorizedName.String(),
+	})
+	AuditSessionClosed(context.Background(), logger, SessionAudit{
+		User:      "admin",
+		Channel:   1,
+		Handle:    1,
+		SessionID: 0x42,
+		Privilege: types.PrivilegeLevelAdministrator,
+		Reason:    string(bmc.EvictTimeout),
+	})
+	records := auditRecords(t, &buf)
+	if len(records) != 3 {
+		t.Fatalf("%d records, want 3", len(records))
+	}
+	if r := records[0]; r["msg"] != AuditMsgSessionEstablished || r["outcome"] != "success" || r["privilege"] != "ADMINISTRATOR" {
+		t.Errorf("established: %v", r)
+	}
+	if r := records[1]; r["msg"] != AuditMsgSessionRejected || r["level"] != "WARN" || r["reason"] != "Unauthorized name" || r["user"] != "guest" {
+		t.Errorf("rejected: %v", r)
+	}
+	if r := records[2]; r["msg"] != AuditMsgSessionClosed || r["level"] != "INFO" || r["reason"] != "timeout" || r["session_id"] != "0x00000042" {
+		t.Errorf("closed: %v", r)
+	}
+}
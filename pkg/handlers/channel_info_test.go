@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/app"
+	"github.com/bougou/go-ipmi/pkg/command/chassis"
 	"github.com/bougou/go-ipmi/pkg/types"
 )
 
@@ -147,3 +149,83 @@ func TestHandleGetChannelInfoErrors(t *testing.T) {
 		}
 	})
 }
+
+func TestHandleChannelAccess(t *testing.T) {
+	b := newTestBMC()
+	lan, _ := b.Channels.Get(lanChannelNumber)
+	hctx := &HandlerContext{BMC: b, Channel: lan}
+	ctx := context.Background()
+
+	set := func(req *app.SetChannelAccessRequest) types.CompletionCode {
+		_, cc, _ := handleSetChannelAccess(ctx, hctx, req.Pack())
+		return cc
+	}
+	get := func(option types.ChannelAccessOption) *app.GetChannelAccessResponse {
+		t.Helper()
+		req := &app.GetChannelAccessRequest{ChannelNumber: types.ChannelNumberSelf, AccessOption: option}
+		resp, cc, _ := handleGetChannelAccess(ctx, hctx, req.Pack())
+		res := &app.GetChannelAccessResponse{}
+		if err := res.Unpack(resp); err != nil || cc != types.CodeOK {
+			t.Fatalf("get channel access: cc=%#02x %v", uint8(cc), err)
+		}
+		return res
+	}
+
+	// Privilege limit to volatile, access settings to non-volatile.
+	if cc := set(&app.SetChannelAccessRequest{
+		ChannelNumber:      types.ChannelNumberSelf,
+		AccessOption:       channelAccessNonVolatile,
+		DisablePEFAlerting: true,
+		DisablePerMsgAuth:  true,
+		AccessMode:         types.ChannelAccessMode_PrebootOnly,
+		PrivilegeOption:    channelAccessVolatile,
+		MaxPrivilegeLevel:  uint8(bmc.PrivilegeLevelOperator),
+	}); cc != types.CodeOK {
+		t.Fatalf("set: cc=%#02x", uint8(cc))
+	}
+	v, nv := get(types.ChannelAccessOption_Volatile), get(types.ChannelAccessOption_NonVolatile)
+	if v.AccessMode != types.ChannelAccessMode_AlwaysAvailable || v.PerMsgAuthDisabled || v.MaxPrivilegeLevel != types.PrivilegeLevelOperator {
+		t.Fatalf("volatile: %+v", v)
+	}
+	if nv.AccessMode != types.ChannelAccessMode_PrebootOnly || !nv.PerMsgAuthDisabled || !nv.PEFAlertingDisabled || nv.UserLevelAuthDisabled || nv.MaxPrivilegeLevel != types.PrivilegeLevelAdministrator {
+		t.Fatalf("non-volatile: %+v", nv)
+	}
+
+	// The lowered limit caps a running Administrator session.
+	hctx.Channel, _ = b.Channels.Get(lanChannelNumber)
+	hctx.Session = &bmc.Session{PrivilegeLevel: bmc.PrivilegeLevelAdministrator}
+	if cc := checkCommandPrivilege(hctx, NetFnAppRequest, CmdSetChannelAccess); cc != types.CodeInsufficientPrivilege {
+		t.Fatalf("capped session: cc=%#02x", uint8(cc))
+	}
+
+	// Cold reset restores the non-volatile copy: pre-boot only, available
+	// while the system is off.
+	b.Channels.RestoreAccess()
+	lan, _ = b.Channels.Get(lanChannelNumber)
+	if lan.MaxPrivilege != bmc.PrivilegeLevelAdministrator || lan.PEFAlerts || !b.ChannelAvailable(ctx, lan) {
+		t.Fatalf("restored: %+v", lan)
+	}
+	powerUp := []byte{byte(chassis.ChassisControlPowerUp)}
+	if _, cc, err := handleChassisControl(ctx, hctx, powerUp); err != nil || cc != types.CodeOK {
+		t.Fatalf("power up: cc=%#02x err=%v", uint8(cc), err)
+	}
+	if b.ChannelAvailable(ctx, lan) {
+		t.Fatal("pre-boot only channel available with the system powered on")
+	}
+
+	for name, tc := range map[string]struct {
+		req  app.SetChannelAccessRequest
+		want types.CompletionCode
+	}{
+		"shared":           {app.SetChannelAccessRequest{ChannelNumber: 1, AccessOption: channelAccessVolatile, AccessMode: types.ChannelAccessMode_Shared}, types.CodeSetChannelAccessModeNotSupported},
+		"reserved mode":    {app.SetChannelAccessRequest{ChannelNumber: 1, AccessOption: channelAccessVolatile, AccessMode: 5}, types.CodeRequestDataFieldInvalid},
+		"reserved option":  {app.SetChannelAccessRequest{ChannelNumber: 1, AccessOption: 3}, types.CodeRequestDataFieldInvalid},
+		"no privilege":     {app.SetChannelAccessRequest{ChannelNumber: 1, PrivilegeOption: channelAccessVolatile}, types.CodeRequestDataFieldInvalid},
+		"unknown channel":  {app.SetChannelAccessRequest{ChannelNumber: 7}, types.CodeRequestDataFieldInvalid},
+		"system interface": {app.SetChannelAccessRequest{ChannelNumber: 0x0f, AccessOption: channelAccessVolatile}, types.CodeSetChannelAccessSetNotSupported},
+	} {
+		if cc := set(&tc.req); cc != tc.want {
+			t.Errorf("%s: cc=%#02x, want %#02x", name, uint8(cc), uint8(tc.want))
+		}
+	}
+}
@@ -2,14 +2,21 @@ package handlers
 
 import (
 	"context"
+	"errors"
 
+	"github.com/bougou/go-ipmi/pkg/bmc"
 	"github.com/bougou/go-ipmi/pkg/command/chassis"
+	"github.com/bougou/go-ipmi/pkg/hal"
 	"github.com/bougou/go-ipmi/pkg/types"
 )
 
 // IPMI Chassis command IDs (spec §28).
 const (
-	CmdChassisControl uint8 = 0x02
+	CmdChassisControl         uint8 = 0x02
+	CmdSetChassisCapabilities uint8 = 0x05
+	CmdSetPowerRestorePolicy  uint8 = 0x06
+	CmdSetFrontPanelEnables   uint8 = 0x0a
+	CmdSetPowerCycleInterval  uint8 = 0x0b
 )
 
 // RegisterChassisHandlers adds all Chassis command handlers to r.
@@ -20,15 +27,165 @@ func RegisterChassisHandlers(r *Registry) {
 	r.RegisterFunc(types.CommandChassisIdentify, handleChassisIdentify)
 	r.RegisterFunc(types.CommandSetSystemBootOptions, handleSetSystemBootOptions)
 	r.RegisterFunc(types.CommandGetSystemBootOptions, handleGetSystemBootOptions)
+	r.RegisterFunc(types.CommandSetChassisCapabilities, handleSetChassisCapabilities)
+	r.RegisterFunc(types.CommandSetPowerRestorePolicy, handleSetPowerRestorePolicy)
+	r.RegisterFunc(types.CommandGetSystemRestartCause, handleGetSystemRestartCause)
+	r.RegisterFunc(types.CommandSetFrontPanelEnables, handleSetFrontPanelEnables)
+	r.RegisterFunc(types.CommandSetPowerCycleInterval, handleSetPowerCycleInterval)
+	r.RegisterFunc(types.CommandGetPOHCounter, handleGetPOHCounter)
 }
 
-// handleGetChassisCapabilities returns a minimal static response.
-// Real implementations may override this handler to report actual front-panel
-// button and FRUS device addresses.
-func handleGetChassisCapabilities(_ context.Context, _ *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
-	// Byte 0: capabilities flags (all disabled in reference impl)
-	// Byte 1-4: FRU info device address, SDR device, SEL device, System management device
-	return []byte{0x00, 0x20, 0x20, 0x20, 0x20}, types.CodeOK, nil
+// chassisCommandCC maps a chassis state failure to its completion code
+// (v2.0§28). Like Get Chassis Status, the chassis commands define no
+// command-specific codes, so HAL errors go through codeFromErr.
+func chassisCommandCC(err error) types.CompletionCode {
+	switch {
+	case err == nil:
+		return types.CodeOK
+	case errors.Is(err, bmc.ErrChassisNotPresent):
+		return types.CodeNotSupported
+	case errors.Is(err, bmc.ErrPowerRestorePolicyInvalid):
+		return types.CodeRequestDataFieldInvalid
+	default:
+		return codeFromErr(err)
+	}
+}
+
+// chassisFailure is the handler return for a chassis state failure. Only
+// an unmapped error is passed on for logging.
+func chassisFailure(err error) ([]byte, types.CompletionCode, error) {
+	cc := chassisCommandCC(err)
+	if cc == types.CodeUnspecifiedError {
+		return nil, cc, err
+	}
+	return nil, cc, nil
+}
+
+// handleGetChassisCapabilities implements Get Chassis Capabilities (Chassis
+// 0x00, spec §28.1) from the capabilities Set Chassis Capabilities
+// configures. Diagnostic interrupt is reported when the chassis implements
+// [hal.DiagnosticInterruptHAL]; the reference BMC has no power interlock.
+func handleGetChassisCapabilities(_ context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	caps := hctx.BMC.Chassis.Capabilities()
+	resp := &chassis.GetChassisCapabilitiesResponse{
+		ProvideFrontPanelLockout:      caps.FrontPanelLockout,
+		ProvideIntrusionSensor:        caps.IntrusionSensor,
+		FRUDeviceAddress:              caps.FRUDeviceAddress,
+		SDRDeviceAddress:              caps.SDRDeviceAddress,
+		SELDeviceAddress:              caps.SELDeviceAddress,
+		SystemManagementDeviceAddress: caps.SystemManagementDeviceAddress,
+		BridgeDeviceAddress:           caps.BridgeDeviceAddress,
+	}
+	if ch := hctx.BMC.HAL().Chassis(); ch != nil {
+		_, resp.ProvideDiagnosticInterrupt = ch.(hal.DiagnosticInterruptHAL)
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetChassisCapabilities implements Set Chassis Capabilities (Chassis
+// 0x05, spec §28.7). The settings only change what Get Chassis
+// Capabilities reports.
+func handleSetChassisCapabilities(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	var typed chassis.SetChassisCapabilitiesRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	hctx.BMC.Chassis.SetCapabilities(bmc.ChassisCapabilities{
+		FrontPanelLockout:             typed.ProvideFrontPanelLockout,
+		IntrusionSensor:               typed.ProvideIntrusionSensor,
+		FRUDeviceAddress:              typed.FRUDeviceAddress,
+		SDRDeviceAddress:              typed.SDRDeviceAddress,
+		SELDeviceAddress:              typed.SELDeviceAddress,
+		SystemManagementDeviceAddress: typed.SystemManagementDeviceAddress,
+		BridgeDeviceAddress:           typed.BridgeDeviceAddress,
+	})
+	return nil, types.CodeOK, nil
+}
+
+// handleSetPowerRestorePolicy implements Set Power Restore Policy (Chassis
+// 0x06, spec §28.8). Every policy is supported; the server applies the
+// policy when it starts. The response lists the supported policies, which
+// is all a "no change" request asks for.
+func handleSetPowerRestorePolicy(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	var typed chassis.SetPowerRestorePolicyRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if err := hctx.BMC.Chassis.SetRestorePolicy(uint8(typed.PowerRestorePolicy)); err != nil {
+		return chassisFailure(err)
+	}
+	resp := &chassis.SetPowerRestorePolicyResponse{
+		SupportPolicyAlwaysOn:  true,
+		SupportPolicyPrevious:  true,
+		SupportPolicyAlwaysOff: true,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetSystemRestartCause implements Get System Restart Cause (Chassis
+// 0x07, spec §28.11). Chassis Control, watchdog and PEF restarts and the
+// restore policy record the cause; before any of them it is unknown.
+func handleGetSystemRestartCause(_ context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	cause, channel := hctx.BMC.Chassis.RestartCause()
+	resp := &chassis.GetSystemRestartCauseResponse{
+		SystemRestartCause: chassis.SystemRestartCause(cause),
+		ChannelNumber:      channel,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetFrontPanelEnables implements Set Front Panel Enables (Chassis
+// 0x0A, spec §28.6). The reference BMC allows every button to be disabled
+// and reports the result in Get Chassis Status.
+func handleSetFrontPanelEnables(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	var typed chassis.SetFrontPanelEnablesRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	var disables uint8
+	if typed.DisablePoweroffButton {
+		disables |= bmc.FrontPanelDisablePowerOff
+	}
+	if typed.DisableResetButton {
+		disables |= bmc.FrontPanelDisableReset
+	}
+	if typed.DisableDiagnosticButton {
+		disables |= bmc.FrontPanelDisableDiagnostic
+	}
+	if typed.DisableSleepButton {
+		disables |= bmc.FrontPanelDisableStandby
+	}
+	hctx.BMC.Chassis.SetFrontPanelDisables(disables)
+	return nil, types.CodeOK, nil
+}
+
+// handleSetPowerCycleInterval implements Set Power Cycle Interval (Chassis
+// 0x0B, spec §28.9), passed on to a chassis that implements
+// [hal.PowerCycleIntervalHAL].
+func handleSetPowerCycleInterval(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	var typed chassis.SetPowerCycleIntervalRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if err := hctx.BMC.Chassis.SetPowerCycleInterval(ctx, typed.IntervalInSec); err != nil {
+		return chassisFailure(err)
+	}
+	return nil, types.CodeOK, nil
+}
+
+// handleGetPOHCounter implements Get POH Counter (Chassis 0x0F, spec
+// §28.14): the minutes the system has been powered on since the BMC
+// started, counted from the BMC clock.
+func handleGetPOHCounter(ctx context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	counter, err := hctx.BMC.Chassis.POHCounter(ctx)
+	if err != nil {
+		return chassisFailure(err)
+	}
+	resp := &chassis.GetPOHCounterResponse{
+		MinutesPerCount: bmc.POHMinutesPerCount,
+		CounterReading:  counter,
+	}
+	return resp.Pack(), types.CodeOK, nil
 }
 
 // handleGetChassisStatus implements Get Chassis Status (Chassis 0x01, spec §28.2).
@@ -56,6 +213,19 @@ func handleGetChassisStatus(ctx context.Context, hctx *HandlerContext, _ []byte)
 		}
 		resp.ChassisIdentifySupported = true
 	}
+
+	state := hctx.BMC.Chassis
+	resp.PowerRestorePolicy = chassis.PowerRestorePolicy(state.RestorePolicy())
+	resp.LastPowerOnByCommand = state.PowerOnByCommand()
+	disables := state.FrontPanelDisables()
+	resp.SleepButtonDisableAllowed = true
+	resp.DiagnosticButtonDisableAllowed = true
+	resp.ResetButtonDisableAllowed = true
+	resp.PoweroffButtonDisableAllowed = true
+	resp.SleepButtonDisabled = disables&bmc.FrontPanelDisableStandby != 0
+	resp.DiagnosticButtonDisabled = disables&bmc.FrontPanelDisableDiagnostic != 0
+	resp.ResetButtonDisabled = disables&bmc.FrontPanelDisableReset != 0
+	resp.PoweroffButtonDisabled = disables&bmc.FrontPanelDisablePowerOff != 0
 	return resp.Pack(), types.CodeOK, nil
 }
 
@@ -78,24 +248,55 @@ func handleChassisControl(ctx context.Context, hctx *HandlerContext, req []byte)
 
 	switch typed.ChassisControl {
 	case chassis.ChassisControlPowerDown:
-		return nil, codeFromErr(ch.SetPower(ctx, false)), nil
+		return nil, codeFromErr(powerChanged(ctx, hctx, ch.SetPower(ctx, false))), nil
 	case chassis.ChassisControlPowerUp:
-		return nil, codeFromErr(ch.SetPower(ctx, true)), nil
+		return nil, chassisRestart(hctx, powerChanged(ctx, hctx, ch.SetPower(ctx, true))), nil
 	case chassis.ChassisControlPowerCycle:
-		return nil, codeFromErr(ch.PowerCycle(ctx)), nil
+		return nil, chassisRestart(hctx, powerChanged(ctx, hctx, ch.PowerCycle(ctx))), nil
 	case chassis.ChassisControlHardReset:
-		return nil, codeFromErr(ch.ColdReset(ctx)), nil
+		return nil, chassisRestart(hctx, ch.ColdReset(ctx)), nil
 	case chassis.ChassisControlSoftShutdown:
 		return nil, codeFromErr(ch.WarmReset(ctx)), nil
 	case chassis.ChassisControlDiagnosticInterrupt:
-		// No corresponding typed HAL method in the reference interface;
-		// treat as an unsupported chassis control action per spec.
-		return nil, types.CodeParameterOutOfRange, nil
+		// Optional in the HAL; a chassis that cannot pulse a diagnostic
+		// interrupt reports the action as unsupported per spec.
+		di, ok := ch.(hal.DiagnosticInterruptHAL)
+		if !ok {
+			return nil, types.CodeParameterOutOfRange, nil
+		}
+		return nil, codeFromErr(di.DiagnosticInterrupt(ctx)), nil
 	default:
 		return nil, types.CodeParameterOutOfRange, nil
 	}
 }
 
+// powerChanged samples the power state after a successful Chassis Control
+// action changed it, so that pre-boot only channels follow at once rather
+// than on the next poll, and returns err.
+func powerChanged(ctx context.Context, hctx *HandlerContext, err error) error {
+	if err == nil {
+		// The action succeeded; a failed sample only delays its effect
+		// on the channels until the next poll.
+		_ = hctx.BMC.Chassis.Poll(ctx)
+	}
+	return err
+}
+
+// chassisRestart records a successful Chassis Control power-up, power cycle
+// or hard reset as the system restart cause, with the channel the command
+// arrived on (spec Table 28-11), and returns the completion code for err.
+func chassisRestart(hctx *HandlerContext, err error) types.CompletionCode {
+	if err != nil {
+		return codeFromErr(err)
+	}
+	var channel uint8
+	if hctx.Channel != nil {
+		channel = hctx.Channel.Number
+	}
+	hctx.BMC.Chassis.RecordRestart(bmc.RestartCauseChassisControl, channel)
+	return types.CodeOK
+}
+
 // handleChassisIdentify implements Chassis Identify (Chassis 0x04).
 func handleChassisIdentify(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
 	ch := hctx.BMC.HAL().Chassis()
@@ -154,7 +355,7 @@ func handleSetSystemBootOptions(ctx context.Context, hctx *HandlerContext, req [
 		if err := ack.Unpack(paramData); err != nil {
 			return nil, types.CodeRequestDataTruncated, nil
 		}
-		return nil, codeFromHalErr(ch.SetBootInfoAcknowledge(ctx, &ack)), nil
+		return nil, codeFromHalErr(hctx.BMC.Chassis.SetBootInfoAcknowledge(ctx, &ack)), nil
 
 	case types.BootOptionParamSelector_BootFlags:
 		if len(paramData) == 0 {
@@ -164,7 +365,7 @@ func handleSetSystemBootOptions(ctx context.Context, hctx *HandlerContext, req [
 		if err := flags.Unpack(paramData); err != nil {
 			return nil, types.CodeRequestDataTruncated, nil
 		}
-		return nil, codeFromHalErr(ch.SetBootFlags(ctx, &flags)), nil
+		return nil, codeFromHalErr(hctx.BMC.Chassis.SetBootFlags(ctx, &flags)), nil
 
 	case types.BootOptionParamSelector_BMCBootFlagValidBitClear:
 		// Parameter #3 (§28.14 Table 28-14) suppresses the conditions under
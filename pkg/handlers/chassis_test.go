@@ -107,6 +107,7 @@ func TestHandleChassisControl_TypedDispatch(t *testing.T) {
 		{"PowerUp", chassis.ChassisControlPowerUp, func(c *mock.Chassis) bool { return c.On }},
 		{"HardReset", chassis.ChassisControlHardReset, func(c *mock.Chassis) bool { return c.ColdResets == 1 }},
 		{"SoftShutdown", chassis.ChassisControlSoftShutdown, func(c *mock.Chassis) bool { return c.WarmResets == 1 }},
+		{"DiagnosticInterrupt", chassis.ChassisControlDiagnosticInterrupt, func(c *mock.Chassis) bool { return c.DiagInterrupts == 1 }},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -278,3 +279,122 @@ func TestCodeFromHalErr(t *testing.T) {
 		t.Fatalf("generic error: want types.CodeUnspecifiedError, got %d", got)
 	}
 }
+
+func TestHandleChassisControl_RecordsRestartCause(t *testing.T) {
+	b := newTestBMCWithMock(mock.New())
+	hctx := &HandlerContext{BMC: b, Channel: &bmc.Channel{Number: lanChannelNumber, Medium: bmc.ChannelMediumLAN}}
+	ctx := context.Background()
+
+	req := (&chassis.ChassisControlRequest{ChassisControl: chassis.ChassisControlPowerUp}).Pack()
+	if _, cc, _ := handleChassisControl(ctx, hctx, req); cc != types.CodeOK {
+		t.Fatalf("power up: cc=%#02x", uint8(cc))
+	}
+	resp, cc, _ := handleGetSystemRestartCause(ctx, hctx, nil)
+	var cause chassis.GetSystemRestartCauseResponse
+	if err := cause.Unpack(resp); err != nil || cc != types.CodeOK {
+		t.Fatalf("restart cause: cc=%#02x %v", uint8(cc), err)
+	}
+	if cause.SystemRestartCause != bmc.RestartCauseChassisControl || cause.ChannelNumber != lanChannelNumber {
+		t.Fatalf("restart cause: %+v", cause)
+	}
+
+	resp, _, _ = handleGetChassisStatus(ctx, hctx, nil)
+	var status chassis.GetChassisStatusResponse
+	if err := status.Unpack(resp); err != nil || !status.LastPowerOnByCommand {
+		t.Fatalf("last power-on by command: %+v %v", status, err)
+	}
+}
+
+func TestHandleSetPowerRestorePolicy(t *testing.T) {
+	b := newTestBMCWithMock(mock.New())
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	req := (&chassis.SetPowerRestorePolicyRequest{PowerRestorePolicy: chassis.PowerRestorePolicyAlwaysOn}).Pack()
+	resp, cc, _ := handleSetPowerRestorePolicy(ctx, hctx, req)
+	var set chassis.SetPowerRestorePolicyResponse
+	if err := set.Unpack(resp); err != nil || cc != types.CodeOK {
+		t.Fatalf("set: cc=%#02x %v", uint8(cc), err)
+	}
+	if !set.SupportPolicyAlwaysOn || !set.SupportPolicyPrevious || !set.SupportPolicyAlwaysOff {
+		t.Fatalf("supported policies: %+v", set)
+	}
+
+	// "No change" only queries the supported policies.
+	req = (&chassis.SetPowerRestorePolicyRequest{PowerRestorePolicy: chassis.PowerRestorePolicyNoChange}).Pack()
+	if _, cc, _ := handleSetPowerRestorePolicy(ctx, hctx, req); cc != types.CodeOK {
+		t.Fatalf("no change: cc=%#02x", uint8(cc))
+	}
+	resp, _, _ = handleGetChassisStatus(ctx, hctx, nil)
+	var status chassis.GetChassisStatusResponse
+	if err := status.Unpack(resp); err != nil || status.PowerRestorePolicy != chassis.PowerRestorePolicyAlwaysOn {
+		t.Fatalf("status policy: %+v %v", status.PowerRestorePolicy, err)
+	}
+
+	if _, cc, _ := handleSetPowerRestorePolicy(ctx, hctx, []byte{0x05}); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("reserved policy: cc=%#02x", uint8(cc))
+	}
+	if _, cc, _ := handleSetPowerRestorePolicy(ctx, hctx, nil); cc != types.CodeRequestDataTruncated {
+		t.Fatalf("empty request: cc=%#02x", uint8(cc))
+	}
+}
+
+func TestHandleSetFrontPanelEnables(t *testing.T) {
+	b := newTestBMCWithMock(mock.New())
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	req := (&chassis.SetFrontPanelEnablesRequest{DisableResetButton: true, DisableSleepButton: true}).Pack()
+	if _, cc, _ := handleSetFrontPanelEnables(ctx, hctx, req); cc != types.CodeOK {
+		t.Fatalf("set: cc=%#02x", uint8(cc))
+	}
+	resp, _, _ := handleGetChassisStatus(ctx, hctx, nil)
+	var status chassis.GetChassisStatusResponse
+	if err := status.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if !status.ResetButtonDisabled || !status.SleepButtonDisabled || status.PoweroffButtonDisabled || !status.PoweroffButtonDisableAllowed {
+		t.Fatalf("front panel: %+v", status)
+	}
+}
+
+func TestHandleChassisCapabilitiesAndInterval(t *testing.T) {
+	m := mock.New()
+	b := newTestBMCWithMock(m)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	set := &chassis.SetChassisCapabilitiesRequest{
+		ProvideIntrusionSensor:        true,
+		FRUDeviceAddress:              0x20,
+		SDRDeviceAddress:              0x20,
+		SELDeviceAddress:              0x22,
+		SystemManagementDeviceAddress: 0x20,
+		BridgeDeviceAddress:           0x24,
+	}
+	if _, cc, _ := handleSetChassisCapabilities(ctx, hctx, set.Pack()); cc != types.CodeOK {
+		t.Fatalf("set capabilities: cc=%#02x", uint8(cc))
+	}
+	resp, _, _ := handleGetChassisCapabilities(ctx, hctx, nil)
+	var caps chassis.GetChassisCapabilitiesResponse
+	if err := caps.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if !caps.ProvideIntrusionSensor || caps.SELDeviceAddress != 0x22 || caps.BridgeDeviceAddress != 0x24 {
+		t.Fatalf("capabilities: %+v", caps)
+	}
+
+	req := (&chassis.SetPowerCycleIntervalRequest{IntervalInSec: 7}).Pack()
+	if _, cc, _ := handleSetPowerCycleInterval(ctx, hctx, req); cc != types.CodeOK {
+		t.Fatalf("power cycle interval: cc=%#02x", uint8(cc))
+	}
+	if got := m.Chassis().(*mock.Chassis).CycleInterval; got != 7 {
+		t.Fatalf("HAL interval: %d", got)
+	}
+
+	resp, cc, _ := handleGetPOHCounter(ctx, hctx, nil)
+	var poh chassis.GetPOHCounterResponse
+	if err := poh.Unpack(resp); err != nil || cc != types.CodeOK || poh.MinutesPerCount != bmc.POHMinutesPerCount {
+		t.Fatalf("POH counter: cc=%#02x %+v %v", uint8(cc), poh, err)
+	}
+}
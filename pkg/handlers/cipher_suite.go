@@ -80,3 +80,16 @@ func isCipherSuiteAllowed(b *bmc.BMC, auth types.AuthAlg, integ types.IntegrityA
 	// no single suite contains this triple — a cross-suite recombination.
 	return false, 0x04
 }
+
+// cipherSuitePrivilege returns the maximum privilege LAN configuration
+// parameter #24 allows for the configured suite with this algorithm triple,
+// or NoAccess when no configured suite matches.
+func cipherSuitePrivilege(b *bmc.BMC, auth types.AuthAlg, integ types.IntegrityAlg, crypt types.CryptAlg) bmc.PrivilegeLevel {
+	for n, id := range b.ResolvedCipherSuites() {
+		a, i, c, ok := types.GetCipherSuiteAlgorithms(id)
+		if ok && a == auth && i == integ && c == crypt {
+			return b.LANConfig.CipherSuitePrivilege(n)
+		}
+	}
+	return bmc.PrivilegeLevelNoAccess
+}
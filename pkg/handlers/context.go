@@ -17,6 +17,8 @@
 package handlers
 
 import (
+	"net"
+
 	"github.com/bougou/go-ipmi/pkg/bmc"
 	"github.com/bougou/go-ipmi/pkg/types"
 )
@@ -44,6 +46,11 @@ type HandlerContext struct {
 	// entry in the table, only ID and NetFn are populated and Name is empty.
 	Command types.Command
 
+	// Registry is the registry dispatching the request, filled in by
+	// [Registry.Dispatch]. The firmware firewall and the command discovery
+	// commands consult it for the commands that exist (v2.0§21).
+	Registry *Registry
+
 	// BMC is the top-level BMC state.
 	BMC *bmc.BMC
 
@@ -53,9 +60,28 @@ type HandlerContext struct {
 	// V15Session is the authenticated IPMI v1.5 session, or nil.
 	V15Session *bmc.V15Session
 
+	// SessionID is the session ID the request's session header carried: the
+	// BMC's ID of an RMCP+ session, a v1.5 session's ID (the temporary one
+	// for Activate Session), or 0 outside a session.
+	SessionID uint32
+
+	// RemoteAddr is the transport address the request came from, or nil
+	// when the frontend has none (the VM protocol's socket).
+	RemoteAddr net.Addr
+
+	// LUN is the LUN the request was addressed to (rsLUN), which selects
+	// the sensors Get Device SDR Info counts (v2.0§35.2) and the sensor the
+	// sensor commands' number refers to.
+	LUN uint8
+
 	// Channel is the channel the request arrived on.
 	Channel *bmc.Channel
 
+	// Origin is where a request another BMC bridged onto an IPMB came from,
+	// or nil. Such a request carries no session and runs at no more than
+	// Origin.Privilege.
+	Origin *bmc.IPMBOrigin
+
 	// User is the authenticated user for this session, or nil for anonymous.
 	User *bmc.User
 }
@@ -0,0 +1,585 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/dcmi"
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// NetFnGroupExtensionRequest and the DCMI command bytes below are referenced
+// by the privilege table. DCMI commands share the Group Extension NetFn and
+// carry the DCMI defining body code (DCh) as their first data byte.
+const (
+	NetFnGroupExtensionRequest uint8 = 0x2c
+
+	CmdGetDCMICapabilities     uint8 = 0x01
+	CmdSetDCMIPowerLimit       uint8 = 0x04
+	CmdActivateDCMIPowerLimit  uint8 = 0x05
+	CmdSetDCMIAssetTag         uint8 = 0x08
+	CmdSetDCMIMgmtControllerID uint8 = 0x0a
+	CmdSetDCMIThermalLimit     uint8 = 0x0b
+	CmdSetDCMIConfigParam      uint8 = 0x12
+)
+
+// DCMI version reported by Get DCMI Capabilities and Get DCMI Configuration
+// Parameters (DCMI v1.5 §6.1).
+const (
+	dcmiMajorVersion      = 0x01
+	dcmiMinorVersion      = 0x05
+	dcmiCapParamRevision  = 0x02
+	dcmiConfParamRevision = 0x01
+)
+
+// dcmiPowerReadingModeBasic selects the System Power Statistics mode of Get
+// Power Reading, the only mode served (DCMI v1.5 §6.6.1).
+const dcmiPowerReadingModeBasic = 0x01
+
+// dcmiMaxInstances is the most entity instances a single Get DCMI Sensor
+// Info or Get Temperature Readings response carries (DCMI v1.5 §6.5.2,
+// §6.7.3).
+const dcmiMaxInstances = 8
+
+// RegisterDCMIHandlers adds the DCMI v1.5 command handlers to r.
+func RegisterDCMIHandlers(r *Registry) {
+	r.RegisterFunc(types.CommandGetDCMICapParam, dcmiHandler(handleGetDCMICapabilities))
+	r.RegisterFunc(types.CommandGetDCMIPowerReading, dcmiHandler(handleGetDCMIPowerReading))
+	r.RegisterFunc(types.CommandGetDCMIPowerLimit, dcmiHandler(handleGetDCMIPowerLimit))
+	r.RegisterFunc(types.CommandSetDCMIPowerLimit, dcmiHandler(handleSetDCMIPowerLimit))
+	r.RegisterFunc(types.CommandActivateDCMIPowerLimit, dcmiHandler(handleActivateDCMIPowerLimit))
+	r.RegisterFunc(types.CommandGetDCMIAssetTag, dcmiHandler(handleGetDCMIAssetTag))
+	r.RegisterFunc(types.CommandGetDCMISensorInfo, dcmiHandler(handleGetDCMISensorInfo))
+	r.RegisterFunc(types.CommandSetDCMIAssetTag, dcmiHandler(handleSetDCMIAssetTag))
+	r.RegisterFunc(types.CommandGetDCMIMgmtControllerIdentifier, dcmiHandler(handleGetDCMIMgmtControllerID))
+	r.RegisterFunc(types.CommandSetDCMIMgmtControllerIdentifier, dcmiHandler(handleSetDCMIMgmtControllerID))
+	r.RegisterFunc(types.CommandSetDCMIThermalLimit, dcmiHandler(handleSetDCMIThermalLimit))
+	r.RegisterFunc(types.CommandGetDCMIThermalLimit, dcmiHandler(handleGetDCMIThermalLimit))
+	r.RegisterFunc(types.CommandGetDCMITemperatureReadings, dcmiHandler(handleGetDCMITemperatureReadings))
+	r.RegisterFunc(types.CommandSetDCMIConfigParam, dcmiHandler(handleSetDCMIConfigParam))
+	r.RegisterFunc(types.CommandGetDCMIConfigParam, dcmiHandler(handleGetDCMIConfigParam))
+}
+
+// dcmiHandler wraps a DCMI command handler. It rejects requests for other
+// group extensions sharing the NetFn and the command, and starts every
+// response, error responses included, with the DCMI defining body code as
+// the spec requires.
+func dcmiHandler(h HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+		if len(req) < 1 {
+			return nil, types.CodeRequestDataTruncated, nil
+		}
+		if req[0] != types.GroupExtensionDCMI {
+			return nil, types.CodeInvalidCommand, nil
+		}
+		resp, cc, err := h(ctx, hctx, req)
+		if len(resp) == 0 {
+			resp = []byte{types.GroupExtensionDCMI}
+		}
+		return resp, cc, err
+	}
+}
+
+// dcmiCommandCC maps DCMI errors to the completion codes of the DCMI
+// commands (DCMI v1.5 §6).
+func dcmiCommandCC(err error) types.CompletionCode {
+	switch {
+	case err == nil:
+		return types.CodeOK
+	case errors.Is(err, bmc.ErrDCMIPowerNotSupported):
+		return types.CodeInvalidCommand
+	case errors.Is(err, bmc.ErrDCMIPowerLimitOutOfRange):
+		return types.CodeSetDCMIPowerLimitOutOfRange
+	case errors.Is(err, bmc.ErrDCMIExceptionActionInvalid):
+		return types.CodeRequestDataFieldInvalid
+	case errors.Is(err, bmc.ErrDCMIStringOutOfRange):
+		return types.CodeParameterOutOfRange
+	case errors.Is(err, bmc.ErrDCMIEntityInvalid):
+		return types.CodeRequestDataFieldInvalid
+	case errors.Is(err, bmc.ErrDCMIParamNotSupported):
+		return types.CodeParameterNotSupported
+	case errors.Is(err, bmc.ErrDCMIParamOutOfRange):
+		return types.CodeParameterOutOfRange
+	case errors.Is(err, bmc.ErrDCMIParamLength):
+		return types.CodeRequestDataLengthInvalid
+	case errors.Is(err, bmc.ErrSensorNotPresent):
+		return types.CodeRequestedDataNotPresent
+	default:
+		return codeFromHalErr(err)
+	}
+}
+
+// dcmiFailure returns the handler result for a DCMI error. Errors with no
+// command-specific completion code are passed up for logging.
+func dcmiFailure(err error) ([]byte, types.CompletionCode, error) {
+	cc := dcmiCommandCC(err)
+	if cc == types.CodeUnspecifiedError {
+		return nil, cc, err
+	}
+	return nil, cc, nil
+}
+
+// dcmiDevice returns the BMC's DCMI state, or nil.
+func dcmiDevice(hctx *HandlerContext) *bmc.DCMIStore {
+	if hctx == nil || hctx.BMC == nil {
+		return nil
+	}
+	return hctx.BMC.DCMI
+}
+
+// handleGetDCMICapabilities implements Get DCMI Capabilities Info (DCMI
+// v1.5 §6.1.1).
+func handleGetDCMICapabilities(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.GetDCMICapParamRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	b := hctx.BMC
+
+	var primaryLAN, secondaryLAN, serial uint8 = 0xff, 0xff, 0xff
+	var systemIF bool
+	channels := b.Channels.All()
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Number < channels[j].Number })
+	for _, ch := range channels {
+		switch ch.Medium {
+		case bmc.ChannelMediumLAN:
+			if primaryLAN == 0xff {
+				primaryLAN = ch.Number
+			} else if secondaryLAN == 0xff {
+				secondaryLAN = ch.Number
+			}
+		case bmc.ChannelMediumSerial:
+			if serial == 0xff {
+				serial = ch.Number
+			}
+		case bmc.ChannelMediumSystemIF:
+			systemIF = true
+		}
+	}
+
+	var param types.DCMICapParameter
+	switch typed.ParamSelector {
+	case types.DCMICapParamSelector_SupportedDCMICapabilities:
+		param = &types.DCMICapParam_SupportedDCMICapabilities{
+			SupportPowerManagement: d.PowerSupported(),
+			SupportInBandKCS:       systemIF,
+			SupportOutOfBandSerial: serial != 0xff,
+			SupportOutOfBandLAN:    secondaryLAN != 0xff,
+		}
+	case types.DCMICapParamSelector_MandatoryPlatformAttributes:
+		var entries uint16
+		if b.SEL.Supported() {
+			alloc, err := b.SEL.AllocInfo(ctx)
+			if err != nil {
+				return dcmiFailure(err)
+			}
+			entries = min(alloc.PossibleAllocUnits, 0x0fff)
+		}
+		param = &types.DCMICapParam_MandatoryPlatformAttributes{
+			SELEntriesCount:                  entries,
+			TemperatrureSamplingFrequencySec: uint8(bmc.DefaultSensorScanInterval / time.Second),
+		}
+	case types.DCMICapParamSelector_OptionalPlatformAttributes:
+		// Power is managed by the BMC itself on the primary IPMB.
+		param = &types.DCMICapParam_OptionalPlatformAttributes{
+			PowerMgmtDeviceSlaveAddr: types.BMC_SA,
+			DeviceRevision:           0x01,
+		}
+	case types.DCMICapParamSelector_ManageabilityAccessAttributes:
+		param = &types.DCMICapParam_ManageabilityAccessAttributes{
+			PrimaryLANChannelNumber:   primaryLAN,
+			SecondaryLANChannelNumber: secondaryLAN,
+			SerialChannelNumber:       serial,
+		}
+	case types.DCMICapParamSelector_EnhancedSystemPowerStatisticsAttributes:
+		// Only the basic power statistics are kept: no rolling averages.
+		param = &types.DCMICapParam_EnhancedSystemPowerStatisticsAttributes{}
+	default:
+		return nil, types.CodeParameterNotSupported, nil
+	}
+	resp := &dcmi.GetDCMICapParamResponse{
+		MajorVersion:  dcmiMajorVersion,
+		MinorVersion:  dcmiMinorVersion,
+		ParamRevision: dcmiCapParamRevision,
+		ParamData:     param.Pack(),
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetDCMIPowerReading implements Get Power Reading in System Power
+// Statistics mode (DCMI v1.5 §6.6.1).
+func handleGetDCMIPowerReading(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.GetDCMIPowerReadingRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if req[1] != dcmiPowerReadingModeBasic {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	stats, err := d.PowerReading(ctx)
+	if err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.GetDCMIPowerReadingResponse{
+		CurrentPower:           stats.Current,
+		MinimumPower:           stats.Minimum,
+		MaximumPower:           stats.Maximum,
+		AveragePower:           stats.Average,
+		Timestamp:              uint32(stats.Timestamp.Unix()),
+		ReportingPeriod:        uint32(stats.Period / time.Millisecond),
+		PowerMeasurementActive: true,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetDCMIPowerLimit implements Get Power Limit (DCMI v1.5 §6.6.2).
+// The limit last set is returned even when it is not active, with
+// completion code 80h.
+func handleGetDCMIPowerLimit(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.GetDCMIPowerLimitRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	limit, active, err := d.PowerLimit()
+	if err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.GetDCMIPowerLimitResponse{
+		ExceptionAction:             limit.ExceptionAction,
+		PowerLimitRequested:         limit.Watts,
+		CorrectionTimeLimitMilliSec: limit.CorrectionTimeMs,
+		StatisticsSamplingPeriodSec: limit.SamplingPeriodSec,
+	}
+	if !active {
+		return resp.Pack(), types.CodeGetDCMIPowerLimitNoActiveLimit, nil
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetDCMIPowerLimit implements Set Power Limit (DCMI v1.5 §6.6.3).
+func handleSetDCMIPowerLimit(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.SetDCMIPowerLimitRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	err := d.SetPowerLimit(ctx, hal.PowerLimit{
+		Watts:             typed.PowerLimitRequested,
+		CorrectionTimeMs:  typed.CorrectionTimeLimitMilliSec,
+		ExceptionAction:   typed.ExceptionAction,
+		SamplingPeriodSec: typed.StatisticsSamplingPeriodSec,
+	})
+	if err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.SetDCMIPowerLimitResponse{}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleActivateDCMIPowerLimit implements Activate/Deactivate Power Limit
+// (DCMI v1.5 §6.6.4).
+func handleActivateDCMIPowerLimit(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.ActivateDCMIPowerLimitRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if req[1] > 0x01 {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	if err := d.ActivatePowerLimit(ctx, typed.Activate); err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.ActivateDCMIPowerLimitResponse{}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetDCMIAssetTag implements Get Asset Tag (DCMI v1.5 §6.4.2). The
+// asset tag is kept as ASCII+Latin1 or UTF-8 text, so the completion code
+// is always 00h.
+func handleGetDCMIAssetTag(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.GetDCMIAssetTagRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	data, total, err := d.AssetTag(typed.Offset, typed.ReadBytes)
+	if err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.GetDCMIAssetTagResponse{AssetTag: data, TotalLength: total}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetDCMIAssetTag implements Set Asset Tag (DCMI v1.5 §6.4.3).
+func handleSetDCMIAssetTag(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.SetDCMIAssetTagRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if len(typed.AssetTag) != int(typed.WriteBytes) {
+		return nil, types.CodeRequestDataLengthInvalid, nil
+	}
+	total, err := d.SetAssetTag(typed.Offset, typed.AssetTag)
+	if err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.SetDCMIAssetTagResponse{TotalLength: total}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetDCMIMgmtControllerID implements Get Management Controller
+// Identifier String (DCMI v1.5 §6.4.6.1).
+func handleGetDCMIMgmtControllerID(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.GetDCMIMgmtControllerIdentifierRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	data, length, err := d.MCIdentifier(typed.Offset, typed.ReadBytes)
+	if err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.GetDCMIMgmtControllerIdentifierResponse{IDStrLength: length, IDStr: data}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetDCMIMgmtControllerID implements Set Management Controller
+// Identifier String (DCMI v1.5 §6.4.6.2).
+func handleSetDCMIMgmtControllerID(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.SetDCMIMgmtControllerIdentifierRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if len(typed.IDStr) != int(typed.WriteBytes) {
+		return nil, types.CodeRequestDataLengthInvalid, nil
+	}
+	total, err := d.SetMCIdentifier(typed.Offset, typed.IDStr)
+	if err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.SetDCMIMgmtControllerIdentifierResponse{TotalLength: total}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetDCMIThermalLimit implements Get Thermal Limit (DCMI v1.5
+// §6.7.1).
+func handleGetDCMIThermalLimit(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.GetDCMIThermalLimitRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	limit, err := d.ThermalLimit(typed.EntityID, typed.EntityInstance)
+	if err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.GetDCMIThermalLimitResponse{
+		ExceptionAction_PowerOffAndLogSEL: limit.PowerOff,
+		ExceptionAction_LogSELOnly:        limit.LogSEL,
+		TemperatureLimit:                  limit.Limit,
+		ExceptionTimeSec:                  limit.ExceptionTimeSec,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetDCMIThermalLimit implements Set Thermal Limit (DCMI v1.5
+// §6.7.2).
+func handleSetDCMIThermalLimit(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.SetDCMIThermalLimitRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	err := d.SetThermalLimit(typed.EntityID, typed.EntityInstance, bmc.ThermalLimit{
+		PowerOff:         typed.ExceptionAction_PowerOffAndLogSEL,
+		LogSEL:           typed.ExceptionAction_LogSELOnly,
+		Limit:            typed.TemperatureLimit,
+		ExceptionTimeSec: typed.ExceptionTimeSec,
+	})
+	if err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.SetDCMIThermalLimitResponse{}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// dcmiInstances returns the sensors of the given type monitoring entity,
+// ordered by entity instance, and the page of at most [dcmiMaxInstances]
+// of them a Get DCMI Sensor Info or Get Temperature Readings request
+// selects (DCMI v1.5 §6.5.2): instance 00h pages through every instance
+// from the 1-based start, any other instance selects just that one.
+func dcmiInstances(ctx context.Context, hctx *HandlerContext, sensorType types.SensorType, entity types.EntityID, instance types.EntityInstance, start uint8) (all, page []bmc.Sensor, err error) {
+	sensors := sensorDevice(hctx)
+	if sensors == nil {
+		return nil, nil, nil
+	}
+	keys, err := sensors.Keys(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, k := range keys {
+		s, err := sensors.Get(ctx, k.LUN, k.Number)
+		if err != nil {
+			return nil, nil, err
+		}
+		if s.Type != sensorType || bmc.DCMIEntity(s.Entity) != bmc.DCMIEntity(entity) {
+			continue
+		}
+		if instance != 0 && s.EntityInstance != instance {
+			continue
+		}
+		all = append(all, s)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].EntityInstance < all[j].EntityInstance })
+	if instance != 0 {
+		start = 1
+	}
+	first := max(int(start), 1) - 1
+	if first >= len(all) {
+		return all, nil, nil
+	}
+	return all, all[first:min(len(all), first+dcmiMaxInstances)], nil
+}
+
+// handleGetDCMISensorInfo implements Get DCMI Sensor Info (DCMI v1.5
+// §6.5.2). Sensors that only the HAL lists have no record and are left out.
+func handleGetDCMISensorInfo(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	var typed dcmi.GetDCMISensorInfoRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if typed.SensorType != types.SensorTypeTemperature {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	all, page, err := dcmiInstances(ctx, hctx, typed.SensorType, typed.EntityID, typed.EntityInstance, typed.EntityInstanceStart)
+	if err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.GetDCMISensorInfoResponse{TotalEntityInstances: uint8(len(all))}
+	for _, s := range page {
+		if s.RecordID != 0 {
+			resp.SDRRecordID = append(resp.SDRRecordID, s.RecordID)
+		}
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetDCMITemperatureReadings implements Get Temperature Readings
+// (DCMI v1.5 §6.7.3). Sensors with no reading available are left out of
+// the readings but still counted in the total.
+func handleGetDCMITemperatureReadings(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	var typed dcmi.GetDCMITemperatureReadingsRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if typed.SensorType != types.SensorTypeTemperature {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	all, page, err := dcmiInstances(ctx, hctx, typed.SensorType, typed.EntityID, typed.EntityInstance, typed.EntityInstanceStart)
+	if err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.GetDCMITemperatureReadingsResponse{TotalEntityInstances: uint8(len(all))}
+	sensors := sensorDevice(hctx)
+	for _, s := range page {
+		_, reading, err := sensors.Read(ctx, s.LUN, s.Number)
+		if err != nil {
+			return dcmiFailure(err)
+		}
+		if reading.Unavailable || !s.HasAnalogReading() {
+			continue
+		}
+		degrees := math.Round(s.Reading(reading.Raw))
+		resp.TemperatureReadings = append(resp.TemperatureReadings, dcmi.DCMITemperatureReading{
+			TemperatureReading: int8(max(-127, min(127, degrees))),
+			EntityInstance:     s.EntityInstance,
+		})
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetDCMIConfigParam implements Get DCMI Configuration Parameters
+// (DCMI v1.5 §6.1.3).
+func handleGetDCMIConfigParam(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.GetDCMIConfigParamRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	data, err := d.ConfigParam(typed.ParamSelector, typed.SetSelector)
+	if err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.GetDCMIConfigParamResponse{
+		MajorVersion:  dcmiMajorVersion,
+		MinorVersion:  dcmiMinorVersion,
+		ParamRevision: dcmiConfParamRevision,
+		ParamData:     data,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetDCMIConfigParam implements Set DCMI Configuration Parameters
+// (DCMI v1.5 §6.1.2).
+func handleSetDCMIConfigParam(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := dcmiDevice(hctx)
+	if d == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed dcmi.SetDCMIConfigParamRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if err := d.SetConfigParam(typed.ParamSelector, typed.SetSelector, typed.ParamData); err != nil {
+		return dcmiFailure(err)
+	}
+	resp := &dcmi.SetDCMIConfigParamResponse{}
+	return resp.Pack(), types.CodeOK, nil
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/dcmi"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// newTestBMCWithInletSensors returns a BMC whose SDR repository holds two
+// inlet temperature sensors, 0x30 (instance 1, 1 degree C per count) and
+// 0x31 (instance 2, signed), reading 24 and -3 degrees C.
+func newTestBMCWithInletSensors(t *testing.T) (*bmc.BMC, *mock.HAL) {
+	t.Helper()
+	b, m := newTestBMCWithStorage(t)
+	ctx := context.Background()
+	for i, n := range []uint8{0x30, 0x31} {
+		full := &types.SDRFull{
+			SensorNumber:           types.SensorNumber(n),
+			SensorType:             types.SensorTypeTemperature,
+			SensorEventReadingType: types.EventReadingTypeThreshold,
+			SensorEntityID:         bmc.DCMIEntityInlet,
+			SensorEntityInstance:   types.EntityInstance(i + 1),
+			SensorInitialization:   types.SensorInitialization{InitScanning: true},
+			ReadingFactors:         types.ReadingFactors{M: 1},
+		}
+		if n == 0x31 {
+			full.SensorUnit.AnalogDataFormat = types.SensorAnalogUnitFormat_2sComplement
+		}
+		id := uint16(i + 1)
+		_ = m.Storage().SDR().Write(ctx, id, full.Pack(id))
+	}
+	m.Sensors().(*mock.Sensors).Values = map[uint8]uint8{0x30: 24, 0x31: 0xfd}
+	return b, m
+}
+
+func TestDCMIHandlerGroupExtension(t *testing.T) {
+	b := newTestBMCWithMock(mock.New())
+	hctx := &HandlerContext{BMC: b}
+	h := dcmiHandler(handleGetDCMIPowerReading)
+
+	// Another body code under the Group Extension NetFn is not DCMI.
+	if _, cc, _ := h(context.Background(), hctx, []byte{0x00, 0x01, 0x00, 0x00}); cc != types.CodeInvalidCommand {
+		t.Fatalf("non-DCMI body code: cc=%#02x", uint8(cc))
+	}
+	// Error responses still carry the DCMI body code.
+	resp, cc, _ := h(context.Background(), hctx, []byte{types.GroupExtensionDCMI, 0x02, 0x00, 0x00})
+	if cc != types.CodeRequestDataFieldInvalid || len(resp) != 1 || resp[0] != types.GroupExtensionDCMI {
+		t.Fatalf("enhanced mode: cc=%#02x resp=% x", uint8(cc), resp)
+	}
+}
+
+func TestHandleGetDCMIPowerReading(t *testing.T) {
+	m := mock.New()
+	m.Power().(*mock.Power).SetWatts(120)
+	b := newTestBMCWithMock(m)
+	hctx := &HandlerContext{BMC: b}
+
+	req := &dcmi.GetDCMIPowerReadingRequest{}
+	resp, cc, err := handleGetDCMIPowerReading(context.Background(), hctx, req.Pack())
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("cc=%#02x err=%v", uint8(cc), err)
+	}
+	var got dcmi.GetDCMIPowerReadingResponse
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if got.CurrentPower != 120 || got.MinimumPower != 120 || got.AveragePower != 120 || !got.PowerMeasurementActive {
+		t.Fatalf("reading: %+v", got)
+	}
+}
+
+func TestHandleDCMIPowerLimit(t *testing.T) {
+	m := mock.New()
+	b := newTestBMCWithMock(m)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	set := &dcmi.SetDCMIPowerLimitRequest{
+		ExceptionAction:             types.DCMIExceptionAction_LogSEL,
+		PowerLimitRequested:         300,
+		CorrectionTimeLimitMilliSec: 2000,
+		StatisticsSamplingPeriodSec: 10,
+	}
+	if _, cc, err := handleSetDCMIPowerLimit(ctx, hctx, set.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("set: cc=%#02x err=%v", uint8(cc), err)
+	}
+
+	// The limit reads back with 80h until it is activated.
+	get := &dcmi.GetDCMIPowerLimitRequest{}
+	resp, cc, _ := handleGetDCMIPowerLimit(ctx, hctx, get.Pack())
+	if cc != types.CodeGetDCMIPowerLimitNoActiveLimit {
+		t.Fatalf("inactive limit: cc=%#02x", uint8(cc))
+	}
+	var got dcmi.GetDCMIPowerLimitResponse
+	if err := got.Unpack(resp); err != nil || got.PowerLimitRequested != 300 || got.CorrectionTimeLimitMilliSec != 2000 {
+		t.Fatalf("inactive limit data: %+v %v", got, err)
+	}
+
+	activate := &dcmi.ActivateDCMIPowerLimitRequest{Activate: true}
+	if _, cc, _ := handleActivateDCMIPowerLimit(ctx, hctx, activate.Pack()); cc != types.CodeOK {
+		t.Fatalf("activate: cc=%#02x", uint8(cc))
+	}
+	if _, cc, _ := handleGetDCMIPowerLimit(ctx, hctx, get.Pack()); cc != types.CodeOK {
+		t.Fatalf("active limit: cc=%#02x", uint8(cc))
+	}
+	if limit := m.Power().(*mock.Power).ActiveLimit(); limit == nil || limit.Watts != 300 {
+		t.Fatalf("HAL limit: %+v", limit)
+	}
+
+	set.PowerLimitRequested = 0
+	if _, cc, _ := handleSetDCMIPowerLimit(ctx, hctx, set.Pack()); cc != types.CodeSetDCMIPowerLimitOutOfRange {
+		t.Fatalf("zero limit: cc=%#02x", uint8(cc))
+	}
+}
+
+func TestHandleDCMIAssetTag(t *testing.T) {
+	b := newTestBMCWithMock(mock.New())
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	set := &dcmi.SetDCMIAssetTagRequest{Offset: 0, WriteBytes: 8, AssetTag: []byte("asset-01")}
+	resp, cc, _ := handleSetDCMIAssetTag(ctx, hctx, set.Pack())
+	if cc != types.CodeOK || len(resp) != 2 || resp[1] != 8 {
+		t.Fatalf("set: cc=%#02x resp=% x", uint8(cc), resp)
+	}
+	get := &dcmi.GetDCMIAssetTagRequest{Offset: 0, ReadBytes: 16}
+	resp, cc, _ = handleGetDCMIAssetTag(ctx, hctx, get.Pack())
+	var got dcmi.GetDCMIAssetTagResponse
+	if err := got.Unpack(resp); err != nil || cc != types.CodeOK || string(got.AssetTag) != "asset-01" || got.TotalLength != 8 {
+		t.Fatalf("get: cc=%#02x %+v %v", uint8(cc), got, err)
+	}
+
+	set = &dcmi.SetDCMIAssetTagRequest{Offset: 60, WriteBytes: 8, AssetTag: []byte("overflow")}
+	if _, cc, _ := handleSetDCMIAssetTag(ctx, hctx, set.Pack()); cc != types.CodeParameterOutOfRange {
+		t.Fatalf("past maximum: cc=%#02x", uint8(cc))
+	}
+	if _, cc, _ := handleSetDCMIAssetTag(ctx, hctx, []byte{types.GroupExtensionDCMI, 0, 4, 'a'}); cc != types.CodeRequestDataLengthInvalid {
+		t.Fatalf("short data: cc=%#02x", uint8(cc))
+	}
+}
+
+func TestHandleGetDCMITemperatureReadings(t *testing.T) {
+	b, _ := newTestBMCWithInletSensors(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	// DCMI 1.0 entity codes select the same sensors.
+	req := &dcmi.GetDCMITemperatureReadingsRequest{
+		SensorType: types.SensorTypeTemperature,
+		EntityID:   bmc.DCMIEntityInletV1,
+	}
+	resp, cc, err := handleGetDCMITemperatureReadings(ctx, hctx, req.Pack())
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("cc=%#02x err=%v", uint8(cc), err)
+	}
+	var got dcmi.GetDCMITemperatureReadingsResponse
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if got.TotalEntityInstances != 2 || len(got.TemperatureReadings) != 2 {
+		t.Fatalf("readings: %+v", got)
+	}
+	if r := got.TemperatureReadings[0]; r.TemperatureReading != 24 || r.EntityInstance != 1 {
+		t.Fatalf("instance 1: %+v", r)
+	}
+	if r := got.TemperatureReadings[1]; r.TemperatureReading != -3 || r.EntityInstance != 2 {
+		t.Fatalf("instance 2: %+v", r)
+	}
+
+	req.EntityInstance = 2
+	resp, _, _ = handleGetDCMITemperatureReadings(ctx, hctx, req.Pack())
+	if err := got.Unpack(resp); err != nil || got.TotalEntityInstances != 1 || got.TemperatureReadings[0].EntityInstance != 2 {
+		t.Fatalf("single instance: %+v %v", got, err)
+	}
+
+	info := &dcmi.GetDCMISensorInfoRequest{SensorType: types.SensorTypeTemperature, EntityID: bmc.DCMIEntityInlet, EntityInstanceStart: 2}
+	resp, cc, _ = handleGetDCMISensorInfo(ctx, hctx, info.Pack())
+	var records dcmi.GetDCMISensorInfoResponse
+	if err := records.Unpack(resp); err != nil || cc != types.CodeOK {
+		t.Fatalf("sensor info: cc=%#02x %v", uint8(cc), err)
+	}
+	if records.TotalEntityInstances != 2 || len(records.SDRRecordID) != 1 || records.SDRRecordID[0] != 2 {
+		t.Fatalf("sensor info from instance 2: %+v", records)
+	}
+}
+
+func TestHandleGetDCMICapabilities(t *testing.T) {
+	b := newTestBMCWithMock(mock.New())
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	req := &dcmi.GetDCMICapParamRequest{ParamSelector: types.DCMICapParamSelector_SupportedDCMICapabilities}
+	resp, cc, _ := handleGetDCMICapabilities(ctx, hctx, req.Pack())
+	var got dcmi.GetDCMICapParamResponse
+	if err := got.Unpack(resp); err != nil || cc != types.CodeOK {
+		t.Fatalf("cc=%#02x err=%v", uint8(cc), err)
+	}
+	if got.MajorVersion != 1 || got.MinorVersion != 5 {
+		t.Fatalf("version %d.%d", got.MajorVersion, got.MinorVersion)
+	}
+	var caps types.DCMICapParam_SupportedDCMICapabilities
+	if err := caps.Unpack(got.ParamData); err != nil || !caps.SupportPowerManagement || !caps.SupportInBandKCS {
+		t.Fatalf("capabilities: %+v %v", caps, err)
+	}
+
+	req.ParamSelector = types.DCMICapParamSelector_ManageabilityAccessAttributes
+	resp, _, _ = handleGetDCMICapabilities(ctx, hctx, req.Pack())
+	var access types.DCMICapParam_ManageabilityAccessAttributes
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if err := access.Unpack(got.ParamData); err != nil || access.PrimaryLANChannelNumber != lanChannelNumber || access.SecondaryLANChannelNumber != 0xff {
+		t.Fatalf("access attributes: %+v %v", access, err)
+	}
+
+	req.ParamSelector = 0x7f
+	if _, cc, _ := handleGetDCMICapabilities(ctx, hctx, req.Pack()); cc != types.CodeParameterNotSupported {
+		t.Fatalf("unknown parameter: cc=%#02x", uint8(cc))
+	}
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/sensor"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// PEF and Alerting command IDs (Sensor/Event NetFn, v2.0§30).
+const (
+	CmdArmPEFPostponeTimer     uint8 = 0x11
+	CmdSetPEFConfigParam       uint8 = 0x12
+	CmdGetPEFConfigParam       uint8 = 0x13
+	CmdSetLastProcessedEventID uint8 = 0x14
+	CmdGetLastProcessedEventID uint8 = 0x15
+	CmdAlertImmediate          uint8 = 0x16
+	CmdPETAcknowledge          uint8 = 0x17
+)
+
+// pefParamRevision is the parameter revision of the PEF configuration
+// parameters: present revision 1, backward compatible to 1 (v2.0§30.4).
+const pefParamRevision = 0x11
+
+// RegisterPEFHandlers adds the PEF and Alerting command handlers to r.
+func RegisterPEFHandlers(r *Registry) {
+	r.RegisterFunc(types.CommandGetPEFCapabilities, handleGetPEFCapabilities)
+	r.RegisterFunc(types.CommandArmPEFPostponeTimer, handleArmPEFPostponeTimer)
+	r.RegisterFunc(types.CommandSetPEFConfigParam, handleSetPEFConfigParam)
+	r.RegisterFunc(types.CommandGetPEFConfigParam, handleGetPEFConfigParam)
+	r.RegisterFunc(types.CommandSetLastProcessedEventId, handleSetLastProcessedEventID)
+	r.RegisterFunc(types.CommandGetLastProcessedEventId, handleGetLastProcessedEventID)
+	r.RegisterFunc(types.CommandAlertImmediate, handleAlertImmediate)
+	r.RegisterFunc(types.CommandPETAcknowledge, handlePETAcknowledge)
+}
+
+// pefCommandCC maps PEF and SEL errors to the completion codes of the PEF
+// and Alerting commands (v2.0§30).
+func pefCommandCC(err error) types.CompletionCode {
+	switch {
+	case err == nil:
+		return types.CodeOK
+	case errors.Is(err, bmc.ErrPEFParamNotSupported):
+		return types.CodeParameterNotSupported
+	case errors.Is(err, bmc.ErrPEFSetInProgress):
+		return types.CodeParamConfigSetInProgressConflict
+	case errors.Is(err, bmc.ErrPEFParamReadOnly):
+		return types.CodeParamConfigSetReadOnly
+	case errors.Is(err, bmc.ErrPEFParamLength):
+		return types.CodeRequestDataLengthInvalid
+	case errors.Is(err, bmc.ErrPEFParamInvalid):
+		return types.CodeRequestDataFieldInvalid
+	case errors.Is(err, bmc.ErrPEFParamOutOfRange):
+		return types.CodeParameterOutOfRange
+	case errors.Is(err, bmc.ErrSELEraseInProgress):
+		return types.CodeSELEraseInProgress
+	case errors.Is(err, bmc.ErrAlertInProgress):
+		return types.CodeAlertImmediateAlreadyInProgress
+	case errors.Is(err, bmc.ErrAlertDestinationOutOfRange):
+		return types.CodeParameterOutOfRange
+	case errors.Is(err, bmc.ErrAlertQueueFull):
+		return types.CodeNodeBusy
+	default:
+		return codeFromErr(err)
+	}
+}
+
+// pefFailure returns the handler result for a PEF error. Errors with no
+// command-specific completion code are passed up for logging.
+func pefFailure(err error) ([]byte, types.CompletionCode, error) {
+	cc := pefCommandCC(err)
+	if cc == types.CodeUnspecifiedError {
+		return nil, cc, err
+	}
+	return nil, cc, nil
+}
+
+// pefDevice returns the BMC's PEF, or nil.
+func pefDevice(hctx *HandlerContext) *bmc.PEFStore {
+	if hctx == nil || hctx.BMC == nil {
+		return nil
+	}
+	return hctx.BMC.PEF
+}
+
+// handleGetPEFCapabilities implements Get PEF Capabilities (Sensor/Event
+// 0x10, v2.0§30.1).
+func handleGetPEFCapabilities(_ context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	pef := pefDevice(hctx)
+	if pef == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	caps := pef.Capabilities()
+	resp := &sensor.GetPEFCapabilitiesResponse{
+		PEFVersion:                 caps.Version,
+		SupportDiagnosticInterrupt: caps.Actions&bmc.PEFActionDiagnosticInterrupt != 0,
+		SupportOEMAction:           caps.Actions&bmc.PEFActionOEM != 0,
+		SupportPowerCycle:          caps.Actions&bmc.PEFActionPowerCycle != 0,
+		SupportReset:               caps.Actions&bmc.PEFActionReset != 0,
+		SupportPowerDown:           caps.Actions&bmc.PEFActionPowerDown != 0,
+		SupportAlert:               caps.Actions&bmc.PEFActionAlert != 0,
+		EventFilterTableEntries:    caps.EventFilters,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleArmPEFPostponeTimer implements Arm PEF Postpone Timer (Sensor/Event
+// 0x11, v2.0§30.2).
+func handleArmPEFPostponeTimer(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	pef := pefDevice(hctx)
+	if pef == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.ArmPEFPostponeTimerRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	resp := &sensor.ArmPEFPostponeTimerResponse{PresentValue: pef.ArmPostponeTimer(typed.Timeout)}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetPEFConfigParam implements Set PEF Configuration Parameters
+// (Sensor/Event 0x12, v2.0§30.3).
+func handleSetPEFConfigParam(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	pef := pefDevice(hctx)
+	if pef == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.SetPEFConfigParamRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if err := pef.SetParam(uint8(typed.ParamSelector), typed.ParamData); err != nil {
+		return pefFailure(err)
+	}
+	return nil, types.CodeOK, nil
+}
+
+// handleGetPEFConfigParam implements Get PEF Configuration Parameters
+// (Sensor/Event 0x13, v2.0§30.4).
+func handleGetPEFConfigParam(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	pef := pefDevice(hctx)
+	if pef == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.GetPEFConfigParamRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	data, err := pef.GetParam(uint8(typed.ParamSelector), typed.SetSelector, typed.BlockSelector)
+	if err != nil {
+		return pefFailure(err)
+	}
+	resp := &sensor.GetPEFConfigParamResponse{ParamRevision: pefParamRevision}
+	if !typed.GetParamRevisionOnly {
+		resp.ParamData = data
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetLastProcessedEventID implements Set Last Processed Event ID
+// (Sensor/Event 0x14, v2.0§30.5).
+func handleSetLastProcessedEventID(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	pef := pefDevice(hctx)
+	if pef == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.SetLastProcessedEventIdRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if sel := selDevice(hctx); sel != nil && !sel.EraseComplete() {
+		return nil, types.CodeSELEraseInProgress, nil
+	}
+	pef.SetLastProcessed(typed.ByBMC, typed.RecordID)
+	return nil, types.CodeOK, nil
+}
+
+// handleGetLastProcessedEventID implements Get Last Processed Event ID
+// (Sensor/Event 0x15, v2.0§30.6). Without a SEL the last record reads as
+// FFFFh (empty) and the addition time as unspecified.
+func handleGetLastProcessedEventID(ctx context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	pef := pefDevice(hctx)
+	if pef == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	lastID, lastAdd := uint16(0xffff), types.ParseTimestamp(0xffffffff)
+	if sel := selDevice(hctx); sel != nil {
+		var err error
+		if lastID, lastAdd, err = sel.LastRecord(ctx); err != nil {
+			return pefFailure(err)
+		}
+	}
+	bmcID, softwareID := pef.LastProcessed()
+	resp := &sensor.GetLastProcessedEventIdResponse{
+		MostRecentAdditionTime:             lastAdd,
+		LastRecordID:                       lastID,
+		LastSoftwareProcessedEventRecordID: softwareID,
+		LastBMCProcessedEventRecordID:      bmcID,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// alertDevice returns the BMC's LAN alert store, or nil.
+func alertDevice(hctx *HandlerContext) *bmc.LANAlertStore {
+	if hctx == nil || hctx.BMC == nil {
+		return nil
+	}
+	return hctx.BMC.Alerts
+}
+
+// handleAlertImmediate implements Alert Immediate (Sensor/Event 0x16,
+// v2.0§30.7) for the LAN channel: it sends a PET to the selected alert
+// destination, or reads or clears the channel's alert status. Without the
+// optional Platform Event Message fields the trap reports an unspecified
+// event from the BMC. PET traps carry no alert string, so the alert string
+// selector is ignored.
+func handleAlertImmediate(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	alerts := alertDevice(hctx)
+	if alerts == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.AlertImmediateRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if !lanChannelValid(hctx, typed.ChannelNumber) {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	channel := resolveUserChannel(hctx, typed.ChannelNumber)
+
+	switch sensor.AlertImmediateOperation(typed.Operation) {
+	case sensor.AlertImmediateOperationInitiateAlert:
+		ev := bmc.AlertEvent{Event: bmc.PlatformEvent{GeneratorID: types.GeneratorID(types.BMC_SA)}}
+		if typed.WithPlatformEvent {
+			ev.Event = bmc.PlatformEvent{
+				GeneratorID:      types.GeneratorID(typed.GeneratorID),
+				SensorType:       typed.SensorType,
+				SensorNumber:     uint8(typed.SensorNumber),
+				EventReadingType: typed.EventReadingType,
+				EventDir:         typed.EventDir,
+				EventData:        typed.EventData,
+			}
+		}
+		if err := alerts.Immediate(ctx, channel, typed.DestinationSelector, ev); err != nil {
+			return pefFailure(err)
+		}
+	case sensor.AlertImmediateOperationGetStatus:
+	case sensor.AlertImmediateOperationClearStatus:
+		alerts.ClearImmediateStatus(channel)
+	default:
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	resp := &sensor.AlertImmediateResponse{AlertImmediateStatus: uint8(alerts.ImmediateStatus(channel))}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handlePETAcknowledge implements PET Acknowledge (Sensor/Event 0x17,
+// v2.0§30.8). The sequence number and local timestamp identify the
+// acknowledged trap; an acknowledge that matches no alert waiting for one,
+// such as a repeat, is accepted and ignored.
+func handlePETAcknowledge(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	alerts := alertDevice(hctx)
+	if alerts == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.PETAcknowledgeRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	alerts.Acknowledge(ctx, typed.SequenceNumber, typed.LocalTimestamp)
+	return nil, types.CodeOK, nil
+}
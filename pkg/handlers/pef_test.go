@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/sensor"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestHandlePEFConfigParams(t *testing.T) {
+	hctx := &HandlerContext{BMC: newTestBMCWithMock(mock.New())}
+	ctx := context.Background()
+
+	resp, cc, err := handleGetPEFCapabilities(ctx, hctx, nil)
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("capabilities: cc=%v err=%v", cc, err)
+	}
+	var caps sensor.GetPEFCapabilitiesResponse
+	if err := caps.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if caps.PEFVersion != bmc.PEFVersion || !caps.SupportPowerDown || !caps.SupportAlert ||
+		caps.EventFilterTableEntries != bmc.DefaultPEFEventFilters {
+		t.Fatalf("capabilities: %+v", caps)
+	}
+
+	filter := &types.PEFConfigParam_EventFilter{
+		SetSelector: 2,
+		Filter: &types.PEFEventFilter{
+			FilterState:    true,
+			ActionPowerOff: true,
+			GeneratorID:    0xffff,
+			SensorType:     types.SensorTypeTemperature,
+			SensorNumber:   0xff,
+		},
+	}
+	set := &sensor.SetPEFConfigParamRequest{ParamSelector: types.PEFConfigParamSelector_EventFilter, ParamData: filter.Pack()}
+	if _, cc, err := handleSetPEFConfigParam(ctx, hctx, set.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("set filter: cc=%v err=%v", cc, err)
+	}
+
+	get := &sensor.GetPEFConfigParamRequest{ParamSelector: types.PEFConfigParamSelector_EventFilter, SetSelector: 2}
+	resp, cc, err = handleGetPEFConfigParam(ctx, hctx, get.Pack())
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("get filter: cc=%v err=%v", cc, err)
+	}
+	var got sensor.GetPEFConfigParamResponse
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	var gotFilter types.PEFConfigParam_EventFilter
+	if err := gotFilter.Unpack(got.ParamData); err != nil {
+		t.Fatal(err)
+	}
+	if got.ParamRevision != pefParamRevision || gotFilter.SetSelector != 2 || !gotFilter.Filter.ActionPowerOff ||
+		gotFilter.Filter.SensorType != types.SensorTypeTemperature {
+		t.Fatalf("get filter: rev=%#02x %+v", got.ParamRevision, gotFilter.Filter)
+	}
+
+	get.GetParamRevisionOnly = true
+	if resp, cc, _ = handleGetPEFConfigParam(ctx, hctx, get.Pack()); cc != types.CodeOK || len(resp) != 1 {
+		t.Fatalf("revision only: cc=%v resp=% x", cc, resp)
+	}
+
+	for name, tc := range map[string]struct {
+		req  []byte
+		want types.CompletionCode
+	}{
+		"read-only":     {(&sensor.SetPEFConfigParamRequest{ParamSelector: types.PEFConfigParamSelector_EventFiltersCount, ParamData: []byte{1}}).Pack(), types.CodeParamConfigSetReadOnly},
+		"not supported": {[]byte{0x7f, 0x00}, types.CodeParameterNotSupported},
+		"short":         {[]byte{byte(types.PEFConfigParamSelector_EventFilter), 2}, types.CodeRequestDataLengthInvalid},
+		"empty":         {nil, types.CodeRequestDataTruncated},
+	} {
+		if _, cc, _ := handleSetPEFConfigParam(ctx, hctx, tc.req); cc != tc.want {
+			t.Errorf("%s: want %v, got %v", name, tc.want, cc)
+		}
+	}
+}
+
+func TestHandleLastProcessedEventID(t *testing.T) {
+	b := newTestBMCWithMock(mock.New())
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	rec := make([]byte, 16)
+	rec[2] = 0x02
+	id, err := b.SEL.Add(ctx, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := &sensor.SetLastProcessedEventIdRequest{ByBMC: false, RecordID: id}
+	if _, cc, err := handleSetLastProcessedEventID(ctx, hctx, set.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("set: cc=%v err=%v", cc, err)
+	}
+
+	resp, cc, err := handleGetLastProcessedEventID(ctx, hctx, nil)
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("get: cc=%v err=%v", cc, err)
+	}
+	var got sensor.GetLastProcessedEventIdResponse
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if got.LastRecordID != id || got.LastSoftwareProcessedEventRecordID != id || got.LastBMCProcessedEventRecordID != 0 {
+		t.Fatalf("get: %+v", got)
+	}
+
+	if _, cc, _ := handleSetLastProcessedEventID(ctx, hctx, []byte{1}); cc != types.CodeRequestDataTruncated {
+		t.Fatalf("short request: %v", cc)
+	}
+}
+
+func TestRegisterAllHandlers_IncludesPEF(t *testing.T) {
+	r := NewRegistry()
+	RegisterAllHandlers(r)
+	if _, ok := r.handlers[makeKey(uint8(types.CommandGetPEFCapabilities.NetFn), types.CommandGetPEFCapabilities.ID)]; !ok {
+		t.Fatal("Get PEF Capabilities not registered")
+	}
+	if got := MinimumPrivilege(NetFnSensorEventRequest, CmdGetPEFConfigParam); got != bmc.PrivilegeLevelOperator {
+		t.Fatalf("Get PEF Configuration Parameters privilege: %v", got)
+	}
+	for _, cmd := range []uint8{CmdArmPEFPostponeTimer, CmdSetPEFConfigParam, CmdSetLastProcessedEventID} {
+		if got := MinimumPrivilege(NetFnSensorEventRequest, cmd); got != bmc.PrivilegeLevelAdministrator {
+			t.Fatalf("PEF command %#02x privilege: %v", cmd, got)
+		}
+	}
+}
+
+func TestHandleAlertImmediate(t *testing.T) {
+	b := newTestBMCWithMock(mock.New())
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+	var sent [][]byte
+	b.Alerts.SetSender(func(_ context.Context, _ [4]byte, trap []byte) error {
+		sent = append(sent, trap)
+		return nil
+	})
+	if err := b.Alerts.SetDestination(1, bmc.LANAlertDestination{Acknowledged: true, IP: [4]byte{192, 0, 2, 7}}); err != nil {
+		t.Fatal(err)
+	}
+
+	alert := func(op sensor.AlertImmediateOperation, dest uint8) (uint8, types.CompletionCode) {
+		t.Helper()
+		req := &sensor.AlertImmediateRequest{ChannelNumber: 1, DestinationSelector: dest, Operation: uint8(op)}
+		resp, cc, err := handleAlertImmediate(ctx, hctx, req.Pack())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cc != types.CodeOK {
+			return 0, cc
+		}
+		var res sensor.AlertImmediateResponse
+		if err := res.Unpack(resp); err != nil {
+			t.Fatal(err)
+		}
+		return res.AlertImmediateStatus, cc
+	}
+
+	if st, _ := alert(sensor.AlertImmediateOperationInitiateAlert, 1); st != uint8(sensor.AlertImmediateStatusInProgress) || len(sent) != 1 {
+		t.Fatalf("initiate: status %#02x, %d traps", st, len(sent))
+	}
+	if _, cc := alert(sensor.AlertImmediateOperationInitiateAlert, 1); cc != types.CodeAlertImmediateAlreadyInProgress {
+		t.Fatalf("second initiate: %v", cc)
+	}
+
+	// The receiver's PET Acknowledge ends the alert.
+	var trap types.PlatformEventTrap
+	if err := trap.Unpack(sent[0]); err != nil {
+		t.Fatal(err)
+	}
+	ack := &sensor.PETAcknowledgeRequest{SequenceNumber: trap.Sequence, LocalTimestamp: trap.LocalTimestamp}
+	if _, cc, err := handlePETAcknowledge(ctx, hctx, ack.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("PET acknowledge: cc=%v err=%v", cc, err)
+	}
+	if st, _ := alert(sensor.AlertImmediateOperationGetStatus, 0); st != uint8(sensor.AlertImmediateStatusNormalEnd) {
+		t.Fatalf("status after acknowledge: %#02x", st)
+	}
+	if st, _ := alert(sensor.AlertImmediateOperationClearStatus, 0); st != uint8(sensor.AlertImmediateStatusNoStatus) {
+		t.Fatalf("status after clear: %#02x", st)
+	}
+
+	if _, cc := alert(sensor.AlertImmediateOperationInitiateAlert, bmc.DefaultLANAlertDestinations+1); cc != types.CodeParameterOutOfRange {
+		t.Fatalf("out of range destination: %v", cc)
+	}
+	if _, cc := alert(sensor.AlertImmediateOperationReserved, 1); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("reserved operation: %v", cc)
+	}
+	if _, cc, _ := handleAlertImmediate(ctx, hctx, []byte{0x0f, 0x01, 0x00}); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("non-LAN channel: %v", cc)
+	}
+}
+
+func TestPETAcknowledgeIsSessionless(t *testing.T) {
+	b := newTestBMCWithMock(mock.New())
+	ch, err := b.Channels.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hctx := &HandlerContext{BMC: b, Channel: ch}
+	if cc := checkCommandPrivilege(hctx, NetFnSensorEventRequest, CmdPETAcknowledge); cc != types.CodeOK {
+		t.Fatalf("PET acknowledge without a session: %v", cc)
+	}
+	if cc := checkCommandPrivilege(hctx, NetFnSensorEventRequest, CmdAlertImmediate); cc != types.CodeInsufficientPrivilege {
+		t.Fatalf("Alert Immediate without a session: %v", cc)
+	}
+	if got := MinimumPrivilege(NetFnSensorEventRequest, CmdAlertImmediate); got != bmc.PrivilegeLevelAdministrator {
+		t.Fatalf("Alert Immediate privilege: %v", got)
+	}
+}
@@ -8,7 +8,16 @@ import (
 )
 
 // privilegeExempt reports commands that do not require session privilege checks.
+// PET Acknowledge comes from the trap receiver outside any session (spec
+// v2.0§30.8), and Get DCMI Capabilities Info is available outside a session
+// for discovery (DCMI v1.5 §6.1.1).
 func privilegeExempt(netFn, cmd uint8) bool {
+	if netFn == NetFnSensorEventRequest {
+		return cmd == CmdPETAcknowledge
+	}
+	if netFn == NetFnGroupExtensionRequest {
+		return cmd == CmdGetDCMICapabilities
+	}
 	if netFn != NetFnAppRequest {
 		return false
 	}
@@ -32,32 +41,70 @@ func sessionPrivilege(hctx *HandlerContext) (bmc.PrivilegeLevel, bool) {
 	return 0, false
 }
 
+// requestPrivilege returns the privilege hctx's request runs at, and false
+// when it has none.
+func requestPrivilege(hctx *HandlerContext) (bmc.PrivilegeLevel, bool) {
+	priv, ok := sessionPrivilege(hctx)
+	if !ok {
+		switch {
+		case hctx.Channel != nil && hctx.Channel.Medium == bmc.ChannelMediumSystemIF:
+			// The system interface is inherently local (physical access is
+			// the authorization) and carries no session, so an in-band
+			// request runs at full privilege the way real hardware treats
+			// its KCS/BT interface.
+			return bmc.PrivilegeLevelAdministrator, true
+		case hctx.Origin != nil:
+			// A request bridged onto an IPMB carries no session either; it
+			// runs at the privilege its requester held where it was sent.
+			priv = hctx.Origin.Privilege
+		default:
+			// A pre-session LAN packet, or a request on an IPMB that no BMC
+			// bridged.
+			return 0, false
+		}
+	}
+	// A privilege limit lowered by Set Channel Access also caps the sessions
+	// already running on the channel.
+	if hctx.Channel != nil && priv > hctx.Channel.MaxPrivilege {
+		priv = hctx.Channel.MaxPrivilege
+	}
+	return priv, true
+}
+
 // checkCommandPrivilege enforces per-command minimum privilege (spec v1.5§6.8 / v2.0§6.8).
 func checkCommandPrivilege(hctx *HandlerContext, netFn, cmd uint8) types.CompletionCode {
 	if privilegeExempt(netFn, cmd) {
 		return types.CodeOK
 	}
-	priv, ok := sessionPrivilege(hctx)
-	if !ok {
-		// No active session. The system interface is inherently local (physical
-		// access is the authorization) and carries no session, so an in-band
-		// request runs at full privilege the way real hardware treats its KCS/BT
-		// interface. Every other session-less request is a pre-session LAN
-		// packet: only the exempt commands above (channel-auth discovery and
-		// session setup) may run there, so account management, chassis power,
-		// and the like are rejected rather than executed for an unauthenticated
-		// remote caller.
-		if hctx != nil && hctx.Channel != nil && hctx.Channel.Medium == bmc.ChannelMediumSystemIF {
-			return types.CodeOK
-		}
-		return types.CodeInsufficientPrivilege
-	}
-	if priv < MinimumPrivilege(netFn, cmd) {
+	// Without a privilege only the exempt commands above (channel-auth
+	// discovery and session setup) may run, so account management, chassis
+	// power, and the like are rejected rather than executed for an
+	// unauthenticated caller.
+	priv, ok := requestPrivilege(hctx)
+	if !ok || priv < MinimumPrivilege(netFn, cmd) {
 		return types.CodeInsufficientPrivilege
 	}
 	return types.CodeOK
 }
 
+// checkCommandEnabled enforces the firmware firewall (v2.0§21): a command
+// disabled on the request's channel and LUN answers the firewall's
+// completion code, C1h unless configured otherwise. Commands the registry
+// does not let the firewall configure are always enabled.
+func checkCommandEnabled(hctx *HandlerContext, netFn, cmd uint8) types.CompletionCode {
+	if hctx == nil || hctx.BMC == nil || hctx.BMC.CommandEnables == nil || hctx.Channel == nil {
+		return types.CodeOK
+	}
+	if hctx.Registry != nil && !hctx.Registry.Configurable(netFn, cmd) {
+		return types.CodeOK
+	}
+	firewall := hctx.BMC.CommandEnables
+	if firewall.Enabled(hctx.Channel.Number, hctx.LUN, netFn, cmd) {
+		return types.CodeOK
+	}
+	return firewall.DisabledCode()
+}
+
 type dispatchingHandler struct {
 	inner Handler
 	netFn uint8
@@ -65,6 +112,11 @@ type dispatchingHandler struct {
 }
 
 func (d *dispatchingHandler) Handle(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	// A disabled command is rejected before the privilege check, so it looks
+	// the same to every caller.
+	if cc := checkCommandEnabled(hctx, d.netFn, d.cmd); cc != types.CodeOK {
+		return nil, cc, nil
+	}
 	if cc := checkCommandPrivilege(hctx, d.netFn, d.cmd); cc != types.CodeOK {
 		return nil, cc, nil
 	}
@@ -107,12 +107,14 @@ func TestPreSessionRejectsPrivilegedCommands(t *testing.T) {
 // TestCheckCommandPrivilegeSessionless pins the session-less authorization
 // boundary directly, since the sibling PR's tightening and the VM frontend's
 // carve-out both depend on it. A session-less request is authorized only on the
-// inherently-local system interface; on a LAN or unspecified channel a
-// non-exempt command is rejected, which is what stops an unauthenticated remote
-// caller from reaching account management or chassis power. An exempt
-// pre-session command runs regardless.
+// inherently-local system interface, and on an IPMB at the privilege of the
+// requester that bridged it; on a LAN or unspecified channel, or on an IPMB
+// with no bridging requester, a non-exempt command is rejected, which is what
+// stops an unauthenticated remote caller from reaching account management or
+// chassis power. An exempt pre-session command runs regardless.
 func TestCheckCommandPrivilegeSessionless(t *testing.T) {
 	sysIF := &bmc.Channel{Number: 0x0F, Medium: bmc.ChannelMediumSystemIF}
+	ipmb := &bmc.Channel{Number: 0x00, Medium: bmc.ChannelMediumIPMBv10, MaxPrivilege: bmc.PrivilegeLevelAdministrator}
 	lan := &bmc.Channel{Number: lanChannelNumber, Medium: bmc.ChannelMediumLAN}
 
 	tests := []struct {
@@ -123,10 +125,15 @@ func TestCheckCommandPrivilegeSessionless(t *testing.T) {
 		want  types.CompletionCode
 	}{
 		{"in-band non-exempt allowed", &HandlerContext{Channel: sysIF}, NetFnAppRequest, CmdSetUserPassword, types.CodeOK},
+		{"ipmb unbridged rejected", &HandlerContext{Channel: ipmb}, NetFnAppRequest, CmdSetUserPassword, types.CodeInsufficientPrivilege},
+		{"ipmb bridged by an administrator allowed", &HandlerContext{Channel: ipmb, Origin: &bmc.IPMBOrigin{Channel: lanChannelNumber, Privilege: bmc.PrivilegeLevelAdministrator}}, NetFnAppRequest, CmdSetUserPassword, types.CodeOK},
+		{"ipmb bridged by a user rejected", &HandlerContext{Channel: ipmb, Origin: &bmc.IPMBOrigin{Channel: lanChannelNumber, Privilege: bmc.PrivilegeLevelUser}}, NetFnChassisRequest, CmdChassisControl, types.CodeInsufficientPrivilege},
 		{"lan non-exempt rejected", &HandlerContext{Channel: lan}, NetFnAppRequest, CmdSetUserPassword, types.CodeInsufficientPrivilege},
 		{"no-channel non-exempt rejected", &HandlerContext{}, NetFnAppRequest, CmdSetUserPassword, types.CodeInsufficientPrivilege},
 		{"lan chassis control rejected", &HandlerContext{Channel: lan}, NetFnChassisRequest, CmdChassisControl, types.CodeInsufficientPrivilege},
 		{"exempt command allowed pre-session", &HandlerContext{Channel: lan}, NetFnAppRequest, CmdGetChannelAuthCapabilities, types.CodeOK},
+		{"lan DCMI discovery allowed pre-session", &HandlerContext{Channel: lan}, NetFnGroupExtensionRequest, CmdGetDCMICapabilities, types.CodeOK},
+		{"lan DCMI power limit rejected", &HandlerContext{Channel: lan}, NetFnGroupExtensionRequest, CmdSetDCMIPowerLimit, types.CodeInsufficientPrivilege},
 	}
 
 	for _, tc := range tests {
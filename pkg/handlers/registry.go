@@ -51,6 +51,9 @@ type Registry struct {
 	handlers   map[commandKey]Handler
 	commands   map[commandKey]types.Command
 	middleware []Middleware
+	// fixed holds the registered commands the firmware firewall cannot
+	// disable (v2.0§21.5).
+	fixed map[commandKey]bool
 }
 
 // NewRegistry returns an empty [Registry].
@@ -58,6 +61,7 @@ func NewRegistry() *Registry {
 	return &Registry{
 		handlers: make(map[commandKey]Handler),
 		commands: make(map[commandKey]types.Command),
+		fixed:    make(map[commandKey]bool),
 	}
 }
 
@@ -67,13 +71,17 @@ func NewRegistry() *Registry {
 // added later.
 func RegisterAllHandlers(r *Registry) {
 	RegisterAppHandlers(r)
+	RegisterWatchdogHandlers(r)
 	RegisterSessionHandlers(r)
 	RegisterChassisHandlers(r)
 	RegisterStorageHandlers(r)
+	RegisterSensorHandlers(r)
+	RegisterPEFHandlers(r)
 	RegisterPayloadHandlers(r)
 	RegisterSOLHandlers(r)
 	RegisterUserHandlers(r)
 	RegisterTransportHandlers(r)
+	RegisterDCMIHandlers(r)
 }
 
 // Register adds or replaces the handler for c, whose NetFn must be the
@@ -109,14 +117,59 @@ func (r *Registry) Use(m Middleware) {
 }
 
 // Merge copies all handlers from other into r.  Handlers in other overwrite
-// those in r when they share the same (netFn, cmd) key.
+// those in r when they share the same (netFn, cmd) key, and so does whether
+// the firmware firewall may disable them.
 func (r *Registry) Merge(other *Registry) {
 	for k, h := range other.handlers {
 		r.handlers[k] = h
+		delete(r.fixed, k)
 	}
 	for k, c := range other.commands {
 		r.commands[k] = c
 	}
+	for k := range other.fixed {
+		r.fixed[k] = true
+	}
+}
+
+// SetConfigurable sets whether the firmware firewall may disable c (v2.0§21).
+// Every registered command is configurable unless this turns it off; the
+// standard handlers keep the firewall commands themselves and session
+// setup out of reach, so a client cannot lock itself out.
+func (r *Registry) SetConfigurable(c types.Command, configurable bool) {
+	key := makeKey(uint8(c.NetFn), c.ID)
+	if configurable {
+		delete(r.fixed, key)
+		return
+	}
+	r.fixed[key] = true
+}
+
+// Supported reports whether a handler is registered for the request NetFn
+// netFn and cmd. The command discovery commands derive their bitmaps from it
+// (v2.0§21.2-§21.4).
+func (r *Registry) Supported(netFn, cmd uint8) bool {
+	_, ok := r.handlers[makeKey(netFn, cmd)]
+	return ok
+}
+
+// Configurable reports whether the firmware firewall may disable the
+// command: it is registered and not excluded by [Registry.SetConfigurable].
+func (r *Registry) Configurable(netFn, cmd uint8) bool {
+	key := makeKey(netFn, cmd)
+	_, ok := r.handlers[key]
+	return ok && !r.fixed[key]
+}
+
+// SupportsNetFn reports whether any command is registered for the request
+// NetFn netFn.
+func (r *Registry) SupportsNetFn(netFn uint8) bool {
+	for k := range r.handlers {
+		if uint8(k>>8) == netFn {
+			return true
+		}
+	}
+	return false
 }
 
 // Dispatch identifies the request on hctx, then looks up and calls its handler.
@@ -134,6 +187,7 @@ func (r *Registry) Dispatch(ctx context.Context, hctx *HandlerContext, netFn, cm
 	key := makeKey(netFn, cmd)
 	if hctx != nil {
 		hctx.Command = r.lookup(key, netFn, cmd)
+		hctx.Registry = r
 	}
 
 	h, ok := r.handlers[key]
@@ -204,3 +204,28 @@ func TestRegistry_Merge(t *testing.T) {
 		t.Errorf("after merge both keys should be present: cc1=%d cc2=%d", cc1, cc2)
 	}
 }
+
+func TestRegistry_Configurable(t *testing.T) {
+	a := NewRegistry()
+	a.RegisterFunc(types.CommandGetDeviceID, okHandler)
+	a.RegisterFunc(types.CommandSetCommandEnables, okHandler)
+	a.SetConfigurable(types.CommandSetCommandEnables, false)
+
+	b := NewRegistry()
+	b.RegisterFunc(types.CommandGetChassisStatus, okHandler)
+	a.Merge(b)
+
+	app, chassis := uint8(types.NetFnAppRequest), uint8(types.NetFnChassisRequest)
+	if !a.Supported(app, types.CommandGetDeviceID.ID) || !a.Configurable(app, types.CommandGetDeviceID.ID) {
+		t.Error("Get Device ID should be supported and configurable")
+	}
+	if !a.Supported(app, types.CommandSetCommandEnables.ID) || a.Configurable(app, types.CommandSetCommandEnables.ID) {
+		t.Error("Set Command Enables should be supported but not configurable")
+	}
+	if a.Supported(app, 0xff) || a.Configurable(app, 0xff) {
+		t.Error("an unregistered command is neither supported nor configurable")
+	}
+	if !a.SupportsNetFn(chassis) || a.SupportsNetFn(uint8(types.NetFnStorageRequest)) {
+		t.Error("NetFn support should follow the merged registrations")
+	}
+}
@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/sensor"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// NetFnSensorEventRequest and the sensor command bytes below are referenced
+// by the privilege table.
+const (
+	NetFnSensorEventRequest uint8 = 0x04
+
+	CmdSetSensorHysteresis  uint8 = 0x24
+	CmdSetSensorThresholds  uint8 = 0x26
+	CmdSetSensorEventEnable uint8 = 0x28
+	CmdRearmSensorEvents    uint8 = 0x2a
+)
+
+// RegisterSensorHandlers adds the Sensor/Event NetFn sensor device handlers
+// (v2.0§35) to r.
+func RegisterSensorHandlers(r *Registry) {
+	r.RegisterFunc(types.CommandGetDeviceSDRInfo, handleGetDeviceSDRInfo)
+	r.RegisterFunc(types.CommandGetDeviceSDR, handleGetDeviceSDR)
+	r.RegisterFunc(types.CommandReserveDeviceSDRRepo, handleReserveDeviceSDRRepo)
+	r.RegisterFunc(types.CommandGetSensorReadingFactors, handleGetSensorReadingFactors)
+	r.RegisterFunc(types.CommandSetSensorHysteresis, handleSetSensorHysteresis)
+	r.RegisterFunc(types.CommandGetSensorHysteresis, handleGetSensorHysteresis)
+	r.RegisterFunc(types.CommandSetSensorThresholds, handleSetSensorThresholds)
+	r.RegisterFunc(types.CommandGetSensorThresholds, handleGetSensorThresholds)
+	r.RegisterFunc(types.CommandSetSensorEventEnable, handleSetSensorEventEnable)
+	r.RegisterFunc(types.CommandGetSensorEventEnable, handleGetSensorEventEnable)
+	r.RegisterFunc(types.CommandRearmSensorEvents, handleRearmSensorEvents)
+	r.RegisterFunc(types.CommandGetSensorEventStatus, handleGetSensorEventStatus)
+	r.RegisterFunc(types.CommandGetSensorReading, handleGetSensorReading)
+	r.RegisterFunc(types.CommandGetSensorType, handleGetSensorType)
+}
+
+// sensorCommandCC maps sensor store errors to the completion codes of the
+// sensor device commands (v2.0§35).
+func sensorCommandCC(err error) types.CompletionCode {
+	switch {
+	case err == nil:
+		return types.CodeOK
+	case errors.Is(err, bmc.ErrSensorNotPresent):
+		return types.CodeRequestedDataNotPresent
+	case errors.Is(err, bmc.ErrSensorCommandIllegal):
+		return types.CodeIllegalCommand
+	case errors.Is(err, bmc.ErrSensorThresholdNotSettable):
+		return types.CodeRequestDataFieldInvalid
+	default:
+		return codeFromErr(err)
+	}
+}
+
+// sensorFailure returns the handler result for a sensor store error. Errors
+// with no command-specific completion code are passed up for logging.
+func sensorFailure(err error) ([]byte, types.CompletionCode, error) {
+	cc := sensorCommandCC(err)
+	if cc == types.CodeUnspecifiedError {
+		return nil, cc, err
+	}
+	return nil, cc, nil
+}
+
+// sensorDevice returns the BMC's sensor store, or nil when there is none.
+func sensorDevice(hctx *HandlerContext) *bmc.SensorStore {
+	if hctx == nil || hctx.BMC == nil {
+		return nil
+	}
+	return hctx.BMC.Sensors
+}
+
+// sensorLUN returns the LUN whose sensors a request's sensor number refers
+// to: sensor numbers are unique only per LUN (v2.0§35).
+func sensorLUN(hctx *HandlerContext) uint8 {
+	return hctx.LUN & 0x03
+}
+
+// handleGetSensorReadingFactors implements Get Sensor Reading Factors
+// (Sensor/Event 0x23, v2.0§35.5). Only sensors with a Full Sensor Record and
+// an analog reading have factors. One set of factors covers every reading,
+// so Next Reading is always FFh.
+func handleGetSensorReadingFactors(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sensors := sensorDevice(hctx)
+	if sensors == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.GetSensorReadingFactorsRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	s, err := sensors.Get(ctx, sensorLUN(hctx), typed.SensorNumber)
+	if err != nil {
+		return sensorFailure(err)
+	}
+	if !s.Full || !s.HasAnalogReading() {
+		return nil, types.CodeIllegalCommand, nil
+	}
+	resp := &sensor.GetSensorReadingFactorsResponse{
+		NextReading:    0xff,
+		ReadingFactors: s.ReadingFactors,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetSensorHysteresis implements Set Sensor Hysteresis (Sensor/Event
+// 0x24, v2.0§35.6).
+func handleSetSensorHysteresis(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sensors := sensorDevice(hctx)
+	if sensors == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.SetSensorHysteresisRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if err := sensors.SetHysteresis(ctx, sensorLUN(hctx), typed.SensorNumber, typed.PositiveHysteresis, typed.NegativeHysteresis); err != nil {
+		return sensorFailure(err)
+	}
+	return nil, types.CodeOK, nil
+}
+
+// handleGetSensorHysteresis implements Get Sensor Hysteresis (Sensor/Event
+// 0x25, v2.0§35.7).
+func handleGetSensorHysteresis(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sensors := sensorDevice(hctx)
+	if sensors == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.GetSensorHysteresisRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	s, err := sensors.Get(ctx, sensorLUN(hctx), typed.SensorNumber)
+	if err != nil {
+		return sensorFailure(err)
+	}
+	if s.HysteresisAccess == types.SensorHysteresisAccess_No {
+		return nil, types.CodeIllegalCommand, nil
+	}
+	resp := &sensor.GetSensorHysteresisResponse{
+		PositiveRaw: s.PositiveHysteresis,
+		NegativeRaw: s.NegativeHysteresis,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetSensorThresholds implements Set Sensor Thresholds (Sensor/Event
+// 0x26, v2.0§35.8).
+func handleSetSensorThresholds(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sensors := sensorDevice(hctx)
+	if sensors == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.SetSensorThresholdsRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	var mask uint8
+	mask = types.SetOrClearBit0(mask, typed.SetLNC)
+	mask = types.SetOrClearBit1(mask, typed.SetLCR)
+	mask = types.SetOrClearBit2(mask, typed.SetLNR)
+	mask = types.SetOrClearBit3(mask, typed.SetUNC)
+	mask = types.SetOrClearBit4(mask, typed.SetUCR)
+	mask = types.SetOrClearBit5(mask, typed.SetUNR)
+	values := [bmc.NumThresholds]uint8{typed.LNC_Raw, typed.LCR_Raw, typed.LNR_Raw, typed.UNC_Raw, typed.UCR_Raw, typed.UNR_Raw}
+	if err := sensors.SetThresholds(ctx, sensorLUN(hctx), typed.SensorNumber, mask, values); err != nil {
+		return sensorFailure(err)
+	}
+	return nil, types.CodeOK, nil
+}
+
+// handleGetSensorThresholds implements Get Sensor Thresholds (Sensor/Event
+// 0x27, v2.0§35.9). Unreadable thresholds are returned as 00h.
+func handleGetSensorThresholds(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sensors := sensorDevice(hctx)
+	if sensors == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.GetSensorThresholdsRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	s, err := sensors.Get(ctx, sensorLUN(hctx), typed.SensorNumber)
+	if err != nil {
+		return sensorFailure(err)
+	}
+	if !s.IsThreshold() || s.ThresholdAccess == types.SensorThresholdAccess_No {
+		return nil, types.CodeIllegalCommand, nil
+	}
+
+	var values [bmc.NumThresholds]uint8
+	for i := range values {
+		if s.ReadableThresholds&(1<<i) != 0 {
+			values[i] = s.Thresholds[i]
+		}
+	}
+	resp := &sensor.GetSensorThresholdsResponse{
+		LNC_Readable: types.IsBit0Set(s.ReadableThresholds),
+		LCR_Readable: types.IsBit1Set(s.ReadableThresholds),
+		LNR_Readable: types.IsBit2Set(s.ReadableThresholds),
+		UNC_Readable: types.IsBit3Set(s.ReadableThresholds),
+		UCR_Readable: types.IsBit4Set(s.ReadableThresholds),
+		UNR_Readable: types.IsBit5Set(s.ReadableThresholds),
+		LNC_Raw:      values[bmc.ThresholdLNC],
+		LCR_Raw:      values[bmc.ThresholdLCR],
+		LNR_Raw:      values[bmc.ThresholdLNR],
+		UNC_Raw:      values[bmc.ThresholdUNC],
+		UCR_Raw:      values[bmc.ThresholdUCR],
+		UNR_Raw:      values[bmc.ThresholdUNR],
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetSensorEventEnable implements Set Sensor Event Enable
+// (Sensor/Event 0x28, v2.0§35.10). Mode 11b is reserved.
+func handleSetSensorEventEnable(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sensors := sensorDevice(hctx)
+	if sensors == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.SetSensorEventEnableRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+
+	var selected bmc.SensorEventMask
+	selected.Assert, selected.Deassert = typed.SensorEventFlag.EventMasks()
+	var enable, disable bmc.SensorEventMask
+	switch typed.Mode {
+	case sensor.SetSensorEventEnableModeNoChange:
+	case sensor.SetSensorEventEnableModeEnable:
+		enable = selected
+	case sensor.SetSensorEventEnableModeDisable:
+		disable = selected
+	default:
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+
+	err := sensors.SetEventEnable(ctx, sensorLUN(hctx), typed.SensorNumber, !typed.DisableEventMessages, !typed.DisableSensorScanning, enable, disable)
+	if err != nil {
+		return sensorFailure(err)
+	}
+	return nil, types.CodeOK, nil
+}
+
+// handleGetSensorEventEnable implements Get Sensor Event Enable
+// (Sensor/Event 0x29, v2.0§35.11).
+func handleGetSensorEventEnable(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sensors := sensorDevice(hctx)
+	if sensors == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.GetSensorEventEnableRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	s, err := sensors.Get(ctx, sensorLUN(hctx), typed.SensorNumber)
+	if err != nil {
+		return sensorFailure(err)
+	}
+	if s.EventControl == types.SensorEventMessageControl_NoEvents {
+		return nil, types.CodeIllegalCommand, nil
+	}
+	resp := &sensor.GetSensorEventEnableResponse{
+		EventMessagesDisabled:  !s.EventMessagesEnabled,
+		SensorScanningDisabled: !s.ScanningEnabled,
+	}
+	resp.SensorEventFlag.SetEventMasks(s.EventEnables.Assert, s.EventEnables.Deassert)
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleRearmSensorEvents implements Re-arm Sensor Events (Sensor/Event
+// 0x2A, v2.0§35.12).
+func handleRearmSensorEvents(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sensors := sensorDevice(hctx)
+	if sensors == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.RearmSensorEventsRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	var selected bmc.SensorEventMask
+	selected.Assert, selected.Deassert = typed.SensorEventFlag.EventMasks()
+	if err := sensors.Rearm(ctx, sensorLUN(hctx), typed.SensorNumber, typed.RearmAllEventStatus, selected); err != nil {
+		return sensorFailure(err)
+	}
+	return nil, types.CodeOK, nil
+}
+
+// handleGetSensorEventStatus implements Get Sensor Event Status
+// (Sensor/Event 0x2B, v2.0§35.13).
+func handleGetSensorEventStatus(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sensors := sensorDevice(hctx)
+	if sensors == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.GetSensorEventStatusRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	s, reading, err := sensors.Read(ctx, sensorLUN(hctx), typed.SensorNumber)
+	if err != nil {
+		return sensorFailure(err)
+	}
+	resp := &sensor.GetSensorEventStatusResponse{
+		EventMessagesDisabled:  !s.EventMessagesEnabled,
+		SensorScanningDisabled: !s.ScanningEnabled,
+		ReadingUnavailable:     reading.Unavailable,
+	}
+	resp.SensorEventFlag.SetEventMasks(s.EventStatus.Assert, s.EventStatus.Deassert)
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetSensorReading implements Get Sensor Reading (Sensor/Event 0x2D,
+// v2.0§35.14). A reading the HAL cannot provide is reported with the
+// "reading/state unavailable" bit rather than an error completion code.
+func handleGetSensorReading(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sensors := sensorDevice(hctx)
+	if sensors == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.GetSensorReadingRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	s, reading, err := sensors.Read(ctx, sensorLUN(hctx), typed.SensorNumber)
+	if err != nil {
+		return sensorFailure(err)
+	}
+
+	resp := &sensor.GetSensorReadingResponse{
+		Reading:                reading.Raw,
+		EventMessagesDisabled:  !s.EventMessagesEnabled,
+		SensorScanningDisabled: !s.ScanningEnabled,
+		ReadingUnavailable:     reading.Unavailable,
+	}
+	if s.IsThreshold() {
+		status := reading.ThresholdStatus
+		resp.Below_LNC = types.IsBit0Set(status)
+		resp.Below_LCR = types.IsBit1Set(status)
+		resp.Below_LNR = types.IsBit2Set(status)
+		resp.Above_UNC = types.IsBit3Set(status)
+		resp.Above_UCR = types.IsBit4Set(status)
+		resp.Above_UNR = types.IsBit5Set(status)
+	} else {
+		resp.ActiveStates = discreteStates(reading.States)
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetSensorType implements Get Sensor Type (Sensor/Event 0x2F,
+// v2.0§35.16).
+func handleGetSensorType(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sensors := sensorDevice(hctx)
+	if sensors == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed sensor.GetSensorTypeRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	s, err := sensors.Get(ctx, sensorLUN(hctx), typed.SensorNumber)
+	if err != nil {
+		return sensorFailure(err)
+	}
+	resp := &sensor.GetSensorTypeResponse{
+		SensorType:       s.Type,
+		EventReadingType: s.EventReadingType,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// discreteStates expands a 15-bit state mask (bit n = state n).
+func discreteStates(states uint16) types.Mask_DiscreteEvent {
+	bit := func(n uint) bool { return states&(1<<n) != 0 }
+	return types.Mask_DiscreteEvent{
+		State_0: bit(0), State_1: bit(1), State_2: bit(2), State_3: bit(3),
+		State_4: bit(4), State_5: bit(5), State_6: bit(6), State_7: bit(7),
+		State_8: bit(8), State_9: bit(9), State_10: bit(10), State_11: bit(11),
+		State_12: bit(12), State_13: bit(13), State_14: bit(14),
+	}
+}
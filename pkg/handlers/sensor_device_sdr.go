@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/storage"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// deviceSDRs returns the BMC's Device SDRs, or nil when it serves none.
+func deviceSDRs(hctx *HandlerContext) *bmc.DeviceSDRStore {
+	if hctx == nil || hctx.BMC == nil {
+		return nil
+	}
+	return hctx.BMC.DeviceSDRs
+}
+
+// handleGetDeviceSDRInfo implements Get Device SDR Info (Sensor/Event 0x20,
+// v2.0§35.2). The sensor count is that of the LUN the request was addressed
+// to; the change indicator is only sent for a dynamic population.
+func handleGetDeviceSDRInfo(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := deviceSDRs(hctx)
+	if d == nil {
+		return nil, types.CodeInvalidCommand, nil
+	}
+
+	var typed storage.GetDeviceSDRInfoRequest
+	_ = typed.Unpack(req)
+
+	info, err := d.Info(ctx)
+	if err != nil {
+		return nil, codeFromErr(err), err
+	}
+	count := info.SensorCount[hctx.LUN&0x03]
+	if typed.GetSDRCount {
+		count = info.SDRCount
+	}
+	resp := &storage.GetDeviceSDRInfoResponse{
+		Count:                           uint8(min(count, 0xff)),
+		DynamicSensorPopulation:         info.Dynamic,
+		LUN0HasSensors:                  info.SensorCount[0] > 0,
+		LUN1HasSensors:                  info.SensorCount[1] > 0,
+		LUN2HasSensors:                  info.SensorCount[2] > 0,
+		LUN3HasSensors:                  info.SensorCount[3] > 0,
+		SensorPopulationChangeIndicator: info.ChangeIndicator,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetDeviceSDR implements Get Device SDR (Sensor/Event 0x21,
+// v2.0§35.3). Like Get SDR, a read that does not start at offset 0 needs
+// the current reservation.
+func handleGetDeviceSDR(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	d := deviceSDRs(hctx)
+	if d == nil {
+		return nil, types.CodeInvalidCommand, nil
+	}
+
+	var typed storage.GetDeviceSDRRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if typed.ReadOffset > 0 && !d.ValidateReservation(typed.ReservationID) {
+		return nil, types.CodeReservationCanceled, nil
+	}
+
+	record, nextID, err := d.GetRecord(ctx, typed.RecordID)
+	if err != nil {
+		if bmc.StorageMissing(err) {
+			return nil, types.CodeRequestedDataNotPresent, nil
+		}
+		return nil, codeFromErr(err), err
+	}
+	if int(typed.ReadOffset) >= len(record) {
+		return nil, types.CodeRequestedDataNotPresent, nil
+	}
+
+	want := int(typed.ReadBytes)
+	avail := len(record) - int(typed.ReadOffset)
+	if typed.ReadBytes == 0xff || want > avail {
+		want = avail
+	}
+	if want > maxSDRReadBytes {
+		return nil, types.CodeCannotReturnRequestedDataBytes, nil
+	}
+
+	start := int(typed.ReadOffset)
+	resp := &storage.GetDeviceSDRResponse{
+		NextRecordID: nextID,
+		RecordData:   record[start : start+want],
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleReserveDeviceSDRRepo implements Reserve Device SDR Repository
+// (Sensor/Event 0x22, v2.0§35.4).
+func handleReserveDeviceSDRRepo(ctx context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	d := deviceSDRs(hctx)
+	if d == nil {
+		return nil, types.CodeInvalidCommand, nil
+	}
+	resp := &storage.ReserveDeviceSDRRepoResponse{ReservationID: d.Reserve()}
+	return resp.Pack(), types.CodeOK, nil
+}
@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/sensor"
+	"github.com/bougou/go-ipmi/pkg/command/storage"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// newTestBMCWithSensor returns a BMC whose SDR repository holds one Full
+// threshold sensor 0x10 (UNC 80, UCR 90 settable; LNC 10 read-only) and a
+// Compact discrete sensor 0x20.
+func newTestBMCWithSensor(t *testing.T) (*bmc.BMC, *mock.HAL) {
+	t.Helper()
+	b, m := newTestBMCWithStorage(t)
+	full := &types.SDRFull{
+		SensorNumber:           0x10,
+		SensorType:             types.SensorTypeTemperature,
+		SensorEventReadingType: types.EventReadingTypeThreshold,
+		SensorInitialization:   types.SensorInitialization{InitScanning: true, InitEvents: true},
+		SensorCapabilities: types.SensorCapabilities{
+			HysteresisAccess:    types.SensorHysteresisAccess_ReadableSettable,
+			ThresholdAccess:     types.SensorThresholdAccess_ReadableSettable,
+			EventMessageControl: types.SensorEventMessageControl_PerThresholdState,
+		},
+		ReadingFactors:        types.ReadingFactors{M: 2, B: -5, R_Exp: -1},
+		LNC_Raw:               10,
+		UNC_Raw:               80,
+		UCR_Raw:               90,
+		PositiveHysteresisRaw: 2,
+		NegativeHysteresisRaw: 3,
+	}
+	full.Mask.Threshold.LNC = types.Mask_Threshold{Readable: true, StatusReturned: true}
+	full.Mask.Threshold.UNC = types.Mask_Threshold{Readable: true, Settable: true, StatusReturned: true, High_Assert: true}
+	full.Mask.Threshold.UCR = types.Mask_Threshold{Readable: true, Settable: true, StatusReturned: true, High_Assert: true}
+
+	compact := &types.SDRCompact{
+		SensorNumber:           0x20,
+		SensorType:             types.SensorTypeButtonSwitch,
+		SensorEventReadingType: types.EventReadingType(0x6f),
+		SensorInitialization:   types.SensorInitialization{InitScanning: true, InitEvents: true},
+		SensorCapabilities: types.SensorCapabilities{
+			EventMessageControl: types.SensorEventMessageControl_PerThresholdState,
+		},
+		SensorUnit: types.SensorUnit{AnalogDataFormat: types.SensorAnalogUnitFormat_NotAnalog},
+	}
+	compact.Mask.Discrete.Assert.State_0 = true
+
+	ctx := context.Background()
+	_ = m.Storage().SDR().Write(ctx, 1, full.Pack(1))
+	_ = m.Storage().SDR().Write(ctx, 2, compact.Pack(2))
+	m.Sensors().(*mock.Sensors).Values = map[uint8]uint8{0x10: 85}
+	return b, m
+}
+
+func TestHandleGetSensorReading(t *testing.T) {
+	b, m := newTestBMCWithSensor(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	resp, cc, err := handleGetSensorReading(ctx, hctx, []byte{0x10})
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("reading: cc=%v err=%v", cc, err)
+	}
+	var got sensor.GetSensorReadingResponse
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if got.Reading != 85 || got.ReadingUnavailable || got.EventMessagesDisabled || got.SensorScanningDisabled {
+		t.Fatalf("reading response: %+v", got)
+	}
+	if got.ThresholdStatus() != types.SensorThresholdStatus_UNC {
+		t.Fatalf("threshold status: %v", got.ThresholdStatus())
+	}
+
+	delete(m.Sensors().(*mock.Sensors).Values, 0x10)
+	resp, cc, _ = handleGetSensorReading(ctx, hctx, []byte{0x10})
+	if cc != types.CodeOK {
+		t.Fatalf("HAL error: want OK with unavailable bit, got %v", cc)
+	}
+	_ = got.Unpack(resp)
+	if !got.ReadingUnavailable {
+		t.Fatal("reading unavailable bit not set")
+	}
+
+	if err := b.Sensors.SetDiscreteState(ctx, 0, 0x20, 0x0001); err != nil {
+		t.Fatal(err)
+	}
+	resp, _, _ = handleGetSensorReading(ctx, hctx, []byte{0x20})
+	_ = got.Unpack(resp)
+	if !got.ActiveStates.State_0 || got.ActiveStates.State_1 {
+		t.Fatalf("discrete states: %+v", got.ActiveStates)
+	}
+
+	if _, cc, _ = handleGetSensorReading(ctx, hctx, []byte{0x99}); cc != types.CodeRequestedDataNotPresent {
+		t.Fatalf("unknown sensor: want CBh, got %v", cc)
+	}
+	if _, cc, _ = handleGetSensorReading(ctx, hctx, nil); cc != types.CodeRequestDataTruncated {
+		t.Fatalf("empty request: want C6h, got %v", cc)
+	}
+}
+
+func TestHandleSensorThresholds_SetGet(t *testing.T) {
+	b, _ := newTestBMCWithSensor(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	set := &sensor.SetSensorThresholdsRequest{SensorNumber: 0x10, SetUNC: true, UNC_Raw: 88}
+	if _, cc, err := handleSetSensorThresholds(ctx, hctx, set.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("set: cc=%v err=%v", cc, err)
+	}
+	resp, cc, err := handleGetSensorThresholds(ctx, hctx, []byte{0x10})
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("get: cc=%v err=%v", cc, err)
+	}
+	var got sensor.GetSensorThresholdsResponse
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if got.UNC_Raw != 88 || got.UCR_Raw != 90 || got.LNC_Raw != 10 || !got.UNC_Readable || got.UNR_Readable {
+		t.Fatalf("thresholds: %+v", got)
+	}
+
+	set = &sensor.SetSensorThresholdsRequest{SensorNumber: 0x10, SetLNC: true, LNC_Raw: 1}
+	if _, cc, _ = handleSetSensorThresholds(ctx, hctx, set.Pack()); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("non-settable threshold: want CCh, got %v", cc)
+	}
+	if _, cc, _ = handleGetSensorThresholds(ctx, hctx, []byte{0x20}); cc != types.CodeIllegalCommand {
+		t.Fatalf("discrete sensor: want CDh, got %v", cc)
+	}
+}
+
+func TestHandleSensorHysteresis_SetGet(t *testing.T) {
+	b, _ := newTestBMCWithSensor(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	set := &sensor.SetSensorHysteresisRequest{SensorNumber: 0x10, PositiveHysteresis: 4, NegativeHysteresis: 5}
+	if _, cc, err := handleSetSensorHysteresis(ctx, hctx, set.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("set: cc=%v err=%v", cc, err)
+	}
+	get := &sensor.GetSensorHysteresisRequest{SensorNumber: 0x10}
+	resp, cc, err := handleGetSensorHysteresis(ctx, hctx, get.Pack())
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("get: cc=%v err=%v", cc, err)
+	}
+	var got sensor.GetSensorHysteresisResponse
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if got.PositiveRaw != 4 || got.NegativeRaw != 5 {
+		t.Fatalf("hysteresis: %+v", got)
+	}
+
+	get.SensorNumber = 0x20
+	if _, cc, _ = handleGetSensorHysteresis(ctx, hctx, get.Pack()); cc != types.CodeIllegalCommand {
+		t.Fatalf("no hysteresis support: want CDh, got %v", cc)
+	}
+}
+
+// TestHandleSensorThresholds_PerLUN verifies a sensor number owned on two
+// LUNs names two sensors, each addressed by the request's LUN.
+func TestHandleSensorThresholds_PerLUN(t *testing.T) {
+	b, m := newTestBMCWithSensor(t)
+	ctx := context.Background()
+	lun1 := &types.SDRFull{
+		GeneratorID:            types.GeneratorID(types.BMC_SA) | 1<<8,
+		SensorNumber:           0x10,
+		SensorType:             types.SensorTypeVoltage,
+		SensorEventReadingType: types.EventReadingTypeThreshold,
+		SensorCapabilities:     types.SensorCapabilities{ThresholdAccess: types.SensorThresholdAccess_ReadableSettable},
+		ReadingFactors:         types.ReadingFactors{M: 1},
+		UNC_Raw:                40,
+	}
+	lun1.Mask.Threshold.UNC = types.Mask_Threshold{Readable: true, Settable: true}
+	_ = m.Storage().SDR().Write(ctx, 3, lun1.Pack(3))
+
+	thresholds := func(hctx *HandlerContext) sensor.GetSensorThresholdsResponse {
+		t.Helper()
+		resp, cc, err := handleGetSensorThresholds(ctx, hctx, []byte{0x10})
+		if err != nil || cc != types.CodeOK {
+			t.Fatalf("get on LUN %d: cc=%v err=%v", hctx.LUN, cc, err)
+		}
+		var got sensor.GetSensorThresholdsResponse
+		if err := got.Unpack(resp); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	lun0Ctx, lun1Ctx := &HandlerContext{BMC: b}, &HandlerContext{BMC: b, LUN: 1}
+	if got := thresholds(lun0Ctx); got.UNC_Raw != 80 {
+		t.Fatalf("LUN 0 UNC = %d, want 80", got.UNC_Raw)
+	}
+	if got := thresholds(lun1Ctx); got.UNC_Raw != 40 {
+		t.Fatalf("LUN 1 UNC = %d, want 40", got.UNC_Raw)
+	}
+
+	set := &sensor.SetSensorThresholdsRequest{SensorNumber: 0x10, SetUNC: true, UNC_Raw: 44}
+	if _, cc, err := handleSetSensorThresholds(ctx, lun1Ctx, set.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("set on LUN 1: cc=%v err=%v", cc, err)
+	}
+	if got := thresholds(lun0Ctx); got.UNC_Raw != 80 {
+		t.Fatalf("setting LUN 1 changed LUN 0: UNC = %d", got.UNC_Raw)
+	}
+	if got := thresholds(lun1Ctx); got.UNC_Raw != 44 {
+		t.Fatalf("LUN 1 UNC = %d, want 44", got.UNC_Raw)
+	}
+
+	// The mock HAL reads LUN 0's sensors only.
+	resp, cc, _ := handleGetSensorReading(ctx, lun1Ctx, []byte{0x10})
+	var reading sensor.GetSensorReadingResponse
+	if cc != types.CodeOK || reading.Unpack(resp) != nil || !reading.ReadingUnavailable {
+		t.Fatalf("LUN 1 reading: cc=%v %+v", cc, reading)
+	}
+	if _, cc, _ := handleGetSensorReading(ctx, &HandlerContext{BMC: b, LUN: 2}, []byte{0x10}); cc != types.CodeRequestedDataNotPresent {
+		t.Fatalf("no sensor on LUN 2: want CBh, got %v", cc)
+	}
+}
+
+func TestHandleSensorEventEnable(t *testing.T) {
+	b, _ := newTestBMCWithSensor(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	set := &sensor.SetSensorEventEnableRequest{SensorNumber: 0x10, Mode: sensor.SetSensorEventEnableModeDisable}
+	set.SensorEvent_UCR_High_Assert = true
+	if _, cc, err := handleSetSensorEventEnable(ctx, hctx, set.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("set: cc=%v err=%v", cc, err)
+	}
+
+	resp, cc, err := handleGetSensorEventEnable(ctx, hctx, []byte{0x10})
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("get: cc=%v err=%v", cc, err)
+	}
+	var got sensor.GetSensorEventEnableResponse
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if got.EventMessagesDisabled || got.SensorScanningDisabled {
+		t.Fatalf("sensor-wide enables: %+v", got)
+	}
+	if !got.SensorEvent_UNC_High_Assert || got.SensorEvent_UCR_High_Assert {
+		t.Fatalf("assert enables: UNC+=%v UCR+=%v", got.SensorEvent_UNC_High_Assert, got.SensorEvent_UCR_High_Assert)
+	}
+
+	set = &sensor.SetSensorEventEnableRequest{SensorNumber: 0x10, DisableEventMessages: true}
+	if _, cc, _ = handleSetSensorEventEnable(ctx, hctx, set.Pack()); cc != types.CodeOK {
+		t.Fatalf("disable events: cc=%v", cc)
+	}
+	resp, _, _ = handleGetSensorEventEnable(ctx, hctx, []byte{0x10})
+	_ = got.Unpack(resp)
+	if !got.EventMessagesDisabled {
+		t.Fatal("event messages still enabled")
+	}
+
+	if _, cc, _ = handleSetSensorEventEnable(ctx, hctx, []byte{0x10, 0xf0}); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("reserved mode: want CCh, got %v", cc)
+	}
+}
+
+func TestHandleSensorEventStatusAndRearm(t *testing.T) {
+	b, _ := newTestBMCWithSensor(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	resp, cc, err := handleGetSensorEventStatus(ctx, hctx, []byte{0x10})
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("status: cc=%v err=%v", cc, err)
+	}
+	var got sensor.GetSensorEventStatusResponse
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.SensorEventFlag.TrueEvents()) != 0 {
+		t.Fatalf("fresh sensor has event status: %v", got.SensorEventFlag.TrueEvents())
+	}
+
+	rearm := &sensor.RearmSensorEventsRequest{SensorNumber: 0x10, RearmAllEventStatus: true}
+	if _, cc, err = handleRearmSensorEvents(ctx, hctx, rearm.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("re-arm: cc=%v err=%v", cc, err)
+	}
+	rearm.SensorNumber = 0x99
+	if _, cc, _ = handleRearmSensorEvents(ctx, hctx, rearm.Pack()); cc != types.CodeRequestedDataNotPresent {
+		t.Fatalf("unknown sensor: want CBh, got %v", cc)
+	}
+}
+
+func TestHandleGetSensorTypeAndFactors(t *testing.T) {
+	b, _ := newTestBMCWithSensor(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	resp, cc, err := handleGetSensorType(ctx, hctx, []byte{0x20})
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("type: cc=%v err=%v", cc, err)
+	}
+	var typ sensor.GetSensorTypeResponse
+	if err := typ.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if typ.SensorType != types.SensorTypeButtonSwitch || typ.EventReadingType != 0x6f {
+		t.Fatalf("type: %+v", typ)
+	}
+
+	req := &sensor.GetSensorReadingFactorsRequest{SensorNumber: 0x10}
+	resp, cc, err = handleGetSensorReadingFactors(ctx, hctx, req.Pack())
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("factors: cc=%v err=%v", cc, err)
+	}
+	var factors sensor.GetSensorReadingFactorsResponse
+	if err := factors.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if factors.NextReading != 0xff || factors.M != 2 || factors.B != -5 || factors.R_Exp != -1 {
+		t.Fatalf("factors: %+v", factors)
+	}
+
+	req.SensorNumber = 0x20
+	if _, cc, _ = handleGetSensorReadingFactors(ctx, hctx, req.Pack()); cc != types.CodeIllegalCommand {
+		t.Fatalf("compact sensor: want CDh, got %v", cc)
+	}
+}
+
+func TestRegisterAllHandlers_IncludesSensor(t *testing.T) {
+	r := NewRegistry()
+	RegisterAllHandlers(r)
+	if _, ok := r.handlers[makeKey(uint8(types.CommandGetSensorReading.NetFn), types.CommandGetSensorReading.ID)]; !ok {
+		t.Fatal("Get Sensor Reading not registered")
+	}
+	if got := MinimumPrivilege(NetFnSensorEventRequest, CmdSetSensorThresholds); got != bmc.PrivilegeLevelOperator {
+		t.Fatalf("Set Sensor Thresholds privilege: %v", got)
+	}
+}
+
+func TestHandleDeviceSDRs(t *testing.T) {
+	ctx := context.Background()
+	store := (&mock.Storage{}).SDR()
+	full := &types.SDRFull{SensorNumber: 0x50, SensorType: types.SensorTypeTemperature, IDStringBytes: []byte("Satellite Temp")}
+	_ = store.Write(ctx, 1, full.Pack(1))
+	_ = store.Write(ctx, 2, (&types.SDRCompact{GeneratorID: 0x0120, SensorNumber: 0x51}).Pack(2))
+	d := bmc.NewDeviceSDRStore(store, true)
+	b := bmc.New(bmc.DeviceInfo{}, [16]byte{}, mock.New(), bmc.WithDeviceSDRs(d))
+	hctx := &HandlerContext{BMC: b, LUN: 1}
+
+	resp, cc, _ := handleGetDeviceSDRInfo(ctx, hctx, nil)
+	var info storage.GetDeviceSDRInfoResponse
+	if err := info.Unpack(resp); err != nil || cc != types.CodeOK {
+		t.Fatalf("info: cc=%#02x err=%v", uint8(cc), err)
+	}
+	if info.Count != 1 || !info.DynamicSensorPopulation || !info.LUN0HasSensors || !info.LUN1HasSensors || info.LUN2HasSensors {
+		t.Fatalf("LUN 1 sensor count: %+v", info)
+	}
+	resp, _, _ = handleGetDeviceSDRInfo(ctx, hctx, (&storage.GetDeviceSDRInfoRequest{GetSDRCount: true}).Pack())
+	if err := info.Unpack(resp); err != nil || info.Count != 2 {
+		t.Fatalf("SDR count: %+v %v", info, err)
+	}
+
+	// A read past offset 0 needs the reservation.
+	get := &storage.GetDeviceSDRRequest{RecordID: 1, ReadOffset: 16, ReadBytes: 8}
+	if _, cc, _ := handleGetDeviceSDR(ctx, hctx, get.Pack()); cc != types.CodeReservationCanceled {
+		t.Fatalf("unreserved partial read: cc=%#02x", uint8(cc))
+	}
+	resp, _, _ = handleReserveDeviceSDRRepo(ctx, hctx, nil)
+	var reserve storage.ReserveDeviceSDRRepoResponse
+	if err := reserve.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	get.ReservationID = reserve.ReservationID
+	resp, cc, _ = handleGetDeviceSDR(ctx, hctx, get.Pack())
+	var rec storage.GetDeviceSDRResponse
+	if err := rec.Unpack(resp); err != nil || cc != types.CodeOK {
+		t.Fatalf("partial read: cc=%#02x err=%v", uint8(cc), err)
+	}
+	if rec.NextRecordID != 2 || len(rec.RecordData) != 8 {
+		t.Fatalf("partial read: %+v", rec)
+	}
+
+	// The Device SDR sensors are served by the sensor commands, on the
+	// LUN their record owns them on.
+	reading := &sensor.GetSensorReadingRequest{SensorNumber: 0x51}
+	if _, cc, _ := handleGetSensorReading(ctx, hctx, reading.Pack()); cc != types.CodeOK {
+		t.Fatalf("device SDR sensor reading: cc=%#02x", uint8(cc))
+	}
+	reading.SensorNumber = 0x50
+	if _, cc, _ := handleGetSensorReading(ctx, hctx, reading.Pack()); cc != types.CodeRequestedDataNotPresent {
+		t.Fatalf("LUN 0 sensor read on LUN 1: cc=%#02x", uint8(cc))
+	}
+
+	resp, _, _ = handleGetDeviceID(ctx, hctx, nil)
+	if resp[1]&0x80 == 0 {
+		t.Fatalf("Get Device ID does not report Device SDRs: % x", resp)
+	}
+}
+
+func TestHandleDeviceSDRs_None(t *testing.T) {
+	b, _ := newTestBMCWithStorage(t)
+	hctx := &HandlerContext{BMC: b}
+	if _, cc, _ := handleGetDeviceSDRInfo(context.Background(), hctx, nil); cc != types.CodeInvalidCommand {
+		t.Fatalf("no Device SDRs: cc=%#02x", uint8(cc))
+	}
+}
@@ -31,6 +31,19 @@ func RegisterSessionHandlers(r *Registry) {
 	r.RegisterFunc(types.CommandCloseSession, handleCloseSession)
 	r.RegisterFunc(types.CommandGetSessionInfo, handleGetSessionInfo)
 	registerV15SessionHandlers(r)
+
+	// Session setup and teardown cannot be disabled by the firmware firewall,
+	// or a client could lock every remote console out for good.
+	for _, c := range []types.Command{
+		types.CommandGetChannelAuthCapabilities,
+		types.CommandGetChannelCipherSuites,
+		types.CommandSetSessionPrivilegeLevel,
+		types.CommandCloseSession,
+		types.CommandGetSessionChallenge,
+		types.CommandActivateSession,
+	} {
+		r.SetConfigurable(c, false)
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -425,8 +438,8 @@ func HandleRAKP1(ctx context.Context, b *bmc.BMC, data []byte) ([]byte, error) {
 	}
 	sess.User = user
 	if user != nil {
-		if status, ok := authorizeSessionPrivilege(b, sess); !ok {
-			_ = b.Sessions.Close(bmcSessionID)
+		if status, ok := authorizeSessionPrivilege(ctx, b, sess); !ok {
+			_ = b.Sessions.Evict(bmcSessionID, bmc.EvictRefused)
 			return rakp2Error(tag, sess.ConsoleID, status), nil
 		}
 	}
@@ -488,9 +501,9 @@ func HandleRAKP3(ctx context.Context, b *bmc.BMC, data []byte) ([]byte, error) {
 	defer sess.ProcMu.Unlock()
 
 	// If the console sent a non-zero status in RAKP3, it means the console
-	// rejected RAKP2.  Close the session and return an error response.
+	// rejected RAKP2.  Discard the session and return an error response.
 	if statusCode != 0x00 {
-		_ = b.Sessions.Close(bmcSessionID)
+		_ = b.Sessions.Evict(bmcSessionID, bmc.EvictRefused)
 		return rakp4Error(tag, sess.ConsoleID, statusCode), nil
 	}
 
@@ -505,11 +518,11 @@ func HandleRAKP3(ctx context.Context, b *bmc.BMC, data []byte) ([]byte, error) {
 	}
 
 	if sess.User == nil || !hmacEqual(expected, req.KeyExchangeAuthenticationCode) {
-		_ = b.Sessions.Close(bmcSessionID)
+		_ = b.Sessions.Evict(bmcSessionID, bmc.EvictRefused)
 		return rakp4Error(tag, sess.ConsoleID, 0x0D), nil // Unauthorized name
 	}
-	if status, ok := authorizeSessionPrivilege(b, sess); !ok {
-		_ = b.Sessions.Close(bmcSessionID)
+	if status, ok := authorizeSessionPrivilege(ctx, b, sess); !ok {
+		_ = b.Sessions.Evict(bmcSessionID, bmc.EvictRefused)
 		return rakp4Error(tag, sess.ConsoleID, status), nil
 	}
 
@@ -546,7 +559,7 @@ func rakp4Error(tag uint8, consoleID uint32, status uint8) []byte {
 	return resp.Pack()
 }
 
-func authorizeSessionPrivilege(b *bmc.BMC, sess *bmc.Session) (uint8, bool) {
+func authorizeSessionPrivilege(ctx context.Context, b *bmc.BMC, sess *bmc.Session) (uint8, bool) {
 	if sess.User == nil || !sess.User.Enabled {
 		return 0x0D, false // Unauthorized name
 	}
@@ -558,9 +571,12 @@ func authorizeSessionPrivilege(b *bmc.BMC, sess *bmc.Session) (uint8, bool) {
 	if requested > sess.MaxPrivilege {
 		return 0x0A, false // Unauthorized role or privilege level
 	}
+	if requested > cipherSuitePrivilege(b, sess.AuthAlg, sess.IntegrityAlg, sess.CryptAlg) {
+		return 0x0A, false // Above the cipher suite's privilege limit
+	}
 
 	ch, err := b.Channels.Get(sess.Channel)
-	if err != nil || ch.AccessMode == bmc.ChannelAccessDisabled {
+	if err != nil || !b.ChannelAvailable(ctx, ch) {
 		return 0x0A, false
 	}
 	if requested > ch.MaxPrivilege {
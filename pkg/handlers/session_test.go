@@ -96,6 +96,40 @@ func TestHandleRAKP1AcceptsAuthorizedPrivilege(t *testing.T) {
 	}
 }
 
+func TestHandleRAKP1EnforcesCipherSuitePrivilege(t *testing.T) {
+	b := newTestBMC()
+	user, err := b.Users.Add(2, "ADMIN")
+	if err != nil {
+		t.Fatalf("add user: %v", err)
+	}
+	user.SetPassword([]byte("ADMIN"))
+	user.Enabled = true
+	user.ChannelAccess[lanChannelNumber] = bmc.UserChannelAccess{
+		MaxPrivilege: bmc.PrivilegeLevelAdministrator,
+		Enabled:      true,
+	}
+	// LAN parameter #24 caps every cipher suite at User.
+	var levels [bmc.MaxCipherSuiteEntries]bmc.PrivilegeLevel
+	for i := range levels {
+		levels[i] = bmc.PrivilegeLevelUser
+	}
+	if err := b.LANConfig.SetCipherSuitePrivileges(levels, len(b.ResolvedCipherSuites())); err != nil {
+		t.Fatal(err)
+	}
+	sess, err := b.Sessions.Allocate(0x01020304, types.AuthAlg_HMAC_SHA1, types.IntegrityAlg_HMAC_SHA1_96, types.CryptAlg_AES_CBC_128, bmc.PrivilegeLevelAdministrator, lanChannelNumber)
+	if err != nil {
+		t.Fatalf("allocate session: %v", err)
+	}
+
+	resp, err := HandleRAKP1(context.Background(), b, rakp1Payload(sess.BMCID, bmc.PrivilegeLevelAdministrator, "ADMIN"))
+	if err != nil {
+		t.Fatalf("HandleRAKP1: %v", err)
+	}
+	if len(resp) < 2 || resp[1] != 0x0A {
+		t.Fatalf("want unauthorized privilege status 0x0a, got %x", resp)
+	}
+}
+
 func rakp1Payload(bmcSessionID uint32, role bmc.PrivilegeLevel, username string) []byte {
 	payload := make([]byte, 28+len(username))
 	payload[0] = 0x01
@@ -71,7 +71,7 @@ func handleGetSessionChallenge(_ context.Context, hctx *HandlerContext, req []by
 	return resp, types.CodeOK, nil
 }
 
-func handleActivateSession(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+func handleActivateSession(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
 	if len(req) < 22 {
 		return nil, types.CodeRequestDataTruncated, nil
 	}
@@ -96,7 +96,7 @@ func handleActivateSession(_ context.Context, hctx *HandlerContext, req []byte)
 		return nil, CCV15InvalidSessionID, nil
 	}
 
-	if cc, ok := authorizeV15Session(hctx.BMC, sess, requested); !ok {
+	if cc, ok := authorizeV15Session(ctx, hctx.BMC, sess, requested); !ok {
 		return nil, cc, nil
 	}
 
@@ -197,13 +197,13 @@ func trimV15Username(username []byte) string {
 	return string(username[:end])
 }
 
-func authorizeV15Session(b *bmc.BMC, sess *bmc.V15Session, requested bmc.PrivilegeLevel) (types.CompletionCode, bool) {
+func authorizeV15Session(ctx context.Context, b *bmc.BMC, sess *bmc.V15Session, requested bmc.PrivilegeLevel) (types.CompletionCode, bool) {
 	if sess.User == nil || !sess.User.Enabled {
 		return CCV15InvalidSessionID, false
 	}
 
 	ch, err := b.Channels.Get(sess.Channel)
-	if err != nil || ch.AccessMode == bmc.ChannelAccessDisabled {
+	if err != nil || !b.ChannelAvailable(ctx, ch) {
 		return ccV15PrivilegeExceedsLimit, false
 	}
 	if requested > ch.MaxPrivilege {
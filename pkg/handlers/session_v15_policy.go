@@ -10,8 +10,12 @@ func MinimumPrivilege(netFn, cmd uint8) bmc.PrivilegeLevel {
 	switch netFn {
 	case NetFnChassisRequest:
 		switch cmd {
-		case CmdChassisControl:
+		case CmdChassisControl, CmdSetPowerRestorePolicy:
 			return bmc.PrivilegeLevelOperator
+		case CmdSetChassisCapabilities, CmdSetFrontPanelEnables, CmdSetPowerCycleInterval:
+			// Chassis configuration writes require Administrator (spec
+			// Appendix G).
+			return bmc.PrivilegeLevelAdministrator
 		default:
 			return bmc.PrivilegeLevelUser
 		}
@@ -23,11 +27,27 @@ func MinimumPrivilege(netFn, cmd uint8) bmc.PrivilegeLevel {
 			// Administrator (spec Appendix G). Set User Password in particular
 			// must never be reachable from a lesser-privileged session.
 			return bmc.PrivilegeLevelAdministrator
+		case CmdSetSystemInfoParam:
+			// Set System Info Parameters requires Administrator (spec
+			// Appendix G).
+			return bmc.PrivilegeLevelAdministrator
+		case CmdSetChannelAccess:
+			// Setting the channel access configuration requires
+			// Administrator (spec Appendix G).
+			return bmc.PrivilegeLevelAdministrator
+		case CmdSetCommandEnables, CmdSetCommandSubfunctionEnables:
+			// Configuring the firmware firewall requires Administrator (spec
+			// Appendix G).
+			return bmc.PrivilegeLevelAdministrator
 		case 0x4c: // Set User Payload Access (§24.6, Table 24-8): user administration
 			return bmc.PrivilegeLevelAdministrator
 		case CmdGetUserAccess, CmdGetUsername:
 			// Reading user configuration requires Operator (spec Appendix G).
 			return bmc.PrivilegeLevelOperator
+		case CmdResetWatchdogTimer, CmdSetWatchdogTimer:
+			// Arming the watchdog can reset or power off the managed system
+			// and requires Operator (spec Appendix G).
+			return bmc.PrivilegeLevelOperator
 		default:
 			return bmc.PrivilegeLevelUser
 		}
@@ -38,12 +58,68 @@ func MinimumPrivilege(netFn, cmd uint8) bmc.PrivilegeLevel {
 			// like Set LAN Configuration Parameters; the Activate Payload
 			// privilege itself comes from SOL parameter #2 (Table 26-5).
 			return bmc.PrivilegeLevelAdministrator
+		case CmdSetLanConfigParam:
+			// Set LAN Configuration Parameters requires Administrator (spec
+			// Appendix G).
+			return bmc.PrivilegeLevelAdministrator
 		case CmdGetLanConfigParam:
 			// Get LAN Configuration Parameters requires Operator (spec Appendix G).
 			return bmc.PrivilegeLevelOperator
 		default:
 			return bmc.PrivilegeLevelUser
 		}
+	case NetFnStorageRequest:
+		switch cmd {
+		case CmdAddSELEntry, CmdPartialAddSELEntry, CmdDeleteSELEntry,
+			CmdClearSEL, CmdSetSELTime, CmdSetSELTimeUTCOffset:
+			// Writing the SEL or its clock requires Operator (spec Appendix G).
+			return bmc.PrivilegeLevelOperator
+		case CmdWriteFRUData:
+			// Write FRU Data requires Operator (spec Appendix G).
+			return bmc.PrivilegeLevelOperator
+		case CmdAddSDR, CmdPartialAddSDR, CmdDeleteSDR, CmdClearSDRRepo,
+			CmdEnterSDRRepoUpdateMode, CmdExitSDRRepoUpdateMode,
+			CmdRunInitializationAgent:
+			// Updating the SDR repository and running the initialization
+			// agent require Operator (spec Appendix G).
+			return bmc.PrivilegeLevelOperator
+		default:
+			return bmc.PrivilegeLevelUser
+		}
+	case NetFnSensorEventRequest:
+		switch cmd {
+		case CmdSetSensorHysteresis, CmdSetSensorThresholds,
+			CmdSetSensorEventEnable, CmdRearmSensorEvents:
+			// Changing sensor thresholds and event behaviour requires
+			// Operator (spec Appendix G).
+			return bmc.PrivilegeLevelOperator
+		case CmdGetPEFConfigParam:
+			// Reading the PEF configuration requires Operator (spec
+			// Appendix G).
+			return bmc.PrivilegeLevelOperator
+		case CmdArmPEFPostponeTimer, CmdSetPEFConfigParam,
+			CmdSetLastProcessedEventID, CmdGetLastProcessedEventID,
+			CmdAlertImmediate:
+			// PEF configuration, event processing state and sending
+			// alerts require Administrator (spec Appendix G).
+			return bmc.PrivilegeLevelAdministrator
+		default:
+			return bmc.PrivilegeLevelUser
+		}
+	case NetFnGroupExtensionRequest:
+		switch cmd {
+		case CmdSetDCMIPowerLimit, CmdActivateDCMIPowerLimit,
+			CmdSetDCMIAssetTag, CmdSetDCMIThermalLimit:
+			// Power limiting, the asset tag and thermal limits require
+			// Operator (DCMI v1.5 Table 6-1).
+			return bmc.PrivilegeLevelOperator
+		case CmdSetDCMIMgmtControllerID, CmdSetDCMIConfigParam:
+			// The controller identity and DHCP configuration require
+			// Administrator (DCMI v1.5 Table 6-1).
+			return bmc.PrivilegeLevelAdministrator
+		default:
+			return bmc.PrivilegeLevelUser
+		}
 	default:
 		return bmc.PrivilegeLevelUser
 	}
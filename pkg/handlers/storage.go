@@ -6,12 +6,55 @@ const (
 	maxSDRReadBytes = 16
 )
 
-// RegisterStorageHandlers adds P0 read-only Storage NetFn handlers to r.
+// NetFnStorageRequest and the FRU, SDR and SEL command bytes below are
+// referenced by the privilege table.
+const (
+	NetFnStorageRequest uint8 = 0x0a
+
+	CmdWriteFRUData           uint8 = 0x12
+	CmdAddSDR                 uint8 = 0x24
+	CmdPartialAddSDR          uint8 = 0x25
+	CmdDeleteSDR              uint8 = 0x26
+	CmdClearSDRRepo           uint8 = 0x27
+	CmdEnterSDRRepoUpdateMode uint8 = 0x2a
+	CmdExitSDRRepoUpdateMode  uint8 = 0x2b
+	CmdRunInitializationAgent uint8 = 0x2c
+	CmdAddSELEntry            uint8 = 0x44
+	CmdPartialAddSELEntry     uint8 = 0x45
+	CmdDeleteSELEntry         uint8 = 0x46
+	CmdClearSEL               uint8 = 0x47
+	CmdSetSELTime             uint8 = 0x49
+	CmdSetSELTimeUTCOffset    uint8 = 0x5d
+)
+
+// RegisterStorageHandlers adds the Storage NetFn handlers (FRU, SDR
+// repository, and SEL device) to r.
 func RegisterStorageHandlers(r *Registry) {
 	r.RegisterFunc(types.CommandGetFRUInventoryAreaInfo, handleGetFRUInventoryAreaInfo)
 	r.RegisterFunc(types.CommandReadFRUData, handleReadFRUData)
+	r.RegisterFunc(types.CommandWriteFRUData, handleWriteFRUData)
 	r.RegisterFunc(types.CommandGetSDRRepoInfo, handleGetSDRRepoInfo)
 	r.RegisterFunc(types.CommandGetSDRRepoAllocInfo, handleGetSDRRepoAllocInfo)
 	r.RegisterFunc(types.CommandReserveSDRRepo, handleReserveSDRRepo)
 	r.RegisterFunc(types.CommandGetSDR, handleGetSDR)
+	r.RegisterFunc(types.CommandAddSDR, handleAddSDR)
+	r.RegisterFunc(types.CommandPartialAddSDR, handlePartialAddSDR)
+	r.RegisterFunc(types.CommandDeleteSDR, handleDeleteSDR)
+	r.RegisterFunc(types.CommandClearSDRRepo, handleClearSDRRepo)
+	r.RegisterFunc(types.CommandEnterSDRRepoUpdateMode, handleEnterSDRRepoUpdateMode)
+	r.RegisterFunc(types.CommandExitSDRRepoUpdateMode, handleExitSDRRepoUpdateMode)
+	r.RegisterFunc(types.CommandRunInitializationAgent, handleRunInitializationAgent)
+
+	r.RegisterFunc(types.CommandGetSELInfo, handleGetSELInfo)
+	r.RegisterFunc(types.CommandGetSELAllocInfo, handleGetSELAllocInfo)
+	r.RegisterFunc(types.CommandReserveSEL, handleReserveSEL)
+	r.RegisterFunc(types.CommandGetSELEntry, handleGetSELEntry)
+	r.RegisterFunc(types.CommandAddSELEntry, handleAddSELEntry)
+	r.RegisterFunc(types.CommandPartialAddSELEntry, handlePartialAddSELEntry)
+	r.RegisterFunc(types.CommandDeleteSELEntry, handleDeleteSELEntry)
+	r.RegisterFunc(types.CommandClearSEL, handleClearSEL)
+	r.RegisterFunc(types.CommandGetSELTime, handleGetSELTime)
+	r.RegisterFunc(types.CommandSetSELTime, handleSetSELTime)
+	r.RegisterFunc(types.CommandGetSELTimeUTCOffset, handleGetSELTimeUTCOffset)
+	r.RegisterFunc(types.CommandSetSELTimeUTCOffset, handleSetSELTimeUTCOffset)
 }
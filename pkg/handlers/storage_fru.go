@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 
 	"github.com/bougou/go-ipmi/pkg/bmc"
 	"github.com/bougou/go-ipmi/pkg/command/storage"
@@ -75,6 +76,51 @@ func handleReadFRUData(ctx context.Context, hctx *HandlerContext, req []byte) ([
 	return resp.Pack(), types.CodeOK, nil
 }
 
+// handleWriteFRUData implements Write FRU Data (Storage 0x12, spec §34.3).
+// The write lands in the FRU inventory area as is, unless FRU write
+// validation is on ([bmc.FRUInventory.SetValidateWrites]).
+func handleWriteFRUData(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	fru := hctx.BMC.FRUInventory()
+	if fru == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+
+	var typed storage.WriteFRUDataRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if len(typed.WriteData) == 0 {
+		return nil, types.CodeRequestDataLengthInvalid, nil
+	}
+
+	n, err := fru.Write(ctx, typed.FRUDeviceID, typed.WriteOffset, typed.WriteData)
+	if err != nil {
+		cc := fruWriteCC(err)
+		if cc == types.CodeUnspecifiedError {
+			return nil, cc, err
+		}
+		return nil, cc, nil
+	}
+	resp := &storage.WriteFRUDataResponse{CountWritten: uint8(n)}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// fruWriteCC maps a FRU write failure to its completion code (spec §34.3).
+func fruWriteCC(err error) types.CompletionCode {
+	switch {
+	case bmc.StorageMissing(err):
+		return types.CodeRequestedDataNotPresent
+	case errors.Is(err, bmc.ErrFRUWriteProtected):
+		return types.CodeWriteFRUDataWriteProtected
+	case errors.Is(err, bmc.ErrFRUWriteOutOfRange):
+		return types.CodeParameterOutOfRange
+	case errors.Is(err, bmc.ErrFRUInvalid):
+		return types.CodeRequestDataFieldInvalid
+	default:
+		return codeFromErr(err)
+	}
+}
+
 func storageHAL(hctx *HandlerContext) hal.StorageHAL {
 	if hctx == nil || hctx.BMC == nil || hctx.BMC.HAL() == nil {
 		return nil
@@ -2,12 +2,53 @@ package handlers
 
 import (
 	"context"
+	"errors"
 
 	"github.com/bougou/go-ipmi/pkg/bmc"
 	"github.com/bougou/go-ipmi/pkg/command/storage"
 	"github.com/bougou/go-ipmi/pkg/types"
 )
 
+// sdrCommandCC maps SDR repository errors to completion codes (v2.0§33).
+func sdrCommandCC(err error) types.CompletionCode {
+	switch {
+	case err == nil:
+		return types.CodeOK
+	case bmc.StorageMissing(err):
+		return types.CodeRequestedDataNotPresent
+	case errors.Is(err, bmc.ErrSDRReservationCanceled):
+		return types.CodeReservationCanceled
+	case errors.Is(err, bmc.ErrSDRRepoFull):
+		return types.CodeOutOfSpace
+	case errors.Is(err, bmc.ErrSDRRecordMismatch):
+		return types.CodePartialAddRecordMismatch
+	case errors.Is(err, bmc.ErrSDRPartialOffset):
+		return types.CodeParameterOutOfRange
+	default:
+		return codeFromErr(err)
+	}
+}
+
+// sdrFailure returns the handler result for an SDR repository error. Errors
+// with no command-specific completion code are passed up for logging.
+func sdrFailure(err error) ([]byte, types.CompletionCode, error) {
+	cc := sdrCommandCC(err)
+	if cc == types.CodeUnspecifiedError {
+		return nil, cc, err
+	}
+	return nil, cc, nil
+}
+
+// sdrRepository returns the BMC's SDR repository, or nil when no SDR
+// storage backs it.
+func sdrRepository(hctx *HandlerContext) *bmc.SDRRepository {
+	store := storageHAL(hctx)
+	if store == nil || store.SDR() == nil {
+		return nil
+	}
+	return hctx.BMC.SDRRepository()
+}
+
 // encodeSDRRepoFreeSpace maps a free-byte count onto the Get SDR Repository
 // Info Free Space field (v2.0§33.9): 0000h = full, FFFEh = 64KB-2 or more,
 // FFFFh = unspecified.
@@ -105,6 +146,9 @@ func handleGetSDR(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte
 	if err := typed.Unpack(req); err != nil {
 		return nil, types.CodeRequestDataTruncated, nil
 	}
+	if repo := hctx.BMC.SDRRepository(); repo != nil && repo.UpdateMode() {
+		return nil, types.CodeCannotProvideResponseSDRRInUpdate, nil
+	}
 
 	if typed.ReadOffset > 0 {
 		if hctx.BMC.SDRRepo == nil || !hctx.BMC.SDRRepo.Validate(typed.ReservationID) {
@@ -148,3 +192,138 @@ func handleGetSDR(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte
 	}
 	return resp.Pack(), types.CodeOK, nil
 }
+
+// handleAddSDR implements Add SDR (Storage 0x24, v2.0§33.13). The request
+// carries a whole record, which must be as long as its header says.
+func handleAddSDR(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	repo := sdrRepository(hctx)
+	if repo == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+
+	var typed storage.AddSDRRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if len(typed.RecordData) != types.SDRRecordHeaderSize+int(typed.RecordData[4]) {
+		return nil, types.CodeRequestDataLengthInvalid, nil
+	}
+
+	id, err := repo.Add(ctx, typed.RecordData)
+	if err != nil {
+		return sdrFailure(err)
+	}
+	resp := &storage.AddSDRResponse{RecordID: id}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handlePartialAddSDR implements Partial Add SDR (Storage 0x25, v2.0§33.14).
+func handlePartialAddSDR(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	repo := sdrRepository(hctx)
+	if repo == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+
+	var typed storage.PartialAddSDRRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if req[5]&0x0f > 0x01 {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+
+	id, err := repo.PartialAdd(ctx, typed.ReservationID, typed.RecordID, typed.Offset, typed.RecordData, typed.LastPart)
+	if err != nil {
+		return sdrFailure(err)
+	}
+	resp := &storage.PartialAddSDRResponse{RecordID: id}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleDeleteSDR implements Delete SDR (Storage 0x26, v2.0§33.15).
+func handleDeleteSDR(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	repo := sdrRepository(hctx)
+	if repo == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+
+	var typed storage.DeleteSDRRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+
+	id, err := repo.Delete(ctx, typed.ReservationID, typed.RecordID)
+	if err != nil {
+		return sdrFailure(err)
+	}
+	resp := &storage.DeleteSDRResponse{RecordID: id}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleClearSDRRepo implements Clear SDR Repository (Storage 0x27,
+// v2.0§33.16). AAh initiates the erase and needs the reservation; 00h polls
+// erasure status and does not. The erase completes at once, so the response
+// always reports 1 (erase completed).
+func handleClearSDRRepo(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	repo := sdrRepository(hctx)
+	if repo == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	if len(req) < 6 {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+
+	var typed storage.ClearSDRRepoRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+
+	if !typed.GetErasureStatusFlag {
+		if err := repo.Clear(ctx, typed.ReservationID); err != nil {
+			return sdrFailure(err)
+		}
+	}
+	resp := &storage.ClearSDRRepoResponse{ErasureProgressStatus: 0x01}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleEnterSDRRepoUpdateMode implements Enter SDR Repository Update Mode
+// (Storage 0x2A, v2.0§33.19). Get SDR fails with D0h until the update mode
+// is exited or the BMC is reset.
+func handleEnterSDRRepoUpdateMode(ctx context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	repo := sdrRepository(hctx)
+	if repo == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	repo.EnterUpdateMode()
+	return nil, types.CodeOK, nil
+}
+
+// handleExitSDRRepoUpdateMode implements Exit SDR Repository Update Mode
+// (Storage 0x2B, v2.0§33.20).
+func handleExitSDRRepoUpdateMode(ctx context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	repo := sdrRepository(hctx)
+	if repo == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	repo.ExitUpdateMode()
+	return nil, types.CodeOK, nil
+}
+
+// handleRunInitializationAgent implements Run Initialization Agent (Storage
+// 0x2C, v2.0§33.21). Running the agent rebuilds sensor state from the SDR
+// repository, so updated records take effect. It runs to completion before
+// the response, which therefore always reports initialization completed.
+func handleRunInitializationAgent(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	var typed storage.RunInitializationAgentRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if !typed.GetStatus && hctx.BMC.Sensors != nil {
+		if err := hctx.BMC.Sensors.Reload(ctx); err != nil {
+			return nil, codeFromErr(err), err
+		}
+	}
+	resp := &storage.RunInitializationAgentResponse{Completed: true}
+	return resp.Pack(), types.CodeOK, nil
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/storage"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// selCommandCC maps SEL store errors to the completion codes of the SEL
+// Device commands (v2.0§31).
+func selCommandCC(err error) types.CompletionCode {
+	switch {
+	case err == nil:
+		return types.CodeOK
+	case bmc.StorageMissing(err):
+		return types.CodeRequestedDataNotPresent
+	case errors.Is(err, bmc.ErrSELReservationCanceled):
+		return types.CodeReservationCanceled
+	case errors.Is(err, bmc.ErrSELEraseInProgress):
+		return types.CodeSELEraseInProgress
+	case errors.Is(err, bmc.ErrSELFull):
+		return types.CodeOutOfSpace
+	case errors.Is(err, bmc.ErrSELRecordType):
+		return types.CodeAddSELEntryRecordTypeNotSupported
+	case errors.Is(err, bmc.ErrSELRecordMismatch):
+		return types.CodePartialAddRecordMismatch
+	case errors.Is(err, bmc.ErrSELPartialOffset):
+		return types.CodeParameterOutOfRange
+	default:
+		return codeFromErr(err)
+	}
+}
+
+// selFailure returns the handler result for a SEL store error. Errors with
+// no command-specific completion code are passed up for logging.
+func selFailure(err error) ([]byte, types.CompletionCode, error) {
+	cc := selCommandCC(err)
+	if cc == types.CodeUnspecifiedError {
+		return nil, cc, err
+	}
+	return nil, cc, nil
+}
+
+// selDevice returns the BMC's SEL device, or nil when no SEL storage backs it.
+func selDevice(hctx *HandlerContext) *bmc.SELStore {
+	if hctx == nil || hctx.BMC == nil || hctx.BMC.SEL == nil || !hctx.BMC.SEL.Supported() {
+		return nil
+	}
+	return hctx.BMC.SEL
+}
+
+// encodeSELFreeSpace maps a free-byte count onto the Get SEL Info Free Space
+// field (v2.0§31.2): FFFFh = 65535 bytes or more.
+func encodeSELFreeSpace(free int) uint16 {
+	if free <= 0 {
+		return 0
+	}
+	if free >= 0xFFFF {
+		return 0xFFFF
+	}
+	return uint16(free)
+}
+
+// handleGetSELInfo implements Get SEL Info (Storage 0x40, v2.0§31.2).
+func handleGetSELInfo(ctx context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	sel := selDevice(hctx)
+	if sel == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	info, err := sel.Info(ctx)
+	if err != nil {
+		return nil, codeFromErr(err), err
+	}
+	resp := &storage.GetSELInfoResponse{
+		SELVersion:         info.Version,
+		Entries:            info.Entries,
+		FreeBytes:          encodeSELFreeSpace(info.FreeBytes),
+		RecentAdditionTime: info.MostRecentAdd,
+		RecentEraseTime:    info.MostRecentErase,
+		OperationSupport: storage.SELOperationSupport{
+			Overflow:        info.Overflow,
+			DeleteSEL:       true,
+			PartialAddSEL:   true,
+			ReserveSEL:      true,
+			GetSELAllocInfo: true,
+		},
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetSELAllocInfo implements Get SEL Allocation Info (Storage 0x41,
+// v2.0§31.3).
+func handleGetSELAllocInfo(ctx context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	sel := selDevice(hctx)
+	if sel == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	info, err := sel.AllocInfo(ctx)
+	if err != nil {
+		return nil, codeFromErr(err), err
+	}
+	resp := &storage.GetSELAllocInfoResponse{
+		PossibleAllocUnits: info.PossibleAllocUnits,
+		AllocUnitsSize:     info.AllocUnitSize,
+		FreeAllocUnits:     info.FreeAllocUnits,
+		LargestFreeBlock:   info.LargestFreeBlock,
+		MaximumRecordSize:  info.MaximumRecordSize,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleReserveSEL implements Reserve SEL (Storage 0x42, v2.0§31.4).
+func handleReserveSEL(ctx context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	sel := selDevice(hctx)
+	if sel == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	resp := &storage.ReserveSELResponse{ReservationID: sel.Reserve()}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetSELEntry implements Get SEL Entry (Storage 0x43, v2.0§31.5).
+// Like Get SDR, only partial reads (non-zero offset) need a reservation.
+func handleGetSELEntry(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sel := selDevice(hctx)
+	if sel == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+
+	var typed storage.GetSELEntryRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if typed.Offset > 0 && !sel.Validate(typed.ReservationID) {
+		return nil, types.CodeReservationCanceled, nil
+	}
+
+	record, nextID, err := sel.GetEntry(ctx, typed.RecordID)
+	if err != nil {
+		return selFailure(err)
+	}
+	if int(typed.Offset) >= len(record) {
+		return nil, types.CodeParameterOutOfRange, nil
+	}
+
+	want := len(record) - int(typed.Offset)
+	if typed.ReadBytes != 0xff && int(typed.ReadBytes) < want {
+		want = int(typed.ReadBytes)
+	}
+	start := int(typed.Offset)
+	resp := &storage.GetSELEntryResponse{
+		NextRecordID: nextID,
+		Data:         record[start : start+want],
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleAddSELEntry implements Add SEL Entry (Storage 0x44, v2.0§31.6). The
+// request is the 16-byte record itself; the BMC assigns its Record ID.
+func handleAddSELEntry(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sel := selDevice(hctx)
+	if sel == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	if len(req) < bmc.SELRecordSize {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if len(req) > bmc.SELRecordSize {
+		return nil, types.CodeRequestDataLengthInvalid, nil
+	}
+
+	id, err := sel.Add(ctx, req)
+	if err != nil {
+		return selFailure(err)
+	}
+	resp := &storage.AddSELEntryResponse{RecordID: id}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handlePartialAddSELEntry implements Partial Add SEL Entry (Storage 0x45,
+// v2.0§31.7).
+func handlePartialAddSELEntry(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sel := selDevice(hctx)
+	if sel == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+
+	var typed storage.PartialAddSELEntryRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if req[5]&0x0f > 0x01 {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+
+	id, err := sel.PartialAdd(ctx, typed.ReservationID, typed.RecordID, typed.Offset, typed.RecordData, typed.LastPart)
+	if err != nil {
+		return selFailure(err)
+	}
+	resp := &storage.PartialAddSELEntryResponse{RecordID: id}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleDeleteSELEntry implements Delete SEL Entry (Storage 0x46, v2.0§31.8).
+func handleDeleteSELEntry(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sel := selDevice(hctx)
+	if sel == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+
+	var typed storage.DeleteSELEntryRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+
+	id, err := sel.Delete(ctx, typed.ReservationID, typed.RecordID)
+	if err != nil {
+		return selFailure(err)
+	}
+	resp := &storage.DeleteSELEntryResponse{RecordID: id}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleClearSEL implements Clear SEL (Storage 0x47, v2.0§31.9). AAh
+// initiates the erase and needs the reservation; 00h polls erasure status and
+// does not. The response reports 1 once the erase has completed, 0 while it
+// is in progress.
+func handleClearSEL(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sel := selDevice(hctx)
+	if sel == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	if len(req) < 6 {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+
+	var typed storage.ClearSELRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+
+	complete := sel.EraseComplete()
+	if !typed.GetErasureStatusFlag {
+		var err error
+		complete, err = sel.Clear(ctx, typed.ReservationID)
+		if err != nil {
+			return selFailure(err)
+		}
+	}
+	resp := &storage.ClearSELResponse{}
+	if complete {
+		resp.ErasureProgressStatus = 0x01
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleGetSELTime implements Get SEL Time (Storage 0x48, v2.0§31.10).
+func handleGetSELTime(ctx context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	sel := selDevice(hctx)
+	if sel == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	resp := &storage.GetSELTimeResponse{Time: sel.Time()}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetSELTime implements Set SEL Time (Storage 0x49, v2.0§31.11).
+func handleSetSELTime(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sel := selDevice(hctx)
+	if sel == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed storage.SetSELTimeRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	sel.SetTime(typed.Time)
+	return nil, types.CodeOK, nil
+}
+
+// handleGetSELTimeUTCOffset implements Get SEL Time UTC Offset (Storage 0x5C,
+// v2.0§31.11a).
+func handleGetSELTimeUTCOffset(ctx context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	sel := selDevice(hctx)
+	if sel == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	resp := &storage.GetSELTimeUTCOffsetResponse{MinutesOffset: sel.UTCOffset()}
+	return resp.Pack(), types.CodeOK, nil
+}
+
+// handleSetSELTimeUTCOffset implements Set SEL Time UTC Offset (Storage 0x5D,
+// v2.0§31.11b). Offsets outside -1440..1440 minutes, other than 07FFh
+// (unspecified), are out of range.
+func handleSetSELTimeUTCOffset(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	sel := selDevice(hctx)
+	if sel == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed storage.SetSELTimeUTCOffsetRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if !sel.SetUTCOffset(typed.MinutesOffset) {
+		return nil, types.CodeParameterOutOfRange, nil
+	}
+	return nil, types.CodeOK, nil
+}
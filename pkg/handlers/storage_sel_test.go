@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/storage"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func testSELEntryBytes() []byte {
+	rec := make([]byte, 16)
+	rec[2] = 0x02 // system event record
+	rec[7] = 0x20 // generator ID: BMC
+	rec[9] = 0x04 // EvMRev
+	rec[10] = 0x01
+	rec[11] = 0x30
+	rec[12] = 0x01
+	return rec
+}
+
+func reserveSEL(t *testing.T, hctx *HandlerContext) uint16 {
+	t.Helper()
+	resp, cc, err := handleReserveSEL(context.Background(), hctx, nil)
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("reserve: cc=%v err=%v", cc, err)
+	}
+	var decoded storage.ReserveSELResponse
+	if err := decoded.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	return decoded.ReservationID
+}
+
+func TestHandleSEL_AddGetDelete(t *testing.T) {
+	b, _ := newTestBMCWithStorage(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	resp, cc, err := handleAddSELEntry(ctx, hctx, testSELEntryBytes())
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("add: cc=%v err=%v", cc, err)
+	}
+	var added storage.AddSELEntryResponse
+	if err := added.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, cc, err = handleGetSELInfo(ctx, hctx, nil)
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("info: cc=%v err=%v", cc, err)
+	}
+	var info storage.GetSELInfoResponse
+	if err := info.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if info.Entries != 1 || info.SELVersion != bmc.SELVersion {
+		t.Fatalf("info: %+v", info)
+	}
+	if !info.OperationSupport.DeleteSEL || !info.OperationSupport.PartialAddSEL || !info.OperationSupport.ReserveSEL || !info.OperationSupport.GetSELAllocInfo {
+		t.Fatalf("operation support: %+v", info.OperationSupport)
+	}
+
+	get := &storage.GetSELEntryRequest{RecordID: 0, ReadBytes: 0xff}
+	resp, cc, err = handleGetSELEntry(ctx, hctx, get.Pack())
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("get: cc=%v err=%v", cc, err)
+	}
+	var entry storage.GetSELEntryResponse
+	if err := entry.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if entry.NextRecordID != 0xffff || len(entry.Data) != 16 {
+		t.Fatalf("entry: next=%#04x len=%d", entry.NextRecordID, len(entry.Data))
+	}
+	sel, err := types.ParseSEL(entry.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel.RecordID != added.RecordID {
+		t.Fatalf("record ID: want %#04x got %#04x", added.RecordID, sel.RecordID)
+	}
+
+	del := &storage.DeleteSELEntryRequest{ReservationID: 0x7777, RecordID: added.RecordID}
+	if _, cc, _ = handleDeleteSELEntry(ctx, hctx, del.Pack()); cc != types.CodeReservationCanceled {
+		t.Fatalf("delete without reservation: want C5h, got %v", cc)
+	}
+	del.ReservationID = reserveSEL(t, hctx)
+	if _, cc, err = handleDeleteSELEntry(ctx, hctx, del.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("delete: cc=%v err=%v", cc, err)
+	}
+	if _, cc, _ = handleGetSELEntry(ctx, hctx, get.Pack()); cc != types.CodeRequestedDataNotPresent {
+		t.Fatalf("get after delete: want CBh, got %v", cc)
+	}
+}
+
+func TestHandleGetSELEntry_PartialReadNeedsReservation(t *testing.T) {
+	b, _ := newTestBMCWithStorage(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+	if _, cc, _ := handleAddSELEntry(ctx, hctx, testSELEntryBytes()); cc != types.CodeOK {
+		t.Fatalf("add: cc=%v", cc)
+	}
+
+	get := &storage.GetSELEntryRequest{RecordID: 0, Offset: 8, ReadBytes: 4}
+	if _, cc, _ := handleGetSELEntry(ctx, hctx, get.Pack()); cc != types.CodeReservationCanceled {
+		t.Fatalf("partial read without reservation: want C5h, got %v", cc)
+	}
+	get.ReservationID = reserveSEL(t, hctx)
+	resp, cc, err := handleGetSELEntry(ctx, hctx, get.Pack())
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("partial read: cc=%v err=%v", cc, err)
+	}
+	if len(resp) != 2+4 {
+		t.Fatalf("partial read length: got %d", len(resp))
+	}
+}
+
+func TestHandleAddSELEntry_Errors(t *testing.T) {
+	b, _ := newTestBMCWithStorage(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	if _, cc, _ := handleAddSELEntry(ctx, hctx, make([]byte, 15)); cc != types.CodeRequestDataTruncated {
+		t.Fatalf("short record: want C6h, got %v", cc)
+	}
+	rec := testSELEntryBytes()
+	rec[2] = 0x10
+	if _, cc, _ := handleAddSELEntry(ctx, hctx, rec); cc != types.CodeAddSELEntryRecordTypeNotSupported {
+		t.Fatalf("record type 10h: want 80h, got %v", cc)
+	}
+
+	b.SEL.SetCapacity(1)
+	if _, cc, _ := handleAddSELEntry(ctx, hctx, testSELEntryBytes()); cc != types.CodeOK {
+		t.Fatalf("add: cc=%v", cc)
+	}
+	if _, cc, _ := handleAddSELEntry(ctx, hctx, testSELEntryBytes()); cc != types.CodeOutOfSpace {
+		t.Fatalf("full SEL: want C4h, got %v", cc)
+	}
+}
+
+func TestHandlePartialAddSELEntry(t *testing.T) {
+	b, _ := newTestBMCWithStorage(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+	rec := testSELEntryBytes()
+	resID := reserveSEL(t, hctx)
+
+	first := &storage.PartialAddSELEntryRequest{ReservationID: resID, RecordData: rec[:10]}
+	resp, cc, err := handlePartialAddSELEntry(ctx, hctx, first.Pack())
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("first part: cc=%v err=%v", cc, err)
+	}
+	var part storage.PartialAddSELEntryResponse
+	if err := part.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+
+	last := &storage.PartialAddSELEntryRequest{ReservationID: resID, RecordID: part.RecordID, Offset: 10, LastPart: true, RecordData: rec[10:]}
+	if _, cc, err = handlePartialAddSELEntry(ctx, hctx, last.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("last part: cc=%v err=%v", cc, err)
+	}
+
+	get := &storage.GetSELEntryRequest{RecordID: part.RecordID, ReadBytes: 0xff}
+	if _, cc, _ = handleGetSELEntry(ctx, hctx, get.Pack()); cc != types.CodeOK {
+		t.Fatalf("get assembled record: cc=%v", cc)
+	}
+
+	first.RecordData = rec[:4]
+	resp, _, _ = handlePartialAddSELEntry(ctx, hctx, first.Pack())
+	_ = part.Unpack(resp)
+	short := &storage.PartialAddSELEntryRequest{ReservationID: resID, RecordID: part.RecordID, Offset: 4, LastPart: true, RecordData: rec[4:8]}
+	if _, cc, _ = handlePartialAddSELEntry(ctx, hctx, short.Pack()); cc != types.CodePartialAddRecordMismatch {
+		t.Fatalf("short record: want 80h, got %v", cc)
+	}
+}
+
+func TestHandleClearSEL(t *testing.T) {
+	b, _ := newTestBMCWithStorage(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+	if _, cc, _ := handleAddSELEntry(ctx, hctx, testSELEntryBytes()); cc != types.CodeOK {
+		t.Fatalf("add: cc=%v", cc)
+	}
+
+	bad := (&storage.ClearSELRequest{ReservationID: 1}).Pack()
+	bad[3] = 'X'
+	if _, cc, _ := handleClearSEL(ctx, hctx, bad); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("bad CLR: want CCh, got %v", cc)
+	}
+
+	clear := &storage.ClearSELRequest{ReservationID: reserveSEL(t, hctx)}
+	resp, cc, err := handleClearSEL(ctx, hctx, clear.Pack())
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("clear: cc=%v err=%v", cc, err)
+	}
+	if resp[0]&0x0f != 0x01 {
+		t.Fatalf("erasure status: want completed, got %#02x", resp[0])
+	}
+
+	// Status polls need no reservation.
+	status := &storage.ClearSELRequest{GetErasureStatusFlag: true}
+	if resp, cc, _ = handleClearSEL(ctx, hctx, status.Pack()); cc != types.CodeOK || resp[0]&0x0f != 0x01 {
+		t.Fatalf("status: cc=%v resp=%x", cc, resp)
+	}
+
+	resp, _, _ = handleGetSELInfo(ctx, hctx, nil)
+	var info storage.GetSELInfoResponse
+	_ = info.Unpack(resp)
+	if info.Entries != 0 {
+		t.Fatalf("entries after clear: %d", info.Entries)
+	}
+}
+
+func TestHandleSELTimeUTCOffset(t *testing.T) {
+	b, _ := newTestBMCWithStorage(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	set := &storage.SetSELTimeUTCOffsetRequest{MinutesOffset: -300}
+	if _, cc, err := handleSetSELTimeUTCOffset(ctx, hctx, set.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("set: cc=%v err=%v", cc, err)
+	}
+	resp, cc, err := handleGetSELTimeUTCOffset(ctx, hctx, nil)
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("get: cc=%v err=%v", cc, err)
+	}
+	var got storage.GetSELTimeUTCOffsetResponse
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if got.MinutesOffset != -300 {
+		t.Fatalf("offset: want -300 got %d", got.MinutesOffset)
+	}
+
+	set.MinutesOffset = 2000
+	if _, cc, _ := handleSetSELTimeUTCOffset(ctx, hctx, set.Pack()); cc != types.CodeParameterOutOfRange {
+		t.Fatalf("out of range offset: want C9h, got %v", cc)
+	}
+}
+
+func TestSELHandlers_NilHAL(t *testing.T) {
+	b := bmc.New(bmc.DeviceInfo{DeviceID: 1, IPMIVersion: 0x20}, [16]byte{}, nil)
+	hctx := &HandlerContext{BMC: b}
+
+	if _, cc, err := handleGetSELInfo(context.Background(), hctx, nil); err != nil || cc != types.CodeNotSupported {
+		t.Fatalf("sel info: cc=%v err=%v", cc, err)
+	}
+	resp, _, _ := handleGetDeviceID(context.Background(), hctx, nil)
+	if resp[5]&0x04 != 0 {
+		t.Fatal("SEL device bit set without SEL storage")
+	}
+}
@@ -89,6 +89,50 @@ func TestHandleReadFRUData_MissingDevice(t *testing.T) {
 	}
 }
 
+func TestHandleWriteFRUData(t *testing.T) {
+	b, m := newTestBMCWithStorage(t)
+	fru := testFRUBytes(t)
+	_ = m.Storage().FRU().Write(context.Background(), 0, fru)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	req := &storage.WriteFRUDataRequest{FRUDeviceID: 0, WriteOffset: 16, WriteData: []byte{0xaa, 0xbb}}
+	resp, cc, err := handleWriteFRUData(ctx, hctx, req.Pack())
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("write: cc=%v err=%v", cc, err)
+	}
+	var decoded storage.WriteFRUDataResponse
+	if err := decoded.Unpack(resp); err != nil || decoded.CountWritten != 2 {
+		t.Fatalf("write response: %+v %v", decoded, err)
+	}
+	read := (&storage.ReadFRUDataRequest{FRUDeviceID: 0, ReadOffset: 16, ReadCount: 2}).Pack()
+	resp, _, _ = handleReadFRUData(ctx, hctx, read)
+	if len(resp) != 3 || resp[1] != 0xaa || resp[2] != 0xbb {
+		t.Fatalf("read back: % x", resp)
+	}
+
+	req.WriteOffset = uint16(len(fru))
+	if _, cc, _ := handleWriteFRUData(ctx, hctx, req.Pack()); cc != types.CodeParameterOutOfRange {
+		t.Fatalf("past the end: cc=%v", cc)
+	}
+	if _, cc, _ := handleWriteFRUData(ctx, hctx, []byte{0, 0, 0}); cc != types.CodeRequestDataLengthInvalid {
+		t.Fatalf("no data: cc=%v", cc)
+	}
+
+	b.FRUInventory().SetWriteProtected(0, true)
+	req.WriteOffset = 0
+	if _, cc, _ := handleWriteFRUData(ctx, hctx, req.Pack()); cc != types.CodeWriteFRUDataWriteProtected {
+		t.Fatalf("write-protected: cc=%v", cc)
+	}
+
+	b.FRUInventory().SetWriteProtected(0, false)
+	b.FRUInventory().SetValidateWrites(true)
+	req.WriteData = []byte{0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}
+	if _, cc, _ := handleWriteFRUData(ctx, hctx, req.Pack()); cc != types.CodeRequestDataFieldInvalid {
+		t.Fatalf("bad common header: cc=%v", cc)
+	}
+}
+
 func TestHandleGetSDR_Traverse(t *testing.T) {
 	b, m := newTestBMCWithStorage(t)
 	body := make([]byte, 32)
@@ -203,6 +247,128 @@ func TestHandleGetSDRRepoInfo_EmptyRepoFreeSpace(t *testing.T) {
 	}
 }
 
+// testSDRBytes returns a Compact Sensor SDR for sensor number n.
+func testSDRBytes(n uint8) []byte {
+	compact := &types.SDRCompact{
+		SensorNumber:           types.SensorNumber(n),
+		SensorType:             types.SensorTypeTemperature,
+		SensorEventReadingType: types.EventReadingTypeThreshold,
+	}
+	return compact.Pack(0)
+}
+
+func TestHandleSDRRepoUpdate(t *testing.T) {
+	b, _ := newTestBMCWithStorage(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+	reserve := func() uint16 {
+		resp, _, _ := handleReserveSDRRepo(ctx, hctx, nil)
+		var r storage.ReserveSDRRepoResponse
+		_ = r.Unpack(resp)
+		return r.ReservationID
+	}
+
+	rec := testSDRBytes(0x40)
+	resp, cc, err := handleAddSDR(ctx, hctx, rec)
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("add: cc=%#02x err=%v", uint8(cc), err)
+	}
+	var added storage.AddSDRResponse
+	if err := added.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if _, cc, _ := handleAddSDR(ctx, hctx, rec[:len(rec)-1]); cc != types.CodeRequestDataLengthInvalid {
+		t.Fatalf("short record: cc=%#02x", uint8(cc))
+	}
+
+	// Partial add in two fragments; a change cancels the reservation.
+	resID := reserve()
+	rec = testSDRBytes(0x41)
+	first := &storage.PartialAddSDRRequest{ReservationID: resID, RecordData: rec[:10]}
+	resp, cc, _ = handlePartialAddSDR(ctx, hctx, first.Pack())
+	var partial storage.PartialAddSDRResponse
+	if err := partial.Unpack(resp); err != nil || cc != types.CodeOK {
+		t.Fatalf("first part: cc=%#02x err=%v", uint8(cc), err)
+	}
+	last := &storage.PartialAddSDRRequest{ReservationID: resID, RecordID: partial.RecordID, Offset: 10, LastPart: true, RecordData: rec[10:]}
+	if _, cc, _ := handlePartialAddSDR(ctx, hctx, last.Pack()); cc != types.CodeOK {
+		t.Fatalf("last part: cc=%#02x", uint8(cc))
+	}
+	if _, cc, _ := handlePartialAddSDR(ctx, hctx, first.Pack()); cc != types.CodeReservationCanceled {
+		t.Fatalf("canceled reservation: cc=%#02x", uint8(cc))
+	}
+
+	del := &storage.DeleteSDRRequest{ReservationID: reserve(), RecordID: added.RecordID}
+	if _, cc, _ := handleDeleteSDR(ctx, hctx, del.Pack()); cc != types.CodeOK {
+		t.Fatalf("delete: cc=%#02x", uint8(cc))
+	}
+	del.ReservationID = reserve()
+	if _, cc, _ := handleDeleteSDR(ctx, hctx, del.Pack()); cc != types.CodeRequestedDataNotPresent {
+		t.Fatalf("delete missing: cc=%#02x", uint8(cc))
+	}
+
+	resp, _, _ = handleGetSDRRepoInfo(ctx, hctx, nil)
+	var info storage.GetSDRRepoInfoResponse
+	if err := info.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if info.RecordCount != 1 || info.MostRecentAdditionTime.Unix() == 0xffffffff || info.MostRecentEraseTime.Unix() == 0xffffffff {
+		t.Fatalf("info after update: %+v", info)
+	}
+	if op := info.SDROperationSupport; !op.SupportModalSDRRepoUpdate || !op.SupportNonModalSDRRepoUpdate || !op.SupportDeleteSDR || !op.SupportPartialAddSDR {
+		t.Fatalf("operation support: %+v", op)
+	}
+
+	clear := &storage.ClearSDRRepoRequest{ReservationID: reserve()}
+	resp, cc, _ = handleClearSDRRepo(ctx, hctx, clear.Pack())
+	if cc != types.CodeOK || len(resp) != 1 || resp[0] != 0x01 {
+		t.Fatalf("clear: cc=%#02x resp=% x", uint8(cc), resp)
+	}
+	if _, cc, _ := handleClearSDRRepo(ctx, hctx, clear.Pack()); cc != types.CodeReservationCanceled {
+		t.Fatalf("clear with stale reservation: cc=%#02x", uint8(cc))
+	}
+}
+
+func TestHandleSDRRepoUpdateMode(t *testing.T) {
+	b, _ := newTestBMCWithStorage(t)
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+	if _, err := b.Sensors.Keys(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, cc, _ := handleAddSDR(ctx, hctx, testSDRBytes(0x42)); cc != types.CodeOK {
+		t.Fatalf("add: cc=%#02x", uint8(cc))
+	}
+	if _, err := b.Sensors.Get(ctx, 0, 0x42); err == nil {
+		t.Fatal("sensor 0x42 in service before the initialization agent ran")
+	}
+
+	if _, cc, _ := handleEnterSDRRepoUpdateMode(ctx, hctx, nil); cc != types.CodeOK {
+		t.Fatalf("enter: cc=%#02x", uint8(cc))
+	}
+	get := (&storage.GetSDRRequest{RecordID: 0, ReadBytes: 5}).Pack()
+	if _, cc, _ := handleGetSDR(ctx, hctx, get); cc != types.CodeCannotProvideResponseSDRRInUpdate {
+		t.Fatalf("get in update mode: cc=%#02x", uint8(cc))
+	}
+	if _, cc, _ := handleExitSDRRepoUpdateMode(ctx, hctx, nil); cc != types.CodeOK {
+		t.Fatalf("exit: cc=%#02x", uint8(cc))
+	}
+	if _, cc, _ := handleGetSDR(ctx, hctx, get); cc != types.CodeOK {
+		t.Fatalf("get after update mode: cc=%#02x", uint8(cc))
+	}
+
+	// The initialization agent brings the new sensor into service.
+	run := &storage.RunInitializationAgentRequest{}
+	resp, cc, _ := handleRunInitializationAgent(ctx, hctx, run.Pack())
+	var status storage.RunInitializationAgentResponse
+	if err := status.Unpack(resp); err != nil || cc != types.CodeOK || !status.Completed {
+		t.Fatalf("run: cc=%#02x %+v %v", uint8(cc), status, err)
+	}
+	if _, err := b.Sensors.Get(ctx, 0, 0x42); err != nil {
+		t.Fatalf("sensor 0x42 after initialization: %v", err)
+	}
+}
+
 func TestHandleGetSDR_LastRecordID(t *testing.T) {
 	// v2.0§33.12: Record ID FFFFh returns the last SDR in the repository.
 	b, m := newTestBMCWithStorage(t)
@@ -248,6 +414,9 @@ func TestHandleGetDeviceID_StorageBits(t *testing.T) {
 	if resp[5]&0x08 == 0 {
 		t.Fatal("FRU inventory bit not set in additional support")
 	}
+	if resp[5]&0x04 == 0 {
+		t.Fatal("SEL device bit not set in additional support")
+	}
 }
 
 func TestStorageHandlers_NilHAL(t *testing.T) {
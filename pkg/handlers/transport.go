@@ -3,15 +3,20 @@ package handlers
 import (
 	"context"
 	"encoding/binary"
+	"errors"
+	"net"
 
 	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/hal"
 	"github.com/bougou/go-ipmi/pkg/types"
 )
 
-// CmdGetLanConfigParam is the Get LAN Configuration Parameters command byte,
-// referenced by the privilege table. The Transport request NetFn is declared
-// with the payload handlers.
-const CmdGetLanConfigParam uint8 = 0x02
+// Transport (LAN) command bytes referenced by the privilege table. The
+// Transport request NetFn is declared with the payload handlers.
+const (
+	CmdSetLanConfigParam uint8 = 0x01
+	CmdGetLanConfigParam uint8 = 0x02
+)
 
 // LAN configuration parameter revision reported for every supported parameter.
 // The high nibble is the "oldest revision supported" and the low nibble the
@@ -26,9 +31,44 @@ const standardPrimaryRMCPPort uint16 = 623
 
 // RegisterTransportHandlers adds all Transport (LAN) command handlers to r.
 func RegisterTransportHandlers(r *Registry) {
+	r.RegisterFunc(types.CommandSetLanConfigParam, handleSetLanConfigParam)
 	r.RegisterFunc(types.CommandGetLanConfigParam, handleGetLanConfigParam)
 }
 
+// lanCommandCC maps a LAN configuration failure to its completion code
+// (v2.0§23.1).
+func lanCommandCC(err error) types.CompletionCode {
+	switch {
+	case err == nil:
+		return types.CodeOK
+	case errors.Is(err, bmc.ErrLANNoNetwork):
+		return types.CodeNotSupported
+	case errors.Is(err, bmc.ErrLANParamNotSupported):
+		return types.CodeParameterNotSupported
+	case errors.Is(err, bmc.ErrLANSetInProgress):
+		return types.CodeParamConfigSetInProgressConflict
+	case errors.Is(err, bmc.ErrLANParamReadOnly):
+		return types.CodeParamConfigSetReadOnly
+	case errors.Is(err, bmc.ErrLANParamInvalid):
+		return types.CodeRequestDataFieldInvalid
+	case errors.Is(err, bmc.ErrLANParamOutOfRange),
+		errors.Is(err, bmc.ErrAlertDestinationOutOfRange):
+		return types.CodeParameterOutOfRange
+	default:
+		return codeFromHalErr(err)
+	}
+}
+
+// lanFailure is the handler return for a LAN configuration failure. Only an
+// unmapped error is passed on for logging.
+func lanFailure(err error) ([]byte, types.CompletionCode, error) {
+	cc := lanCommandCC(err)
+	if cc == types.CodeUnspecifiedError {
+		return nil, cc, err
+	}
+	return nil, cc, nil
+}
+
 // handleGetLanConfigParam implements Get LAN Configuration Parameters
 // (Transport 0x02, spec §23.2), the command in-band software and the metal
 // agent use to discover the BMC's own network address.
@@ -42,19 +82,23 @@ func RegisterTransportHandlers(r *Registry) {
 //
 // The channel is validated to be a configured LAN channel (0x0E resolves to the
 // arrival channel), but the reference BMC models a single NIC, so every LAN
-// channel resolves to the same NetworkHAL configuration. The set/block
-// selectors are not used by the parameters this handler serves. When bit 7 of
-// the channel byte is set the caller wants only the parameter revision, so the
-// data field is omitted (spec §23.2).
-//
-// The address-family parameters (IP, IP source, MAC, subnet, default gateway)
-// are backed by [hal.NetworkHAL]; when Network() is nil the BMC has no NIC to
-// describe and the command returns CannotExecuteCommandNotSupported. The set-in
-// progress, authentication-type support and primary RMCP port parameters are
-// static and answer without a NIC. Any other selector returns
-// ParameterNotSupported (spec Table 23-4 permits a BMC to implement a subset).
+// channel resolves to the same NetworkHAL configuration and alert
+// destinations. The set selector picks the alert destination of params #18
+// and #19; the block selector is not used by the parameters this handler
+// serves. When bit 7 of the channel byte is set the caller wants only the
+// parameter revision, so the data field is omitted (spec §23.2).
 //
-// Only Get is implemented; Set LAN Configuration Parameters is out of scope.
+// The network interface parameters (IP, IP source, MAC, subnet, default
+// gateway, VLAN, IPv6) are backed by [hal.NetworkHAL] through
+// [bmc.LANConfigStore], so values staged by a set in progress read back
+// before they are committed; when Network() is nil the BMC has no NIC to
+// describe and the command returns CannotExecuteCommandNotSupported. The
+// set-in-progress, authentication-type support, primary RMCP port and cipher
+// suite parameters answer without a NIC, as do the community string and
+// alert destination parameters (#16-#19), which come from
+// [bmc.LANAlertStore]. The set selector picks the static address of param
+// #56. Any other selector returns ParameterNotSupported (spec Table 23-4
+// permits a BMC to implement a subset).
 func handleGetLanConfigParam(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
 	// The command's request is 4 bytes (channel, parameter selector, set
 	// selector, block selector); the bundled client always packs all four.
@@ -79,7 +123,7 @@ func handleGetLanConfigParam(ctx context.Context, hctx *HandlerContext, req []by
 	// revision-only for an unsupported selector still returns the
 	// parameter-not-supported code rather than a spurious success. The data of
 	// a revision-only query is discarded; the only cost is a NetworkHAL read.
-	data, cc := lanParamData(ctx, hctx, param)
+	data, cc := lanParamData(ctx, hctx, param, req[2])
 	if cc != types.CodeOK {
 		return nil, cc, nil
 	}
@@ -89,14 +133,274 @@ func handleGetLanConfigParam(ctx context.Context, hctx *HandlerContext, req []by
 	return lanParamResponse(data...), types.CodeOK, nil
 }
 
+// handleSetLanConfigParam implements Set LAN Configuration Parameters
+// (Transport 0x01, spec §23.1). The request body is the channel number, the
+// parameter selector and the parameter data, laid out as Get LAN
+// Configuration Parameters returns it.
+//
+// Network interface parameters are written through [bmc.LANConfigStore]:
+// while Set In Progress (#0) is "set in progress" they are staged, and
+// "commit write" or "set complete" hands them to [hal.NetworkHAL.SetConfig];
+// otherwise each write goes to the NetworkHAL at once. A setting the
+// NetworkHAL lists in [hal.IPConfig.Fixed] returns 82h, as do the read-only
+// parameters. The community string and alert destinations (#16, #18, #19)
+// go to [bmc.LANAlertStore] and the cipher suite privilege levels (#24) to
+// the LAN configuration store; neither is staged.
+func handleSetLanConfigParam(ctx context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	if len(req) < 2 {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	if hctx == nil || hctx.BMC == nil || hctx.BMC.LANConfig == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	if !lanChannelValid(hctx, req[0]&0x0f) {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+
+	param := types.LanConfigParamSelector(req[1])
+	data := req[2:]
+	if n, ok := lanParamSetLength(param); ok && len(data) < n {
+		return nil, types.CodeRequestDataLengthInvalid, nil
+	}
+	if err := setLanParam(ctx, hctx, param, data); err != nil {
+		return lanFailure(err)
+	}
+	return nil, types.CodeOK, nil
+}
+
+// lanParamSetLength returns the data length of a writable LAN parameter.
+// ok is false for parameters that cannot be written.
+func lanParamSetLength(param types.LanConfigParamSelector) (n int, ok bool) {
+	switch param {
+	case types.LanConfigParamSelector_SetInProgress,
+		types.LanConfigParamSelector_IPSource,
+		types.LanConfigParamSelector_VLANPriority,
+		types.LanConfigParamSelector_IPv6Enables:
+		return 1, true
+	case types.LanConfigParamSelector_VLANID:
+		return 2, true
+	case types.LanConfigParamSelector_IP,
+		types.LanConfigParamSelector_SubnetMask,
+		types.LanConfigParamSelector_DefaultGatewayIP,
+		types.LanConfigParamSelector_AlertDestinationType:
+		return 4, true
+	case types.LanConfigParamSelector_MAC:
+		return 6, true
+	case types.LanConfigParamSelector_CipherSuitesPrivLevel:
+		return 9, true
+	case types.LanConfigParamSelector_AlertDestinationAddress:
+		return 13, true
+	case types.LanConfigParamSelector_CommunityString:
+		return 18, true
+	case types.LanConfigParamSelector_IPv6StaticAddress:
+		return 20, true
+	default:
+		return 0, false
+	}
+}
+
+// setLanParam writes one LAN configuration parameter. data is at least
+// [lanParamSetLength] bytes long.
+func setLanParam(ctx context.Context, hctx *HandlerContext, param types.LanConfigParamSelector, data []byte) error {
+	lan := hctx.BMC.LANConfig
+
+	switch param {
+	case types.LanConfigParamSelector_SetInProgress:
+		switch types.SetInProgressState(data[0] & 0x03) {
+		case types.SetInProgress_SetComplete:
+			return lan.Commit(ctx, true)
+		case types.SetInProgress_SetInProgress:
+			return lan.BeginSet()
+		case types.SetInProgress_CommitWrite:
+			return lan.Commit(ctx, false)
+		default:
+			return bmc.ErrLANParamInvalid
+		}
+
+	case types.LanConfigParamSelector_IP:
+		return lan.UpdateNetwork(ctx, hal.NetworkFieldAddress, func(cfg *hal.IPConfig) error {
+			copy(cfg.IP[:], data)
+			return nil
+		})
+
+	case types.LanConfigParamSelector_SubnetMask:
+		return lan.UpdateNetwork(ctx, hal.NetworkFieldAddress, func(cfg *hal.IPConfig) error {
+			copy(cfg.Mask[:], data)
+			return nil
+		})
+
+	case types.LanConfigParamSelector_DefaultGatewayIP:
+		return lan.UpdateNetwork(ctx, hal.NetworkFieldAddress, func(cfg *hal.IPConfig) error {
+			copy(cfg.Gateway[:], data)
+			return nil
+		})
+
+	case types.LanConfigParamSelector_IPSource:
+		// Only static and DHCP are modelled; BIOS-assigned and "other"
+		// sources cannot be selected.
+		var dhcp bool
+		switch types.LanIPAddressSource(data[0] & 0x0f) {
+		case types.IPAddressSourceStatic:
+		case types.IPAddressSourceDHCP:
+			dhcp = true
+		default:
+			return bmc.ErrLANParamInvalid
+		}
+		return lan.UpdateNetwork(ctx, hal.NetworkFieldAddress, func(cfg *hal.IPConfig) error {
+			cfg.DHCP = dhcp
+			return nil
+		})
+
+	case types.LanConfigParamSelector_MAC:
+		return lan.UpdateNetwork(ctx, hal.NetworkFieldMAC, func(cfg *hal.IPConfig) error {
+			copy(cfg.MAC[:], data)
+			return nil
+		})
+
+	case types.LanConfigParamSelector_VLANID:
+		vlan := &types.LanConfigParam_VLANID{}
+		if err := vlan.Unpack(data); err != nil {
+			return err
+		}
+		if vlan.Enabled && (vlan.ID == 0 || vlan.ID > 4094) {
+			return bmc.ErrLANParamInvalid
+		}
+		return lan.UpdateNetwork(ctx, hal.NetworkFieldVLAN, func(cfg *hal.IPConfig) error {
+			cfg.VLANEnabled = vlan.Enabled
+			cfg.VLANID = vlan.ID
+			return nil
+		})
+
+	case types.LanConfigParamSelector_VLANPriority:
+		if data[0] > 7 {
+			return bmc.ErrLANParamInvalid
+		}
+		return lan.UpdateNetwork(ctx, hal.NetworkFieldVLAN, func(cfg *hal.IPConfig) error {
+			cfg.VLANPriority = data[0]
+			return nil
+		})
+
+	case types.LanConfigParamSelector_IPv6Enables:
+		mode := types.LanIPv6EnableMode(data[0])
+		if mode > types.LanIPv6EnableMode_IPv4AndIPv6 {
+			return bmc.ErrLANParamInvalid
+		}
+		return lan.UpdateNetwork(ctx, hal.NetworkFieldIPv6, func(cfg *hal.IPConfig) error {
+			cfg.IPv6Mode = mode
+			return nil
+		})
+
+	case types.LanConfigParamSelector_IPv6StaticAddress:
+		addr := &types.LanConfigParam_IPv6StaticAddress{}
+		if err := addr.Unpack(data); err != nil {
+			return err
+		}
+		if addr.SetSelector >= bmc.DefaultIPv6StaticAddresses {
+			return bmc.ErrLANParamOutOfRange
+		}
+		if addr.Source != types.LanIPv6StaticAddressSource_Static || addr.PrefixLength > 128 {
+			return bmc.ErrLANParamInvalid
+		}
+		return lan.UpdateNetwork(ctx, hal.NetworkFieldIPv6, func(cfg *hal.IPConfig) error {
+			for len(cfg.IPv6Static) <= int(addr.SetSelector) {
+				cfg.IPv6Static = append(cfg.IPv6Static, hal.IPv6Address{})
+			}
+			static := &cfg.IPv6Static[addr.SetSelector]
+			static.Enabled = addr.Enabled
+			copy(static.IP[:], addr.IPv6)
+			static.PrefixLength = addr.PrefixLength
+			return nil
+		})
+
+	case types.LanConfigParamSelector_CipherSuitesPrivLevel:
+		levels := &types.LanConfigParam_CipherSuitesPrivLevel{}
+		if err := levels.Unpack(data); err != nil {
+			return err
+		}
+		var set [bmc.MaxCipherSuiteEntries]bmc.PrivilegeLevel
+		for i, level := range levels.PrivLevels {
+			set[i] = bmc.PrivilegeLevel(level)
+		}
+		return lan.SetCipherSuitePrivileges(set, len(hctx.BMC.ResolvedCipherSuites()))
+
+	case types.LanConfigParamSelector_CommunityString,
+		types.LanConfigParamSelector_AlertDestinationType,
+		types.LanConfigParamSelector_AlertDestinationAddress:
+		return setLanAlertParam(hctx, param, data)
+
+	case types.LanConfigParamSelector_AuthTypeSupport,
+		types.LanConfigParamSelector_PrimaryRMCPPort,
+		types.LanConfigParamSelector_AlertDestinationsCount,
+		types.LanConfigParamSelector_CipherSuitesSupport,
+		types.LanConfigParamSelector_CipherSuitesID,
+		types.LanConfigParamSelector_IPv6Support,
+		types.LanConfigParamSelector_IPv6Status:
+		return bmc.ErrLANParamReadOnly
+
+	default:
+		return bmc.ErrLANParamNotSupported
+	}
+}
+
+// setLanAlertParam writes the community string or one field group of an
+// alert destination. Type and address are separate parameters, so each
+// keeps the other's fields of the destination.
+func setLanAlertParam(hctx *HandlerContext, param types.LanConfigParamSelector, data []byte) error {
+	alerts := hctx.BMC.Alerts
+	if alerts == nil {
+		return bmc.ErrLANParamNotSupported
+	}
+
+	if param == types.LanConfigParamSelector_CommunityString {
+		var community [18]byte
+		copy(community[:], data)
+		alerts.SetCommunity(community)
+		return nil
+	}
+
+	set := data[0] & 0x0f
+	dest, err := alerts.Destination(set)
+	if err != nil {
+		return err
+	}
+	if param == types.LanConfigParamSelector_AlertDestinationType {
+		typ := &types.LanConfigParam_AlertDestinationType{}
+		if err := typ.Unpack(data); err != nil {
+			return err
+		}
+		dest.Acknowledged = typ.AlertAcknowledged
+		dest.Type = typ.DestinationType
+		dest.Timeout = typ.AlertAcknowledgeTimeout
+		dest.Retries = typ.Retries
+		return alerts.SetDestination(set, dest)
+	}
+
+	if data[1]&0xf0 != 0 {
+		// IPv6 alert destinations are not supported (param #50).
+		return bmc.ErrLANParamInvalid
+	}
+	addr := &types.LanConfigParam_AlertDestinationAddress{}
+	if err := addr.Unpack(data); err != nil {
+		return err
+	}
+	dest.UseBackupGateway = addr.UseBackupGateway
+	copy(dest.IP[:], addr.IPv4)
+	copy(dest.MAC[:], addr.MAC)
+	return alerts.SetDestination(set, dest)
+}
+
 // lanParamData returns the raw data bytes for one LAN configuration parameter.
 // Its default arm is the single authority on which selectors are supported.
-func lanParamData(ctx context.Context, hctx *HandlerContext, param types.LanConfigParamSelector) ([]byte, types.CompletionCode) {
+func lanParamData(ctx context.Context, hctx *HandlerContext, param types.LanConfigParamSelector, set uint8) ([]byte, types.CompletionCode) {
 	switch param {
 	case types.LanConfigParamSelector_SetInProgress:
-		// Report "set complete": the reference server has no in-progress LAN
-		// configuration transaction.
-		return []byte{byte(types.SetInProgress_SetComplete)}, types.CodeOK
+		// "Set in progress" while Set LAN Configuration Parameters stages
+		// network interface writes, "set complete" otherwise.
+		state := types.SetInProgress_SetComplete
+		if lan := hctx.BMC.LANConfig; lan != nil && lan.SetInProgress() {
+			state = types.SetInProgress_SetInProgress
+		}
+		return []byte{byte(state)}, types.CodeOK
 
 	case types.LanConfigParamSelector_AuthTypeSupport:
 		// Read-only bitmask (spec Table 23-4 param #1). Advertise the same v1.5
@@ -132,28 +436,40 @@ func lanParamData(ctx context.Context, hctx *HandlerContext, param types.LanConf
 		types.LanConfigParamSelector_IPSource,
 		types.LanConfigParamSelector_MAC,
 		types.LanConfigParamSelector_SubnetMask,
-		types.LanConfigParamSelector_DefaultGatewayIP:
-		return lanAddressParamData(ctx, hctx, param)
+		types.LanConfigParamSelector_DefaultGatewayIP,
+		types.LanConfigParamSelector_VLANID,
+		types.LanConfigParamSelector_VLANPriority,
+		types.LanConfigParamSelector_IPv6Support,
+		types.LanConfigParamSelector_IPv6Enables,
+		types.LanConfigParamSelector_IPv6Status,
+		types.LanConfigParamSelector_IPv6StaticAddress:
+		return lanAddressParamData(ctx, hctx, param, set)
+
+	case types.LanConfigParamSelector_CipherSuitesSupport,
+		types.LanConfigParamSelector_CipherSuitesID,
+		types.LanConfigParamSelector_CipherSuitesPrivLevel:
+		return lanCipherParamData(hctx, param)
+
+	case types.LanConfigParamSelector_CommunityString,
+		types.LanConfigParamSelector_AlertDestinationsCount,
+		types.LanConfigParamSelector_AlertDestinationType,
+		types.LanConfigParamSelector_AlertDestinationAddress:
+		return lanAlertParamData(hctx, param, set)
 
 	default:
 		return nil, types.CodeParameterNotSupported
 	}
 }
 
-// lanAddressParamData answers the address-family LAN parameters from the
+// lanAddressParamData answers the network interface LAN parameters from the
 // NetworkHAL configuration. The raw bytes it emits match what the client's
 // GetLanConfigParamFor decoder expects (spec Table 23-4): 4 octets for the IPv4
 // address, subnet mask and default gateway; 6 octets for the MAC; a single
 // source byte for the IP address source.
-func lanAddressParamData(ctx context.Context, hctx *HandlerContext, param types.LanConfigParamSelector) ([]byte, types.CompletionCode) {
-	network := hctx.BMC.HAL().Network()
-	if network == nil {
-		return nil, types.CodeNotSupported
-	}
-
-	cfg, err := network.GetConfig(ctx)
+func lanAddressParamData(ctx context.Context, hctx *HandlerContext, param types.LanConfigParamSelector, set uint8) ([]byte, types.CompletionCode) {
+	cfg, err := lanNetwork(ctx, hctx)
 	if err != nil {
-		return nil, codeFromHalErr(err)
+		return nil, lanCommandCC(err)
 	}
 
 	switch param {
@@ -176,12 +492,144 @@ func lanAddressParamData(ctx context.Context, hctx *HandlerContext, param types.
 	case types.LanConfigParamSelector_DefaultGatewayIP:
 		return cfg.Gateway[:], types.CodeOK
 
+	case types.LanConfigParamSelector_VLANID:
+		return (&types.LanConfigParam_VLANID{Enabled: cfg.VLANEnabled, ID: cfg.VLANID}).Pack(), types.CodeOK
+
+	case types.LanConfigParamSelector_VLANPriority:
+		return (&types.LanConfigParam_VLANPriority{Priority: cfg.VLANPriority}).Pack(), types.CodeOK
+
+	case types.LanConfigParamSelector_IPv6Support:
+		// IPv6 alert destinations are not supported: PET traps go to the
+		// IPv4 destinations only.
+		configurable := cfg.Fixed&hal.NetworkFieldIPv6 == 0
+		return (&types.LanConfigParam_IPv6Support{
+			CanUseBothIPv4AndIPv6: configurable || cfg.IPv6Mode == types.LanIPv6EnableMode_IPv4AndIPv6,
+			CanUseIPv6Only:        configurable || cfg.IPv6Mode == types.LanIPv6EnableMode_IPv6Only,
+		}).Pack(), types.CodeOK
+
+	case types.LanConfigParamSelector_IPv6Enables:
+		return (&types.LanConfigParam_IPv6Enables{EnableMode: cfg.IPv6Mode}).Pack(), types.CodeOK
+
+	case types.LanConfigParamSelector_IPv6Status:
+		// Static addresses only; SLAAC and DHCPv6 are not modelled.
+		return (&types.LanConfigParam_IPv6Status{StaticAddressMax: bmc.DefaultIPv6StaticAddresses}).Pack(), types.CodeOK
+
+	case types.LanConfigParamSelector_IPv6StaticAddress:
+		if set >= bmc.DefaultIPv6StaticAddresses {
+			return nil, types.CodeParameterOutOfRange
+		}
+		param := &types.LanConfigParam_IPv6StaticAddress{
+			SetSelector: set,
+			Source:      types.LanIPv6StaticAddressSource_Static,
+			IPv6:        net.IP(make([]byte, net.IPv6len)),
+			Status:      types.LanIPv6AddressStatus_Disabled,
+		}
+		if int(set) < len(cfg.IPv6Static) {
+			addr := cfg.IPv6Static[set]
+			param.Enabled = addr.Enabled
+			param.IPv6 = net.IP(addr.IP[:])
+			param.PrefixLength = addr.PrefixLength
+			if addr.Enabled && cfg.IPv6Mode != types.LanIPv6EnableMode_IPv6Disabled {
+				param.Status = types.LanIPv6AddressStatus_Active
+			}
+		}
+		return param.Pack(), types.CodeOK
+
 	default:
 		// Unreachable: the caller only routes the parameters handled above.
 		return nil, types.CodeParameterNotSupported
 	}
 }
 
+// lanNetwork returns the network interface configuration, including any
+// settings staged by a set in progress.
+func lanNetwork(ctx context.Context, hctx *HandlerContext) (hal.IPConfig, error) {
+	if lan := hctx.BMC.LANConfig; lan != nil {
+		return lan.Network(ctx)
+	}
+	network := hctx.BMC.HAL().Network()
+	if network == nil {
+		return hal.IPConfig{}, bmc.ErrLANNoNetwork
+	}
+	cfg, err := network.GetConfig(ctx)
+	if err != nil {
+		return hal.IPConfig{}, err
+	}
+	return *cfg, nil
+}
+
+// lanCipherParamData answers the cipher suite parameters (#22-#24) from the
+// BMC's configured cipher suites, in the order Get Channel Cipher Suites
+// lists them.
+func lanCipherParamData(hctx *HandlerContext, param types.LanConfigParamSelector) ([]byte, types.CompletionCode) {
+	suites := hctx.BMC.ResolvedCipherSuites()
+	if len(suites) > bmc.MaxCipherSuiteEntries {
+		suites = suites[:bmc.MaxCipherSuiteEntries]
+	}
+
+	switch param {
+	case types.LanConfigParamSelector_CipherSuitesSupport:
+		return (&types.LanConfigParam_CipherSuitesSupport{Count: uint8(len(suites))}).Pack(), types.CodeOK
+
+	case types.LanConfigParamSelector_CipherSuitesID:
+		ids := &types.LanConfigParam_CipherSuitesID{}
+		for i, id := range suites {
+			ids.IDs[i] = id
+		}
+		return ids.Pack()[:1+len(suites)], types.CodeOK
+
+	default:
+		levels := &types.LanConfigParam_CipherSuitesPrivLevel{}
+		if lan := hctx.BMC.LANConfig; lan != nil {
+			for i, level := range lan.CipherSuitePrivileges() {
+				if i < len(suites) {
+					levels.PrivLevels[i] = types.PrivilegeLevel(level)
+				}
+			}
+		}
+		return levels.Pack(), types.CodeOK
+	}
+}
+
+// lanAlertParamData answers the LAN alerting parameters from the BMC's alert
+// store. Destination 0 is the volatile destination Alert Immediate uses.
+func lanAlertParamData(hctx *HandlerContext, param types.LanConfigParamSelector, set uint8) ([]byte, types.CompletionCode) {
+	alerts := hctx.BMC.Alerts
+	if alerts == nil {
+		return nil, types.CodeParameterNotSupported
+	}
+
+	switch param {
+	case types.LanConfigParamSelector_CommunityString:
+		community := alerts.Community()
+		return community[:], types.CodeOK
+
+	case types.LanConfigParamSelector_AlertDestinationsCount:
+		return (&types.LanConfigParam_AlertDestinationsCount{Count: alerts.DestinationCount()}).Pack(), types.CodeOK
+	}
+
+	set &= 0x0f
+	dest, err := alerts.Destination(set)
+	if err != nil {
+		return nil, types.CodeParameterOutOfRange
+	}
+	if param == types.LanConfigParamSelector_AlertDestinationType {
+		return (&types.LanConfigParam_AlertDestinationType{
+			SetSelector:             set,
+			AlertAcknowledged:       dest.Acknowledged,
+			DestinationType:         dest.Type,
+			AlertAcknowledgeTimeout: dest.Timeout,
+			Retries:                 dest.Retries,
+		}).Pack(), types.CodeOK
+	}
+	return (&types.LanConfigParam_AlertDestinationAddress{
+		SetSelector:      set,
+		UseBackupGateway: dest.UseBackupGateway,
+		IPv4:             net.IP(dest.IP[:]),
+		MAC:              net.HardwareAddr(dest.MAC[:]),
+	}).Pack(), types.CodeOK
+}
+
 // lanChannelValid resolves the request's channel nibble (0x0E means "this
 // channel") and reports whether it names a configured LAN channel. Non-LAN and
 // unknown channels are rejected so the command never describes the wrong NIC.
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"net"
 	"testing"
 
 	"github.com/bougou/go-ipmi/pkg/bmc"
@@ -218,8 +219,8 @@ func TestHandleGetLanConfigParamErrors(t *testing.T) {
 	})
 
 	t.Run("unsupported-selector", func(t *testing.T) {
-		// Community string (param 16) is not implemented by the reference server.
-		_, cc := getLanParam(t, b, types.LanConfigParamSelector_CommunityString)
+		// BMC-generated ARP control (param 10) is not implemented by the reference server.
+		_, cc := getLanParam(t, b, types.LanConfigParamSelector_ARPControl)
 		if cc != types.CodeParameterNotSupported {
 			t.Fatalf("completion code = 0x%02x, want parameter not supported", uint8(cc))
 		}
@@ -229,7 +230,7 @@ func TestHandleGetLanConfigParamErrors(t *testing.T) {
 		// Revision-only must validate the selector first, so an unsupported one
 		// returns parameter-not-supported instead of a spurious success.
 		_, cc, err := handleGetLanConfigParam(context.Background(), hctx,
-			[]byte{0x81, byte(types.LanConfigParamSelector_CommunityString), 0x00, 0x00})
+			[]byte{0x81, byte(types.LanConfigParamSelector_ARPControl), 0x00, 0x00})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -339,3 +340,256 @@ func TestHandleGetLanConfigParamPrimaryRMCPPort(t *testing.T) {
 		}
 	})
 }
+
+// TestHandleGetLanConfigParamAlerting proves the LAN alerting parameters
+// (#16-#19) report the BMC's alert destinations, decoded the way the client
+// decodes them.
+func TestHandleGetLanConfigParamAlerting(t *testing.T) {
+	b := newTestBMCWithNetwork(t, testIPConfig)
+	hctx := &HandlerContext{BMC: b}
+	if err := b.Alerts.SetDestination(2, bmc.LANAlertDestination{
+		Acknowledged: true, Timeout: 3, Retries: 2,
+		IP: [4]byte{192, 168, 1, 9}, MAC: [6]byte{0x52, 0x54, 0x00, 0xaa, 0xbb, 0xcc},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	community := &types.LanConfigParam_CommunityString{}
+	resp, cc := getLanParam(t, b, types.LanConfigParamSelector_CommunityString)
+	unpackParamData(t, resp, cc, community)
+	if got := community.CommunityString.String(); got != bmc.DefaultCommunityString {
+		t.Errorf("community string = %q, want %q", got, bmc.DefaultCommunityString)
+	}
+
+	count := &types.LanConfigParam_AlertDestinationsCount{}
+	resp, cc = getLanParam(t, b, types.LanConfigParamSelector_AlertDestinationsCount)
+	unpackParamData(t, resp, cc, count)
+	if count.Count != bmc.DefaultLANAlertDestinations {
+		t.Errorf("destination count = %d, want %d", count.Count, bmc.DefaultLANAlertDestinations)
+	}
+
+	resp, cc, err := handleGetLanConfigParam(context.Background(), hctx, []byte{0x01, byte(types.LanConfigParamSelector_AlertDestinationType), 0x02, 0x00})
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := &types.LanConfigParam_AlertDestinationType{}
+	unpackParamData(t, resp, cc, typ)
+	if typ.SetSelector != 2 || !typ.AlertAcknowledged || typ.DestinationType != bmc.LANAlertDestinationPET ||
+		typ.AlertAcknowledgeTimeout != 3 || typ.Retries != 2 {
+		t.Errorf("destination type: %+v", typ)
+	}
+
+	resp, cc, err = handleGetLanConfigParam(context.Background(), hctx, []byte{0x01, byte(types.LanConfigParamSelector_AlertDestinationAddress), 0x02, 0x00})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := &types.LanConfigParam_AlertDestinationAddress{}
+	unpackParamData(t, resp, cc, addr)
+	if addr.SetSelector != 2 || addr.IsIPv6 || addr.IPv4.String() != "192.168.1.9" || addr.MAC.String() != "52:54:00:aa:bb:cc" {
+		t.Errorf("destination address: %+v", addr)
+	}
+
+	// Destinations past the count are out of range.
+	_, cc, err = handleGetLanConfigParam(context.Background(), hctx, []byte{0x01, byte(types.LanConfigParamSelector_AlertDestinationAddress), bmc.DefaultLANAlertDestinations + 1, 0x00})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc != types.CodeParameterOutOfRange {
+		t.Errorf("out of range destination: completion code = 0x%02x, want parameter out of range", uint8(cc))
+	}
+}
+
+// setLanParamReq dispatches Set LAN Configuration Parameters for one selector on
+// channel 1.
+func setLanParamReq(t *testing.T, b *bmc.BMC, param types.LanConfigParamSelector, data ...byte) types.CompletionCode {
+	t.Helper()
+	hctx := &HandlerContext{BMC: b}
+	_, cc, err := handleSetLanConfigParam(context.Background(), hctx, append([]byte{0x01, byte(param)}, data...))
+	if err != nil {
+		t.Fatalf("handleSetLanConfigParam(%s): unexpected error: %v", param, err)
+	}
+	return cc
+}
+
+// TestHandleSetLanConfigParamStaged proves writes made under Set In Progress
+// read back at once but reach the NetworkHAL only on commit.
+func TestHandleSetLanConfigParamStaged(t *testing.T) {
+	b := newTestBMCWithNetwork(t, testIPConfig)
+	network := b.HAL().Network().(*mock.Network)
+
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_SetInProgress, byte(types.SetInProgress_SetInProgress)); cc != types.CodeOK {
+		t.Fatalf("begin set: completion code = 0x%02x", uint8(cc))
+	}
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_SetInProgress, byte(types.SetInProgress_SetInProgress)); cc != types.CodeParamConfigSetInProgressConflict {
+		t.Fatalf("second set in progress: completion code = 0x%02x, want 81h", uint8(cc))
+	}
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_IP, 10, 0, 0, 7); cc != types.CodeOK {
+		t.Fatalf("set IP: completion code = 0x%02x", uint8(cc))
+	}
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_IPSource, byte(types.IPAddressSourceDHCP)); cc != types.CodeOK {
+		t.Fatalf("set IP source: completion code = 0x%02x", uint8(cc))
+	}
+
+	ip := &types.LanConfigParam_IP{}
+	resp, cc := getLanParam(t, b, types.LanConfigParamSelector_IP)
+	unpackParamData(t, resp, cc, ip)
+	if ip.IP.String() != "10.0.0.7" {
+		t.Errorf("staged IP = %s, want 10.0.0.7", ip.IP)
+	}
+	progress := &types.LanConfigParam_SetInProgress{}
+	resp, cc = getLanParam(t, b, types.LanConfigParamSelector_SetInProgress)
+	unpackParamData(t, resp, cc, progress)
+	if progress.Value != types.SetInProgress_SetInProgress {
+		t.Errorf("set in progress = %d, want set in progress", progress.Value)
+	}
+	if network.Cfg.IP != testIPConfig.IP || network.Cfg.DHCP {
+		t.Fatalf("staged settings reached the HAL before commit: %+v", network.Cfg)
+	}
+
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_SetInProgress, byte(types.SetInProgress_SetComplete)); cc != types.CodeOK {
+		t.Fatalf("set complete: completion code = 0x%02x", uint8(cc))
+	}
+	if network.Cfg.IP != [4]byte{10, 0, 0, 7} || !network.Cfg.DHCP {
+		t.Errorf("committed settings: %+v", network.Cfg)
+	}
+	if b.LANConfig.SetInProgress() {
+		t.Error("set complete left the set in progress")
+	}
+
+	// Outside a set in progress a write goes straight to the HAL.
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_DefaultGatewayIP, 10, 0, 0, 1); cc != types.CodeOK {
+		t.Fatalf("set gateway: completion code = 0x%02x", uint8(cc))
+	}
+	if network.Cfg.Gateway != [4]byte{10, 0, 0, 1} {
+		t.Errorf("gateway = %v, want 10.0.0.1", network.Cfg.Gateway)
+	}
+}
+
+func TestHandleSetLanConfigParamErrors(t *testing.T) {
+	cfg := testIPConfig
+	cfg.Fixed = hal.NetworkFieldMAC
+	b := newTestBMCWithNetwork(t, cfg)
+
+	tests := []struct {
+		name  string
+		param types.LanConfigParamSelector
+		data  []byte
+		want  types.CompletionCode
+	}{
+		{"fixed MAC", types.LanConfigParamSelector_MAC, []byte{0x52, 0x54, 0, 0, 0, 1}, types.CodeParamConfigSetReadOnly},
+		{"read-only auth types", types.LanConfigParamSelector_AuthTypeSupport, []byte{0x04}, types.CodeParamConfigSetReadOnly},
+		{"read-only cipher suites", types.LanConfigParamSelector_CipherSuitesID, []byte{0, 3}, types.CodeParamConfigSetReadOnly},
+		{"unsupported parameter", types.LanConfigParamSelector_ARPControl, []byte{0}, types.CodeParameterNotSupported},
+		{"short IP", types.LanConfigParamSelector_IP, []byte{10, 0}, types.CodeRequestDataLengthInvalid},
+		{"BIOS IP source", types.LanConfigParamSelector_IPSource, []byte{byte(types.IPAddressSourceBIOS)}, types.CodeRequestDataFieldInvalid},
+		{"VLAN 0 enabled", types.LanConfigParamSelector_VLANID, []byte{0x00, 0x80}, types.CodeRequestDataFieldInvalid},
+		{"reserved set in progress", types.LanConfigParamSelector_SetInProgress, []byte{0x03}, types.CodeRequestDataFieldInvalid},
+		{"IPv6 address past the table", types.LanConfigParamSelector_IPv6StaticAddress, (&types.LanConfigParam_IPv6StaticAddress{SetSelector: bmc.DefaultIPv6StaticAddresses, IPv6: make([]byte, 16)}).Pack(), types.CodeParameterOutOfRange},
+		{"alert destination past the count", types.LanConfigParamSelector_AlertDestinationType, []byte{bmc.DefaultLANAlertDestinations + 1, 0, 0, 0}, types.CodeParameterOutOfRange},
+		{"cipher privilege below callback", types.LanConfigParamSelector_CipherSuitesPrivLevel, make([]byte, 9), types.CodeRequestDataFieldInvalid},
+	}
+	for _, tc := range tests {
+		if cc := setLanParamReq(t, b, tc.param, tc.data...); cc != tc.want {
+			t.Errorf("%s: completion code = 0x%02x, want 0x%02x", tc.name, uint8(cc), uint8(tc.want))
+		}
+	}
+
+	// A non-LAN channel is rejected before the parameter is looked at.
+	_, cc, _ := handleSetLanConfigParam(context.Background(), &HandlerContext{BMC: b}, []byte{0x0F, byte(types.LanConfigParamSelector_IP), 10, 0, 0, 7})
+	if cc != types.CodeRequestDataFieldInvalid {
+		t.Errorf("system interface channel: completion code = 0x%02x, want field invalid", uint8(cc))
+	}
+}
+
+func TestHandleSetLanConfigParamVLANAndIPv6(t *testing.T) {
+	b := newTestBMCWithNetwork(t, testIPConfig)
+
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_VLANID, (&types.LanConfigParam_VLANID{Enabled: true, ID: 300}).Pack()...); cc != types.CodeOK {
+		t.Fatalf("set VLAN ID: completion code = 0x%02x", uint8(cc))
+	}
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_VLANPriority, 5); cc != types.CodeOK {
+		t.Fatalf("set VLAN priority: completion code = 0x%02x", uint8(cc))
+	}
+	vlan := &types.LanConfigParam_VLANID{}
+	resp, cc := getLanParam(t, b, types.LanConfigParamSelector_VLANID)
+	unpackParamData(t, resp, cc, vlan)
+	if !vlan.Enabled || vlan.ID != 300 {
+		t.Errorf("VLAN ID: %+v", vlan)
+	}
+
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_IPv6Enables, byte(types.LanIPv6EnableMode_IPv4AndIPv6)); cc != types.CodeOK {
+		t.Fatalf("set IPv6 enables: completion code = 0x%02x", uint8(cc))
+	}
+	static := &types.LanConfigParam_IPv6StaticAddress{
+		SetSelector:  1,
+		Enabled:      true,
+		IPv6:         net.ParseIP("fd00::50"),
+		PrefixLength: 64,
+	}
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_IPv6StaticAddress, static.Pack()...); cc != types.CodeOK {
+		t.Fatalf("set IPv6 address: completion code = 0x%02x", uint8(cc))
+	}
+	resp, cc, err := handleGetLanConfigParam(context.Background(), &HandlerContext{BMC: b}, []byte{0x01, byte(types.LanConfigParamSelector_IPv6StaticAddress), 0x01, 0x00})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &types.LanConfigParam_IPv6StaticAddress{}
+	unpackParamData(t, resp, cc, got)
+	if !got.Enabled || got.IPv6.String() != "fd00::50" || got.PrefixLength != 64 || got.Status != types.LanIPv6AddressStatus_Active {
+		t.Errorf("IPv6 address: %+v", got)
+	}
+	if cfg := b.HAL().Network().(*mock.Network).Cfg; len(cfg.IPv6Static) != 2 || cfg.IPv6Mode != types.LanIPv6EnableMode_IPv4AndIPv6 {
+		t.Errorf("HAL IPv6 settings: %+v", cfg)
+	}
+}
+
+func TestHandleSetLanConfigParamAlertingAndCiphers(t *testing.T) {
+	b := newTestBMCWithNetwork(t, testIPConfig)
+
+	var community types.CommunityString
+	copy(community[:], "provision")
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_CommunityString, community[:]...); cc != types.CodeOK {
+		t.Fatalf("set community: completion code = 0x%02x", uint8(cc))
+	}
+	if got := b.Alerts.Community(); string(got[:9]) != "provision" {
+		t.Errorf("community = %q", got)
+	}
+
+	typ := &types.LanConfigParam_AlertDestinationType{SetSelector: 1, AlertAcknowledged: true, AlertAcknowledgeTimeout: 4, Retries: 3}
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_AlertDestinationType, typ.Pack()...); cc != types.CodeOK {
+		t.Fatalf("set destination type: completion code = 0x%02x", uint8(cc))
+	}
+	addr := &types.LanConfigParam_AlertDestinationAddress{SetSelector: 1, IPv4: net.IPv4(10, 0, 0, 9), MAC: net.HardwareAddr{0x52, 0x54, 0, 0, 0, 9}}
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_AlertDestinationAddress, addr.Pack()...); cc != types.CodeOK {
+		t.Fatalf("set destination address: completion code = 0x%02x", uint8(cc))
+	}
+	// The address write keeps the type fields written before it.
+	dest, err := b.Alerts.Destination(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dest.Acknowledged || dest.Timeout != 4 || dest.Retries != 3 || dest.IP != [4]byte{10, 0, 0, 9} {
+		t.Errorf("destination 1: %+v", dest)
+	}
+
+	count := len(b.ResolvedCipherSuites())
+	levels := &types.LanConfigParam_CipherSuitesPrivLevel{}
+	for i := 0; i < count; i++ {
+		levels.PrivLevels[i] = types.PrivilegeLevelUser
+	}
+	if cc := setLanParamReq(t, b, types.LanConfigParamSelector_CipherSuitesPrivLevel, levels.Pack()...); cc != types.CodeOK {
+		t.Fatalf("set cipher suite privileges: completion code = 0x%02x", uint8(cc))
+	}
+	got := &types.LanConfigParam_CipherSuitesPrivLevel{}
+	resp, cc := getLanParam(t, b, types.LanConfigParamSelector_CipherSuitesPrivLevel)
+	unpackParamData(t, resp, cc, got)
+	if *got != *levels {
+		t.Errorf("cipher suite privileges = %v, want %v", got.PrivLevels, levels.PrivLevels)
+	}
+	support := &types.LanConfigParam_CipherSuitesSupport{}
+	resp, cc = getLanParam(t, b, types.LanConfigParamSelector_CipherSuitesSupport)
+	unpackParamData(t, resp, cc, support)
+	if int(support.Count) != count {
+		t.Errorf("cipher suite count = %d, want %d", support.Count, count)
+	}
+}
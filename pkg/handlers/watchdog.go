@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/app"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// BMC Watchdog Timer command IDs (App NetFn, v2.0§27).
+const (
+	CmdResetWatchdogTimer uint8 = 0x22
+	CmdSetWatchdogTimer   uint8 = 0x24
+)
+
+// RegisterWatchdogHandlers adds the BMC Watchdog Timer command handlers to r.
+func RegisterWatchdogHandlers(r *Registry) {
+	r.RegisterFunc(types.CommandResetWatchdogTimer, handleResetWatchdogTimer)
+	r.RegisterFunc(types.CommandSetWatchdogTimer, handleSetWatchdogTimer)
+	r.RegisterFunc(types.CommandGetWatchdogTimer, handleGetWatchdogTimer)
+}
+
+// watchdogCommandCC maps watchdog errors to the completion codes of the
+// BMC Watchdog Timer commands (v2.0§27).
+func watchdogCommandCC(err error) types.CompletionCode {
+	switch {
+	case err == nil:
+		return types.CodeOK
+	case errors.Is(err, bmc.ErrWatchdogUninitialized):
+		return types.CodeResetWatchdogTimerUninitialized
+	case errors.Is(err, bmc.ErrWatchdogInvalidConfig):
+		return types.CodeRequestDataFieldInvalid
+	default:
+		return codeFromErr(err)
+	}
+}
+
+// watchdogDevice returns the BMC's watchdog timer, or nil.
+func watchdogDevice(hctx *HandlerContext) *bmc.Watchdog {
+	if hctx == nil || hctx.BMC == nil {
+		return nil
+	}
+	return hctx.BMC.Watchdog
+}
+
+// handleResetWatchdogTimer implements Reset Watchdog Timer (App 0x22,
+// v2.0§27.5).
+func handleResetWatchdogTimer(_ context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	wd := watchdogDevice(hctx)
+	if wd == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	return nil, watchdogCommandCC(wd.Reset()), nil
+}
+
+// handleSetWatchdogTimer implements Set Watchdog Timer (App 0x24,
+// v2.0§27.6).
+func handleSetWatchdogTimer(_ context.Context, hctx *HandlerContext, req []byte) ([]byte, types.CompletionCode, error) {
+	wd := watchdogDevice(hctx)
+	if wd == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	var typed app.SetWatchdogTimerRequest
+	if err := typed.Unpack(req); err != nil {
+		return nil, types.CodeRequestDataTruncated, nil
+	}
+	// Reserved bits in bytes 1, 2 and 4 must be zero.
+	if req[0]&0x38 != 0 || req[1]&0x88 != 0 || req[3]&^0x3e != 0 {
+		return nil, types.CodeRequestDataFieldInvalid, nil
+	}
+	cfg := bmc.WatchdogConfig{
+		DontLog:             typed.DontLog,
+		TimerUse:            uint8(typed.TimerUse),
+		PreTimeoutInterrupt: uint8(typed.PreTimeoutInterrupt),
+		TimeoutAction:       uint8(typed.TimeoutAction),
+		PreTimeoutInterval:  typed.PreTimeoutIntervalSec,
+		InitialCountdown:    typed.InitialCountdown,
+	}
+	return nil, watchdogCommandCC(wd.Set(cfg, typed.DontStopTimer, typed.ExpirationFlags)), nil
+}
+
+// handleGetWatchdogTimer implements Get Watchdog Timer (App 0x25,
+// v2.0§27.7).
+func handleGetWatchdogTimer(_ context.Context, hctx *HandlerContext, _ []byte) ([]byte, types.CompletionCode, error) {
+	wd := watchdogDevice(hctx)
+	if wd == nil {
+		return nil, types.CodeNotSupported, nil
+	}
+	st := wd.Status()
+	resp := &app.GetWatchdogTimerResponse{
+		DontLog:               st.DontLog,
+		TimerIsStarted:        st.Running,
+		TimerUse:              app.TimerUse(st.TimerUse),
+		PreTimeoutInterrupt:   app.PreTimeoutInterrupt(st.PreTimeoutInterrupt),
+		TimeoutAction:         app.TimeoutAction(st.TimeoutAction),
+		PreTimeoutIntervalSec: st.PreTimeoutInterval,
+		ExpirationFlags:       st.ExpirationFlags,
+		InitialCountdown:      st.InitialCountdown,
+		PresentCountdown:      st.PresentCountdown,
+	}
+	return resp.Pack(), types.CodeOK, nil
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/app"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestHandleWatchdogTimer(t *testing.T) {
+	b := newTestBMCWithMock(mock.New())
+	hctx := &HandlerContext{BMC: b}
+	ctx := context.Background()
+
+	if _, cc, _ := handleResetWatchdogTimer(ctx, hctx, nil); cc != types.CodeResetWatchdogTimerUninitialized {
+		t.Fatalf("reset before set: want 80h, got %v", cc)
+	}
+
+	set := &app.SetWatchdogTimerRequest{
+		TimerUse:              app.TimerUseSMSOS,
+		TimeoutAction:         app.TimeoutActionPowerCycle,
+		PreTimeoutInterrupt:   app.PreTimeoutInterruptMessaging,
+		PreTimeoutIntervalSec: 10,
+		InitialCountdown:      600,
+	}
+	if _, cc, err := handleSetWatchdogTimer(ctx, hctx, set.Pack()); err != nil || cc != types.CodeOK {
+		t.Fatalf("set: cc=%v err=%v", cc, err)
+	}
+	if _, cc, _ := handleResetWatchdogTimer(ctx, hctx, nil); cc != types.CodeOK {
+		t.Fatalf("reset: %v", cc)
+	}
+
+	resp, cc, err := handleGetWatchdogTimer(ctx, hctx, nil)
+	if err != nil || cc != types.CodeOK {
+		t.Fatalf("get: cc=%v err=%v", cc, err)
+	}
+	var got app.GetWatchdogTimerResponse
+	if err := got.Unpack(resp); err != nil {
+		t.Fatal(err)
+	}
+	if !got.TimerIsStarted || got.TimerUse != app.TimerUseSMSOS || got.TimeoutAction != app.TimeoutActionPowerCycle ||
+		got.PreTimeoutInterrupt != app.PreTimeoutInterruptMessaging || got.PreTimeoutIntervalSec != 10 ||
+		got.InitialCountdown != 600 || got.PresentCountdown == 0 || got.PresentCountdown > 600 {
+		t.Fatalf("get response: %+v", got)
+	}
+}
+
+func TestHandleSetWatchdogTimer_Invalid(t *testing.T) {
+	hctx := &HandlerContext{BMC: newTestBMCWithMock(mock.New())}
+	ctx := context.Background()
+
+	if _, cc, _ := handleSetWatchdogTimer(ctx, hctx, []byte{0x04, 0x01}); cc != types.CodeRequestDataTruncated {
+		t.Fatalf("short request: want truncated, got %v", cc)
+	}
+	for name, req := range map[string][]byte{
+		"reserved timer use": {0x00, 0x01, 0x00, 0x00, 0x0a, 0x00},
+		"reserved action":    {0x04, 0x04, 0x00, 0x00, 0x0a, 0x00},
+		"reserved bits":      {0x0c, 0x01, 0x00, 0x00, 0x0a, 0x00},
+	} {
+		if _, cc, _ := handleSetWatchdogTimer(ctx, hctx, req); cc != types.CodeRequestDataFieldInvalid {
+			t.Errorf("%s: want CCh, got %v", name, cc)
+		}
+	}
+}
+
+func TestRegisterAllHandlers_IncludesWatchdog(t *testing.T) {
+	r := NewRegistry()
+	RegisterAllHandlers(r)
+	if _, ok := r.handlers[makeKey(uint8(types.CommandGetWatchdogTimer.NetFn), types.CommandGetWatchdogTimer.ID)]; !ok {
+		t.Fatal("Get Watchdog Timer not registered")
+	}
+	for _, cmd := range []uint8{CmdResetWatchdogTimer, CmdSetWatchdogTimer} {
+		if got := MinimumPrivilege(NetFnAppRequest, cmd); got != bmc.PrivilegeLevelOperator {
+			t.Fatalf("watchdog command %#02x privilege: %v", cmd, got)
+		}
+	}
+}
@@ -18,8 +18,11 @@ func TestWireConstantsMatchCommandTable(t *testing.T) {
 		got  uint8
 		want types.NetFn
 	}{
-		"NetFnAppRequest":     {NetFnAppRequest, types.NetFnAppRequest},
-		"NetFnChassisRequest": {NetFnChassisRequest, types.NetFnChassisRequest},
+		"NetFnAppRequest":            {NetFnAppRequest, types.NetFnAppRequest},
+		"NetFnChassisRequest":        {NetFnChassisRequest, types.NetFnChassisRequest},
+		"NetFnStorageRequest":        {NetFnStorageRequest, types.NetFnStorageRequest},
+		"NetFnSensorEventRequest":    {NetFnSensorEventRequest, types.NetFnSensorEventRequest},
+		"NetFnGroupExtensionRequest": {NetFnGroupExtensionRequest, types.NetFnGroupExtensionRequest},
 	}
 	for name, tc := range netFns {
 		if tc.got != uint8(tc.want) {
@@ -31,13 +34,57 @@ func TestWireConstantsMatchCommandTable(t *testing.T) {
 		got  uint8
 		want types.Command
 	}{
-		"CmdColdReset":                  {CmdColdReset, types.CommandColdReset},
-		"CmdWarmReset":                  {CmdWarmReset, types.CommandWarmReset},
-		"CmdGetChannelCipherSuites":     {CmdGetChannelCipherSuites, types.CommandGetChannelCipherSuites},
-		"CmdChassisControl":             {CmdChassisControl, types.CommandChassisControl},
-		"CmdGetChannelAuthCapabilities": {CmdGetChannelAuthCapabilities, types.CommandGetChannelAuthCapabilities},
-		"CmdGetSessionChallenge":        {CmdGetSessionChallenge, types.CommandGetSessionChallenge},
-		"CmdActivateSession":            {CmdActivateSession, types.CommandActivateSession},
+		"CmdColdReset":                    {CmdColdReset, types.CommandColdReset},
+		"CmdWarmReset":                    {CmdWarmReset, types.CommandWarmReset},
+		"CmdGetChannelCipherSuites":       {CmdGetChannelCipherSuites, types.CommandGetChannelCipherSuites},
+		"CmdSetChannelAccess":             {CmdSetChannelAccess, types.CommandSetChannelAccess},
+		"CmdSetSystemInfoParam":           {CmdSetSystemInfoParam, types.CommandSetSystemInfoParam},
+		"CmdSetCommandEnables":            {CmdSetCommandEnables, types.CommandSetCommandEnables},
+		"CmdSetCommandSubfunctionEnables": {CmdSetCommandSubfunctionEnables, types.CommandSetCommandSubfunctionEnables},
+		"CmdChassisControl":               {CmdChassisControl, types.CommandChassisControl},
+		"CmdSetChassisCapabilities":       {CmdSetChassisCapabilities, types.CommandSetChassisCapabilities},
+		"CmdSetPowerRestorePolicy":        {CmdSetPowerRestorePolicy, types.CommandSetPowerRestorePolicy},
+		"CmdSetFrontPanelEnables":         {CmdSetFrontPanelEnables, types.CommandSetFrontPanelEnables},
+		"CmdSetPowerCycleInterval":        {CmdSetPowerCycleInterval, types.CommandSetPowerCycleInterval},
+		"CmdGetChannelAuthCapabilities":   {CmdGetChannelAuthCapabilities, types.CommandGetChannelAuthCapabilities},
+		"CmdGetSessionChallenge":          {CmdGetSessionChallenge, types.CommandGetSessionChallenge},
+		"CmdActivateSession":              {CmdActivateSession, types.CommandActivateSession},
+		"CmdWriteFRUData":                 {CmdWriteFRUData, types.CommandWriteFRUData},
+		"CmdAddSDR":                       {CmdAddSDR, types.CommandAddSDR},
+		"CmdPartialAddSDR":                {CmdPartialAddSDR, types.CommandPartialAddSDR},
+		"CmdDeleteSDR":                    {CmdDeleteSDR, types.CommandDeleteSDR},
+		"CmdClearSDRRepo":                 {CmdClearSDRRepo, types.CommandClearSDRRepo},
+		"CmdEnterSDRRepoUpdateMode":       {CmdEnterSDRRepoUpdateMode, types.CommandEnterSDRRepoUpdateMode},
+		"CmdExitSDRRepoUpdateMode":        {CmdExitSDRRepoUpdateMode, types.CommandExitSDRRepoUpdateMode},
+		"CmdRunInitializationAgent":       {CmdRunInitializationAgent, types.CommandRunInitializationAgent},
+		"CmdAddSELEntry":                  {CmdAddSELEntry, types.CommandAddSELEntry},
+		"CmdPartialAddSELEntry":           {CmdPartialAddSELEntry, types.CommandPartialAddSELEntry},
+		"CmdDeleteSELEntry":               {CmdDeleteSELEntry, types.CommandDeleteSELEntry},
+		"CmdClearSEL":                     {CmdClearSEL, types.CommandClearSEL},
+		"CmdSetSELTime":                   {CmdSetSELTime, types.CommandSetSELTime},
+		"CmdSetSELTimeUTCOffset":          {CmdSetSELTimeUTCOffset, types.CommandSetSELTimeUTCOffset},
+		"CmdSetSensorHysteresis":          {CmdSetSensorHysteresis, types.CommandSetSensorHysteresis},
+		"CmdSetSensorThresholds":          {CmdSetSensorThresholds, types.CommandSetSensorThresholds},
+		"CmdSetSensorEventEnable":         {CmdSetSensorEventEnable, types.CommandSetSensorEventEnable},
+		"CmdRearmSensorEvents":            {CmdRearmSensorEvents, types.CommandRearmSensorEvents},
+		"CmdResetWatchdogTimer":           {CmdResetWatchdogTimer, types.CommandResetWatchdogTimer},
+		"CmdSetWatchdogTimer":             {CmdSetWatchdogTimer, types.CommandSetWatchdogTimer},
+		"CmdArmPEFPostponeTimer":          {CmdArmPEFPostponeTimer, types.CommandArmPEFPostponeTimer},
+		"CmdSetPEFConfigParam":            {CmdSetPEFConfigParam, types.CommandSetPEFConfigParam},
+		"CmdGetPEFConfigParam":            {CmdGetPEFConfigParam, types.CommandGetPEFConfigParam},
+		"CmdSetLastProcessedEventID":      {CmdSetLastProcessedEventID, types.CommandSetLastProcessedEventId},
+		"CmdGetLastProcessedEventID":      {CmdGetLastProcessedEventID, types.CommandGetLastProcessedEventId},
+		"CmdAlertImmediate":               {CmdAlertImmediate, types.CommandAlertImmediate},
+		"CmdPETAcknowledge":               {CmdPETAcknowledge, types.CommandPETAcknowledge},
+		"CmdSetLanConfigParam":            {CmdSetLanConfigParam, types.CommandSetLanConfigParam},
+		"CmdGetLanConfigParam":            {CmdGetLanConfigParam, types.CommandGetLanConfigParam},
+		"CmdGetDCMICapabilities":          {CmdGetDCMICapabilities, types.CommandGetDCMICapParam},
+		"CmdSetDCMIPowerLimit":            {CmdSetDCMIPowerLimit, types.CommandSetDCMIPowerLimit},
+		"CmdActivateDCMIPowerLimit":       {CmdActivateDCMIPowerLimit, types.CommandActivateDCMIPowerLimit},
+		"CmdSetDCMIAssetTag":              {CmdSetDCMIAssetTag, types.CommandSetDCMIAssetTag},
+		"CmdSetDCMIMgmtControllerID":      {CmdSetDCMIMgmtControllerID, types.CommandSetDCMIMgmtControllerIdentifier},
+		"CmdSetDCMIThermalLimit":          {CmdSetDCMIThermalLimit, types.CommandSetDCMIThermalLimit},
+		"CmdSetDCMIConfigParam":           {CmdSetDCMIConfigParam, types.CommandSetDCMIConfigParam},
 	}
 	for name, tc := range commands {
 		if tc.got != tc.want.ID {
@@ -0,0 +1,180 @@
+// Package ipmb simulates an IPMB segment joining several [bmc.BMC] instances:
+// a BMC and the satellite management controllers of a modular chassis, such
+// as blade controllers or the ME/Node Manager at 2Ch, so that multi-node
+// platforms can be modeled in simulations and tests.
+//
+// Every controller attached to a [Bus] sees it as an IPMB channel of its own,
+// which Send Message writes to (v2.0§6.13). A request on the bus is
+// dispatched through the handler registry of the controller at its responder
+// slave address, and the response is put back on the bus; responses, and
+// requests for a controller's SMS LUN, go to its [bmc.MessageStore]. As on a
+// real segment, a write is acknowledged at once, with a NAK when no
+// controller answers to the address, and the message arrives later.
+//
+// A request carries no session on the bus. One a BMC bridged runs at the
+// privilege of its requester (see [bmc.IPMBOrigin]); any other request may
+// only run the commands that need no privilege.
+package ipmb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/handlers"
+	"github.com/bougou/go-ipmi/pkg/protocol"
+)
+
+// PrimaryChannel is the channel number of the primary IPMB (v2.0§6.3), the
+// one a controller attaches the bus as by default.
+const PrimaryChannel uint8 = 0x00
+
+// smsLUN is the LUN of system software on a controller (v2.0§7.2).
+const smsLUN uint8 = 0x02
+
+var (
+	// ErrAddressInUse is returned by [Bus.Attach] for a slave address already
+	// on the bus.
+	ErrAddressInUse = errors.New("slave address already on the bus")
+	// ErrInvalidAddress is returned by [Bus.Attach] for an address that is
+	// not a 7-bit slave address in the IPMB's 8-bit form.
+	ErrInvalidAddress = errors.New("invalid IPMB slave address")
+	// ErrChannelInUse is returned by [Bus.Attach] when the channel the bus
+	// would be attached as is not an IPMB channel.
+	ErrChannelInUse = errors.New("channel is not an IPMB channel")
+	// ErrInvalidMessage is returned by [Bus.Write] for a message that is not
+	// IPMB-format or fails its checksums.
+	ErrInvalidMessage = errors.New("invalid IPMB message")
+	// ErrNoRegistry is returned by [Bus.Attach] without a handler registry.
+	ErrNoRegistry = errors.New("no handler registry")
+)
+
+// Bus is one simulated IPMB segment. The zero value is not usable; create one
+// with [NewBus].
+type Bus struct {
+	mu    sync.RWMutex
+	nodes map[uint8]*node
+}
+
+// node is a controller attached to the bus.
+type node struct {
+	addr    uint8
+	channel uint8
+	bmc     *bmc.BMC
+	reg     *handlers.Registry
+}
+
+// NewBus returns a bus with no controller attached.
+func NewBus() *Bus {
+	return &Bus{nodes: make(map[uint8]*node)}
+}
+
+// AttachOption configures how a controller is attached by [Bus.Attach].
+type AttachOption func(*node)
+
+// WithChannel attaches the bus as channel instead of [PrimaryChannel].
+func WithChannel(channel uint8) AttachOption {
+	return func(n *node) { n.channel = channel }
+}
+
+// Attach puts b on the bus at slave address addr, dispatching the requests
+// addressed to it through reg. reg is the registry of the frontend serving
+// b, so that a request from the bus meets the same handlers, firewall and
+// middleware (audit, tracing, metrics) as one from a LAN session. The bus
+// becomes one of b's channels, added to its channel table as an
+// always-available IPMB channel unless it is already there, and b's Send
+// Message to that channel is written to the bus.
+func (bus *Bus) Attach(addr uint8, b *bmc.BMC, reg *handlers.Registry, opts ...AttachOption) error {
+	if addr == 0 || addr&0x01 != 0 {
+		return fmt.Errorf("0x%02x: %w", addr, ErrInvalidAddress)
+	}
+	if reg == nil {
+		return ErrNoRegistry
+	}
+	n := &node{addr: addr, channel: PrimaryChannel, bmc: b, reg: reg}
+	for _, o := range opts {
+		o(n)
+	}
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	if _, ok := bus.nodes[addr]; ok {
+		return fmt.Errorf("0x%02x: %w", addr, ErrAddressInUse)
+	}
+	ch, err := b.Channels.Get(n.channel)
+	switch {
+	case errors.Is(err, bmc.ErrChannelNotFound):
+		b.Channels.Set(&bmc.Channel{
+			Number:       n.channel,
+			Medium:       bmc.ChannelMediumIPMBv10,
+			AccessMode:   bmc.ChannelAccessAlways,
+			MaxPrivilege: bmc.PrivilegeLevelAdministrator,
+		})
+	case err != nil:
+		return err
+	case ch.Medium != bmc.ChannelMediumIPMBv10:
+		return fmt.Errorf("channel %d: %w", n.channel, ErrChannelInUse)
+	}
+
+	bus.nodes[addr] = n
+	b.Messages.ConnectIPMB(n.channel, addr, bus.Write)
+	return nil
+}
+
+// Detach takes the controller at addr off the bus. Its IPMB channel stays in
+// its channel table, disconnected.
+func (bus *Bus) Detach(addr uint8) {
+	bus.mu.Lock()
+	n, ok := bus.nodes[addr]
+	delete(bus.nodes, addr)
+	bus.mu.Unlock()
+	if ok {
+		n.bmc.Messages.ConnectIPMB(n.channel, addr, nil)
+	}
+}
+
+// Write puts msg, an IPMB message, on the bus. It fails with an error
+// wrapping [bmc.ErrIPMBNAK] when no controller is at the responder slave
+// address; otherwise the message is delivered asynchronously, after Write
+// returns, and ctx only lends its values to the delivery, the message's
+// [bmc.IPMBOrigin] among them. Write is the [bmc.IPMBWriter] of every
+// attached controller, and lets a test stand in for a controller that is
+// not modeled.
+func (bus *Bus) Write(ctx context.Context, msg []byte) error {
+	if !protocol.ValidMessage(msg) {
+		return ErrInvalidMessage
+	}
+	bus.mu.RLock()
+	n, ok := bus.nodes[msg[0]]
+	bus.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("slave address 0x%02x: %w", msg[0], bmc.ErrIPMBNAK)
+	}
+	msg = append([]byte(nil), msg...)
+	go bus.deliver(context.WithoutCancel(ctx), n, msg)
+	return nil
+}
+
+// deliver hands msg to n: a request is served by its registry, at the
+// privilege of its origin, and answered on the bus, while a response or a
+// request for system software goes to its message store.
+func (bus *Bus) deliver(ctx context.Context, n *node, msg []byte) {
+	lun := msg[1] & 0x03
+	if msg[1]&0x04 != 0 || lun == smsLUN {
+		_ = n.bmc.Messages.ReceiveIPMB(ctx, n.channel, msg)
+		return
+	}
+	netFn, cmd, data, _, _ := protocol.ParseIPMIRequest(msg)
+	// Resolved per message, so a channel table reconfigured meanwhile is
+	// observed.
+	ch, _ := n.bmc.Channels.Get(n.channel)
+	hctx := &handlers.HandlerContext{BMC: n.bmc, LUN: lun, Channel: ch}
+	if from, ok := bmc.IPMBOriginFromContext(ctx); ok {
+		hctx.Origin = &from
+	}
+	resp, cc, _ := n.reg.Dispatch(ctx, hctx, netFn, cmd, data)
+	// A requester that has left the bus cannot be answered.
+	_ = bus.Write(ctx, protocol.BuildIPMBResponse(msg, uint8(cc), resp))
+}
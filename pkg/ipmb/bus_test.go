@@ -0,0 +1,235 @@
+package ipmb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/handlers"
+	"github.com/bougou/go-ipmi/pkg/protocol"
+)
+
+const (
+	mainAddr = 0x20
+	nmAddr   = 0x2C
+	lan      = 1
+	handle   = 0x05
+)
+
+func newTestBMC(deviceID uint8) *bmc.BMC {
+	info := bmc.DeviceInfo{
+		DeviceID:       deviceID,
+		DeviceRevision: 1,
+		FirmwareMajor:  1,
+		IPMIVersion:    0x20,
+		ManufacturerID: 0x000157,
+		ProductID:      0x0001,
+	}
+	var guid [16]byte
+	return bmc.New(info, guid, mock.New(), bmc.WithClock(clock.Real))
+}
+
+// newTestRegistry returns the standard handler registry, as a frontend
+// serving the controller has.
+func newTestRegistry() *handlers.Registry {
+	reg := handlers.NewRegistry()
+	handlers.RegisterAllHandlers(reg)
+	return reg
+}
+
+// console is the origin of the requests a LAN administrator bridges.
+var console = bmc.IPMBOrigin{Channel: lan, Privilege: bmc.PrivilegeLevelAdministrator}
+
+// ipmbMessage builds an IPMB-format message with both checksums.
+func ipmbMessage(rsSA, netFnLUN, rqSA, seqLUN, cmd uint8, data ...byte) []byte {
+	msg := []byte{rsSA, netFnLUN, 0, rqSA, seqLUN, cmd}
+	msg[2] = protocol.Checksum(msg[0:2])
+	msg = append(msg, data...)
+	return append(msg, protocol.Checksum(msg[3:]))
+}
+
+// newTestBus attaches a main BMC and a Node Manager, and returns the main BMC
+// with the messages it delivers to LAN sessions.
+func newTestBus(t *testing.T) (*Bus, *bmc.BMC, *bmc.BMC, <-chan []byte) {
+	t.Helper()
+	bus := NewBus()
+	main, nm := newTestBMC(0x20), newTestBMC(0x2C)
+	if err := bus.Attach(mainAddr, main, newTestRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Attach(nmAddr, nm, newTestRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	delivered := make(chan []byte, 4)
+	main.Messages.SetDeliverer(func(_ context.Context, channel, h uint8, msg []byte) error {
+		if channel != lan || h != handle {
+			t.Errorf("delivered to channel %d handle 0x%02x", channel, h)
+		}
+		delivered <- msg
+		return nil
+	})
+	return bus, main, nm, delivered
+}
+
+func receive(t *testing.T, delivered <-chan []byte) []byte {
+	t.Helper()
+	select {
+	case msg := <-delivered:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message delivered")
+		return nil
+	}
+}
+
+func TestBusBridgesToSatellite(t *testing.T) {
+	_, main, _, delivered := newTestBus(t)
+	ctx := context.Background()
+
+	ch, err := main.Channels.Get(PrimaryChannel)
+	if err != nil || ch.Medium != bmc.ChannelMediumIPMBv10 {
+		t.Fatalf("channel 0 = %+v, %v", ch, err)
+	}
+
+	// Get Device ID from the remote console (81h, seq 9) to the Node Manager.
+	req := ipmbMessage(nmAddr, 0x06<<2, 0x81, 9<<2, 0x01)
+	if err := main.Messages.Bridge(ctx, PrimaryChannel, console, handle, req); err != nil {
+		t.Fatal(err)
+	}
+	resp := receive(t, delivered)
+	if !protocol.ValidMessage(resp) || resp[0] != 0x81 || resp[1]>>2 != 0x07 || resp[3] != nmAddr || resp[4]>>2 != 9 {
+		t.Fatalf("response % x is not addressed back to the console", resp)
+	}
+	if resp[6] != 0x00 || resp[7] != 0x2C {
+		t.Fatalf("response % x: want cc 00 and the Node Manager's device ID", resp)
+	}
+
+	absent := ipmbMessage(0x30, 0x06<<2, 0x81, 0, 0x01)
+	if err := main.Messages.Bridge(ctx, PrimaryChannel, console, handle, absent); !errors.Is(err, bmc.ErrIPMBNAK) {
+		t.Fatalf("absent controller: %v", err)
+	}
+}
+
+// TestBusPrivilege verifies a bridged request runs at its requester's
+// privilege on the satellite, and one no BMC bridged at none.
+func TestBusPrivilege(t *testing.T) {
+	bus, main, nm, delivered := newTestBus(t)
+	ctx := context.Background()
+
+	// Chassis Control (power up) needs Operator.
+	powerUp := ipmbMessage(nmAddr, 0x00<<2, 0x81, 4<<2, 0x02, 0x01)
+	user := bmc.IPMBOrigin{Channel: lan, Privilege: bmc.PrivilegeLevelUser}
+	if err := main.Messages.Bridge(ctx, PrimaryChannel, user, handle, powerUp); err != nil {
+		t.Fatal(err)
+	}
+	if resp := receive(t, delivered); resp[6] != 0xD4 {
+		t.Fatalf("User-level power up: % x, want cc D4h", resp)
+	}
+	if on, _ := nm.HAL().Chassis().PowerState(ctx); on {
+		t.Fatal("User-level request powered the satellite up")
+	}
+	if err := main.Messages.Bridge(ctx, PrimaryChannel, console, handle, powerUp); err != nil {
+		t.Fatal(err)
+	}
+	if resp := receive(t, delivered); resp[6] != 0x00 {
+		t.Fatalf("Administrator power up: % x, want cc 00h", resp)
+	}
+
+	// A controller that is not modeled writes the request directly: it
+	// has no requester behind it, so it gets no privilege.
+	if err := bus.Attach(0x40, newTestBMC(0x40), newTestRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	direct := ipmbMessage(0x40, 0x00<<2, mainAddr, 5<<2|smsLUN, 0x02, 0x01)
+	if err := bus.Write(ctx, direct); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !main.Messages.Available() {
+		if time.Now().After(deadline) {
+			t.Fatal("no response to the unbridged request")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	msg, _ := main.Messages.Dequeue()
+	if msg.Data[6] != 0xD4 {
+		t.Fatalf("unbridged power up: % x, want cc D4h", msg.Data)
+	}
+}
+
+func TestBusTimeout(t *testing.T) {
+	_, main, _, delivered := newTestBus(t)
+	ctx := context.Background()
+	main.Messages.SetBridgeTimeout(10 * time.Millisecond)
+
+	// The Node Manager acknowledges the request but never answers it.
+	req := ipmbMessage(nmAddr, 0x06<<2, 0x81, 3<<2, 0x01)
+	main.Messages.ConnectIPMB(PrimaryChannel, mainAddr, func(context.Context, []byte) error { return nil })
+	if err := main.Messages.Bridge(ctx, PrimaryChannel, console, handle, req); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := main.Messages.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	resp := receive(t, delivered)
+	if resp[0] != 0x81 || resp[4]>>2 != 3 || resp[6] != 0xC3 {
+		t.Fatalf("timeout response % x", resp)
+	}
+}
+
+func TestBusSystemSoftware(t *testing.T) {
+	_, main, nm, _ := newTestBus(t)
+	ctx := context.Background()
+
+	// The Node Manager's system software asks the BMC for its device ID; the
+	// response comes back to its SMS LUN.
+	req := ipmbMessage(mainAddr, 0x06<<2, nmAddr, 1<<2|smsLUN, 0x01)
+	system := bmc.IPMBOrigin{Channel: 0x0F, Privilege: bmc.PrivilegeLevelAdministrator}
+	if err := nm.Messages.WriteIPMB(ctx, PrimaryChannel, system, req); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !nm.Messages.Available() {
+		if time.Now().After(deadline) {
+			t.Fatal("no response in the receive message queue")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	msg, err := nm.Messages.Dequeue()
+	if err != nil || msg.Channel != PrimaryChannel || msg.Data[3] != mainAddr || msg.Data[6] != 0x00 || msg.Data[7] != 0x20 {
+		t.Fatalf("dequeued %+v, %v", msg, err)
+	}
+	if main.Messages.Available() {
+		t.Fatal("the request should have been served, not queued")
+	}
+}
+
+func TestBusAttach(t *testing.T) {
+	bus, _, _, _ := newTestBus(t)
+
+	if err := bus.Attach(nmAddr, newTestBMC(1), newTestRegistry()); !errors.Is(err, ErrAddressInUse) {
+		t.Fatalf("address in use: %v", err)
+	}
+	if err := bus.Attach(0x2D, newTestBMC(1), newTestRegistry()); !errors.Is(err, ErrInvalidAddress) {
+		t.Fatalf("odd address: %v", err)
+	}
+	if err := bus.Attach(0x82, newTestBMC(1), nil); !errors.Is(err, ErrNoRegistry) {
+		t.Fatalf("no registry: %v", err)
+	}
+	if err := bus.Attach(0x82, newTestBMC(1), newTestRegistry(), WithChannel(lan)); !errors.Is(err, ErrChannelInUse) {
+		t.Fatalf("LAN channel: %v", err)
+	}
+	if err := bus.Write(context.Background(), []byte{0x2C, 0x18}); !errors.Is(err, ErrInvalidMessage) {
+		t.Fatalf("short message: %v", err)
+	}
+
+	bus.Detach(nmAddr)
+	if err := bus.Write(context.Background(), ipmbMessage(nmAddr, 0x06<<2, mainAddr, 0, 0x01)); !errors.Is(err, bmc.ErrIPMBNAK) {
+		t.Fatalf("detached controller: %v", err)
+	}
+}
@@ -51,6 +51,16 @@ func ParseIPMIRequest(msg []byte) (netFn, cmd uint8, data []byte, seq uint8, ok
 	return netFn, cmd, data, seq, true
 }
 
+// RequestLUN returns the LUN a raw IPMI LAN request message is addressed to
+// (rsLUN, bits [1:0] of the NetFn byte), or 0 for a message too short to
+// carry one.
+func RequestLUN(msg []byte) uint8 {
+	if len(msg) < 2 {
+		return 0
+	}
+	return msg[1] & 0x03
+}
+
 // BuildIPMIResponse constructs a raw IPMI LAN response message.
 // reqNetFn is the *request* NetFn; the response uses reqNetFn|1.
 func BuildIPMIResponse(reqNetFn, cmd, seq, cc uint8, data []byte) []byte {
@@ -67,3 +77,32 @@ func BuildIPMIResponse(reqNetFn, cmd, seq, cc uint8, data []byte) []byte {
 	msg[7+len(data)] = Checksum(msg[3:])
 	return msg
 }
+
+// ValidMessage reports whether msg is a complete IPMB-format message: the
+// six header bytes and the trailing checksum at least, with both the header
+// checksum and the message checksum correct.
+func ValidMessage(msg []byte) bool {
+	if len(msg) < 7 {
+		return false
+	}
+	end := len(msg) - 1
+	return Checksum(msg[0:2]) == msg[2] && Checksum(msg[3:end]) == msg[end]
+}
+
+// BuildIPMBResponse constructs the IPMB-format response to req, a request
+// that passed [ValidMessage]: the requester and responder addresses and LUNs
+// swap places, the NetFn becomes the response NetFn and the requester's
+// sequence number is echoed (v2.0 Table 13-8).
+func BuildIPMBResponse(req []byte, cc uint8, data []byte) []byte {
+	msg := make([]byte, 8+len(data))
+	msg[0] = req[3]                           // rqSA
+	msg[1] = (req[1]|0x04)&0xFC | req[4]&0x03 // response NetFn, rqLUN
+	msg[2] = Checksum(msg[0:2])
+	msg[3] = req[0]                    // rsSA
+	msg[4] = req[4]&0xFC | req[1]&0x03 // rqSeq, rsLUN
+	msg[5] = req[5]
+	msg[6] = cc
+	copy(msg[7:], data)
+	msg[7+len(data)] = Checksum(msg[3:])
+	return msg
+}
@@ -0,0 +1,350 @@
+package redfish
+
+// AccountService: the user store, as Set User Name, Set User Password and
+// Set User Access manage it (v2.0§22.26, §22.28, §22.30). An account is a
+// named user slot; its role is its privilege on the channel Redfish stands
+// in for.
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+)
+
+// Predefined roles, and the privilege level each is.
+const (
+	roleAdministrator = "Administrator"
+	roleOperator      = "Operator"
+	roleReadOnly      = "ReadOnly"
+	roleNoAccess      = "NoAccess"
+)
+
+var roles = []struct {
+	id         string
+	privilege  bmc.PrivilegeLevel
+	privileges []string
+}{
+	{roleAdministrator, bmc.PrivilegeLevelAdministrator,
+		[]string{"Login", "ConfigureManager", "ConfigureUsers", "ConfigureSelf", "ConfigureComponents"}},
+	{roleOperator, bmc.PrivilegeLevelOperator,
+		[]string{"Login", "ConfigureSelf", "ConfigureComponents"}},
+	{roleReadOnly, bmc.PrivilegeLevelUser,
+		[]string{"Login", "ConfigureSelf"}},
+}
+
+// roleOf returns the role of privilege level priv. Callback privilege and
+// no access have no predefined role.
+func roleOf(priv bmc.PrivilegeLevel) string {
+	switch {
+	case priv == bmc.PrivilegeLevelNoAccess:
+		return roleNoAccess
+	case priv >= bmc.PrivilegeLevelAdministrator:
+		return roleAdministrator
+	case priv == bmc.PrivilegeLevelOperator:
+		return roleOperator
+	case priv == bmc.PrivilegeLevelUser:
+		return roleReadOnly
+	default:
+		return roleNoAccess
+	}
+}
+
+func rolePrivilege(id string) (bmc.PrivilegeLevel, bool) {
+	for _, r := range roles {
+		if r.id == id {
+			return r.privilege, true
+		}
+	}
+	return 0, false
+}
+
+type accountService struct {
+	ODataID           string `json:"@odata.id"`
+	ODataType         string `json:"@odata.type"`
+	ID                string `json:"Id"`
+	Name              string
+	ServiceEnabled    bool
+	MinPasswordLength int
+	MaxPasswordLength int
+	Status            *status
+	Accounts          odataID
+	Roles             odataID
+}
+
+func (s *Server) getAccountService(w http.ResponseWriter, _ *http.Request, _ *principal) {
+	writeJSON(w, http.StatusOK, accountService{
+		ODataID:           pathAccountService,
+		ODataType:         "#AccountService.v1_3_0.AccountService",
+		ID:                "AccountService",
+		Name:              "Account Service",
+		ServiceEnabled:    true,
+		MinPasswordLength: 0,
+		MaxPasswordLength: bmc.MaxPasswordLen,
+		Status:            statusOK,
+		Accounts:          odataID{pathAccounts},
+		Roles:             odataID{pathRoles},
+	})
+}
+
+func accountPath(id uint8) string {
+	return fmt.Sprintf("%s/%d", pathAccounts, id)
+}
+
+type account struct {
+	ODataID   string `json:"@odata.id"`
+	ODataType string `json:"@odata.type"`
+	ID        string `json:"Id"`
+	Name      string
+	UserName  string
+	RoleID    string `json:"RoleId"`
+	Enabled   bool
+	Locked    bool
+	// Password is never read back.
+	Password *string
+	Links    accountLinks
+}
+
+type accountLinks struct {
+	Role *odataID `json:",omitempty"`
+}
+
+func (s *Server) newAccount(u *bmc.User) account {
+	role := roleOf(u.ChannelAccess[s.channel].MaxPrivilege)
+	if !u.ChannelAccess[s.channel].Enabled {
+		role = roleNoAccess
+	}
+	a := account{
+		ODataID:   accountPath(u.ID),
+		ODataType: "#ManagerAccount.v1_1_0.ManagerAccount",
+		ID:        strconv.Itoa(int(u.ID)),
+		Name:      "User Account",
+		UserName:  u.Name,
+		RoleID:    role,
+		Enabled:   u.Enabled,
+	}
+	if role != roleNoAccess {
+		a.Links.Role = &odataID{pathRoles + "/" + role}
+	}
+	return a
+}
+
+func (s *Server) getAccounts(w http.ResponseWriter, _ *http.Request, _ *principal) {
+	var members []any
+	for _, u := range s.bmc.Users.List() {
+		// The anonymous user and the emptied slots are not accounts.
+		if u.Name != "" {
+			members = append(members, odataID{accountPath(u.ID)})
+		}
+	}
+	writeJSON(w, http.StatusOK, newCollection(pathAccounts,
+		"#ManagerAccountCollection.ManagerAccountCollection", "Accounts Collection", members))
+}
+
+// lookupAccount returns the account the {id} of r names, answering 404 and
+// returning nil when there is none.
+func (s *Server) lookupAccount(w http.ResponseWriter, r *http.Request) *bmc.User {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 8)
+	if err == nil {
+		if u, err := s.bmc.Users.Get(uint8(id)); err == nil && u.Name != "" {
+			return u
+		}
+	}
+	writeError(w, http.StatusNotFound, "ResourceNotFound", "no resource at %s", r.URL.Path)
+	return nil
+}
+
+func (s *Server) getAccount(w http.ResponseWriter, r *http.Request, _ *principal) {
+	if u := s.lookupAccount(w, r); u != nil {
+		writeJSON(w, http.StatusOK, s.newAccount(u))
+	}
+}
+
+type accountRequest struct {
+	UserName *string
+	Password *string
+	RoleID   *string `json:"RoleId"`
+	Enabled  *bool
+}
+
+// validate checks the properties the request sets.
+func (req *accountRequest) validate(w http.ResponseWriter) bool {
+	if v := req.UserName; v != nil && (*v == "" || len(*v) > bmc.MaxUserNameLen) {
+		writeError(w, http.StatusBadRequest, "PropertyValueFormatError", "UserName must be 1 to %d bytes", bmc.MaxUserNameLen)
+		return false
+	}
+	if v := req.Password; v != nil && len(*v) > bmc.MaxPasswordLen {
+		writeError(w, http.StatusBadRequest, "PropertyValueFormatError", "Password must be at most %d bytes", bmc.MaxPasswordLen)
+		return false
+	}
+	if v := req.RoleID; v != nil {
+		if _, ok := rolePrivilege(*v); !ok {
+			writeError(w, http.StatusBadRequest, "PropertyValueNotInList", "RoleId %q is not supported", *v)
+			return false
+		}
+	}
+	return true
+}
+
+// apply sets the properties of req on u.
+func (s *Server) apply(req *accountRequest, u *bmc.User) {
+	if req.UserName != nil {
+		u.Name = *req.UserName
+	}
+	if req.Password != nil {
+		u.SetPassword([]byte(*req.Password))
+	}
+	if req.Enabled != nil {
+		u.Enabled = *req.Enabled
+	}
+	if req.RoleID != nil {
+		priv, _ := rolePrivilege(*req.RoleID)
+		access := u.ChannelAccess[s.channel]
+		access.MaxPrivilege = priv
+		access.Enabled = true
+		u.ChannelAccess[s.channel] = access
+	}
+}
+
+// errSlotTaken rejects a create into a slot another request just filled.
+var errSlotTaken = errors.New("user slot taken")
+
+// postAccount creates an account in the first free user slot. The anonymous
+// user's slot 1 is never handed out.
+func (s *Server) postAccount(w http.ResponseWriter, r *http.Request, _ *principal) {
+	var req accountRequest
+	if !decodeBody(w, r, &req) || !req.validate(w) {
+		return
+	}
+	if req.UserName == nil || req.Password == nil || req.RoleID == nil {
+		writeError(w, http.StatusBadRequest, "PropertyMissing", "UserName, Password and RoleId are required")
+		return
+	}
+	if req.Enabled == nil {
+		enabled := true
+		req.Enabled = &enabled
+	}
+
+	for id := uint8(2); id <= s.bmc.Users.MaxUserCount(); id++ {
+		err := s.bmc.Users.Upsert(id, func(u *bmc.User) error {
+			if u.Name != "" {
+				return errSlotTaken
+			}
+			s.apply(&req, u)
+			return nil
+		})
+		switch {
+		case errors.Is(err, errSlotTaken):
+			continue
+		case errors.Is(err, bmc.ErrUsernameTaken):
+			writeError(w, http.StatusConflict, "ResourceAlreadyExists", "an account named %q exists", *req.UserName)
+			return
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, "InternalError", "create account: %v", err)
+			return
+		}
+		u, err := s.bmc.Users.Get(id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "InternalError", "create account: %v", err)
+			return
+		}
+		w.Header().Set("Location", accountPath(id))
+		writeJSON(w, http.StatusCreated, s.newAccount(u))
+		return
+	}
+	writeError(w, http.StatusBadRequest, "CreateLimitReachedForResource", "every user slot is in use")
+}
+
+// patchAccount updates an account. Without ConfigureUsers, that is below
+// Administrator privilege, a user may only change its own password.
+func (s *Server) patchAccount(w http.ResponseWriter, r *http.Request, p *principal) {
+	var req accountRequest
+	if !decodeBody(w, r, &req) || !req.validate(w) {
+		return
+	}
+	u := s.lookupAccount(w, r)
+	if u == nil {
+		return
+	}
+	if !p.has(bmc.PrivilegeLevelAdministrator) {
+		own := u.ID == p.user.ID
+		if !own || req.UserName != nil || req.RoleID != nil || req.Enabled != nil {
+			writeError(w, http.StatusForbidden, "InsufficientPrivilege", "the account's role does not allow this operation")
+			return
+		}
+	}
+
+	err := s.bmc.Users.Upsert(u.ID, func(u *bmc.User) error {
+		s.apply(&req, u)
+		return nil
+	})
+	if errors.Is(err, bmc.ErrUsernameTaken) {
+		writeError(w, http.StatusConflict, "ResourceAlreadyExists", "an account named %q exists", *req.UserName)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", "update account: %v", err)
+		return
+	}
+	if u, err = s.bmc.Users.Get(u.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", "update account: %v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.newAccount(u))
+}
+
+func (s *Server) deleteAccount(w http.ResponseWriter, r *http.Request, _ *principal) {
+	u := s.lookupAccount(w, r)
+	if u == nil {
+		return
+	}
+	if u.ID == 1 {
+		writeError(w, http.StatusBadRequest, "ResourceCannotBeDeleted", "the anonymous user cannot be deleted")
+		return
+	}
+	if err := s.bmc.Users.Delete(u.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", "delete account: %v", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type role struct {
+	ODataID            string `json:"@odata.id"`
+	ODataType          string `json:"@odata.type"`
+	ID                 string `json:"Id"`
+	Name               string
+	RoleID             string `json:"RoleId"`
+	IsPredefined       bool
+	AssignedPrivileges []string
+}
+
+func (s *Server) getRoles(w http.ResponseWriter, _ *http.Request, _ *principal) {
+	members := make([]any, len(roles))
+	for i, r := range roles {
+		members[i] = odataID{pathRoles + "/" + r.id}
+	}
+	writeJSON(w, http.StatusOK, newCollection(pathRoles,
+		"#RoleCollection.RoleCollection", "Roles Collection", members))
+}
+
+func (s *Server) getRole(w http.ResponseWriter, r *http.Request, _ *principal) {
+	id := r.PathValue("id")
+	for _, rl := range roles {
+		if rl.id == id {
+			writeJSON(w, http.StatusOK, role{
+				ODataID:            pathRoles + "/" + rl.id,
+				ODataType:          "#Role.v1_2_0.Role",
+				ID:                 rl.id,
+				Name:               rl.id + " Role",
+				RoleID:             rl.id,
+				IsPredefined:       true,
+				AssignedPrivileges: rl.privileges,
+			})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "ResourceNotFound", "no resource at %s", r.URL.Path)
+}
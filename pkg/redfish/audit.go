@@ -0,0 +1,67 @@
+package redfish
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// AuditMsgRequest is the message of the audit record of a state-changing
+// Redfish operation. Like the IPMI records, it carries the requester's
+// identity (user, channel, remote, privilege) and its outcome, with the IPMI
+// commands the operation stands for as its command.
+const AuditMsgRequest = "redfish request"
+
+// WithAuditLogger has every state-changing operation (reset, boot override,
+// SEL clear and account changes) written to logger as an audit record once
+// it is answered: at Info when it succeeded, at Warn when it was refused or
+// failed.
+func WithAuditLogger(logger *slog.Logger) Option {
+	return func(s *Server) { s.audit = logger }
+}
+
+// statusRecorder remembers the status code a handler answered with.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// auditRequest writes the audit record of r, served by rt and answered with
+// status. p is nil when r did not authenticate; the user is then the name
+// it tried.
+func (s *Server) auditRequest(r *http.Request, rt *route, p *principal, status int) {
+	user, privilege := "", "-"
+	if p != nil {
+		user = p.user.Name
+		privilege = types.PrivilegeLevel(p.privilege).String()
+	} else if name, _, ok := r.BasicAuth(); ok {
+		user = name
+	}
+	names := make([]string, len(rt.commands))
+	for i, c := range rt.commands {
+		names[i] = c.Name
+	}
+	ok := status < http.StatusBadRequest
+	outcome, level := "success", slog.LevelInfo
+	if !ok {
+		outcome, level = "failure", slog.LevelWarn
+	}
+	s.audit.LogAttrs(r.Context(), level, AuditMsgRequest,
+		slog.String("user", user),
+		slog.Int("channel", int(s.channel)),
+		slog.String("remote", r.RemoteAddr),
+		slog.String("privilege", privilege),
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.String("command", strings.Join(names, ", ")),
+		slog.String("outcome", outcome),
+		slog.Int("status", status),
+	)
+}
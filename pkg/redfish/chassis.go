@@ -0,0 +1,442 @@
+package redfish
+
+// Chassis: asset data from the FRU inventory (FRU §10-§12), intrusion from
+// the chassis HAL, Thermal and Power from the sensors (v2.0§35) and the DCMI
+// power reading (DCMI v1.5 §6.6.1).
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// fruDeviceID is the FRU device describing the system: the BMC's own FRU
+// inventory device (v2.0§34).
+const fruDeviceID = 0
+
+// assetInfo is the asset data of the system, taken from the first FRU area
+// that has each field.
+type assetInfo struct {
+	chassisType  types.ChassisType
+	manufacturer string
+	model        string
+	partNumber   string
+	serialNumber string
+	assetTag     string
+}
+
+// asset returns the asset data of the FRU inventory, empty when the BMC has
+// no parseable FRU. A DCMI asset tag takes precedence over the FRU's.
+func (s *Server) asset(ctx context.Context) assetInfo {
+	var a assetInfo
+	if inv := s.bmc.FRUInventory(); inv != nil {
+		if data, err := inv.Read(ctx, fruDeviceID); err == nil {
+			if fru, err := types.ParseFRU(data); err == nil {
+				a = assetFromFRU(fru)
+			}
+		}
+	}
+	if tag := s.dcmiAssetTag(); tag != "" {
+		a.assetTag = tag
+	}
+	return a
+}
+
+// dcmiAssetTag reads the DCMI asset tag a chunk at a time, as Get Asset Tag
+// returns it (DCMI v1.5 §6.4.2).
+func (s *Server) dcmiAssetTag() string {
+	var tag []byte
+	for {
+		chunk, total, err := s.bmc.DCMI.AssetTag(uint8(len(tag)), bmc.DCMIStringChunk)
+		if err != nil || len(chunk) == 0 {
+			break
+		}
+		tag = append(tag, chunk...)
+		if len(tag) >= int(total) {
+			break
+		}
+	}
+	return string(tag)
+}
+
+func assetFromFRU(fru *types.FRU) assetInfo {
+	var a assetInfo
+	first := func(dst *string, tl types.TypeLength, raw []byte) {
+		if *dst == "" && len(raw) > 0 {
+			*dst = types.FRUFieldString(tl, raw)
+		}
+	}
+	if c := fru.ChassisInfoArea; c != nil {
+		a.chassisType = c.ChassisType
+		first(&a.partNumber, c.PartNumberTypeLength, c.PartNumber)
+		first(&a.serialNumber, c.SerialNumberTypeLength, c.SerialNumber)
+	}
+	if p := fru.ProductInfoArea; p != nil {
+		first(&a.manufacturer, p.ManufacturerTypeLength, p.Manufacturer)
+		first(&a.model, p.NameTypeLength, p.Name)
+		first(&a.partNumber, p.PartModelTypeLength, p.PartModel)
+		first(&a.serialNumber, p.SerialNumberTypeLength, p.SerialNumber)
+		first(&a.assetTag, p.AssetTagTypeLength, p.AssetTag)
+	}
+	if b := fru.BoardInfoArea; b != nil {
+		first(&a.manufacturer, b.ManufacturerTypeLength, b.Manufacturer)
+		first(&a.model, b.ProductNameTypeLength, b.ProductName)
+		first(&a.partNumber, b.PartNumberTypeLength, b.PartNumber)
+		first(&a.serialNumber, b.SerialNumberTypeLength, b.SerialNumber)
+	}
+	return a
+}
+
+// redfishChassisType maps the SMBIOS chassis type of the FRU chassis area
+// to a Redfish ChassisType.
+func redfishChassisType(t types.ChassisType) string {
+	switch t {
+	case 0x03, 0x04, 0x06, 0x07, 0x0d, 0x0f, 0x10, 0x18, 0x23, 0x24:
+		return "StandAlone"
+	case 0x11, 0x17:
+		return "RackMount"
+	case 0x12, 0x14:
+		return "Expansion"
+	case 0x13:
+		return "Sidecar"
+	case 0x16:
+		return "StorageEnclosure"
+	case 0x1c:
+		return "Blade"
+	case 0x1d, 0x19:
+		return "Enclosure"
+	case 0x1a, 0x1b:
+		return "Card"
+	default:
+		return "Other"
+	}
+}
+
+// uuid returns the BMC GUID in the SMBIOS byte order Get Device GUID
+// reports it in (v2.0§20.8), or "" when it is unset.
+func (s *Server) uuid() string {
+	if s.bmc.GUID == ([16]byte{}) {
+		return ""
+	}
+	u, err := types.ParseGUID(s.bmc.GUID[:], types.GUIDModeSMBIOS)
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+type chassis struct {
+	ODataID          string `json:"@odata.id"`
+	ODataType        string `json:"@odata.type"`
+	ID               string `json:"Id"`
+	Name             string
+	ChassisType      string
+	Manufacturer     string `json:",omitempty"`
+	Model            string `json:",omitempty"`
+	PartNumber       string `json:",omitempty"`
+	SerialNumber     string `json:",omitempty"`
+	AssetTag         string `json:",omitempty"`
+	PowerState       string `json:",omitempty"`
+	Status           *status
+	PhysicalSecurity *physicalSecurity `json:",omitempty"`
+	Thermal          odataID
+	Power            odataID
+	Links            chassisLinks
+}
+
+type physicalSecurity struct {
+	IntrusionSensor string
+}
+
+type chassisLinks struct {
+	ComputerSystems []odataID
+	ManagedBy       []odataID
+}
+
+func (s *Server) getChassisCollection(w http.ResponseWriter, _ *http.Request, _ *principal) {
+	writeJSON(w, http.StatusOK, newCollection(pathChassisColl,
+		"#ChassisCollection.ChassisCollection", "Chassis Collection",
+		[]any{odataID{pathChassis}}))
+}
+
+func (s *Server) getChassis(w http.ResponseWriter, r *http.Request, _ *principal) {
+	ctx := r.Context()
+	asset := s.asset(ctx)
+	c := chassis{
+		ODataID:      pathChassis,
+		ODataType:    "#Chassis.v1_10_0.Chassis",
+		ID:           chassisID,
+		Name:         "Chassis",
+		ChassisType:  redfishChassisType(asset.chassisType),
+		Manufacturer: asset.manufacturer,
+		Model:        asset.model,
+		PartNumber:   asset.partNumber,
+		SerialNumber: asset.serialNumber,
+		AssetTag:     asset.assetTag,
+		PowerState:   s.powerState(ctx),
+		Status:       statusOK,
+		Thermal:      odataID{pathThermal},
+		Power:        odataID{pathPower},
+		Links: chassisLinks{
+			ComputerSystems: []odataID{{pathSystem}},
+			ManagedBy:       []odataID{{pathManager}},
+		},
+	}
+	// Intrusion detection is optional in the HAL; without it the
+	// property is left out.
+	if ch := s.bmc.HAL().Chassis(); ch != nil {
+		if intruded, err := ch.IntrusionState(ctx); err == nil {
+			c.PhysicalSecurity = &physicalSecurity{IntrusionSensor: "Normal"}
+			if intruded {
+				c.PhysicalSecurity.IntrusionSensor = "HardwareIntrusion"
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+// sensorThresholds are the thresholds of a sensor in its units; the ones the
+// sensor cannot read are left out.
+type sensorThresholds struct {
+	LowerThresholdNonCritical *float64 `json:",omitempty"`
+	LowerThresholdCritical    *float64 `json:",omitempty"`
+	LowerThresholdFatal       *float64 `json:",omitempty"`
+	UpperThresholdNonCritical *float64 `json:",omitempty"`
+	UpperThresholdCritical    *float64 `json:",omitempty"`
+	UpperThresholdFatal       *float64 `json:",omitempty"`
+}
+
+// sensorReading is a threshold sensor as a Thermal or Power array member.
+type sensorReading struct {
+	ODataID      string `json:"@odata.id"`
+	MemberID     string `json:"MemberId"`
+	Name         string
+	SensorNumber int
+	Status       status
+	reading      *float64
+	sensorThresholds
+}
+
+// sensorReadings reads the analog threshold sensors of type t. Each is
+// named for its record, and its Status reports the thresholds its reading
+// crosses: Warning for non-critical, Critical for the rest.
+func (s *Server) sensorReadings(ctx context.Context, t types.SensorType, path string) ([]sensorReading, error) {
+	keys, err := s.bmc.Sensors.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []sensorReading
+	for _, k := range keys {
+		sensor, reading, err := s.bmc.Sensors.Read(ctx, k.LUN, k.Number)
+		if err != nil {
+			return nil, err
+		}
+		if sensor.Type != t || !sensor.IsThreshold() || !sensor.HasAnalogReading() {
+			continue
+		}
+		// Sensor numbers repeat across LUNs; the member ID of a sensor
+		// beyond LUN 0 carries its LUN.
+		memberID := fmt.Sprintf("%d", k.Number)
+		if k.LUN != 0 {
+			memberID = fmt.Sprintf("%d.%d", k.LUN, k.Number)
+		}
+		m := sensorReading{
+			ODataID:      fmt.Sprintf("%s/%d", path, len(out)),
+			MemberID:     memberID,
+			Name:         sensor.Name,
+			SensorNumber: int(k.Number),
+		}
+		if m.Name == "" {
+			m.Name = fmt.Sprintf("Sensor %s", memberID)
+		}
+		threshold := func(i int) *float64 {
+			if sensor.ReadableThresholds&(1<<i) == 0 {
+				return nil
+			}
+			v := sensor.Reading(sensor.Thresholds[i])
+			return &v
+		}
+		m.sensorThresholds = sensorThresholds{
+			LowerThresholdNonCritical: threshold(bmc.ThresholdLNC),
+			LowerThresholdCritical:    threshold(bmc.ThresholdLCR),
+			LowerThresholdFatal:       threshold(bmc.ThresholdLNR),
+			UpperThresholdNonCritical: threshold(bmc.ThresholdUNC),
+			UpperThresholdCritical:    threshold(bmc.ThresholdUCR),
+			UpperThresholdFatal:       threshold(bmc.ThresholdUNR),
+		}
+		if reading.Unavailable {
+			m.Status = status{State: "UnavailableOffline"}
+		} else {
+			v := sensor.Reading(reading.Raw)
+			m.reading = &v
+			m.Status = status{State: "Enabled", Health: thresholdHealth(reading.ThresholdStatus)}
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// thresholdHealth returns the health of a reading with the threshold
+// comparison status ts.
+func thresholdHealth(ts uint8) string {
+	const nonCritical = 1<<bmc.ThresholdLNC | 1<<bmc.ThresholdUNC
+	switch {
+	case ts&^nonCritical != 0:
+		return "Critical"
+	case ts != 0:
+		return "Warning"
+	default:
+		return "OK"
+	}
+}
+
+type temperature struct {
+	sensorReading
+	ReadingCelsius *float64
+}
+
+type fan struct {
+	sensorReading
+	Reading      *float64
+	ReadingUnits string
+}
+
+type thermal struct {
+	ODataID      string `json:"@odata.id"`
+	ODataType    string `json:"@odata.type"`
+	ID           string `json:"Id"`
+	Name         string
+	Temperatures []temperature
+	Fans         []fan
+}
+
+func (s *Server) getThermal(w http.ResponseWriter, r *http.Request, _ *principal) {
+	ctx := r.Context()
+	temps, err := s.sensorReadings(ctx, types.SensorTypeTemperature, pathThermal+"#/Temperatures")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", "read sensors: %v", err)
+		return
+	}
+	fans, err := s.sensorReadings(ctx, types.SensorTypeFan, pathThermal+"#/Fans")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", "read sensors: %v", err)
+		return
+	}
+	t := thermal{
+		ODataID:      pathThermal,
+		ODataType:    "#Thermal.v1_5_0.Thermal",
+		ID:           "Thermal",
+		Name:         "Thermal",
+		Temperatures: []temperature{},
+		Fans:         []fan{},
+	}
+	for _, m := range temps {
+		t.Temperatures = append(t.Temperatures, temperature{sensorReading: m, ReadingCelsius: m.reading})
+	}
+	for _, m := range fans {
+		t.Fans = append(t.Fans, fan{sensorReading: m, Reading: m.reading, ReadingUnits: "RPM"})
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+type voltage struct {
+	sensorReading
+	ReadingVolts *float64
+}
+
+type powerControl struct {
+	ODataID            string `json:"@odata.id"`
+	MemberID           string `json:"MemberId"`
+	Name               string
+	PowerConsumedWatts int
+	PowerMetrics       powerMetrics
+	PowerLimit         powerLimit
+}
+
+type powerMetrics struct {
+	IntervalInMin        int
+	MinConsumedWatts     int
+	MaxConsumedWatts     int
+	AverageConsumedWatts int
+}
+
+type powerLimit struct {
+	// LimitInWatts is null while no power limit is active.
+	LimitInWatts   *int
+	LimitException string `json:",omitempty"`
+	CorrectionInMs int    `json:",omitempty"`
+}
+
+type power struct {
+	ODataID      string `json:"@odata.id"`
+	ODataType    string `json:"@odata.type"`
+	ID           string `json:"Id"`
+	Name         string
+	PowerControl []powerControl
+	Voltages     []voltage
+}
+
+// limitExceptions maps the DCMI exception actions to the Redfish
+// LimitException.
+var limitExceptions = map[types.DCMIExceptionAction]string{
+	types.DCMIExceptionAction_NoAction:          "NoAction",
+	types.DCMIExceptionAction_PowerOffAndLogSEL: "HardPowerOff",
+	types.DCMIExceptionAction_LogSEL:            "LogEventOnly",
+}
+
+func (s *Server) getPower(w http.ResponseWriter, r *http.Request, _ *principal) {
+	ctx := r.Context()
+	volts, err := s.sensorReadings(ctx, types.SensorTypeVoltage, pathPower+"#/Voltages")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", "read sensors: %v", err)
+		return
+	}
+	p := power{
+		ODataID:      pathPower,
+		ODataType:    "#Power.v1_5_0.Power",
+		ID:           "Power",
+		Name:         "Power",
+		PowerControl: []powerControl{},
+		Voltages:     []voltage{},
+	}
+	for _, m := range volts {
+		p.Voltages = append(p.Voltages, voltage{sensorReading: m, ReadingVolts: m.reading})
+	}
+	// The power control is the DCMI power meter; a platform without one
+	// has none.
+	if s.bmc.DCMI.PowerSupported() {
+		stats, err := s.bmc.DCMI.PowerReading(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "InternalError", "read power: %v", err)
+			return
+		}
+		pc := powerControl{
+			ODataID:            pathPower + "#/PowerControl/0",
+			MemberID:           "0",
+			Name:               "System Power Control",
+			PowerConsumedWatts: int(stats.Current),
+			PowerMetrics: powerMetrics{
+				IntervalInMin:        int(bmc.DCMIPowerStatsPeriod.Minutes()),
+				MinConsumedWatts:     int(stats.Minimum),
+				MaxConsumedWatts:     int(stats.Maximum),
+				AverageConsumedWatts: int(stats.Average),
+			},
+		}
+		if limit, active, err := s.bmc.DCMI.PowerLimit(); err == nil && active {
+			watts := int(limit.Watts)
+			pc.PowerLimit = powerLimit{
+				LimitInWatts:   &watts,
+				LimitException: limitExceptions[limit.ExceptionAction],
+				CorrectionInMs: int(limit.CorrectionTimeMs),
+			}
+			if pc.PowerLimit.LimitException == "" {
+				pc.PowerLimit.LimitException = "Oem"
+			}
+		}
+		p.PowerControl = append(p.PowerControl, pc)
+	}
+	writeJSON(w, http.StatusOK, p)
+}
@@ -0,0 +1,292 @@
+package redfish
+
+// Manager: the BMC itself, identified as Get Device ID and Get Device GUID
+// report it (v2.0§20.1, §20.8), and its LogService over the SEL (v2.0§31).
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+type manager struct {
+	ODataID             string `json:"@odata.id"`
+	ODataType           string `json:"@odata.type"`
+	ID                  string `json:"Id"`
+	Name                string
+	ManagerType         string
+	FirmwareVersion     string
+	UUID                string `json:",omitempty"`
+	PowerState          string
+	DateTime            string
+	DateTimeLocalOffset string
+	Status              *status
+	LogServices         odataID
+	Links               managerLinks
+}
+
+type managerLinks struct {
+	ManagerForServers []odataID
+	ManagerForChassis []odataID
+}
+
+func (s *Server) getManagers(w http.ResponseWriter, _ *http.Request, _ *principal) {
+	writeJSON(w, http.StatusOK, newCollection(pathManagers,
+		"#ManagerCollection.ManagerCollection", "Manager Collection",
+		[]any{odataID{pathManager}}))
+}
+
+func (s *Server) getManager(w http.ResponseWriter, _ *http.Request, _ *principal) {
+	info := s.bmc.Info
+	dateTime, offset := s.selTime()
+	writeJSON(w, http.StatusOK, manager{
+		ODataID:     pathManager,
+		ODataType:   "#Manager.v1_5_0.Manager",
+		ID:          managerID,
+		Name:        "Manager",
+		ManagerType: "BMC",
+		// The major revision is binary, the minor BCD, as Get Device ID
+		// encodes them.
+		FirmwareVersion:     fmt.Sprintf("%d.%02x", info.FirmwareMajor&0x7f, info.FirmwareMinor),
+		UUID:                s.uuid(),
+		PowerState:          "On",
+		DateTime:            dateTime,
+		DateTimeLocalOffset: offset,
+		Status:              statusOK,
+		LogServices:         odataID{pathLogServices},
+		Links: managerLinks{
+			ManagerForServers: []odataID{{pathSystem}},
+			ManagerForChassis: []odataID{{pathChassis}},
+		},
+	})
+}
+
+// selTime returns the SEL Time and its UTC offset, the BMC's clock
+// (v2.0§31.10, §31.11a). An unspecified offset reads as UTC.
+func (s *Server) selTime() (dateTime, offset string) {
+	minutes := s.bmc.SEL.UTCOffset()
+	if minutes == bmc.SELTimeUTCOffsetUnspecified {
+		minutes = 0
+	}
+	zone := time.FixedZone("", int(minutes)*60)
+	t := s.bmc.SEL.Time().In(zone)
+	return t.Format(time.RFC3339), t.Format("-07:00")
+}
+
+func (s *Server) getLogServices(w http.ResponseWriter, _ *http.Request, _ *principal) {
+	var members []any
+	if s.bmc.SEL.Supported() {
+		members = append(members, odataID{pathSELService})
+	}
+	writeJSON(w, http.StatusOK, newCollection(pathLogServices,
+		"#LogServiceCollection.LogServiceCollection", "Log Service Collection", members))
+}
+
+type logService struct {
+	ODataID             string `json:"@odata.id"`
+	ODataType           string `json:"@odata.type"`
+	ID                  string `json:"Id"`
+	Name                string
+	MaxNumberOfRecords  int
+	OverWritePolicy     string
+	ServiceEnabled      bool
+	DateTime            string
+	DateTimeLocalOffset string
+	Status              *status
+	Entries             odataID
+	Actions             logServiceActions
+}
+
+type logServiceActions struct {
+	ClearLog action `json:"#LogService.ClearLog"`
+}
+
+// selSupported answers 404 and returns false when the BMC has no SEL.
+func (s *Server) selSupported(w http.ResponseWriter, r *http.Request) bool {
+	if !s.bmc.SEL.Supported() {
+		writeError(w, http.StatusNotFound, "ResourceNotFound", "no resource at %s", r.URL.Path)
+		return false
+	}
+	return true
+}
+
+func (s *Server) getSELService(w http.ResponseWriter, r *http.Request, _ *principal) {
+	if !s.selSupported(w, r) {
+		return
+	}
+	alloc, err := s.bmc.SEL.AllocInfo(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", "read SEL: %v", err)
+		return
+	}
+	dateTime, offset := s.selTime()
+	writeJSON(w, http.StatusOK, logService{
+		ODataID:            pathSELService,
+		ODataType:          "#LogService.v1_1_0.LogService",
+		ID:                 "SEL",
+		Name:               "System Event Log",
+		MaxNumberOfRecords: int(alloc.PossibleAllocUnits),
+		// A full SEL refuses new records rather than overwriting the
+		// oldest (v2.0§31.6).
+		OverWritePolicy:     "NeverOverWrites",
+		ServiceEnabled:      true,
+		DateTime:            dateTime,
+		DateTimeLocalOffset: offset,
+		Status:              statusOK,
+		Entries:             odataID{pathSELEntries},
+		Actions:             logServiceActions{ClearLog: action{Target: pathSELClear}},
+	})
+}
+
+type logEntry struct {
+	ODataID      string `json:"@odata.id"`
+	ODataType    string `json:"@odata.type"`
+	ID           string `json:"Id"`
+	Name         string
+	EntryType    string
+	Created      string `json:",omitempty"`
+	Severity     string
+	Message      string
+	SensorType   string `json:",omitempty"`
+	SensorNumber *int   `json:",omitempty"`
+	EntryCode    string `json:",omitempty"`
+}
+
+func (s *Server) getSELEntries(w http.ResponseWriter, r *http.Request, _ *principal) {
+	if !s.selSupported(w, r) {
+		return
+	}
+	entries, err := s.selEntries(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", "read SEL: %v", err)
+		return
+	}
+	members := make([]any, len(entries))
+	for i := range entries {
+		members[i] = entries[i]
+	}
+	writeJSON(w, http.StatusOK, newCollection(pathSELEntries,
+		"#LogEntryCollection.LogEntryCollection", "System Event Log Entries", members))
+}
+
+// selEntries walks the SEL from its first record, as Get SEL Entry
+// traverses it (v2.0§31.5).
+func (s *Server) selEntries(ctx context.Context) ([]logEntry, error) {
+	var out []logEntry
+	for id := uint16(0); id != 0xffff; {
+		record, next, err := s.bmc.SEL.GetEntry(ctx, id)
+		// An empty SEL has no first record, and one being erased has
+		// none left to read.
+		if errors.Is(err, hal.ErrNotFound) || errors.Is(err, bmc.ErrSELEraseInProgress) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if sel, err := types.ParseSEL(record); err == nil {
+			out = append(out, newLogEntry(sel))
+		}
+		id = next
+	}
+	return out, nil
+}
+
+func (s *Server) getSELEntry(w http.ResponseWriter, r *http.Request, _ *principal) {
+	if !s.selSupported(w, r) {
+		return
+	}
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 16)
+	// 0000h and FFFFh are the first and last entry aliases, not entries.
+	if err != nil || id == 0 || id == 0xffff {
+		writeError(w, http.StatusNotFound, "ResourceNotFound", "no resource at %s", r.URL.Path)
+		return
+	}
+	record, _, err := s.bmc.SEL.GetEntry(r.Context(), uint16(id))
+	if errors.Is(err, hal.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "ResourceNotFound", "no resource at %s", r.URL.Path)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", "read SEL: %v", err)
+		return
+	}
+	sel, err := types.ParseSEL(record)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", "parse SEL record: %v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newLogEntry(sel))
+}
+
+// newLogEntry returns the SEL record sel as a LogEntry.
+func newLogEntry(sel *types.SEL) logEntry {
+	e := logEntry{
+		ODataID:   fmt.Sprintf("%s/%d", pathSELEntries, sel.RecordID),
+		ODataType: "#LogEntry.v1_4_0.LogEntry",
+		ID:        strconv.Itoa(int(sel.RecordID)),
+		Name:      fmt.Sprintf("SEL Entry %d", sel.RecordID),
+		EntryType: "SEL",
+		Severity:  "OK",
+	}
+	switch {
+	case sel.Standard != nil:
+		std := sel.Standard
+		e.Created = selCreated(std.Timestamp)
+		e.Message = std.EventString()
+		e.Severity = logSeverity(std.EventSeverity())
+		e.SensorType = std.SensorType.String()
+		number := int(std.SensorNumber)
+		e.SensorNumber = &number
+		e.EntryCode = "Assert"
+		if std.EventDir {
+			e.EntryCode = "Deassert"
+		}
+	case sel.OEMTimestamped != nil:
+		e.Created = selCreated(sel.OEMTimestamped.Timestamp)
+		e.Message = fmt.Sprintf("OEM record type %#02x, manufacturer %d", uint8(sel.RecordType), sel.OEMTimestamped.ManufacturerID)
+	default:
+		e.Message = fmt.Sprintf("OEM record type %#02x", uint8(sel.RecordType))
+	}
+	return e
+}
+
+// selCreated formats a SEL timestamp, leaving out the unspecified and
+// pre-init ones that mean nothing as a date (v2.0§37).
+func selCreated(t time.Time) string {
+	if t.Unix() <= 0x20000000 || t.Unix() == 0xffffffff {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// logSeverity maps an event's severity to a Redfish Health.
+func logSeverity(s types.EventSeverity) string {
+	switch s {
+	case types.EventSeverityCritical:
+		return "Critical"
+	case types.EventSeverityWarning, types.EventSeverityDegraded, types.EventSeverityNonFatal:
+		return "Warning"
+	default:
+		return "OK"
+	}
+}
+
+// postSELClear performs LogService.ClearLog as Clear SEL, reserving the SEL
+// first as a client must (v2.0§31.9).
+func (s *Server) postSELClear(w http.ResponseWriter, r *http.Request, _ *principal) {
+	if !s.selSupported(w, r) {
+		return
+	}
+	if _, err := s.bmc.SEL.Clear(r.Context(), s.bmc.SEL.Reserve()); err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", "clear SEL: %v", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
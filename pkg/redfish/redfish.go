@@ -0,0 +1,467 @@
+// Package redfish implements a BMC server frontend that speaks DMTF Redfish
+// (HTTP/JSON), a sibling to the RMCP+ server in pkg/server and the VM
+// protocol server in pkg/vmproto. It serves the same [bmc.BMC], so fleet
+// tooling moving from IPMI to Redfish can be tested against one simulator
+// that answers both protocols consistently: a ComputerSystem.Reset is what
+// Get Chassis Status then reports, a boot override is the boot flags Get
+// System Boot Options returns, an account created through the
+// AccountService can open an RMCP+ session, and an event the sensor scan
+// logs to the SEL is a LogEntry.
+//
+// The service has one system, one chassis and one manager, the BMC itself:
+//
+//	/redfish/v1                                  ServiceRoot
+//	/redfish/v1/Systems/1                        power state, Reset, boot override
+//	/redfish/v1/Chassis/1                        FRU asset data, intrusion
+//	/redfish/v1/Chassis/1/Thermal                temperature and fan sensors
+//	/redfish/v1/Chassis/1/Power                  DCMI power reading, voltage sensors
+//	/redfish/v1/Managers/1                       device ID, GUID, SEL time
+//	/redfish/v1/Managers/1/LogServices/SEL       the SEL, with ClearLog
+//	/redfish/v1/AccountService                   the user store
+//
+// Requests authenticate with HTTP Basic against [bmc.UserStore]. Redfish
+// stands in for the LAN channel (channel 1 unless [WithChannel] says
+// otherwise): a user must be enabled on it, and the user's privilege there,
+// capped by the channel's privilege limit, is its role. Administrator,
+// Operator and User privilege are the Administrator, Operator and ReadOnly
+// roles, and each operation needs the privilege of its IPMI counterpart.
+// Only the service root is served without credentials.
+//
+// The channel's other controls apply too. While its access mode makes it
+// unavailable (disabled, or pre-boot only with the system powered on) every
+// request answers 503, and an operation whose IPMI counterpart the firmware
+// firewall disabled on the channel answers 403. With [WithAuditLogger], the
+// operations that change state are audited like the IPMI commands they
+// stand for.
+//
+// [Server] is an [http.Handler] speaking plain HTTP; [Server.Serve] runs it
+// on a listener the way [vmproto.VMServer.Serve] does. For HTTPS, hand it to
+// an [http.Server] with a TLS configuration.
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// defaultChannel is the LAN channel whose user access and privilege limit
+// apply to Redfish requests.
+const defaultChannel uint8 = 1
+
+// The resource IDs of the one system, chassis and manager the service has.
+const (
+	systemID  = "1"
+	chassisID = "1"
+	managerID = "1"
+)
+
+// Resource paths.
+const (
+	pathServiceRoot    = "/redfish/v1"
+	pathSystems        = pathServiceRoot + "/Systems"
+	pathSystem         = pathSystems + "/" + systemID
+	pathSystemReset    = pathSystem + "/Actions/ComputerSystem.Reset"
+	pathChassisColl    = pathServiceRoot + "/Chassis"
+	pathChassis        = pathChassisColl + "/" + chassisID
+	pathThermal        = pathChassis + "/Thermal"
+	pathPower          = pathChassis + "/Power"
+	pathManagers       = pathServiceRoot + "/Managers"
+	pathManager        = pathManagers + "/" + managerID
+	pathLogServices    = pathManager + "/LogServices"
+	pathSELService     = pathLogServices + "/SEL"
+	pathSELEntries     = pathSELService + "/Entries"
+	pathSELClear       = pathSELService + "/Actions/LogService.ClearLog"
+	pathAccountService = pathServiceRoot + "/AccountService"
+	pathAccounts       = pathAccountService + "/Accounts"
+	pathRoles          = pathAccountService + "/Roles"
+)
+
+// Server serves the Redfish service of a [bmc.BMC]. Construct one with
+// [NewServer] sharing the [bmc.BMC] the other frontends serve.
+type Server struct {
+	bmc     *bmc.BMC
+	channel uint8
+	routes  []route
+	audit   *slog.Logger
+}
+
+// Option configures a [Server].
+type Option func(*Server)
+
+// WithChannel sets the LAN channel Redfish stands in for: users authenticate
+// with their access on it, and its privilege limit caps their roles.
+func WithChannel(n uint8) Option {
+	return func(s *Server) { s.channel = n }
+}
+
+// NewServer creates a Redfish frontend over the BMC state b.
+func NewServer(b *bmc.BMC, opts ...Option) *Server {
+	s := &Server{bmc: b, channel: defaultChannel}
+	for _, o := range opts {
+		o(s)
+	}
+	s.registerRoutes()
+	return s
+}
+
+func (s *Server) registerRoutes() {
+	s.handle(http.MethodGet, "/redfish", 0, s.getVersions)
+	s.handle(http.MethodGet, pathServiceRoot, 0, s.getServiceRoot)
+
+	s.handle(http.MethodGet, pathSystems, bmc.PrivilegeLevelUser, s.getSystems)
+	s.handle(http.MethodGet, pathSystem, bmc.PrivilegeLevelUser, s.getSystem)
+	s.handle(http.MethodPatch, pathSystem, bmc.PrivilegeLevelOperator, s.patchSystem,
+		types.CommandSetSystemBootOptions)
+	s.handle(http.MethodPost, pathSystemReset, bmc.PrivilegeLevelOperator, s.postSystemReset,
+		types.CommandChassisControl)
+
+	s.handle(http.MethodGet, pathChassisColl, bmc.PrivilegeLevelUser, s.getChassisCollection)
+	s.handle(http.MethodGet, pathChassis, bmc.PrivilegeLevelUser, s.getChassis)
+	s.handle(http.MethodGet, pathThermal, bmc.PrivilegeLevelUser, s.getThermal)
+	s.handle(http.MethodGet, pathPower, bmc.PrivilegeLevelUser, s.getPower)
+
+	s.handle(http.MethodGet, pathManagers, bmc.PrivilegeLevelUser, s.getManagers)
+	s.handle(http.MethodGet, pathManager, bmc.PrivilegeLevelUser, s.getManager)
+	s.handle(http.MethodGet, pathLogServices, bmc.PrivilegeLevelUser, s.getLogServices)
+	s.handle(http.MethodGet, pathSELService, bmc.PrivilegeLevelUser, s.getSELService)
+	s.handle(http.MethodGet, pathSELEntries, bmc.PrivilegeLevelUser, s.getSELEntries)
+	s.handle(http.MethodGet, pathSELEntries+"/{id}", bmc.PrivilegeLevelUser, s.getSELEntry)
+	s.handle(http.MethodPost, pathSELClear, bmc.PrivilegeLevelOperator, s.postSELClear,
+		types.CommandClearSEL)
+
+	s.handle(http.MethodGet, pathAccountService, bmc.PrivilegeLevelUser, s.getAccountService)
+	s.handle(http.MethodGet, pathAccounts, bmc.PrivilegeLevelUser, s.getAccounts)
+	s.handle(http.MethodPost, pathAccounts, bmc.PrivilegeLevelAdministrator, s.postAccount,
+		types.CommandSetUsername, types.CommandSetUserPassword, types.CommandSetUserAccess)
+	s.handle(http.MethodGet, pathAccounts+"/{id}", bmc.PrivilegeLevelUser, s.getAccount)
+	// A user may change its own password; patchAccount checks the rest.
+	s.handle(http.MethodPatch, pathAccounts+"/{id}", bmc.PrivilegeLevelUser, s.patchAccount,
+		types.CommandSetUsername, types.CommandSetUserPassword, types.CommandSetUserAccess)
+	s.handle(http.MethodDelete, pathAccounts+"/{id}", bmc.PrivilegeLevelAdministrator, s.deleteAccount,
+		types.CommandSetUsername, types.CommandSetUserAccess)
+	s.handle(http.MethodGet, pathRoles, bmc.PrivilegeLevelUser, s.getRoles)
+	s.handle(http.MethodGet, pathRoles+"/{id}", bmc.PrivilegeLevelUser, s.getRole)
+}
+
+// Serve serves HTTP on ln until ctx is canceled or ln is closed.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{
+		Handler:     s,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	// Shut down when ctx is canceled. The child context is canceled on
+	// return, so the goroutine cannot outlive Serve.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	// The BMC keeps time while Redfish serves it, alone or alongside the
+	// IPMI frontends.
+	go s.bmc.Run(ctx)
+
+	err := srv.Serve(ln)
+	if errors.Is(err, http.ErrServerClosed) || errors.Is(err, net.ErrClosed) || ctx.Err() != nil {
+		return nil
+	}
+	return fmt.Errorf("serve redfish: %w", err)
+}
+
+// handlerFunc serves one request on behalf of the authenticated principal,
+// which is nil for the routes served without credentials.
+type handlerFunc func(w http.ResponseWriter, r *http.Request, p *principal)
+
+// route is one method and path pattern, whose "{name}" segments match any
+// single segment and are made available through [http.Request.PathValue].
+type route struct {
+	method    string
+	segments  []string
+	privilege bmc.PrivilegeLevel // 0 = no authentication
+	h         handlerFunc
+	// commands are the IPMI commands a state-changing operation stands
+	// for; nil for the read-only ones.
+	commands []types.Command
+}
+
+// handle adds a route. A state-changing operation lists the IPMI commands
+// it stands for, which the firmware firewall must leave enabled.
+func (s *Server) handle(method, pattern string, privilege bmc.PrivilegeLevel, h handlerFunc, commands ...types.Command) {
+	s.routes = append(s.routes, route{
+		method:    method,
+		segments:  splitPath(pattern),
+		privilege: privilege,
+		h:         h,
+		commands:  commands,
+	})
+}
+
+// splitPath splits a path into its segments, ignoring a trailing slash:
+// Redfish clients address "/redfish/v1/" and "/redfish/v1" alike.
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// match reports whether segments match the route's pattern, setting the
+// values of its wildcards on r when they do.
+func (rt *route) match(segments []string, r *http.Request) bool {
+	if len(segments) != len(rt.segments) {
+		return false
+	}
+	for i, seg := range rt.segments {
+		if strings.HasPrefix(seg, "{") {
+			continue
+		}
+		if seg != segments[i] {
+			return false
+		}
+	}
+	if r != nil {
+		for i, seg := range rt.segments {
+			if strings.HasPrefix(seg, "{") {
+				r.SetPathValue(strings.Trim(seg, "{}"), segments[i])
+			}
+		}
+	}
+	return true
+}
+
+// ServeHTTP routes r to its resource. An unknown resource answers 404 and a
+// method the resource does not take 405, both with a Redfish error body.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("OData-Version", "4.0")
+
+	if !s.channelAvailable(r.Context()) {
+		writeError(w, http.StatusServiceUnavailable, "ServiceTemporarilyUnavailable", "channel %d is not available", s.channel)
+		return
+	}
+
+	segments := splitPath(r.URL.Path)
+	var allowed []string
+	for i := range s.routes {
+		rt := &s.routes[i]
+		if rt.method != r.Method {
+			if rt.match(segments, nil) {
+				allowed = append(allowed, rt.method)
+			}
+			continue
+		}
+		if !rt.match(segments, r) {
+			continue
+		}
+		var p *principal
+		if len(rt.commands) > 0 && s.audit != nil {
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			w = rec
+			defer func() { s.auditRequest(r, rt, p, rec.status) }()
+		}
+		if rt.privilege != 0 {
+			var ok bool
+			if p, ok = s.authenticate(r); !ok {
+				w.Header().Set("WWW-Authenticate", `Basic realm="Redfish"`)
+				writeError(w, http.StatusUnauthorized, "NoValidSession", "valid credentials are required")
+				return
+			}
+			if !p.has(rt.privilege) {
+				writeError(w, http.StatusForbidden, "InsufficientPrivilege", "the account's role does not allow this operation")
+				return
+			}
+		}
+		for _, c := range rt.commands {
+			if !s.bmc.CommandEnables.Enabled(s.channel, 0, uint8(c.NetFn), c.ID) {
+				writeError(w, http.StatusForbidden, "ActionNotSupported", "%s is disabled on channel %d", c.Name, s.channel)
+				return
+			}
+		}
+		rt.h(w, r, p)
+		return
+	}
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, http.StatusMethodNotAllowed, "OperationNotAllowed", "%s is not allowed on %s", r.Method, r.URL.Path)
+		return
+	}
+	writeError(w, http.StatusNotFound, "ResourceNotFound", "no resource at %s", r.URL.Path)
+}
+
+// channelAvailable reports whether the channel's access mode lets it serve
+// requests now. A channel that is not configured restricts nothing.
+func (s *Server) channelAvailable(ctx context.Context) bool {
+	ch, err := s.bmc.Channels.Get(s.channel)
+	if err != nil {
+		return true
+	}
+	return s.bmc.ChannelAvailable(ctx, ch)
+}
+
+// principal is the user a request authenticated as.
+type principal struct {
+	user *bmc.User
+	// privilege is the user's privilege on the channel, capped by the
+	// channel's privilege limit.
+	privilege bmc.PrivilegeLevel
+}
+
+// has reports whether the principal holds privilege level want.
+func (p *principal) has(want bmc.PrivilegeLevel) bool {
+	return p.privilege != bmc.PrivilegeLevelNoAccess && p.privilege >= want
+}
+
+// authenticate checks the Basic credentials of r against the user store,
+// the way session setup checks them for the channel.
+func (s *Server) authenticate(r *http.Request) (*principal, bool) {
+	name, password, ok := r.BasicAuth()
+	if !ok || name == "" || len(password) > bmc.MaxPasswordLen {
+		return nil, false
+	}
+	u, err := s.bmc.Users.FindEnabledByNameOnChannel(name, s.channel)
+	if err != nil || !u.VerifyPassword([]byte(password)) {
+		return nil, false
+	}
+	priv := u.ChannelAccess[s.channel].MaxPrivilege
+	if ch, err := s.bmc.Channels.Get(s.channel); err == nil &&
+		priv != bmc.PrivilegeLevelNoAccess && priv > ch.MaxPrivilege {
+		priv = ch.MaxPrivilege
+	}
+	return &principal{user: u, privilege: priv}, true
+}
+
+// odataID is a reference to another resource.
+type odataID struct {
+	ID string `json:"@odata.id"`
+}
+
+// collection is a resource collection.
+type collection struct {
+	ODataID      string `json:"@odata.id"`
+	ODataType    string `json:"@odata.type"`
+	Name         string
+	Members      []any
+	MembersCount int `json:"Members@odata.count"`
+}
+
+func newCollection(path, odataType, name string, members []any) collection {
+	if members == nil {
+		members = []any{}
+	}
+	return collection{
+		ODataID:      path,
+		ODataType:    odataType,
+		Name:         name,
+		Members:      members,
+		MembersCount: len(members),
+	}
+}
+
+// status is the Status of a resource.
+type status struct {
+	State  string `json:",omitempty"`
+	Health string `json:",omitempty"`
+}
+
+var statusOK = &status{State: "Enabled", Health: "OK"}
+
+// action is an action a resource advertises.
+type action struct {
+	Target          string   `json:"target"`
+	AllowableValues []string `json:"ResetType@Redfish.AllowableValues,omitempty"`
+}
+
+func (s *Server) getVersions(w http.ResponseWriter, _ *http.Request, _ *principal) {
+	writeJSON(w, http.StatusOK, map[string]string{"v1": pathServiceRoot + "/"})
+}
+
+type serviceRoot struct {
+	ODataID        string `json:"@odata.id"`
+	ODataType      string `json:"@odata.type"`
+	ID             string `json:"Id"`
+	Name           string
+	RedfishVersion string
+	UUID           string `json:",omitempty"`
+	Systems        odataID
+	Chassis        odataID
+	Managers       odataID
+	AccountService odataID
+}
+
+func (s *Server) getServiceRoot(w http.ResponseWriter, _ *http.Request, _ *principal) {
+	writeJSON(w, http.StatusOK, serviceRoot{
+		ODataID:        pathServiceRoot,
+		ODataType:      "#ServiceRoot.v1_5_0.ServiceRoot",
+		ID:             "RootService",
+		Name:           "Root Service",
+		RedfishVersion: "1.6.0",
+		UUID:           s.uuid(),
+		Systems:        odataID{pathSystems},
+		Chassis:        odataID{pathChassisColl},
+		Managers:       odataID{pathManagers},
+		AccountService: odataID{pathAccountService},
+	})
+}
+
+// writeJSON writes v as the JSON body of a response with status code.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// redfishError is the error response body (Redfish Specification §9.6).
+type redfishError struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code         string           `json:"code"`
+	Message      string           `json:"message"`
+	ExtendedInfo []messageDetails `json:"@Message.ExtendedInfo"`
+}
+
+type messageDetails struct {
+	MessageID string `json:"MessageId"`
+	Message   string
+	Severity  string
+}
+
+// writeError writes an error response whose message is messageID of the
+// Base message registry.
+func writeError(w http.ResponseWriter, code int, messageID, format string, args ...any) {
+	id := "Base.1.8." + messageID
+	msg := fmt.Sprintf(format, args...)
+	writeJSON(w, code, redfishError{Error: errorBody{
+		Code:    id,
+		Message: msg,
+		ExtendedInfo: []messageDetails{{
+			MessageID: id,
+			Message:   msg,
+			Severity:  "Critical",
+		}},
+	}})
+}
+
+// maxRequestBody bounds the request bodies the service decodes.
+const maxRequestBody = 64 << 10
+
+// decodeBody decodes the JSON body of r into v, answering 400 and returning
+// false when it is malformed or has properties v does not.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "MalformedJSON", "the request body is not valid: %v", err)
+		return false
+	}
+	return true
+}
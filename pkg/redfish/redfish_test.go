@@ -0,0 +1,386 @@
+package redfish
+
+// Tests for the Redfish frontend, driven through httptest against a BMC on
+// the mock HAL. They prove the service root is open and the rest needs
+// credentials, that roles follow channel privilege, and that what Redfish
+// does is the state the IPMI side sees: a reset is a Chassis Control restart,
+// a boot override is the boot flags, an account is a user, a SEL record is
+// a LogEntry.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+const (
+	testAdmin    = "admin"
+	testPass     = "adminpass1234567"
+	testOperator = "oper"
+	testReadOnly = "viewer"
+)
+
+// newTestBMC builds a BMC with an administrator, an operator and a user
+// enabled on the LAN channel, the same shape the RMCP+ server tests use.
+func newTestBMC(t *testing.T) (*bmc.BMC, *mock.HAL) {
+	t.Helper()
+
+	h := mock.New()
+	b := bmc.New(bmc.DeviceInfo{IPMIVersion: 0x20}, [16]byte{}, h, bmc.WithClock(clock.Real))
+
+	for i, u := range []struct {
+		name string
+		priv bmc.PrivilegeLevel
+	}{
+		{testAdmin, bmc.PrivilegeLevelAdministrator},
+		{testOperator, bmc.PrivilegeLevelOperator},
+		{testReadOnly, bmc.PrivilegeLevelUser},
+	} {
+		user, err := b.Users.Add(uint8(i+2), u.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		user.SetPassword([]byte(testPass))
+		user.Enabled = true
+		user.ChannelAccess[1] = bmc.UserChannelAccess{MaxPrivilege: u.priv, Enabled: true}
+	}
+
+	return b, h
+}
+
+type testClient struct {
+	t   *testing.T
+	url string
+}
+
+func newTestClient(t *testing.T, b *bmc.BMC, opts ...Option) *testClient {
+	t.Helper()
+
+	srv := httptest.NewServer(NewServer(b, opts...))
+	t.Cleanup(srv.Close)
+	return &testClient{t: t, url: srv.URL}
+}
+
+// do sends a request as user (none when empty) and decodes a JSON response
+// body into out when out is non-nil.
+func (c *testClient) do(method, path, user string, body, out any) *http.Response {
+	c.t.Helper()
+
+	var rd bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&rd).Encode(body); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, c.url+path, &rd)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if user != "" {
+		req.SetBasicAuth(user, testPass)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			c.t.Fatalf("%s %s: decode: %v", method, path, err)
+		}
+	}
+	return resp
+}
+
+func (c *testClient) expect(method, path, user string, body, out any, want int) {
+	c.t.Helper()
+
+	if resp := c.do(method, path, user, body, out); resp.StatusCode != want {
+		c.t.Fatalf("%s %s as %q: status %d, want %d", method, path, user, resp.StatusCode, want)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	b, _ := newTestBMC(t)
+	c := newTestClient(t, b)
+
+	var root serviceRoot
+	c.expect(http.MethodGet, "/redfish/v1/", "", nil, &root, http.StatusOK)
+	if root.Systems.ID != pathSystems {
+		t.Errorf("Systems = %q, want %q", root.Systems.ID, pathSystems)
+	}
+
+	resp := c.do(http.MethodGet, pathSystem, "", nil, nil)
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+		t.Errorf("unauthenticated: status %d, WWW-Authenticate %q", resp.StatusCode, resp.Header.Get("WWW-Authenticate"))
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, c.url+pathSystem, nil)
+	req.SetBasicAuth(testAdmin, "wrong")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong password: status %d, want 401", resp.StatusCode)
+	}
+
+	c.expect(http.MethodGet, pathSystem, testReadOnly, nil, nil, http.StatusOK)
+	c.expect(http.MethodGet, "/redfish/v1/Nowhere", testReadOnly, nil, nil, http.StatusNotFound)
+	c.expect(http.MethodDelete, pathSystem, testAdmin, nil, nil, http.StatusMethodNotAllowed)
+}
+
+func TestSystemReset(t *testing.T) {
+	b, h := newTestBMC(t)
+	c := newTestClient(t, b)
+	ch := h.Chassis().(*mock.Chassis)
+
+	c.expect(http.MethodPost, pathSystemReset, testReadOnly, resetRequest{ResetType: resetOn}, nil, http.StatusForbidden)
+	c.expect(http.MethodPost, pathSystemReset, testOperator, resetRequest{ResetType: "Bogus"}, nil, http.StatusBadRequest)
+
+	c.expect(http.MethodPost, pathSystemReset, testOperator, resetRequest{ResetType: resetOn}, nil, http.StatusNoContent)
+	var sys computerSystem
+	c.expect(http.MethodGet, pathSystem, testReadOnly, nil, &sys, http.StatusOK)
+	if sys.PowerState != "On" {
+		t.Errorf("PowerState = %q, want On", sys.PowerState)
+	}
+
+	c.expect(http.MethodPost, pathSystemReset, testOperator, resetRequest{ResetType: resetPowerCycle}, nil, http.StatusNoContent)
+	if ch.PowerCycles != 1 {
+		t.Errorf("PowerCycles = %d, want 1", ch.PowerCycles)
+	}
+	// Get System Restart Cause reports what Redfish did as Chassis Control.
+	if cause, channel := b.Chassis.RestartCause(); cause != bmc.RestartCauseChassisControl || channel != 1 {
+		t.Errorf("restart cause = %#x on channel %d, want Chassis Control on 1", cause, channel)
+	}
+
+	c.expect(http.MethodPost, pathSystemReset, testOperator, resetRequest{ResetType: resetForceOff}, nil, http.StatusNoContent)
+	if on, _ := ch.PowerState(context.Background()); on {
+		t.Error("ForceOff left the chassis powered on")
+	}
+}
+
+// TestChannelControls verifies Redfish honours the channel's access mode
+// and the firmware firewall like the LAN channel it stands in for.
+func TestChannelControls(t *testing.T) {
+	b, _ := newTestBMC(t)
+	c := newTestClient(t, b)
+
+	ch, err := b.Channels.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabled := ch.Access()
+	disabled := enabled
+	disabled.AccessMode = bmc.ChannelAccessDisabled
+	if err := b.Channels.SetAccess(1, true, disabled); err != nil {
+		t.Fatal(err)
+	}
+	c.expect(http.MethodGet, pathServiceRoot, "", nil, nil, http.StatusServiceUnavailable)
+	c.expect(http.MethodGet, pathSystem, testReadOnly, nil, nil, http.StatusServiceUnavailable)
+	if err := b.Channels.SetAccess(1, true, enabled); err != nil {
+		t.Fatal(err)
+	}
+
+	netFn := uint8(types.CommandChassisControl.NetFn)
+	b.CommandEnables.SetEnables(1, 0, netFn, map[uint8]bool{types.CommandChassisControl.ID: false})
+	c.expect(http.MethodPost, pathSystemReset, testOperator, resetRequest{ResetType: resetOn}, nil, http.StatusForbidden)
+	c.expect(http.MethodGet, pathSystem, testReadOnly, nil, nil, http.StatusOK)
+	// The firewall of another channel does not apply.
+	b.CommandEnables.SetEnables(1, 0, netFn, map[uint8]bool{types.CommandChassisControl.ID: true})
+	b.CommandEnables.SetEnables(2, 0, netFn, map[uint8]bool{types.CommandChassisControl.ID: false})
+	c.expect(http.MethodPost, pathSystemReset, testOperator, resetRequest{ResetType: resetOn}, nil, http.StatusNoContent)
+}
+
+// TestAuditLogger verifies state-changing operations, refused ones
+// included, are audited and reads are not.
+func TestAuditLogger(t *testing.T) {
+	b, _ := newTestBMC(t)
+	var buf bytes.Buffer
+	var mu sync.Mutex
+	logger := slog.New(slog.NewJSONHandler(writerFunc(func(p []byte) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return buf.Write(p)
+	}), nil))
+	c := newTestClient(t, b, WithAuditLogger(logger))
+
+	c.expect(http.MethodPost, pathSystemReset, testOperator, resetRequest{ResetType: resetOn}, nil, http.StatusNoContent)
+	c.expect(http.MethodGet, pathSystem, testReadOnly, nil, nil, http.StatusOK)
+	c.expect(http.MethodPost, pathSystemReset, testReadOnly, resetRequest{ResetType: resetOn}, nil, http.StatusForbidden)
+	name, password, role := "newop", "newpass", roleOperator
+	req := accountRequest{UserName: &name, Password: &password, RoleID: &role}
+	c.expect(http.MethodPost, pathAccounts, testAdmin, req, nil, http.StatusCreated)
+
+	type record struct {
+		Msg, User, Privilege, Command, Outcome string
+		Status                                 int
+	}
+	want := []record{
+		{AuditMsgRequest, testOperator, "OPERATOR", "Chassis Control", "success", http.StatusNoContent},
+		{AuditMsgRequest, testReadOnly, "USER", "Chassis Control", "failure", http.StatusForbidden},
+		{AuditMsgRequest, testAdmin, "ADMINISTRATOR", "Set User Name, Set User Password Command, Set User Access Command", "success", http.StatusCreated},
+	}
+	mu.Lock()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	mu.Unlock()
+	if len(lines) != len(want) {
+		t.Fatalf("audit records %q", lines)
+	}
+	for i, line := range lines {
+		var r struct {
+			Msg       string `json:"msg"`
+			User      string `json:"user"`
+			Privilege string `json:"privilege"`
+			Command   string `json:"command"`
+			Outcome   string `json:"outcome"`
+			Status    int    `json:"status"`
+		}
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatal(err)
+		}
+		if got := (record{r.Msg, r.User, r.Privilege, r.Command, r.Outcome, r.Status}); got != want[i] {
+			t.Errorf("record %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+// writerFunc is an io.Writer calling itself.
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+func TestBootOverride(t *testing.T) {
+	b, h := newTestBMC(t)
+	c := newTestClient(t, b)
+
+	target, enabled, mode := "Pxe", bootOverrideOnce, "UEFI"
+	patch := systemPatch{Boot: &struct {
+		BootSourceOverrideEnabled *string
+		BootSourceOverrideTarget  *string
+		BootSourceOverrideMode    *string
+	}{&enabled, &target, &mode}}
+	var sys computerSystem
+	c.expect(http.MethodPatch, pathSystem, testOperator, patch, &sys, http.StatusOK)
+
+	flags, err := h.Chassis().GetBootFlags(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !flags.BootFlagsValid || flags.Persist || flags.BootDeviceSelector != types.BootDeviceSelectorForcePXE || flags.BIOSBootType != types.BIOSBootTypeEFI {
+		t.Errorf("boot flags = %+v, want a one-time EFI PXE override", flags)
+	}
+	if sys.Boot == nil || sys.Boot.BootSourceOverrideTarget != "Pxe" || sys.Boot.BootSourceOverrideEnabled != bootOverrideOnce {
+		t.Errorf("Boot = %+v, want a one-time Pxe override", sys.Boot)
+	}
+
+	bad := "Tape"
+	patch.Boot.BootSourceOverrideTarget = &bad
+	c.expect(http.MethodPatch, pathSystem, testOperator, patch, nil, http.StatusBadRequest)
+}
+
+func TestAccounts(t *testing.T) {
+	b, _ := newTestBMC(t)
+	c := newTestClient(t, b)
+
+	name, password, role := "newop", "newpass", roleOperator
+	req := accountRequest{UserName: &name, Password: &password, RoleID: &role}
+	c.expect(http.MethodPost, pathAccounts, testOperator, req, nil, http.StatusForbidden)
+
+	var acct account
+	resp := c.do(http.MethodPost, pathAccounts, testAdmin, req, &acct)
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("Location") != acct.ODataID {
+		t.Fatalf("create: status %d, Location %q, account %q", resp.StatusCode, resp.Header.Get("Location"), acct.ODataID)
+	}
+	// The account is a user that can authenticate on the LAN channel.
+	u, err := b.Users.FindEnabledByNameOnChannel(name, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !u.VerifyPassword([]byte(password)) || u.ChannelAccess[1].MaxPrivilege != bmc.PrivilegeLevelOperator {
+		t.Errorf("user %q: password or privilege not as created", name)
+	}
+	c.expect(http.MethodPost, pathAccounts, testAdmin, req, nil, http.StatusConflict)
+
+	// A non-administrator may change only its own password.
+	admin := roleAdministrator
+	c.expect(http.MethodPatch, acct.ODataID, testOperator, accountRequest{RoleID: &admin}, nil, http.StatusForbidden)
+	c.expect(http.MethodPatch, accountPath(3), testOperator, accountRequest{Password: &password}, nil, http.StatusOK)
+	if u, _ := b.Users.Get(3); !u.VerifyPassword([]byte(password)) {
+		t.Error("own password change was not applied")
+	}
+
+	c.expect(http.MethodDelete, acct.ODataID, testAdmin, nil, nil, http.StatusNoContent)
+	c.expect(http.MethodGet, acct.ODataID, testAdmin, nil, nil, http.StatusNotFound)
+}
+
+func TestSELLogService(t *testing.T) {
+	b, _ := newTestBMC(t)
+	c := newTestClient(t, b)
+	if !b.SEL.Supported() {
+		t.Skip("mock HAL has no SEL storage")
+	}
+
+	// A system event record: temperature sensor 0x30 asserting upper
+	// critical going high.
+	record := []byte{
+		0x00, 0x00, 0x02,
+		0x00, 0x00, 0x00, 0x60, // timestamp
+		0x20, 0x00, 0x04,
+		0x01, 0x30, 0x01,
+		0x09, 0xff, 0xff,
+	}
+	id, err := b.SEL.Add(context.Background(), record)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries struct {
+		Members []logEntry
+	}
+	c.expect(http.MethodGet, pathSELEntries, testReadOnly, nil, &entries, http.StatusOK)
+	if len(entries.Members) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries.Members))
+	}
+	e := entries.Members[0]
+	if e.ODataID != pathSELEntries+"/"+strconv.Itoa(int(id)) || e.SensorNumber == nil || *e.SensorNumber != 0x30 || e.Created == "" {
+		t.Errorf("entry = %+v", e)
+	}
+
+	c.expect(http.MethodPost, pathSELClear, testReadOnly, struct{}{}, nil, http.StatusForbidden)
+	c.expect(http.MethodPost, pathSELClear, testOperator, struct{}{}, nil, http.StatusNoContent)
+	c.expect(http.MethodGet, pathSELEntries, testReadOnly, nil, &entries, http.StatusOK)
+	if len(entries.Members) != 0 {
+		t.Errorf("got %d entries after ClearLog, want 0", len(entries.Members))
+	}
+}
+
+func TestResources(t *testing.T) {
+	b, _ := newTestBMC(t)
+	c := newTestClient(t, b)
+
+	for _, path := range []string{
+		pathSystems, pathSystem, pathChassisColl, pathChassis, pathThermal, pathPower,
+		pathManagers, pathManager, pathLogServices, pathSELService, pathSELEntries,
+		pathAccountService, pathAccounts, pathRoles, pathRoles + "/" + roleReadOnly,
+	} {
+		var body map[string]any
+		c.expect(http.MethodGet, path, testReadOnly, nil, &body, http.StatusOK)
+		if body["@odata.id"] != path {
+			t.Errorf("GET %s: @odata.id = %v", path, body["@odata.id"])
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package redfish
+
+// ComputerSystem: the managed system's power state, ComputerSystem.Reset as
+// Chassis Control, and the boot source override as the boot flags of Set
+// System Boot Options (v2.0§28.3, Table 28-14 parameter #5).
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// Reset types of ComputerSystem.Reset, and the Chassis Control action each
+// is (v2.0 Table 28-3).
+const (
+	resetOn               = "On"               // power up
+	resetForceOn          = "ForceOn"          // power up
+	resetForceOff         = "ForceOff"         // power down
+	resetGracefulShutdown = "GracefulShutdown" // soft shutdown
+	resetForceRestart     = "ForceRestart"     // hard reset
+	resetPowerCycle       = "PowerCycle"       // power cycle
+	resetNmi              = "Nmi"              // diagnostic interrupt
+)
+
+// Boot source override enables, and the boot flags valid and persist bits
+// each is.
+const (
+	bootOverrideDisabled   = "Disabled"
+	bootOverrideOnce       = "Once"
+	bootOverrideContinuous = "Continuous"
+)
+
+// bootTargets maps the boot source override targets to the boot device
+// selectors of the boot flags. Selectors missing here read as the target of
+// the nearest device: a remote CD-ROM is still a CD.
+var bootTargets = []struct {
+	target   string
+	selector types.BootDeviceSelector
+}{
+	{"None", types.BootDeviceSelectorNoOverride},
+	{"Pxe", types.BootDeviceSelectorForcePXE},
+	{"Hdd", types.BootDeviceSelectorForceHardDrive},
+	{"Cd", types.BootDeviceSelectorForceCDROM},
+	{"BiosSetup", types.BootDeviceSelectorForceBIOSSetup},
+	{"Diags", types.BootDeviceSelectorForceDiagnosticPartition},
+	{"Floppy", types.BootDeviceSelectorForceFloppy},
+	{"RemoteDrive", types.BootDeviceSelectorForceRemoteHardDrive},
+}
+
+var bootTargetAliases = map[types.BootDeviceSelector]string{
+	types.BootDeviceSelectorForceHardDriveSafe: "Hdd",
+	types.BootDeviceSelectorForceRemoteCDROM:   "Cd",
+	types.BootDeviceSelectorForceRemoteFloppy:  "Floppy",
+	types.BootDeviceSelectorForceRemoteMedia:   "RemoteDrive",
+}
+
+func bootTarget(selector types.BootDeviceSelector) string {
+	for _, t := range bootTargets {
+		if t.selector == selector {
+			return t.target
+		}
+	}
+	if target, ok := bootTargetAliases[selector]; ok {
+		return target
+	}
+	return "None"
+}
+
+func bootSelector(target string) (types.BootDeviceSelector, bool) {
+	for _, t := range bootTargets {
+		if t.target == target {
+			return t.selector, true
+		}
+	}
+	return 0, false
+}
+
+func bootTargetNames() []string {
+	names := make([]string, len(bootTargets))
+	for i, t := range bootTargets {
+		names[i] = t.target
+	}
+	return names
+}
+
+type computerSystem struct {
+	ODataID      string `json:"@odata.id"`
+	ODataType    string `json:"@odata.type"`
+	ID           string `json:"Id"`
+	Name         string
+	SystemType   string
+	Manufacturer string `json:",omitempty"`
+	Model        string `json:",omitempty"`
+	SerialNumber string `json:",omitempty"`
+	PartNumber   string `json:",omitempty"`
+	AssetTag     string `json:",omitempty"`
+	UUID         string `json:",omitempty"`
+	PowerState   string `json:",omitempty"`
+	Status       *status
+	Boot         *boot `json:",omitempty"`
+	Links        systemLinks
+	Actions      systemActions
+}
+
+type boot struct {
+	BootSourceOverrideEnabled       string
+	BootSourceOverrideTarget        string
+	BootSourceOverrideMode          string
+	BootSourceOverrideTargetAllowed []string `json:"BootSourceOverrideTarget@Redfish.AllowableValues,omitempty"`
+}
+
+type systemLinks struct {
+	Chassis   []odataID
+	ManagedBy []odataID
+}
+
+type systemActions struct {
+	Reset action `json:"#ComputerSystem.Reset"`
+}
+
+func (s *Server) getSystems(w http.ResponseWriter, _ *http.Request, _ *principal) {
+	writeJSON(w, http.StatusOK, newCollection(pathSystems,
+		"#ComputerSystemCollection.ComputerSystemCollection", "Computer System Collection",
+		[]any{odataID{pathSystem}}))
+}
+
+func (s *Server) getSystem(w http.ResponseWriter, r *http.Request, _ *principal) {
+	ctx := r.Context()
+	asset := s.asset(ctx)
+	sys := computerSystem{
+		ODataID:      pathSystem,
+		ODataType:    "#ComputerSystem.v1_10_0.ComputerSystem",
+		ID:           systemID,
+		Name:         "System",
+		SystemType:   "Physical",
+		Manufacturer: asset.manufacturer,
+		Model:        asset.model,
+		SerialNumber: asset.serialNumber,
+		PartNumber:   asset.partNumber,
+		AssetTag:     asset.assetTag,
+		UUID:         s.uuid(),
+		PowerState:   s.powerState(ctx),
+		Status:       statusOK,
+		Links: systemLinks{
+			Chassis:   []odataID{{pathChassis}},
+			ManagedBy: []odataID{{pathManager}},
+		},
+		Actions: systemActions{Reset: action{
+			Target:          pathSystemReset,
+			AllowableValues: s.resetTypes(),
+		}},
+	}
+	if ch := s.bmc.HAL().Chassis(); ch != nil {
+		flags, err := ch.GetBootFlags(ctx)
+		if errors.Is(err, hal.ErrNotSupported) {
+			flags, err = &types.BootOptionParam_BootFlags{}, nil
+		}
+		// Boot flags that cannot be read leave the override out.
+		if err == nil {
+			sys.Boot = bootFromFlags(flags)
+		}
+	}
+	writeJSON(w, http.StatusOK, sys)
+}
+
+func bootFromFlags(flags *types.BootOptionParam_BootFlags) *boot {
+	b := &boot{
+		BootSourceOverrideEnabled:       bootOverrideDisabled,
+		BootSourceOverrideTarget:        bootTarget(flags.BootDeviceSelector),
+		BootSourceOverrideMode:          "Legacy",
+		BootSourceOverrideTargetAllowed: bootTargetNames(),
+	}
+	switch {
+	case flags.BootFlagsValid && flags.Persist:
+		b.BootSourceOverrideEnabled = bootOverrideContinuous
+	case flags.BootFlagsValid:
+		b.BootSourceOverrideEnabled = bootOverrideOnce
+	}
+	if flags.BIOSBootType == types.BIOSBootTypeEFI {
+		b.BootSourceOverrideMode = "UEFI"
+	}
+	return b
+}
+
+// powerState returns the power state of the managed system, or "" when it
+// is unknown.
+func (s *Server) powerState(ctx context.Context) string {
+	ch := s.bmc.HAL().Chassis()
+	if ch == nil {
+		return ""
+	}
+	on, err := ch.PowerState(ctx)
+	if err != nil {
+		return ""
+	}
+	if on {
+		return "On"
+	}
+	return "Off"
+}
+
+// resetTypes returns the reset types the chassis supports: every one but
+// Nmi, which needs a chassis that can pulse a diagnostic interrupt.
+func (s *Server) resetTypes() []string {
+	ch := s.bmc.HAL().Chassis()
+	if ch == nil {
+		return nil
+	}
+	resets := []string{resetOn, resetForceOn, resetForceOff, resetGracefulShutdown, resetForceRestart, resetPowerCycle}
+	if _, ok := ch.(hal.DiagnosticInterruptHAL); ok {
+		resets = append(resets, resetNmi)
+	}
+	return resets
+}
+
+type systemPatch struct {
+	Boot *struct {
+		BootSourceOverrideEnabled *string
+		BootSourceOverrideTarget  *string
+		BootSourceOverrideMode    *string
+	}
+}
+
+// patchSystem sets the boot source override. Properties left out of the
+// request keep their value in the boot flags.
+func (s *Server) patchSystem(w http.ResponseWriter, r *http.Request, p *principal) {
+	var req systemPatch
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	ctx := r.Context()
+	ch := s.bmc.HAL().Chassis()
+	if req.Boot != nil {
+		if ch == nil {
+			writeError(w, http.StatusBadRequest, "PropertyNotWritable", "the system has no boot options")
+			return
+		}
+		flags, err := ch.GetBootFlags(ctx)
+		if errors.Is(err, hal.ErrNotSupported) {
+			flags, err = &types.BootOptionParam_BootFlags{}, nil
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "InternalError", "read boot flags: %v", err)
+			return
+		}
+		if v := req.Boot.BootSourceOverrideEnabled; v != nil {
+			switch *v {
+			case bootOverrideDisabled:
+				flags.BootFlagsValid = false
+			case bootOverrideOnce:
+				flags.BootFlagsValid, flags.Persist = true, false
+			case bootOverrideContinuous:
+				flags.BootFlagsValid, flags.Persist = true, true
+			default:
+				writeError(w, http.StatusBadRequest, "PropertyValueNotInList", "BootSourceOverrideEnabled %q is not supported", *v)
+				return
+			}
+		}
+		if v := req.Boot.BootSourceOverrideTarget; v != nil {
+			selector, ok := bootSelector(*v)
+			if !ok {
+				writeError(w, http.StatusBadRequest, "PropertyValueNotInList", "BootSourceOverrideTarget %q is not supported", *v)
+				return
+			}
+			flags.BootDeviceSelector = selector
+		}
+		if v := req.Boot.BootSourceOverrideMode; v != nil {
+			switch *v {
+			case "Legacy":
+				flags.BIOSBootType = types.BIOSBootTypeLegacy
+			case "UEFI":
+				flags.BIOSBootType = types.BIOSBootTypeEFI
+			default:
+				writeError(w, http.StatusBadRequest, "PropertyValueNotInList", "BootSourceOverrideMode %q is not supported", *v)
+				return
+			}
+		}
+		if err := s.bmc.Chassis.SetBootFlags(ctx, flags); err != nil {
+			writeHALError(w, "set boot flags", err)
+			return
+		}
+	}
+	s.getSystem(w, r, p)
+}
+
+type resetRequest struct {
+	ResetType string
+}
+
+// postSystemReset performs ComputerSystem.Reset as the Chassis Control
+// action of its reset type.
+func (s *Server) postSystemReset(w http.ResponseWriter, r *http.Request, _ *principal) {
+	var req resetRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.ResetType == "" {
+		writeError(w, http.StatusBadRequest, "ActionParameterMissing", "ResetType is required")
+		return
+	}
+	if !slices.Contains(s.resetTypes(), req.ResetType) {
+		writeError(w, http.StatusBadRequest, "ActionParameterValueNotInList", "ResetType %q is not supported", req.ResetType)
+		return
+	}
+
+	ctx := r.Context()
+	ch := s.bmc.HAL().Chassis()
+	var err error
+	switch req.ResetType {
+	case resetOn, resetForceOn:
+		err = s.restart(ch.SetPower(ctx, true))
+	case resetForceOff:
+		err = ch.SetPower(ctx, false)
+	case resetGracefulShutdown:
+		err = ch.WarmReset(ctx)
+	case resetForceRestart:
+		err = s.restart(ch.ColdReset(ctx))
+	case resetPowerCycle:
+		err = s.restart(ch.PowerCycle(ctx))
+	case resetNmi:
+		err = ch.(hal.DiagnosticInterruptHAL).DiagnosticInterrupt(ctx)
+	}
+	if err != nil {
+		writeHALError(w, "reset", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// restart records a successful power up, power cycle or hard reset as a
+// Chassis Control system restart on the channel Redfish stands in for
+// (v2.0 Table 28-11), as the Chassis Control handler does.
+func (s *Server) restart(err error) error {
+	if err == nil {
+		s.bmc.Chassis.RecordRestart(bmc.RestartCauseChassisControl, s.channel)
+	}
+	return err
+}
+
+// writeHALError answers a failed hardware operation: 400 for one the
+// hardware does not support, 500 for the rest.
+func writeHALError(w http.ResponseWriter, op string, err error) {
+	if errors.Is(err, hal.ErrNotSupported) {
+		writeError(w, http.StatusBadRequest, "ActionNotSupported", "%s: %v", op, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, "InternalError", "%s: %v", op, err)
+}
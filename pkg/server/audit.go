@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"net"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/handlers"
+	"github.com/bougou/go-ipmi/pkg/rmcpplus"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// WithAuditLogger writes audit records to logger: each RMCP+ session
+// handshake and each v1.5 activation refused before dispatch, which never
+// reach the registry, each session the BMC closes on its own (evicted for
+// inactivity or capacity, or discarded after a refused handshake), and — unless [WithHandlerRegistry] replaces the
+// default registry — the state-changing and session commands
+// [handlers.AuditLog] records. A custom registry gets those with
+// reg.Use(handlers.AuditLog(logger)) before its handlers are registered.
+func WithAuditLogger(logger *slog.Logger) ServerOption {
+	return func(s *Server) { s.audit = logger }
+}
+
+// notePendingAddr records the console's address on the session an Open
+// Session Response allocated, so an eviction of the pending session names
+// it. The first validated packet of the established session replaces it, and
+// nothing is sent to a pending session's address on its own.
+func (s *Server) notePendingAddr(addr net.Addr, resp []byte) {
+	// The managed system session ID follows the status, privilege and
+	// console session ID (v2.0 Table 13-10).
+	if len(resp) < 12 || resp[1] != uint8(types.RmcpStatusCodeNoErrors) {
+		return
+	}
+	if sess, err := s.bmc.Sessions.Get(binary.LittleEndian.Uint32(resp[8:12])); err == nil {
+		sess.SetAddr(addr)
+	}
+}
+
+// pendingHandshakeSession returns the session a RAKP Message 3 names, looked
+// up before the handler runs: a refused handshake closes the session, and
+// the record still needs its user.
+func (s *Server) pendingHandshakeSession(payload []byte) *bmc.Session {
+	if s.audit == nil || len(payload) < 8 {
+		return nil
+	}
+	sess, err := s.bmc.Sessions.Get(binary.LittleEndian.Uint32(payload[4:8]))
+	if err != nil {
+		return nil
+	}
+	return sess
+}
+
+// auditRAKP1 records a handshake the BMC refused in RAKP Message 2, naming
+// the user and privilege Message 1 asked for. An accepted Message 1 is not
+// recorded; the handshake's outcome is known at Message 4.
+func (s *Server) auditRAKP1(ctx context.Context, addr net.Addr, payload, resp []byte) {
+	if s.audit == nil || len(resp) < 2 || resp[1] == uint8(types.RmcpStatusCodeNoErrors) {
+		return
+	}
+	var req rmcpplus.RAKPMessage1
+	_ = req.Unpack(payload) // a short message leaves the fields zero
+	handlers.AuditSession(ctx, s.audit, handlers.SessionAudit{
+		User:       string(req.Username),
+		Channel:    lanChannelNumber,
+		SessionID:  req.ManagedSystemSessionID,
+		RemoteAddr: addr,
+		Privilege:  req.RequestedMaximumPrivilegeLevel,
+		Reason:     types.RmcpStatusCode(resp[1]).String(),
+	})
+}
+
+// auditRAKP3 records the outcome of a handshake's RAKP Message 3: the
+// session established, or refused with the status Message 4 carries.
+func (s *Server) auditRAKP3(ctx context.Context, addr net.Addr, sess *bmc.Session, resp []byte) {
+	if s.audit == nil || len(resp) < 2 {
+		return
+	}
+	a := handlers.SessionAudit{
+		Channel:    lanChannelNumber,
+		RemoteAddr: addr,
+	}
+	if status := types.RmcpStatusCode(resp[1]); status != types.RmcpStatusCodeNoErrors {
+		a.Reason = status.String()
+	}
+	if sess != nil {
+		sess.ProcMu.Lock()
+		if sess.User != nil {
+			a.User = sess.User.Name
+		}
+		a.Channel = sess.Channel
+		a.Handle = sess.Handle
+		a.SessionID = sess.BMCID
+		a.Privilege = types.PrivilegeLevel(sess.PrivilegeLevel)
+		if a.Reason != "" {
+			a.Privilege = types.PrivilegeLevel(sess.Role & 0x0F)
+		}
+		sess.ProcMu.Unlock()
+	}
+	handlers.AuditSession(ctx, s.audit, a)
+}
+
+// auditEvicted records a session its store evicted, as closed for the
+// eviction's reason.
+func (s *Server) auditEvicted(ev bmc.Eviction) {
+	if s.audit == nil {
+		return
+	}
+	handlers.AuditSessionClosed(context.Background(), s.audit, handlers.SessionAudit{
+		User:       ev.User,
+		Channel:    ev.Channel,
+		Handle:     ev.Handle,
+		SessionID:  ev.SessionID,
+		RemoteAddr: ev.Addr,
+		Privilege:  types.PrivilegeLevel(ev.MaxPrivilege),
+		Reason:     string(ev.Reason),
+	})
+}
+
+// auditV15Refused records a v1.5 Activate Session the server answers itself,
+// before dispatch, because the session's credentials do not authenticate it.
+// The caller holds sess.ProcMu.
+func (s *Server) auditV15Refused(addr net.Addr, sess *bmc.V15Session, req []byte, reason string) {
+	if s.audit == nil {
+		return
+	}
+	a := handlers.SessionAudit{
+		Channel:    sess.Channel,
+		Handle:     sess.Handle,
+		SessionID:  sess.TempSessionID,
+		RemoteAddr: addr,
+		Reason:     reason,
+	}
+	if sess.User != nil {
+		a.User = sess.User.Name
+	}
+	// Activate Session requests its maximum privilege in byte 2
+	// (v2.0§22.17).
+	if len(req) >= 2 {
+		a.Privilege = types.PrivilegeLevel(req[1] & 0x0F)
+	}
+	handlers.AuditSession(context.Background(), s.audit, a)
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/chassis"
+	"github.com/bougou/go-ipmi/pkg/handlers"
+	"github.com/bougou/go-ipmi/pkg/transport/udp"
+)
+
+// lockedBuffer is a bytes.Buffer the server's goroutines can write while
+// the test reads it.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestAuditLogger drives a session, a state-changing command and a refused
+// authentication through a server with an audit logger and checks the
+// records, in order.
+func TestAuditLogger(t *testing.T) {
+	b := raceNewBMC(t)
+	locked, err := b.Users.Add(3, "locked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked.SetPassword([]byte(racePass))
+	locked.ChannelAccess[1] = bmc.UserChannelAccess{MaxPrivilege: bmc.PrivilegeLevelUser, Enabled: true}
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port //nolint:forcetypeassert
+	var buf lockedBuffer
+	srv := NewServer(b, udp.Wrap(conn, udp.WithReadTimeout(time.Second)),
+		WithAuditLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); _ = srv.Close() }()
+	go srv.Serve(ctx) //nolint:errcheck
+
+	cl := adminClient(t, ctx, port)
+	if _, err := cl.ChassisControl(ctx, chassis.ChassisControlPowerUp); err != nil {
+		t.Fatalf("ChassisControl: %v", err)
+	}
+	if err := authAs(ctx, port, "locked", racePass); err == nil {
+		t.Fatal("authenticated as a disabled user")
+	}
+	if err := cl.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each record is written before the response that answers it is sent.
+	type record struct{ msg, user, command, outcome, reason string }
+	want := []record{
+		{handlers.AuditMsgSessionEstablished, raceUser, "", "success", ""},
+		{handlers.AuditMsgCommand, raceUser, "Chassis Control", "success", ""},
+		// The refused handshake's pending session is discarded as it is
+		// refused, before the refusal is answered.
+		{handlers.AuditMsgSessionClosed, "locked", "", "success", "refused"},
+		{handlers.AuditMsgSessionRejected, "locked", "", "failure", "Unauthorized name"},
+		{handlers.AuditMsgSessionClosed, raceUser, "Close Session", "success", ""},
+	}
+	var got []record
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r map[string]any
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("audit record %q: %v", line, err)
+		}
+		if remote, _ := r["remote"].(string); !strings.HasPrefix(remote, "127.0.0.1:") {
+			t.Errorf("record %q: remote = %q", r["msg"], remote)
+		}
+		str := func(k string) string { s, _ := r[k].(string); return s }
+		got = append(got, record{str("msg"), str("user"), str("command"), str("outcome"), str("reason")})
+	}
+	if len(got) != len(want) {
+		t.Fatalf("records = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
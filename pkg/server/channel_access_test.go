@@ -0,0 +1,75 @@
+package server
+
+// End-to-end Set/Get Channel Access test driven through the real pkg/client:
+// a volatile change takes effect at once, a non-volatile one waits for the
+// BMC to restart, and a disabled LAN channel stops answering.
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/client"
+	"github.com/bougou/go-ipmi/pkg/command/app"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+func TestChannelAccessDisablesLAN(t *testing.T) {
+	b := raceNewBMC(t)
+	port, ctx, stop := raceStartServer(t, b)
+	defer stop()
+
+	admin := adminClient(t, ctx, port)
+	defer admin.Close(ctx) //nolint:errcheck
+
+	// Non-volatile: stored, but the channel stays up.
+	if _, err := admin.SetChannelAccess(ctx, &app.SetChannelAccessRequest{
+		ChannelNumber: 1,
+		AccessOption:  uint8(types.ChannelAccessOption_NonVolatile),
+		AccessMode:    types.ChannelAccessMode_Disabled,
+	}); err != nil {
+		t.Fatalf("SetChannelAccess non-volatile: %v", err)
+	}
+	nv, err := admin.GetChannelAccess(ctx, 1, types.ChannelAccessOption_NonVolatile)
+	if err != nil || nv.AccessMode != types.ChannelAccessMode_Disabled {
+		t.Fatalf("non-volatile access: %+v %v", nv, err)
+	}
+	v, err := admin.GetChannelAccess(ctx, 1, types.ChannelAccessOption_Volatile)
+	if err != nil || v.AccessMode != types.ChannelAccessMode_AlwaysAvailable || v.MaxPrivilegeLevel != types.PrivilegeLevelAdministrator {
+		t.Fatalf("volatile access: %+v %v", v, err)
+	}
+
+	// Volatile: the channel goes silent, the running session included.
+	if _, err := admin.SetChannelAccess(ctx, &app.SetChannelAccessRequest{
+		ChannelNumber: 1,
+		AccessOption:  uint8(types.ChannelAccessOption_Volatile),
+		AccessMode:    types.ChannelAccessMode_Disabled,
+	}); err != nil {
+		t.Fatalf("SetChannelAccess volatile: %v", err)
+	}
+	cl, err := client.NewClient("127.0.0.1", port, raceUser, racePass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl = cl.WithTimeout(200 * time.Millisecond).WithRetry(0).WithCipherSuiteID(types.CipherSuiteID3)
+	if err := cl.Connect(ctx); err == nil {
+		t.Fatal("connected over a disabled LAN channel")
+	}
+
+	// A BMC restart brings the non-volatile setting into effect: still off.
+	b.Channels.RestoreAccess()
+	ch, _ := b.Channels.Get(1)
+	if b.ChannelAvailable(ctx, ch) {
+		t.Fatal("channel available after restoring a disabled non-volatile setting")
+	}
+
+	// Re-enabled locally, the session that was cut off answers again.
+	access := ch.Access()
+	access.AccessMode = bmc.ChannelAccessAlways
+	if err := b.Channels.SetAccess(1, true, access); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := admin.GetChannelInfo(ctx, types.ChannelNumberSelf); err != nil {
+		t.Fatalf("GetChannelInfo after re-enabling: %v", err)
+	}
+}
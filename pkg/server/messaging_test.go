@@ -0,0 +1,94 @@
+package server
+
+// End-to-end message bridging test: a LAN session sends a request to the
+// system interface with Send Message, in-band software on the VM protocol
+// collects it with Get Message and answers with Send Message, and the answer
+// arrives in the LAN session.
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/command/app"
+	"github.com/bougou/go-ipmi/pkg/command/sensor"
+	"github.com/bougou/go-ipmi/pkg/protocol"
+	"github.com/bougou/go-ipmi/pkg/types"
+	"github.com/bougou/go-ipmi/pkg/vmproto"
+)
+
+func TestMessageBridgingLANToSystemInterface(t *testing.T) {
+	b := raceNewBMC(t, bmc.WithCipherSuites([]types.CipherSuiteID{types.CipherSuiteID0}))
+	port, ctx, stop := raceStartServer(t, b)
+	defer stop()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go vmproto.NewVMServer(b).Serve(ctx, ln) //nolint:errcheck
+	conn, err := net.DialTimeout("tcp", ln.Addr().String(), 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	host := vmproto.NewClient(conn, 2*time.Second)
+
+	lan, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lan.Close()
+	bmcID := raceOpenSessionSuite0(t, lan, 0x55667788)
+	raceDoRAKPNone(t, lan, bmcID)
+
+	// The remote console's request (OEM NetFn 30h, command 01h) for the
+	// host agent, encapsulated in Send Message to the system interface.
+	request := []byte{0x81, 0x30 << 2, 0, protocol.RemoteConsoleAddr, 0x09 << 2, 0x01, 0xaa, 0}
+	request[2] = protocol.Checksum(request[0:2])
+	request[7] = protocol.Checksum(request[3:7])
+	send := &app.SendMessageRequest{TrackMask: 0x01, ChannelNumber: 0x0f, MessageData: request}
+	raceMustWrite(t, lan, protocol.BuildRMCPPlusPacket(uint8(types.PayloadTypeIPMI), 0, bmcID, 1,
+		raceBuildIPMIRequest(uint8(types.NetFnAppRequest), types.CommandSendMessage.ID, 0x01, send.Pack())))
+	if resp := raceMustReadPayload(t, lan); len(resp) < 7 || resp[5] != types.CommandSendMessage.ID || resp[6] != uint8(types.CodeOK) {
+		t.Fatalf("send message response: % x", resp)
+	}
+
+	cc, resp, err := host.Command(uint8(types.NetFnAppRequest), types.CommandGetMessageFlags.ID)
+	flags := &sensor.GetMessageFlagsResponse{}
+	if err != nil || cc != uint8(types.CodeOK) || flags.Unpack(resp) != nil || !flags.ReceiveMessageQueueAvailable {
+		t.Fatalf("get message flags: cc=%#02x % x %v", cc, resp, err)
+	}
+	cc, resp, err = host.Command(uint8(types.NetFnAppRequest), types.CommandGetMessage.ID)
+	msg := &sensor.GetMessageResponse{}
+	if err != nil || cc != uint8(types.CodeOK) || msg.Unpack(resp) != nil {
+		t.Fatalf("get message: cc=%#02x % x %v", cc, resp, err)
+	}
+	if msg.ChannelNumber != 1 || len(msg.MessageData) != 1+len(request) || !bytes.Equal(msg.MessageData[1:], request) {
+		t.Fatalf("get message: %+v", msg)
+	}
+
+	// The host agent answers with the request's addresses swapped, the way
+	// the OpenIPMI driver formats a LAN response.
+	handle := msg.MessageData[0]
+	answer := []byte{request[3], (0x30 | 1) << 2, 0, request[0], request[4], request[5], uint8(types.CodeOK), 0x55, 0}
+	answer[2] = protocol.Checksum(answer[0:2])
+	answer[8] = protocol.Checksum(answer[3:8])
+	reply := &app.SendMessageRequest{ChannelNumber: msg.ChannelNumber, MessageData: append([]byte{handle}, answer...)}
+	if cc, resp, err := host.Command(uint8(types.NetFnAppRequest), types.CommandSendMessage.ID, reply.Pack()...); err != nil || cc != uint8(types.CodeOK) {
+		t.Fatalf("answer: cc=%#02x % x %v", cc, resp, err)
+	}
+
+	if got := raceMustReadPayload(t, lan); !bytes.Equal(got, answer) {
+		t.Fatalf("bridged response: % x, want % x", got, answer)
+	}
+
+	// A handle no session holds is refused.
+	reply.MessageData[0] = handle + 1
+	if cc, _, err := host.Command(uint8(types.NetFnAppRequest), types.CommandSendMessage.ID, reply.Pack()...); err != nil ||
+		cc != uint8(types.CodeSendMessageInvalidSessionHandle) {
+		t.Fatalf("unknown handle: cc=%#02x %v, want 80h", cc, err)
+	}
+}
@@ -0,0 +1,403 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bougou/go-ipmi/internal/promtext"
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// DropReason classifies an inbound packet the server discarded without a
+// response.
+type DropReason string
+
+const (
+	// DropMalformed: too short, or a header or IPMI message that does not
+	// parse.
+	DropMalformed DropReason = "malformed"
+	// DropChannelUnavailable: the LAN channel's access mode forbids use now.
+	DropChannelUnavailable DropReason = "channel_unavailable"
+	// DropUnknownSession: the session ID names no session, or one not in a
+	// state to accept the packet.
+	DropUnknownSession DropReason = "unknown_session"
+	// DropIntegrity: the integrity check value or v1.5 AuthCode did not
+	// verify.
+	DropIntegrity DropReason = "integrity"
+	// DropDecrypt: an encrypted payload did not decrypt.
+	DropDecrypt DropReason = "decrypt"
+	// DropSequence: the session sequence number is outside the window.
+	DropSequence DropReason = "sequence"
+	// DropPolicy: v1.5 LAN is disabled, or the packet's authentication does
+	// not meet what the session or channel requires.
+	DropPolicy DropReason = "policy"
+	// DropSOLInactive: an SOL packet for a session with no SOL activated.
+	DropSOLInactive DropReason = "sol_inactive"
+	// DropSOLQueueFull: the session's SOL queue was full.
+	DropSOLQueueFull DropReason = "sol_queue_full"
+)
+
+// Session protocols, named like the ipmitool interfaces that speak them.
+const (
+	protocolRMCPPlus = "lanplus"
+	protocolV15      = "lan"
+)
+
+// Metrics counts what the clients of a [Server] do: the commands they send,
+// how their sessions authenticate, and what the server had to drop. Every
+// method is safe for concurrent use, and a nil *Metrics records nothing.
+type Metrics struct {
+	mu sync.Mutex
+
+	commands       map[commandStatsKey]*CommandStats
+	rakpSuccesses  uint64
+	rakpFailures   map[string]uint64
+	dropped        map[DropReason]uint64
+	evictions      map[evictionKey]uint64
+	solActivations uint64
+	solBytesIn     uint64
+	solBytesOut    uint64
+}
+
+type evictionKey struct {
+	protocol string
+	reason   bmc.EvictReason
+}
+
+type commandStatsKey struct {
+	netFn types.NetFn
+	cmd   uint8
+	cc    types.CompletionCode
+}
+
+func newMetrics() *Metrics {
+	return &Metrics{
+		commands:     make(map[commandStatsKey]*CommandStats),
+		rakpFailures: make(map[string]uint64),
+		dropped:      make(map[DropReason]uint64),
+		evictions:    make(map[evictionKey]uint64),
+	}
+}
+
+// command records one dispatched command and how long its handler took.
+func (m *Metrics) command(c types.Command, cc types.CompletionCode, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := commandStatsKey{netFn: c.NetFn, cmd: c.ID, cc: cc}
+	st, ok := m.commands[key]
+	if !ok {
+		st = &CommandStats{Command: c, CompletionCode: cc}
+		m.commands[key] = st
+	}
+	st.Count++
+	st.Total += elapsed
+	if elapsed > st.Max {
+		st.Max = elapsed
+	}
+}
+
+// rakp records the outcome a RAKP Message 2 or 4 reports. Only Message 4
+// with no error completes a session; any error status is a failure.
+func (m *Metrics) rakp(resp []byte, final bool) {
+	if m == nil || len(resp) < 2 {
+		return
+	}
+	// The RMCP+ status code follows the message tag (Tables 13-12, 13-13).
+	status := types.RmcpStatusCode(resp[1])
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case status != types.RmcpStatusCodeNoErrors:
+		m.rakpFailures[status.String()]++
+	case final:
+		m.rakpSuccesses++
+	}
+}
+
+// drop records a discarded packet.
+func (m *Metrics) drop(reason DropReason) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.dropped[reason]++
+	m.mu.Unlock()
+}
+
+// evicted records a session of protocol its store evicted.
+func (m *Metrics) evicted(protocol string, reason bmc.EvictReason) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.evictions[evictionKey{protocol: protocol, reason: reason}]++
+	m.mu.Unlock()
+}
+
+// solActivated records one SOL payload activation.
+func (m *Metrics) solActivated() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.solActivations++
+	m.mu.Unlock()
+}
+
+// solBytes records character data carried by SOL packets, in from the
+// console or out to it.
+func (m *Metrics) solBytes(in, out int) {
+	if m == nil || in+out == 0 {
+		return
+	}
+	m.mu.Lock()
+	m.solBytesIn += uint64(in)
+	m.solBytesOut += uint64(out)
+	m.mu.Unlock()
+}
+
+// Stats is a snapshot of a server's metrics, taken by [Server.Stats].
+type Stats struct {
+	// Commands counts dispatched commands per command and completion code,
+	// ordered by NetFn, command and completion code.
+	Commands []CommandStats
+
+	// RAKPSuccesses counts RMCP+ sessions the RAKP handshake established.
+	// RAKPFailures counts failed handshakes by the name of the RMCP+ status
+	// code the BMC answered, such as "Unauthorized name".
+	RAKPSuccesses uint64
+	RAKPFailures  map[string]uint64
+
+	// Sessions counts the active sessions per protocol, channel and user,
+	// ordered by those.
+	Sessions []SessionStats
+
+	// SOLActivations counts SOL payload activations; SOLActive is the
+	// number active now. SOLBytesIn and SOLBytesOut count the character data
+	// of SOL packets from and to the console, retransmissions included.
+	SOLActivations uint64
+	SOLActive      int
+	SOLBytesIn     uint64
+	SOLBytesOut    uint64
+
+	// Dropped counts inbound packets discarded without a response.
+	Dropped map[DropReason]uint64
+
+	// EventBufferDiscards counts the events the BMC discarded because its
+	// Event Message Buffer was full.
+	EventBufferDiscards uint64
+
+	// Evictions counts the sessions the session stores evicted per protocol
+	// and reason, ordered by those.
+	Evictions []EvictionStats
+}
+
+// CommandStats counts the dispatches of one command that completed with one
+// completion code.
+type CommandStats struct {
+	// Command is the command as the registry knows it; Name is empty for a
+	// command with no handler.
+	Command        types.Command
+	CompletionCode types.CompletionCode
+	Count          uint64
+	// Total and Max are the summed and longest handler latency.
+	Total time.Duration
+	Max   time.Duration
+}
+
+// SessionStats counts the active sessions of one user on one channel. User
+// is empty for the anonymous user.
+type SessionStats struct {
+	Protocol string
+	Channel  uint8
+	User     string
+	Active   int
+}
+
+// EvictionStats counts the sessions of one protocol ("lanplus" or "lan")
+// evicted for one reason: inactivity, making room for a new session, or a
+// refused handshake.
+type EvictionStats struct {
+	Protocol string
+	Reason   bmc.EvictReason
+	Count    uint64
+}
+
+// snapshot copies the counters into st.
+func (m *Metrics) snapshot(st *Stats) {
+	st.RAKPFailures = make(map[string]uint64)
+	st.Dropped = make(map[DropReason]uint64)
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.commands {
+		st.Commands = append(st.Commands, *c)
+	}
+	sort.Slice(st.Commands, func(i, j int) bool {
+		a, b := st.Commands[i], st.Commands[j]
+		if a.Command.NetFn != b.Command.NetFn {
+			return a.Command.NetFn < b.Command.NetFn
+		}
+		if a.Command.ID != b.Command.ID {
+			return a.Command.ID < b.Command.ID
+		}
+		return a.CompletionCode < b.CompletionCode
+	})
+	st.RAKPSuccesses = m.rakpSuccesses
+	for k, v := range m.rakpFailures {
+		st.RAKPFailures[k] = v
+	}
+	for k, v := range m.dropped {
+		st.Dropped[k] = v
+	}
+	for k, v := range m.evictions {
+		st.Evictions = append(st.Evictions, EvictionStats{Protocol: k.protocol, Reason: k.reason, Count: v})
+	}
+	sort.Slice(st.Evictions, func(i, j int) bool {
+		a, b := st.Evictions[i], st.Evictions[j]
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		return a.Reason < b.Reason
+	})
+	st.SOLActivations = m.solActivations
+	st.SOLBytesIn = m.solBytesIn
+	st.SOLBytesOut = m.solBytesOut
+}
+
+// Metrics returns the server's metrics recorder.
+func (s *Server) Metrics() *Metrics { return s.metrics }
+
+// Stats returns a snapshot of the server's metrics, with the sessions and
+// SOL payloads active now.
+func (s *Server) Stats() Stats {
+	var st Stats
+	s.metrics.snapshot(&st)
+	if s.bmc == nil {
+		return st
+	}
+
+	counts := make(map[SessionStats]int)
+	for _, a := range s.bmc.Sessions.ActiveSessions() {
+		counts[SessionStats{Protocol: protocolRMCPPlus, Channel: a.Channel, User: a.User}]++
+	}
+	for _, a := range s.bmc.V15Sessions.ActiveSessions() {
+		counts[SessionStats{Protocol: protocolV15, Channel: a.Channel, User: a.User}]++
+	}
+	for k, n := range counts {
+		k.Active = n
+		st.Sessions = append(st.Sessions, k)
+	}
+	sort.Slice(st.Sessions, func(i, j int) bool {
+		a, b := st.Sessions[i], st.Sessions[j]
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+		return a.User < b.User
+	})
+
+	if s.bmc.SOL != nil {
+		_, active1to8, active9to16 := s.bmc.SOL.ActivationStatus()
+		st.SOLActive = bits.OnesCount8(active1to8) + bits.OnesCount8(active9to16)
+	}
+	if s.bmc.Messages != nil {
+		st.EventBufferDiscards = s.bmc.Messages.EventBufferDiscards()
+	}
+	return st
+}
+
+// MetricsHandler serves [Server.Stats] in the Prometheus text exposition
+// format, for mounting on an HTTP mux at /metrics.
+func (s *Server) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", promtext.ContentType)
+		_ = s.Stats().WritePrometheus(w)
+	})
+}
+
+// WritePrometheus writes st in the Prometheus text exposition format
+// (version 0.0.4), every metric named goipmi_server_*.
+func (st Stats) WritePrometheus(w io.Writer) error {
+	var pw promtext.Writer
+
+	pw.Family("goipmi_server_command_duration_seconds", "summary",
+		"Handler latency of dispatched commands by command and completion code.")
+	for _, c := range st.Commands {
+		labels := promtext.Labels(
+			"netfn", fmt.Sprintf("0x%02x", uint8(c.Command.NetFn)),
+			"cmd", fmt.Sprintf("0x%02x", c.Command.ID),
+			"name", c.Command.Name,
+			"cc", fmt.Sprintf("0x%02x", uint8(c.CompletionCode)),
+		)
+		pw.Sample("goipmi_server_command_duration_seconds_sum", c.Total.Seconds(), labels...)
+		pw.Sample("goipmi_server_command_duration_seconds_count", float64(c.Count), labels...)
+	}
+
+	pw.Family("goipmi_server_rakp_successes_total", "counter",
+		"RMCP+ sessions established by the RAKP handshake.")
+	pw.Sample("goipmi_server_rakp_successes_total", float64(st.RAKPSuccesses))
+	pw.Family("goipmi_server_rakp_failures_total", "counter",
+		"Failed RAKP handshakes by the RMCP+ status code returned.")
+	for _, reason := range sortedKeys(st.RAKPFailures) {
+		pw.Sample("goipmi_server_rakp_failures_total", float64(st.RAKPFailures[reason]), promtext.Labels("reason", reason)...)
+	}
+
+	pw.Family("goipmi_server_sessions_active", "gauge",
+		"Active sessions by protocol, channel and user.")
+	for _, sess := range st.Sessions {
+		pw.Sample("goipmi_server_sessions_active", float64(sess.Active),
+			promtext.Labels("protocol", sess.Protocol, "channel", strconv.Itoa(int(sess.Channel)), "user", sess.User)...)
+	}
+	pw.Family("goipmi_server_session_evictions_total", "counter",
+		"Sessions evicted by their store, by protocol and reason: timeout (inactivity), capacity (the oldest pending session, dropped for a new one) or refused (a refused RMCP+ handshake).")
+	for _, e := range st.Evictions {
+		pw.Sample("goipmi_server_session_evictions_total", float64(e.Count),
+			promtext.Labels("protocol", e.Protocol, "reason", string(e.Reason))...)
+	}
+
+	pw.Family("goipmi_server_sol_activations_total", "counter", "SOL payload activations.")
+	pw.Sample("goipmi_server_sol_activations_total", float64(st.SOLActivations))
+	pw.Family("goipmi_server_sol_active", "gauge", "Active SOL payload instances.")
+	pw.Sample("goipmi_server_sol_active", float64(st.SOLActive))
+	pw.Family("goipmi_server_sol_bytes_total", "counter",
+		"SOL character data from (in) and to (out) the console, retransmissions included.")
+	pw.Sample("goipmi_server_sol_bytes_total", float64(st.SOLBytesIn), promtext.Labels("direction", "in")...)
+	pw.Sample("goipmi_server_sol_bytes_total", float64(st.SOLBytesOut), promtext.Labels("direction", "out")...)
+
+	pw.Family("goipmi_server_packets_dropped_total", "counter",
+		"Inbound packets discarded without a response, by reason.")
+	for _, reason := range sortedKeys(st.Dropped) {
+		pw.Sample("goipmi_server_packets_dropped_total", float64(st.Dropped[reason]), promtext.Labels("reason", string(reason))...)
+	}
+
+	pw.Family("goipmi_server_event_buffer_discards_total", "counter",
+		"Events discarded because the Event Message Buffer was full.")
+	pw.Sample("goipmi_server_event_buffer_discards_total", float64(st.EventBufferDiscards))
+
+	_, err := pw.WriteTo(w)
+	return err
+}
+
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bougou/go-ipmi/pkg/bmc"
+	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/transport/udp"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// TestServerStats drives a session, a failed authentication and a malformed
+// packet through a running server and checks each is counted, in the Go API
+// and on the Prometheus endpoint alike.
+func TestServerStats(t *testing.T) {
+	b := raceNewBMC(t)
+	// A disabled account is refused by the BMC in RAKP Message 2. A wrong
+	// password would not do: the console detects it in RAKP Message 2 and
+	// gives up without telling the BMC.
+	locked, err := b.Users.Add(3, "locked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked.SetPassword([]byte(racePass))
+	locked.ChannelAccess[1] = bmc.UserChannelAccess{MaxPrivilege: bmc.PrivilegeLevelUser, Enabled: true}
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port //nolint:forcetypeassert
+	srv := NewServer(b, udp.Wrap(conn, udp.WithReadTimeout(time.Second)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() { cancel(); _ = srv.Close() }()
+	go srv.Serve(ctx) //nolint:errcheck
+
+	cl := adminClient(t, ctx, port)
+	if _, err := cl.GetDeviceID(ctx); err != nil {
+		t.Fatalf("GetDeviceID: %v", err)
+	}
+	if err := authAs(ctx, port, "locked", racePass); err == nil {
+		t.Fatal("authenticated as a disabled user")
+	}
+	probe, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer probe.Close()
+	if _, err := probe.Write([]byte{0x06, 0x00}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Packets are dispatched from their own goroutines; wait for the drop.
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.Stats().Dropped[DropMalformed] == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("malformed packet not counted")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	st := srv.Stats()
+	if st.RAKPSuccesses != 1 {
+		t.Errorf("RAKPSuccesses = %d, want 1", st.RAKPSuccesses)
+	}
+	if n := st.RAKPFailures[types.RmcpStatusCodeUnauthorizedName.String()]; n != 1 {
+		t.Errorf("RAKP failures for unauthorized name = %d, want 1 (%v)", n, st.RAKPFailures)
+	}
+	var getDeviceID *CommandStats
+	for i, c := range st.Commands {
+		if c.Command.Name == "Get Device ID" && c.CompletionCode == types.CodeOK {
+			getDeviceID = &st.Commands[i]
+		}
+	}
+	if getDeviceID == nil || getDeviceID.Count != 1 || getDeviceID.Total <= 0 {
+		t.Errorf("Get Device ID stats = %+v, want one timed dispatch", getDeviceID)
+	}
+	want := SessionStats{Protocol: "lanplus", Channel: 1, User: raceUser, Active: 1}
+	if len(st.Sessions) != 1 || st.Sessions[0] != want {
+		t.Errorf("Sessions = %+v, want [%+v]", st.Sessions, want)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, line := range []string{
+		`goipmi_server_command_duration_seconds_count{netfn="0x06",cmd="0x01",name="Get Device ID",cc="0x00"} 1`,
+		`goipmi_server_rakp_successes_total 1`,
+		`goipmi_server_rakp_failures_total{reason="Unauthorized name"} 1`,
+		`goipmi_server_sessions_active{protocol="lanplus",channel="1",user="` + raceUser + `"} 1`,
+		`goipmi_server_packets_dropped_total{reason="malformed"} 1`,
+		`# TYPE goipmi_server_sol_bytes_total counter`,
+		`goipmi_server_event_buffer_discards_total 0`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("metrics lack %q:\n%s", line, body)
+		}
+	}
+
+	if err := cl.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if st := srv.Stats(); len(st.Sessions) != 0 {
+		t.Errorf("Sessions after close = %+v, want none", st.Sessions)
+	}
+}
+
+// TestNilMetrics verifies a server assembled without a recorder, as the
+// tests that build a Server literal do, reports empty stats.
+func TestNilMetrics(t *testing.T) {
+	s := &Server{}
+	s.metrics.drop(DropMalformed)
+	s.metrics.rakp([]byte{0, 0}, true)
+	if st := s.Stats(); st.RAKPSuccesses != 0 || len(st.Dropped) != 0 {
+		t.Errorf("nil metrics recorded: %+v", st)
+	}
+}
+
+// TestEvictionStats checks sessions a store evicts while allocating are
+// counted by protocol and reason, without waiting for the periodic scan.
+func TestEvictionStats(t *testing.T) {
+	conn, err := udp.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	b := bmc.New(bmc.DeviceInfo{}, [16]byte{}, mock.New())
+	srv := NewServer(b, conn)
+
+	for i := range b.Sessions.Cap() + 1 {
+		if _, err := b.Sessions.Allocate(uint32(i+1), 0, 0, 0, bmc.PrivilegeLevelUser, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []EvictionStats{{Protocol: "lanplus", Reason: bmc.EvictCapacity, Count: 1}}
+	if st := srv.Stats(); len(st.Evictions) != 1 || st.Evictions[0] != want[0] {
+		t.Errorf("Evictions = %+v, want %+v", st.Evictions, want)
+	}
+	var body strings.Builder
+	if err := srv.Stats().WritePrometheus(&body); err != nil {
+		t.Fatal(err)
+	}
+	line := `goipmi_server_session_evictions_total{protocol="lanplus",reason="capacity"} 1` + "\n"
+	if !strings.Contains(body.String(), line) {
+		t.Errorf("metrics lack %q:\n%s", line, body.String())
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net"
 	"os"
 	"sync"
@@ -47,6 +48,10 @@ const (
 
 const defaultBufferSize = 4096
 
+// lanChannelNumber is the channel the server's LAN sessions run on, the one
+// the session handlers open them on.
+const lanChannelNumber uint8 = 1
+
 // WithSOLDebug traces every SOL data-plane packet (decoded fields on
 // receipt/send, raw wire bytes on transmit) to stderr. SOL failures are
 // otherwise invisible to command tracing: the data plane rides the session
@@ -71,6 +76,16 @@ type Server struct {
 	bufSize  int
 	solDebug bool
 
+	// metrics counts commands, handshakes, drops and SOL traffic; see
+	// [Server.Stats].
+	metrics *Metrics
+	// audit receives the audit records; nil = no audit log. See
+	// [WithAuditLogger].
+	audit *slog.Logger
+
+	// trapPort is the UDP port LAN alerts (PET traps) are sent to.
+	trapPort int
+
 	// solQueues holds one ordered packet queue per session for the SOL data
 	// plane. The Serve loop spawns a goroutine per packet; for SOL that
 	// would apply console keystrokes in scheduler order, so SOL packets are
@@ -109,6 +124,25 @@ func WithHandlerRegistry(r *handlers.Registry) ServerOption {
 	return func(s *Server) { s.reg = r }
 }
 
+// WithSensorScanInterval sets how often the BMC's threshold engine samples
+// sensors (default [bmc.DefaultSensorScanInterval]). Zero or a negative
+// value turns the engine off. It sets the BMC's own period, shared by every
+// frontend serving it, as [bmc.WithSensorScanInterval] does.
+func WithSensorScanInterval(d time.Duration) ServerOption {
+	return func(s *Server) {
+		if s.bmc != nil {
+			s.bmc.SetSensorScanInterval(d)
+		}
+	}
+}
+
+// WithPETTrapPort sets the UDP port LAN alerts are sent to on each alert
+// destination (default [types.PETTrapPort], the SNMP trap port). Use it to
+// point alerts at an unprivileged trap receiver.
+func WithPETTrapPort(port int) ServerOption {
+	return func(s *Server) { s.trapPort = port }
+}
+
 // WithServerBufferSize sets the UDP read buffer size (default 4096).
 func WithServerBufferSize(n int) ServerOption {
 	return func(s *Server) { s.bufSize = n }
@@ -157,15 +191,24 @@ func NewServer(b *bmc.BMC, conn transport.PacketConn, opts ...ServerOption) *Ser
 	s := &Server{
 		bmc:       b,
 		conn:      conn,
-		reg:       newDefaultRegistry(),
 		clk:       b.Clock(),
 		bufSize:   defaultBufferSize,
 		solQueues: make(map[uint32]chan solJob),
 		solDone:   make(chan struct{}),
+		metrics:   newMetrics(),
+
+		trapPort: types.PETTrapPort,
 	}
 	for _, o := range opts {
 		o(s)
 	}
+	if s.reg == nil {
+		var middleware []handlers.Middleware
+		if s.audit != nil {
+			middleware = append(middleware, handlers.AuditLog(s.audit))
+		}
+		s.reg = newDefaultRegistry(middleware...)
+	}
 	// Activate Payload reports the port payloads are served on (Table 24-2);
 	// learn it from the transport when possible.
 	if la, ok := conn.(interface{ LocalAddr() net.Addr }); ok {
@@ -177,6 +220,7 @@ func NewServer(b *bmc.BMC, conn transport.PacketConn, opts ...ServerOption) *Ser
 	// activation a sender that applies the session's encryption and
 	// authentication exactly like command responses.
 	b.SOL.SetSenderFactory(func(sess *bmc.Session, inst *bmc.SOLInstance) bmc.SOLSendFunc {
+		s.metrics.solActivated()
 		if s.solDebug {
 			// Console lifecycle events (reconnect) ride the same trace
 			// facility as the packet lines.
@@ -192,19 +236,54 @@ func NewServer(b *bmc.BMC, conn transport.PacketConn, opts ...ServerOption) *Ser
 				fmt.Fprintf(os.Stderr, "%s sol> sess=%x seq=%d ack=%d accept=%d nack=%v ctrl=%#02x data=%q (async %s)\n",
 					solStamp(), sess.BMCID, pkt.SequenceNumber, pkt.AckedSequenceNumber, pkt.AcceptedCharacterCount, pkt.NACK, pkt.ControlByte, pkt.CharacterData, addr)
 			}
+			s.metrics.solBytes(0, len(pkt.CharacterData))
 			s.respondInSession(addr, sess, srvPayloadSOL, inst.OutboundEncrypted(), pkt.Pack())
 			return nil
 		}
 	})
+	// Sessions evicted for inactivity or capacity, whether by the periodic
+	// scan or by a new session's allocation, and those discarded after a
+	// refused handshake, are counted and audited as they go.
+	b.Sessions.SetOnEvict(func(ev bmc.Eviction) {
+		s.metrics.evicted(protocolRMCPPlus, ev.Reason)
+		s.auditEvicted(ev)
+	})
+	b.V15Sessions.SetOnEvict(func(ev bmc.Eviction) {
+		s.metrics.evicted(protocolV15, ev.Reason)
+		s.auditEvicted(ev)
+	})
+	// LAN alerts leave from the IPMI port, so a PET Acknowledge sent back
+	// to the trap's source address reaches this server (§30.8).
+	b.Alerts.SetSender(func(ctx context.Context, ip [4]byte, trap []byte) error {
+		_, err := s.conn.WriteTo(trap, &net.UDPAddr{IP: net.IP(ip[:]), Port: s.trapPort})
+		return err
+	})
+	// A message system software sends to a LAN session with Send Message
+	// (§6.13) is pushed into the session like a command response, protected
+	// the way the session protects its own traffic.
+	b.Messages.SetDeliverer(func(_ context.Context, channel, handle uint8, msg []byte) error {
+		sess, err := b.Sessions.GetByHandle(handle)
+		if err != nil {
+			return err
+		}
+		if sess.Channel != channel {
+			return fmt.Errorf("session handle 0x%02x on channel %d: %w", handle, channel, bmc.ErrNoSession)
+		}
+		s.respondInSession(sess.GetAddr(), sess, srvPayloadIPMI, sess.CryptAlg != types.CryptAlg_None, msg)
+		return nil
+	})
 	return s
 }
 
 // newDefaultRegistry builds a [handlers.Registry] populated with every standard
-// command handler. It is the registry each server frontend uses unless the
-// caller overrides it, so the RMCP+ and VM protocol frontends dispatch through
-// an identical command set.
-func newDefaultRegistry() *handlers.Registry {
+// command handler, wrapped in middleware. It is the registry each server
+// frontend uses unless the caller overrides it, so the RMCP+ and VM protocol
+// frontends dispatch through an identical command set.
+func newDefaultRegistry(middleware ...handlers.Middleware) *handlers.Registry {
 	reg := handlers.NewRegistry()
+	for _, m := range middleware {
+		reg.Use(m)
+	}
 	handlers.RegisterAllHandlers(reg)
 	return reg
 }
@@ -212,9 +291,14 @@ func newDefaultRegistry() *handlers.Registry {
 // Serve reads packets from the transport and dispatches them until ctx is
 // cancelled or [Server.Close] is called.
 func (s *Server) Serve(ctx context.Context) error {
-	evictCtx, evictCancel := context.WithCancel(ctx)
-	defer evictCancel()
-	go s.runSessionEviction(evictCtx)
+	bgCtx, bgCancel := context.WithCancel(ctx)
+	defer bgCancel()
+	// The BMC keeps time for as long as the server serves (and for as long
+	// as any other frontend sharing it does).
+	if s.bmc != nil {
+		go s.bmc.Run(bgCtx)
+	}
+	go s.runSessionEviction(bgCtx)
 
 	buf := make([]byte, s.bufSize)
 	for {
@@ -239,7 +323,11 @@ func (s *Server) Serve(ctx context.Context) error {
 		// happens from per-packet goroutines, and a keystroke burst handed
 		// to the queue in scheduler order would reach the console garbled.
 		if sessionID, ok := solSessionPacket(pkt); ok {
-			s.enqueueSOL(sessionID, addr, pkt)
+			if s.lanChannelAvailable(ctx) {
+				s.enqueueSOL(sessionID, addr, pkt)
+			} else {
+				s.metrics.drop(DropChannelUnavailable)
+			}
 			continue
 		}
 		go s.handlePacket(ctx, addr, pkt)
@@ -301,6 +389,7 @@ func (s *Server) runSessionEviction(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C():
+			// The stores' eviction hooks count what they remove.
 			if s.bmc != nil {
 				s.bmc.Sessions.EvictExpired()
 				s.bmc.V15Sessions.EvictExpired()
@@ -312,6 +401,7 @@ func (s *Server) runSessionEviction(ctx context.Context) {
 // handlePacket is the top-level packet dispatcher.
 func (s *Server) handlePacket(ctx context.Context, addr net.Addr, pkt []byte) {
 	if len(pkt) < 4 {
+		s.metrics.drop(DropMalformed)
 		return
 	}
 
@@ -323,12 +413,15 @@ func (s *Server) handlePacket(ctx context.Context, addr net.Addr, pkt []byte) {
 		s.handleASF(ctx, addr, pkt)
 	case 0x07: // IPMI
 		s.handleIPMI(ctx, addr, pkt)
+	default:
+		s.metrics.drop(DropMalformed)
 	}
 }
 
 // handleASF handles RMCP/ASF Presence Ping (used by ipmitool -p 623 ping).
 func (s *Server) handleASF(ctx context.Context, addr net.Addr, pkt []byte) {
 	if len(pkt) < 12 {
+		s.metrics.drop(DropMalformed)
 		return
 	}
 	msgType := pkt[8] // ASF message type
@@ -341,8 +434,13 @@ func (s *Server) handleASF(ctx context.Context, addr net.Addr, pkt []byte) {
 }
 
 // handleIPMI routes a raw IPMI-class RMCP packet.
-func (s *Server) handleIPMI(_ context.Context, addr net.Addr, pkt []byte) {
+func (s *Server) handleIPMI(ctx context.Context, addr net.Addr, pkt []byte) {
 	if len(pkt) < 5 {
+		s.metrics.drop(DropMalformed)
+		return
+	}
+	if !s.lanChannelAvailable(ctx) {
+		s.metrics.drop(DropChannelUnavailable)
 		return
 	}
 
@@ -355,10 +453,28 @@ func (s *Server) handleIPMI(_ context.Context, addr net.Addr, pkt []byte) {
 	}
 }
 
+// lanChannelAvailable reports whether the access mode of the LAN channel
+// lets it be used now. A disabled channel, or a pre-boot only one while the
+// system is powered on, is silent on the network: IPMI packets, sessions
+// already running included, are dropped without a response, as a real BMC
+// does. RMCP presence pings are still answered.
+func (s *Server) lanChannelAvailable(ctx context.Context) bool {
+	if s.bmc == nil {
+		return true
+	}
+	ch, err := s.bmc.Channels.Get(lanChannelNumber)
+	if err != nil {
+		// No channel configured to restrict.
+		return true
+	}
+	return s.bmc.ChannelAvailable(ctx, ch)
+}
+
 // handleRMCPPlus routes RMCP+ (IPMI 2.0) packets.
 func (s *Server) handleRMCPPlus(addr net.Addr, pkt []byte) {
 	sessionID, inboundSeq, payloadType, flags, payload, ok := protocol.ParseRMCPPlusHeader(pkt)
 	if !ok {
+		s.metrics.drop(DropMalformed)
 		return
 	}
 	encrypted := flags&types.PayloadFlagEncrypted != 0
@@ -372,6 +488,7 @@ func (s *Server) handleRMCPPlus(addr net.Addr, pkt []byte) {
 		if err != nil || resp == nil {
 			return
 		}
+		s.notePendingAddr(addr, resp)
 		s.sendRMCPPlus(addr, srvPayloadOpenSessionResponse, 0, resp)
 
 	case srvPayloadRAKPMessage1:
@@ -379,13 +496,18 @@ func (s *Server) handleRMCPPlus(addr net.Addr, pkt []byte) {
 		if err != nil || resp == nil {
 			return
 		}
+		s.metrics.rakp(resp, false)
+		s.auditRAKP1(ctx, addr, payload, resp)
 		s.sendRMCPPlus(addr, srvPayloadRAKPMessage2, 0, resp)
 
 	case srvPayloadRAKPMessage3:
+		pending := s.pendingHandshakeSession(payload)
 		resp, err := handlers.HandleRAKP3(ctx, s.bmc, payload)
 		if err != nil || resp == nil {
 			return
 		}
+		s.metrics.rakp(resp, true)
+		s.auditRAKP3(ctx, addr, pending, resp)
 		s.sendRMCPPlus(addr, srvPayloadRAKPMessage4, 0, resp)
 
 	case srvPayloadIPMI:
@@ -396,6 +518,7 @@ func (s *Server) handleRMCPPlus(addr net.Addr, pkt []byte) {
 		}
 		sess, err := s.bmc.Sessions.Get(sessionID)
 		if err != nil {
+			s.metrics.drop(DropUnknownSession)
 			return
 		}
 		// The seq window check and dispatch must be atomic per session:
@@ -412,6 +535,7 @@ func (s *Server) handleRMCPPlus(addr net.Addr, pkt []byte) {
 		// In-session SOL packets never reach here: the Serve loop routes
 		// them to the ordered per-session queue (see solSessionPacket).
 		// A session-ID-less SOL packet is meaningless; drop it.
+		s.metrics.drop(DropMalformed)
 		return
 	}
 }
@@ -437,6 +561,7 @@ func (s *Server) enqueueSOL(sessionID uint32, addr net.Addr, pkt []byte) {
 	select {
 	case q <- solJob{addr: addr, pkt: pkt}:
 	default:
+		s.metrics.drop(DropSOLQueueFull)
 		if s.solDebug {
 			fmt.Fprintf(os.Stderr, "%s sol! sess=%x queue full, packet dropped\n", solStamp(), sessionID)
 		}
@@ -463,6 +588,7 @@ func (s *Server) runSOLWorker(sessionID uint32, q chan solJob) {
 			idle.Reset(solWorkerIdleTimeout)
 			sess, err := s.bmc.Sessions.Get(sessionID)
 			if err != nil {
+				s.metrics.drop(DropUnknownSession)
 				// Session unknown (forged packet) or gone. Session IDs are
 				// minted only during the RAKP handshake, so an ID that is
 				// unknown now can never become valid: retiring the queue
@@ -496,9 +622,11 @@ func (s *Server) runSOLWorker(sessionID uint32, q chan solJob) {
 // under it.
 func (s *Server) acceptInbound(sess *bmc.Session, pkt []byte, addr net.Addr, inboundSeq uint32, authenticated bool) bool {
 	if !verifyRMCPPlusIntegrity(pkt, sess, authenticated) {
+		s.metrics.drop(DropIntegrity)
 		return false
 	}
 	if !bmc.InboundSeqValid(sess.InboundSeq, inboundSeq) {
+		s.metrics.drop(DropSequence)
 		return false
 	}
 	sess.InboundSeq = inboundSeq
@@ -514,6 +642,7 @@ func (s *Server) acceptInbound(sess *bmc.Session, pkt []byte, addr net.Addr, inb
 func (s *Server) processSOLJob(sess *bmc.Session, job solJob, q chan solJob) {
 	_, inboundSeq, _, flags, payload, ok := protocol.ParseRMCPPlusHeader(job.pkt)
 	if !ok {
+		s.metrics.drop(DropMalformed)
 		return
 	}
 	encrypted := flags&types.PayloadFlagEncrypted != 0
@@ -555,14 +684,25 @@ func drainSOLQueue(q chan solJob) int {
 	}
 }
 
+// dispatch runs one command through the registry, recording it and its
+// handler latency in the server's metrics. Handler errors are for middleware;
+// the completion code is what goes on the wire.
+func (s *Server) dispatch(ctx context.Context, hctx *handlers.HandlerContext, netFn, cmd uint8, data []byte) ([]byte, types.CompletionCode) {
+	start := time.Now()
+	resp, cc, _ := s.reg.Dispatch(ctx, hctx, netFn, cmd, data)
+	s.metrics.command(hctx.Command, cc, time.Since(start))
+	return resp, cc
+}
+
 // dispatchIPMIPreSession handles IPMI commands that arrive before a session exists.
 func (s *Server) dispatchIPMIPreSession(ctx context.Context, addr net.Addr, payload []byte) {
 	netFn, cmd, data, seq, ok := protocol.ParseIPMIRequest(payload)
 	if !ok {
+		s.metrics.drop(DropMalformed)
 		return
 	}
-	hctx := &handlers.HandlerContext{BMC: s.bmc}
-	respData, cc, _ := s.reg.Dispatch(ctx, hctx, netFn, cmd, data)
+	hctx := &handlers.HandlerContext{BMC: s.bmc, LUN: protocol.RequestLUN(payload), RemoteAddr: addr}
+	respData, cc := s.dispatch(ctx, hctx, netFn, cmd, data)
 	resp := protocol.BuildIPMIResponse(netFn, cmd, seq, uint8(cc), respData)
 	s.sendRMCPPlus(addr, srvPayloadIPMI, 0, resp)
 }
@@ -573,22 +713,27 @@ func (s *Server) dispatchIPMIPreSession(ctx context.Context, addr net.Addr, payl
 func (s *Server) dispatchIPMISession(ctx context.Context, addr net.Addr, sess *bmc.Session, payload []byte, encrypted bool) {
 	ipmiPayload, ok := decryptSessionPayload(sess, payload, encrypted)
 	if !ok {
+		s.metrics.drop(DropDecrypt)
 		return
 	}
 
 	netFn, cmd, data, seq, ok := protocol.ParseIPMIRequest(ipmiPayload)
 	if !ok {
+		s.metrics.drop(DropMalformed)
 		return
 	}
 
 	ch, _ := s.bmc.Channels.Get(sess.Channel)
 	hctx := &handlers.HandlerContext{
-		BMC:     s.bmc,
-		Session: sess,
-		Channel: ch,
-		User:    sess.User,
-	}
-	respData, cc, _ := s.reg.Dispatch(ctx, hctx, netFn, cmd, data)
+		BMC:        s.bmc,
+		Session:    sess,
+		SessionID:  sess.BMCID,
+		RemoteAddr: addr,
+		LUN:        protocol.RequestLUN(ipmiPayload),
+		Channel:    ch,
+		User:       sess.User,
+	}
+	respData, cc := s.dispatch(ctx, hctx, netFn, cmd, data)
 	rawResp := protocol.BuildIPMIResponse(netFn, cmd, seq, uint8(cc), respData)
 
 	s.respondInSession(addr, sess, srvPayloadIPMI, encrypted, rawResp)
@@ -605,17 +750,21 @@ func (s *Server) dispatchIPMISession(ctx context.Context, addr net.Addr, sess *b
 func (s *Server) dispatchSOLSession(ctx context.Context, addr net.Addr, sess *bmc.Session, payload []byte, encrypted bool) (flushed bool) {
 	inst := s.bmc.SOL.InstanceBySession(sess.BMCID)
 	if inst == nil {
+		s.metrics.drop(DropSOLInactive)
 		return false
 	}
 
 	plain, ok := decryptSessionPayload(sess, payload, encrypted)
 	if !ok {
+		s.metrics.drop(DropDecrypt)
 		return false
 	}
 	var in types.SOLPayloadPacket
 	if err := in.Unpack(plain); err != nil {
+		s.metrics.drop(DropMalformed)
 		return false
 	}
+	s.metrics.solBytes(len(in.CharacterData), 0)
 	flushed = in.ControlByte&0x02 != 0
 	if s.solDebug {
 		fmt.Fprintf(os.Stderr, "%s sol< sess=%x seq=%d ack=%d accept=%d nack=%v ctrl=%#02x data=%q\n",
@@ -625,6 +774,7 @@ func (s *Server) dispatchSOLSession(ctx context.Context, addr net.Addr, sess *bm
 	if out == nil {
 		return flushed
 	}
+	s.metrics.solBytes(0, len(out.CharacterData))
 	if s.solDebug {
 		fmt.Fprintf(os.Stderr, "%s sol> sess=%x seq=%d ack=%d accept=%d nack=%v ctrl=%#02x data=%q\n",
 			solStamp(), sess.BMCID, out.SequenceNumber, out.AckedSequenceNumber, out.AcceptedCharacterCount, out.NACK, out.ControlByte, out.CharacterData)
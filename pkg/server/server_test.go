@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"net"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/bougou/go-ipmi/pkg/bmc"
 	"github.com/bougou/go-ipmi/pkg/hal/mock"
 	"github.com/bougou/go-ipmi/pkg/protocol"
+	"github.com/bougou/go-ipmi/pkg/transport/udp"
 	"github.com/bougou/go-ipmi/pkg/types"
 )
 
@@ -66,3 +68,91 @@ func TestSOLSessionPacketRejectsV15(t *testing.T) {
 		t.Fatalf("RMCP+ SOL packet: ok=%v id=%#x, want true/0x1234", ok, id)
 	}
 }
+
+// TestAlertsReachTrapReceiver verifies LAN alerts leave through the server's
+// socket as PET traps addressed to the configured trap port.
+func TestAlertsReachTrapReceiver(t *testing.T) {
+	receiver, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer receiver.Close()
+	conn, err := udp.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	b := bmc.New(bmc.DeviceInfo{}, [16]byte{}, mock.New())
+	NewServer(b, conn, WithPETTrapPort(receiver.LocalAddr().(*net.UDPAddr).Port))
+	if err := b.Alerts.SetDestination(1, bmc.LANAlertDestination{IP: [4]byte{127, 0, 0, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	ev := bmc.AlertEvent{Event: bmc.PlatformEvent{SensorType: types.SensorTypeTemperature, SensorNumber: 7}}
+	if err := b.Alerts.Immediate(context.Background(), 1, 1, ev); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = receiver.SetReadDeadline(time.Now().Add(3 * time.Second))
+	buf := make([]byte, 1024)
+	n, from, err := receiver.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from.Port != conn.LocalAddr().(*net.UDPAddr).Port {
+		t.Fatalf("trap sent from port %d, want the IPMI port %d", from.Port, conn.LocalAddr().(*net.UDPAddr).Port)
+	}
+	var trap types.PlatformEventTrap
+	if err := trap.Unpack(buf[:n]); err != nil {
+		t.Fatal(err)
+	}
+	if trap.Community != bmc.DefaultCommunityString || trap.SensorNumber != 7 || trap.SensorType != types.SensorTypeTemperature {
+		t.Fatalf("trap: %+v", trap)
+	}
+	if st := b.Alerts.ImmediateStatus(1); st != bmc.AlertStatusNormalEnd {
+		t.Fatalf("status: want normal end, got %#02x", st)
+	}
+}
+
+// TestServeAppliesRestorePolicy verifies starting the server powers up a
+// chassis whose restore policy is always-on and records the restart cause.
+func TestServeAppliesRestorePolicy(t *testing.T) {
+	conn, err := udp.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	m := mock.New()
+	b := bmc.New(bmc.DeviceInfo{}, [16]byte{}, m)
+	if err := b.Chassis.SetRestorePolicy(bmc.PowerRestoreAlwaysOn); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(b, conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = s.Serve(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		conn.Close()
+		<-done
+	}()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if cause, _ := b.Chassis.RestartCause(); cause == bmc.RestartCauseAlwaysRestore {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("restore policy not applied")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if on, _ := m.Chassis().PowerState(context.Background()); !on {
+		t.Fatal("chassis not powered up")
+	}
+}
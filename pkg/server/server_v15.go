@@ -13,16 +13,19 @@ import (
 // handleIPMIv15 dispatches IPMI v1.5 LAN packets (AuthType != 0x06).
 func (s *Server) handleIPMIv15(addr net.Addr, pkt []byte) {
 	if len(pkt) < 14 {
+		s.metrics.drop(DropMalformed)
 		return
 	}
 
 	var sess types.Session15
 	if err := sess.Unpack(pkt[4:]); err != nil {
+		s.metrics.drop(DropMalformed)
 		return
 	}
 	hdr := sess.SessionHeader15
 
 	if hdr.AuthType != types.AuthTypeNone && (s.bmc == nil || !s.bmc.V15LANEnabled()) {
+		s.metrics.drop(DropPolicy)
 		return
 	}
 
@@ -36,19 +39,20 @@ func (s *Server) handleIPMIv15(addr net.Addr, pkt []byte) {
 	case types.AuthTypeMD2, types.AuthTypeMD5, types.AuthTypePassword:
 		s.dispatchIPMIv15Auth(addr, pkt, &sess)
 	default:
-		return
+		s.metrics.drop(DropMalformed)
 	}
 }
 
 func (s *Server) dispatchIPMIv15UnAuth(addr net.Addr, pkt []byte, sess *types.Session15) {
 	netFn, cmd, data, seq, ok := protocol.ParseIPMIRequest(sess.Payload)
 	if !ok {
+		s.metrics.drop(DropMalformed)
 		return
 	}
 
 	ctx := context.Background()
-	hctx := &handlers.HandlerContext{BMC: s.bmc}
-	respData, cc, _ := s.reg.Dispatch(ctx, hctx, netFn, cmd, data)
+	hctx := &handlers.HandlerContext{BMC: s.bmc, LUN: protocol.RequestLUN(sess.Payload), RemoteAddr: addr}
+	respData, cc := s.dispatch(ctx, hctx, netFn, cmd, data)
 
 	ipmiResp := protocol.BuildIPMIResponse(netFn, cmd, seq, uint8(cc), respData)
 	s.sendIPMIv15UnAuth(addr, pkt, ipmiResp)
@@ -60,11 +64,13 @@ func (s *Server) dispatchIPMIv15SessionUnauth(addr net.Addr, pkt []byte, sess *t
 	hdr := sess.SessionHeader15
 	netFn, cmd, data, seq, ok := protocol.ParseIPMIRequest(sess.Payload)
 	if !ok {
+		s.metrics.drop(DropMalformed)
 		return
 	}
 
 	v15Sess, err := s.bmc.V15Sessions.Get(hdr.SessionID)
 	if err != nil {
+		s.metrics.drop(DropUnknownSession)
 		return
 	}
 	// The seq window check and dispatch must be atomic per session: packets of
@@ -72,26 +78,32 @@ func (s *Server) dispatchIPMIv15SessionUnauth(addr net.Addr, pkt []byte, sess *t
 	v15Sess.ProcMu.Lock()
 	defer v15Sess.ProcMu.Unlock()
 	if v15Sess.State != bmc.V15SessionStateActive {
+		s.metrics.drop(DropUnknownSession)
 		return
 	}
 
 	ch, _ := s.bmc.Channels.Get(v15Sess.Channel)
 	if !handlers.V15AllowsAuthTypeNone(ch, netFn, cmd, v15Sess) {
+		s.metrics.drop(DropPolicy)
 		return
 	}
 	if !v15Sess.TryAcceptInboundSeq(hdr.Sequence) {
+		s.metrics.drop(DropSequence)
 		return
 	}
 
 	hctx := &handlers.HandlerContext{
 		BMC:        s.bmc,
 		V15Session: v15Sess,
+		SessionID:  hdr.SessionID,
+		RemoteAddr: addr,
+		LUN:        protocol.RequestLUN(sess.Payload),
 		Channel:    ch,
 		User:       v15Sess.User,
 	}
 
 	ctx := context.Background()
-	respData, cc, _ := s.reg.Dispatch(ctx, hctx, netFn, cmd, data)
+	respData, cc := s.dispatch(ctx, hctx, netFn, cmd, data)
 	s.bmc.V15Sessions.Touch(hdr.SessionID)
 
 	ipmiResp := protocol.BuildIPMIResponse(netFn, cmd, seq, uint8(cc), respData)
@@ -103,11 +115,13 @@ func (s *Server) dispatchIPMIv15Auth(addr net.Addr, pkt []byte, sess *types.Sess
 	hdr := sess.SessionHeader15
 	netFn, cmd, data, seq, ok := protocol.ParseIPMIRequest(sess.Payload)
 	if !ok {
+		s.metrics.drop(DropMalformed)
 		return
 	}
 
 	v15Sess, err := s.bmc.V15Sessions.Get(hdr.SessionID)
 	if err != nil {
+		s.metrics.drop(DropUnknownSession)
 		return
 	}
 	// Hold ProcMu across seq validate/accept, auth verify, dispatch (which may
@@ -128,7 +142,10 @@ func (s *Server) dispatchIPMIv15Auth(addr net.Addr, pkt []byte, sess *types.Sess
 	// surfaces here, at authentication.
 	if !handlers.V15Usable(v15Sess.User) {
 		if v15Sess.State == bmc.V15SessionStatePending && cmd == handlers.CmdActivateSession {
+			s.auditV15Refused(addr, v15Sess, data, "20-byte password cannot authenticate over IPMI v1.5")
 			s.sendIPMIv15CommandCC(addr, pkt, v15Sess, netFn, cmd, seq, handlers.CCV15InvalidSessionID, false)
+		} else {
+			s.metrics.drop(DropPolicy)
 		}
 		return
 	}
@@ -136,7 +153,10 @@ func (s *Server) dispatchIPMIv15Auth(addr net.Addr, pkt []byte, sess *types.Sess
 	authType := bmc.V15AuthType(hdr.AuthType)
 	if authType != v15Sess.AuthType {
 		if v15Sess.State == bmc.V15SessionStatePending && cmd == handlers.CmdActivateSession {
+			s.auditV15Refused(addr, v15Sess, data, "authentication type mismatch")
 			s.sendIPMIv15CommandCC(addr, pkt, v15Sess, netFn, cmd, seq, handlers.CCV15InvalidSessionID, true)
+		} else {
+			s.metrics.drop(DropPolicy)
 		}
 		return
 	}
@@ -146,10 +166,8 @@ func (s *Server) dispatchIPMIv15Auth(addr net.Addr, pkt []byte, sess *types.Sess
 	pendingActivate := v15Sess.State == bmc.V15SessionStatePending
 
 	if pendingActivate {
-		if cmd != handlers.CmdActivateSession {
-			return
-		}
-		if hdr.Sequence != 0 {
+		if cmd != handlers.CmdActivateSession || hdr.Sequence != 0 {
+			s.metrics.drop(DropPolicy)
 			return
 		}
 		lookupID = v15Sess.TempSessionID
@@ -161,17 +179,23 @@ func (s *Server) dispatchIPMIv15Auth(addr net.Addr, pkt []byte, sess *types.Sess
 		if !bmc.V15InboundSeqValid(v15Sess, hdr.Sequence) {
 			if cmd == handlers.CmdActivateSession {
 				s.sendIPMIv15CommandCC(addr, pkt, v15Sess, netFn, cmd, seq, handlers.CCV15SeqOutOfRange, true)
+			} else {
+				s.metrics.drop(DropSequence)
 			}
 			return
 		}
 	} else {
+		s.metrics.drop(DropUnknownSession)
 		return
 	}
 
 	password := v15Sess.User.PasswordV15Padded()
 	if !handlers.VerifyV15AuthCode(password, authType, lookupID, sess.Payload, sessionSeq, hdr.AuthCode) {
 		if pendingActivate {
+			s.auditV15Refused(addr, v15Sess, data, "invalid AuthCode")
 			s.sendIPMIv15CommandCC(addr, pkt, v15Sess, netFn, cmd, seq, handlers.CCV15InvalidSessionID, true)
+		} else {
+			s.metrics.drop(DropIntegrity)
 		}
 		return
 	}
@@ -180,6 +204,7 @@ func (s *Server) dispatchIPMIv15Auth(addr net.Addr, pkt []byte, sess *types.Sess
 	if !pendingActivate {
 		if !v15Sess.TryAcceptInboundSeq(hdr.Sequence) {
 			// Lost a race (e.g. duplicate accepted concurrently); drop.
+			s.metrics.drop(DropSequence)
 			return
 		}
 	}
@@ -188,12 +213,15 @@ func (s *Server) dispatchIPMIv15Auth(addr net.Addr, pkt []byte, sess *types.Sess
 	hctx := &handlers.HandlerContext{
 		BMC:        s.bmc,
 		V15Session: v15Sess,
+		SessionID:  hdr.SessionID,
+		RemoteAddr: addr,
+		LUN:        protocol.RequestLUN(sess.Payload),
 		Channel:    ch,
 		User:       v15Sess.User,
 	}
 
 	ctx := context.Background()
-	respData, cc, _ := s.reg.Dispatch(ctx, hctx, netFn, cmd, data)
+	respData, cc := s.dispatch(ctx, hctx, netFn, cmd, data)
 	// For a successful Activate Session this lookup misses, because the store
 	// just re-keyed the session to its permanent ID; that is fine, activation
 	// itself stamps the activity.
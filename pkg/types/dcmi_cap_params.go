@@ -104,7 +104,13 @@ func (param *DCMICapParam_SupportedDCMICapabilities) DCMICapParameter() DCMICapP
 }
 
 func (param *DCMICapParam_SupportedDCMICapabilities) Pack() []byte {
-	return []byte{}
+	out := make([]byte, 3)
+	// byte 0 is reserved
+	out[1] = SetOrClearBit0(out[1], param.SupportPowerManagement)
+	out[2] = SetOrClearBit0(out[2], param.SupportInBandKCS)
+	out[2] = SetOrClearBit1(out[2], param.SupportOutOfBandSerial)
+	out[2] = SetOrClearBit2(out[2], param.SupportOutOfBandLAN)
+	return out
 }
 
 func (param *DCMICapParam_SupportedDCMICapabilities) Unpack(paramData []byte) error {
@@ -1050,9 +1050,9 @@ func (param *LanConfigParam_AlertDestinationAddress) Unpack(data []byte) error {
 		if len(data) < 18 {
 			return ErrUnpackedDataTooShortWith(len(data), 18)
 		}
-		param.IPv6 = net.IP(data[3:18])
+		param.IPv6 = net.IP(data[2:18])
 	} else {
-		param.UseBackupGateway = IsBit7Set(data[2])
+		param.UseBackupGateway = IsBit0Set(data[2])
 		param.IPv4 = net.IP(data[3:7])
 		param.MAC = net.HardwareAddr(data[7:13])
 
@@ -1062,7 +1062,20 @@ func (param *LanConfigParam_AlertDestinationAddress) Unpack(data []byte) error {
 }
 
 func (param *LanConfigParam_AlertDestinationAddress) Pack() []byte {
-	out := make([]byte, 2)
+	if param.IsIPv6 {
+		out := make([]byte, 18)
+		out[0] = param.SetSelector
+		out[1] = 0x10 // address format: IPv6
+		copy(out[2:18], param.IPv6.To16())
+		return out
+	}
+
+	out := make([]byte, 13)
+	out[0] = param.SetSelector
+	out[1] = 0x00 // address format: IPv4 IP address followed by MAC address
+	out[2] = SetOrClearBit0(0, param.UseBackupGateway)
+	copy(out[3:7], param.IPv4.To4())
+	copy(out[7:13], param.MAC)
 
 	return out
 }
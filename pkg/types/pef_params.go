@@ -440,7 +440,7 @@ func (param *PEFConfigParam_EventFilter) Unpack(data []byte) error {
 
 func (param *PEFConfigParam_EventFilter) Pack() []byte {
 	entryData := param.Filter.Pack()
-	out := make([]byte, len(entryData))
+	out := make([]byte, 1+len(entryData))
 
 	out[0] = param.SetSelector
 	PackBytes(entryData, out, 1)
@@ -482,7 +482,7 @@ func (param *PEFConfigParam_EventFilterData1) PEFConfigParameter() (paramSelecto
 
 func (param *PEFConfigParam_EventFilterData1) Unpack(data []byte) error {
 	if len(data) < 2 {
-		return ErrUnpackedDataTooShortWith(len(data), 21)
+		return ErrUnpackedDataTooShortWith(len(data), 2)
 	}
 
 	param.SetSelector = data[0]
@@ -495,7 +495,7 @@ func (param *PEFConfigParam_EventFilterData1) Unpack(data []byte) error {
 }
 
 func (param *PEFConfigParam_EventFilterData1) Pack() []byte {
-	out := make([]byte, 21)
+	out := make([]byte, 2)
 
 	out[0] = param.SetSelector
 
@@ -0,0 +1,325 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Platform Event Trap (PET) format.
+//
+// LAN alerts are sent as SNMPv1 Trap-PDUs in the Platform Event Trap
+// format (IPMI v2.0 §17.16, PET v1.0 spec). The enterprise is the "Wired for
+// Management" PET OID and the single variable binding carries the PET data:
+//
+//	[0:16]  System GUID
+//	[16:18] Sequence Number / Cookie
+//	[18:22] Local Timestamp, seconds since 1998-01-01 00:00:00 UTC
+//	[22:24] UTC Offset in minutes, FFFFh = unspecified
+//	[24]    Trap Source Type
+//	[25]    Event Source Type
+//	[26]    Event Severity
+//	[27]    Sensor Device
+//	[28]    Sensor Number
+//	[29]    Entity
+//	[30]    Entity Instance
+//	[31:39] Event Data 1 - 8
+//	[39]    Language Code
+//	[40:44] Manufacturer ID
+//	[44:46] System ID
+//	[46]    C1h, no more OEM custom fields
+//
+// Multi-byte fields are MS-byte first.
+
+const (
+	// PETTrapPort is the standard SNMP trap port PET alerts are sent to.
+	PETTrapPort = 162
+
+	// PETTrapSourceBMC and PETEventSourceIPMI are the trap and event source
+	// types of PET alerts sent by a BMC for IPMI events.
+	PETTrapSourceBMC   uint8 = 0x20
+	PETEventSourceIPMI uint8 = 0x20
+
+	// PETLanguageEnglish is the PET language code for English.
+	PETLanguageEnglish uint8 = 0x19
+
+	// PETUTCOffsetUnspecified is the UTC offset of a BMC that does not know
+	// its time zone.
+	PETUTCOffsetUnspecified uint16 = 0xffff
+
+	petDataSize       = 47
+	petNoMoreFields   = 0xc1
+	snmpVersion1      = 0
+	snmpGenericTrapES = 6 // enterpriseSpecific
+
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berOID         = 0x06
+	berSequence    = 0x30
+	berIPAddress   = 0x40
+	berTimeTicks   = 0x43
+	berTrapPDU     = 0xa4
+)
+
+// petEpoch is the start of the PET Local Timestamp.
+var petEpoch = time.Date(1998, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	// petEnterpriseOID is 1.3.6.1.4.1.3183.1.1, the PET enterprise.
+	petEnterpriseOID = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x98, 0x6f, 0x01, 0x01}
+	// petVarBindOID is 1.3.6.1.4.1.3183.1.1.1, the PET data variable.
+	petVarBindOID = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x98, 0x6f, 0x01, 0x01, 0x01}
+)
+
+var errPETMalformed = errors.New("malformed PET trap")
+
+// PlatformEventTrap is an IPMI Platform Event Trap together with the SNMPv1
+// Trap-PDU fields it is sent in.
+type PlatformEventTrap struct {
+	Community string
+	AgentAddr [4]byte
+	// Uptime is the agent's sysUpTime in hundredths of a second.
+	Uptime uint32
+
+	// SensorType, EventReadingType, EventDir and the event offset (event
+	// data 1 [3:0]) make up the specific trap number.
+	SensorType       SensorType
+	EventReadingType EventReadingType
+	EventDir         EventDir
+
+	GUID            [16]byte
+	Sequence        uint16
+	LocalTimestamp  uint32
+	UTCOffset       uint16
+	TrapSourceType  uint8
+	EventSourceType uint8
+	Severity        PEFEventSeverity
+	SensorDevice    uint8
+	SensorNumber    uint8
+	Entity          uint8
+	EntityInstance  uint8
+	// EventData holds event data 1 to 8; IPMI events fill the first three
+	// and leave the rest FFh.
+	EventData    [8]byte
+	LanguageCode uint8
+	// ManufacturerID and SystemID identify the system: the IANA
+	// manufacturer ID and the product ID from Get Device ID.
+	ManufacturerID uint32
+	SystemID       uint16
+}
+
+// PETTimestamp converts t to a PET Local Timestamp. Times before the PET
+// epoch read as 0, unspecified.
+func PETTimestamp(t time.Time) uint32 {
+	if t.Before(petEpoch) {
+		return 0
+	}
+	return uint32(t.Sub(petEpoch) / time.Second)
+}
+
+// SpecificTrap returns the specific trap number (PET v1.0 §3.2):
+// [23:16] sensor type, [15:8] event type, [7] event direction (1b =
+// deassertion) and [3:0] event offset.
+func (trap *PlatformEventTrap) SpecificTrap() uint32 {
+	specific := uint32(trap.SensorType)<<16 | uint32(trap.EventReadingType)<<8 | uint32(trap.EventData[0]&0x0f)
+	if trap.EventDir == EventDirDeassertion {
+		specific |= 0x80
+	}
+	return specific
+}
+
+// Pack encodes the trap as an SNMPv1 message.
+func (trap *PlatformEventTrap) Pack() []byte {
+	data := make([]byte, petDataSize)
+	copy(data[0:16], trap.GUID[:])
+	PackUint16(trap.Sequence, data, 16)
+	PackUint32(trap.LocalTimestamp, data, 18)
+	PackUint16(trap.UTCOffset, data, 22)
+	data[24] = trap.TrapSourceType
+	data[25] = trap.EventSourceType
+	data[26] = uint8(trap.Severity)
+	data[27] = trap.SensorDevice
+	data[28] = trap.SensorNumber
+	data[29] = trap.Entity
+	data[30] = trap.EntityInstance
+	copy(data[31:39], trap.EventData[:])
+	data[39] = trap.LanguageCode
+	PackUint32(trap.ManufacturerID, data, 40)
+	PackUint16(trap.SystemID, data, 44)
+	data[46] = petNoMoreFields
+
+	varBind := berTLV(berSequence, berTLV(berOID, petVarBindOID), berTLV(berOctetString, data))
+	pdu := berTLV(berTrapPDU,
+		berTLV(berOID, petEnterpriseOID),
+		berTLV(berIPAddress, trap.AgentAddr[:]),
+		berTLV(berInteger, berUint(snmpGenericTrapES)),
+		berTLV(berInteger, berUint(trap.SpecificTrap())),
+		berTLV(berTimeTicks, berUint(trap.Uptime)),
+		berTLV(berSequence, varBind),
+	)
+	return berTLV(berSequence,
+		berTLV(berInteger, berUint(snmpVersion1)),
+		berTLV(berOctetString, []byte(trap.Community)),
+		pdu,
+	)
+}
+
+// Unpack decodes an SNMPv1 message carrying a Platform Event Trap.
+func (trap *PlatformEventTrap) Unpack(msg []byte) error {
+	msgBody, _, err := berNext(msg, berSequence)
+	if err != nil {
+		return err
+	}
+	version, rest, err := berNext(msgBody, berInteger)
+	if err != nil {
+		return err
+	}
+	if berValue(version) != snmpVersion1 {
+		return fmt.Errorf("%w: SNMP version %d", errPETMalformed, berValue(version))
+	}
+	community, rest, err := berNext(rest, berOctetString)
+	if err != nil {
+		return err
+	}
+	pdu, _, err := berNext(rest, berTrapPDU)
+	if err != nil {
+		return err
+	}
+
+	enterprise, pdu, err := berNext(pdu, berOID)
+	if err != nil {
+		return err
+	}
+	if !isByteSliceEqual(enterprise, petEnterpriseOID) {
+		return fmt.Errorf("%w: enterprise is not PET", errPETMalformed)
+	}
+	agent, pdu, err := berNext(pdu, berIPAddress)
+	if err != nil {
+		return err
+	}
+	if len(agent) != 4 {
+		return fmt.Errorf("%w: agent address", errPETMalformed)
+	}
+	_, pdu, err = berNext(pdu, berInteger) // generic trap
+	if err != nil {
+		return err
+	}
+	specific, pdu, err := berNext(pdu, berInteger)
+	if err != nil {
+		return err
+	}
+	uptime, pdu, err := berNext(pdu, berTimeTicks)
+	if err != nil {
+		return err
+	}
+	varBinds, _, err := berNext(pdu, berSequence)
+	if err != nil {
+		return err
+	}
+	varBind, _, err := berNext(varBinds, berSequence)
+	if err != nil {
+		return err
+	}
+	_, varBind, err = berNext(varBind, berOID)
+	if err != nil {
+		return err
+	}
+	data, _, err := berNext(varBind, berOctetString)
+	if err != nil {
+		return err
+	}
+	if len(data) < petDataSize-1 {
+		return ErrUnpackedDataTooShortWith(len(data), petDataSize-1)
+	}
+
+	trap.Community = string(community)
+	copy(trap.AgentAddr[:], agent)
+	trap.Uptime = uint32(berValue(uptime))
+	s := berValue(specific)
+	trap.SensorType = SensorType(s >> 16)
+	trap.EventReadingType = EventReadingType(s >> 8)
+	trap.EventDir = EventDir(s&0x80 != 0)
+
+	copy(trap.GUID[:], data[0:16])
+	trap.Sequence, _, _ = UnpackUint16(data, 16)
+	trap.LocalTimestamp, _, _ = UnpackUint32(data, 18)
+	trap.UTCOffset, _, _ = UnpackUint16(data, 22)
+	trap.TrapSourceType = data[24]
+	trap.EventSourceType = data[25]
+	trap.Severity = PEFEventSeverity(data[26])
+	trap.SensorDevice = data[27]
+	trap.SensorNumber = data[28]
+	trap.Entity = data[29]
+	trap.EntityInstance = data[30]
+	copy(trap.EventData[:], data[31:39])
+	trap.LanguageCode = data[39]
+	trap.ManufacturerID, _, _ = UnpackUint32(data, 40)
+	trap.SystemID, _, _ = UnpackUint16(data, 44)
+	return nil
+}
+
+// berTLV encodes a BER tag-length-value with the concatenated contents.
+func berTLV(tag byte, contents ...[]byte) []byte {
+	var n int
+	for _, c := range contents {
+		n += len(c)
+	}
+	out := []byte{tag}
+	switch {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n <= 0xff:
+		out = append(out, 0x81, byte(n))
+	default:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	}
+	for _, c := range contents {
+		out = append(out, c...)
+	}
+	return out
+}
+
+// berUint encodes v as the contents of a non-negative BER integer.
+func berUint(v uint32) []byte {
+	out := []byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+	for len(out) > 1 && out[0] == 0 && out[1]&0x80 == 0 {
+		out = out[1:]
+	}
+	if out[0]&0x80 != 0 {
+		out = append([]byte{0}, out...)
+	}
+	return out
+}
+
+// berValue decodes the contents of a non-negative BER integer.
+func berValue(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// berNext splits the tag-length-value at the start of msg, which must
+// have the given tag, into its contents and the bytes following it.
+func berNext(msg []byte, tag byte) (contents, rest []byte, err error) {
+	if len(msg) < 2 {
+		return nil, nil, ErrUnpackedDataTooShortWith(len(msg), 2)
+	}
+	if msg[0] != tag {
+		return nil, nil, fmt.Errorf("%w: tag %#02x, want %#02x", errPETMalformed, msg[0], tag)
+	}
+	n, off := int(msg[1]), 2
+	if n&0x80 != 0 {
+		octets := n & 0x7f
+		if octets == 0 || octets > 2 || len(msg) < 2+octets {
+			return nil, nil, fmt.Errorf("%w: length", errPETMalformed)
+		}
+		n = int(berValue(msg[2 : 2+octets]))
+		off += octets
+	}
+	if len(msg) < off+n {
+		return nil, nil, ErrUnpackedDataTooShortWith(len(msg), off+n)
+	}
+	return msg[off : off+n], msg[off+n:], nil
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlatformEventTrapRoundTrip(t *testing.T) {
+	orig := PlatformEventTrap{
+		Community:        "public",
+		AgentAddr:        [4]byte{192, 168, 1, 50},
+		Uptime:           123456,
+		SensorType:       SensorTypeTemperature,
+		EventReadingType: EventReadingTypeThreshold,
+		EventDir:         EventDirDeassertion,
+		GUID:             [16]byte{0x01, 0x02, 0x03, 0x04, 15: 0xff},
+		Sequence:         0x1234,
+		LocalTimestamp:   PETTimestamp(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)),
+		UTCOffset:        PETUTCOffsetUnspecified,
+		TrapSourceType:   PETTrapSourceBMC,
+		EventSourceType:  PETEventSourceIPMI,
+		Severity:         PEFEventSeverityCritical,
+		SensorDevice:     0x20,
+		SensorNumber:     0x10,
+		EventData:        [8]byte{0x59, 95, 90, 0xff, 0xff, 0xff, 0xff, 0xff},
+		LanguageCode:     PETLanguageEnglish,
+		ManufacturerID:   0x0001c6,
+		SystemID:         0x0102,
+	}
+	if got := orig.SpecificTrap(); got != 0x010189 {
+		t.Fatalf("specific trap: want 0x010189, got %#06x", got)
+	}
+	msg := orig.Pack()
+	// SNMPv1 message: SEQUENCE { INTEGER 0, OCTET STRING community, Trap-PDU }.
+	if msg[0] != 0x30 || msg[2] != 0x02 || msg[3] != 0x01 || msg[4] != 0x00 {
+		t.Fatalf("message header: % x", msg[:8])
+	}
+	var got PlatformEventTrap
+	if err := got.Unpack(msg); err != nil {
+		t.Fatal(err)
+	}
+	if got != orig {
+		t.Fatalf("trap mismatch:\n got %+v\nwant %+v", got, orig)
+	}
+
+	if err := got.Unpack(msg[:len(msg)-10]); err == nil {
+		t.Fatal("truncated trap decoded")
+	}
+}
+
+func TestPETTimestamp(t *testing.T) {
+	if got := PETTimestamp(time.Date(1998, 1, 1, 0, 1, 0, 0, time.UTC)); got != 60 {
+		t.Fatalf("want 60, got %d", got)
+	}
+	if got := PETTimestamp(time.Unix(0, 0)); got != 0 {
+		t.Fatalf("before the PET epoch: want 0, got %d", got)
+	}
+}
@@ -304,6 +304,83 @@ func (flag *SensorEventFlag) TrueEvents() []SensorEvent {
 	return out
 }
 
+// eventMaskBits returns, for bit n of a 16-bit event mask, the fields of flag
+// that share it: the threshold event (bits 0-11 only) and discrete state n.
+// Threshold events are ordered LNC going low (bit 0) through UNR going high
+// (bit 11), as in the event mask bytes of v2.0§35.10-35.13.
+func (flag *SensorEventFlag) eventMaskBits() (assert, deassert [15][]*bool) {
+	assert = [15][]*bool{
+		{&flag.SensorEvent_LNC_Low_Assert, &flag.SensorEvent_State_0_Assert},
+		{&flag.SensorEvent_LNC_High_Assert, &flag.SensorEvent_State_1_Assert},
+		{&flag.SensorEvent_LCR_Low_Assert, &flag.SensorEvent_State_2_Assert},
+		{&flag.SensorEvent_LCR_High_Assert, &flag.SensorEvent_State_3_Assert},
+		{&flag.SensorEvent_LNR_Low_Assert, &flag.SensorEvent_State_4_Assert},
+		{&flag.SensorEvent_LNR_High_Assert, &flag.SensorEvent_State_5_Assert},
+		{&flag.SensorEvent_UNC_Low_Assert, &flag.SensorEvent_State_6_Assert},
+		{&flag.SensorEvent_UNC_High_Assert, &flag.SensorEvent_State_7_Assert},
+		{&flag.SensorEvent_UCR_Low_Assert, &flag.SensorEvent_State_8_Assert},
+		{&flag.SensorEvent_UCR_High_Assert, &flag.SensorEvent_State_9_Assert},
+		{&flag.SensorEvent_UNR_Low_Assert, &flag.SensorEvent_State_10_Assert},
+		{&flag.SensorEvent_UNR_High_Assert, &flag.SensorEvent_State_11_Assert},
+		{&flag.SensorEvent_State_12_Assert},
+		{&flag.SensorEvent_State_13_Assert},
+		{&flag.SensorEvent_State_14_Assert},
+	}
+	deassert = [15][]*bool{
+		{&flag.SensorEvent_LNC_Low_Deassert, &flag.SensorEvent_State_0_Deassert},
+		{&flag.SensorEvent_LNC_High_Deassert, &flag.SensorEvent_State_1_Deassert},
+		{&flag.SensorEvent_LCR_Low_Deassert, &flag.SensorEvent_State_2_Deassert},
+		{&flag.SensorEvent_LCR_High_Deassert, &flag.SensorEvent_State_3_Deassert},
+		{&flag.SensorEvent_LNR_Low_Deassert, &flag.SensorEvent_State_4_Deassert},
+		{&flag.SensorEvent_LNR_High_Deassert, &flag.SensorEvent_State_5_Deassert},
+		{&flag.SensorEvent_UNC_Low_Deassert, &flag.SensorEvent_State_6_Deassert},
+		{&flag.SensorEvent_UNC_High_Deassert, &flag.SensorEvent_State_7_Deassert},
+		{&flag.SensorEvent_UCR_Low_Deassert, &flag.SensorEvent_State_8_Deassert},
+		{&flag.SensorEvent_UCR_High_Deassert, &flag.SensorEvent_State_9_Deassert},
+		{&flag.SensorEvent_UNR_Low_Deassert, &flag.SensorEvent_State_10_Deassert},
+		{&flag.SensorEvent_UNR_High_Deassert, &flag.SensorEvent_State_11_Deassert},
+		{&flag.SensorEvent_State_12_Deassert},
+		{&flag.SensorEvent_State_13_Deassert},
+		{&flag.SensorEvent_State_14_Deassert},
+	}
+	return
+}
+
+// EventMasks returns flag as the 16-bit assertion and deassertion event
+// masks carried (LS byte first) by the sensor event commands. Bit n is set
+// when either the threshold event or the discrete state mapped to it is set.
+func (flag *SensorEventFlag) EventMasks() (assert, deassert uint16) {
+	assertBits, deassertBits := flag.eventMaskBits()
+	for n := range assertBits {
+		for _, f := range assertBits[n] {
+			if *f {
+				assert |= 1 << n
+			}
+		}
+		for _, f := range deassertBits[n] {
+			if *f {
+				deassert |= 1 << n
+			}
+		}
+	}
+	return
+}
+
+// SetEventMasks is the inverse of [SensorEventFlag.EventMasks]. Each mask
+// bit sets both the threshold and the discrete field that share it, the same
+// way the command responses are decoded.
+func (flag *SensorEventFlag) SetEventMasks(assert, deassert uint16) {
+	assertBits, deassertBits := flag.eventMaskBits()
+	for n := range assertBits {
+		for _, f := range assertBits[n] {
+			*f = assert&(1<<n) != 0
+		}
+		for _, f := range deassertBits[n] {
+			*f = deassert&(1<<n) != 0
+		}
+	}
+}
+
 var (
 	SensorEvent_UNC_High_Assert = SensorEvent{
 		SensorClass:   SensorClassThreshold,
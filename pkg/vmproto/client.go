@@ -15,6 +15,13 @@ type Client struct {
 	conn    net.Conn
 	timeout time.Duration
 	msgID   byte
+
+	attention  bool
+	interrupt  bool
+	irqEnabled bool
+	// controls holds the hardware control commands not yet read with
+	// [Client.ReadControl].
+	controls []ControlCommand
 }
 
 // NewClient wraps conn, a stream connection to a [VMServer] listener, as a
@@ -24,6 +31,17 @@ func NewClient(conn net.Conn, timeout time.Duration) *Client {
 	return &Client{conn: conn, timeout: timeout}
 }
 
+// Handshake announces the protocol version and caps, the control commands
+// the client takes, the way QEMU does on connect. A [VMServer] sends only the
+// hardware control commands a VM announced.
+func (c *Client) Handshake(caps Capabilities) error {
+	frame := append(vmEncodeCommand(byte(CmdVersion), ProtocolVersion), vmEncodeCommand(byte(CmdCapabilities), byte(caps))...)
+	if _, err := c.conn.Write(frame); err != nil {
+		return fmt.Errorf("write handshake: %w", err)
+	}
+	return nil
+}
+
 // Command sends one IPMI request over the VM protocol and returns the response
 // completion code and data. The system interface carries one transaction at a
 // time, so it writes the request and reads the matching response.
@@ -56,11 +74,69 @@ func (c *Client) Command(netFn, cmd byte, data ...byte) (cc byte, resp []byte, e
 	return reply[3], reply[4 : len(reply)-1], nil
 }
 
-// readMessage reads one unescaped, checksum-verified message from the stream.
+// Attention reports the SMS attention state the server last announced, and
+// whether it came with an interrupt. Announcements are taken in while a
+// response is read, so this is the state as of the last [Client.Command] or
+// [Client.ReadControl].
+func (c *Client) Attention() (attention, interrupt bool) {
+	return c.attention, c.interrupt
+}
+
+// InterruptEnabled reports whether the server last enabled the guest's
+// interrupt, as of the last [Client.Command] or [Client.ReadControl].
+func (c *Client) InterruptEnabled() bool {
+	return c.irqEnabled
+}
+
+// ReadControl returns the next hardware control command the server sent:
+// [CmdPowerOff], [CmdReset], [CmdSendNMI] or [CmdGracefulShutdown]. Those
+// that arrived while a response was read are returned first; otherwise it
+// waits for one, and an IPMI message arriving meanwhile, with no request
+// outstanding, is an error.
+func (c *Client) ReadControl() (ControlCommand, error) {
+	for len(c.controls) == 0 {
+		frame, isCommand, err := c.readFrame()
+		if err != nil {
+			return 0, err
+		}
+		if !isCommand {
+			return 0, fmt.Errorf("unexpected message: % x", frame)
+		}
+		c.command(frame)
+	}
+	cmd := c.controls[0]
+	c.controls = c.controls[1:]
+	return cmd, nil
+}
+
+// readMessage reads one unescaped, checksum-verified message from the stream,
+// taking in the control commands that arrive before it.
 func (c *Client) readMessage() ([]byte, error) {
+	for {
+		frame, isCommand, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		if isCommand {
+			c.command(frame)
+			continue
+		}
+		if len(frame) < 4 {
+			return nil, fmt.Errorf("response too short: % x", frame)
+		}
+		if vmChecksum(frame) != 0 {
+			return nil, fmt.Errorf("response checksum mismatch: % x", frame)
+		}
+		return frame, nil
+	}
+}
+
+// readFrame reads one unescaped frame from the stream, a message or, when
+// isCommand is set, a non-empty control command.
+func (c *Client) readFrame() (frame []byte, isCommand bool, err error) {
 	if c.timeout > 0 {
 		if err := c.conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
-			return nil, err
+			return nil, false, err
 		}
 	}
 
@@ -71,17 +147,16 @@ func (c *Client) readMessage() ([]byte, error) {
 	)
 	for {
 		if _, err := c.conn.Read(buf); err != nil {
-			return nil, fmt.Errorf("read response: %w", err)
+			return nil, false, fmt.Errorf("read response: %w", err)
 		}
 		switch b := buf[0]; b {
 		case vmMsgChar:
-			if len(acc) < 4 {
-				return nil, fmt.Errorf("response too short: % x", acc)
-			}
-			if vmChecksum(acc) != 0 {
-				return nil, fmt.Errorf("response checksum mismatch: % x", acc)
+			return acc, false, nil
+		case vmCmdChar:
+			if len(acc) > 0 {
+				return acc, true, nil
 			}
-			return acc, nil
+			inEscape = false
 		case vmEscapeChar:
 			inEscape = true
 		default:
@@ -93,3 +168,21 @@ func (c *Client) readMessage() ([]byte, error) {
 		}
 	}
 }
+
+// command takes in a control command from the server.
+func (c *Client) command(cmd []byte) {
+	switch ControlCommand(cmd[0]) {
+	case CmdNoAttn:
+		c.attention, c.interrupt = false, false
+	case CmdAttn:
+		c.attention, c.interrupt = true, false
+	case CmdAttnIRQ:
+		c.attention, c.interrupt = true, true
+	case CmdEnableIRQ:
+		c.irqEnabled = true
+	case CmdDisableIRQ:
+		c.irqEnabled = false
+	case CmdPowerOff, CmdReset, CmdSendNMI, CmdGracefulShutdown:
+		c.controls = append(c.controls, ControlCommand(cmd[0]))
+	}
+}
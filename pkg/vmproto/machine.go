@@ -0,0 +1,201 @@
+package vmproto
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bougou/go-ipmi/pkg/hal"
+	"github.com/bougou/go-ipmi/pkg/types"
+)
+
+// Machine is the emulated machine at the other end of a VM protocol
+// connection, as a [hal.HAL] for the [bmc.BMC] managing it. Its chassis sends
+// Chassis Control, and the watchdog's actions, to the connected QEMU as the
+// matching control command when QEMU announced the capability for it, and
+// passes every call on to the chassis of the HAL it wraps, which keeps the
+// power state and everything the protocol has no command for: power-on and
+// the boot flags among them, since a powered-off QEMU process has exited and
+// the boot device is chosen by its command line.
+//
+//	Chassis Control      control command      capability
+//	power down           POWEROFF             CapPower
+//	power cycle          RESET                CapReset
+//	hard reset           RESET                CapReset
+//	soft shutdown        GRACEFUL_SHUTDOWN    CapGracefulShutdown
+//	diagnostic interrupt SEND_NMI             CapNMI
+//
+// The chassis offers the diagnostic interrupt ([hal.DiagnosticInterruptHAL])
+// only while the connected VM announced CapNMI or the wrapped chassis has one
+// of its own, so that Get Chassis Capabilities and Redfish do not advertise
+// an action nothing would take.
+//
+// Construct one with [NewMachine], build the BMC over it and pass it to
+// [NewVMServer] with [WithMachine].
+type Machine struct {
+	hal.HAL
+
+	mu      sync.Mutex
+	w       *vmWriter // the connected VM, or nil
+	version byte
+	caps    Capabilities
+}
+
+// NewMachine wraps h, the HAL of the rest of the platform; the mock HAL stands
+// in for one that has no hardware of its own.
+func NewMachine(h hal.HAL) *Machine {
+	return &Machine{HAL: h}
+}
+
+// Chassis returns the wrapped chassis, or nil when the wrapped HAL has none.
+// It implements [hal.DiagnosticInterruptHAL] when the VM or the wrapped
+// chassis can take a diagnostic interrupt.
+func (m *Machine) Chassis() hal.ChassisHAL {
+	inner := m.HAL.Chassis()
+	if inner == nil {
+		return nil
+	}
+	c := &machineChassis{ChassisHAL: inner, m: m}
+	if _, ok := inner.(hal.DiagnosticInterruptHAL); ok || m.can(CapNMI) {
+		return &machineNMIChassis{c}
+	}
+	return c
+}
+
+// Power returns the wrapped HAL's power meter, or nil when it has none
+// ([hal.PowerMeterHAL]); the VM protocol has no power meter of its own.
+func (m *Machine) Power() hal.PowerHAL {
+	meter, ok := m.HAL.(hal.PowerMeterHAL)
+	if !ok {
+		return nil
+	}
+	return meter.Power()
+}
+
+// Connected reports whether a VM is connected, with the protocol version and
+// capabilities it announced; a VM that announced none has none.
+func (m *Machine) Connected() (connected bool, version byte, caps Capabilities) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.w != nil, m.version, m.caps
+}
+
+// attach makes w the connected VM. It has announced nothing yet.
+func (m *Machine) attach(w *vmWriter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.w, m.version, m.caps = w, 0, 0
+}
+
+// detach forgets w once its connection is closed.
+func (m *Machine) detach(w *vmWriter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.w == w {
+		m.w, m.version, m.caps = nil, 0, 0
+	}
+}
+
+// announce takes in a control command the VM sent.
+func (m *Machine) announce(w *vmWriter, cmd []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.w != w || len(cmd) < 2 {
+		return
+	}
+	switch ControlCommand(cmd[0]) {
+	case CmdVersion:
+		m.version = cmd[1]
+	case CmdCapabilities:
+		m.caps = Capabilities(cmd[1])
+	}
+}
+
+// can reports whether a VM with the capability need is connected.
+func (m *Machine) can(need Capabilities) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.w != nil && m.caps&need != 0
+}
+
+// send sends cmd to the connected VM when it has the capability need, and
+// reports whether it did.
+func (m *Machine) send(cmd ControlCommand, need Capabilities) (bool, error) {
+	m.mu.Lock()
+	w, caps := m.w, m.caps
+	m.mu.Unlock()
+	if w == nil || caps&need == 0 {
+		return false, nil
+	}
+	return true, w.write(vmEncodeCommand(byte(cmd)))
+}
+
+// machineChassis is the chassis of a [Machine].
+type machineChassis struct {
+	hal.ChassisHAL
+	m *Machine
+}
+
+func (c *machineChassis) SetPower(ctx context.Context, on bool) error {
+	if !on {
+		if _, err := c.m.send(CmdPowerOff, CapPower); err != nil {
+			return err
+		}
+	}
+	return c.ChassisHAL.SetPower(ctx, on)
+}
+
+func (c *machineChassis) PowerCycle(ctx context.Context) error {
+	if _, err := c.m.send(CmdReset, CapReset); err != nil {
+		return err
+	}
+	return c.ChassisHAL.PowerCycle(ctx)
+}
+
+func (c *machineChassis) ColdReset(ctx context.Context) error {
+	if _, err := c.m.send(CmdReset, CapReset); err != nil {
+		return err
+	}
+	return c.ChassisHAL.ColdReset(ctx)
+}
+
+func (c *machineChassis) WarmReset(ctx context.Context) error {
+	if _, err := c.m.send(CmdGracefulShutdown, CapGracefulShutdown); err != nil {
+		return err
+	}
+	return c.ChassisHAL.WarmReset(ctx)
+}
+
+// machineNMIChassis is the chassis of a [Machine] whose VM or wrapped
+// chassis can take a diagnostic interrupt.
+type machineNMIChassis struct {
+	*machineChassis
+}
+
+// DiagnosticInterrupt implements [hal.DiagnosticInterruptHAL]: the NMI goes
+// to the VM and to the wrapped chassis, whichever can take it. A VM that
+// disconnected since the chassis was handed out leaves the action
+// unsupported.
+func (c *machineNMIChassis) DiagnosticInterrupt(ctx context.Context) error {
+	sent, err := c.m.send(CmdSendNMI, CapNMI)
+	if err != nil {
+		return err
+	}
+	di, ok := c.ChassisHAL.(hal.DiagnosticInterruptHAL)
+	switch {
+	case ok:
+		return di.DiagnosticInterrupt(ctx)
+	case sent:
+		return nil
+	default:
+		return types.CodeParameterOutOfRange
+	}
+}
+
+// SetPowerCycleInterval implements [hal.PowerCycleIntervalHAL], passing the
+// interval on to the wrapped chassis when it takes one.
+func (c *machineChassis) SetPowerCycleInterval(ctx context.Context, seconds uint8) error {
+	if pi, ok := c.ChassisHAL.(hal.PowerCycleIntervalHAL); ok {
+		return pi.SetPowerCycleInterval(ctx, seconds)
+	}
+	return nil
+}
@@ -13,6 +13,16 @@
 // is the system interface, which the handler privilege check treats as locally
 // authorized.
 //
+// Control commands go both ways. QEMU announces its protocol version and
+// capabilities on connect; the BMC signals SMS attention with the ATTN
+// commands as it changes, so the guest driver runs Get Message Flags, enables
+// the guest's interrupt with ENABLE_IRQ and DISABLE_IRQ as system software
+// sets the BMC Global Enables, and, through a [Machine], delivers Chassis
+// Control as POWEROFF, RESET, GRACEFUL_SHUTDOWN and SEND_NMI, each only when
+// QEMU announced the capability for it. That is the command set of
+// OpenIPMI's ipmi_sim, so the server can stand in for it behind
+// ipmi-bmc-extern.
+//
 // [Client] is the console side of the same protocol, standing in for QEMU or
 // the in-guest OpenIPMI driver in simulation and end-to-end testing.
 package vmproto
@@ -22,6 +32,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/bougou/go-ipmi/pkg/bmc"
 	"github.com/bougou/go-ipmi/pkg/handlers"
@@ -43,6 +55,54 @@ const (
 	vmMaxMsgSize = 4096
 
 	vmReadBufferSize = 4096
+
+	// vmWriteTimeout bounds each write to the VM. A guest that stops reading
+	// would otherwise block the read loop and the goroutines telling it of
+	// SMS attention for good.
+	vmWriteTimeout = 5 * time.Second
+)
+
+// ProtocolVersion is the VM protocol version QEMU announces with
+// [CmdVersion].
+const ProtocolVersion = 1
+
+// ControlCommand is an OpenIPMI VM protocol control command, the first byte
+// of a vmCmdChar frame.
+type ControlCommand byte
+
+// Control commands. CmdVersion and CmdCapabilities go from the VM to the BMC,
+// followed by the version and the [Capabilities] byte; the rest go from the
+// BMC to the VM.
+const (
+	// CmdNoAttn, CmdAttn and CmdAttnIRQ deassert SMS attention, assert it,
+	// and assert it with an interrupt to the guest.
+	CmdNoAttn  ControlCommand = 0x00
+	CmdAttn    ControlCommand = 0x01
+	CmdAttnIRQ ControlCommand = 0x02
+
+	CmdPowerOff ControlCommand = 0x03
+	CmdReset    ControlCommand = 0x04
+	// CmdEnableIRQ and CmdDisableIRQ turn the guest's interrupt on and off.
+	CmdEnableIRQ        ControlCommand = 0x05
+	CmdDisableIRQ       ControlCommand = 0x06
+	CmdSendNMI          ControlCommand = 0x07
+	CmdCapabilities     ControlCommand = 0x08
+	CmdGracefulShutdown ControlCommand = 0x09
+
+	CmdVersion ControlCommand = 0xFF
+)
+
+// Capabilities are the control commands a VM takes, as it announces them with
+// [CmdCapabilities].
+type Capabilities byte
+
+const (
+	CapPower            Capabilities = 0x01
+	CapReset            Capabilities = 0x02
+	CapIRQ              Capabilities = 0x04
+	CapNMI              Capabilities = 0x08
+	CapAttn             Capabilities = 0x10
+	CapGracefulShutdown Capabilities = 0x20
 )
 
 // VMServer serves QEMU's OpenIPMI VM protocol on a stream listener, dispatching
@@ -54,8 +114,9 @@ const (
 // protocol is a byte stream, so it takes a [net.Listener], and the system
 // interface is unauthenticated, so messages carry no session.
 type VMServer struct {
-	bmc *bmc.BMC
-	reg *handlers.Registry
+	bmc     *bmc.BMC
+	reg     *handlers.Registry
+	machine *Machine
 }
 
 // VMServerOption configures a [VMServer].
@@ -69,6 +130,12 @@ func WithVMHandlerRegistry(r *handlers.Registry) VMServerOption {
 	return func(s *VMServer) { s.reg = r }
 }
 
+// WithMachine has the connected VM be m: m's chassis sends the hardware
+// control commands to it. Pass the [Machine] the BMC was built over.
+func WithMachine(m *Machine) VMServerOption {
+	return func(s *VMServer) { s.machine = m }
+}
+
 // NewVMServer creates a VM protocol frontend over the BMC state b.
 //
 // Share b with the [Server] created by [NewServer] so in-band (VM protocol) and
@@ -86,6 +153,11 @@ func NewVMServer(b *bmc.BMC, opts ...VMServerOption) *VMServer {
 	for _, o := range opts {
 		o(s)
 	}
+	if s.machine == nil {
+		// Only the announcements are kept: without [WithMachine] the BMC
+		// does not control the VM.
+		s.machine = NewMachine(nil)
+	}
 	return s
 }
 
@@ -106,6 +178,9 @@ func (s *VMServer) Serve(ctx context.Context, ln net.Listener) error {
 		<-ctx.Done()
 		_ = ln.Close()
 	}()
+	// The watchdog and the other timed engines run while the VM is served,
+	// with or without a LAN frontend alongside.
+	go s.bmc.Run(ctx)
 
 	for {
 		conn, err := ln.Accept()
@@ -137,6 +212,20 @@ func (s *VMServer) serveConn(ctx context.Context, conn net.Conn) {
 		_ = conn.Close()
 	}()
 
+	// SMS attention and the hardware control commands go to the guest as
+	// they happen, between responses, so writes are serialized from here on.
+	w := &vmWriter{conn: conn}
+	s.machine.attach(w)
+	defer s.machine.detach(w)
+	// The notifier is told of the state at once; the guest starts out with
+	// neither attention nor its interrupt.
+	var last bmc.AttentionState
+	s.bmc.SetAttentionNotifier(func(state bmc.AttentionState) {
+		s.sendAttention(last, state)
+		last = state
+	})
+	defer s.bmc.SetAttentionNotifier(nil)
+
 	var (
 		acc      []byte
 		inEscape bool
@@ -162,15 +251,15 @@ func (s *VMServer) serveConn(ctx context.Context, conn net.Conn) {
 					// channel leaves the context channel nil and downstream
 					// handlers fall back gracefully.
 					sysCh, _ := s.bmc.Channels.Get(systemInterfaceChannel)
-					s.handleMessage(connCtx, conn, sysCh, acc)
+					s.handleMessage(connCtx, w, sysCh, acc)
 				}
 				acc, inEscape, overflow = acc[:0], false, false
 			case vmCmdChar:
-				// Control command (QEMU's version and capability announcements
-				// on connect). These carry no BMC state, and the BMC never
-				// sends hardware control commands back: power authority stays
-				// with the hardware layer, so the frame is consumed and
-				// ignored.
+				// Control command: QEMU's version and capability
+				// announcements on connect. Anything else is not for the BMC.
+				if !inEscape && !overflow && len(acc) > 0 {
+					s.machine.announce(w, acc)
+				}
 				acc, inEscape, overflow = acc[:0], false, false
 			case vmEscapeChar:
 				inEscape = true
@@ -199,9 +288,8 @@ func (s *VMServer) serveConn(ctx context.Context, conn net.Conn) {
 // It runs synchronously on the read loop, so msg (the decoder's accumulation
 // buffer) is stable for the whole call and handlers must not retain the request
 // slice past return, per the [handlers.Handler] contract; there is no need to
-// copy it. Being synchronous also means only one write happens at a time per
-// connection, so no write lock is needed.
-func (s *VMServer) handleMessage(ctx context.Context, conn net.Conn, ch *bmc.Channel, msg []byte) {
+// copy it.
+func (s *VMServer) handleMessage(ctx context.Context, w *vmWriter, ch *bmc.Channel, msg []byte) {
 	// msgID + netfn/lun + cmd + checksum is the shortest valid message.
 	if len(msg) < 4 || vmChecksum(msg) != 0 {
 		return
@@ -213,7 +301,7 @@ func (s *VMServer) handleMessage(ctx context.Context, conn net.Conn, ch *bmc.Cha
 	cmd := msg[2]
 	data := msg[3 : len(msg)-1]
 
-	hctx := &handlers.HandlerContext{BMC: s.bmc, Channel: ch}
+	hctx := &handlers.HandlerContext{BMC: s.bmc, LUN: lun, Channel: ch}
 	respData, cc, _ := s.reg.Dispatch(ctx, hctx, netFn, cmd, data)
 
 	resp := make([]byte, 0, 4+len(respData)+1)
@@ -221,7 +309,62 @@ func (s *VMServer) handleMessage(ctx context.Context, conn net.Conn, ch *bmc.Cha
 	resp = append(resp, respData...)
 	resp = append(resp, -vmChecksum(resp))
 
-	_, _ = conn.Write(vmEncode(resp))
+	_ = w.write(vmEncode(resp))
+}
+
+// vmWriter serializes the writes to one connection: the responses of the
+// read loop and the attention changes, which come from any goroutine.
+type vmWriter struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// write sends frame within [vmWriteTimeout]. A write that fails may have
+// left a partial frame on the stream, so the connection is closed: the read
+// loop returns and QEMU reconnects.
+func (w *vmWriter) write(frame []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.conn.SetWriteDeadline(time.Now().Add(vmWriteTimeout)); err != nil {
+		_ = w.conn.Close()
+		return err
+	}
+	if _, err := w.conn.Write(frame); err != nil {
+		_ = w.conn.Close()
+		return err
+	}
+	return nil
+}
+
+// sendAttention tells the connected VM of the change from the attention
+// state old to state, as far as it announced the capabilities for: CapIRQ
+// for its interrupt and CapAttn for attention. The BMC calls its notifier
+// with one change at a time, so the changes arrive in order.
+func (s *VMServer) sendAttention(old, state bmc.AttentionState) {
+	if state.InterruptsEnabled != old.InterruptsEnabled {
+		cmd := CmdDisableIRQ
+		if state.InterruptsEnabled {
+			cmd = CmdEnableIRQ
+		}
+		_, _ = s.machine.send(cmd, CapIRQ)
+	}
+	if state.Attention != old.Attention || state.Interrupt != old.Interrupt {
+		_, _ = s.machine.send(vmAttentionCommand(state, s.machine.can(CapIRQ)), CapAttn)
+	}
+}
+
+// vmAttentionCommand returns the control command that tells the guest of the
+// SMS attention state; a guest without an interrupt (irq) is only told of
+// attention.
+func vmAttentionCommand(state bmc.AttentionState, irq bool) ControlCommand {
+	switch {
+	case state.Attention && state.Interrupt && irq:
+		return CmdAttnIRQ
+	case state.Attention:
+		return CmdAttn
+	default:
+		return CmdNoAttn
+	}
 }
 
 // vmChecksum is the IPMB two's-complement checksum: a well-formed message,
@@ -248,3 +391,11 @@ func vmEncode(bs []byte) []byte {
 	}
 	return append(out, vmMsgChar)
 }
+
+// vmEncodeCommand escapes a control command and appends the end-of-command
+// marker.
+func vmEncodeCommand(bs ...byte) []byte {
+	out := vmEncode(bs)
+	out[len(out)-1] = vmCmdChar
+	return out
+}
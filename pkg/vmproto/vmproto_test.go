@@ -4,8 +4,8 @@ package vmproto
 // that speaks the ipmi-bmc-extern codec: single-byte control commands on
 // connect, then IPMI messages framed with the 0xA0/0xA1/0xAA bytes and an IPMB
 // checksum. They prove Get Device ID succeeds, an unknown command answers 0xC1
-// without dropping the connection, the escape path round-trips, and a fresh
-// QEMU process can reconnect.
+// without dropping the connection, the escape path round-trips, a fresh QEMU
+// process can reconnect, and the BMC drives the VM with control commands.
 
 import (
 	"context"
@@ -15,7 +15,9 @@ import (
 
 	"github.com/bougou/go-ipmi/pkg/bmc"
 	"github.com/bougou/go-ipmi/pkg/clock"
+	"github.com/bougou/go-ipmi/pkg/hal"
 	"github.com/bougou/go-ipmi/pkg/hal/mock"
+	"github.com/bougou/go-ipmi/pkg/types"
 )
 
 const (
@@ -62,8 +64,8 @@ func dialVM(t *testing.T, addr string) *vmClient {
 
 // sendControl sends a single-byte control command (version, capabilities),
 // terminated by the end-of-command marker instead of end-of-message.
-// Control command bytes QEMU sends on connect; the server consumes and ignores
-// them, and these tests prove that leaves the connection usable.
+// Control command bytes QEMU sends on connect; the server takes them in, and
+// these tests prove that leaves the connection usable.
 const (
 	vmTestCmdVersion      = 0xFF
 	vmTestCmdCapabilities = 0x08
@@ -242,6 +244,26 @@ func TestVMGetChannelInfoSystemInterface(t *testing.T) {
 	}
 }
 
+// TestVMSystemInfoFirmwareVersion proves Set System Info Parameters over
+// the VM frontend counts as the system interface, which may write the
+// system firmware version, and that the value lands in the shared BMC.
+func TestVMSystemInfoFirmwareVersion(t *testing.T) {
+	b := newTestBMC(t)
+	addr := startVM(t, NewVMServer(b))
+	vm := dialVM(t, addr)
+
+	vm.sendControl(t, vmTestCmdVersion, 1)
+
+	// Set System Info Parameters (App 0x58), param #1 set 0: ASCII, "1.2".
+	if cc, _ := vm.request(t, 0x06, 0x58, 0x01, 0x00, 0x00, 0x03, '1', '.', '2'); cc != 0 {
+		t.Fatalf("set firmware version: cc=%#x", cc)
+	}
+	v, err := b.SystemInfo.String(context.Background(), types.SystemInfoParamSelector_SystemFirmwareVersion)
+	if err != nil || v != "1.2" {
+		t.Fatalf("firmware version = %q, %v", v, err)
+	}
+}
+
 // TestVMUnknownCommand proves an unimplemented command answers 0xC1 (Invalid
 // Command) and the connection survives to serve the next request.
 func TestVMUnknownCommand(t *testing.T) {
@@ -387,3 +409,167 @@ func TestClientRoundTrip(t *testing.T) {
 		t.Fatalf("Get Device ID short response: %d bytes", len(data))
 	}
 }
+
+// TestVMAttention proves SMS attention reaches the guest: an event in the
+// Event Message Buffer asserts it, with the interrupt the guest enabled, and
+// reading the buffer deasserts it.
+func TestVMAttention(t *testing.T) {
+	b := newTestBMC(t)
+	addr := startVM(t, NewVMServer(b))
+
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	c := NewClient(conn, 5*time.Second)
+	if err := c.Handshake(CapAttn | CapIRQ); err != nil {
+		t.Fatal(err)
+	}
+
+	// System Event Logging, the Event Message Buffer and its interrupt.
+	if cc, _, err := c.Command(0x06, 0x2e, 0x0e); err != nil || cc != 0 {
+		t.Fatalf("Set BMC Global Enables: cc=%#x %v", cc, err)
+	}
+	err = b.LogEvent(context.Background(), bmc.PlatformEvent{
+		SensorType:       types.SensorTypeTemperature,
+		EventReadingType: types.EventReadingTypeThreshold,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cc, flags, err := c.Command(0x06, 0x31) // Get Message Flags
+	if err != nil || cc != 0 || len(flags) != 1 || flags[0]&0x02 == 0 {
+		t.Fatalf("Get Message Flags: cc=%#x % x %v", cc, flags, err)
+	}
+	if attention, interrupt := c.Attention(); !attention || !interrupt {
+		t.Fatalf("attention = %v, interrupt = %v, want both", attention, interrupt)
+	}
+
+	cc, event, err := c.Command(0x06, 0x35) // Read Event Message Buffer
+	if err != nil || cc != 0 || len(event) != 16 {
+		t.Fatalf("Read Event Message Buffer: cc=%#x % x %v", cc, event, err)
+	}
+	if attention, _ := c.Attention(); attention {
+		t.Fatal("attention should drop once the buffer is read")
+	}
+}
+
+// TestVMAttentionCapabilities proves a VM is told of SMS attention and its
+// interrupt only when it announced the capabilities for them, and is offered
+// the diagnostic interrupt only when it or the wrapped chassis can take it.
+func TestVMAttentionCapabilities(t *testing.T) {
+	m := NewMachine(noNMIHAL{mock.New()})
+	b := bmc.New(bmc.DeviceInfo{IPMIVersion: 0x20}, [16]byte{}, m, bmc.WithClock(clock.Real))
+	addr := startVM(t, NewVMServer(b, WithMachine(m)))
+	if _, ok := m.Chassis().(hal.DiagnosticInterruptHAL); ok {
+		t.Fatal("diagnostic interrupt offered with no VM connected")
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	c := NewClient(conn, 5*time.Second)
+	if err := c.Handshake(CapPower | CapNMI); err != nil {
+		t.Fatal(err)
+	}
+
+	// The receive message queue interrupt, and a message in the queue.
+	if cc, _, err := c.Command(0x06, 0x2e, 0x09); err != nil || cc != 0 {
+		t.Fatalf("Set BMC Global Enables: cc=%#x %v", cc, err)
+	}
+	if err := b.Messages.Enqueue(bmc.ReceivedMessage{Channel: 1, Data: []byte{0x05}}); err != nil {
+		t.Fatal(err)
+	}
+	if cc, _, err := c.Command(0x06, 0x31); err != nil || cc != 0 { // Get Message Flags
+		t.Fatalf("Get Message Flags: cc=%#x %v", cc, err)
+	}
+	if attention, _ := c.Attention(); attention || c.InterruptEnabled() {
+		t.Fatal("attention or interrupt sent to a VM without CapAttn and CapIRQ")
+	}
+
+	if _, ok := m.Chassis().(hal.DiagnosticInterruptHAL); !ok {
+		t.Fatal("diagnostic interrupt not offered to a VM with CapNMI")
+	}
+	if cc, _, err := c.Command(0x00, 0x02, 0x04); err != nil || cc != 0 { // diagnostic interrupt
+		t.Fatalf("Chassis Control: cc=%#x %v", cc, err)
+	}
+	if got, err := c.ReadControl(); err != nil || got != CmdSendNMI {
+		t.Fatalf("control command %#x, %v, want SEND_NMI", got, err)
+	}
+}
+
+// noNMIHAL is a HAL whose chassis has no diagnostic interrupt.
+type noNMIHAL struct{ *mock.HAL }
+
+func (h noNMIHAL) Chassis() hal.ChassisHAL {
+	return struct{ hal.ChassisHAL }{h.HAL.Chassis()}
+}
+
+// TestVMHardwareControl proves Chassis Control reaches the VM as the control
+// command QEMU announced the capability for, and the wrapped chassis is told
+// too.
+func TestVMHardwareControl(t *testing.T) {
+	m := NewMachine(mock.New())
+	b := bmc.New(bmc.DeviceInfo{IPMIVersion: 0x20}, [16]byte{}, m, bmc.WithClock(clock.Real))
+	addr := startVM(t, NewVMServer(b, WithMachine(m)))
+	chassis := m.HAL.Chassis().(*mock.Chassis)
+
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	c := NewClient(conn, 5*time.Second)
+
+	control := func(action byte) {
+		t.Helper()
+		if cc, _, err := c.Command(0x00, 0x02, action); err != nil || cc != 0 {
+			t.Fatalf("Chassis Control %#x: cc=%#x %v", action, cc, err)
+		}
+	}
+	expect := func(want ControlCommand) {
+		t.Helper()
+		if got, err := c.ReadControl(); err != nil || got != want {
+			t.Fatalf("control command %#x, %v, want %#x", got, err, want)
+		}
+	}
+
+	// Before the handshake the VM takes no control command.
+	control(0x03) // hard reset
+	if err := c.Handshake(CapPower | CapReset | CapGracefulShutdown | CapAttn | CapIRQ); err != nil {
+		t.Fatal(err)
+	}
+	control(0x04) // diagnostic interrupt: no NMI capability
+	control(0x03)
+	expect(CmdReset)
+	control(0x05) // soft shutdown
+	expect(CmdGracefulShutdown)
+	control(0x00) // power down
+	expect(CmdPowerOff)
+
+	if chassis.ColdResets != 2 || chassis.DiagInterrupts != 1 || chassis.On {
+		t.Fatalf("wrapped chassis: %d cold resets, %d diagnostic interrupts, on=%v", chassis.ColdResets, chassis.DiagInterrupts, chassis.On)
+	}
+	connected, version, caps := m.Connected()
+	if !connected || version != ProtocolVersion || caps&CapNMI != 0 || caps&CapPower == 0 {
+		t.Fatalf("connected=%v version=%d caps=%#x", connected, version, caps)
+	}
+
+	// The guest's interrupt follows the BMC Global Enables.
+	if cc, _, err := c.Command(0x06, 0x2e, 0x09); err != nil || cc != 0 {
+		t.Fatalf("Set BMC Global Enables: cc=%#x %v", cc, err)
+	}
+	if !c.InterruptEnabled() {
+		t.Fatal("the receive message queue interrupt should enable the guest's interrupt")
+	}
+	if cc, _, err := c.Command(0x06, 0x2e, 0x08); err != nil || cc != 0 {
+		t.Fatalf("Set BMC Global Enables: cc=%#x %v", cc, err)
+	}
+	if c.InterruptEnabled() {
+		t.Fatal("the guest's interrupt should be disabled again")
+	}
+}